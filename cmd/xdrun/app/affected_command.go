@@ -0,0 +1,248 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Affected Tasks
+// This file contains the cmd:affected command that runs only the tasks whose
+// declared sources changed since a git revision, and the tasks that depend on
+// them.
+
+// affectedTask is a task selected by cmd:affected and why it was selected
+type affectedTask struct {
+	Name   string
+	Reason string // the changed file matching its sources, or the dependency it depends on
+}
+
+// createAffectedCommand creates the cmd:affected subcommand
+func (a *App) createAffectedCommand() *cobra.Command {
+	var configFile string
+	var since string
+	var listOnly bool
+	var dryRun bool
+	var noInput bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:affected",
+		Short: "Run the tasks affected by the files changed since a git revision",
+		Long: `Run only the tasks affected by the files changed since a git revision.
+
+A task is affected when a changed file matches one of the globs of its
+sources declaration, or when it depends, directly or through other tasks, on
+an affected task. Changed files are those that differ between the working
+tree and the merge base of --since and HEAD, so both committed and
+uncommitted changes count. Globs are relative to the current directory and
+support ** for any number of directories.
+
+  task "test":
+    sources "src/**/*.go", "go.mod"
+    run "go test ./..."
+
+Examples:
+  xdrun cmd:affected --since origin/main          # Run the affected tasks
+  xdrun cmd:affected --since origin/main --list   # Only list them and why
+  xdrun cmd:affected --since HEAD~1 --dry-run     # Show what would be executed
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			changed, err := changedFilesSince(since)
+			if err != nil {
+				return err
+			}
+			affected, err := findAffectedTasks(configFile, changed)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if len(affected) == 0 {
+				_, _ = fmt.Fprintf(out, "No tasks affected by changes since %s\n", since)
+				return nil
+			}
+			writeAffectedTasks(out, affected)
+			if listOnly {
+				return nil
+			}
+
+			names := make([]string, len(affected))
+			for i, task := range affected {
+				names[i] = task.Name
+			}
+			_, _ = fmt.Fprintln(out)
+			return ExecuteTask(RunOptions{ConfigFile: configFile, DryRun: dryRun, NoInput: noInput, Args: names})
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().StringVar(&since, "since", "", "Git revision to compare against (required), e.g. origin/main")
+	cmd.Flags().BoolVar(&listOnly, "list", false, "List the affected tasks and why instead of running them")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be executed without running")
+	cmd.Flags().BoolVar(&noInput, "no-input", false, "Never prompt for missing required parameters; fail instead")
+	_ = cmd.MarkFlagRequired("since")
+
+	return cmd
+}
+
+// changedFilesSince returns the files, relative to the current directory,
+// that changed between the merge base of since and HEAD and the working tree
+func changedFilesSince(since string) ([]string, error) {
+	// #nosec G204 -- the revision is passed to git as a single argument
+	base, err := exec.Command("git", "merge-base", since, "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the merge base of %s and HEAD: %w", since, err)
+	}
+
+	// #nosec G204 -- the merge base comes from git itself
+	diff, err := exec.Command("git", "diff", "--name-only", "--relative", strings.TrimSpace(string(base))).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %w", since, err)
+	}
+
+	// Untracked files are new sources too
+	untracked, err := exec.Command("git", "ls-files", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(diff)+"\n"+string(untracked), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// findAffectedTasks returns the tasks of the task file affected by the
+// changed files, in declaration order
+func findAffectedTasks(configFile string, changed []string) ([]affectedTask, error) {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:affected intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	tasks, err := engine.NewEngine(io.Discard).ListTasksWithIncludes(program, actualConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectAffectedTasks(tasks, changed), nil
+}
+
+// selectAffectedTasks returns the tasks whose sources match a changed file,
+// followed in the same pass by the tasks that depend on them
+func selectAffectedTasks(tasks []engine.TaskInfo, changed []string) []affectedTask {
+	byName := make(map[string]engine.TaskInfo, len(tasks))
+	for _, info := range tasks {
+		if _, exists := byName[info.Name]; !exists {
+			byName[info.Name] = info
+		}
+	}
+
+	reasons := make(map[string]string)
+	for _, info := range tasks {
+		if file, ok := matchingSource(info.Sources, changed); ok {
+			reasons[info.Name] = "changed " + file
+		}
+	}
+
+	// Propagate to dependents until nothing changes; dependency graphs are
+	// small, so the repeated passes are cheap
+	for grew := true; grew; {
+		grew = false
+		for _, info := range tasks {
+			if _, done := reasons[info.Name]; done {
+				continue
+			}
+			for _, depName := range info.Dependencies {
+				dep, found := lookupDependency(info, depName, byName)
+				if _, depAffected := reasons[dep.Name]; found && depAffected {
+					reasons[info.Name] = "depends on " + dep.Name
+					grew = true
+					break
+				}
+			}
+		}
+	}
+
+	var affected []affectedTask
+	for _, info := range tasks {
+		if reason, ok := reasons[info.Name]; ok {
+			affected = append(affected, affectedTask{Name: info.Name, Reason: reason})
+			delete(reasons, info.Name)
+		}
+	}
+	return affected
+}
+
+// matchingSource returns the first changed file matched by one of globs
+func matchingSource(globs, changed []string) (string, bool) {
+	for _, file := range changed {
+		for _, glob := range globs {
+			if matchSourceGlob(glob, file) {
+				return file, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchSourceGlob reports whether file matches glob, where each path segment
+// is matched with path.Match and a "**" segment matches any number of
+// directories
+func matchSourceGlob(glob, file string) bool {
+	return matchSegments(strings.Split(path.Clean(glob), "/"), strings.Split(path.Clean(file), "/"))
+}
+
+func matchSegments(glob, file []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(glob[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, err := path.Match(glob[0], file[0]); err != nil || !ok {
+			return false
+		}
+		glob, file = glob[1:], file[1:]
+	}
+	return len(file) == 0
+}
+
+// writeAffectedTasks lists the affected tasks and why they were selected
+func writeAffectedTasks(out io.Writer, affected []affectedTask) {
+	_, _ = fmt.Fprintln(out, "Affected tasks:")
+	for _, task := range affected {
+		_, _ = fmt.Fprintf(out, "  %-20s  %s\n", task.Name, task.Reason)
+	}
+}
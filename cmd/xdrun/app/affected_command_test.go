@@ -0,0 +1,60 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+)
+
+func TestMatchSourceGlob(t *testing.T) {
+	tests := []struct {
+		glob, file string
+		want       bool
+	}{
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/pkg/deep/util.go", true},
+		{"src/**/*.go", "cmd/main.go", false},
+		{"src/**/*.go", "src/README.md", false},
+		{"go.mod", "go.mod", true},
+		{"go.mod", "sub/go.mod", false},
+		{"**/go.mod", "sub/go.mod", true},
+		{"docs/**", "docs/guide/intro.md", true},
+		{"*.md", "docs/intro.md", false},
+	}
+	for _, tt := range tests {
+		if got := matchSourceGlob(tt.glob, tt.file); got != tt.want {
+			t.Errorf("matchSourceGlob(%q, %q) = %v, want %v", tt.glob, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestSelectAffectedTasksIncludesDependents(t *testing.T) {
+	tasks := []engine.TaskInfo{
+		{Name: "lint", Sources: []string{"src/**/*.go"}},
+		{Name: "docs", Sources: []string{"docs/**"}},
+		{Name: "test", Sources: []string{"src/**/*_test.go"}, Dependencies: []string{"lint"}},
+		{Name: "release", Dependencies: []string{"test"}},
+		{Name: "publish", Dependencies: []string{"docs"}},
+		{Name: "docker.build", Namespace: "docker", Dependencies: []string{"lint"}},
+	}
+
+	got := selectAffectedTasks(tasks, []string{"src/api/server.go"})
+	want := []affectedTask{
+		{Name: "lint", Reason: "changed src/api/server.go"},
+		{Name: "test", Reason: "depends on lint"},
+		{Name: "release", Reason: "depends on test"},
+		{Name: "docker.build", Reason: "depends on lint"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("selectAffectedTasks() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("selectAffectedTasks()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := selectAffectedTasks(tasks, []string{"README.md"}); len(got) != 0 {
+		t.Errorf("selectAffectedTasks() = %+v, want none", got)
+	}
+}
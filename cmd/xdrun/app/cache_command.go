@@ -0,0 +1,365 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/cache"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+	"github.com/phillarmonic/drun/v2/internal/runtemp"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Cache Maintenance
+// This file contains the cmd:cache command that reports and prunes the
+// ~/.drun/cache.solo database shared by remote includes, provisioning
+// catalogs and init templates, and explains the run history lookups of
+// `once per commit` tasks.
+
+// cacheCategoryOrder is the order categories are reported in
+var cacheCategoryOrder = []string{cache.CategoryIncludes, cache.CategoryCatalogs, cache.CategoryTemplates}
+
+// createCacheCommand creates the cmd:cache subcommand
+func (a *App) createCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cmd:cache",
+		Short: "Show and prune the drun cache",
+		Long: `Show and prune the cache in ~/.drun/cache.solo.
+
+The cache holds remote includes, provisioning catalogs and init templates
+fetched from the network. Entries cached by drun versions without cache
+statistics are not listed until they are fetched again.
+
+Examples:
+  xdrun cmd:cache stats                      # Show disk usage per category
+  xdrun cmd:cache gc                         # Remove expired entries
+  xdrun cmd:cache gc --older-than 30d        # Also remove entries older than 30 days
+  xdrun cmd:cache gc --max-size 50MB --dry-run
+                                             # List what would be removed to fit in 50 MB
+  xdrun cmd:cache gc --temp                  # Remove temporary files left by crashed runs
+  xdrun cmd:cache explain verify env=prod    # Show why a once per commit task runs again
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+	}
+
+	cmd.AddCommand(createCacheStatsCommand())
+	cmd.AddCommand(createCacheGCCommand())
+	cmd.AddCommand(a.createCacheExplainCommand())
+
+	return cmd
+}
+
+func createCacheStatsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show the disk usage of every cache category",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, path, err := openCache()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = manager.Close() }()
+			return runCacheStats(cmd.OutOrStdout(), manager, path, time.Now())
+		},
+	}
+}
+
+func createCacheGCCommand() *cobra.Command {
+	var olderThan, maxSize string
+	var dryRun, temp bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove expired, old or excess cache entries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if temp {
+				if olderThan != "" || maxSize != "" {
+					return fmt.Errorf("--temp cannot be combined with --older-than or --max-size")
+				}
+				return runTempGC(cmd.OutOrStdout(), runtemp.Root(), dryRun)
+			}
+
+			opts := cache.PruneOptions{DryRun: dryRun}
+			var err error
+			if olderThan != "" {
+				if opts.OlderThan, err = parseCacheAge(olderThan); err != nil {
+					return err
+				}
+			}
+			if maxSize != "" {
+				if opts.MaxSize, err = parseByteSize(maxSize); err != nil {
+					return err
+				}
+			}
+
+			manager, _, err := openCache()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = manager.Close() }()
+			return runCacheGC(cmd.OutOrStdout(), manager, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Also remove entries stored longer ago than this (e.g. 12h, 30d)")
+	cmd.Flags().StringVar(&maxSize, "max-size", "", "Remove the oldest entries until the cache fits in this size (e.g. 500KB, 50MB)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the entries that would be removed without removing them")
+	cmd.Flags().BoolVar(&temp, "temp", false, "Remove the temporary files of runs that are no longer running instead of cache entries")
+
+	return cmd
+}
+
+func (a *App) createCacheExplainCommand() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "explain <task> [param=value...]",
+		Short: "Show the inputs a once per commit task is cached by and which changed",
+		Long: `Show the inputs the run history of a 'once per commit' task is keyed by:
+the project directory, the task, the git commit and every parameter value,
+each with a short hash. Inputs that differ from the latest recorded run of
+the task are marked, which explains why the task runs again.
+
+Pass the same parameters as when running the task.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: CompleteTaskNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runCacheExplain(cmd.OutOrStdout(), configFile, args[0], ParseTaskParameters(args[1:]), nil)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+
+	return cmd
+}
+
+// openCache opens the cache database at its default location
+func openCache() (*cache.Manager, string, error) {
+	path, err := cache.DefaultPath()
+	if err != nil {
+		return nil, "", err
+	}
+	manager, err := cache.NewManagerAt(path, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	return manager, path, nil
+}
+
+// runCacheStats writes the entry count and size of every category
+func runCacheStats(out io.Writer, manager *cache.Manager, path string, now time.Time) error {
+	type usage struct {
+		entries, expired int
+		size             int64
+	}
+	usages := make(map[string]*usage)
+	var total int64
+	for _, entry := range manager.Entries() {
+		u := usages[entry.Category()]
+		if u == nil {
+			u = &usage{}
+			usages[entry.Category()] = u
+		}
+		u.entries++
+		u.size += entry.Size
+		total += entry.Size
+		if entry.Expired(now) {
+			u.expired++
+		}
+	}
+
+	categories := append([]string{}, cacheCategoryOrder...)
+	var others []string
+	for category := range usages {
+		if _, known := cache.CategoryNames[category]; !known {
+			others = append(others, category)
+		}
+	}
+	sort.Strings(others)
+	categories = append(categories, others...)
+
+	_, _ = fmt.Fprintf(out, "Cache: %s (%s on disk)\n\n", path, formatByteSize(manager.Stats().FileBytes))
+	_, _ = fmt.Fprintf(out, "%-24s %8s %8s %10s\n", "CATEGORY", "ENTRIES", "EXPIRED", "SIZE")
+	for _, category := range categories {
+		u := usages[category]
+		if u == nil {
+			u = &usage{}
+		}
+		name := cache.CategoryNames[category]
+		if name == "" {
+			name = category
+		}
+		_, _ = fmt.Fprintf(out, "%-24s %8d %8d %10s\n", name, u.entries, u.expired, formatByteSize(u.size))
+	}
+	_, _ = fmt.Fprintf(out, "%-24s %8s %8s %10s\n", "total", "", "", formatByteSize(total))
+	return nil
+}
+
+// runCacheGC prunes the cache and lists the removed entries
+func runCacheGC(out io.Writer, manager *cache.Manager, opts cache.PruneOptions) error {
+	removed, err := manager.Prune(opts)
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if opts.DryRun {
+		verb = "Would remove"
+	}
+	var size int64
+	for _, entry := range removed {
+		size += entry.Size
+		name := cache.CategoryNames[entry.Category()]
+		if name == "" {
+			name = entry.Category()
+		}
+		_, _ = fmt.Fprintf(out, "%s %s entry %s (%s, stored %s)\n", verb, name, entry.Key, formatByteSize(entry.Size), entry.StoredAt.Format(time.RFC3339))
+	}
+	_, _ = fmt.Fprintf(out, "%s %d entries (%s)\n", verb, len(removed), formatByteSize(size))
+	return nil
+}
+
+// runTempGC removes the temporary directories of runs that are no longer
+// alive and lists them
+func runTempGC(out io.Writer, root string, dryRun bool) error {
+	removed, err := runtemp.Sweep(root, runtemp.SweepOptions{Grace: runtemp.DefaultGrace, DryRun: dryRun})
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	var size int64
+	for _, stale := range removed {
+		size += stale.Size
+		_, _ = fmt.Fprintf(out, "%s %s (%s, %s)\n", verb, stale.Path, formatByteSize(stale.Size), stale.Reason)
+	}
+	_, _ = fmt.Fprintf(out, "%s %d temporary files and directories (%s)\n", verb, len(removed), formatByteSize(size))
+	return err
+}
+
+// runCacheExplain lists the run history inputs of a task, marking those that
+// changed since its latest recorded run. A nil store is the user's default
+// run history.
+func runCacheExplain(out io.Writer, configFile, taskName string, params map[string]string, store *runhistory.Store) error {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+	// #nosec G304 -- cmd:cache explain intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	eng := engine.NewEngineWithOptions(engine.WithOutput(io.Discard), engine.WithRunHistory(store))
+	explanation, err := eng.ExplainRunHistory(program, taskName, params, actualConfigFile)
+	if err != nil {
+		return err
+	}
+
+	current := explanation.Current
+	_, _ = fmt.Fprintf(out, "Task '%s' (once per commit) in %s\n", current.Task, current.Project)
+	switch {
+	case explanation.Cached:
+		_, _ = fmt.Fprintln(out, "Cached: yes, a run with these inputs already succeeded and the task will be skipped")
+	case explanation.Previous != nil:
+		_, _ = fmt.Fprintf(out, "Cached: no, the inputs differ from the latest recorded run (finished %s)\n",
+			explanation.Previous.FinishedAt.Format(time.RFC3339))
+	default:
+		_, _ = fmt.Fprintln(out, "Cached: no, the task has no recorded runs")
+	}
+	_, _ = fmt.Fprintln(out)
+
+	previous := make(map[string]runhistory.Input)
+	if explanation.Previous != nil {
+		for _, input := range explanation.Previous.Inputs() {
+			previous[input.Name] = input
+		}
+	}
+	_, _ = fmt.Fprintf(out, "%-20s %-12s %-8s %s\n", "INPUT", "HASH", "CHANGE", "VALUE")
+	for _, input := range current.Inputs() {
+		change, value := "", input.Value
+		if explanation.Previous != nil {
+			if old, found := previous[input.Name]; !found {
+				change = "added"
+			} else if old.Hash != input.Hash {
+				change, value = "changed", fmt.Sprintf("%s (was %s)", input.Value, old.Value)
+			}
+			delete(previous, input.Name)
+		}
+		_, _ = fmt.Fprintf(out, "%-20s %-12s %-8s %s\n", input.Name, input.Hash, change, value)
+	}
+	if explanation.Previous != nil {
+		for _, input := range explanation.Previous.Inputs() {
+			if _, removed := previous[input.Name]; removed {
+				_, _ = fmt.Fprintf(out, "%-20s %-12s %-8s (was %s)\n", input.Name, input.Hash, "removed", input.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// parseCacheAge parses a Go duration or a number of days such as "30d"
+func parseCacheAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q: expected a number of days such as 30d", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q: expected a duration such as 12h or 30d", value)
+	}
+	return age, nil
+}
+
+// parseByteSize parses sizes such as 512, 500KB, 50MB or 1GB (powers of 1024)
+func parseByteSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			text, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: expected a size such as 500KB or 50MB", value)
+	}
+	return n * multiplier, nil
+}
+
+// formatByteSize formats a size in bytes for reports
+func formatByteSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,228 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/cache"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+	"github.com/phillarmonic/drun/v2/internal/runtemp"
+)
+
+func newTestCache(t *testing.T) *cache.Manager {
+	t.Helper()
+	manager, err := cache.NewManagerAt(filepath.Join(t.TempDir(), "cache.solo"), time.Hour)
+	if err != nil {
+		t.Fatalf("NewManagerAt() error = %v", err)
+	}
+	t.Cleanup(func() { _ = manager.Close() })
+
+	entries := []struct {
+		key     string
+		size    int
+		expires time.Duration
+	}{
+		{cache.GenerateKey("github:acme/ci/go.drun", "main"), 300, time.Hour},
+		{cache.GenerateCategoryKey(cache.CategoryCatalogs, "https://example.com/tools.yaml", ""), 200, time.Hour},
+		{cache.GenerateCategoryKey(cache.CategoryTemplates, "github:acme/templates", "v1"), 100, -time.Minute},
+	}
+	for _, entry := range entries {
+		if err := manager.Set(entry.key, bytes.Repeat([]byte("x"), entry.size), entry.expires); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	return manager
+}
+
+func TestRunCacheStats(t *testing.T) {
+	manager := newTestCache(t)
+
+	var out bytes.Buffer
+	if err := runCacheStats(&out, manager, "/home/dev/.drun/cache.solo", time.Now()); err != nil {
+		t.Fatalf("runCacheStats() error = %v", err)
+	}
+	for _, want := range []string{
+		"Cache: /home/dev/.drun/cache.solo",
+		"remote includes                 1        0      300 B",
+		"provisioning catalogs           1        0      200 B",
+		"init templates                  1        1      100 B",
+		"total                                           600 B",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunCacheGC(t *testing.T) {
+	manager := newTestCache(t)
+
+	var out bytes.Buffer
+	if err := runCacheGC(&out, manager, cache.PruneOptions{MaxSize: 250, DryRun: true}); err != nil {
+		t.Fatalf("runCacheGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would remove 2 entries (400 B)") {
+		t.Fatalf("expected the expired and the oldest entry to be listed:\n%s", out.String())
+	}
+	if got := len(manager.Entries()); got != 3 {
+		t.Fatalf("dry run removed entries: %d left", got)
+	}
+
+	out.Reset()
+	if err := runCacheGC(&out, manager, cache.PruneOptions{}); err != nil {
+		t.Fatalf("runCacheGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed init templates entry template:") || !strings.Contains(out.String(), "Removed 1 entries (100 B)") {
+		t.Fatalf("expected only the expired entry to be removed:\n%s", out.String())
+	}
+	for _, entry := range manager.Entries() {
+		if entry.Category() == cache.CategoryTemplates {
+			t.Fatalf("expired entry still indexed: %+v", entry)
+		}
+	}
+
+	out.Reset()
+	if err := runCacheGC(&out, manager, cache.PruneOptions{OlderThan: time.Minute, Now: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("runCacheGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed 2 entries (500 B)") {
+		t.Fatalf("expected the old entries to be removed:\n%s", out.String())
+	}
+}
+
+func TestRunTempGC(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	root := runtemp.Root()
+	host, _ := os.Hostname()
+
+	dir := filepath.Join(root, "run-1-abc")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "remote-1.drun"), bytes.Repeat([]byte("x"), 100), 0600); err != nil {
+		t.Fatal(err)
+	}
+	owner, _ := json.Marshal(map[string]any{"pid": 1 << 30, "host": host})
+	if err := os.WriteFile(filepath.Join(dir, "owner.json"), owner, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runTempGC(&out, root, true); err != nil {
+		t.Fatalf("runTempGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would remove "+dir) || !strings.Contains(out.String(), "Would remove 1 temporary files and directories") {
+		t.Fatalf("expected the directory of the exited run to be listed:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runTempGC(&out, root, false); err != nil {
+		t.Fatalf("runTempGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed 1 temporary files and directories") {
+		t.Fatalf("expected the directory to be removed:\n%s", out.String())
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", dir, err)
+	}
+}
+
+func TestRunCacheExplain(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=drun", "-c", "user.email=drun@example.com"}, args...)...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	oldCommit := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "next")
+
+	file := filepath.Join(dir, "spec.drun")
+	content := `version: 2.0
+
+task "verify":
+  once per commit
+  given $env defaults to "dev"
+  info "verifying {$env}"
+
+task "build":
+  info "building"
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := runhistory.NewStore(filepath.Join(t.TempDir(), "run-history.json"))
+
+	var buf bytes.Buffer
+	if err := runCacheExplain(&buf, file, "verify", nil, store); err != nil {
+		t.Fatalf("runCacheExplain() error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Cached: no, the task has no recorded runs") || !strings.Contains(out, "param env") {
+		t.Errorf("unexpected explanation without history:\n%s", out)
+	}
+
+	if err := store.Add(runhistory.Record{
+		Project: dir, Task: "verify", Commit: oldCommit, Params: map[string]string{"env": "prod"}, FinishedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := runCacheExplain(&buf, file, "verify", map[string]string{"env": "prod"}, store); err != nil {
+		t.Fatalf("runCacheExplain() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Cached: no, the inputs differ from the latest recorded run") {
+		t.Errorf("expected a cache miss, got:\n%s", out)
+	}
+	if !regexp.MustCompile(`commit +[0-9a-f]{12} +changed +[0-9a-f]+ \(was ` + oldCommit + `\)`).MatchString(out) {
+		t.Errorf("expected the commit to be reported as changed, got:\n%s", out)
+	}
+	if !regexp.MustCompile(`param env +[0-9a-f]{12} +prod`).MatchString(out) {
+		t.Errorf("expected the parameter to be reported as unchanged, got:\n%s", out)
+	}
+
+	if err := runCacheExplain(&buf, file, "build", nil, store); err == nil || !strings.Contains(err.Error(), "not declared once per commit") {
+		t.Errorf("expected an error for a task that is not cached, got %v", err)
+	}
+}
+
+func TestParseCacheFlags(t *testing.T) {
+	if age, err := parseCacheAge("30d"); err != nil || age != 30*24*time.Hour {
+		t.Fatalf("parseCacheAge(30d) = %v, %v", age, err)
+	}
+	if age, err := parseCacheAge("12h"); err != nil || age != 12*time.Hour {
+		t.Fatalf("parseCacheAge(12h) = %v, %v", age, err)
+	}
+	if _, err := parseCacheAge("soon"); err == nil {
+		t.Fatal("expected an error for an invalid age")
+	}
+
+	sizes := map[string]int64{"512": 512, "500KB": 500 << 10, "50mb": 50 << 20, "1G": 1 << 30}
+	for value, want := range sizes {
+		if got, err := parseByteSize(value); err != nil || got != want {
+			t.Fatalf("parseByteSize(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	if _, err := parseByteSize("lots"); err == nil {
+		t.Fatal("expected an error for an invalid size")
+	}
+}
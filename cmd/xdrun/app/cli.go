@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -23,7 +24,9 @@ type App struct {
 	// Flags
 	configFile              string
 	listTasks               bool
+	listTree                bool
 	dryRun                  bool
+	checkSyntax             bool
 	verbose                 bool
 	taskMode                string
 	showVersion             bool
@@ -33,12 +36,30 @@ type App struct {
 	initTemplateName        string
 	templatesRepo           string
 	listTemplates           bool
+	specVersions            bool
+	exitCodes               bool
 	saveAsDefault           bool
 	setWorkspace            string
 	selfUpdate              bool
 	allowUndefinedVars      bool
 	allowToolVersionChanges bool
 	noDrunCache             bool
+	parallelTargets         bool
+	maxCPU                  float64
+	maxMemory               string
+	maxDuration             time.Duration
+	skipHooks               bool
+	skipSetup               bool
+	skipTeardown            bool
+	noBefore                bool
+	noInput                 bool
+	force                   bool
+	eventsFile              string
+	reports                 []string
+	watchVar                string
+	notify                  bool
+	timings                 bool
+	profile                 string
 
 	// Debug flags
 	debugMode          bool
@@ -50,6 +71,7 @@ type App struct {
 	debugDomain        bool
 	debugInput         string
 	debugPlan          bool
+	debugHooks         bool
 	debugExportGraph   string
 	debugExportMermaid string
 	debugExportJSON    string
@@ -57,6 +79,7 @@ type App struct {
 
 // NewApp creates a new CLI application
 func NewApp(version, commit, date string) *App {
+	drunVersion = version
 	app := &App{
 		version: version,
 		commit:  commit,
@@ -74,7 +97,12 @@ It supports natural language commands, smart detection, and direct execution wit
 Examples:
   xdrun hello                    # Run the 'hello' task from a .drun file
   xdrun build --env=production   # Run 'build' task with environment
+  xdrun build test package       # Run several tasks in order in one invocation
+  xdrun lint test --parallel-targets
+                                 # Run several tasks concurrently
+  xdrun deploy --profile prod    # Apply the 'prod' parameter profile
   xdrun --list                   # List all available tasks
+  xdrun --list --tree            # List tasks with parameters and dependency trees
   xdrun --list-templates --templates-repo ../drun-templates
                                  # List available init templates from a local template repo
   xdrun --init                   # Create a new .drun file
@@ -83,9 +111,14 @@ Examples:
   xdrun --init --from-template github:owner/repo/templates.yaml@main --template go-cli
                                  # Create a new .drun file from a specific template manifest
   xdrun --init-minimal           # Create a minimal .drun file
+  xdrun --spec-versions          # Show the spec version of every .drun file
+  xdrun --exit-codes             # List the exit codes for each class of failure
   xdrun --debug --tokens         # Debug lexer tokens
   xdrun --debug --ast            # Debug AST structure
   xdrun --debug --full           # Full debug output
+  xdrun --debug --debug-hooks    # Show the effective hook chain
+  xdrun build --watch-var version
+                                 # Trace every change to $version while 'build' runs
 
 Built-in Commands:
   Use the 'cmd:' prefix for built-in commands to avoid conflicts with tasks:
@@ -96,7 +129,22 @@ Built-in Commands:
   xdrun cmd:lsp                  # Start the Drun language server over stdio
   xdrun cmd:skill install basics # Install project AI guidance for drun/xdrun
   xdrun cmd:secret add key       # Manage secrets (add, remove, list)
-  xdrun cmd:hook install         # Install git hooks for git policies`,
+  xdrun cmd:hook install         # Install git hooks for git policies
+  xdrun cmd:which eslint         # Show how a tool resolves against the project PATH
+  xdrun cmd:vendor               # Vendor remote includes into vendor/drun/
+  xdrun cmd:includes outdated    # List drunhub includes with newer releases
+  xdrun cmd:new task "deploy"    # Append a task skeleton (--template docker-build|release|service-deploy)
+  xdrun cmd:docs -o docs/tasks.md  # Generate markdown reference docs for tasks
+  xdrun cmd:inspect --format json  # Export a machine-readable description of the task file
+  xdrun cmd:inspect --tokens ci.drun  # Export semantic tokens for editor highlighting
+  xdrun cmd:config set outputStyle plain  # Change a default in ~/.drun/config.yml
+  xdrun cmd:env up               # Pull images and install versions from the environment block
+  xdrun cmd:deps deploy          # Draw a task's dependency graph in the terminal
+  xdrun cmd:affected --since origin/main  # Run the tasks affected by changed files
+  xdrun cmd:cache gc --older-than 30d     # Prune old entries from the drun cache
+  xdrun cmd:plan ci --format gha-matrix   # Export parallel dependencies as a GitHub Actions matrix
+  xdrun cmd:test --update-golden          # Record the output of the test blocks as golden files
+  xdrun cmd:lint                          # List the TODOs left in the task file`,
 		RunE:              app.run,
 		Args:              cobra.ArbitraryArgs,
 		ValidArgsFunction: CompleteTaskNames,
@@ -173,16 +221,36 @@ func (a *App) setupFlags() {
 
 	flags.StringVarP(&a.configFile, "file", "f", "", "[xdrun CLI cmd] Task file (default: .drun/spec.drun or workspace configured file)")
 	flags.BoolVarP(&a.listTasks, "list", "l", false, "[xdrun CLI cmd] List available tasks")
+	flags.BoolVar(&a.listTree, "tree", false, "[xdrun CLI cmd] With --list, show each task's tags, parameters and dependency tree")
 	flags.BoolVar(&a.dryRun, "dry-run", false, "[xdrun CLI cmd] Show what would be executed without running")
+	flags.BoolVar(&a.checkSyntax, "check-syntax", false, "[xdrun CLI cmd] With --dry-run, check the syntax of every shell command with the shell that would run it")
 	flags.BoolVarP(&a.verbose, "verbose", "v", false, "[xdrun CLI cmd] Show detailed execution information")
 	flags.StringVar(&a.taskMode, "task-mode", "", "[xdrun CLI cmd] Override task execution mode for this run (supported: ci, normal)")
 	flags.BoolVar(&a.noDrunCache, "no-drun-cache", false, "[xdrun CLI cmd] Disable remote include caching (always fetch)")
+	flags.BoolVar(&a.parallelTargets, "parallel-targets", false, "[xdrun CLI cmd] Run multiple requested tasks concurrently (their dependencies run first)")
+	flags.Float64Var(&a.maxCPU, "max-cpu", 0, "[xdrun CLI cmd] Cpus shared by tasks that declare 'needs' when they run concurrently (default: all cpus)")
+	flags.StringVar(&a.maxMemory, "max-memory", "", "[xdrun CLI cmd] Memory shared by tasks that declare 'needs', e.g. 8GB (default: all memory)")
+	flags.DurationVar(&a.maxDuration, "max-duration", 0, "[xdrun CLI cmd] Stop the whole run after this long, e.g. 30m; finally blocks and teardown hooks still run (default: the project's max duration)")
+	flags.BoolVar(&a.skipHooks, "skip-hooks", false, "[xdrun CLI cmd] Skip every project hook (setup, teardown, before and after)")
+	flags.BoolVar(&a.skipSetup, "skip-setup", false, "[xdrun CLI cmd] Skip the 'on drun setup' hooks")
+	flags.BoolVar(&a.skipTeardown, "skip-teardown", false, "[xdrun CLI cmd] Skip the 'on drun teardown' hooks")
+	flags.BoolVar(&a.noBefore, "no-before", false, "[xdrun CLI cmd] Skip the 'before any task' hooks")
+	flags.BoolVar(&a.noInput, "no-input", false, "[xdrun CLI cmd] Never prompt for missing required parameters; fail instead")
+	flags.StringVar(&a.eventsFile, "events-json", "", "[xdrun CLI cmd] Write execution events as JSON lines to a file ('-' for stderr)")
+	flags.StringArrayVar(&a.reports, "report", nil, "[xdrun CLI cmd] Write a report of the run when it ends, as format=file, e.g. html=report.html (can be repeated)")
+	flags.StringVar(&a.watchVar, "watch-var", "", "[xdrun CLI cmd] Trace every assignment to a variable during execution")
+	flags.BoolVar(&a.notify, "notify", false, "[xdrun CLI cmd] Show a desktop notification and ring the terminal bell when the run finishes")
+	flags.BoolVar(&a.timings, "timings", false, "[xdrun CLI cmd] Show how long each task took and a duration summary at the end of the run")
+	flags.BoolVar(&a.force, "force", false, "[xdrun CLI cmd] Run 'once per commit' tasks even if they already succeeded for this commit")
+	flags.StringVar(&a.profile, "profile", "", "[xdrun CLI cmd] Apply a project parameter profile before command-line parameters")
 	flags.BoolVar(&a.showVersion, "version", false, "[xdrun CLI cmd] Show version information")
 	flags.BoolVar(&a.initConfig, "init", false, "[xdrun CLI cmd] Initialize a new .drun task file")
 	flags.BoolVar(&a.initMinimalConfig, "init-minimal", false, "[xdrun CLI cmd] Initialize a new minimal .drun task file")
 	flags.StringVar(&a.initFromTemplate, "from-template", "", "[xdrun CLI cmd] Initialize from a specific template manifest (github:/drunhub:/https:// or local path)")
 	flags.StringVar(&a.initTemplateName, "template", "", "[xdrun CLI cmd] Template entry name to use with --from-template or --templates-repo")
 	flags.StringVar(&a.templatesRepo, "templates-repo", "", "[xdrun CLI cmd] Local template repository root containing templates.yaml")
+	flags.BoolVar(&a.exitCodes, "exit-codes", false, "[xdrun CLI cmd] List the exit codes xdrun uses for each class of failure")
+	flags.BoolVar(&a.specVersions, "spec-versions", false, "[xdrun CLI cmd] List the .drun files under the current directory with their spec versions")
 	flags.BoolVar(&a.listTemplates, "list-templates", false, "[xdrun CLI cmd] List available init templates from a manifest, local template repo, or configured catalog")
 	flags.BoolVar(&a.saveAsDefault, "save-as-default", false, "[xdrun CLI cmd] Save custom file name as workspace default (use with --init or --init-minimal)")
 	flags.StringVar(&a.setWorkspace, "set-workspace", "", "[xdrun CLI cmd] Set workspace default task file location")
@@ -200,6 +268,7 @@ func (a *App) setupFlags() {
 	flags.BoolVar(&a.debugDomain, "debug-domain", false, "[xdrun CLI cmd] Show domain layer information (task registry, dependencies)")
 	flags.StringVar(&a.debugInput, "debug-input", "", "[xdrun CLI cmd] Debug input string directly instead of file (requires --debug)")
 	flags.BoolVar(&a.debugPlan, "debug-plan", false, "[xdrun CLI cmd] Show execution plan (requires --debug-domain)")
+	flags.BoolVar(&a.debugHooks, "debug-hooks", false, "[xdrun CLI cmd] Show the effective lifecycle hook chain, including included hooks (requires --debug)")
 	flags.StringVar(&a.debugExportGraph, "debug-export-graph", "", "[xdrun CLI cmd] Export execution plan as Graphviz DOT file (e.g., 'plan' creates plan-<task>.dot)")
 	flags.StringVar(&a.debugExportMermaid, "debug-export-mermaid", "", "[xdrun CLI cmd] Export execution plan as Mermaid diagram (e.g., 'plan' creates plan-<task>.mmd)")
 	flags.StringVar(&a.debugExportJSON, "debug-export-json", "", "[xdrun CLI cmd] Export execution plan as JSON (e.g., 'plan' creates plan-<task>.json)")
@@ -222,6 +291,22 @@ func (a *App) setupCommands() {
 		a.createSkillCommand(),
 		a.createSecretsCommand(),
 		a.createHookCommand(),
+		a.createWhichCommand(),
+		a.createVendorCommand(),
+		a.createIncludesCommand(),
+		a.createNewCommand(),
+		a.createDocsCommand(),
+		a.createInspectCommand(),
+		a.createConfigCommand(),
+		a.createEnvCommand(),
+		a.createDepsCommand(),
+		a.createAffectedCommand(),
+		a.createCacheCommand(),
+		a.createRerunCommand(),
+		a.createFmtCommand(),
+		a.createLintCommand(),
+		a.createPlanCommand(),
+		a.createTestCommand(),
 	}
 	for _, cmd := range cmds {
 		cmd.Hidden = true
@@ -241,6 +326,14 @@ func (a *App) run(cmd *cobra.Command, args []string) error {
 		return HandleSelfUpdate(a.version)
 	}
 
+	if a.specVersions {
+		return ReportSpecVersions(os.Stdout, ".")
+	}
+
+	if a.exitCodes {
+		return PrintExitCodes(os.Stdout)
+	}
+
 	if a.listTemplates {
 		if a.initConfig || a.initMinimalConfig {
 			return fmt.Errorf("--list-templates cannot be combined with --init or --init-minimal")
@@ -281,22 +374,81 @@ func (a *App) run(cmd *cobra.Command, args []string) error {
 				ExportGraphviz: a.debugExportGraph,
 				ExportMermaid:  a.debugExportMermaid,
 				ExportJSON:     a.debugExportJSON,
+				ShowHooks:      a.debugHooks,
 			},
 		)
 	}
 
+	// --verbose on the command line wins over the configured default
+	verbose := a.verbose
+	if !cmd.Flags().Changed("verbose") {
+		if userConfig, err := loadUserConfig(); err == nil && userConfig.Verbose != nil {
+			verbose = *userConfig.Verbose
+		}
+	}
+
+	maxMemory := int64(0)
+	if a.maxMemory != "" {
+		var err error
+		if maxMemory, err = parseByteSize(a.maxMemory); err != nil {
+			return fmt.Errorf("--max-memory: %w", err)
+		}
+	}
+	if a.maxCPU < 0 {
+		return fmt.Errorf("--max-cpu must not be negative")
+	}
+	if a.maxDuration < 0 {
+		return fmt.Errorf("--max-duration must not be negative")
+	}
+	if a.checkSyntax && !a.dryRun {
+		return fmt.Errorf("--check-syntax requires --dry-run")
+	}
+
 	// Normal execution - run task
-	return ExecuteTask(
-		a.configFile,
-		a.listTasks,
-		a.dryRun,
-		a.verbose,
-		a.taskMode,
-		a.allowUndefinedVars,
-		a.allowToolVersionChanges,
-		a.noDrunCache,
-		args,
-	)
+	return ExecuteTask(RunOptions{
+		ConfigFile:              a.configFile,
+		ListTasks:               a.listTasks,
+		ListTree:                a.listTree,
+		DryRun:                  a.dryRun,
+		CheckSyntax:             a.checkSyntax,
+		Verbose:                 verbose,
+		TaskMode:                a.taskMode,
+		AllowUndefinedVars:      a.allowUndefinedVars,
+		AllowToolVersionChanges: a.allowToolVersionChanges,
+		NoDrunCache:             a.noDrunCache,
+		ParallelTargets:         a.parallelTargets,
+		NoInput:                 a.noInput,
+		Force:                   a.force,
+		EventsFile:              a.eventsFile,
+		Reports:                 a.reports,
+		WatchVar:                a.watchVar,
+		Notify:                  a.notify,
+		Timings:                 a.timings,
+		Profile:                 a.profile,
+		MaxCPU:                  a.maxCPU,
+		MaxMemory:               maxMemory,
+		MaxDuration:             a.maxDuration,
+		SkipHooks:               a.skippedHooks(),
+		Args:                    args,
+	})
+}
+
+// skippedHooks returns the hook kinds the --skip-* and --no-before flags skip
+func (a *App) skippedHooks() []string {
+	if a.skipHooks {
+		return []string{"setup", "teardown", "before", "after"}
+	}
+	var kinds []string
+	if a.skipSetup {
+		kinds = append(kinds, "setup")
+	}
+	if a.skipTeardown {
+		kinds = append(kinds, "teardown")
+	}
+	if a.noBefore {
+		kinds = append(kinds, "before")
+	}
+	return kinds
 }
 
 // createCompletionCommand creates the cmd:completion subcommand
@@ -1,6 +1,9 @@
 package app
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestAllowToolVersionChangesFlagParses(t *testing.T) {
 	app := NewApp("test", "test", "test")
@@ -12,3 +15,24 @@ func TestAllowToolVersionChangesFlagParses(t *testing.T) {
 		t.Fatalf("allowToolVersionChanges = false, want true")
 	}
 }
+
+func TestSkipHookFlags(t *testing.T) {
+	tests := []struct {
+		flags []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"--skip-setup", "--no-before"}, "setup,before"},
+		{[]string{"--skip-teardown"}, "teardown"},
+		{[]string{"--skip-hooks", "--skip-setup"}, "setup,teardown,before,after"},
+	}
+	for _, tt := range tests {
+		app := NewApp("test", "test", "test")
+		if err := app.rootCmd.ParseFlags(tt.flags); err != nil {
+			t.Fatalf("ParseFlags(%v) error = %v", tt.flags, err)
+		}
+		if got := strings.Join(app.skippedHooks(), ","); got != tt.want {
+			t.Errorf("skippedHooks() with %v = %q, want %q", tt.flags, got, tt.want)
+		}
+	}
+}
@@ -105,7 +105,7 @@ func CompleteTaskNames(cmd *cobra.Command, args []string, toComplete string) ([]
 
 func completeTaskParameters(program *ast.Program, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	directive := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
-	if len(args) == 0 || strings.Contains(toComplete, "=") {
+	if len(args) == 0 {
 		return nil, directive
 	}
 
@@ -113,6 +113,9 @@ func completeTaskParameters(program *ast.Program, args []string, toComplete stri
 	if err != nil {
 		return nil, directive
 	}
+	if strings.Contains(toComplete, "=") {
+		return completeParameterValues(program, taskName, toComplete)
+	}
 
 	used := make(map[string]struct{}, len(args)-1)
 	for _, arg := range args[1:] {
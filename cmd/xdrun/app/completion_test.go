@@ -221,6 +221,47 @@ task "deploy" means "Deploy":
 	}
 }
 
+func TestCompleteTaskNamesCompletesValuesFromCompletionCommand(t *testing.T) {
+	withCompletionSpec(t, `
+version: 2.0
+
+task "logs" means "Show logs":
+  requires $service completed by "echo called >> calls.txt; echo api; echo web; echo worker"
+  requires $since
+  info "logs"
+`)
+	cacheDir := t.TempDir()
+	original := completionCacheDir
+	completionCacheDir = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { completionCacheDir = original })
+
+	app := NewApp("test", "test", "test")
+	completions, directive := CompleteTaskNames(app.rootCmd, []string{"logs"}, "service=w")
+	if strings.Join(completions, ",") != "service=web,service=worker" {
+		t.Fatalf("CompleteTaskNames() completions = %#v, want the command's matching values", completions)
+	}
+	expectedDirective := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	if directive != expectedDirective {
+		t.Fatalf("CompleteTaskNames() directive = %v, want %v", directive, expectedDirective)
+	}
+
+	completions, _ = CompleteTaskNames(app.rootCmd, []string{"logs"}, "service=")
+	if len(completions) != 3 {
+		t.Fatalf("CompleteTaskNames() completions = %#v, want every value", completions)
+	}
+	calls, err := os.ReadFile("calls.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Count(string(calls), "called") != 1 {
+		t.Fatalf("completion command ran %d times, want once with the cache", strings.Count(string(calls), "called"))
+	}
+
+	if completions, _ := CompleteTaskNames(app.rootCmd, []string{"logs"}, "since="); len(completions) != 0 {
+		t.Fatalf("CompleteTaskNames() completions = %#v, want none without a completion command", completions)
+	}
+}
+
 func withCompletionSpec(t *testing.T, source string) {
 	t.Helper()
 	tempRoot := t.TempDir()
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Parameter Value Completion
+// This file suggests values for parameters declared with
+// `completed by "command"`, such as live Kubernetes services. The command
+// runs in the current directory with a timeout, and its output is cached for
+// a short while so repeated tab presses stay fast.
+
+const (
+	// completionCommandTimeout bounds a completion command; a slower one
+	// suggests nothing rather than freezing the shell
+	completionCommandTimeout = 5 * time.Second
+	// completionCacheTTL is how long a completion command's output is reused
+	completionCacheTTL = time.Minute
+)
+
+// completionCacheDir returns where completion command output is cached,
+// ~/.drun/completions; tests replace it
+var completionCacheDir = func() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".drun", "completions"), nil
+}
+
+// completeParameterValues completes the value of a name=value argument from
+// the parameter's completion command
+func completeParameterValues(program *ast.Program, taskName, toComplete string) ([]string, cobra.ShellCompDirective) {
+	directive := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	name, prefix, _ := strings.Cut(toComplete, "=")
+
+	command := ""
+	for _, task := range program.Tasks {
+		if task.Name != taskName {
+			continue
+		}
+		for _, parameter := range task.Parameters {
+			if parameter.Name == name && parameter.Completion != "" {
+				command = parameter.Completion
+			}
+		}
+	}
+	if command == "" {
+		return nil, directive
+	}
+
+	var completions []string
+	for _, value := range completionCommandValues(command) {
+		if strings.HasPrefix(value, prefix) {
+			completions = append(completions, name+"="+value)
+		}
+	}
+	return completions, directive
+}
+
+// completionCommandValues returns the non-empty lines a completion command
+// prints, from the cache when it ran recently. A failing or slow command
+// gives no values.
+func completionCommandValues(command string) []string {
+	cwd, _ := os.Getwd()
+	sum := sha256.Sum256([]byte(cwd + "\x00" + command))
+	cachePath := ""
+	if dir, err := completionCacheDir(); err == nil {
+		cachePath = filepath.Join(dir, hex.EncodeToString(sum[:16]))
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < completionCacheTTL {
+			// #nosec G304 -- the cache file name is a hash drun derived itself.
+			if data, err := os.ReadFile(cachePath); err == nil {
+				return completionLines(string(data))
+			}
+		}
+	}
+
+	opts := shell.DefaultOptions()
+	opts.Timeout = completionCommandTimeout
+	result, err := shell.Execute(command, opts)
+	if err != nil || !result.Success {
+		return nil
+	}
+	if cachePath != "" && os.MkdirAll(filepath.Dir(cachePath), 0750) == nil {
+		_ = os.WriteFile(cachePath, []byte(result.Stdout), 0600)
+	}
+	return completionLines(result.Stdout)
+}
+
+func completionLines(output string) []string {
+	var values []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values
+}
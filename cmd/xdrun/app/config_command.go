@@ -0,0 +1,192 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Domain: Configuration
+// This file contains the cmd:config command that reads and writes the user
+// (~/.drun/config.yml) and repository (.drun/config.local.yml) settings.
+
+// createConfigCommand creates the cmd:config subcommand
+func (a *App) createConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cmd:config",
+		Short: "Read and change drun configuration defaults",
+		Long: `Read and change configuration defaults.
+
+Settings are read from ~/.drun/config.yml and then from .drun/config.local.yml
+in the current directory, whose values win. Command-line flags and the
+project's own settings take precedence over both.
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+	}
+
+	cmd.AddCommand(createConfigGetCommand())
+	cmd.AddCommand(createConfigSetCommand())
+	cmd.AddCommand(createConfigListCommand())
+
+	return cmd
+}
+
+func createConfigGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := lookupConfigKey(args[0])
+			if err != nil {
+				return err
+			}
+			config, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			if value, ok := key.get(config); ok {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), value)
+			}
+			return nil
+		},
+	}
+}
+
+func createConfigSetCommand() *cobra.Command {
+	var local bool
+
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting in the user or local configuration",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := localConfigPath
+			if !local {
+				var err error
+				if path, err = getUserConfigPath(); err != nil {
+					return err
+				}
+			}
+			if err := setConfigValue(path, args[0], args[1]); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Set %s to %s in %s\n", args[0], args[1], path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&local, "local", false, "Write to .drun/config.local.yml instead of ~/.drun/config.yml")
+
+	return cmd
+}
+
+func createConfigListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the settings with their effective values and sources",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userPath, err := getUserConfigPath()
+			if err != nil {
+				return err
+			}
+			return listConfig(cmd.OutOrStdout(), userPath, localConfigPath)
+		},
+	}
+}
+
+// listConfig writes every setting with the file its effective value comes from
+func listConfig(out io.Writer, userPath, localPath string) error {
+	user, err := loadConfigFile(userPath)
+	if err != nil {
+		return err
+	}
+	local, err := loadConfigFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range configKeys {
+		value, source := "(default)", ""
+		if v, ok := key.get(user); ok {
+			value, source = v, userPath
+		}
+		if v, ok := key.get(local); ok {
+			value, source = v, localPath
+		}
+		if source != "" {
+			source = "  # " + filepath.ToSlash(source)
+		}
+		_, _ = fmt.Fprintf(out, "%-16s %s%s\n", key.Name, value, source)
+		_, _ = fmt.Fprintf(out, "%-16s %s\n", "", key.Description)
+	}
+	return nil
+}
+
+// setConfigValue validates value and stores it under name in the YAML file
+// at path, keeping the file's other keys and comments
+func setConfigValue(path, name, value string) error {
+	key, err := lookupConfigKey(name)
+	if err != nil {
+		return err
+	}
+	if err := key.validate(value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	var doc yaml.Node
+	// #nosec G304 -- cmd:config intentionally edits the user and repository config files.
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping at the top level", path)
+	}
+
+	found := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			mapping.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+			found = true
+			break
+		}
+	}
+	if !found {
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value},
+		)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
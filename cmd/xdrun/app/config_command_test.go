@@ -0,0 +1,89 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetConfigValueKeepsOtherKeysAndComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	original := "# team defaults\nprovisioningSources:\n  - catalog.yaml\noutputStyle: emoji\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := setConfigValue(path, "outputStyle", "plain"); err != nil {
+		t.Fatalf("setConfigValue(outputStyle) error = %v", err)
+	}
+	if err := setConfigValue(path, "parallelism", "4"); err != nil {
+		t.Fatalf("setConfigValue(parallelism) error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "# team defaults\nprovisioningSources:\n  - catalog.yaml\noutputStyle: plain\nparallelism: 4\n"
+	if string(data) != want {
+		t.Fatalf("config file =\n%s\nwant\n%s", data, want)
+	}
+
+	config, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if config.OutputStyle != "plain" || config.Parallelism != 4 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+}
+
+func TestSetConfigValueValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yml")
+
+	if err := setConfigValue(path, "colour", "true"); err == nil || !strings.Contains(err.Error(), "unknown config key") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+	if err := setConfigValue(path, "parallelism", "zero"); err == nil || !strings.Contains(err.Error(), "invalid value for parallelism") {
+		t.Fatalf("expected invalid value error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no file to be written, got %v", err)
+	}
+
+	if err := setConfigValue(path, "includeCacheTTL", "10m"); err != nil {
+		t.Fatalf("setConfigValue() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "includeCacheTTL: 10m\n" {
+		t.Fatalf("unexpected new config file: %q", data)
+	}
+}
+
+func TestListConfigShowsSources(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user.yml")
+	localPath := filepath.Join(dir, "local.yml")
+	if err := os.WriteFile(userPath, []byte("outputStyle: plain\nparallelism: 2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(user) error = %v", err)
+	}
+	if err := os.WriteFile(localPath, []byte("parallelism: 6\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(local) error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := listConfig(&out, userPath, localPath); err != nil {
+		t.Fatalf("listConfig() error = %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"outputStyle      plain  # " + filepath.ToSlash(userPath),
+		"parallelism      6  # " + filepath.ToSlash(localPath),
+		"verbose          (default)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
@@ -26,6 +26,7 @@ func HandleDebugMode(
 	opts DebugOptions,
 ) error {
 	var content string
+	var sourceFile string
 
 	// Get content from input string or file
 	if debugInput != "" {
@@ -44,6 +45,7 @@ func HandleDebugMode(
 			return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
 		}
 		content = string(data)
+		sourceFile = actualConfigFile
 	}
 
 	// Handle specific debug flags
@@ -53,12 +55,18 @@ func HandleDebugMode(
 	}
 
 	// Handle individual debug flags
-	hasSpecificFlag := debugTokens || debugAST || debugJSON || debugErrors || debugDomain
+	hasSpecificFlag := debugTokens || debugAST || debugJSON || debugErrors || debugDomain || opts.ShowHooks
 
 	if debugTokens {
 		debug.DebugTokens(content)
 	}
 
+	if opts.ShowHooks {
+		if err := debugHookChain(os.Stdout, content, sourceFile); err != nil {
+			return fmt.Errorf("hook debug failed: %w", err)
+		}
+	}
+
 	var program *ast.Program
 	if debugAST || debugJSON || debugErrors || debugDomain {
 		// Parse without full debug output
@@ -10,6 +10,7 @@ import (
 	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/domain/task"
+	"github.com/phillarmonic/drun/v2/internal/engine/hooks"
 	"github.com/phillarmonic/drun/v2/internal/engine/planner"
 )
 
@@ -22,6 +23,7 @@ type DebugOptions struct {
 	ExportGraphviz string
 	ExportMermaid  string
 	ExportJSON     string
+	ShowHooks      bool // print the effective lifecycle hook chain
 }
 
 // debugDomainLayer initializes domain services and shows their state
@@ -135,24 +137,27 @@ func debugDomainLayer(program *ast.Program, currentFile string, opts DebugOption
 					Version: program.Project.Version,
 				}
 				// Convert lifecycle hooks if present
+				hookMgr := hooks.NewManager()
 				for _, setting := range program.Project.Settings {
 					if hook, ok := setting.(*ast.LifecycleHook); ok {
 						hookBody, err := statement.FromASTList(hook.Body)
 						if err != nil {
 							continue
 						}
-						switch hook.Type {
-						case "setup":
-							projectCtx.SetupHooks = append(projectCtx.SetupHooks, hookBody...)
-						case "teardown":
-							projectCtx.TeardownHooks = append(projectCtx.TeardownHooks, hookBody...)
-						case "before":
-							projectCtx.BeforeHooks = append(projectCtx.BeforeHooks, hookBody...)
-						case "after":
-							projectCtx.AfterHooks = append(projectCtx.AfterHooks, hookBody...)
+						registered := hooks.Hook{Priority: hook.Priority, Body: hookBody}
+						if hook.Scope == "task" || hook.Scope == "matching" {
+							registered.Pattern = hook.Target
+							registered.Glob = hook.Scope == "matching"
 						}
+						hookMgr.RegisterHook(hook.Type, registered)
 					}
 				}
+				projectCtx.SetupHooks = hookMgr.GetSetupHooks()
+				projectCtx.TeardownHooks = hookMgr.GetTeardownHooks()
+				projectCtx.BeforeHooks = hookMgr.GetBeforeHooks()
+				projectCtx.AfterHooks = hookMgr.GetAfterHooks()
+				projectCtx.BeforeHookChain = hookMgr.Chain("before")
+				projectCtx.AfterHookChain = hookMgr.Chain("after")
 			}
 
 			// Generate execution plan
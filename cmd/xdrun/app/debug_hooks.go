@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/engine/hooks"
+)
+
+// Domain: Hook Debugging
+// This file contains the --debug-hooks view of the effective lifecycle hook
+// chain, including hooks contributed by included files.
+
+// hookPhases lists hook types in the order they run around a task
+var hookPhases = []struct {
+	hookType string
+	title    string
+}{
+	{"setup", "on drun setup"},
+	{"before", "before task"},
+	{"after", "after task"},
+	{"teardown", "on drun teardown"},
+}
+
+// debugHookChain resolves the project's hooks (including includes) and
+// prints them in execution order
+func debugHookChain(out io.Writer, content, sourceFile string) error {
+	program, err := engine.ParseStringWithFilename(content, sourceFile)
+	if err != nil {
+		return err
+	}
+
+	eng := engine.NewEngine(io.Discard)
+	defer eng.Cleanup()
+
+	projectCtx, err := eng.BuildProjectContext(program.Project, sourceFile)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out, "=== LIFECYCLE HOOKS ===")
+	if projectCtx == nil || projectCtx.HookManager == nil {
+		_, _ = fmt.Fprintln(out, "No project declaration - no hooks")
+		return nil
+	}
+	renderHookChain(out, projectCtx.HookManager)
+	return nil
+}
+
+// renderHookChain prints each hook phase with its hooks in execution order
+func renderHookChain(out io.Writer, mgr *hooks.Manager) {
+	for _, phase := range hookPhases {
+		chain := mgr.Chain(phase.hookType)
+		_, _ = fmt.Fprintf(out, "%s (%d):\n", phase.title, len(chain))
+		for i, hook := range chain {
+			_, _ = fmt.Fprintf(out, "  %d. %s, priority %d, from %s\n",
+				i+1, hookScopeLabel(phase.hookType, hook), hook.Priority, hook.Source)
+		}
+	}
+}
+
+// hookScopeLabel describes which tasks a hook applies to
+func hookScopeLabel(hookType string, hook hooks.Hook) string {
+	switch {
+	case hookType == "setup" || hookType == "teardown":
+		return "once"
+	case !hook.Scoped():
+		return "any task"
+	case hook.Glob:
+		return fmt.Sprintf("tasks matching %q", hook.Pattern)
+	default:
+		return fmt.Sprintf("task %q", hook.Pattern)
+	}
+}
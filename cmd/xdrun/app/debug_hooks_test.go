@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDebugHookChainShowsEffectiveOrder(t *testing.T) {
+	root := t.TempDir()
+	shared := `version: 2.0
+
+project "shared":
+  before task "deploy" priority 20:
+    info "auth"
+
+task "deploy":
+  info "deploy"
+`
+	if err := os.WriteFile(filepath.Join(root, "shared.drun"), []byte(shared), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := `version: 2.0
+
+project "app":
+  on drun setup:
+    info "setup"
+
+  before any task:
+    info "before"
+
+  include "shared.drun"
+
+  after tasks matching "build-*" priority 5:
+    info "after build"
+
+task "build-api":
+  info "build"
+`
+	specFile := filepath.Join(root, "spec.drun")
+
+	var out bytes.Buffer
+	if err := debugHookChain(&out, spec, specFile); err != nil {
+		t.Fatalf("debugHookChain failed: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"on drun setup (1):",
+		"1. once, priority 0, from spec.drun:4",
+		"before task (2):",
+		`1. task "shared.deploy", priority 20, from shared (shared.drun:4)`,
+		"2. any task, priority 0, from spec.drun:7",
+		"after task (1):",
+		`1. tasks matching "build-*", priority 5, from spec.drun:12`,
+		"on drun teardown (0):",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Dependency Graph
+// This file contains the cmd:deps command that draws a task's dependency
+// graph in the terminal.
+
+// createDepsCommand creates the cmd:deps subcommand
+func (a *App) createDepsCommand() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "cmd:deps <task>",
+		Short: "Show a task's dependency graph in the terminal",
+		Long: `Show a task's dependency graph as a tree, followed by the order the tasks run in.
+
+Dependencies that may run in parallel ("depends on lint in parallel, test in
+parallel") are grouped under a "⇉ in parallel" node. A dependency that is
+already on the current path is marked as a cycle, and one that does not
+exist as not found.
+
+To write the execution plan to a file, use --debug-export-graph or
+--debug-export-mermaid.
+
+Examples:
+  xdrun cmd:deps deploy               # Draw the dependency graph of deploy
+  xdrun cmd:deps -f ci.drun release   # Use a specific task file
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: CompleteTaskNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runDeps(cmd.OutOrStdout(), configFile, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+
+	return cmd
+}
+
+// runDeps draws the dependency graph of taskName from the task file
+func runDeps(out io.Writer, configFile, taskName string) error {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:deps intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	tasks, err := engine.NewEngine(io.Discard).ListTasksWithIncludes(program, actualConfigFile)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]engine.TaskInfo, len(tasks))
+	for _, info := range tasks {
+		if _, exists := byName[info.Name]; !exists {
+			byName[info.Name] = info
+		}
+	}
+	root, found := byName[taskName]
+	if !found {
+		return fmt.Errorf("task '%s' not found", taskName)
+	}
+
+	_, _ = fmt.Fprintln(out, root.Name)
+	writeDependencyGraph(out, root, byName, "", map[string]bool{root.Name: true})
+
+	var order []string
+	dependencyOrder(root, byName, map[string]bool{}, &order)
+	_, _ = fmt.Fprintf(out, "\nExecution order: %s\n", strings.Join(order, " → "))
+	return nil
+}
+
+// writeDependencyGraph writes the dependency groups of info as a tree; groups
+// with several tasks are drawn under an "in parallel" node
+func writeDependencyGraph(out io.Writer, info engine.TaskInfo, byName map[string]engine.TaskInfo, prefix string, path map[string]bool) {
+	for i, group := range info.DependencyGroups {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(info.DependencyGroups)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+
+		if len(group) == 1 {
+			writeDependencyNode(out, info, group[0], byName, prefix, connector, childPrefix, path)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "%s%s⇉ in parallel\n", prefix, connector)
+		for j, depName := range group {
+			memberConnector, memberPrefix := "├── ", childPrefix+"│   "
+			if j == len(group)-1 {
+				memberConnector, memberPrefix = "└── ", childPrefix+"    "
+			}
+			writeDependencyNode(out, info, depName, byName, childPrefix, memberConnector, memberPrefix, path)
+		}
+	}
+}
+
+// writeDependencyNode writes one dependency of info and, unless it is missing
+// or closes a cycle, its own dependencies
+func writeDependencyNode(out io.Writer, info engine.TaskInfo, depName string, byName map[string]engine.TaskInfo, prefix, connector, childPrefix string, path map[string]bool) {
+	dep, found := lookupDependency(info, depName, byName)
+	switch {
+	case !found:
+		_, _ = fmt.Fprintf(out, "%s%s%s (not found)\n", prefix, connector, depName)
+	case path[dep.Name]:
+		_, _ = fmt.Fprintf(out, "%s%s%s (cycle)\n", prefix, connector, dep.Name)
+	default:
+		_, _ = fmt.Fprintf(out, "%s%s%s\n", prefix, connector, dep.Name)
+		path[dep.Name] = true
+		writeDependencyGraph(out, dep, byName, childPrefix, path)
+		delete(path, dep.Name)
+	}
+}
+
+// dependencyOrder appends info's dependencies and then info to order, each
+// task once, in the order they run
+func dependencyOrder(info engine.TaskInfo, byName map[string]engine.TaskInfo, visited map[string]bool, order *[]string) {
+	if visited[info.Name] {
+		return
+	}
+	visited[info.Name] = true
+	for _, depName := range info.Dependencies {
+		if dep, found := lookupDependency(info, depName, byName); found {
+			dependencyOrder(dep, byName, visited, order)
+		}
+	}
+	*order = append(*order, info.Name)
+}
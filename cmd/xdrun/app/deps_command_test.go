@@ -0,0 +1,71 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunDepsDrawsParallelGroups(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+task "prepare":
+  info "prepare"
+
+task "lint":
+  depends on prepare
+  info "lint"
+
+task "test":
+  depends on prepare
+  info "test"
+
+task "deploy":
+  depends on lint in parallel, test in parallel
+  info "deploy"
+`)
+
+	var out bytes.Buffer
+	if err := runDeps(&out, "", "deploy"); err != nil {
+		t.Fatalf("runDeps() error = %v", err)
+	}
+
+	want := `deploy
+└── ⇉ in parallel
+    ├── lint
+    │   └── prepare
+    └── test
+        └── prepare
+
+Execution order: prepare → lint → test → deploy`
+	if got := strings.TrimSpace(out.String()); got != want {
+		t.Errorf("runDeps() output =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunDepsMarksCyclesAndUnknownTasks(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+task "a":
+  depends on b
+  info "a"
+
+task "b":
+  depends on a and ghost
+  info "b"
+`)
+
+	var out bytes.Buffer
+	if err := runDeps(&out, "", "a"); err != nil {
+		t.Fatalf("runDeps() error = %v", err)
+	}
+	for _, want := range []string{"    ├── a (cycle)", "    └── ghost (not found)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("runDeps() output ghost %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := runDeps(&out, "", "nope"); err == nil || !strings.Contains(err.Error(), "task 'nope' not found") {
+		t.Errorf("runDeps() error = %v, want task not found", err)
+	}
+}
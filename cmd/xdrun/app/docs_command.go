@@ -0,0 +1,257 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Documentation Generation
+// This file contains the cmd:docs command that renders task reference
+// documentation from the AST and the tasks' doc: blocks.
+
+// createDocsCommand creates the cmd:docs subcommand
+func (a *App) createDocsCommand() *cobra.Command {
+	var configFile string
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "cmd:docs",
+		Short: "Generate markdown reference documentation for tasks",
+		Long: `Generate markdown reference documentation for every task in the drun file:
+descriptions, doc: blocks, parameters, dependencies and example invocations.
+
+Examples:
+  xdrun cmd:docs                          # Print task documentation to stdout
+  xdrun cmd:docs --output docs/tasks.md   # Write task documentation to a file
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runDocs(cmd.OutOrStdout(), configFile, outputPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write the documentation to this file instead of stdout")
+
+	return cmd
+}
+
+// runDocs renders the task documentation to out, or to outputPath when set
+func runDocs(out io.Writer, configFile, outputPath string) error {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:docs intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	docs := renderTaskDocs(program)
+	if outputPath == "" {
+		_, _ = io.WriteString(out, docs)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	// #nosec G306 -- generated documentation is meant to be committed and shared.
+	if err := os.WriteFile(outputPath, []byte(docs), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	_, _ = fmt.Fprintf(out, "✅ Wrote documentation for %d tasks to %s\n", len(program.Tasks), outputPath)
+	return nil
+}
+
+// renderTaskDocs renders markdown reference documentation for the program's
+// tasks in declaration order
+func renderTaskDocs(program *ast.Program) string {
+	var out strings.Builder
+
+	title := "Tasks"
+	if program.Project != nil && program.Project.Name != "" {
+		title = program.Project.Name + " tasks"
+	}
+	fmt.Fprintf(&out, "# %s\n", title)
+
+	if len(program.Tasks) == 0 {
+		out.WriteString("\nNo tasks defined.\n")
+		return out.String()
+	}
+
+	out.WriteString("\n")
+	for _, task := range program.Tasks {
+		fmt.Fprintf(&out, "- [%s](#%s)\n", task.Name, markdownAnchor(task.Name))
+	}
+
+	for _, task := range program.Tasks {
+		fmt.Fprintf(&out, "\n## %s\n", task.Name)
+		if task.Description != "" {
+			fmt.Fprintf(&out, "\n%s\n", task.Description)
+		}
+		if task.Doc != "" {
+			fmt.Fprintf(&out, "\n%s\n", demoteHeadings(task.Doc, 2))
+		}
+
+		if len(task.Parameters) > 0 {
+			out.WriteString("\n### Parameters\n\n")
+			out.WriteString("| Name | Required | Default | Allowed values | Type |\n")
+			out.WriteString("|------|----------|---------|----------------|------|\n")
+			for _, param := range task.Parameters {
+				fmt.Fprintf(&out, "| `%s` | %s | %s | %s | %s |\n",
+					param.Name,
+					yesNo(param.Type == "requires" && !param.HasDefault),
+					markdownCode(param.DefaultValue, param.HasDefault),
+					markdownCodeList(param.Constraints),
+					parameterTypeLabel(param),
+				)
+			}
+		}
+
+		if deps := taskDependencyNames(task); len(deps) > 0 {
+			out.WriteString("\n### Dependencies\n\n")
+			for _, dep := range deps {
+				fmt.Fprintf(&out, "- `%s`\n", dep)
+			}
+		}
+
+		out.WriteString("\n### Example\n\n```bash\n")
+		out.WriteString(exampleInvocation(task))
+		out.WriteString("\n```\n")
+	}
+
+	return out.String()
+}
+
+// demoteHeadings pushes markdown ATX headings down by levels so a doc block's
+// own headings nest under the task heading; fenced code is left untouched
+func demoteHeadings(doc string, levels int) string {
+	lines := strings.Split(doc, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		hashes := len(line) - len(strings.TrimLeft(line, "#"))
+		if hashes <= 6 && (len(line) == hashes || line[hashes] == ' ') {
+			demoted := hashes + levels
+			if demoted > 6 {
+				demoted = 6
+			}
+			lines[i] = strings.Repeat("#", demoted) + line[hashes:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// taskDependencyNames returns the names of the tasks a task depends on
+func taskDependencyNames(task *ast.TaskStatement) []string {
+	var names []string
+	for _, group := range task.Dependencies {
+		for _, dep := range group.Dependencies {
+			names = append(names, dep.Name)
+		}
+	}
+	return names
+}
+
+// exampleInvocation builds an xdrun command line that satisfies the task's
+// required parameters
+func exampleInvocation(task *ast.TaskStatement) string {
+	parts := []string{"xdrun", quoteTaskArg(task.Name)}
+	for _, param := range task.Parameters {
+		if param.Type != "requires" || param.HasDefault {
+			continue
+		}
+		value := "<" + param.Name + ">"
+		if len(param.Constraints) > 0 {
+			value = param.Constraints[0]
+		}
+		parts = append(parts, param.Name+"="+quoteTaskArg(value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parameterTypeLabel describes a parameter's data type for the docs table
+func parameterTypeLabel(param ast.ParameterStatement) string {
+	label := param.DataType
+	if label == "" {
+		label = "string"
+	}
+	if param.Variadic {
+		label += " (variadic)"
+	}
+	return label
+}
+
+// quoteTaskArg quotes a command line argument when it contains spaces or
+// shell metacharacters
+func quoteTaskArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'<>|&;$`\\") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// markdownAnchor returns the GitHub-style heading anchor for a task name
+func markdownAnchor(name string) string {
+	var out strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == ' ':
+			out.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+func markdownCode(value string, present bool) string {
+	if !present {
+		return "-"
+	}
+	return "`" + value + "`"
+}
+
+func markdownCodeList(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "`" + value + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDocsRendersTaskReference(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+project "shop":
+
+task "build":
+  info "building"
+
+task "deploy api" means "Deploy the API":
+  doc:
+    Rolls out the API to Kubernetes.
+
+    # Rollback
+    Run `+"`kubectl rollout undo`"+` if health checks fail.
+
+    `+"```bash"+`
+    # not a heading
+    `+"```"+`
+  depends on build
+  requires $env from ["staging", "production"]
+  given $replicas defaults to "3"
+
+  info "deploying"
+`)
+
+	var out bytes.Buffer
+	if err := runDocs(&out, "", filepath.Join("docs", "tasks.md")); err != nil {
+		t.Fatalf("runDocs() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join("docs", "tasks.md"))
+	if err != nil {
+		t.Fatalf("expected generated docs file: %v", err)
+	}
+	docs := string(content)
+
+	for _, want := range []string{
+		"# shop tasks\n",
+		"- [deploy api](#deploy-api)\n",
+		"## deploy api\n\nDeploy the API\n\nRolls out the API to Kubernetes.\n\n### Rollback\n",
+		"```bash\n# not a heading\n```\n",
+		"| `env` | yes | - | `staging`, `production` | string |\n",
+		"| `replicas` | no | `3` | - | string |\n",
+		"### Dependencies\n\n- `build`\n",
+		"```bash\nxdrun 'deploy api' env=staging\n```\n",
+		"```bash\nxdrun build\n```\n",
+	} {
+		if !strings.Contains(docs, want) {
+			t.Errorf("generated docs missing %q:\n%s", want, docs)
+		}
+	}
+	if !strings.Contains(out.String(), "Wrote documentation for 2 tasks") {
+		t.Errorf("unexpected command output: %s", out.String())
+	}
+}
+
+func TestRunDocsPrintsToStdout(t *testing.T) {
+	withCompletionSpec(t, "version: 2.0\n\ntask \"hello\":\n  info \"hi\"\n")
+
+	var out bytes.Buffer
+	if err := runDocs(&out, "", ""); err != nil {
+		t.Fatalf("runDocs() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "# Tasks\n") || !strings.Contains(out.String(), "## hello\n") {
+		t.Errorf("unexpected docs output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Environment Provisioning
+// This file contains the cmd:env command that provisions the toolchain
+// declared in the project's environment: block.
+
+// environmentStep is one provisioning command for an environment entry
+type environmentStep struct {
+	Entry   ast.EnvironmentEntry
+	Command string
+}
+
+// createEnvCommand creates the cmd:env subcommand
+func (a *App) createEnvCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cmd:env",
+		Short: "Provision the toolchain declared in the environment block",
+		Long: `Provision the toolchain declared in the project's environment: block.
+
+'up' pulls the declared images with docker, installs the declared asdf
+versions and installs the declared nix packages, in the order they are
+declared, so tasks find them before they run.
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+	}
+
+	cmd.AddCommand(a.createEnvUpCommand())
+
+	return cmd
+}
+
+func (a *App) createEnvUpCommand() *cobra.Command {
+	var configFile string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Pull images and install versions declared in the environment block",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runEnvUp(cmd.OutOrStdout(), configFile, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the provisioning commands without running them")
+
+	return cmd
+}
+
+// runEnvUp provisions every entry of the environment block of the task file
+func runEnvUp(out io.Writer, configFile string, dryRun bool) error {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:env intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	var env *ast.EnvironmentStatement
+	if program.Project != nil {
+		env = program.Project.Environment()
+	}
+	if env == nil {
+		_, _ = fmt.Fprintf(out, "No environment: block declared in %s\n", actualConfigFile)
+		return nil
+	}
+
+	for _, step := range environmentSteps(env.Entries) {
+		if dryRun {
+			_, _ = fmt.Fprintf(out, "[DRY RUN] %s: %s\n", step.Entry, step.Command)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "📦 %s\n", step.Entry)
+		opts := shell.DefaultOptions()
+		opts.CaptureOutput = false
+		opts.StreamOutput = true
+		opts.Output = out
+		if _, err := shell.Execute(step.Command, opts); err != nil {
+			return fmt.Errorf("failed to provision %s: %w", step.Entry, err)
+		}
+	}
+
+	if !dryRun {
+		_, _ = fmt.Fprintf(out, "✅ Environment is up (%d entries)\n", len(env.Entries))
+	}
+	return nil
+}
+
+// environmentSteps returns the commands that provision entries, in order
+func environmentSteps(entries []ast.EnvironmentEntry) []environmentStep {
+	var steps []environmentStep
+	for _, entry := range entries {
+		switch entry.Kind {
+		case "image":
+			steps = append(steps, environmentStep{entry, "docker pull " + shellQuote(entry.Name)})
+		case "asdf":
+			name := shellQuote(entry.Name)
+			steps = append(steps,
+				environmentStep{entry, "asdf plugin add " + name + " || true"},
+				environmentStep{entry, "asdf install " + name + " " + shellQuote(entry.Version)},
+			)
+		case "nix":
+			steps = append(steps, environmentStep{entry, "nix profile install " + shellQuote("nixpkgs#"+entry.Name)})
+		}
+	}
+	return steps
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunEnvUpDryRunListsProvisioningCommands(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+project "app":
+  environment:
+    image "golang:1.22"
+    asdf nodejs "20.11.0"
+    nix jq
+
+task "build":
+  run "go build ./..."
+`)
+
+	var out bytes.Buffer
+	if err := runEnvUp(&out, "", true); err != nil {
+		t.Fatalf("runEnvUp() error = %v\n%s", err, out.String())
+	}
+
+	want := []string{
+		`[DRY RUN] image "golang:1.22": docker pull 'golang:1.22'`,
+		`[DRY RUN] asdf nodejs "20.11.0": asdf plugin add 'nodejs' || true`,
+		`[DRY RUN] asdf nodejs "20.11.0": asdf install 'nodejs' '20.11.0'`,
+		`[DRY RUN] nix jq: nix profile install 'nixpkgs#jq'`,
+	}
+	if got := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("runEnvUp() output =\n%s\nwant:\n%s", out.String(), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunEnvUpWithoutEnvironmentBlock(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+task "build":
+  run "go build ./..."
+`)
+
+	var out bytes.Buffer
+	if err := runEnvUp(&out, "", false); err != nil {
+		t.Fatalf("runEnvUp() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No environment: block") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
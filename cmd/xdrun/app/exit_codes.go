@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/task"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	drunErrors "github.com/phillarmonic/drun/v2/internal/errors"
+	"github.com/phillarmonic/drun/v2/internal/gitpolicy"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+)
+
+// Domain: Exit Codes
+// This file contains the exit codes xdrun uses so scripts can branch on the
+// class of a failure, and the --exit-codes listing.
+
+// Exit codes of the xdrun process
+const (
+	ExitOK              = 0
+	ExitError           = 1 // any failure without a more specific code
+	ExitParseError      = 2
+	ExitValidationError = 3
+	ExitTaskFailure     = 4
+	ExitDependencyCycle = 5
+	ExitPolicyViolation = 6
+	ExitTimedOut        = 124 // like timeout(1)
+	ExitCancelled       = 130
+)
+
+// exitCodeDocs lists the exit codes for --exit-codes, in numeric order
+var exitCodeDocs = []struct {
+	code        int
+	name        string
+	description string
+}{
+	{ExitOK, "success", "every requested task succeeded"},
+	{ExitError, "error", "any other failure, such as a missing task file or an invalid flag"},
+	{ExitParseError, "parse error", "the task file has syntax errors"},
+	{ExitValidationError, "validation error", "a parameter value, task name or dependency is invalid"},
+	{ExitTaskFailure, "task failure", "a task, hook or command failed while running"},
+	{ExitDependencyCycle, "dependency cycle", "task dependencies form a cycle"},
+	{ExitPolicyViolation, "policy violation", "the branch or commit breaks the project's git policy"},
+	{ExitTimedOut, "timed out", "the run took longer than --max-duration or the project's max duration"},
+	{ExitCancelled, "cancelled", "the run was interrupted (Ctrl+C or SIGTERM)"},
+}
+
+// exitCodeError attaches an exit code to an error returned by the CLI
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// withExitCode makes err exit with code unless it matches a more specific
+// class, as decided by ExitCode
+func withExitCode(code int, err error) error {
+	return &exitCodeError{code: code, err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by the CLI.
+// Cancellation wins over every other class, so a run interrupted inside a
+// failing task still exits with 130.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var parseErrors *drunErrors.ParseErrorList
+	var parseError *drunErrors.ParseError
+	var paramError *drunErrors.ParameterValidationError
+	var validationError *drunErrors.ValidationError
+	var violation *gitpolicy.ViolationError
+	var coded *exitCodeError
+	switch {
+	case errors.Is(err, shell.ErrInterrupted):
+		return ExitCancelled
+	case errors.Is(err, engine.ErrMaxDuration):
+		return ExitTimedOut
+	case errors.As(err, &parseErrors), errors.As(err, &parseError):
+		return ExitParseError
+	case errors.Is(err, task.ErrCircularDependency):
+		return ExitDependencyCycle
+	case errors.As(err, &violation):
+		return ExitPolicyViolation
+	case errors.As(err, &paramError), errors.As(err, &validationError):
+		return ExitValidationError
+	case errors.As(err, &coded):
+		return coded.code
+	}
+	return ExitError
+}
+
+// PrintExitCodes writes the --exit-codes listing
+func PrintExitCodes(out io.Writer) error {
+	_, _ = fmt.Fprintln(out, "xdrun exit codes:")
+	for _, doc := range exitCodeDocs {
+		_, _ = fmt.Fprintf(out, "  %-4d %-17s %s\n", doc.code, doc.name, doc.description)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/task"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	drunErrors "github.com/phillarmonic/drun/v2/internal/errors"
+	"github.com/phillarmonic/drun/v2/internal/gitpolicy"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+)
+
+func TestExitCode(t *testing.T) {
+	cycle := &task.TaskError{Task: "a", Message: "circular dependency detected: a -> b", Kind: task.ErrCircularDependency}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"other", errors.New("no drun task file found"), ExitError},
+		{"parse", fmt.Errorf("failed to parse: %w", &drunErrors.ParseErrorList{}), ExitParseError},
+		{"parameter", drunErrors.NewParameterValidationError("required parameter 'env' not provided"), ExitValidationError},
+		{"unknown task", drunErrors.NewValidationError(errors.New("task 'x' not found")), ExitValidationError},
+		{"task failure", withExitCode(ExitTaskFailure, errors.New("task 'build' failed")), ExitTaskFailure},
+		{"cycle", withExitCode(ExitTaskFailure, drunErrors.NewValidationError(fmt.Errorf("execution planning failed: %w", cycle))), ExitDependencyCycle},
+		{"policy", withExitCode(ExitTaskFailure, fmt.Errorf("task 'commit' failed: %w", &gitpolicy.ViolationError{Err: errors.New("branch is protected")})), ExitPolicyViolation},
+		{"timed out", withExitCode(ExitTaskFailure, fmt.Errorf("task 'deploy' failed: %w (30m0s)", engine.ErrMaxDuration)), ExitTimedOut},
+		{"cancelled", withExitCode(ExitTaskFailure, fmt.Errorf("task 'dev' failed: command %w (exit code 130)", shell.ErrInterrupted)), ExitCancelled},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrintExitCodes(t *testing.T) {
+	var out bytes.Buffer
+	if err := PrintExitCodes(&out); err != nil {
+		t.Fatalf("PrintExitCodes() error = %v", err)
+	}
+	for _, want := range []string{"2    parse error", "5    dependency cycle", "124  timed out", "130  cancelled"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
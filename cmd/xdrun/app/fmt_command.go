@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Formatting
+// This file contains the cmd:fmt command, which rewrites drun files in the
+// standard layout: UTF-8 without a byte order mark, LF line endings and one
+// trailing newline.
+
+// createFmtCommand creates the cmd:fmt subcommand
+func (a *App) createFmtCommand() *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:fmt [files...]",
+		Short: "Standardize the line endings of drun files",
+		Long: `Rewrite drun files with LF line endings, without a UTF-8 byte order mark
+and with a single trailing newline.
+
+drun reads files authored on Windows (CRLF line endings, byte order mark)
+like any other, so formatting only keeps diffs and editors consistent.
+Without arguments, the task file is formatted.
+
+Examples:
+  xdrun cmd:fmt                          # Format the task file
+  xdrun cmd:fmt .drun/*.drun             # Format several files
+  xdrun cmd:fmt --check .drun/*.drun     # Fail when a file is not formatted (for CI)
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				actualConfigFile, err := FindConfigFile(a.configFile)
+				if err != nil {
+					return fmt.Errorf("no drun task file found: %w", err)
+				}
+				files = []string{actualConfigFile}
+			}
+			return runFmt(cmd.OutOrStdout(), files, check)
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "List files that are not formatted and fail instead of rewriting them")
+
+	return cmd
+}
+
+// runFmt formats files, or with check only lists those that need it
+func runFmt(out io.Writer, files []string, check bool) error {
+	var unformatted []string
+	for _, file := range files {
+		// #nosec G304 -- cmd:fmt intentionally reads the drun files it is given.
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read drun file '%s': %w", file, err)
+		}
+		formatted := formatSource(string(content))
+		if formatted == string(content) {
+			continue
+		}
+		unformatted = append(unformatted, file)
+		if check {
+			_, _ = fmt.Fprintln(out, file)
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, []byte(formatted), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write drun file '%s': %w", file, err)
+		}
+		_, _ = fmt.Fprintf(out, "Formatted %s\n", file)
+	}
+	if check && len(unformatted) > 0 {
+		return fmt.Errorf("%d of %d files are not formatted; run xdrun cmd:fmt to fix them", len(unformatted), len(files))
+	}
+	return nil
+}
+
+// formatSource returns source in the standard layout
+func formatSource(source string) string {
+	formatted := strings.TrimRight(lexer.Normalize(source), "\n")
+	if formatted == "" {
+		return ""
+	}
+	return formatted + "\n"
+}
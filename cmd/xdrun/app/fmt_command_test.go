@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunFmtStandardizesLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	windows := filepath.Join(dir, "windows.drun")
+	unix := filepath.Join(dir, "unix.drun")
+	source := "version: 2.0\n\ntask \"build\":\n  info \"hi\"\n"
+	if err := os.WriteFile(windows, []byte("\ufeff"+strings.ReplaceAll(source, "\n", "\r\n")+"\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(unix, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err := runFmt(&out, []string{windows, unix}, true)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 files are not formatted") {
+		t.Fatalf("runFmt(check) error = %v", err)
+	}
+	if out.String() != windows+"\n" {
+		t.Errorf("runFmt(check) listed %q, want only the windows file", out.String())
+	}
+	if data, _ := os.ReadFile(windows); !bytes.HasPrefix(data, []byte("\ufeff")) {
+		t.Error("runFmt(check) rewrote the file")
+	}
+
+	out.Reset()
+	if err := runFmt(&out, []string{windows, unix}, false); err != nil {
+		t.Fatalf("runFmt() error = %v", err)
+	}
+	if data, _ := os.ReadFile(windows); string(data) != source {
+		t.Errorf("formatted file = %q, want %q", data, source)
+	}
+	if err := runFmt(&out, []string{windows, unix}, true); err != nil {
+		t.Errorf("runFmt(check) after formatting error = %v", err)
+	}
+}
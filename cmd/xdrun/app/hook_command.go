@@ -190,7 +190,7 @@ func createHookRunCommand(a *App) *cobra.Command {
 
 				if err := policy.ValidateProtectedBranchCommit(branchName); err != nil {
 					fmt.Printf("❌ Protected branch commit blocked: %v\n", err)
-					return err
+					return &gitpolicy.ViolationError{Err: err}
 				}
 
 				fmt.Println("✅ Protected branch check passed.")
@@ -215,13 +215,13 @@ func createHookRunCommand(a *App) *cobra.Command {
 
 				if err := policy.ValidateBranchName(branchName); err != nil {
 					fmt.Printf("❌ Invalid branch name: %v\n", err)
-					return err
+					return &gitpolicy.ViolationError{Err: err}
 				}
 
 				// Then validate commit message
 				if err := policy.ValidateCommitMessage(string(msgBytes), branchName); err != nil {
 					fmt.Printf("❌ Invalid commit message: %v\n", err)
-					return err
+					return &gitpolicy.ViolationError{Err: err}
 				}
 
 				fmt.Println("✅ Git policy checks passed.")
@@ -237,7 +237,7 @@ func createHookRunCommand(a *App) *cobra.Command {
 					sigStatus := strings.TrimSpace(out)
 					if sigStatus != "G" && sigStatus != "U" {
 						fmt.Printf("❌ Commit is not properly signed (signature status: '%s')\n", sigStatus)
-						return fmt.Errorf("unsigned commit")
+						return &gitpolicy.ViolationError{Err: fmt.Errorf("unsigned commit")}
 					}
 					fmt.Println("✅ Signed commits check passed.")
 				}
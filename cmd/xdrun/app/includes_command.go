@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/remote"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Include Versions
+// This file contains the cmd:includes command that reports drunhub includes
+// with newer published releases.
+
+// includeTagsFunc lists the published tags of the repository behind a
+// remote include URL
+type includeTagsFunc func(url string) ([]string, error)
+
+// createIncludesCommand creates the cmd:includes subcommand
+func (a *App) createIncludesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cmd:includes",
+		Short: "Inspect the versions of drunhub includes",
+		Long: `Inspect the versions of drunhub includes.
+
+A drunhub include may pin a version range such as ^1.2 (1.2.0 up to 2.0.0),
+~1.2 (1.2.x), 1.x or ">=1.2 <2". drun resolves the range to the highest
+matching tag published in drun-hub; cmd:vendor records that tag in
+vendor/drun/drun.lock.
+
+Examples:
+  xdrun cmd:includes outdated    # List drunhub includes with newer releases
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+	}
+
+	cmd.AddCommand(a.createIncludesOutdatedCommand())
+
+	return cmd
+}
+
+func (a *App) createIncludesOutdatedCommand() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "List drunhub includes with newer published releases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runIncludesOutdated(cmd.OutOrStdout(), configFile, listIncludeTags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+
+	return cmd
+}
+
+// runIncludesOutdated writes the current, newest compatible and latest
+// release of every versioned drunhub include
+func runIncludesOutdated(out io.Writer, configFile string, tags includeTagsFunc) error {
+	specFile, urls, err := loadRemoteIncludes(configFile)
+	if err != nil {
+		return err
+	}
+
+	lock, err := remote.LoadVendorLock(remote.VendorRoot(specFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	type row struct{ url, current, compatible, latest string }
+	var rows []row
+	outdated := 0
+	for _, url := range urls {
+		protocol, _, ref, err := remote.ParseRemoteURL(url)
+		if err != nil {
+			return err
+		}
+		// Unversioned drunhub includes use the built-in library or the default branch
+		if protocol != "drunhub" || ref == "" {
+			continue
+		}
+
+		published, err := tags(url)
+		if err != nil {
+			return fmt.Errorf("failed to list releases of %s: %w", url, err)
+		}
+
+		r := row{url: url, current: ref, compatible: "-", latest: "-"}
+		if latest, ok := remote.LatestTag(published); ok {
+			r.latest = latest
+		}
+
+		compatibleRange := ref
+		if !remote.IsVersionRange(ref) {
+			// An exact version stays compatible within its major version
+			if _, ok := remote.TagVersion(ref); ok {
+				compatibleRange = "^" + ref
+			} else {
+				compatibleRange = ""
+			}
+		}
+		if compatibleRange != "" {
+			if compatible, err := remote.ResolveVersionRange(compatibleRange, published); err == nil {
+				r.compatible = compatible
+			}
+		}
+
+		if remote.IsVersionRange(ref) {
+			// A vendored range stays on the recorded version until cmd:vendor runs again
+			r.current = r.compatible
+			if entry, ok := lock.Lookup(url); ok && entry.Version != "" {
+				r.current = entry.Version
+			}
+		}
+
+		if newerTag(r.compatible, r.current) || newerTag(r.latest, r.current) {
+			outdated++
+		}
+		rows = append(rows, r)
+	}
+
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(out, "No versioned drunhub includes")
+		return nil
+	}
+
+	width := len("INCLUDE")
+	for _, r := range rows {
+		width = max(width, len(r.url))
+	}
+	_, _ = fmt.Fprintf(out, "%-*s  %-10s  %-10s  %-10s\n", width, "INCLUDE", "CURRENT", "COMPATIBLE", "LATEST")
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(out, "%-*s  %-10s  %-10s  %-10s\n", width, r.url, r.current, r.compatible, r.latest)
+	}
+
+	if outdated == 0 {
+		_, _ = fmt.Fprintln(out, "\nAll drunhub includes are up to date")
+	} else {
+		_, _ = fmt.Fprintf(out, "\n%d of %d drunhub includes have newer releases\n", outdated, len(rows))
+	}
+	return nil
+}
+
+// newerTag reports whether the version tag candidate is newer than current
+func newerTag(candidate, current string) bool {
+	candidateVersion, ok := remote.TagVersion(candidate)
+	if !ok {
+		return false
+	}
+	currentVersion, ok := remote.TagVersion(current)
+	return !ok || candidateVersion.Compare(currentVersion) > 0
+}
+
+// resolveIncludeVersion resolves the version range of a drunhub include URL
+// and returns the URL of the concrete release and its tag. Other URLs are
+// returned unchanged with an empty version.
+func resolveIncludeVersion(url string, tags includeTagsFunc) (string, string, error) {
+	protocol, path, ref, err := remote.ParseRemoteURL(url)
+	if err != nil {
+		return "", "", err
+	}
+	if protocol != "drunhub" || !remote.IsVersionRange(ref) {
+		return url, "", nil
+	}
+
+	published, err := tags(url)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list releases of %s: %w", url, err)
+	}
+	version, err := remote.ResolveVersionRange(ref, published)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", url, err)
+	}
+	return "drunhub:" + path + "@" + version, version, nil
+}
+
+// listIncludeTags lists the tags published in drun-hub
+func listIncludeTags(url string) ([]string, error) {
+	if !strings.HasPrefix(url, "drunhub:") {
+		return nil, fmt.Errorf("version ranges are only supported for drunhub includes: %s", url)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), vendorFetchTimeout)
+	defer cancel()
+	return remote.NewDrunhubFetcher(remote.NewGitHubFetcher()).ListTags(ctx)
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/remote"
+)
+
+const includesTestSpec = `version: 2.0
+
+project "app":
+  include from drunhub ops/docker@^1.2 as docker
+  include from drunhub ops/go@v1.2.0 as go
+  include from drunhub std/core
+
+task "build":
+  info "building"
+`
+
+func fakeIncludeTags(tags ...string) includeTagsFunc {
+	return func(string) ([]string, error) { return tags, nil }
+}
+
+func TestRunVendorRecordsResolvedVersion(t *testing.T) {
+	withCompletionSpec(t, includesTestSpec)
+
+	var out bytes.Buffer
+	err := runVendor(&out, "", fakeVendorFetch(map[string]string{
+		"drunhub:ops/docker@v1.3.0": "version: 2.0\nproject \"docker\":\n",
+		"drunhub:ops/go@v1.2.0":     "version: 2.0\nproject \"go\":\n",
+		"drunhub:std/core":          "version: 2.0\nproject \"std\":\n",
+	}), fakeIncludeTags("v1.1.0", "v1.2.0", "v1.3.0", "v2.0.0"))
+	if err != nil {
+		t.Fatalf("runVendor() error = %v\n%s", err, out.String())
+	}
+
+	lock, err := remote.LoadVendorLock(".")
+	if err != nil {
+		t.Fatalf("LoadVendorLock() error = %v", err)
+	}
+	entry, ok := lock.Lookup("drunhub:ops/docker@^1.2")
+	if !ok || entry.Version != "v1.3.0" {
+		t.Fatalf("expected the range to be locked at v1.3.0, got %+v", entry)
+	}
+	if entry, _ := lock.Lookup("drunhub:ops/go@v1.2.0"); entry.Version != "" {
+		t.Fatalf("exact refs record no resolved version, got %+v", entry)
+	}
+}
+
+func TestRunIncludesOutdated(t *testing.T) {
+	withCompletionSpec(t, includesTestSpec)
+	tags := fakeIncludeTags("v1.1.0", "v1.2.0", "v1.3.0", "v1.4.2", "v2.0.0", "main")
+
+	// Without a lock the range reports the release a run resolves to
+	var out bytes.Buffer
+	if err := runIncludesOutdated(&out, "", tags); err != nil {
+		t.Fatalf("runIncludesOutdated() error = %v", err)
+	}
+	for _, want := range []string{
+		"drunhub:ops/docker@^1.2  v1.4.2      v1.4.2      v2.0.0",
+		"drunhub:ops/go@v1.2.0    v1.2.0      v1.4.2      v2.0.0",
+		"2 of 2 drunhub includes have newer releases",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "std/core") {
+		t.Fatalf("unversioned includes should not be listed:\n%s", out.String())
+	}
+
+	// A vendored range reports the locked release
+	lock := &remote.VendorLock{Includes: []remote.VendorEntry{{URL: "drunhub:ops/docker@^1.2", File: "drunhub/ops/docker@_1.2.drun", Version: "v1.2.0"}}}
+	if err := lock.Save("."); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	out.Reset()
+	if err := runIncludesOutdated(&out, "", tags); err != nil {
+		t.Fatalf("runIncludesOutdated() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "drunhub:ops/docker@^1.2  v1.2.0      v1.4.2") {
+		t.Fatalf("expected the locked version to be reported:\n%s", out.String())
+	}
+}
@@ -2,9 +2,11 @@ package app
 
 import (
 	"context"
+	"embed"
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -20,6 +22,9 @@ import (
 
 const (
 	initTemplateKindGoCLI          = "go-cli"
+	initTemplateKindTask           = "task"
+	builtinTemplatePrefix          = "builtin:"
+	builtinTaskTemplateManifest    = builtinTemplatePrefix + "task_templates/templates.yaml"
 	initTemplateCacheDuration      = time.Minute
 	initTemplateFetchTimeout       = 30 * time.Second
 	initTemplateManifestVerion     = "1"
@@ -28,6 +33,11 @@ const (
 
 var initTemplateContentFetcher = fetchInitTemplateContent
 
+// builtinTemplates holds the templates served under builtin: refs
+//
+//go:embed task_templates
+var builtinTemplates embed.FS
+
 type initTemplateManifest struct {
 	Version   string              `yaml:"version"`
 	Templates []initTemplateEntry `yaml:"-"`
@@ -41,6 +51,7 @@ type initTemplateEntry struct {
 }
 
 type initTemplateVariables struct {
+	TaskName    string
 	ProjectName string
 	BinaryName  string
 	CmdPath     string
@@ -97,6 +108,9 @@ func generateConfigFromTemplate(manifestURL, templateName, templatesRepo string)
 	if err != nil {
 		return "", err
 	}
+	if entry.Kind == initTemplateKindTask {
+		return "", fmt.Errorf("template %q is a task template; add it with cmd:new task --template %s", templateName, templateName)
+	}
 
 	content, err := initTemplateContentFetcher(entry.Source)
 	if err != nil {
@@ -161,6 +175,9 @@ func (m *initTemplateManifest) templateByName(name string) (*initTemplateEntry,
 }
 
 func fetchInitTemplateContent(url string) ([]byte, error) {
+	if name, ok := strings.CutPrefix(url, builtinTemplatePrefix); ok {
+		return builtinTemplates.ReadFile(name)
+	}
 	if isLocalTemplatePath(url) {
 		content, err := os.ReadFile(url)
 		if err != nil {
@@ -180,7 +197,7 @@ func fetchInitTemplateContent(url string) ([]byte, error) {
 	}
 	defer func() { _ = cacheManager.Close() }()
 
-	cacheKey := cache.GenerateKey(url, ref)
+	cacheKey := cache.GenerateCategoryKey(cache.CategoryTemplates, url, ref)
 	if content, hit, err := cacheManager.Get(cacheKey); err == nil && hit {
 		return content, nil
 	}
@@ -269,6 +286,9 @@ func ListInitTemplates(fromTemplate, templatesRepo string) error {
 
 	fmt.Printf("Available init templates (%s):\n", manifestRef)
 	for _, entry := range entries {
+		if entry.Kind == initTemplateKindTask {
+			continue
+		}
 		if entry.Description != "" {
 			fmt.Printf("  - %s: %s\n", entry.Name, entry.Description)
 			continue
@@ -286,6 +306,9 @@ func resolveTemplateSource(manifestRef, source string) (string, error) {
 	if remote.IsRemoteURL(source) || filepath.IsAbs(source) {
 		return source, nil
 	}
+	if name, ok := strings.CutPrefix(manifestRef, builtinTemplatePrefix); ok {
+		return builtinTemplatePrefix + path.Join(path.Dir(name), source), nil
+	}
 
 	if remote.IsRemoteURL(manifestRef) {
 		protocol, path, ref, err := remote.ParseRemoteURL(manifestRef)
@@ -327,7 +350,7 @@ func isLocalTemplatePath(path string) bool {
 	if path == "" {
 		return false
 	}
-	if strings.Contains(path, "://") || strings.HasPrefix(path, "github:") || strings.HasPrefix(path, "drunhub:") {
+	if strings.Contains(path, "://") || strings.HasPrefix(path, "github:") || strings.HasPrefix(path, "drunhub:") || strings.HasPrefix(path, builtinTemplatePrefix) {
 		return false
 	}
 	return true
@@ -336,12 +359,7 @@ func isLocalTemplatePath(path string) bool {
 func applyInitTemplate(templateContent, kind string) (string, error) {
 	vars := inferInitTemplateVariables()
 
-	rendered := strings.NewReplacer(
-		"{{project_name}}", vars.ProjectName,
-		"{{binary_name}}", vars.BinaryName,
-		"{{cmd_path}}", vars.CmdPath,
-		"{{module_name}}", vars.ModuleName,
-	).Replace(templateContent)
+	rendered := renderTemplateVariables(templateContent, vars)
 
 	rendered = rewriteProjectDeclaration(rendered, vars.ProjectName)
 
@@ -352,6 +370,17 @@ func applyInitTemplate(templateContent, kind string) (string, error) {
 	return rendered, nil
 }
 
+// renderTemplateVariables replaces the {{placeholder}}s of a template
+func renderTemplateVariables(templateContent string, vars initTemplateVariables) string {
+	return strings.NewReplacer(
+		"{{task_name}}", vars.TaskName,
+		"{{project_name}}", vars.ProjectName,
+		"{{binary_name}}", vars.BinaryName,
+		"{{cmd_path}}", vars.CmdPath,
+		"{{module_name}}", vars.ModuleName,
+	).Replace(templateContent)
+}
+
 func inferInitTemplateVariables() initTemplateVariables {
 	projectName := inferProjectNameFromWorkingDir()
 	moduleName := inferGoModuleName(projectName)
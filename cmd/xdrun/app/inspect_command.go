@@ -0,0 +1,407 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/lsp"
+	"github.com/phillarmonic/drun/v2/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Program Inspection
+// This file contains the cmd:inspect command that exports a machine-readable
+// description of a drun file for IDE plugins, web UIs and doc generators.
+
+// inspectSchemaVersion is bumped whenever a field is removed or changes
+// meaning; new fields may be added without a bump
+const inspectSchemaVersion = 1
+
+// createInspectCommand creates the cmd:inspect subcommand
+func (a *App) createInspectCommand() *cobra.Command {
+	var configFile string
+	var format string
+	var tokens bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:inspect",
+		Short: "Export a machine-readable description of the drun file",
+		Long: `Export a machine-readable description of the drun file: project settings and
+parameters, includes, templates and every task (including tasks brought in by
+includes) with its parameters, types, constraints and dependencies.
+
+With --tokens it instead exports the semantic token classification of a file
+(keyword, action, variable, string, number, comment, namespace) with zero-based
+UTF-16 ranges, so editor extensions can highlight drun without reimplementing
+the lexer. The file may be given as an argument and does not need to parse.
+
+The output carries a "schema_version" field; it changes only when existing
+fields are removed or change meaning.
+
+Examples:
+  xdrun cmd:inspect --format json               # Describe the task file as JSON
+  xdrun cmd:inspect -f ci.drun --format json    # Describe a specific task file
+  xdrun cmd:inspect --tokens ci.drun            # Export semantic tokens of a file
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if !tokens {
+					return fmt.Errorf("a file argument is only accepted with --tokens; use --file to select the task file")
+				}
+				configFile = args[0]
+			}
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			if tokens {
+				return runInspectTokens(cmd.OutOrStdout(), configFile, format)
+			}
+			return runInspect(cmd.OutOrStdout(), configFile, format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().StringVar(&format, "format", "json", "Output format (supported: json)")
+	cmd.Flags().BoolVar(&tokens, "tokens", false, "Export semantic token classifications instead of the program description")
+
+	return cmd
+}
+
+type inspectDocument struct {
+	SchemaVersion int               `json:"schema_version"`
+	File          string            `json:"file"`
+	Version       string            `json:"version,omitempty"`
+	Project       *inspectProject   `json:"project,omitempty"`
+	Includes      []inspectInclude  `json:"includes"`
+	Templates     []inspectTemplate `json:"templates"`
+	Tasks         []inspectTask     `json:"tasks"`
+}
+
+type inspectTokensDocument struct {
+	SchemaVersion int                 `json:"schema_version"`
+	File          string              `json:"file"`
+	TokenTypes    []string            `json:"token_types"`
+	Tokens        []lsp.SemanticToken `json:"tokens"`
+}
+
+type inspectProject struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version,omitempty"`
+	DefaultTask string            `json:"default_task,omitempty"`
+	Settings    map[string]string `json:"settings"`
+	Parameters  []inspectParam    `json:"parameters"`
+
+	Profiles map[string]map[string]string `json:"profiles,omitempty"`
+}
+
+type inspectInclude struct {
+	Path      string          `json:"path"`
+	Namespace string          `json:"namespace,omitempty"`
+	Selectors []string        `json:"selectors,omitempty"`
+	Symbols   []inspectSymbol `json:"symbols,omitempty"`
+	Override  bool            `json:"override,omitempty"`
+	Line      int             `json:"line"`
+}
+
+type inspectSymbol struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+}
+
+type inspectTemplate struct {
+	Name        string         `json:"name"`
+	Namespace   string         `json:"namespace,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Parameters  []inspectParam `json:"parameters"`
+	Line        int            `json:"line"`
+}
+
+type inspectTask struct {
+	Name         string              `json:"name"`
+	Namespace    string              `json:"namespace,omitempty"`
+	Source       string              `json:"source"`
+	Line         int                 `json:"line"`
+	Description  string              `json:"description,omitempty"`
+	Doc          string              `json:"doc,omitempty"`
+	Mode         string              `json:"mode,omitempty"`
+	Container    string              `json:"container,omitempty"`
+	Default      bool                `json:"default,omitempty"`
+	Platforms    []string            `json:"platforms,omitempty"`
+	Parameters   []inspectParam      `json:"parameters"`
+	Dependencies []inspectDependency `json:"dependencies"`
+}
+
+type inspectParam struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind,omitempty"` // requires, given or accepts; empty for project parameters
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Default     *string  `json:"default,omitempty"`
+	Constraints []string `json:"constraints,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Variadic    bool     `json:"variadic,omitempty"`
+}
+
+type inspectDependency struct {
+	Name       string `json:"name"`
+	Parallel   bool   `json:"parallel,omitempty"`
+	Sequential bool   `json:"sequential,omitempty"`
+}
+
+// runInspect writes the description of the drun file in format to out
+func runInspect(out io.Writer, configFile, format string) error {
+	if format != "json" {
+		return fmt.Errorf("unsupported inspect format %q (supported: json)", format)
+	}
+
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:inspect intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	doc, err := inspectProgram(program, actualConfigFile)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// runInspectTokens writes the semantic tokens of the drun file in format to
+// out. Only the lexer runs, so files that do not parse are still classified.
+func runInspectTokens(out io.Writer, configFile, format string) error {
+	if format != "json" {
+		return fmt.Errorf("unsupported inspect format %q (supported: json)", format)
+	}
+
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:inspect intentionally reads the requested drun file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	doc := inspectTokensDocument{
+		SchemaVersion: inspectSchemaVersion,
+		File:          filepath.ToSlash(actualConfigFile),
+		TokenTypes:    lsp.SemanticTokenTypes,
+		Tokens:        lsp.SemanticTokens(string(content)),
+	}
+	if doc.Tokens == nil {
+		doc.Tokens = []lsp.SemanticToken{}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// inspectProgram describes program, resolving its includes relative to file
+func inspectProgram(program *ast.Program, file string) (*inspectDocument, error) {
+	doc := &inspectDocument{
+		SchemaVersion: inspectSchemaVersion,
+		File:          filepath.ToSlash(file),
+		Includes:      []inspectInclude{},
+		Templates:     []inspectTemplate{},
+		Tasks:         []inspectTask{},
+	}
+	if program.Version != nil {
+		doc.Version = program.Version.Value
+	}
+
+	for _, template := range program.Templates {
+		doc.Templates = append(doc.Templates, newInspectTemplate(template, ""))
+	}
+	for _, task := range program.Tasks {
+		doc.Tasks = append(doc.Tasks, newInspectTask(task, "", doc.File))
+	}
+
+	if program.Project == nil {
+		return doc, nil
+	}
+
+	doc.Project = &inspectProject{
+		Name:       program.Project.Name,
+		Version:    program.Project.Version,
+		Settings:   make(map[string]string),
+		Parameters: []inspectParam{},
+	}
+	for _, setting := range program.Project.Settings {
+		switch s := setting.(type) {
+		case *ast.SetStatement:
+			if s.Value != nil {
+				doc.Project.Settings[s.Key] = s.Value.String()
+			}
+		case *ast.DefaultTaskStatement:
+			doc.Project.DefaultTask = s.TaskName
+		case *ast.ProfileStatement:
+			if doc.Project.Profiles == nil {
+				doc.Project.Profiles = make(map[string]map[string]string)
+			}
+			doc.Project.Profiles[s.Name] = s.Params()
+		case *ast.ProjectParameterStatement:
+			doc.Project.Parameters = append(doc.Project.Parameters, inspectParam{
+				Name:        s.Name,
+				Type:        inspectType(s.DataType),
+				Default:     inspectDefault(s.DefaultValue, s.HasDefault),
+				Constraints: s.Constraints,
+				Min:         s.MinValue,
+				Max:         s.MaxValue,
+				Pattern:     s.Pattern,
+			})
+		case *ast.IncludeStatement:
+			include := inspectInclude{
+				Path:      s.Path,
+				Namespace: s.Namespace,
+				Selectors: s.Selectors,
+				Override:  s.Override,
+				Line:      s.Token.Line,
+			}
+			for _, symbol := range s.Symbols {
+				include.Symbols = append(include.Symbols, inspectSymbol(symbol))
+			}
+			doc.Includes = append(doc.Includes, include)
+		}
+	}
+
+	if len(doc.Includes) == 0 {
+		return doc, nil
+	}
+
+	projectCtx, err := engine.NewEngine(io.Discard).BuildProjectContext(program.Project, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project context: %w", err)
+	}
+
+	templateNames := make([]string, 0, len(projectCtx.IncludedTemplates))
+	for name := range projectCtx.IncludedTemplates {
+		templateNames = append(templateNames, name)
+	}
+	sort.Strings(templateNames)
+	for _, name := range templateNames {
+		// Describe the template under the name it was imported as
+		template := *projectCtx.IncludedTemplates[name]
+		namespace, local, _ := strings.Cut(name, ".")
+		template.Name = local
+		doc.Templates = append(doc.Templates, newInspectTemplate(&template, namespace))
+	}
+
+	taskNames := make([]string, 0, len(projectCtx.IncludedTasks))
+	for name := range projectCtx.IncludedTasks {
+		taskNames = append(taskNames, name)
+	}
+	sort.Strings(taskNames)
+	for _, name := range taskNames {
+		namespace, _, _ := strings.Cut(name, ".")
+		source := ""
+		if origin, ok := projectCtx.IncludedOrigins["task:"+name]; ok {
+			source = origin.File
+		}
+		for _, task := range projectCtx.IncludedTasks[name] {
+			doc.Tasks = append(doc.Tasks, newInspectTask(task, namespace, source))
+		}
+	}
+
+	return doc, nil
+}
+
+func newInspectTask(task *ast.TaskStatement, namespace, source string) inspectTask {
+	item := inspectTask{
+		Name:         task.Name,
+		Namespace:    namespace,
+		Source:       source,
+		Line:         task.Token.Line,
+		Description:  task.Description,
+		Doc:          task.Doc,
+		Mode:         task.Mode,
+		Container:    task.Container,
+		Default:      task.Default,
+		Parameters:   inspectParams(task.Parameters),
+		Dependencies: []inspectDependency{},
+	}
+	if meta, err := platform.ValidateAnnotations("task", task.Name, task.Annotations); err == nil {
+		item.Platforms = meta.Platforms
+	}
+	for _, group := range task.Dependencies {
+		for _, dep := range group.Dependencies {
+			item.Dependencies = append(item.Dependencies, inspectDependency{
+				Name:       dep.Name,
+				Parallel:   dep.Parallel,
+				Sequential: group.Sequential,
+			})
+		}
+	}
+	return item
+}
+
+func newInspectTemplate(template *ast.TaskTemplateStatement, namespace string) inspectTemplate {
+	return inspectTemplate{
+		Name:        template.Name,
+		Namespace:   namespace,
+		Description: template.Description,
+		Parameters:  inspectParams(template.Parameters),
+		Line:        template.Token.Line,
+	}
+}
+
+func inspectParams(params []ast.ParameterStatement) []inspectParam {
+	items := make([]inspectParam, 0, len(params))
+	for _, param := range params {
+		items = append(items, inspectParam{
+			Name:        param.Name,
+			Kind:        param.Type,
+			Type:        inspectType(param.DataType),
+			Required:    param.Type == "requires" && !param.HasDefault,
+			Default:     inspectDefault(param.DefaultValue, param.HasDefault),
+			Constraints: param.Constraints,
+			Min:         param.MinValue,
+			Max:         param.MaxValue,
+			Pattern:     param.Pattern,
+			Variadic:    param.Variadic,
+		})
+	}
+	return items
+}
+
+func inspectType(dataType string) string {
+	if dataType == "" {
+		return "string"
+	}
+	return dataType
+}
+
+func inspectDefault(value string, present bool) *string {
+	if !present {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInspectDescribesProgramAsJSON(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+project "shop" version "1.2.0":
+  set registry to "ghcr.io/shop"
+  parameter $region as string defaults to "eu"
+  include tasks from "lib.drun" as lib
+
+template task "image":
+  given $tag defaults to "latest"
+  info "image {$tag}"
+
+task "build":
+  info "building"
+
+task "deploy" means "Deploy the shop" default:
+  depends on build
+  requires $env from ["staging", "production"]
+  given $replicas as number defaults to "3"
+  info "deploying"
+`)
+	lib := `version: 2.0
+
+project "library":
+
+task "lint":
+  info "lint"
+`
+	if err := os.WriteFile(filepath.Join(".drun", "lib.drun"), []byte(lib), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runInspect(&out, "", "json"); err != nil {
+		t.Fatalf("runInspect() error = %v", err)
+	}
+
+	var doc inspectDocument
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if doc.SchemaVersion != inspectSchemaVersion || doc.Version != "2.0" {
+		t.Errorf("unexpected header: schema %d, version %q", doc.SchemaVersion, doc.Version)
+	}
+	if doc.Project == nil || doc.Project.Name != "shop" || doc.Project.Settings["registry"] != "ghcr.io/shop" {
+		t.Fatalf("unexpected project: %+v", doc.Project)
+	}
+	if len(doc.Project.Parameters) != 1 || *doc.Project.Parameters[0].Default != "eu" {
+		t.Errorf("unexpected project parameters: %+v", doc.Project.Parameters)
+	}
+	if len(doc.Includes) != 1 || doc.Includes[0].Path != "lib.drun" || doc.Includes[0].Namespace != "lib" {
+		t.Errorf("unexpected includes: %+v", doc.Includes)
+	}
+	if len(doc.Templates) != 1 || doc.Templates[0].Name != "image" {
+		t.Errorf("unexpected templates: %+v", doc.Templates)
+	}
+
+	if len(doc.Tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %+v", doc.Tasks)
+	}
+	deploy := doc.Tasks[1]
+	if deploy.Name != "deploy" || !deploy.Default || deploy.Description != "Deploy the shop" {
+		t.Errorf("unexpected deploy task: %+v", deploy)
+	}
+	if len(deploy.Dependencies) != 1 || deploy.Dependencies[0].Name != "build" {
+		t.Errorf("unexpected deploy dependencies: %+v", deploy.Dependencies)
+	}
+	if len(deploy.Parameters) != 2 {
+		t.Fatalf("unexpected deploy parameters: %+v", deploy.Parameters)
+	}
+	env, replicas := deploy.Parameters[0], deploy.Parameters[1]
+	if env.Kind != "requires" || !env.Required || strings.Join(env.Constraints, ",") != "staging,production" {
+		t.Errorf("unexpected env parameter: %+v", env)
+	}
+	if replicas.Type != "number" || replicas.Required || replicas.Default == nil || *replicas.Default != "3" {
+		t.Errorf("unexpected replicas parameter: %+v", replicas)
+	}
+
+	included := doc.Tasks[2]
+	if included.Name != "lint" || included.Namespace != "lib" || included.Source != "lib.drun" {
+		t.Errorf("unexpected included task: %+v", included)
+	}
+}
+
+func TestRunInspectRejectsUnknownFormat(t *testing.T) {
+	withCompletionSpec(t, "version: 2.0\n\ntask \"build\":\n  info \"building\"\n")
+
+	err := runInspect(&bytes.Buffer{}, "", "yaml")
+	if err == nil || !strings.Contains(err.Error(), `unsupported inspect format "yaml"`) {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
+
+func TestRunInspectTokensClassifiesUnparsableFile(t *testing.T) {
+	withCompletionSpec(t, "version: 2.0\n")
+	// Missing colon: the parser rejects it, the token export must not
+	source := "task \"build\"\n  info \"building\"\n"
+	if err := os.WriteFile("broken.drun", []byte(source), 0600); err != nil {
+		t.Fatalf("write broken.drun: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runInspectTokens(&out, "broken.drun", "json"); err != nil {
+		t.Fatalf("runInspectTokens: %v", err)
+	}
+
+	var doc inspectTokensDocument
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out.String())
+	}
+	if doc.SchemaVersion != inspectSchemaVersion || doc.File != "broken.drun" || len(doc.TokenTypes) == 0 {
+		t.Errorf("unexpected document header: %+v", doc)
+	}
+	if len(doc.Tokens) != 4 {
+		t.Fatalf("expected 4 tokens, got %+v", doc.Tokens)
+	}
+	if info := doc.Tokens[2]; info.Type != "action" || info.Line != 1 || info.Character != 2 {
+		t.Errorf("unexpected info token: %+v", info)
+	}
+}
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/remote"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Linting
+// This file contains the cmd:lint command, which reports things in drun
+// files that are worth a look before they ship. The todo rule lists every
+// `todo "..."` left in tasks and task templates; the shadow rule lists the
+// task parameters reassigned with `let shadow` or `set shadow`, including in
+// the local libraries the checked files include.
+
+// lintFinding is one thing a lint rule reports
+type lintFinding struct {
+	File    string
+	Line    int
+	Rule    string
+	Message string
+}
+
+// lintRule checks a parsed drun file
+type lintRule struct {
+	Name     string
+	Check    func(program *ast.Program) []lintFinding
+	Includes bool // also check the local files the checked files include
+}
+
+// lintRules are the rules cmd:lint runs
+var lintRules = []lintRule{
+	{Name: "todo", Check: lintTodos},
+	{Name: "shadow", Check: lintShadows, Includes: true},
+}
+
+// createLintCommand creates the cmd:lint subcommand
+func (a *App) createLintCommand() *cobra.Command {
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:lint [files...]",
+		Short: "Report unfinished work and other issues in drun files",
+		Long: `Check drun files and report what is worth a look before they ship.
+Without arguments, the task file is checked.
+
+Rules:
+  todo    Every todo "..." statement, with the task or template it is in
+  shadow  Every task parameter reassigned with let shadow or set shadow,
+          in the checked files and the local files they include
+
+Findings are printed one per line as file:line: [rule] message. They do
+not fail the command unless --strict is given.
+
+Examples:
+  xdrun cmd:lint                          # List the TODOs of the task file
+  xdrun cmd:lint .drun/*.drun             # Check several files
+  xdrun cmd:lint --strict                 # Fail when there is a finding (for CI)
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				actualConfigFile, err := FindConfigFile(a.configFile)
+				if err != nil {
+					return fmt.Errorf("no drun task file found: %w", err)
+				}
+				files = []string{actualConfigFile}
+			}
+			return runLint(cmd.OutOrStdout(), files, strict)
+		},
+	}
+
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when a rule reports a finding")
+
+	return cmd
+}
+
+// runLint runs every lint rule over files and prints their findings
+func runLint(out io.Writer, files []string, strict bool) error {
+	var findings []lintFinding
+	checked := make(map[string]bool)
+	for _, file := range files {
+		fileFindings, err := lintFile(file, false, checked)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, fileFindings...)
+	}
+
+	for _, finding := range findings {
+		_, _ = fmt.Fprintf(out, "%s:%d: [%s] %s\n", finding.File, finding.Line, finding.Rule, finding.Message)
+	}
+	if len(findings) == 0 {
+		_, _ = fmt.Fprintln(out, "No findings")
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "\n%s\n", pluralFindings(len(findings)))
+	if strict {
+		return withExitCode(ExitValidationError, fmt.Errorf("cmd:lint reported %s", pluralFindings(len(findings))))
+	}
+	return nil
+}
+
+// lintFile runs the lint rules over a drun file, then the rules that check
+// includes over the local files it includes. Each file is checked once.
+func lintFile(file string, included bool, checked map[string]bool) ([]lintFinding, error) {
+	if abs, err := filepath.Abs(file); err == nil {
+		if checked[abs] {
+			return nil, nil
+		}
+		checked[abs] = true
+	}
+
+	// #nosec G304 -- cmd:lint intentionally reads the drun files it is given and their includes.
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drun file '%s': %w", file, err)
+	}
+	program, err := engine.ParseStringWithFilename(string(content), file)
+	if err != nil {
+		return nil, withExitCode(ExitParseError, fmt.Errorf("failed to parse drun file '%s': %w", file, err))
+	}
+
+	var findings []lintFinding
+	for _, rule := range lintRules {
+		if included && !rule.Includes {
+			continue
+		}
+		for _, finding := range rule.Check(program) {
+			finding.File = file
+			finding.Rule = rule.Name
+			findings = append(findings, finding)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+
+	for _, path := range localIncludes(program, file) {
+		includedFindings, err := lintFile(path, true, checked)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, includedFindings...)
+	}
+	return findings, nil
+}
+
+// localIncludes returns the paths of the local files a program includes,
+// relative to the file it was read from. Remote includes and workspace
+// members are not followed.
+func localIncludes(program *ast.Program, file string) []string {
+	if program.Project == nil {
+		return nil
+	}
+	var paths []string
+	for _, setting := range program.Project.Settings {
+		include, ok := setting.(*ast.IncludeStatement)
+		if !ok || include.Member || remote.IsRemoteURL(include.Path) {
+			continue
+		}
+		path := include.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// pluralFindings returns "1 finding" or "n findings"
+func pluralFindings(n int) string {
+	if n == 1 {
+		return "1 finding"
+	}
+	return fmt.Sprintf("%d findings", n)
+}
+
+// lintTodos lists the todo statements of a program
+func lintTodos(program *ast.Program) []lintFinding {
+	var findings []lintFinding
+	collect := func(where string, body []ast.Statement) {
+		walkStatements(body, func(stmt ast.Statement) {
+			if todo, ok := stmt.(*ast.TodoStatement); ok {
+				findings = append(findings, lintFinding{
+					Line:    todo.Token.Line,
+					Message: fmt.Sprintf("%s: %s", where, todo.Message),
+				})
+			}
+		})
+	}
+
+	for _, template := range program.Templates {
+		collect(fmt.Sprintf("template '%s'", template.Name), template.Body)
+	}
+	for _, task := range program.Tasks {
+		collect(fmt.Sprintf("task '%s'", task.Name), task.Body)
+	}
+	return findings
+}
+
+// lintShadows lists the let and set statements that shadow a task parameter
+func lintShadows(program *ast.Program) []lintFinding {
+	var findings []lintFinding
+	collect := func(where string, body []ast.Statement) {
+		walkStatements(body, func(stmt ast.Statement) {
+			if variable, ok := stmt.(*ast.VariableStatement); ok && variable.Shadow {
+				findings = append(findings, lintFinding{
+					Line:    variable.Token.Line,
+					Message: fmt.Sprintf("%s shadows parameter $%s", where, strings.TrimPrefix(variable.Variable, "$")),
+				})
+			}
+		})
+	}
+
+	for _, template := range program.Templates {
+		collect(fmt.Sprintf("template '%s'", template.Name), template.Body)
+	}
+	for _, task := range program.Tasks {
+		collect(fmt.Sprintf("task '%s'", task.Name), task.Body)
+	}
+	return findings
+}
+
+// walkStatements calls visit with every statement of body, including the
+// statements nested in conditions, loops, try blocks and other blocks
+func walkStatements(body []ast.Statement, visit func(ast.Statement)) {
+	for _, stmt := range body {
+		visit(stmt)
+		switch s := stmt.(type) {
+		case *ast.ConditionalStatement:
+			walkStatements(s.Body, visit)
+			walkStatements(s.ElseBody, visit)
+		case *ast.DetectionStatement:
+			walkStatements(s.Body, visit)
+			walkStatements(s.ElseBody, visit)
+		case *ast.LoopStatement:
+			walkStatements(s.Body, visit)
+		case *ast.TryStatement:
+			walkStatements(s.TryBody, visit)
+			for _, clause := range s.CatchClauses {
+				walkStatements(clause.Body, visit)
+			}
+			walkStatements(s.FinallyBody, visit)
+		case *ast.WithinStatement:
+			walkStatements(s.Body, visit)
+		case *ast.LockStatement:
+			walkStatements(s.Body, visit)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunLintListsTodos(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spec.drun")
+	source := `version: 2.0
+
+template task "release":
+  info "releasing"
+  todo "sign the artifacts"
+
+task "deploy":
+  info "deploying"
+  for each $region in ["eu", "us"]:
+    try:
+      todo "deploy to {$region}"
+    finally:
+      todo "implement rollback"
+
+task "build":
+  info "building"
+`
+	if err := os.WriteFile(file, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runLint(&out, []string{file}, false); err != nil {
+		t.Fatalf("runLint() error = %v", err)
+	}
+	want := []string{
+		file + ":5: [todo] template 'release': sign the artifacts",
+		file + ":11: [todo] task 'deploy': deploy to {$region}",
+		file + ":13: [todo] task 'deploy': implement rollback",
+		"3 findings",
+	}
+	for _, line := range want {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected %q in output:\n%s", line, out.String())
+		}
+	}
+
+	err := runLint(&out, []string{file}, true)
+	if err == nil || ExitCode(err) != ExitValidationError {
+		t.Errorf("runLint(strict) error = %v, want a validation error", err)
+	}
+}
+
+func TestRunLintWithoutFindings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spec.drun")
+	if err := os.WriteFile(file, []byte("version: 2.0\n\ntask \"build\":\n  info \"hi\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runLint(&out, []string{file}, true); err != nil {
+		t.Fatalf("runLint(strict) error = %v", err)
+	}
+	if out.String() != "No findings\n" {
+		t.Errorf("runLint() output = %q", out.String())
+	}
+}
+
+func TestRunLintListsShadowedParametersOfIncludedLibraries(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "spec.drun")
+	library := filepath.Join(dir, "lib", "deploy.drun")
+	files := map[string]string{
+		file: `version: 2.0
+
+project "app":
+  include "lib/deploy.drun"
+  include "https://example.com/remote.drun"
+
+task "build":
+  given $target defaults to "linux"
+  let shadow $target = "{$target}/amd64"
+`,
+		library: `version: 2.0
+
+project "deploy":
+  include "deploy.drun"
+
+task "release":
+  given $env defaults to "dev"
+  if $env is "dev":
+    set shadow $env to "staging"
+`,
+	}
+	for path, source := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runLint(&out, []string{file}, false); err != nil {
+		t.Fatalf("runLint() error = %v", err)
+	}
+	want := file + ":9: [shadow] task 'build' shadows parameter $target\n" +
+		library + ":9: [shadow] task 'release' shadows parameter $env\n" +
+		"\n2 findings\n"
+	if out.String() != want {
+		t.Errorf("runLint() output = %q, want %q", out.String(), want)
+	}
+}
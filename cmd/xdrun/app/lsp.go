@@ -18,6 +18,8 @@ This command is intended for editor integrations. It currently supports:
 - full text document sync
 - parser-backed diagnostics
 - simple keyword and task-name completions
+- full-document semantic tokens (keyword, action, variable, string, number,
+  comment, namespace)
 
 Example:
   xdrun cmd:lsp`,
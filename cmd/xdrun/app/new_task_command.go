@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Task Scaffolding
+// This file contains the cmd:new command that appends task skeletons to the
+// current drun file.
+
+// defaultTaskTemplate is the skeleton used when --template is not given
+const defaultTaskTemplate = "basic"
+
+// createNewCommand creates the cmd:new subcommand
+func (a *App) createNewCommand() *cobra.Command {
+	var configFile string
+	var templateName string
+	var fromTemplate string
+
+	cmd := &cobra.Command{
+		Use:   "cmd:new task <name>",
+		Short: "Append a task skeleton to the drun file",
+		Long: fmt.Sprintf(`Append a well-formed task skeleton (description, parameters and common steps)
+to the current drun file.
+
+Templates: %s (default: %s)
+
+Task templates are entries with kind: task in a template manifest. The ones
+above are built in; --from-template (or DRUN_TEMPLATES_MANIFEST and
+DRUN_TEMPLATES_REPO) adds the task templates of another catalog, which replace
+built-in templates of the same name.
+
+Examples:
+  xdrun cmd:new task "deploy api"                          # Basic task skeleton
+  xdrun cmd:new task "build image" --template docker-build # Docker build task
+  xdrun cmd:new task release --template release -f ci.drun # Add to a specific file
+  xdrun cmd:new task lint --from-template ../drun-templates --template go-lint
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+			strings.Join(taskTemplateNames(), ", "), defaultTaskTemplate),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 || args[0] != "task" {
+				return fmt.Errorf("usage: xdrun cmd:new task <name>")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runNewTask(cmd.OutOrStdout(), configFile, fromTemplate, args[1], templateName)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().StringVarP(&templateName, "template", "t", defaultTaskTemplate, "Task template: "+strings.Join(taskTemplateNames(), ", "))
+	cmd.Flags().StringVar(&fromTemplate, "from-template", "", "Template manifest or repository with more task templates")
+
+	return cmd
+}
+
+// runNewTask renders templateName for taskName and appends it to the drun
+// file. fromTemplate names a template catalog with more task templates.
+func runNewTask(out io.Writer, configFile, fromTemplate, taskName, templateName string) error {
+	taskName = strings.TrimSpace(taskName)
+	if taskName == "" || strings.ContainsAny(taskName, "\"\n") {
+		return fmt.Errorf("invalid task name %q", taskName)
+	}
+
+	templates, err := loadTaskTemplates(fromTemplate)
+	if err != nil {
+		return err
+	}
+	entry, err := templates.templateByName(templateName)
+	if err != nil {
+		return fmt.Errorf("unknown task template %q (available: %s)", templateName, strings.Join(templates.names(), ", "))
+	}
+	template, err := initTemplateContentFetcher(entry.Source)
+	if err != nil {
+		return fmt.Errorf("failed to fetch task template %q: %w", entry.Source, err)
+	}
+
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:new intentionally edits the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+	for _, task := range program.Tasks {
+		if task.Name == taskName {
+			return fmt.Errorf("task %q already exists in %s", taskName, actualConfigFile)
+		}
+	}
+
+	projectName := inferProjectNameFromWorkingDir()
+	if program.Project != nil && program.Project.Name != "" {
+		projectName = program.Project.Name
+	}
+
+	skeleton := renderTemplateVariables(string(template), initTemplateVariables{TaskName: taskName, ProjectName: projectName})
+	skeleton = reindentTaskSkeleton(skeleton, detectIndentUnit(string(content)))
+
+	updated := strings.TrimRight(string(content), "\n") + "\n\n" + skeleton
+	if err := validateGeneratedConfig(updated); err != nil {
+		return fmt.Errorf("generated task is not valid drun: %w", err)
+	}
+
+	info, err := os.Stat(actualConfigFile)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(actualConfigFile, []byte(updated), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write drun file '%s': %w", actualConfigFile, err)
+	}
+
+	_, _ = fmt.Fprintf(out, "✅ Added task %q (%s template) to %s\n", taskName, templateName, actualConfigFile)
+	return nil
+}
+
+// loadTaskTemplates returns the task templates built into xdrun and those
+// of the catalog named by fromTemplate, DRUN_TEMPLATES_MANIFEST or
+// DRUN_TEMPLATES_REPO. Catalog templates come first, so they replace
+// built-in templates of the same name.
+func loadTaskTemplates(fromTemplate string) (*initTemplateManifest, error) {
+	builtin, err := loadInitTemplateManifest(builtinTaskTemplateManifest)
+	if err != nil {
+		return nil, err
+	}
+	if fromTemplate == "" && os.Getenv("DRUN_TEMPLATES_MANIFEST") == "" && os.Getenv("DRUN_TEMPLATES_REPO") == "" {
+		return builtin, nil
+	}
+
+	manifestRef, err := resolveDefaultTemplateManifest(fromTemplate, "")
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := loadInitTemplateManifest(manifestRef)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := &initTemplateManifest{Version: builtin.Version}
+	for _, entry := range append(catalog.Templates, builtin.Templates...) {
+		if entry.Kind != initTemplateKindTask {
+			continue
+		}
+		if _, err := templates.templateByName(entry.Name); err == nil {
+			continue
+		}
+		templates.Templates = append(templates.Templates, entry)
+	}
+	return templates, nil
+}
+
+// taskTemplateNames returns the names of the built-in task templates, sorted
+func taskTemplateNames() []string {
+	builtin, err := loadInitTemplateManifest(builtinTaskTemplateManifest)
+	if err != nil {
+		return nil
+	}
+	return builtin.names()
+}
+
+// names returns the template names of the manifest, sorted
+func (m *initTemplateManifest) names() []string {
+	names := make([]string, 0, len(m.Templates))
+	for _, entry := range m.Templates {
+		names = append(names, entry.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var indentedLinePattern = regexp.MustCompile(`(?m)^([ \t]+)\S`)
+
+// detectIndentUnit returns the indentation used by the first indented line of
+// content, defaulting to two spaces
+func detectIndentUnit(content string) string {
+	if match := indentedLinePattern.FindStringSubmatch(content); match != nil {
+		return match[1]
+	}
+	return "  "
+}
+
+// reindentTaskSkeleton replaces the leading tabs of each line with unit
+func reindentTaskSkeleton(skeleton, unit string) string {
+	if unit == "\t" {
+		return skeleton
+	}
+	lines := strings.Split(skeleton, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, "\t")
+		lines[i] = strings.Repeat(unit, len(line)-len(trimmed)) + trimmed
+	}
+	return strings.Join(lines, "\n")
+}
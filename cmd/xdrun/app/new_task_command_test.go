@@ -0,0 +1,130 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+)
+
+func TestRunNewTaskAppendsEachTemplate(t *testing.T) {
+	for _, templateName := range taskTemplateNames() {
+		t.Run(templateName, func(t *testing.T) {
+			withCompletionSpec(t, `version: 2.0
+
+project "shop":
+
+task "build":
+  info "building"
+`)
+
+			var out bytes.Buffer
+			if err := runNewTask(&out, "", "", "deploy api", templateName); err != nil {
+				t.Fatalf("runNewTask() error = %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(".drun", "spec.drun"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			program, err := engine.ParseStringWithFilename(string(content), "spec.drun")
+			if err != nil {
+				t.Fatalf("updated file does not parse: %v\n%s", err, content)
+			}
+			if len(program.Tasks) != 2 || program.Tasks[1].Name != "deploy api" {
+				t.Fatalf("expected appended task 'deploy api', got %d tasks", len(program.Tasks))
+			}
+			if strings.Contains(string(content), "\t") {
+				t.Errorf("expected skeleton to follow the file's two-space indentation:\n%s", content)
+			}
+			if strings.Contains(string(content), "{{") {
+				t.Errorf("unrendered placeholder in:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestRunNewTaskKeepsTabIndentation(t *testing.T) {
+	withCompletionSpec(t, "version: 2.0\n\ntask \"build\":\n\tinfo \"building\"\n")
+
+	var out bytes.Buffer
+	if err := runNewTask(&out, "", "", "deploy", "service-deploy"); err != nil {
+		t.Fatalf("runNewTask() error = %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(".drun", "spec.drun"))
+	if !strings.Contains(string(content), "\n\twhen $environment is \"production\":\n\t\twarn") {
+		t.Errorf("expected tab-indented skeleton, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "Deploying "+inferProjectNameFromWorkingDir()) {
+		t.Errorf("expected working directory project name without a project block, got:\n%s", content)
+	}
+}
+
+func TestRunNewTaskRejectsDuplicatesAndUnknownTemplates(t *testing.T) {
+	withCompletionSpec(t, "version: 2.0\n\ntask \"deploy\":\n  info \"deploying\"\n")
+
+	var out bytes.Buffer
+	if err := runNewTask(&out, "", "", "deploy", defaultTaskTemplate); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("runNewTask() duplicate error = %v", err)
+	}
+	if err := runNewTask(&out, "", "", "ship", "nope"); err == nil || !strings.Contains(err.Error(), "unknown task template") {
+		t.Errorf("runNewTask() unknown template error = %v", err)
+	}
+}
+
+func TestRunNewTaskUsesTaskTemplatesOfACatalog(t *testing.T) {
+	withCompletionSpec(t, "version: 2.0\n\nproject \"shop\":\n\ntask \"build\":\n  info \"building\"\n")
+	t.Setenv("DRUN_TEMPLATES_MANIFEST", "")
+	t.Setenv("DRUN_TEMPLATES_REPO", "")
+
+	catalog := t.TempDir()
+	files := map[string]string{
+		"templates.yaml": `version: "1"
+templates:
+  - name: go-cli
+    source: go-cli.drun
+  - name: lint
+    kind: task
+    source: tasks/lint.drun
+  - name: basic
+    kind: task
+    source: tasks/basic.drun
+`,
+		"go-cli.drun":      "version: 2.0\n\nproject \"x\":\n",
+		"tasks/lint.drun":  "task \"{{task_name}}\" means \"Lint {{project_name}}\":\n\trun \"golangci-lint run\"\n",
+		"tasks/basic.drun": "task \"{{task_name}}\":\n\tinfo \"catalog basic\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(catalog, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runNewTask(&out, "", catalog, "lint", "lint"); err != nil {
+		t.Fatalf("runNewTask() error = %v", err)
+	}
+	if err := runNewTask(&out, "", catalog, "greet", "basic"); err != nil {
+		t.Fatalf("runNewTask() error = %v", err)
+	}
+	if err := runNewTask(&out, "", catalog, "ship", "release"); err != nil {
+		t.Fatalf("runNewTask() with a built-in template error = %v", err)
+	}
+	if err := runNewTask(&out, "", catalog, "app", "go-cli"); err == nil || !strings.Contains(err.Error(), "unknown task template") {
+		t.Errorf("expected an init template to be rejected, got %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(".drun", "spec.drun"))
+	for _, want := range []string{"task \"lint\" means \"Lint shop\":\n  run \"golangci-lint run\"", "info \"catalog basic\"", "task \"ship\""} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in:\n%s", want, content)
+		}
+	}
+}
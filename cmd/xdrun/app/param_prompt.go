@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"golang.org/x/term"
+)
+
+// Domain: Parameter Prompting
+// This file asks for required task parameters that were not given on the
+// command line when xdrun runs in a terminal.
+
+// terminalParamPrompter returns a prompter that asks for missing parameters on
+// the terminal, or nil when input is disabled or stdin/stdout is not a terminal
+func terminalParamPrompter(noInput bool) engine.ParamPrompter {
+	if noInput || !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) { // #nosec G115 -- file descriptors fit in int
+		return nil
+	}
+	return newParamPrompter(os.Stdin, os.Stdout)
+}
+
+// newParamPrompter returns a prompter that reads answers from in and writes
+// questions to out. Constraint lists are shown as numbered choices, which
+// can be answered with their number or their value.
+func newParamPrompter(in io.Reader, out io.Writer) engine.ParamPrompter {
+	reader := bufio.NewReader(in)
+
+	return func(param *parameter.Parameter, validate func(value string) error) (string, error) {
+		if len(param.Constraints) > 0 {
+			_, _ = fmt.Fprintf(out, "? Choose %s:\n", param.Name)
+			for i, choice := range param.Constraints {
+				_, _ = fmt.Fprintf(out, "  %d) %s\n", i+1, choice)
+			}
+		}
+
+		for {
+			if len(param.Constraints) > 0 {
+				_, _ = fmt.Fprintf(out, "  %s [1-%d]: ", param.Name, len(param.Constraints))
+			} else {
+				_, _ = fmt.Fprintf(out, "? %s: ", param.Name)
+			}
+
+			line, err := reader.ReadString('\n')
+			answer := strings.TrimSpace(line)
+			if err != nil && answer == "" {
+				_, _ = fmt.Fprintln(out)
+				if err == io.EOF {
+					return "", fmt.Errorf("no answer given")
+				}
+				return "", err
+			}
+
+			if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(param.Constraints) {
+				answer = param.Constraints[n-1]
+			}
+			if answer == "" {
+				_, _ = fmt.Fprintf(out, "  ✗ %s is required\n", param.Name)
+				continue
+			}
+			if validateErr := validate(answer); validateErr != nil {
+				_, _ = fmt.Fprintf(out, "  ✗ %v\n", validateErr)
+				if err != nil {
+					return "", validateErr
+				}
+				continue
+			}
+			return answer, nil
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
+)
+
+func TestParamPrompterOffersChoicesAndRetriesInvalidAnswers(t *testing.T) {
+	var out bytes.Buffer
+	prompt := newParamPrompter(strings.NewReader("staging\n\n2\n"), &out)
+
+	param := &parameter.Parameter{Name: "env", Required: true, Constraints: []string{"dev", "prod"}}
+	value, err := prompt(param, func(value string) error {
+		if value != "dev" && value != "prod" {
+			return fmt.Errorf("must be one of: dev, prod")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("prompt() error = %v\n%s", err, out.String())
+	}
+	if value != "prod" {
+		t.Errorf("prompt() = %q, want prod", value)
+	}
+
+	output := out.String()
+	for _, want := range []string{"1) dev", "2) prod", "✗ must be one of: dev, prod", "✗ env is required"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestParamPrompterFailsWithoutAnswer(t *testing.T) {
+	var out bytes.Buffer
+	prompt := newParamPrompter(strings.NewReader(""), &out)
+
+	if _, err := prompt(&parameter.Parameter{Name: "version"}, func(string) error { return nil }); err == nil {
+		t.Fatal("expected an error when input ends before an answer")
+	}
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// Domain: CI Planning
+// This file contains the cmd:plan command that splits a task's parallel
+// dependencies into a GitHub Actions matrix.
+
+// matrixEntry is one job of an exported matrix
+type matrixEntry struct {
+	Name   string            `json:"name"`
+	Task   string            `json:"task"`
+	Stage  int               `json:"stage"`
+	Args   string            `json:"args"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// createPlanCommand creates the cmd:plan subcommand
+func (a *App) createPlanCommand() *cobra.Command {
+	var configFile, format, splitBy string
+
+	cmd := &cobra.Command{
+		Use:   "cmd:plan <task>",
+		Short: "Show the parallel work of a task or export it as a CI matrix",
+		Long: `Show which dependencies of a task may run in parallel, or export them as a
+GitHub Actions matrix.
+
+Every task of a parallel dependency group ("depends on lint in parallel,
+test in parallel") becomes one matrix job that runs 'xdrun <task> <args>'.
+Stage numbers follow the order of the groups. A task without parallel
+dependencies becomes a single job. Run the task itself in a job that needs
+the matrix job.
+
+--split-by expands jobs over the allowed values of a parameter
+('requires $platform from ["linux", "windows"]'), one job per value.
+
+Examples:
+  xdrun cmd:plan ci                                   # Show the parallel groups of ci
+  xdrun cmd:plan ci --format gha-matrix               # Print the matrix JSON
+  xdrun cmd:plan ci --format gha-matrix --split-by platform
+
+In a workflow:
+  - id: plan
+    run: echo "matrix=$(xdrun cmd:plan ci --format gha-matrix)" >> "$GITHUB_OUTPUT"
+  ...
+  strategy:
+    matrix: ${{ fromJSON(needs.plan.outputs.matrix) }}
+  steps:
+    - run: xdrun ${{ matrix.task }} ${{ matrix.args }}
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: CompleteTaskNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runPlan(cmd.OutOrStdout(), configFile, args[0], format, splitBy)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or gha-matrix")
+	cmd.Flags().StringVar(&splitBy, "split-by", "", "Parameter whose allowed values split each job")
+
+	return cmd
+}
+
+// runPlan writes the matrix of taskName in the requested format
+func runPlan(out io.Writer, configFile, taskName, format, splitBy string) error {
+	if format != "text" && format != "gha-matrix" {
+		return fmt.Errorf("unknown format %q: use text or gha-matrix", format)
+	}
+
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:plan intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	tasks, err := engine.NewEngine(io.Discard).ListTasksWithIncludes(program, actualConfigFile)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]engine.TaskInfo, len(tasks))
+	for _, info := range tasks {
+		if _, exists := byName[info.Name]; !exists {
+			byName[info.Name] = info
+		}
+	}
+	root, found := byName[taskName]
+	if !found {
+		return fmt.Errorf("task '%s' not found", taskName)
+	}
+
+	entries, err := planMatrix(root, byName, splitBy)
+	if err != nil {
+		return err
+	}
+
+	if format == "gha-matrix" {
+		data, err := json.Marshal(map[string][]matrixEntry{"include": entries})
+		if err != nil {
+			return fmt.Errorf("failed to encode matrix: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	stage := 0
+	for _, entry := range entries {
+		if entry.Stage != stage {
+			stage = entry.Stage
+			_, _ = fmt.Fprintf(out, "Stage %d:\n", stage)
+		}
+		_, _ = fmt.Fprintf(out, "  xdrun %s\n", strings.TrimSpace(entry.Task+" "+entry.Args))
+	}
+	if entries[0].Task != root.Name {
+		_, _ = fmt.Fprintf(out, "Then: xdrun %s\n", root.Name)
+	}
+	return nil
+}
+
+// planMatrix returns the matrix jobs of root: the tasks of its parallel
+// dependency groups, or root itself when it has none
+func planMatrix(root engine.TaskInfo, byName map[string]engine.TaskInfo, splitBy string) ([]matrixEntry, error) {
+	splitBy = strings.TrimPrefix(splitBy, "$")
+	var jobs []engine.TaskInfo
+	var stages []int
+	stage := 0
+	for _, group := range root.DependencyGroups {
+		if len(group) < 2 {
+			continue
+		}
+		stage++
+		for _, depName := range group {
+			dep, found := lookupDependency(root, depName, byName)
+			if !found {
+				return nil, fmt.Errorf("task '%s' depends on unknown task '%s'", root.Name, depName)
+			}
+			jobs = append(jobs, dep)
+			stages = append(stages, stage)
+		}
+	}
+	if len(jobs) == 0 {
+		jobs, stages = []engine.TaskInfo{root}, []int{1}
+	}
+
+	var entries []matrixEntry
+	for i, job := range jobs {
+		values := splitValues(job, splitBy)
+		if len(values) == 0 {
+			entries = append(entries, matrixEntry{Name: job.Name, Task: job.Name, Stage: stages[i]})
+			continue
+		}
+		for _, value := range values {
+			entries = append(entries, matrixEntry{
+				Name:   fmt.Sprintf("%s (%s=%s)", job.Name, splitBy, value),
+				Task:   job.Name,
+				Stage:  stages[i],
+				Args:   splitBy + "=" + value,
+				Params: map[string]string{splitBy: value},
+			})
+		}
+	}
+	return entries, nil
+}
+
+// splitValues returns the allowed values of the splitBy parameter of info
+func splitValues(info engine.TaskInfo, splitBy string) []string {
+	if splitBy == "" {
+		return nil
+	}
+	for _, param := range info.Parameters {
+		if strings.TrimPrefix(param.Name, "$") == splitBy {
+			return param.Constraints
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const planSpec = `version: 2.0
+
+task "prepare":
+  info "prepare"
+
+task "lint":
+  depends on prepare
+  info "lint"
+
+task "build":
+  requires $platform from ["linux", "windows"]
+  info "build {$platform}"
+
+task "ci":
+  depends on prepare
+  depends on lint in parallel, build in parallel
+  info "ci"
+`
+
+func TestRunPlanGHAMatrix(t *testing.T) {
+	withCompletionSpec(t, planSpec)
+
+	var out bytes.Buffer
+	if err := runPlan(&out, "", "ci", "gha-matrix", "$platform"); err != nil {
+		t.Fatalf("runPlan() error = %v", err)
+	}
+
+	var matrix struct {
+		Include []matrixEntry `json:"include"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &matrix); err != nil {
+		t.Fatalf("matrix is not JSON: %v\n%s", err, out.String())
+	}
+
+	var names []string
+	for _, entry := range matrix.Include {
+		names = append(names, entry.Name+" -> "+strings.TrimSpace(entry.Task+" "+entry.Args))
+	}
+	want := []string{"lint -> lint", "build (platform=linux) -> build platform=linux", "build (platform=windows) -> build platform=windows"}
+	if strings.Join(names, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("matrix jobs =\n%s\nwant:\n%s", strings.Join(names, "\n"), strings.Join(want, "\n"))
+	}
+	if matrix.Include[1].Params["platform"] != "linux" {
+		t.Fatalf("expected the split parameter in params, got %+v", matrix.Include[1])
+	}
+}
+
+func TestRunPlanText(t *testing.T) {
+	withCompletionSpec(t, planSpec)
+
+	var out bytes.Buffer
+	if err := runPlan(&out, "", "ci", "text", ""); err != nil {
+		t.Fatalf("runPlan() error = %v", err)
+	}
+	want := "Stage 1:\n  xdrun lint\n  xdrun build\nThen: xdrun ci\n"
+	if out.String() != want {
+		t.Fatalf("runPlan() output =\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := runPlan(&out, "", "lint", "text", ""); err != nil {
+		t.Fatalf("runPlan() error = %v", err)
+	}
+	if out.String() != "Stage 1:\n  xdrun lint\n" {
+		t.Fatalf("expected a task without parallel dependencies to be one job, got:\n%s", out.String())
+	}
+
+	if err := runPlan(&out, "", "ci", "yaml", ""); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Fatalf("expected an unknown format error, got %v", err)
+	}
+}
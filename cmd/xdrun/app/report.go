@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+)
+
+// Domain: Run Reports
+// This file implements --report format=file, which writes a report of the
+// run once it ends. The html format is a standalone page with the task
+// timeline, the parameters of every task and the output of every statement.
+
+// reportFormats are the formats --report accepts
+var reportFormats = []string{"html"}
+
+// reportSpec is one --report: the format and the file to write it to
+type reportSpec struct {
+	Format string
+	Path   string
+}
+
+// parseReportFlags parses the values of --report
+func parseReportFlags(values []string) ([]reportSpec, error) {
+	var reports []reportSpec
+	for _, value := range values {
+		format, path, ok := strings.Cut(value, "=")
+		format, path = strings.ToLower(strings.TrimSpace(format)), strings.TrimSpace(path)
+		if !ok || format == "" || path == "" {
+			return nil, fmt.Errorf("invalid --report %q: expected format=file, such as html=report.html", value)
+		}
+		if format != "html" {
+			return nil, fmt.Errorf("unknown report format %q (supported: %s)", format, strings.Join(reportFormats, ", "))
+		}
+		reports = append(reports, reportSpec{Format: format, Path: path})
+	}
+	return reports, nil
+}
+
+// writeReports writes the reports of a finished run recorded by observer
+func writeReports(reports []reportSpec, observer *engine.HTMLObserver, title string, verbose bool) error {
+	for _, report := range reports {
+		// #nosec G304 -- the report file is chosen by the user on the command line.
+		f, err := os.Create(report.Path)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		err = observer.WriteHTML(f, title)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write report %s: %w", report.Path, err)
+		}
+		if verbose {
+			_, _ = fmt.Fprintf(os.Stdout, "📄 Report written to %s\n", report.Path)
+		}
+	}
+	return nil
+}
+
+// reportTitle names the run in reports after the tasks it ran
+func reportTitle(targets []engine.TaskTarget) string {
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	return "xdrun " + strings.Join(names, " ")
+}
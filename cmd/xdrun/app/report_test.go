@@ -0,0 +1,30 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReportFlags(t *testing.T) {
+	got, err := parseReportFlags([]string{"html=report.html", "HTML = out/run.html"})
+	if err != nil {
+		t.Fatalf("parseReportFlags() error = %v", err)
+	}
+	want := []reportSpec{{Format: "html", Path: "report.html"}, {Format: "html", Path: "out/run.html"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseReportFlags() = %#v, want %#v", got, want)
+	}
+
+	for value, wantErr := range map[string]string{
+		"report.html":     "expected format=file",
+		"html=":           "expected format=file",
+		"pdf=report.pdf":  `unknown report format "pdf"`,
+		"=report.html":    "expected format=file",
+		"junit=junit.xml": "supported: html",
+	} {
+		if _, err := parseReportFlags([]string{value}); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseReportFlags(%q) error = %v, want %q", value, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Rerun
+// This file contains the cmd:rerun command that replays the items of
+// parallel loops that failed in the last run, with the same tasks and
+// parameters.
+
+// createRerunCommand creates the cmd:rerun subcommand
+func (a *App) createRerunCommand() *cobra.Command {
+	var configFile string
+	var lastFailed, dryRun, verbose, noInput bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:rerun --last-failed",
+		Short: "Replay the failed items of the parallel loops of the last run",
+		Long: `Replay the items of parallel loops that failed in the last run of the
+project, with the same tasks and parameters.
+
+When a parallel loop has failed items, drun records them in
+~/.drun/last-failed.json. A replay runs the same tasks again, but each
+parallel loop that ran only runs its failed items; loops the last run did
+not reach run every item. Items that fail again stay recorded for the next
+replay, and a replay whose loops all pass clears the record.
+
+Examples:
+  xdrun cmd:rerun --last-failed              # Replay the failed items
+  xdrun cmd:rerun --last-failed --dry-run    # Show what would be replayed
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !lastFailed {
+				return fmt.Errorf("nothing to rerun: pass --last-failed to replay the failed loop items of the last run")
+			}
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			run, err := lastFailedRun(configFile, nil)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			writeFailedRun(out, run)
+			_, _ = fmt.Fprintln(out)
+			return ExecuteTask(RunOptions{
+				ConfigFile: configFile,
+				DryRun:     dryRun,
+				Verbose:    verbose,
+				NoInput:    noInput,
+				Args:       rerunArgs(run),
+				LoopReplay: run.Loops,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().BoolVar(&lastFailed, "last-failed", false, "Replay only the loop items that failed in the last run")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be executed without running")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed execution information")
+	cmd.Flags().BoolVar(&noInput, "no-input", false, "Never prompt for missing required parameters; fail instead")
+
+	return cmd
+}
+
+// lastFailedRun returns the latest run with failed loop items of the project
+// of the task file. A nil store is the user's default run history.
+func lastFailedRun(configFile string, store *runhistory.Store) (runhistory.FailedRun, error) {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return runhistory.FailedRun{}, fmt.Errorf("no drun task file found: %w", err)
+	}
+	eng := engine.NewEngineWithOptions(engine.WithOutput(io.Discard), engine.WithRunHistory(store))
+	run, found, err := eng.LastFailedRun(actualConfigFile)
+	if err != nil {
+		return runhistory.FailedRun{}, err
+	}
+	if !found {
+		return runhistory.FailedRun{}, fmt.Errorf("no failed loop items recorded for this project")
+	}
+	return run, nil
+}
+
+// writeFailedRun lists the tasks and loop items a replay runs
+func writeFailedRun(out io.Writer, run runhistory.FailedRun) {
+	_, _ = fmt.Fprintf(out, "Replaying %d failed loop items of run %s (failed %s)\n", run.Items(), run.Run, run.FailedAt.Format(time.RFC3339))
+	keys := make([]string, 0, len(run.Loops))
+	for key, items := range run.Loops {
+		if len(items) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(out, "  %s: %s\n", key, strings.Join(run.Loops[key], ", "))
+	}
+}
+
+// rerunArgs returns the command line arguments that run the tasks of a run
+// with the same parameters. Values starting with '@' are escaped so they are
+// not read from a file again.
+func rerunArgs(run runhistory.FailedRun) []string {
+	var args []string
+	for _, target := range run.Targets {
+		args = append(args, target.Task)
+		names := make([]string, 0, len(target.Params))
+		for name := range target.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := target.Params[name]
+			if strings.HasPrefix(value, "@") {
+				value = "@" + value
+			}
+			args = append(args, name+"="+value)
+		}
+	}
+	return args
+}
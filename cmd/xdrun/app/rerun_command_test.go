@@ -0,0 +1,53 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+)
+
+func TestLastFailedRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "spec.drun")
+	if err := os.WriteFile(file, []byte("version: 2.0\n\ntask \"check\":\n  info \"ok\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := runhistory.NewStore(filepath.Join(t.TempDir(), "run-history.json"))
+
+	if _, err := lastFailedRun(file, store); err == nil || !strings.Contains(err.Error(), "no failed loop items") {
+		t.Errorf("lastFailedRun() without a failed run = %v", err)
+	}
+
+	recorded := runhistory.FailedRun{
+		Project:  dir,
+		Run:      "run-1",
+		Targets:  []runhistory.Target{{Task: "check", Params: map[string]string{"env": "prod", "body": "@literal"}}, {Task: "report"}},
+		Loops:    map[string][]string{"check/item": {"b", "d"}, "check/other": {}},
+		FailedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := store.RecordFailed(recorded); err != nil {
+		t.Fatal(err)
+	}
+	run, err := lastFailedRun(file, store)
+	if err != nil || run.Run != "run-1" {
+		t.Fatalf("lastFailedRun() = %+v, %v", run, err)
+	}
+
+	want := []string{"check", "body=@@literal", "env=prod", "report"}
+	if got := rerunArgs(run); !reflect.DeepEqual(got, want) {
+		t.Errorf("rerunArgs() = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	writeFailedRun(&buf, run)
+	wantOutput := "Replaying 2 failed loop items of run run-1 (failed 2026-01-02T03:04:05Z)\n  check/item: b, d\n"
+	if buf.String() != wantOutput {
+		t.Errorf("writeFailedRun() = %q, want %q", buf.String(), wantOutput)
+	}
+}
@@ -2,44 +2,73 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/engine"
 	"github.com/phillarmonic/drun/v2/internal/errors"
-	"github.com/phillarmonic/drun/v2/internal/platform"
 	"github.com/phillarmonic/drun/v2/internal/secrets"
+	"golang.org/x/term"
 )
 
 // Domain: Task Execution
 // This file contains logic for loading and running drun tasks
 
+// drunVersion is the version of the running binary, exposed to tasks as
+// {drun.version}; NewApp sets it
+var drunVersion = "dev"
+
+// RunOptions holds the command-line options of a task run
+type RunOptions struct {
+	ConfigFile              string // drun file; empty to discover it
+	ListTasks               bool   // list the tasks instead of running one
+	ListTree                bool   // list the tasks with their dependencies
+	DryRun                  bool
+	CheckSyntax             bool // parse shell commands during a dry run
+	Verbose                 bool
+	TaskMode                string // overrides the task mode of every task
+	AllowUndefinedVars      bool
+	AllowToolVersionChanges bool
+	NoDrunCache             bool
+	ParallelTargets         bool
+	NoInput                 bool // never prompt for parameters
+	Force                   bool // run tasks that run history would skip
+	EventsFile              string
+	Reports                 []string // --report flags, format=path
+	WatchVar                string
+	Notify                  bool
+	Timings                 bool
+	Profile                 string
+	MaxCPU                  float64
+	MaxMemory               int64
+	MaxDuration             time.Duration
+	SkipHooks               []string            // hook kinds not to run
+	Args                    []string            // task names and their parameters
+	LoopReplay              map[string][]string // loop items to run again, by loop
+}
+
 // ExecuteTask executes a drun task with the given parameters
-func ExecuteTask(
-	configFile string,
-	listTasks bool,
-	dryRun bool,
-	verbose bool,
-	taskModeOverride string,
-	allowUndefinedVars bool,
-	allowToolVersionChanges bool,
-	noDrunCache bool,
-	args []string,
-) error {
-	taskModeOverride, err := normalizeRuntimeTaskMode(taskModeOverride)
+func ExecuteTask(opts RunOptions) error {
+	taskMode, err := normalizeRuntimeTaskMode(opts.TaskMode)
+	if err != nil {
+		return err
+	}
+	reportSpecs, err := parseReportFlags(opts.Reports)
 	if err != nil {
 		return err
 	}
 
 	// Determine the config file to use
-	actualConfigFile, err := FindConfigFile(configFile)
+	actualConfigFile, err := FindConfigFile(opts.ConfigFile)
 	if err != nil {
 		return fmt.Errorf("no drun task file found: %w\n\nTo get started:\n  drun --init          # Create .drun/spec.drun", err)
 	}
 
 	// Verbose: Show we're starting
-	if verbose {
+	if opts.Verbose {
 		_, _ = fmt.Fprintf(os.Stdout, "📂  Loading: %s\n", actualConfigFile)
 	}
 
@@ -50,7 +79,7 @@ func ExecuteTask(
 		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
 	}
 
-	if verbose {
+	if opts.Verbose {
 		_, _ = fmt.Fprintf(os.Stdout, "🔍  Parsing drun file...\n")
 	}
 
@@ -60,49 +89,90 @@ func ExecuteTask(
 		// Check if it's an enhanced error list
 		if errorList, ok := err.(*errors.ParseErrorList); ok {
 			fmt.Fprint(os.Stderr, errorList.FormatErrors())
-			os.Exit(1)
+			os.Exit(ExitParseError)
 		}
-		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+		return withExitCode(ExitParseError, fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err))
 	}
 
-	if verbose {
+	if opts.Verbose {
 		_, _ = fmt.Fprintf(os.Stdout, "✅  Parsed successfully\n")
 	}
 
-	// Initialize secrets manager using platform-appropriate backend
-	secretsMgr, err := secrets.NewManager()
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to initialize secrets manager: %v\n", err)
-		secretsMgr = nil
-	}
+	// The secrets backend is probed when a task first reads a secret, so
+	// listing tasks never touches the system keychain
+	secretsMgr := secrets.NewLazyManager()
 
 	userConfig, err := loadUserConfig()
 	if err != nil {
 		return err
 	}
 
-	// Create engine with secrets support
-	eng := engine.NewEngineWithOptions(
+	engineOptions := []engine.Option{
 		engine.WithOutput(os.Stdout),
-		engine.WithDryRun(dryRun),
-		engine.WithVerbose(verbose),
-		engine.WithTaskModeOverride(taskModeOverride),
-		engine.WithAllowToolVersionChanges(allowToolVersionChanges),
+		engine.WithDryRun(opts.DryRun),
+		engine.WithShellSyntaxCheck(opts.CheckSyntax),
+		engine.WithVerbose(opts.Verbose),
+		engine.WithTaskModeOverride(taskMode),
+		engine.WithAllowToolVersionChanges(opts.AllowToolVersionChanges),
 		engine.WithUserProvisioningSources(userConfig.ProvisioningSources),
 		engine.WithSecretsManager(secretsMgr),
-	)
-	eng.SetAllowUndefinedVars(allowUndefinedVars)
+		engine.WithDefaultOutputStyle(userConfig.OutputStyle),
+		engine.WithDefaultStatusSymbols(userConfig.StatusSymbols),
+		engine.WithDefaultStatusColors(userConfig.StatusColors),
+		engine.WithIncludeCacheTTL(userConfig.cacheTTL()),
+		engine.WithDefaultParallelism(userConfig.Parallelism),
+		engine.WithResourceLimits(opts.MaxCPU, opts.MaxMemory),
+		engine.WithMaxDuration(opts.MaxDuration),
+		engine.WithSkipHooks(opts.SkipHooks...),
+		engine.WithParamPrompter(terminalParamPrompter(opts.NoInput)),
+		engine.WithForce(opts.Force),
+		engine.WithWatchVariable(opts.WatchVar),
+		engine.WithNotify(opts.Notify),
+		engine.WithTimings(opts.Timings),
+		engine.WithDrunVersion(drunVersion),
+	}
+	if userConfig.NetworkRetries != nil {
+		engineOptions = append(engineOptions, engine.WithDefaultNetworkRetries(*userConfig.NetworkRetries))
+	}
+	if opts.LoopReplay != nil {
+		engineOptions = append(engineOptions, engine.WithLoopReplay(opts.LoopReplay))
+	}
+	// Piped input is exposed to tasks as {stdin}; a terminal is left to prompts
+	if !term.IsTerminal(int(os.Stdin.Fd())) { // #nosec G115 -- file descriptors fit in int
+		engineOptions = append(engineOptions, engine.WithStdin(os.Stdin))
+	}
+	if opts.EventsFile != "" {
+		events, closeEvents, err := openEventsFile(opts.EventsFile)
+		if err != nil {
+			return err
+		}
+		defer closeEvents()
+		engineOptions = append(engineOptions, engine.WithObserver(engine.NewJSONObserver(events)))
+	}
+	var reportObserver *engine.HTMLObserver
+	if len(reportSpecs) > 0 {
+		reportObserver = engine.NewHTMLObserver()
+		engineOptions = append(engineOptions, engine.WithObserver(reportObserver))
+	}
 
-	if verbose {
-		if noDrunCache {
+	// Create engine with secrets support
+	eng := engine.NewEngineWithOptions(engineOptions...)
+	eng.SetAllowUndefinedVars(opts.AllowUndefinedVars)
+
+	if opts.Verbose {
+		if opts.NoDrunCache {
 			_, _ = fmt.Fprintf(os.Stdout, "💾 Remote include caching: disabled\n")
 		} else {
-			_, _ = fmt.Fprintf(os.Stdout, "💾 Remote include caching: enabled (1m expiration)\n")
+			ttl := userConfig.cacheTTL()
+			if ttl == 0 {
+				ttl = time.Minute
+			}
+			_, _ = fmt.Fprintf(os.Stdout, "💾 Remote include caching: enabled (%s expiration)\n", ttl)
 		}
 	}
 
-	// Initialize cache for remote includes
-	if err := eng.SetCacheEnabled(!noDrunCache); err != nil {
+	// Enable the remote include cache; it is opened on the first remote fetch
+	if err := eng.SetCacheEnabled(!opts.NoDrunCache); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to initialize remote include cache: %v\n", err)
 	}
 
@@ -110,77 +180,85 @@ func ExecuteTask(
 	defer eng.Cleanup()
 
 	// Handle --list flag
-	if listTasks {
-		return ListAllTasks(eng, program)
+	if opts.ListTasks {
+		return ListAllTasks(eng, program, actualConfigFile, opts.ListTree)
 	}
 
-	// Determine target task and parse parameters
-	var target string
-	var params map[string]string
+	// Determine target tasks and parse parameters
+	var targets []engine.TaskTarget
 
-	if len(args) == 0 {
+	if len(opts.Args) == 0 {
 		// No arguments - try to find a default task or list tasks
-		if defaultTask := FindDefaultTask(program); defaultTask != "" {
-			target = defaultTask
-		} else {
-			return ListAllTasks(eng, program)
+		defaultTask := FindDefaultTask(program)
+		if defaultTask == "" {
+			return ListAllTasks(eng, program, actualConfigFile, opts.ListTree)
 		}
-		params = make(map[string]string)
+		targets = []engine.TaskTarget{{Name: defaultTask, Params: make(map[string]string)}}
 	} else {
-		// Resolve partial task name to full task name
-		partialName := args[0]
-		resolvedName, err := ResolvePartialTaskName(partialName, program)
+		targets, err = ParseTaskTargets(opts.Args, program)
 		if err != nil {
-			return fmt.Errorf("%w\n\nRun 'xdrun --list' to see all available tasks", err)
+			return errors.NewValidationError(fmt.Errorf("%w\n\nRun 'xdrun --list' to see all available tasks", err))
 		}
-		target = resolvedName
 
-		// Show which task was resolved if it's a partial match
-		if verbose && partialName != resolvedName {
-			_, _ = fmt.Fprintf(os.Stdout, "🎯 Resolved '%s' → '%s'\n", partialName, resolvedName)
+		// Show which tasks were resolved from partial matches
+		if opts.Verbose {
+			for i, partialName := range taskNameArgs(opts.Args) {
+				if partialName != targets[i].Name {
+					_, _ = fmt.Fprintf(os.Stdout, "🎯 Resolved '%s' → '%s'\n", partialName, targets[i].Name)
+				}
+			}
 		}
+	}
 
-		params = ParseTaskParameters(args[1:])
+	if opts.Profile != "" {
+		if err := applyProfile(program, opts.Profile, targets); err != nil {
+			return err
+		}
+		if opts.Verbose {
+			_, _ = fmt.Fprintf(os.Stdout, "🎛️  Using opts.Profile: %s\n", opts.Profile)
+		}
 	}
 
-	// Execute the task with parameters
-	err = eng.ExecuteWithParamsAndFile(program, target, params, actualConfigFile)
+	// Execute the tasks with parameters
+	err = eng.ExecuteTargets(program, targets, actualConfigFile, opts.ParallelTargets)
+	if reportObserver != nil {
+		if reportErr := writeReports(reportSpecs, reportObserver, reportTitle(targets), opts.Verbose); reportErr != nil {
+			if err == nil {
+				return reportErr
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", reportErr)
+		}
+	}
 	if err != nil {
 		// Check if it's a parameter validation error
 		if paramErr, ok := err.(*errors.ParameterValidationError); ok {
-			fmt.Fprintf(os.Stderr, "Error: %s\n", paramErr.Message)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, eng.Localize(fmt.Sprintf("Error: %s", paramErr.Message)))
+			os.Exit(ExitValidationError)
 		}
-		fmt.Fprintf(os.Stderr, "Error: execution failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, eng.Localize(fmt.Sprintf("Error: execution failed: %v", err)))
+		os.Exit(ExitCode(withExitCode(ExitTaskFailure, err)))
 	}
 
 	return nil
 }
 
-// ListAllTasks lists all available tasks
-func ListAllTasks(eng *engine.Engine, program *ast.Program) error {
-	fmt.Println("Available tasks:")
-
-	tasks := eng.ListTasks(program)
-	if len(tasks) == 0 {
-		fmt.Println("  (no tasks defined)")
-		return nil
+// FindDefaultTask finds the task to run when xdrun is invoked without one:
+// the project's `default task is "name"` setting, then a task marked
+// `default`, then a task named "default" or "help"
+func FindDefaultTask(program *ast.Program) string {
+	if program.Project != nil {
+		for _, setting := range program.Project.Settings {
+			if defaultTask, ok := setting.(*ast.DefaultTaskStatement); ok {
+				return defaultTask.TaskName
+			}
+		}
 	}
-
-	for _, task := range tasks {
-		platformSuffix := ""
-		if len(task.Platforms) > 0 {
-			platformSuffix = " [" + platform.FormatList(task.Platforms) + "]"
+	for _, task := range program.Tasks {
+		if task.Default {
+			return task.Name
 		}
-		fmt.Printf("  %-20s  %s\n", task.Name+platformSuffix, task.Description)
 	}
 
-	return nil
-}
-
-// FindDefaultTask finds a default task to run
-func FindDefaultTask(program *ast.Program) string {
 	// Look for common default task names. We restrict this to safe informational
 	// tasks so orchestrations do not launch unless explicitly requested.
 	defaultNames := []string{"default", "help"}
@@ -196,6 +274,125 @@ func FindDefaultTask(program *ast.Program) string {
 	return ""
 }
 
+// ParseTaskTargets splits command line arguments into the tasks to run.
+// Arguments without '=' name tasks (partial names are resolved); param=value
+// arguments apply to the task named before them, or to the first task when
+// they come before any task name:
+//
+//	xdrun build target=linux test package
+func ParseTaskTargets(args []string, program *ast.Program) ([]engine.TaskTarget, error) {
+	var targets []engine.TaskTarget
+	var leading []string
+
+	for _, arg := range args {
+		if strings.Contains(arg, "=") {
+			if len(targets) == 0 {
+				leading = append(leading, arg)
+			} else {
+				last := &targets[len(targets)-1]
+				for k, v := range ParseTaskParameters([]string{arg}) {
+					last.Params[k] = v
+				}
+			}
+			continue
+		}
+
+		resolvedName, err := ResolvePartialTaskName(arg, program)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, engine.TaskTarget{Name: resolvedName, Params: make(map[string]string)})
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no task specified")
+	}
+	for k, v := range ParseTaskParameters(leading) {
+		if _, ok := targets[0].Params[k]; !ok {
+			targets[0].Params[k] = v
+		}
+	}
+
+	if err := resolveParameterSources(targets, os.Stdin); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// resolveParameterSources replaces parameter values of the form @path with
+// the contents of the file and @- with standard input, so large payloads can
+// be passed without shell quoting. Trailing newlines are trimmed, as in shell
+// command substitution. A leading @@ escapes a literal '@'.
+func resolveParameterSources(targets []engine.TaskTarget, stdin io.Reader) error {
+	stdinUsedBy := ""
+	for _, target := range targets {
+		for name, value := range target.Params {
+			switch {
+			case strings.HasPrefix(value, "@@"):
+				target.Params[name] = value[1:]
+			case value == "@-":
+				if stdinUsedBy != "" {
+					return fmt.Errorf("parameters '%s' and '%s' both read from stdin (@-); only one parameter can", stdinUsedBy, name)
+				}
+				stdinUsedBy = name
+				content, err := io.ReadAll(stdin)
+				if err != nil {
+					return fmt.Errorf("parameter '%s': failed to read stdin: %w", name, err)
+				}
+				target.Params[name] = strings.TrimRight(string(content), "\r\n")
+			case strings.HasPrefix(value, "@") && len(value) > 1:
+				// #nosec G304 -- the user explicitly names the file to read on the command line.
+				content, err := os.ReadFile(value[1:])
+				if err != nil {
+					return fmt.Errorf("parameter '%s': failed to read value from '%s': %w (use @@ for a literal '@')", name, value[1:], err)
+				}
+				target.Params[name] = strings.TrimRight(string(content), "\r\n")
+			}
+		}
+	}
+	return nil
+}
+
+// applyProfile fills in the parameter values of the named project profile for
+// every target. Values given on the command line take precedence.
+func applyProfile(program *ast.Program, name string, targets []engine.TaskTarget) error {
+	var profile *ast.ProfileStatement
+	if program.Project != nil {
+		profile = program.Project.Profile(name)
+	}
+	if profile == nil {
+		var available []string
+		if program.Project != nil {
+			available = program.Project.ProfileNames()
+		}
+		if len(available) == 0 {
+			return fmt.Errorf("unknown profile '%s': the project defines no profiles", name)
+		}
+		return fmt.Errorf("unknown profile '%s' (available: %s)", name, strings.Join(available, ", "))
+	}
+
+	for k, v := range profile.Params() {
+		for _, target := range targets {
+			if _, ok := target.Params[k]; !ok {
+				target.Params[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// taskNameArgs returns the arguments that name tasks, in order
+func taskNameArgs(args []string) []string {
+	var names []string
+	for _, arg := range args {
+		if !strings.Contains(arg, "=") {
+			names = append(names, arg)
+		}
+	}
+	return names
+}
+
 // ParseTaskParameters parses task parameters from command line arguments
 // Supports format: param1=value1 param2=value2
 func ParseTaskParameters(args []string) map[string]string {
@@ -212,3 +409,17 @@ func ParseTaskParameters(args []string) map[string]string {
 
 	return params
 }
+
+// openEventsFile opens the destination of --events-json: standard error for
+// "-", otherwise the file, truncated
+func openEventsFile(path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return os.Stderr, func() {}, nil
+	}
+	// #nosec G304 -- the events file is chosen by the user on the command line.
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create events file: %w", err)
+	}
+	return f, func() { _ = f.Close() }, nil
+}
@@ -1,9 +1,13 @@
 package app
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
 )
 
 func TestFindDefaultTaskPrefersDefaultOverEarlierStart(t *testing.T) {
@@ -57,3 +61,150 @@ func TestFindDefaultTaskDoesNotAutoRunStart(t *testing.T) {
 		t.Fatalf("expected empty default task when only start is defined, got %q", got)
 	}
 }
+
+func TestFindDefaultTaskPrefersProjectSetting(t *testing.T) {
+	program := &ast.Program{
+		Project: &ast.ProjectStatement{
+			Settings: []ast.ProjectSetting{&ast.DefaultTaskStatement{TaskName: "help-menu"}},
+		},
+		Tasks: []*ast.TaskStatement{
+			{Name: "default"},
+			{Name: "build", Default: true},
+			{Name: "help-menu"},
+		},
+	}
+
+	if got := FindDefaultTask(program); got != "help-menu" {
+		t.Fatalf("expected project default task to be selected, got %q", got)
+	}
+}
+
+func TestFindDefaultTaskUsesDefaultMarker(t *testing.T) {
+	program := &ast.Program{
+		Tasks: []*ast.TaskStatement{
+			{Name: "help"},
+			{Name: "build", Default: true},
+		},
+	}
+
+	if got := FindDefaultTask(program); got != "build" {
+		t.Fatalf("expected task marked default to be selected, got %q", got)
+	}
+}
+
+func TestParseTaskTargetsAssignsParamsToPrecedingTask(t *testing.T) {
+	program := &ast.Program{
+		Tasks: []*ast.TaskStatement{
+			{Name: "build"},
+			{Name: "test"},
+			{Name: "package"},
+		},
+	}
+
+	targets, err := ParseTaskTargets([]string{"env=ci", "build", "target=linux", "test", "package", "format=tar"}, program)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(targets))
+	}
+
+	names := []string{targets[0].Name, targets[1].Name, targets[2].Name}
+	if names[0] != "build" || names[1] != "test" || names[2] != "package" {
+		t.Fatalf("unexpected target order: %v", names)
+	}
+	if targets[0].Params["env"] != "ci" || targets[0].Params["target"] != "linux" {
+		t.Fatalf("unexpected build params: %v", targets[0].Params)
+	}
+	if len(targets[1].Params) != 0 {
+		t.Fatalf("expected no params for test, got %v", targets[1].Params)
+	}
+	if targets[2].Params["format"] != "tar" {
+		t.Fatalf("unexpected package params: %v", targets[2].Params)
+	}
+}
+
+func TestParseTaskTargetsRejectsUnknownTask(t *testing.T) {
+	program := &ast.Program{Tasks: []*ast.TaskStatement{{Name: "build"}}}
+
+	if _, err := ParseTaskTargets([]string{"build", "publish"}, program); err == nil {
+		t.Fatal("expected an error for an unknown task")
+	}
+	if _, err := ParseTaskTargets([]string{"env=ci"}, program); err == nil {
+		t.Fatal("expected an error when no task is named")
+	}
+}
+
+func TestResolveParameterSourcesReadsFilesAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	valuesPath := filepath.Join(dir, "values.json")
+	if err := os.WriteFile(valuesPath, []byte("{\"replicas\": 3}\n"), 0600); err != nil {
+		t.Fatalf("write values: %v", err)
+	}
+
+	targets := []engine.TaskTarget{{Name: "deploy", Params: map[string]string{
+		"config":   "@" + valuesPath,
+		"manifest": "@-",
+		"scope":    "@@types/node",
+		"env":      "prod",
+	}}}
+	if err := resolveParameterSources(targets, strings.NewReader("kind: Deployment\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params := targets[0].Params
+	if params["config"] != `{"replicas": 3}` {
+		t.Errorf("config = %q", params["config"])
+	}
+	if params["manifest"] != "kind: Deployment" {
+		t.Errorf("manifest = %q", params["manifest"])
+	}
+	if params["scope"] != "@types/node" || params["env"] != "prod" {
+		t.Errorf("unexpected literal params: %v", params)
+	}
+}
+
+func TestResolveParameterSourcesErrors(t *testing.T) {
+	missing := []engine.TaskTarget{{Name: "deploy", Params: map[string]string{"config": "@does-not-exist.json"}}}
+	err := resolveParameterSources(missing, strings.NewReader(""))
+	if err == nil || !strings.Contains(err.Error(), "parameter 'config'") {
+		t.Errorf("expected missing file error, got %v", err)
+	}
+
+	twice := []engine.TaskTarget{
+		{Name: "build", Params: map[string]string{"a": "@-"}},
+		{Name: "deploy", Params: map[string]string{"b": "@-"}},
+	}
+	err = resolveParameterSources(twice, strings.NewReader("x"))
+	if err == nil || !strings.Contains(err.Error(), "both read from stdin") {
+		t.Errorf("expected stdin reuse error, got %v", err)
+	}
+}
+
+func TestApplyProfileFillsParamsNotGivenOnCommandLine(t *testing.T) {
+	program := &ast.Program{Project: &ast.ProjectStatement{Settings: []ast.ProjectSetting{
+		&ast.ProfileStatement{Name: "prod", Values: []*ast.SetStatement{
+			{Key: "env", Value: &ast.LiteralExpression{Value: "prod"}},
+			{Key: "replicas", Value: &ast.LiteralExpression{Value: "5"}},
+		}},
+	}}}
+
+	targets := []engine.TaskTarget{
+		{Name: "build", Params: map[string]string{}},
+		{Name: "deploy", Params: map[string]string{"replicas": "9"}},
+	}
+	if err := applyProfile(program, "prod", targets); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if targets[0].Params["env"] != "prod" || targets[0].Params["replicas"] != "5" {
+		t.Errorf("unexpected build params: %v", targets[0].Params)
+	}
+	if targets[1].Params["env"] != "prod" || targets[1].Params["replicas"] != "9" {
+		t.Errorf("command-line value should win: %v", targets[1].Params)
+	}
+
+	err := applyProfile(program, "staging", targets)
+	if err == nil || !strings.Contains(err.Error(), "available: prod") {
+		t.Errorf("expected unknown profile error, got %v", err)
+	}
+}
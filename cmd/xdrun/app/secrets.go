@@ -13,7 +13,8 @@ import (
 )
 
 // Domain: Secret Management Commands
-// This file contains CLI commands for managing secrets (add, remove, list)
+// This file contains CLI commands for managing secrets (add, remove, list,
+// and encrypt values for 'set encrypted' in drun files)
 
 // createSecretsCommand creates the cmd:secret subcommand for managing secrets
 func (a *App) createSecretsCommand() *cobra.Command {
@@ -35,6 +36,7 @@ Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts wit
 	cmd.AddCommand(createSecretRemoveCommand())
 	cmd.AddCommand(createSecretListCommand())
 	cmd.AddCommand(createSecretListAllCommand())
+	cmd.AddCommand(createSecretEncryptCommand())
 
 	return cmd
 }
@@ -129,6 +131,64 @@ If no value is provided, you'll be prompted to enter it securely.`,
 	return cmd
 }
 
+// createSecretEncryptCommand creates the "encrypt" subcommand
+func createSecretEncryptCommand() *cobra.Command {
+	var recipients []string
+
+	cmd := &cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypt a value for 'set encrypted' in a drun file",
+		Long: `Encrypt a value with age and print it as ENC[...], ready for
+  set encrypted db_password to "ENC[...]"
+
+The value is encrypted to each --to age1... public key; without --to it is
+encrypted to your own age identities ($SOPS_AGE_KEY, $SOPS_AGE_KEY_FILE or
+the SOPS keys.txt). If no value is provided, you'll be prompted to enter it.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var to []*secrets.AgeRecipient
+			for _, text := range recipients {
+				recipient, err := secrets.ParseAgeRecipient(text)
+				if err != nil {
+					return err
+				}
+				to = append(to, recipient)
+			}
+			if len(to) == 0 {
+				identities, err := secrets.LoadAgeIdentities()
+				if err != nil {
+					return fmt.Errorf("%w; pass --to age1... to encrypt to a public key", err)
+				}
+				for _, identity := range identities {
+					to = append(to, identity.Recipient())
+				}
+			}
+
+			var plaintext string
+			if len(args) == 1 {
+				plaintext = args[0]
+			} else {
+				var err error
+				plaintext, err = promptForSecret("Enter value to encrypt: ")
+				if err != nil {
+					return fmt.Errorf("failed to read value: %w", err)
+				}
+			}
+
+			value, err := secrets.EncryptValue(plaintext, to)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&recipients, "to", nil, "age public key (age1...) to encrypt to; repeatable")
+
+	return cmd
+}
+
 // createSecretRemoveCommand creates the "remove" subcommand
 func createSecretRemoveCommand() *cobra.Command {
 	var (
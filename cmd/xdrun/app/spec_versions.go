@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+)
+
+// Domain: Spec Versions
+// This file contains the --spec-versions report that lists the drun files of
+// a repository with the spec version each declares, to follow a file-by-file
+// migration.
+
+// specVersionSkipDirs are directories that never hold the project's own drun files
+var specVersionSkipDirs = map[string]bool{".git": true, "node_modules": true}
+
+// ReportSpecVersions lists every .drun file under root with its declared
+// spec version and whether this binary runs it
+func ReportSpecVersions(out io.Writer, root string) error {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root && specVersionSkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".drun") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s for drun files: %w", root, err)
+	}
+
+	if len(files) == 0 {
+		_, _ = fmt.Fprintf(out, "No .drun files found under %s\n", root)
+		return nil
+	}
+
+	width := 0
+	for _, file := range files {
+		if rel, err := filepath.Rel(root, file); err == nil && len(rel) > width {
+			width = len(rel)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "Spec versions (this binary runs version %s):\n", strings.Join(engine.SupportedSpecVersions, ", "))
+	var supported, unsupported, unversioned int
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = file
+		}
+
+		// #nosec G304 -- the report intentionally reads the drun files it found.
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read drun file '%s': %w", file, err)
+		}
+
+		version := engine.SpecVersion(string(content))
+		var status string
+		switch {
+		case version == "":
+			version = "-"
+			status = "⚠️  no version statement"
+			unversioned++
+		case engine.IsSupportedSpecVersion(version):
+			status = "✅ supported"
+			supported++
+		default:
+			status = fmt.Sprintf("❌ needs a version %s binary", engine.SpecMajorVersion(version))
+			unsupported++
+		}
+		_, _ = fmt.Fprintf(out, "  %-*s  %-5s  %s\n", width, rel, version, status)
+	}
+
+	_, _ = fmt.Fprintf(out, "%d files: %d supported, %d unsupported, %d without a version\n",
+		len(files), supported, unsupported, unversioned)
+	return nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportSpecVersions(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".drun/spec.drun":         "version: 2.0\n\ntask \"a\":\n  info \"x\"\n",
+		"legacy/tasks.drun":       "# old\nversion: 1.0\n",
+		"scratch.drun":            "task \"a\":\n  info \"x\"\n",
+		"node_modules/x/pkg.drun": "version: 1.0\n",
+		".git/hooks/ignored.drun": "version: 1.0\n",
+		"legacy/README.md":        "version: 1.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := ReportSpecVersions(&out, root); err != nil {
+		t.Fatalf("ReportSpecVersions() error = %v", err)
+	}
+
+	report := out.String()
+	for _, want := range []string{
+		".drun/spec.drun    2.0    ✅ supported",
+		"legacy/tasks.drun  1.0    ❌ needs a version 1 binary",
+		"scratch.drun       -      ⚠️  no version statement",
+		"3 files: 1 supported, 1 unsupported, 1 without a version",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "node_modules") || strings.Contains(report, "ignored.drun") {
+		t.Errorf("report includes skipped directories:\n%s", report)
+	}
+}
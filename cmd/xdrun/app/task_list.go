@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/domain/task"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/platform"
+)
+
+// Domain: Task Listing
+// This file contains the --list output: tasks grouped by include namespace,
+// and the --list --tree view with tags, parameters and dependency trees.
+
+// ListAllTasks lists all available tasks, including the tasks brought in by
+// includes; with tree set each task also shows its tags, parameters and
+// dependency tree
+func ListAllTasks(eng *engine.Engine, program *ast.Program, configFile string, tree bool) error {
+	tasks, err := eng.ListTasksWithIncludes(program, configFile)
+	if err != nil {
+		return err
+	}
+	renderTaskList(os.Stdout, tasks, tree)
+	return nil
+}
+
+// renderTaskList writes the program's own tasks, then one group per include
+// namespace in the order the namespaces were first seen
+func renderTaskList(out io.Writer, tasks []engine.TaskInfo, tree bool) {
+	_, _ = fmt.Fprintln(out, "Available tasks:")
+	if len(tasks) == 0 {
+		_, _ = fmt.Fprintln(out, "  (no tasks defined)")
+		return
+	}
+
+	byName := make(map[string]engine.TaskInfo, len(tasks))
+	groups := make(map[string][]engine.TaskInfo)
+	var namespaces []string
+	for _, info := range tasks {
+		if _, exists := byName[info.Name]; !exists {
+			byName[info.Name] = info
+		}
+		if _, seen := groups[info.Namespace]; !seen && info.Namespace != "" {
+			namespaces = append(namespaces, info.Namespace)
+		}
+		groups[info.Namespace] = append(groups[info.Namespace], info)
+	}
+
+	if len(groups[""]) == 0 {
+		_, _ = fmt.Fprintln(out, "  (no tasks defined)")
+	}
+	for _, info := range groups[""] {
+		renderTaskEntry(out, info, byName, tree)
+	}
+
+	for _, namespace := range namespaces {
+		group := groups[namespace]
+		heading := namespace
+		if source := group[0].Source; source != "" {
+			heading += " (from " + filepath.ToSlash(source) + ")"
+		}
+		_, _ = fmt.Fprintf(out, "\n%s:\n", heading)
+		for _, info := range group {
+			renderTaskEntry(out, info, byName, tree)
+		}
+	}
+}
+
+// renderTaskEntry writes one task line, plus its details in tree mode
+func renderTaskEntry(out io.Writer, info engine.TaskInfo, byName map[string]engine.TaskInfo, tree bool) {
+	platformSuffix := ""
+	if len(info.Platforms) > 0 {
+		platformSuffix = " [" + platform.FormatList(info.Platforms) + "]"
+	}
+	_, _ = fmt.Fprintf(out, "  %-20s  %s\n", info.Name+platformSuffix, info.Description)
+	if !tree {
+		return
+	}
+
+	if tags := taskTags(info); len(tags) > 0 {
+		_, _ = fmt.Fprintf(out, "      tags: %s\n", strings.Join(tags, ", "))
+	}
+	if len(info.Parameters) > 0 {
+		params := make([]string, len(info.Parameters))
+		for i, param := range info.Parameters {
+			params[i] = parameterSummary(param)
+		}
+		_, _ = fmt.Fprintf(out, "      params: %s\n", strings.Join(params, ", "))
+	}
+	writeDependencyTree(out, info, byName, "      ", map[string]bool{info.Name: true})
+}
+
+// taskTags returns the short labels shown for a task in tree mode
+func taskTags(info engine.TaskInfo) []string {
+	var tags []string
+	if info.Default {
+		tags = append(tags, "default")
+	}
+	if info.Mode != "" {
+		tags = append(tags, "mode "+info.Mode)
+	}
+	return tags
+}
+
+// parameterSummary describes a parameter in one short phrase, e.g.
+// `environment (required, one of dev|prod)`
+func parameterSummary(param task.Parameter) string {
+	var details []string
+	switch {
+	case param.HasDefault:
+		details = append(details, fmt.Sprintf("default %q", param.DefaultValue))
+	case param.Required:
+		details = append(details, "required")
+	}
+	if param.DataType != "" && param.DataType != "string" {
+		details = append(details, param.DataType)
+	}
+	if len(param.Constraints) > 0 {
+		details = append(details, "one of "+strings.Join(param.Constraints, "|"))
+	}
+	if param.Variadic {
+		details = append(details, "variadic")
+	}
+	if len(details) == 0 {
+		return param.Name
+	}
+	return param.Name + " (" + strings.Join(details, ", ") + ")"
+}
+
+// lookupDependency resolves a dependency name of info, within the task's
+// namespace first
+func lookupDependency(info engine.TaskInfo, depName string, byName map[string]engine.TaskInfo) (engine.TaskInfo, bool) {
+	if info.Namespace != "" {
+		if namespaced, ok := byName[info.Namespace+"."+depName]; ok {
+			return namespaced, true
+		}
+	}
+	dep, found := byName[depName]
+	return dep, found
+}
+
+// writeDependencyTree writes the dependencies of info as a tree. Names are
+// resolved within the task's namespace first; a dependency already on the
+// current path is marked as a cycle instead of being expanded again.
+func writeDependencyTree(out io.Writer, info engine.TaskInfo, byName map[string]engine.TaskInfo, prefix string, path map[string]bool) {
+	for i, depName := range info.Dependencies {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(info.Dependencies)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+
+		dep, found := lookupDependency(info, depName, byName)
+		switch {
+		case !found:
+			_, _ = fmt.Fprintf(out, "%s%s%s (not found)\n", prefix, connector, depName)
+		case path[dep.Name]:
+			_, _ = fmt.Fprintf(out, "%s%s%s (cycle)\n", prefix, connector, dep.Name)
+		default:
+			_, _ = fmt.Fprintf(out, "%s%s%s\n", prefix, connector, dep.Name)
+			path[dep.Name] = true
+			writeDependencyTree(out, dep, byName, childPrefix, path)
+			delete(path, dep.Name)
+		}
+	}
+}
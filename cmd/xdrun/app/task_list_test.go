@@ -0,0 +1,94 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+)
+
+func listTasksForTest(t *testing.T) []engine.TaskInfo {
+	t.Helper()
+	root := t.TempDir()
+	shared := `version: 2.0
+
+project "images":
+
+task "build" means "Build the image":
+  depends on login
+  info "build"
+
+task "login":
+  info "login"
+`
+	if err := os.WriteFile(filepath.Join(root, "images.drun"), []byte(shared), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := `version: 2.0
+
+project "app":
+  include "images.drun"
+
+task "lint" means "Lint the code":
+  info "lint"
+
+task "deploy" means "Deploy the app" default:
+  requires $environment from ["dev", "prod"]
+  given $version defaults to "latest"
+  depends on lint and "images.build"
+  info "deploy"
+`
+	specFile := filepath.Join(root, "spec.drun")
+	program, err := engine.ParseStringWithFilename(spec, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	tasks, err := engine.NewEngine(&bytes.Buffer{}).ListTasksWithIncludes(program, specFile)
+	if err != nil {
+		t.Fatalf("ListTasksWithIncludes failed: %v", err)
+	}
+	return tasks
+}
+
+func TestRenderTaskListGroupsByNamespace(t *testing.T) {
+	var out bytes.Buffer
+	renderTaskList(&out, listTasksForTest(t), false)
+
+	want := `Available tasks:
+  lint                  Lint the code
+  deploy                Deploy the app
+
+images (from images.drun):
+  images.build          Build the image
+  images.login          No description
+`
+	if got := out.String(); got != want {
+		t.Errorf("unexpected listing:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderTaskListTree(t *testing.T) {
+	var out bytes.Buffer
+	renderTaskList(&out, listTasksForTest(t), true)
+
+	got := out.String()
+	for _, want := range []string{
+		"  deploy                Deploy the app\n" +
+			"      tags: default\n" +
+			`      params: environment (required, one of dev|prod), version (default "latest")` + "\n" +
+			"      ├── lint\n" +
+			"      └── images.build\n" +
+			"          └── images.login\n",
+		"  images.build          Build the image\n" +
+			"      └── images.login\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected tree output to contain:\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Golden Tests
+// This file contains the cmd:test command that runs the test blocks of the
+// task file and compares the output of their tasks with golden files.
+
+// createTestCommand creates the cmd:test subcommand
+func (a *App) createTestCommand() *cobra.Command {
+	var configFile string
+	var updateGolden bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:test [test names...]",
+		Short: "Run the test blocks of the task file against their golden files",
+		Long: `Run the test blocks of the task file and compare the output of each
+test's task with its golden file.
+
+A test block calls one task and names the golden file holding the output
+it expects, relative to the project root:
+
+  test "deploy to staging" in dry run:
+    call task "deploy" with environment="staging"
+    expect output to match golden "tests/deploy-staging.golden"
+
+Output is normalized before it is compared: colors, CRLF line endings and
+trailing spaces are removed, and the project root, home and temporary
+directories, host and user names, timestamps, dates, clock times and
+durations become placeholders such as <project> and <timestamp>.
+
+--update-golden records the current output of the tests as their golden
+files instead of comparing it; review the changes before committing them.
+
+Examples:
+  xdrun cmd:test                              # Run every test
+  xdrun cmd:test "deploy to staging"          # Run one test
+  xdrun cmd:test --update-golden              # Record every golden file
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runGoldenTests(cmd.OutOrStdout(), configFile, args, updateGolden)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().BoolVar(&updateGolden, "update-golden", false, "Write the current output of the tests to their golden files")
+
+	return cmd
+}
+
+// runGoldenTests runs the named tests of the task file, or all of them, and
+// fails when one does not match its golden file
+func runGoldenTests(out io.Writer, configFile string, names []string, update bool) error {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:test intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return withExitCode(ExitParseError, fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err))
+	}
+
+	tests, err := selectTests(program.Tests, names)
+	if err != nil {
+		return withExitCode(ExitValidationError, err)
+	}
+
+	failed := 0
+	for _, test := range tests {
+		// Each test runs on an engine of its own, without the user's
+		// output settings, so golden files are the same on every machine
+		result, err := engine.RunTest(program, test, actualConfigFile, update,
+			engine.WithSecretsManager(secrets.NewLazyManager()),
+			engine.WithDrunVersion(drunVersion),
+		)
+		if err != nil {
+			return err
+		}
+		switch result.Status {
+		case engine.TestUpdated:
+			_, _ = fmt.Fprintf(out, "📝  %s: wrote %s\n", result.Name, result.Golden)
+		case engine.TestPassed:
+			_, _ = fmt.Fprintf(out, "✅  %s\n", result.Name)
+		default:
+			failed++
+			_, _ = fmt.Fprintf(out, "❌  %s: the output does not match %s\n", result.Name, result.Golden)
+			for _, line := range result.Diff {
+				_, _ = fmt.Fprintf(out, "    %s\n", line)
+			}
+		}
+	}
+
+	if update {
+		_, _ = fmt.Fprintf(out, "\nUpdated %d golden files\n", len(tests))
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "\n%d passed, %d failed\n", len(tests)-failed, failed)
+	if failed > 0 {
+		return withExitCode(ExitTaskFailure, fmt.Errorf("%d of %d tests failed", failed, len(tests)))
+	}
+	return nil
+}
+
+// selectTests returns the named tests in the order given, or every test
+// when no names are given
+func selectTests(tests []*ast.TestStatement, names []string) ([]*ast.TestStatement, error) {
+	if len(tests) == 0 {
+		return nil, fmt.Errorf("the task file declares no tests")
+	}
+	if len(names) == 0 {
+		return tests, nil
+	}
+	selected := make([]*ast.TestStatement, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, test := range tests {
+			if test.Name == name {
+				selected = append(selected, test)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("test %q not found", name)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const goldenSpec = `version: 2.0
+
+task "greet":
+  requires $name
+  info "Hello, {$name}"
+
+test "greet drun":
+  call task "greet" with name="drun"
+  expect output to match golden "tests/drun.golden"
+
+test "greet world":
+  call task "greet" with name="world"
+  expect output to match golden "tests/world.golden"
+`
+
+func TestRunGoldenTests(t *testing.T) {
+	withCompletionSpec(t, goldenSpec)
+
+	var out bytes.Buffer
+	if err := runGoldenTests(&out, "", nil, true); err != nil {
+		t.Fatalf("runGoldenTests(update) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "greet drun: wrote tests/drun.golden") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runGoldenTests(&out, "", nil, false); err != nil {
+		t.Fatalf("runGoldenTests() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "2 passed, 0 failed") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	if err := os.WriteFile(filepath.Join("tests", "world.golden"), []byte("ℹ️  Hello, everyone\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	err := runGoldenTests(&out, "", []string{"greet world"}, false)
+	if err == nil || ExitCode(err) != ExitTaskFailure {
+		t.Fatalf("expected a task failure, got %v", err)
+	}
+	if !strings.Contains(out.String(), "+ℹ️  Hello, world") || !strings.Contains(out.String(), "0 passed, 1 failed") {
+		t.Errorf("expected the diff of the failed test, got:\n%s", out.String())
+	}
+
+	if err := runGoldenTests(&out, "", []string{"greet nobody"}, false); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected an unknown test error, got %v", err)
+	}
+}
@@ -4,17 +4,33 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/phillarmonic/drun/v2/internal/ui"
 	"gopkg.in/yaml.v3"
 )
 
-// UserConfig represents user-level drun settings stored in the home directory.
+// UserConfig represents drun settings from ~/.drun/config.yml, overridden by
+// the repository's .drun/config.local.yml.
 type UserConfig struct {
-	ExtraTaskFileSearchPaths []string `yaml:"extraTaskFileSearchPaths"`
-	ProvisioningSources      []string `yaml:"provisioningSources"`
+	ExtraTaskFileSearchPaths []string `yaml:"extraTaskFileSearchPaths,omitempty"`
+	ProvisioningSources      []string `yaml:"provisioningSources,omitempty"`
+
+	OutputStyle     string `yaml:"outputStyle,omitempty"`
+	StatusSymbols   string `yaml:"statusSymbols,omitempty"`
+	StatusColors    string `yaml:"statusColors,omitempty"`
+	Verbose         *bool  `yaml:"verbose,omitempty"`
+	IncludeCacheTTL string `yaml:"includeCacheTTL,omitempty"`
+	Parallelism     int    `yaml:"parallelism,omitempty"`
+	NetworkRetries  *int   `yaml:"networkRetries,omitempty"`
 }
 
+// localConfigPath is the per-repository configuration, relative to the
+// working directory; it is meant to stay out of version control
+const localConfigPath = ".drun/config.local.yml"
+
 func getUserConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -23,32 +39,208 @@ func getUserConfigPath() (string, error) {
 	return filepath.Join(homeDir, ".drun", "config.yml"), nil
 }
 
+// loadUserConfig loads the user configuration and merges the repository's
+// local configuration over it: scalar settings from the local file win and
+// lists are combined, user entries first
 func loadUserConfig() (*UserConfig, error) {
 	configPath, err := getUserConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	config, err := loadConfigFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user config: %w", err)
+	}
+	local, err := loadConfigFile(localConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local config: %w", err)
+	}
+	config.merge(local)
+
+	config.ExtraTaskFileSearchPaths = normalizeStringList(config.ExtraTaskFileSearchPaths)
+	config.ProvisioningSources = normalizeStringList(config.ProvisioningSources)
+	return config, nil
+}
+
+// loadConfigFile reads one configuration file; a missing file is empty
+func loadConfigFile(path string) (*UserConfig, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return &UserConfig{}, nil
 	}
 
-	// #nosec G304 -- user config is intentionally loaded from the user's home config path.
-	data, err := os.ReadFile(configPath)
+	// #nosec G304 -- config files are intentionally loaded from the home and repository config paths.
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read user config: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
 	var config UserConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse user config: %w", err)
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, key := range configKeys {
+		if value, ok := key.get(&config); ok {
+			if err := key.validate(value); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, key.Name, err)
+			}
+		}
 	}
-
-	config.ExtraTaskFileSearchPaths = normalizeStringList(config.ExtraTaskFileSearchPaths)
-	config.ProvisioningSources = normalizeStringList(config.ProvisioningSources)
 	return &config, nil
 }
 
+// merge applies the settings of override over c
+func (c *UserConfig) merge(override *UserConfig) {
+	c.ExtraTaskFileSearchPaths = append(c.ExtraTaskFileSearchPaths, override.ExtraTaskFileSearchPaths...)
+	c.ProvisioningSources = append(c.ProvisioningSources, override.ProvisioningSources...)
+	if override.OutputStyle != "" {
+		c.OutputStyle = override.OutputStyle
+	}
+	if override.StatusSymbols != "" {
+		c.StatusSymbols = override.StatusSymbols
+	}
+	if override.StatusColors != "" {
+		c.StatusColors = override.StatusColors
+	}
+	if override.Verbose != nil {
+		c.Verbose = override.Verbose
+	}
+	if override.IncludeCacheTTL != "" {
+		c.IncludeCacheTTL = override.IncludeCacheTTL
+	}
+	if override.Parallelism != 0 {
+		c.Parallelism = override.Parallelism
+	}
+	if override.NetworkRetries != nil {
+		c.NetworkRetries = override.NetworkRetries
+	}
+}
+
+// cacheTTL returns the configured remote include cache duration, or zero to
+// use the engine default
+func (c *UserConfig) cacheTTL() time.Duration {
+	ttl, _ := time.ParseDuration(c.IncludeCacheTTL) // validated on load
+	return ttl
+}
+
+// configKey describes a scalar setting managed by cmd:config
+type configKey struct {
+	Name        string
+	Description string
+	get         func(*UserConfig) (string, bool)
+	set         func(*UserConfig, string)
+	validate    func(string) error
+}
+
+var configKeys = []configKey{
+	{
+		Name:        "outputStyle",
+		Description: "Output style when the project sets none (emoji, plain, ascii or high-contrast)",
+		get:         func(c *UserConfig) (string, bool) { return c.OutputStyle, c.OutputStyle != "" },
+		set:         func(c *UserConfig, v string) { c.OutputStyle = v },
+		validate: func(v string) error {
+			_, err := ui.NewTheme(v)
+			return err
+		},
+	},
+	{
+		Name:        "statusSymbols",
+		Description: "Symbols per status category, e.g. \"ok=✔, warn=▲\" (info, ok, warn, error, fail)",
+		get:         func(c *UserConfig) (string, bool) { return c.StatusSymbols, c.StatusSymbols != "" },
+		set:         func(c *UserConfig, v string) { c.StatusSymbols = v },
+		validate: func(v string) error {
+			return ui.DefaultTheme().SetStatusSymbols(v)
+		},
+	},
+	{
+		Name:        "statusColors",
+		Description: "Colors per status category, e.g. \"ok=bold blue, error=magenta\"",
+		get:         func(c *UserConfig) (string, bool) { return c.StatusColors, c.StatusColors != "" },
+		set:         func(c *UserConfig, v string) { c.StatusColors = v },
+		validate: func(v string) error {
+			return ui.DefaultTheme().SetStatusColors(v)
+		},
+	},
+	{
+		Name:        "verbose",
+		Description: "Show detailed execution information unless --verbose is given (true or false)",
+		get: func(c *UserConfig) (string, bool) {
+			if c.Verbose == nil {
+				return "", false
+			}
+			return strconv.FormatBool(*c.Verbose), true
+		},
+		set: func(c *UserConfig, v string) {
+			verbose, _ := strconv.ParseBool(v)
+			c.Verbose = &verbose
+		},
+		validate: func(v string) error {
+			if _, err := strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("expected true or false, got %q", v)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "includeCacheTTL",
+		Description: "How long fetched remote includes stay cached, e.g. 5m or 1h (default 1m)",
+		get:         func(c *UserConfig) (string, bool) { return c.IncludeCacheTTL, c.IncludeCacheTTL != "" },
+		set:         func(c *UserConfig, v string) { c.IncludeCacheTTL = v },
+		validate: func(v string) error {
+			if ttl, err := time.ParseDuration(v); err != nil || ttl <= 0 {
+				return fmt.Errorf("expected a positive duration such as 5m, got %q", v)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "parallelism",
+		Description: "Worker count for parallel loops that do not set one (default 5)",
+		get: func(c *UserConfig) (string, bool) {
+			return strconv.Itoa(c.Parallelism), c.Parallelism != 0
+		},
+		set: func(c *UserConfig, v string) { c.Parallelism, _ = strconv.Atoi(v) },
+		validate: func(v string) error {
+			if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+				return fmt.Errorf("expected a positive whole number, got %q", v)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "networkRetries",
+		Description: "Retries of downloads, HTTP requests and remote fetches that fail transiently (default 2; 0 turns them off)",
+		get: func(c *UserConfig) (string, bool) {
+			if c.NetworkRetries == nil {
+				return "", false
+			}
+			return strconv.Itoa(*c.NetworkRetries), true
+		},
+		set: func(c *UserConfig, v string) {
+			retries, _ := strconv.Atoi(v)
+			c.NetworkRetries = &retries
+		},
+		validate: func(v string) error {
+			if n, err := strconv.Atoi(v); err != nil || n < 0 {
+				return fmt.Errorf("expected a whole number of retries, got %q", v)
+			}
+			return nil
+		},
+	},
+}
+
+// lookupConfigKey returns the setting named name
+func lookupConfigKey(name string) (configKey, error) {
+	names := make([]string, len(configKeys))
+	for i, key := range configKeys {
+		if key.Name == name {
+			return key, nil
+		}
+		names[i] = key.Name
+	}
+	return configKey{}, fmt.Errorf("unknown config key %q (supported: %s)", name, strings.Join(names, ", "))
+}
+
 func normalizeStringList(paths []string) []string {
 	if len(paths) == 0 {
 		return nil
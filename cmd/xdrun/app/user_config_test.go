@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -53,3 +54,57 @@ func TestNormalizeStringListFiltersEmptyAndDuplicateValues(t *testing.T) {
 		t.Fatalf("normalizeStringList() = %#v, want %#v", got, want)
 	}
 }
+
+func TestLoadUserConfigMergesLocalConfigOverUserConfig(t *testing.T) {
+	homeDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(homeDir, ".drun"), 0o750); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	user := "outputStyle: plain\nparallelism: 3\nprovisioningSources:\n  - user.yaml\n"
+	if err := os.WriteFile(filepath.Join(homeDir, ".drun", "config.yml"), []byte(user), 0o600); err != nil {
+		t.Fatalf("WriteFile(config.yml) error = %v", err)
+	}
+
+	withCompletionSpec(t, "version: 2.0\n")
+	local := "parallelism: 8\nverbose: false\nprovisioningSources:\n  - local.yaml\n"
+	if err := os.WriteFile(localConfigPath, []byte(local), 0o600); err != nil {
+		t.Fatalf("WriteFile(config.local.yml) error = %v", err)
+	}
+
+	withEnv(t, "HOME", homeDir, func() {
+		got, err := loadUserConfig()
+		if err != nil {
+			t.Fatalf("loadUserConfig() error = %v", err)
+		}
+		if got.OutputStyle != "plain" || got.Parallelism != 8 {
+			t.Fatalf("unexpected merged settings: %+v", got)
+		}
+		if got.Verbose == nil || *got.Verbose {
+			t.Fatalf("expected verbose explicitly disabled by local config, got %v", got.Verbose)
+		}
+		if want := []string{"user.yaml", "local.yaml"}; !reflect.DeepEqual(got.ProvisioningSources, want) {
+			t.Fatalf("ProvisioningSources = %#v, want %#v", got.ProvisioningSources, want)
+		}
+	})
+}
+
+func TestLoadConfigFileRejectsInvalidValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("includeCacheTTL: soon\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := loadConfigFile(path); err == nil || !strings.Contains(err.Error(), "includeCacheTTL") {
+		t.Fatalf("expected includeCacheTTL error, got %v", err)
+	}
+}
+
+func TestLocalConfigCanTurnNetworkRetriesOff(t *testing.T) {
+	user, local := 3, 0
+	config := &UserConfig{}
+	config.merge(&UserConfig{NetworkRetries: &user})
+	config.merge(&UserConfig{NetworkRetries: &local})
+	config.merge(&UserConfig{})
+	if config.NetworkRetries == nil || *config.NetworkRetries != 0 {
+		t.Fatalf("expected the local 0 to win, got %v", config.NetworkRetries)
+	}
+}
@@ -0,0 +1,257 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/remote"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Include Vendoring
+// This file contains the cmd:vendor command that copies remote includes into
+// vendor/drun/ so runs are reproducible and work offline.
+
+// vendorFetchTimeout bounds each remote include download
+const vendorFetchTimeout = 30 * time.Second
+
+// vendorFetchFunc downloads the content of a remote include URL
+type vendorFetchFunc func(url string) ([]byte, error)
+
+// createVendorCommand creates the cmd:vendor subcommand
+func (a *App) createVendorCommand() *cobra.Command {
+	var configFile string
+	var verify bool
+
+	cmd := &cobra.Command{
+		Use:   "cmd:vendor",
+		Short: "Vendor remote includes into vendor/drun/",
+		Long: `Download every remote include (github:, https://, drunhub:) declared in the
+project into vendor/drun/ and record them in vendor/drun/drun.lock.
+
+Once vendored, includes resolve from the local copy instead of the network, so
+runs are reproducible and work offline. Re-run cmd:vendor to refresh the copies
+after changing include URLs or refs.
+
+Examples:
+  xdrun cmd:vendor             # Download remote includes and write drun.lock
+  xdrun cmd:vendor --verify    # Check vendored copies against drun.lock
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			if verify {
+				return runVendorVerify(cmd.OutOrStdout(), configFile)
+			}
+			return runVendor(cmd.OutOrStdout(), configFile, fetchVendorInclude, listIncludeTags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+	cmd.Flags().BoolVar(&verify, "verify", false, "Verify vendored includes against drun.lock without downloading")
+
+	return cmd
+}
+
+// runVendor downloads the project's remote includes and rewrites drun.lock.
+// A drunhub version range is vendored at the release it resolves to.
+func runVendor(out io.Writer, configFile string, fetch vendorFetchFunc, tags includeTagsFunc) error {
+	specFile, urls, err := loadRemoteIncludes(configFile)
+	if err != nil {
+		return err
+	}
+
+	root := remote.VendorRoot(specFile)
+	previous, err := remote.LoadVendorLock(root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if len(urls) == 0 {
+		_, _ = fmt.Fprintln(out, "No remote includes to vendor")
+	}
+
+	lock := &remote.VendorLock{}
+	for _, url := range urls {
+		file, err := remote.VendorFileName(url)
+		if err != nil {
+			return err
+		}
+		resolved, version, err := resolveIncludeVersion(url, tags)
+		if err != nil {
+			return err
+		}
+		content, err := fetch(resolved)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", resolved, err)
+		}
+
+		entry := remote.VendorEntry{URL: url, File: file, SHA256: remote.Checksum(content), Version: version}
+		target := entry.Path(root)
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return fmt.Errorf("failed to create vendor directory: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		lock.Includes = append(lock.Includes, entry)
+		if version != "" {
+			_, _ = fmt.Fprintf(out, "✓ %s (%s) → %s/%s\n", url, version, remote.VendorDir, file)
+		} else {
+			_, _ = fmt.Fprintf(out, "✓ %s → %s/%s\n", url, remote.VendorDir, file)
+		}
+	}
+
+	// Remove copies of includes that are no longer declared
+	for _, old := range staleVendorEntries(previous, urls) {
+		if err := os.Remove(old.Path(root)); err == nil {
+			_, _ = fmt.Fprintf(out, "🗑  Removed %s/%s\n", remote.VendorDir, old.File)
+		}
+	}
+
+	if len(urls) == 0 && previous == nil {
+		return nil
+	}
+	if err := lock.Save(root); err != nil {
+		return fmt.Errorf("failed to write %s: %w", remote.VendorLockFile, err)
+	}
+	_, _ = fmt.Fprintf(out, "Wrote %s/%s (%d includes)\n", remote.VendorDir, remote.VendorLockFile, len(lock.Includes))
+	return nil
+}
+
+// runVendorVerify checks that every remote include is vendored and matches drun.lock
+func runVendorVerify(out io.Writer, configFile string) error {
+	specFile, urls, err := loadRemoteIncludes(configFile)
+	if err != nil {
+		return err
+	}
+
+	root := remote.VendorRoot(specFile)
+	lock, err := remote.LoadVendorLock(root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if len(urls) == 0 {
+				_, _ = fmt.Fprintln(out, "No remote includes to verify")
+				return nil
+			}
+			return fmt.Errorf("%s/%s not found; run 'xdrun cmd:vendor' first", remote.VendorDir, remote.VendorLockFile)
+		}
+		return err
+	}
+
+	var problems []error
+	for _, url := range urls {
+		entry, ok := lock.Lookup(url)
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s: not recorded in %s", url, remote.VendorLockFile))
+			continue
+		}
+		if err := entry.Verify(root); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	for _, stale := range staleVendorEntries(lock, urls) {
+		problems = append(problems, fmt.Errorf("%s: recorded in %s but no longer included", stale.URL, remote.VendorLockFile))
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			_, _ = fmt.Fprintf(out, "✗ %v\n", problem)
+		}
+		return fmt.Errorf("vendored includes do not match %s (%d problems)", remote.VendorLockFile, len(problems))
+	}
+
+	_, _ = fmt.Fprintf(out, "✓ %d vendored includes match %s\n", len(urls), remote.VendorLockFile)
+	return nil
+}
+
+// loadRemoteIncludes parses the task file and returns its path and the
+// remote include URLs it declares, in declaration order without duplicates
+func loadRemoteIncludes(configFile string) (string, []string, error) {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:vendor intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	if program.Project != nil {
+		for _, setting := range program.Project.Settings {
+			include, ok := setting.(*ast.IncludeStatement)
+			// Multi-file libraries are fetched file by file and not vendored
+			if !ok || !remote.IsRemoteURL(include.Path) || remote.IsLibraryURL(include.Path) || seen[include.Path] {
+				continue
+			}
+			seen[include.Path] = true
+			urls = append(urls, include.Path)
+		}
+	}
+
+	return actualConfigFile, urls, nil
+}
+
+// staleVendorEntries returns lock entries whose URL is not in urls
+func staleVendorEntries(lock *remote.VendorLock, urls []string) []remote.VendorEntry {
+	if lock == nil {
+		return nil
+	}
+	current := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		current[url] = true
+	}
+
+	var stale []remote.VendorEntry
+	for _, entry := range lock.Includes {
+		if !current[entry.URL] {
+			stale = append(stale, entry)
+		}
+	}
+	return stale
+}
+
+// fetchVendorInclude downloads a remote include, bypassing the include cache
+// so vendored copies always reflect the current remote content
+func fetchVendorInclude(url string) ([]byte, error) {
+	protocol, path, ref, err := remote.ParseRemoteURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	githubFetcher := remote.NewGitHubFetcher()
+	var fetcher remote.Fetcher
+	switch protocol {
+	case "github":
+		fetcher = githubFetcher
+	case "https":
+		fetcher = remote.NewHTTPSFetcher()
+	case "drunhub":
+		fetcher = remote.NewDrunhubFetcher(githubFetcher)
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", protocol)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vendorFetchTimeout)
+	defer cancel()
+	return fetcher.Fetch(ctx, path, ref)
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/remote"
+)
+
+const vendorTestSpec = `version: 2.0
+
+project "app":
+  include "github:acme/ops/docker.drun@v1"
+  include "https://example.com/drun/lint.drun"
+
+task "build":
+  info "building"
+`
+
+func fakeVendorFetch(contents map[string]string) vendorFetchFunc {
+	return func(url string) ([]byte, error) {
+		content, ok := contents[url]
+		if !ok {
+			return nil, fmt.Errorf("unexpected fetch of %s", url)
+		}
+		return []byte(content), nil
+	}
+}
+
+func TestRunVendorWritesFilesAndLock(t *testing.T) {
+	withCompletionSpec(t, vendorTestSpec)
+
+	var out bytes.Buffer
+	err := runVendor(&out, "", fakeVendorFetch(map[string]string{
+		"github:acme/ops/docker.drun@v1":     "version: 2.0\nproject \"docker\":\n",
+		"https://example.com/drun/lint.drun": "version: 2.0\nproject \"lint\":\n",
+	}), nil)
+	if err != nil {
+		t.Fatalf("runVendor() error = %v\n%s", err, out.String())
+	}
+
+	lock, err := remote.LoadVendorLock(".")
+	if err != nil {
+		t.Fatalf("LoadVendorLock() error = %v", err)
+	}
+	if len(lock.Includes) != 2 {
+		t.Fatalf("lock includes = %d, want 2", len(lock.Includes))
+	}
+	entry, ok := lock.Lookup("github:acme/ops/docker.drun@v1")
+	if !ok || entry.File != "github/acme/ops/docker@v1.drun" {
+		t.Fatalf("unexpected github entry %+v", entry)
+	}
+	if _, err := os.Stat(filepath.Join("vendor", "drun", "https", "example.com", "drun", "lint.drun")); err != nil {
+		t.Errorf("expected vendored https include: %v", err)
+	}
+
+	out.Reset()
+	if err := runVendorVerify(&out, ""); err != nil {
+		t.Fatalf("runVendorVerify() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "2 vendored includes match drun.lock") {
+		t.Errorf("unexpected verify output:\n%s", out.String())
+	}
+}
+
+func TestRunVendorVerifyDetectsDrift(t *testing.T) {
+	withCompletionSpec(t, vendorTestSpec)
+
+	var out bytes.Buffer
+	if err := runVendor(&out, "", fakeVendorFetch(map[string]string{
+		"github:acme/ops/docker.drun@v1":     "original",
+		"https://example.com/drun/lint.drun": "original",
+	}), nil); err != nil {
+		t.Fatalf("runVendor() error = %v", err)
+	}
+
+	tampered := filepath.Join("vendor", "drun", "github", "acme", "ops", "docker@v1.drun")
+	if err := os.WriteFile(tampered, []byte("patched"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(".drun", "spec.drun"), []byte(strings.Replace(vendorTestSpec, "@v1", "@v2", 1)), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	out.Reset()
+	err := runVendorVerify(&out, "")
+	if err == nil || !strings.Contains(err.Error(), "2 problems") {
+		t.Fatalf("runVendorVerify() error = %v, want 2 problems\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "github:acme/ops/docker.drun@v2: not recorded") ||
+		!strings.Contains(out.String(), "github:acme/ops/docker.drun@v1: recorded in drun.lock but no longer included") {
+		t.Errorf("unexpected verify output:\n%s", out.String())
+	}
+}
+
+func TestRunVendorVerifyRequiresLock(t *testing.T) {
+	withCompletionSpec(t, vendorTestSpec)
+
+	var out bytes.Buffer
+	err := runVendorVerify(&out, "")
+	if err == nil || !strings.Contains(err.Error(), "run 'xdrun cmd:vendor' first") {
+		t.Fatalf("runVendorVerify() error = %v, want missing lock error", err)
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/phillarmonic/drun/v2/internal/engine"
+	"github.com/phillarmonic/drun/v2/internal/platform"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// Domain: Tool Resolution
+// This file contains the cmd:which command used to debug project PATH entries.
+
+// createWhichCommand creates the cmd:which subcommand
+func (a *App) createWhichCommand() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "cmd:which <tool>",
+		Short: "Show how a tool resolves against the project PATH",
+		Long: `Show how a tool name resolves for shell statements in this project.
+
+Directories declared with 'set path to include ...' in the project block are
+searched first, in declaration order, followed by the PATH from the platform
+shell config (if any) and finally the inherited PATH.
+
+Examples:
+  xdrun cmd:which eslint         # Resolve eslint using the project PATH
+  xdrun cmd:which -f ci.drun go  # Resolve go using a specific task file
+
+Note: The 'cmd:' prefix is reserved for built-in commands to avoid conflicts with user tasks.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				configFile = a.configFile
+			}
+			return runWhich(cmd.OutOrStdout(), configFile, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "file", "f", "", "Task file (default: .drun/spec.drun or workspace configured file)")
+
+	return cmd
+}
+
+// runWhich resolves tool against the project PATH entries and the inherited PATH
+func runWhich(out io.Writer, configFile, tool string) error {
+	actualConfigFile, err := FindConfigFile(configFile)
+	if err != nil {
+		return fmt.Errorf("no drun task file found: %w", err)
+	}
+
+	// #nosec G304 -- cmd:which intentionally reads the discovered drun task file.
+	content, err := os.ReadFile(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read drun file '%s': %w", actualConfigFile, err)
+	}
+
+	program, err := engine.ParseStringWithFilename(string(content), actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse drun file '%s': %w", actualConfigFile, err)
+	}
+
+	eng := engine.NewEngine(io.Discard)
+	projectCtx, err := eng.BuildProjectContext(program.Project, actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load project context: %w", err)
+	}
+
+	basePath := os.Getenv("PATH")
+	baseSource := "inherited PATH"
+	var projectEntries []string
+	if projectCtx != nil {
+		projectEntries = projectCtx.PathEntries
+		if config, ok := projectCtx.ShellConfigs[platform.Current()]; ok {
+			if configPath, ok := config.Environment["PATH"]; ok {
+				basePath = configPath
+				baseSource = "shell config PATH"
+			}
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "Resolving '%s' for %s\n\n", tool, actualConfigFile)
+	_, _ = fmt.Fprintln(out, "Search order:")
+	if len(projectEntries) == 0 {
+		_, _ = fmt.Fprintln(out, "  (no project path entries)")
+	}
+	for i, dir := range projectEntries {
+		marker := ""
+		if path, found := shell.LookPathIn(tool, []string{dir}); found {
+			marker = "  ✓ " + filepath.Base(path)
+		} else if _, statErr := os.Stat(dir); statErr != nil {
+			marker = "  (missing)"
+		}
+		_, _ = fmt.Fprintf(out, "  %d. %s%s\n", i+1, dir, marker)
+	}
+	_, _ = fmt.Fprintf(out, "  %d. %s\n\n", len(projectEntries)+1, baseSource)
+
+	if path, found := shell.LookPathIn(tool, projectEntries); found {
+		_, _ = fmt.Fprintf(out, "→ %s (project path)\n", path)
+		return nil
+	}
+	if path, found := shell.LookPathIn(tool, filepath.SplitList(basePath)); found {
+		_, _ = fmt.Fprintf(out, "→ %s (%s)\n", path, baseSource)
+		return nil
+	}
+
+	return fmt.Errorf("'%s' not found in project path entries or %s", tool, baseSource)
+}
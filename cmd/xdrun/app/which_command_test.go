@@ -0,0 +1,64 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunWhichPrefersProjectPathEntries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on POSIX executable bits")
+	}
+
+	withCompletionSpec(t, `version: 2.0
+
+project "app":
+  set path to include ".drun/tools" and "node_modules/.bin"
+
+task "lint":
+  run "eslint ."
+`)
+
+	binDir := filepath.Join("node_modules", ".bin")
+	if err := os.MkdirAll(binDir, 0750); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	// #nosec G306 -- test fixture must be executable.
+	if err := os.WriteFile(filepath.Join(binDir, "eslint"), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runWhich(&out, "", "eslint"); err != nil {
+		t.Fatalf("runWhich() error = %v\n%s", err, out.String())
+	}
+
+	output := out.String()
+	if !strings.Contains(output, filepath.Join(".drun", "tools")+"  (missing)") {
+		t.Errorf("expected missing .drun/tools entry, got:\n%s", output)
+	}
+	if !strings.Contains(output, filepath.Join("node_modules", ".bin", "eslint")+" (project path)") {
+		t.Errorf("expected eslint to resolve from node_modules/.bin, got:\n%s", output)
+	}
+}
+
+func TestRunWhichReportsUnresolvedTool(t *testing.T) {
+	withCompletionSpec(t, `version: 2.0
+
+task "lint":
+  run "eslint ."
+`)
+
+	var out bytes.Buffer
+	err := runWhich(&out, "", "drun-no-such-tool")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("runWhich() error = %v, want not found", err)
+	}
+	if !strings.Contains(out.String(), "(no project path entries)") {
+		t.Errorf("expected empty project path notice, got:\n%s", out.String())
+	}
+}
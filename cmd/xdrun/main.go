@@ -20,6 +20,6 @@ func main() {
 
 	if err := cliApp.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(app.ExitCode(err))
 	}
 }
@@ -3,6 +3,7 @@ module github.com/phillarmonic/drun/v2
 go 1.26.5
 
 require (
+	filippo.io/age v1.2.1
 	github.com/danieljoos/wincred v1.2.3
 	github.com/keybase/go-keychain v0.0.1
 	github.com/mholt/archives v0.1.5
@@ -12,6 +13,7 @@ require (
 	github.com/spf13/cobra v1.8.1
 	github.com/zalando/go-keyring v0.2.6
 	golang.org/x/crypto v0.45.0
+	golang.org/x/sys v0.38.0
 	golang.org/x/term v0.37.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -38,6 +40,5 @@ require (
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/ulikunitz/xz v0.5.15 // indirect
 	go4.org v0.0.0-20230225012048-214862532bf5 // indirect
-	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 )
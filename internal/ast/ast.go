@@ -30,6 +30,7 @@ type Program struct {
 	Templates      []*TaskTemplateStatement
 	Services       []*ServiceStatement
 	Orchestrations []*OrchestrateStatement
+	Tests          []*TestStatement
 }
 
 func (p *Program) String() string {
@@ -58,6 +59,10 @@ func (p *Program) String() string {
 		out.WriteString(task.String())
 		out.WriteString("\n")
 	}
+	for _, test := range p.Tests {
+		out.WriteString(test.String())
+		out.WriteString("\n")
+	}
 	return out.String()
 }
 
@@ -11,6 +11,8 @@ type ActionStatement struct {
 	Token           lexer.Token
 	Action          string
 	Message         string
+	StepStyle       string // step only: box, banner, underline or minimal (empty = project default)
+	StepWidth       int    // step only: minimum header width in columns (0 = fit to text)
 	LineBreakBefore bool
 	LineBreakAfter  bool
 }
@@ -18,6 +20,15 @@ type ActionStatement struct {
 func (as *ActionStatement) statementNode() {}
 func (as *ActionStatement) String() string {
 	suffix := ""
+	if as.StepStyle != "" || as.StepWidth > 0 {
+		suffix += " with"
+		if as.StepStyle != "" {
+			suffix += fmt.Sprintf(" style \"%s\"", as.StepStyle)
+		}
+		if as.StepWidth > 0 {
+			suffix += fmt.Sprintf(" width %d", as.StepWidth)
+		}
+	}
 	if as.LineBreakBefore {
 		suffix += " add line break before"
 	}
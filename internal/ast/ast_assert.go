@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// AssertStatement fails the task when its condition does not hold.
+// Syntax: assert <condition> [otherwise "message"]
+// The condition uses the same forms as if and when, for example
+// assert {replicas} > 0 otherwise "replicas must be positive"
+// assert file "Dockerfile" exists
+type AssertStatement struct {
+	Token     lexer.Token
+	Condition string
+	Message   string // optional; the failure names the condition when empty
+}
+
+func (as *AssertStatement) statementNode() {}
+func (as *AssertStatement) String() string {
+	if as.Message == "" {
+		return "assert " + as.Condition
+	}
+	return fmt.Sprintf("assert %s otherwise %q", as.Condition, as.Message)
+}
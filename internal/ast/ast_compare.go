@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// CompareStatement compares two files or two directory trees, prints the
+// differences as a unified diff and sets {comparison.identical}.
+// Syntax: compare file|dir "left" with "right" [ignoring "pattern", ...]
+// For example
+// compare file "expected.json" with "actual.json"
+// compare dir "golden" with "out" ignoring "*.log"
+type CompareStatement struct {
+	Token  lexer.Token
+	Kind   string   // "file" or "dir"
+	Left   string   // the expected side
+	Right  string   // the actual side
+	Ignore []string // glob patterns of directory entries to skip
+}
+
+func (cs *CompareStatement) statementNode() {}
+func (cs *CompareStatement) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "compare %s %q with %q", cs.Kind, cs.Left, cs.Right)
+	for i, pattern := range cs.Ignore {
+		if i == 0 {
+			out.WriteString(" ignoring ")
+		} else {
+			out.WriteString(", ")
+		}
+		fmt.Fprintf(&out, "%q", pattern)
+	}
+	return out.String()
+}
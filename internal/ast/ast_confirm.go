@@ -0,0 +1,35 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// ConfirmStatement asks the user a yes/no question before a destructive
+// step; answering no stops the task. A timeout takes the default, so
+// unattended runs never wait forever.
+// Syntax: confirm "question" [within <duration>] [defaulting to yes|no]
+// For example
+// confirm "Delete prod database?"
+// confirm "Delete prod database?" within 30s defaulting to no
+type ConfirmStatement struct {
+	Token    lexer.Token
+	Question string
+	Timeout  string // Go duration syntax, e.g. 30s; empty waits for an answer
+	Default  string // "yes", "no" or empty when there is none
+}
+
+func (cs *ConfirmStatement) statementNode() {}
+func (cs *ConfirmStatement) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "confirm %q", cs.Question)
+	if cs.Timeout != "" {
+		out.WriteString(" within " + cs.Timeout)
+	}
+	if cs.Default != "" {
+		out.WriteString(" defaulting to " + cs.Default)
+	}
+	return out.String()
+}
@@ -44,18 +44,24 @@ func (cs *ConditionalStatement) String() string {
 
 // LoopStatement represents for each loops
 type LoopStatement struct {
-	Token      lexer.Token
-	Type       string
-	Variable   string
-	Iterable   string
-	RangeStart string
-	RangeEnd   string
-	RangeStep  string
-	Filter     *FilterExpression
-	Parallel   bool
-	MaxWorkers int
-	FailFast   bool
-	Body       []Statement
+	Token         lexer.Token
+	Type          string
+	Variable      string
+	Iterable      string
+	RangeStart    string
+	RangeEnd      string
+	RangeStep     string
+	Filter        *FilterExpression
+	Parallel      bool
+	MaxWorkers    int
+	FailFast      bool
+	Pagination    *PaginationClause // set for "http" loops over a paginated API
+	Condition     string            // set for "while" loops, and the until condition of "poll" loops
+	MaxIterations int               // iteration limit of a "while" loop; 0 uses the default
+	PollInterval  string            // time between the attempts of a "poll" loop
+	PollTimeout   string            // time after which a "poll" loop gives up
+	ChunkSize     int               // files per batch of a "chunk" loop
+	Body          []Statement
 }
 
 func (ls *LoopStatement) statementNode() {}
@@ -84,6 +90,32 @@ func (ls *LoopStatement) String() string {
 		out.WriteString(ls.Variable)
 		out.WriteString(" in pattern ")
 		out.WriteString(ls.Iterable)
+	case "while":
+		out.WriteString("while ")
+		out.WriteString(ls.Condition)
+		if ls.MaxIterations > 0 {
+			out.WriteString(fmt.Sprintf(" at most %d times", ls.MaxIterations))
+		}
+	case "poll":
+		out.WriteString("poll every ")
+		out.WriteString(ls.PollInterval)
+		out.WriteString(" up to ")
+		out.WriteString(ls.PollTimeout)
+	case "chunk":
+		out.WriteString(fmt.Sprintf("for each chunk %s of %d files in %q", ls.Variable, ls.ChunkSize, ls.Iterable))
+	case "http":
+		out.WriteString("for each ")
+		out.WriteString(ls.Variable)
+		out.WriteString(" in get \"")
+		out.WriteString(ls.Iterable)
+		out.WriteString("\"")
+		if ls.Pagination != nil {
+			out.WriteString(ls.Pagination.String())
+		}
+	case "stdin":
+		out.WriteString("for each ")
+		out.WriteString(ls.Variable)
+		out.WriteString(" in lines of stdin")
 	default: // "each"
 		out.WriteString("for each ")
 		out.WriteString(ls.Variable)
@@ -111,6 +143,11 @@ func (ls *LoopStatement) String() string {
 		out.WriteString(stmt.String())
 		out.WriteString("\n")
 	}
+	if ls.Type == "poll" {
+		out.WriteString("until ")
+		out.WriteString(ls.Condition)
+		out.WriteString("\n")
+	}
 
 	return out.String()
 }
@@ -230,6 +267,27 @@ func (cs *ContinueStatement) String() string {
 	return "continue"
 }
 
+// PaginationClause describes how an "http" loop finds the next page:
+// by a response header ("Link"), a cursor field in the body, or a numeric
+// query parameter
+type PaginationClause struct {
+	Strategy  string // "header", "cursor" or "query"
+	Name      string // header name, cursor field path or query parameter
+	Param     string // query parameter the cursor is sent in
+	ItemsPath string // field holding the items; detected when empty
+}
+
+func (pc *PaginationClause) String() string {
+	out := fmt.Sprintf(" paginated by %s %q", pc.Strategy, pc.Name)
+	if pc.Param != "" {
+		out += fmt.Sprintf(" param %q", pc.Param)
+	}
+	if pc.ItemsPath != "" {
+		out += fmt.Sprintf(" items at %q", pc.ItemsPath)
+	}
+	return out
+}
+
 // FilterExpression represents filter conditions in loops
 type FilterExpression struct {
 	Variable string
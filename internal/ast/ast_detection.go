@@ -34,6 +34,8 @@ func (ds *DetectionStatement) String() string {
 		if ds.Value != "" {
 			out.WriteString(" " + ds.Value)
 		}
+	case "detect_environment":
+		out.WriteString("detect environment \"" + ds.Target + "\" as $" + ds.CaptureVar)
 	case "detect_available":
 		out.WriteString("detect available " + ds.Target)
 		for _, alt := range ds.Alternatives {
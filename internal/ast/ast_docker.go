@@ -13,6 +13,7 @@ type DockerStatement struct {
 	Resource             string
 	Name                 string
 	Options              map[string]string
+	OptionOrder          []string // Options keys in declaration order
 	ServiceScoped        bool
 	ServiceName          string
 	ServiceNameIsLiteral bool
@@ -32,9 +33,14 @@ func (ds *DockerStatement) String() string {
 		out += fmt.Sprintf(" \"%s\"", ds.Name)
 	}
 
-	for key, value := range ds.Options {
-		out += fmt.Sprintf(" %s \"%s\"", key, value)
+	for _, key := range OrderedKeys(ds.Options, ds.OptionOrder) {
+		out += fmt.Sprintf(" %s \"%s\"", key, ds.Options[key])
 	}
 
 	return out
 }
+
+// SetOption sets an option, keeping its declaration order
+func (ds *DockerStatement) SetOption(key, value string) {
+	ds.Options = setOrdered(ds.Options, &ds.OptionOrder, key, value)
+}
@@ -43,6 +43,9 @@ func (fs *FileStatement) String() string {
 	case "write":
 		return fmt.Sprintf("write \"%s\" to file \"%s\"", fs.Content, fs.Target)
 	case "append":
+		if fs.Source != "" {
+			return fmt.Sprintf("append file \"%s\" to file \"%s\"", fs.Source, fs.Target)
+		}
 		return fmt.Sprintf("append \"%s\" to file \"%s\"", fs.Content, fs.Target)
 	case "replace":
 		return fmt.Sprintf("replace values in \"%s\"", fs.Target)
@@ -8,11 +8,12 @@ import (
 
 // GitStatement represents Git operations
 type GitStatement struct {
-	Token     lexer.Token
-	Operation string
-	Resource  string
-	Name      string
-	Options   map[string]string
+	Token       lexer.Token
+	Operation   string
+	Resource    string
+	Name        string
+	Options     map[string]string
+	OptionOrder []string // Options keys in declaration order
 }
 
 func (gs *GitStatement) statementNode() {}
@@ -27,9 +28,14 @@ func (gs *GitStatement) String() string {
 		out += fmt.Sprintf(" \"%s\"", gs.Name)
 	}
 
-	for key, value := range gs.Options {
-		out += fmt.Sprintf(" %s \"%s\"", key, value)
+	for _, key := range OrderedKeys(gs.Options, gs.OptionOrder) {
+		out += fmt.Sprintf(" %s \"%s\"", key, gs.Options[key])
 	}
 
 	return out
 }
+
+// SetOption sets an option, keeping its declaration order
+func (gs *GitStatement) SetOption(key, value string) {
+	gs.Options = setOrdered(gs.Options, &gs.OptionOrder, key, value)
+}
@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// TestStatement represents a top-level test block that runs a task and
+// compares its normalized output with a golden file
+//
+//	test "deploy to staging" in dry run:
+//	  call task "deploy" with environment="staging"
+//	  expect output to match golden "tests/deploy.golden"
+type TestStatement struct {
+	Token  lexer.Token
+	Name   string
+	DryRun bool
+	Call   *TaskCallStatement
+	Golden string // the golden file, relative to the project root
+}
+
+func (ts *TestStatement) statementNode() {}
+func (ts *TestStatement) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "test %q", ts.Name)
+	if ts.DryRun {
+		out.WriteString(" in dry run")
+	}
+	out.WriteString(":\n")
+	if ts.Call != nil {
+		fmt.Fprintf(&out, "  %s\n", ts.Call.String())
+	}
+	fmt.Fprintf(&out, "  expect output to match golden %q\n", ts.Golden)
+	return out.String()
+}
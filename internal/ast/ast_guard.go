@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// GuardedStatement runs a single-line statement only when a trailing
+// condition allows it.
+// Syntax: <statement> only when <condition> | <statement> unless <condition>
+// For example
+// run "npm run lint" only when file "package.json" exists
+// info "skipping" unless {verbose}
+type GuardedStatement struct {
+	Token     lexer.Token // the 'only' or 'unless' token
+	Statement Statement
+	Condition string
+	Negate    bool // true for unless
+}
+
+func (gs *GuardedStatement) statementNode() {}
+func (gs *GuardedStatement) String() string {
+	if gs.Negate {
+		return gs.Statement.String() + " unless " + gs.Condition
+	}
+	return gs.Statement.String() + " only when " + gs.Condition
+}
@@ -16,6 +16,61 @@ type HTTPStatement struct {
 	Headers map[string]string
 	Auth    map[string]string
 	Options map[string]string
+	Client  string // project HTTP client the request uses (using client "api"); empty when none
+
+	// Keys of Headers, Auth and Options in declaration order
+	HeaderOrder []string
+	AuthOrder   []string
+	OptionOrder []string
+
+	// Parts of the response stored in variables; a statement with captures
+	// sends its request instead of only showing it
+	Captures []HTTPCapture
+
+	// Assertions on the response; a failed one fails the statement
+	Expectations []HTTPExpectation
+}
+
+// HTTPCapture stores part of an HTTP response in a variable:
+// capture status as $code, capture body as $page, capture header "ETag" as $etag
+type HTTPCapture struct {
+	Part     string // "status", "body" or "header"
+	Header   string // header name when Part is "header"
+	Variable string
+}
+
+func (hc HTTPCapture) String() string {
+	if hc.Part == "header" {
+		return fmt.Sprintf("capture header %q as %s", hc.Header, hc.Variable)
+	}
+	return fmt.Sprintf("capture %s as %s", hc.Part, hc.Variable)
+}
+
+// HTTPExpectation asserts part of an HTTP response:
+// expect response status in [200, 201] and header "Content-Type" contains "json"
+// and body matches ".ok == true"
+type HTTPExpectation struct {
+	Part     string   // "status", "header" or "body"
+	Header   string   // header name when Part is "header"
+	Operator string   // "is", "in", "contains", "matches" or "exists"
+	Values   []string // expected values; several only for "in"
+}
+
+func (he HTTPExpectation) String() string {
+	out := he.Part
+	if he.Part == "header" {
+		out += fmt.Sprintf(" %q", he.Header)
+	}
+	out += " " + he.Operator
+	switch {
+	case he.Operator == "in":
+		out += " [" + strings.Join(he.Values, ", ") + "]"
+	case he.Part == "status" && len(he.Values) == 1:
+		out += " " + he.Values[0]
+	case len(he.Values) == 1:
+		out += fmt.Sprintf(" %q", he.Values[0])
+	}
+	return out
 }
 
 func (hs *HTTPStatement) statementNode() {}
@@ -26,25 +81,57 @@ func (hs *HTTPStatement) String() string {
 		out += fmt.Sprintf(" to \"%s\"", hs.URL)
 	}
 
-	for key, value := range hs.Headers {
-		out += fmt.Sprintf(" with header \"%s: %s\"", key, value)
+	if hs.Client != "" {
+		out += fmt.Sprintf(" using client %q", hs.Client)
+	}
+
+	for _, key := range OrderedKeys(hs.Headers, hs.HeaderOrder) {
+		out += fmt.Sprintf(" with header \"%s: %s\"", key, hs.Headers[key])
 	}
 
 	if hs.Body != "" {
 		out += fmt.Sprintf(" with body \"%s\"", hs.Body)
 	}
 
-	for key, value := range hs.Auth {
-		out += fmt.Sprintf(" with %s \"%s\"", key, value)
+	for _, key := range OrderedKeys(hs.Auth, hs.AuthOrder) {
+		out += fmt.Sprintf(" with %s \"%s\"", key, hs.Auth[key])
 	}
 
-	for key, value := range hs.Options {
-		out += fmt.Sprintf(" %s \"%s\"", key, value)
+	for _, key := range OrderedKeys(hs.Options, hs.OptionOrder) {
+		out += fmt.Sprintf(" %s \"%s\"", key, hs.Options[key])
+	}
+
+	for _, capture := range hs.Captures {
+		out += " " + capture.String()
+	}
+
+	for i, expectation := range hs.Expectations {
+		if i == 0 {
+			out += " expect response "
+		} else {
+			out += " and "
+		}
+		out += expectation.String()
 	}
 
 	return out
 }
 
+// SetHeader sets a header, keeping its declaration order
+func (hs *HTTPStatement) SetHeader(key, value string) {
+	hs.Headers = setOrdered(hs.Headers, &hs.HeaderOrder, key, value)
+}
+
+// SetAuth sets an authentication method, keeping its declaration order
+func (hs *HTTPStatement) SetAuth(key, value string) {
+	hs.Auth = setOrdered(hs.Auth, &hs.AuthOrder, key, value)
+}
+
+// SetOption sets an option, keeping its declaration order
+func (hs *HTTPStatement) SetOption(key, value string) {
+	hs.Options = setOrdered(hs.Options, &hs.OptionOrder, key, value)
+}
+
 // DownloadStatement represents file download operations (like curl/wget)
 type DownloadStatement struct {
 	Token            lexer.Token
@@ -53,10 +140,17 @@ type DownloadStatement struct {
 	AllowOverwrite   bool
 	AllowPermissions []PermissionSpec
 	ExtractTo        string
+	StripComponents  int      // leading directories dropped from extracted paths
+	Only             []string // extract only the paths matching these patterns
 	RemoveArchive    bool
 	Headers          map[string]string
 	Auth             map[string]string
 	Options          map[string]string
+
+	// Keys of Headers, Auth and Options in declaration order
+	HeaderOrder []string
+	AuthOrder   []string
+	OptionOrder []string
 }
 
 func (ds *DownloadStatement) statementNode() {}
@@ -65,6 +159,19 @@ func (ds *DownloadStatement) String() string {
 
 	if ds.ExtractTo != "" {
 		out += " extract to \"" + ds.ExtractTo + "\""
+		if ds.StripComponents == 1 {
+			out += " strip 1 component"
+		} else if ds.StripComponents > 1 {
+			out += fmt.Sprintf(" strip %d components", ds.StripComponents)
+		}
+		for i, pattern := range ds.Only {
+			if i == 0 {
+				out += " only"
+			} else {
+				out += ","
+			}
+			out += " \"" + pattern + "\""
+		}
 		if ds.RemoveArchive {
 			out += " remove archive"
 		}
@@ -94,21 +201,36 @@ func (ds *DownloadStatement) String() string {
 		out += "]"
 	}
 
-	for key, value := range ds.Headers {
-		out += fmt.Sprintf(" with header \"%s: %s\"", key, value)
+	for _, key := range OrderedKeys(ds.Headers, ds.HeaderOrder) {
+		out += fmt.Sprintf(" with header \"%s: %s\"", key, ds.Headers[key])
 	}
 
-	for key, value := range ds.Auth {
-		out += fmt.Sprintf(" with %s \"%s\"", key, value)
+	for _, key := range OrderedKeys(ds.Auth, ds.AuthOrder) {
+		out += fmt.Sprintf(" with %s \"%s\"", key, ds.Auth[key])
 	}
 
-	for key, value := range ds.Options {
-		out += fmt.Sprintf(" %s \"%s\"", key, value)
+	for _, key := range OrderedKeys(ds.Options, ds.OptionOrder) {
+		out += fmt.Sprintf(" %s \"%s\"", key, ds.Options[key])
 	}
 
 	return out
 }
 
+// SetHeader sets a header, keeping its declaration order
+func (ds *DownloadStatement) SetHeader(key, value string) {
+	ds.Headers = setOrdered(ds.Headers, &ds.HeaderOrder, key, value)
+}
+
+// SetAuth sets an authentication method, keeping its declaration order
+func (ds *DownloadStatement) SetAuth(key, value string) {
+	ds.Auth = setOrdered(ds.Auth, &ds.AuthOrder, key, value)
+}
+
+// SetOption sets an option, keeping its declaration order
+func (ds *DownloadStatement) SetOption(key, value string) {
+	ds.Options = setOrdered(ds.Options, &ds.OptionOrder, key, value)
+}
+
 // PermissionSpec represents a permission specification for downloaded files
 type PermissionSpec struct {
 	Permissions []string
@@ -0,0 +1,41 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// LockStatement runs its statements while holding a named lock, so only one
+// run of them proceeds at a time wherever the lock backend is shared.
+// Syntax: lock "name" [using "backend"] [waiting up to <duration>]:
+// For example
+// lock "deploy-production":
+// lock "deploy-{$environment}" using "redis://locks.internal:6379" waiting up to 10 minutes:
+type LockStatement struct {
+	Token   lexer.Token
+	Name    string
+	Backend string // lock backend URL; the project's lock backend when empty
+	Wait    string // Go duration syntax; waits until the lock is free when empty
+	Body    []Statement
+}
+
+func (ls *LockStatement) statementNode() {}
+func (ls *LockStatement) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "lock %q", ls.Name)
+	if ls.Backend != "" {
+		fmt.Fprintf(&out, " using %q", ls.Backend)
+	}
+	if ls.Wait != "" {
+		out.WriteString(" waiting up to " + ls.Wait)
+	}
+	out.WriteString(":\n")
+	for _, stmt := range ls.Body {
+		out.WriteString("  ")
+		out.WriteString(stmt.String())
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// LogTarget describes a file that receives a copy of shell output.
+// Syntax: to "path" [appending | keeping N]
+// By default the file is truncated; Keep > 0 rotates previous logs to
+// path.1 .. path.N first, and Append writes after the existing content.
+type LogTarget struct {
+	Path   string
+	Keep   int
+	Append bool
+}
+
+func (lt *LogTarget) String() string {
+	out := fmt.Sprintf("to %q", lt.Path)
+	if lt.Append {
+		out += " appending"
+	} else if lt.Keep > 0 {
+		out += fmt.Sprintf(" keeping %d", lt.Keep)
+	}
+	return out
+}
+
+// LogOutputStatement tees the output of subsequent shell statements in a
+// task to a file.
+// Syntax: log output to "logs/{task}.log" [appending | keeping N]
+type LogOutputStatement struct {
+	Token  lexer.Token
+	Target *LogTarget
+}
+
+func (los *LogOutputStatement) statementNode() {}
+func (los *LogOutputStatement) String() string {
+	return "log output " + los.Target.String()
+}
@@ -35,8 +35,8 @@ func (ns *NetworkStatement) String() string {
 		out = fmt.Sprintf("ping host \"%s\"", ns.Target)
 	}
 
-	for key, value := range ns.Options {
-		out += fmt.Sprintf(" %s %s", key, value)
+	for _, key := range sortedKeys(ns.Options) {
+		out += fmt.Sprintf(" %s %s", key, ns.Options[key])
 	}
 
 	if ns.Condition != "" {
@@ -0,0 +1,15 @@
+package ast
+
+import "github.com/phillarmonic/drun/v2/internal/lexer"
+
+// NotifyStatement asks for a desktop notification and a terminal bell when
+// the run finishes, whether it succeeds or fails.
+// Syntax: notify me when done
+type NotifyStatement struct {
+	Token lexer.Token
+}
+
+func (ns *NotifyStatement) statementNode() {}
+func (ns *NotifyStatement) String() string {
+	return "notify me when done"
+}
@@ -23,8 +23,8 @@ func (oas *OrchestrationActionStatement) String() string {
 	if len(oas.ServiceFilters) > 0 {
 		out += fmt.Sprintf(" services %v", oas.ServiceFilters)
 	}
-	for key, value := range oas.Options {
-		out += fmt.Sprintf(" %s \"%s\"", key, value)
+	for _, key := range sortedKeys(oas.Options) {
+		out += fmt.Sprintf(" %s \"%s\"", key, oas.Options[key])
 	}
 	return out
 }
@@ -79,8 +79,8 @@ func (ss *ServiceStatement) String() string {
 
 	if len(ss.Environment) > 0 {
 		out.WriteString("    environment:\n")
-		for k, v := range ss.Environment {
-			fmt.Fprintf(&out, "        %s \"%s\"\n", k, v)
+		for _, k := range sortedKeys(ss.Environment) {
+			fmt.Fprintf(&out, "        %s \"%s\"\n", k, ss.Environment[k])
 		}
 	}
 
@@ -161,8 +161,8 @@ func (hc *HealthCheckConfig) String() string {
 		}
 		if len(hc.Headers) > 0 {
 			out.WriteString("        headers:\n")
-			for k, v := range hc.Headers {
-				fmt.Fprintf(&out, "            %s \"%s\"\n", k, v)
+			for _, k := range sortedKeys(hc.Headers) {
+				fmt.Fprintf(&out, "            %s \"%s\"\n", k, hc.Headers[k])
 			}
 		}
 	case "tcp":
@@ -447,8 +447,8 @@ func (oas *OrchestrateActionStatement) String() string {
 
 	if len(oas.Options) > 0 {
 		out.WriteString(" with:")
-		for k, v := range oas.Options {
-			fmt.Fprintf(&out, "\n        %s \"%s\"", k, v)
+		for _, k := range sortedKeys(oas.Options) {
+			fmt.Fprintf(&out, "\n        %s \"%s\"", k, oas.Options[k])
 		}
 	}
 
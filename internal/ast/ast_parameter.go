@@ -23,6 +23,9 @@ type ParameterStatement struct {
 	Pattern      string
 	PatternMacro string
 	EmailFormat  bool
+	MustExist    bool   // "as file path which must exist"
+	Completion   string // "completed by" command listing values for shell completion
+	Validator    string // "validated by" custom validator name
 }
 
 func (ps *ParameterStatement) statementNode() {}
@@ -48,6 +51,18 @@ func (ps *ParameterStatement) String() string {
 		out.WriteString(ps.DataType)
 	}
 
+	if ps.MustExist {
+		out.WriteString(" which must exist")
+	}
+
+	if ps.Validator != "" {
+		fmt.Fprintf(&out, " validated by %q", ps.Validator)
+	}
+
+	if ps.Completion != "" {
+		fmt.Fprintf(&out, " completed by %q", ps.Completion)
+	}
+
 	return out.String()
 }
 
@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// PluginStatement delegates a statement to an external drun-x-<tool>
+// executable found on PATH.
+// Syntax: x <tool> <arguments...>
+// For example
+// x sentry release new "{version}"
+type PluginStatement struct {
+	Token lexer.Token
+	Tool  string
+	Args  []string // interpolated when the statement runs
+}
+
+// plainPluginArg matches arguments that are written without quotes
+var plainPluginArg = regexp.MustCompile(`^[A-Za-z0-9_.:/=+-]+$`)
+
+func (ps *PluginStatement) statementNode() {}
+func (ps *PluginStatement) String() string {
+	var out strings.Builder
+	out.WriteString("x " + ps.Tool)
+	for _, arg := range ps.Args {
+		out.WriteByte(' ')
+		if plainPluginArg.MatchString(arg) {
+			out.WriteString(arg)
+		} else {
+			out.WriteString(strconv.Quote(arg))
+		}
+	}
+	return out.String()
+}
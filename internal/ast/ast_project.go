@@ -45,18 +45,309 @@ func (ps *ProjectStatement) String() string {
 
 // SetStatement represents a project setting (set key to value)
 type SetStatement struct {
-	Token lexer.Token
-	Key   string
-	Value Expression
+	Token     lexer.Token
+	Key       string
+	Value     Expression
+	Encrypted bool // set encrypted db_password to "ENC[...]": the value is decrypted with the user's age keys
 }
 
 func (ss *SetStatement) statementNode()      {}
 func (ss *SetStatement) projectSettingNode() {}
 func (ss *SetStatement) String() string {
+	key := ss.Key
+	if ss.Encrypted {
+		key = "encrypted " + key
+	}
 	if ss.Value != nil {
-		return fmt.Sprintf("set %s to %s", ss.Key, ss.Value.String())
+		return fmt.Sprintf("set %s to %s", key, ss.Value.String())
+	}
+	return fmt.Sprintf("set %s to <nil>", key)
+}
+
+// DefaultTaskStatement names the task that runs when xdrun is invoked without
+// a task (default task is "help-menu")
+type DefaultTaskStatement struct {
+	Token    lexer.Token
+	TaskName string
+}
+
+func (ds *DefaultTaskStatement) statementNode()      {}
+func (ds *DefaultTaskStatement) projectSettingNode() {}
+func (ds *DefaultTaskStatement) String() string {
+	return fmt.Sprintf("default task is %q", ds.TaskName)
+}
+
+// ProfileStatement represents a named parameter preset selected with
+// --profile (profile "prod": set env to "prod", set replicas to "5")
+type ProfileStatement struct {
+	Token  lexer.Token
+	Name   string
+	Values []*SetStatement
+}
+
+func (ps *ProfileStatement) statementNode()      {}
+func (ps *ProfileStatement) projectSettingNode() {}
+func (ps *ProfileStatement) String() string {
+	values := make([]string, len(ps.Values))
+	for i, value := range ps.Values {
+		values[i] = value.String()
+	}
+	return fmt.Sprintf("profile %q: %s", ps.Name, strings.Join(values, ", "))
+}
+
+// Params returns the parameter values of the profile, keyed by name
+func (ps *ProfileStatement) Params() map[string]string {
+	params := make(map[string]string, len(ps.Values))
+	for _, value := range ps.Values {
+		if value.Value != nil {
+			params[value.Key] = value.Value.String()
+		}
+	}
+	return params
+}
+
+// Profile returns the profile named name, or nil when the project has none
+func (ps *ProjectStatement) Profile(name string) *ProfileStatement {
+	for _, setting := range ps.Settings {
+		if profile, ok := setting.(*ProfileStatement); ok && profile.Name == name {
+			return profile
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the names of the project's profiles in declaration order
+func (ps *ProjectStatement) ProfileNames() []string {
+	var names []string
+	for _, setting := range ps.Settings {
+		if profile, ok := setting.(*ProfileStatement); ok {
+			names = append(names, profile.Name)
+		}
+	}
+	return names
+}
+
+// NamespaceDefaultsStatement overrides parameter defaults of included tasks
+// (defaults for docker.*: set registry to "ghcr.io/acme"). The pattern names
+// every task of a namespace ("docker.*") or a single task ("docker.build").
+type NamespaceDefaultsStatement struct {
+	Token   lexer.Token
+	Pattern string
+	Values  []*SetStatement
+}
+
+func (ns *NamespaceDefaultsStatement) statementNode()      {}
+func (ns *NamespaceDefaultsStatement) projectSettingNode() {}
+func (ns *NamespaceDefaultsStatement) String() string {
+	values := make([]string, len(ns.Values))
+	for i, value := range ns.Values {
+		values[i] = value.String()
+	}
+	return fmt.Sprintf("defaults for %s: %s", ns.Pattern, strings.Join(values, ", "))
+}
+
+// Params returns the parameter defaults of the statement, keyed by name
+func (ns *NamespaceDefaultsStatement) Params() map[string]string {
+	params := make(map[string]string, len(ns.Values))
+	for _, value := range ns.Values {
+		if value.Value != nil {
+			params[value.Key] = value.Value.String()
+		}
+	}
+	return params
+}
+
+// Matches reports whether the pattern covers task taskName of namespace
+func (ns *NamespaceDefaultsStatement) Matches(namespace, taskName string) bool {
+	if namespace == "" {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(ns.Pattern, ".*"); ok {
+		return namespace == prefix
+	}
+	return ns.Pattern == namespace+"."+taskName
+}
+
+// EnvironmentStatement declares the toolchain the project needs, provisioned
+// with cmd:env up (environment: image "golang:1.22", asdf nodejs "20.11.0", nix jq)
+type EnvironmentStatement struct {
+	Token   lexer.Token
+	Entries []EnvironmentEntry
+}
+
+// EnvironmentEntry is one declared toolchain requirement
+type EnvironmentEntry struct {
+	Kind    string // "image", "asdf" or "nix"
+	Name    string // image reference, asdf plugin or nix package
+	Version string // asdf version; empty for images and nix packages
+}
+
+func (es *EnvironmentStatement) statementNode()      {}
+func (es *EnvironmentStatement) projectSettingNode() {}
+func (es *EnvironmentStatement) String() string {
+	var out strings.Builder
+	out.WriteString("environment:")
+	for _, entry := range es.Entries {
+		out.WriteString("\n    ")
+		out.WriteString(entry.String())
+	}
+	return out.String()
+}
+
+func (ee EnvironmentEntry) String() string {
+	switch ee.Kind {
+	case "image":
+		return fmt.Sprintf("image %q", ee.Name)
+	case "asdf":
+		return fmt.Sprintf("asdf %s %q", ee.Name, ee.Version)
+	default:
+		return fmt.Sprintf("%s %s", ee.Kind, ee.Name)
+	}
+}
+
+// Key returns the name detect environment looks the entry up by: the image
+// repository without its tag, or the tool name
+func (ee EnvironmentEntry) Key() string {
+	if ee.Kind != "image" {
+		return ee.Name
+	}
+	name := ee.Name
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	}
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name = name[:colon]
+	}
+	return name
+}
+
+// Value returns what detect environment reports for the entry: the full
+// image reference, the asdf version or the nix package
+func (ee EnvironmentEntry) Value() string {
+	if ee.Kind == "asdf" {
+		return ee.Version
+	}
+	return ee.Name
+}
+
+// Environment returns the project's environment declaration, or nil
+func (ps *ProjectStatement) Environment() *EnvironmentStatement {
+	for _, setting := range ps.Settings {
+		if env, ok := setting.(*EnvironmentStatement); ok {
+			return env
+		}
+	}
+	return nil
+}
+
+// PathStatement represents a project-level PATH extension
+// (set path to include "dir" and "dir")
+type PathStatement struct {
+	Token   lexer.Token
+	Entries []string
+}
+
+func (ps *PathStatement) statementNode()      {}
+func (ps *PathStatement) projectSettingNode() {}
+func (ps *PathStatement) String() string {
+	quoted := make([]string, len(ps.Entries))
+	for i, entry := range ps.Entries {
+		quoted[i] = fmt.Sprintf("%q", entry)
+	}
+	return "set path to include " + strings.Join(quoted, " and ")
+}
+
+// ShellEnvironmentStatement controls which inherited environment variables
+// shell commands receive
+// (shell environment allow ["PATH", "HOME", "CI_*"] / shell environment deny ["*_TOKEN"])
+type ShellEnvironmentStatement struct {
+	Token    lexer.Token
+	Mode     string   // "allow" or "deny"
+	Patterns []string // variable names; * matches any run of characters
+}
+
+func (ses *ShellEnvironmentStatement) statementNode()      {}
+func (ses *ShellEnvironmentStatement) projectSettingNode() {}
+func (ses *ShellEnvironmentStatement) String() string {
+	quoted := make([]string, len(ses.Patterns))
+	for i, pattern := range ses.Patterns {
+		quoted[i] = fmt.Sprintf("%q", pattern)
+	}
+	return fmt.Sprintf("shell environment %s [%s]", ses.Mode, strings.Join(quoted, ", "))
+}
+
+// CredentialHelperStatement declares where credentials for a host come from
+// (set credential helper for "ghcr.io" to "exec:gh auth token")
+type CredentialHelperStatement struct {
+	Token  lexer.Token
+	Host   string
+	Helper string // "exec:<command>" or "env:<VARIABLE>"
+}
+
+func (cs *CredentialHelperStatement) statementNode()      {}
+func (cs *CredentialHelperStatement) projectSettingNode() {}
+func (cs *CredentialHelperStatement) String() string {
+	return fmt.Sprintf("set credential helper for %q to %q", cs.Host, cs.Helper)
+}
+
+// ConditionStatement names a condition that when, if and while statements
+// reference by name (condition "is prod" means environment is "production")
+type ConditionStatement struct {
+	Token      lexer.Token
+	Name       string
+	Expression string
+}
+
+func (cs *ConditionStatement) statementNode()      {}
+func (cs *ConditionStatement) projectSettingNode() {}
+func (cs *ConditionStatement) String() string {
+	return fmt.Sprintf("condition %q means %s", cs.Name, cs.Expression)
+}
+
+// HostGroupStatement names a group of hosts that run statements can target
+// (hosts group "web" = ["web-1", "web-2", "web-3"])
+type HostGroupStatement struct {
+	Token lexer.Token
+	Name  string
+	Hosts []string
+}
+
+func (hs *HostGroupStatement) statementNode()      {}
+func (hs *HostGroupStatement) projectSettingNode() {}
+func (hs *HostGroupStatement) String() string {
+	quoted := make([]string, len(hs.Hosts))
+	for i, host := range hs.Hosts {
+		quoted[i] = fmt.Sprintf("%q", host)
+	}
+	return fmt.Sprintf("hosts group %q = [%s]", hs.Name, strings.Join(quoted, ", "))
+}
+
+// HTTPClientStatement names a base URL, headers and timeout that HTTP
+// statements reuse with `using client "name"`
+// (http client "api": base url "https://api.example.com", timeout 10s)
+type HTTPClientStatement struct {
+	Token       lexer.Token
+	Name        string
+	BaseURL     string
+	Headers     map[string]string
+	HeaderOrder []string // keys of Headers in declaration order
+	Timeout     string   // Go duration; empty for the HTTP statement default
+}
+
+func (hs *HTTPClientStatement) statementNode()      {}
+func (hs *HTTPClientStatement) projectSettingNode() {}
+func (hs *HTTPClientStatement) String() string {
+	var options []string
+	if hs.BaseURL != "" {
+		options = append(options, fmt.Sprintf("base url %q", hs.BaseURL))
+	}
+	for _, key := range hs.HeaderOrder {
+		options = append(options, fmt.Sprintf("header %q", key+": "+hs.Headers[key]))
+	}
+	if hs.Timeout != "" {
+		options = append(options, "timeout "+hs.Timeout)
 	}
-	return fmt.Sprintf("set %s to <nil>", ss.Key)
+	return fmt.Sprintf("http client %q: %s", hs.Name, strings.Join(options, ", "))
 }
 
 // IncludeStatement represents an include directive
@@ -64,21 +355,83 @@ type IncludeStatement struct {
 	Token     lexer.Token
 	Path      string
 	Selectors []string
+	Symbols   []IncludeSymbol // named snippets/templates to import; empty = all of the selected kinds
 	Namespace string
+	Override  bool // shadow tasks/snippets/templates already included into the namespace
+	Member    bool // Path is a workspace member directory: include tasks from member "services/api"
+}
+
+// IncludeSymbol is a snippet or template imported by name, optionally renamed:
+// include snippets "docker login" as "registry-login" from "lib.drun"
+type IncludeSymbol struct {
+	Kind  string // "snippets" or "templates"
+	Name  string
+	Alias string
+}
+
+// LocalName returns the name the symbol is imported under
+func (s IncludeSymbol) LocalName() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+func (s IncludeSymbol) String() string {
+	if s.Alias != "" {
+		return fmt.Sprintf("%q as %q", s.Name, s.Alias)
+	}
+	return fmt.Sprintf("%q", s.Name)
+}
+
+// SymbolNames maps the names imported for kind ("snippets" or "templates") to
+// their local names; it returns nil when the include imports every symbol
+func (is *IncludeStatement) SymbolNames(kind string) map[string]string {
+	var names map[string]string
+	for _, symbol := range is.Symbols {
+		if symbol.Kind != kind {
+			continue
+		}
+		if names == nil {
+			names = make(map[string]string)
+		}
+		names[symbol.Name] = symbol.LocalName()
+	}
+	return names
 }
 
 func (is *IncludeStatement) statementNode()      {}
 func (is *IncludeStatement) projectSettingNode() {}
 func (is *IncludeStatement) String() string {
 	var out strings.Builder
+	path := is.Path
+	if is.Member {
+		path = fmt.Sprintf("member %q", is.Path)
+	}
 	if len(is.Selectors) > 0 {
-		fmt.Fprintf(&out, "include %s from %s", strings.Join(is.Selectors, ", "), is.Path)
+		selectors := make([]string, len(is.Selectors))
+		for i, selector := range is.Selectors {
+			selectors[i] = selector
+			var names []string
+			for _, symbol := range is.Symbols {
+				if symbol.Kind == selector {
+					names = append(names, symbol.String())
+				}
+			}
+			if len(names) > 0 {
+				selectors[i] += " " + strings.Join(names, ", ")
+			}
+		}
+		fmt.Fprintf(&out, "include %s from %s", strings.Join(selectors, ", "), path)
 	} else {
-		fmt.Fprintf(&out, "include %s", is.Path)
+		fmt.Fprintf(&out, "include %s", path)
 	}
 	if is.Namespace != "" {
 		fmt.Fprintf(&out, " as %s", is.Namespace)
 	}
+	if is.Override {
+		out.WriteString(" override")
+	}
 	return out.String()
 }
 
@@ -93,7 +446,8 @@ func (scs *ShellConfigStatement) projectSettingNode() {}
 func (scs *ShellConfigStatement) String() string {
 	var out strings.Builder
 	out.WriteString("shell config:")
-	for platform, config := range scs.Platforms {
+	for _, platform := range sortedKeys(scs.Platforms) {
+		config := scs.Platforms[platform]
 		fmt.Fprintf(&out, "\n  %s:", platform)
 		fmt.Fprintf(&out, "\n    executable: \"%s\"", config.Executable)
 		if len(config.Args) > 0 {
@@ -104,8 +458,8 @@ func (scs *ShellConfigStatement) String() string {
 		}
 		if len(config.Environment) > 0 {
 			out.WriteString("\n    environment:")
-			for key, value := range config.Environment {
-				fmt.Fprintf(&out, "\n      %s: \"%s\"", key, value)
+			for _, key := range sortedKeys(config.Environment) {
+				fmt.Fprintf(&out, "\n      %s: \"%s\"", key, config.Environment[key])
 			}
 		}
 	}
@@ -121,10 +475,12 @@ type PlatformShellConfig struct {
 
 // LifecycleHook represents lifecycle hooks
 type LifecycleHook struct {
-	Token lexer.Token
-	Type  string // "before", "after", "setup", or "teardown"
-	Scope string // "any" for task hooks, "drun" for tool hooks
-	Body  []Statement
+	Token    lexer.Token
+	Type     string // "before", "after", "setup", or "teardown"
+	Scope    string // "any" for task hooks, "drun" for tool hooks, "task" or "matching" for scoped hooks
+	Target   string // task name ("task" scope) or glob pattern ("matching" scope)
+	Priority int    // higher runs first; hooks with equal priority run in source order
+	Body     []Statement
 }
 
 func (lh *LifecycleHook) statementNode()      {}
@@ -136,13 +492,20 @@ func (lh *LifecycleHook) String() string {
 		out.WriteString(lh.Scope)
 		out.WriteString(" ")
 		out.WriteString(lh.Type)
-		out.WriteString(":")
+	} else if lh.Scope == "task" {
+		fmt.Fprintf(&out, "%s task %q", lh.Type, lh.Target)
+	} else if lh.Scope == "matching" {
+		fmt.Fprintf(&out, "%s tasks matching %q", lh.Type, lh.Target)
 	} else {
 		out.WriteString(lh.Type)
 		out.WriteString(" ")
 		out.WriteString(lh.Scope)
-		out.WriteString(" task:")
+		out.WriteString(" task")
+	}
+	if lh.Priority != 0 {
+		fmt.Fprintf(&out, " priority %d", lh.Priority)
 	}
+	out.WriteString(":")
 	for _, stmt := range lh.Body {
 		out.WriteString("\n    ")
 		out.WriteString(stmt.String())
@@ -2,7 +2,6 @@ package ast
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -69,15 +68,6 @@ func (s *SCMRegistryStatement) String() string {
 	return out.String()
 }
 
-func sortedKeys[V any](values map[string]V) []string {
-	keys := make([]string, 0, len(values))
-	for key := range values {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-	return keys
-}
-
 // GitQueryStatement captures a value derived from a registered Git source.
 type GitQueryStatement struct {
 	Token          lexer.Token
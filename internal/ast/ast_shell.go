@@ -12,16 +12,78 @@ type ShellStatement struct {
 	Action               string
 	Command              string
 	Commands             []string
+	PipeInto             []string // commands receiving Command's stdout, in order (run "a" | run "b")
 	CaptureVar           string
 	Attached             bool
+	Interactive          bool // attached and requires a terminal (run ... interactively)
 	StreamOutput         bool
 	IsMultiline          bool
 	ServiceScoped        bool
 	ServiceName          string
 	ServiceNameIsLiteral bool
+	Verbosity            string            // "quiet", "verbose", or "" (follow global settings)
+	Log                  *LogTarget        // optional tee target (logging to "file")
+	ExitCodes            []ExitCodeMapping // exit codes treated as named outcomes instead of failures
+	ExitStateVar         string            // receives the mapped outcome (mapping exit code ... as $var)
+	FailureMessage       string            // shown with the error when the command fails (failing with "...")
+	RunAs                string            // user the command runs as through sudo (run "..." as root)
+	Host                 string            // host the command runs on over ssh (run "..." on host "web-1")
+	HostGroup            string            // host group the command runs on over ssh (run "..." on group "web")
+	HostParallel         bool              // runs on the hosts of the group at once (in parallel)
+	HostWorkers          int               // hosts at a time in parallel (with N workers); 0 uses the default
+	LineFilters          []LineFilter      // filters of the output lines shown and captured, applied in order
+}
+
+// ExitCodeMapping names an exit code that is an expected outcome rather than
+// a failure, such as exit code 2 of `terraform plan -detailed-exitcode`
+type ExitCodeMapping struct {
+	Code  int
+	Label string
+}
+
+// LineFilter keeps or drops the output lines of a command that contain a
+// text or match a regular expression, such as `keeping lines containing
+// "ERROR"` or `dropping lines matching "^\s*$"`
+type LineFilter struct {
+	Verb    string // "filtering", "keeping" or "dropping"
+	Regexp  bool   // Pattern is a regular expression (matching) rather than a text (containing)
+	Pattern string
+}
+
+// Keep reports whether the filter keeps the lines it selects rather than
+// dropping them
+func (lf LineFilter) Keep() bool {
+	return lf.Verb != "dropping"
+}
+
+func (lf LineFilter) String() string {
+	match := "containing"
+	if lf.Regexp {
+		match = "matching"
+	}
+	return fmt.Sprintf("%s lines %s %q", lf.Verb, match, lf.Pattern)
+}
+
+// lineFiltersString renders line filters as trailing modifiers
+func lineFiltersString(filters []LineFilter) string {
+	var out string
+	for _, filter := range filters {
+		out += " " + filter.String()
+	}
+	return out
 }
 
 func (ss *ShellStatement) statementNode() {}
+
+// AttachedModifier returns the modifier that attached the statement to the
+// terminal: "interactively" or "attached"
+func (ss *ShellStatement) AttachedModifier() string {
+	if ss.Interactive {
+		return "interactively"
+	}
+	return "attached"
+}
+
 func (ss *ShellStatement) String() string {
 	if ss.IsMultiline {
 		var out string
@@ -33,6 +95,7 @@ func (ss *ShellStatement) String() string {
 				prefix += fmt.Sprintf(" in service %s", ss.ServiceName)
 			}
 		}
+		prefix += ss.modifiersString()
 		out = prefix + ":"
 		if ss.CaptureVar != "" {
 			out = fmt.Sprintf("%s as %s:", prefix, ss.CaptureVar)
@@ -55,10 +118,67 @@ func (ss *ShellStatement) String() string {
 	}
 
 	if ss.CaptureVar != "" {
-		return fmt.Sprintf("%s \"%s\" as %s", prefix, ss.Command, ss.CaptureVar)
+		return fmt.Sprintf("%s \"%s\" as %s", prefix, ss.Command, ss.CaptureVar) + lineFiltersString(ss.LineFilters)
 	}
+	out := fmt.Sprintf("%s \"%s\"", prefix, ss.Command)
+	for _, cmd := range ss.PipeInto {
+		out += fmt.Sprintf(" | run \"%s\"", cmd)
+	}
+	return out + ss.hostsString() + ss.modifiersString()
+}
+
+// hostsString renders the remote target: on host "web-1", or on group "web"
+// [in parallel [with N workers]]
+func (ss *ShellStatement) hostsString() string {
+	var out string
+	switch {
+	case ss.Host != "":
+		out = fmt.Sprintf(" on host %q", ss.Host)
+	case ss.HostGroup != "":
+		out = fmt.Sprintf(" on group %q", ss.HostGroup)
+	}
+	if ss.HostParallel {
+		out += " in parallel"
+		if ss.HostWorkers > 0 {
+			out += fmt.Sprintf(" with %d workers", ss.HostWorkers)
+		}
+	}
+	return out
+}
+
+// modifiersString renders trailing modifiers (attached/interactively, run-as user, quietly/verbosely,
+// logging, exit code mappings and failure message)
+func (ss *ShellStatement) modifiersString() string {
+	var out string
 	if ss.Attached {
-		return fmt.Sprintf("%s \"%s\" attached", prefix, ss.Command)
+		out += " " + ss.AttachedModifier()
+	}
+	switch ss.RunAs {
+	case "":
+	case "root":
+		out += " as root"
+	default:
+		out += fmt.Sprintf(" as user %q", ss.RunAs)
+	}
+	if ss.Verbosity != "" {
+		out += " " + ss.Verbosity + "ly"
+	}
+	if ss.Log != nil {
+		out += " logging " + ss.Log.String()
+	}
+	for i, mapping := range ss.ExitCodes {
+		if i == 0 {
+			out += " mapping"
+		} else {
+			out += " and"
+		}
+		out += fmt.Sprintf(" exit code %d to %q", mapping.Code, mapping.Label)
+	}
+	if ss.ExitStateVar != "" {
+		out += " as $" + ss.ExitStateVar
+	}
+	if ss.FailureMessage != "" {
+		out += fmt.Sprintf(" failing with %q", ss.FailureMessage)
 	}
-	return fmt.Sprintf("%s \"%s\"", prefix, ss.Command)
+	return out + lineFiltersString(ss.LineFilters)
 }
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -12,11 +13,36 @@ type TaskStatement struct {
 	Token        lexer.Token
 	Name         string
 	Mode         string
+	Container    string // image the task's shell statements run in (runs in container "image")
 	Description  string
 	Annotations  []Annotation
 	Parameters   []ParameterStatement
 	Dependencies []DependencyGroup
 	Body         []Statement
+	Doc          string       // markdown from the task's doc: block, with indentation removed
+	Sources      []string     // globs of the files the task depends on (sources "src/**/*.go")
+	Once         bool         // declared `once per commit`; skipped when it already succeeded for the commit
+	CPUs         float64      // cpus the task needs while it runs (needs 2 cpus); 0 when not declared
+	Memory       int64        // bytes of memory the task needs while it runs (needs 4GB memory); 0 when not declared
+	Default      bool         // marked with `default`; runs when xdrun is invoked without a task
+	Outputs      []string     // variables the task hands to tasks that need them (outputs "image_tag"), without the $
+	OutputNeeds  []OutputNeed // outputs of other tasks it reads (needs output "image_tag" from task "build")
+}
+
+// OutputNeed is a task's data dependency on an output of another task:
+// needs output "image_tag" from task "build" [as $tag]
+type OutputNeed struct {
+	Output string
+	Task   string
+	As     string // variable the value is exposed as, without the $; the output's name when empty
+}
+
+func (n OutputNeed) String() string {
+	out := fmt.Sprintf("needs output %q from task %q", n.Output, n.Task)
+	if n.As != "" && n.As != n.Output {
+		out += " as $" + n.As
+	}
+	return out
 }
 
 func (ts *TaskStatement) statementNode() {}
@@ -30,11 +56,56 @@ func (ts *TaskStatement) String() string {
 	if ts.Mode != "" {
 		fmt.Fprintf(&out, " mode \"%s\"", ts.Mode)
 	}
+	if ts.Container != "" {
+		fmt.Fprintf(&out, " runs in container \"%s\"", ts.Container)
+	}
 	if ts.Description != "" {
 		fmt.Fprintf(&out, " means \"%s\"", ts.Description)
 	}
+	if ts.Default {
+		out.WriteString(" default")
+	}
 	out.WriteString(":\n")
 
+	if ts.Doc != "" {
+		out.WriteString("  doc:\n")
+		for _, line := range strings.Split(ts.Doc, "\n") {
+			if line == "" {
+				out.WriteString("\n")
+				continue
+			}
+			fmt.Fprintf(&out, "    %s\n", line)
+		}
+	}
+
+	if len(ts.Sources) > 0 {
+		quoted := make([]string, len(ts.Sources))
+		for i, source := range ts.Sources {
+			quoted[i] = fmt.Sprintf("%q", source)
+		}
+		fmt.Fprintf(&out, "  sources %s\n", strings.Join(quoted, ", "))
+	}
+
+	if ts.Once {
+		out.WriteString("  once per commit\n")
+	}
+
+	if ts.CPUs > 0 || ts.Memory > 0 {
+		fmt.Fprintf(&out, "  %s\n", NeedsString(ts.CPUs, ts.Memory))
+	}
+
+	if len(ts.Outputs) > 0 {
+		quoted := make([]string, len(ts.Outputs))
+		for i, output := range ts.Outputs {
+			quoted[i] = fmt.Sprintf("%q", output)
+		}
+		fmt.Fprintf(&out, "  outputs %s\n", strings.Join(quoted, ", "))
+	}
+
+	for _, need := range ts.OutputNeeds {
+		fmt.Fprintf(&out, "  %s\n", need.String())
+	}
+
 	for _, dep := range ts.Dependencies {
 		fmt.Fprintf(&out, "  %s\n", dep.String())
 	}
@@ -49,11 +120,43 @@ func (ts *TaskStatement) String() string {
 	return out.String()
 }
 
+// NeedsString renders the resources a task needs as they are declared
+func NeedsString(cpus float64, memory int64) string {
+	var parts []string
+	if cpus > 0 {
+		noun := "cpus"
+		if cpus == 1 {
+			noun = "cpu"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", strconv.FormatFloat(cpus, 'f', -1, 64), noun))
+	}
+	if memory > 0 {
+		parts = append(parts, FormatMemorySize(memory)+" memory")
+	}
+	return "needs " + strings.Join(parts, " and ")
+}
+
+// FormatMemorySize renders bytes in the largest unit that divides them, like
+// 4GB or 512MB
+func FormatMemorySize(bytes int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+		if bytes%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bytes/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
+
 // TaskCallStatement represents calling another task
 type TaskCallStatement struct {
 	Token      lexer.Token
 	TaskName   string
 	Parameters map[string]string
+	Silent     bool   // silently: the called task's output is only shown if it fails
+	CaptureVar string // capturing output as $var, without the $; empty when not capturing
 }
 
 func (tcs *TaskCallStatement) statementNode() {}
@@ -62,10 +165,16 @@ func (tcs *TaskCallStatement) String() string {
 	fmt.Fprintf(&out, "call task \"%s\"", tcs.TaskName)
 	if len(tcs.Parameters) > 0 {
 		out.WriteString(" with")
-		for key, value := range tcs.Parameters {
-			fmt.Fprintf(&out, " %s=\"%s\"", key, value)
+		for _, key := range sortedKeys(tcs.Parameters) {
+			fmt.Fprintf(&out, " %s=\"%s\"", key, tcs.Parameters[key])
 		}
 	}
+	if tcs.Silent {
+		out.WriteString(" silently")
+	}
+	if tcs.CaptureVar != "" {
+		fmt.Fprintf(&out, " capturing output as $%s", tcs.CaptureVar)
+	}
 	return out.String()
 }
 
@@ -154,8 +263,8 @@ func (tfts *TaskFromTemplateStatement) String() string {
 
 	if len(tfts.Overrides) > 0 {
 		out.WriteString(":\n  with")
-		for key, value := range tfts.Overrides {
-			fmt.Fprintf(&out, " %s=\"%s\"", key, value)
+		for _, key := range sortedKeys(tfts.Overrides) {
+			fmt.Fprintf(&out, " %s=\"%s\"", key, tfts.Overrides[key])
 		}
 	}
 
@@ -0,0 +1,23 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// TodoStatement marks a step that is not written yet, so a task can be
+// sketched before it is finished. Running it prints a notice; the project's
+// todo behavior decides whether the task goes on, stops or fails.
+// Syntax: todo "message"
+// For example
+// todo "implement rollback"
+type TodoStatement struct {
+	Token   lexer.Token
+	Message string
+}
+
+func (ts *TodoStatement) statementNode() {}
+func (ts *TodoStatement) String() string {
+	return fmt.Sprintf("todo %q", ts.Message)
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -14,6 +15,9 @@ type VariableStatement struct {
 	Value     Expression
 	Function  string
 	Arguments []string
+	Shadow    bool // let/set reassigns a task parameter on purpose
+
+	LineFilters []LineFilter // filters of the captured output lines (capture from shell, capture lines of)
 }
 
 func (vs *VariableStatement) statementNode() {}
@@ -23,6 +27,9 @@ func (vs *VariableStatement) String() string {
 	switch vs.Operation {
 	case "let":
 		out.WriteString("let ")
+		if vs.Shadow {
+			out.WriteString("shadow ")
+		}
 		out.WriteString(vs.Variable)
 		out.WriteString(" = ")
 		if vs.Value != nil {
@@ -30,6 +37,9 @@ func (vs *VariableStatement) String() string {
 		}
 	case "set":
 		out.WriteString("set ")
+		if vs.Shadow {
+			out.WriteString("shadow ")
+		}
 		out.WriteString(vs.Variable)
 		out.WriteString(" to ")
 		if vs.Value != nil {
@@ -44,6 +54,16 @@ func (vs *VariableStatement) String() string {
 			out.WriteString(" ")
 			out.WriteString(strings.Join(vs.Arguments, " "))
 		}
+	case "capture_lines":
+		fmt.Fprintf(&out, "capture lines of %q as %s", vs.Value.String(), vs.Variable)
+		for _, option := range vs.Arguments {
+			switch option {
+			case "keep_empty":
+				out.WriteString(" keeping empty lines")
+			case "no_trim":
+				out.WriteString(" without trimming")
+			}
+		}
 	default:
 		out.WriteString(vs.Operation)
 		out.WriteString(" ")
@@ -53,6 +73,7 @@ func (vs *VariableStatement) String() string {
 			out.WriteString(vs.Value.String())
 		}
 	}
+	out.WriteString(lineFiltersString(vs.LineFilters))
 
 	return out.String()
 }
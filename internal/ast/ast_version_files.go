@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// VersionFilesStatement reads or bumps the version declared by several
+// manifests (package.json, Chart.yaml, Cargo.toml, a VERSION file...).
+// Syntax:
+// get version from files ["a", ...] as $var
+// bump version major|minor|patch in files ["a", ...] [keeping them in sync] [as $var]
+type VersionFilesStatement struct {
+	Token      lexer.Token
+	Operation  string   // "get" or "bump"
+	Level      string   // "major", "minor" or "patch" for bumps
+	Files      []string // the manifests, in order
+	InSync     bool     // the manifests must declare the same version before and after
+	CaptureVar string   // variable set to the version read, or bumped to
+}
+
+func (vs *VersionFilesStatement) statementNode() {}
+func (vs *VersionFilesStatement) String() string {
+	files := make([]string, len(vs.Files))
+	for i, file := range vs.Files {
+		files[i] = fmt.Sprintf("%q", file)
+	}
+	var out strings.Builder
+	if vs.Operation == "get" {
+		fmt.Fprintf(&out, "get version from files [%s]", strings.Join(files, ", "))
+	} else {
+		fmt.Fprintf(&out, "bump version %s in files [%s]", vs.Level, strings.Join(files, ", "))
+		if vs.InSync {
+			out.WriteString(" keeping them in sync")
+		}
+	}
+	if vs.CaptureVar != "" {
+		fmt.Fprintf(&out, " as $%s", vs.CaptureVar)
+	}
+	return out.String()
+}
@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// WithinStatement is a time-boxed section: its statements are expected to
+// finish within Budget, and going over it warns or, with 'or fail', fails
+// the task.
+// Syntax: within <duration> [as "name"] [or fail]:
+// For example
+// within 10 minutes as "integration tests":
+// within "1m30s" or fail:
+type WithinStatement struct {
+	Token  lexer.Token
+	Budget string // Go duration syntax, e.g. 10m
+	Name   string // optional section name for warnings and the summary
+	Fail   bool   // fail the task instead of warning when over budget
+	Body   []Statement
+}
+
+func (ws *WithinStatement) statementNode() {}
+func (ws *WithinStatement) String() string {
+	var out strings.Builder
+	out.WriteString("within " + ws.Budget)
+	if ws.Name != "" {
+		fmt.Fprintf(&out, " as %q", ws.Name)
+	}
+	if ws.Fail {
+		out.WriteString(" or fail")
+	}
+	out.WriteString(":\n")
+	for _, stmt := range ws.Body {
+		out.WriteString("  ")
+		out.WriteString(stmt.String())
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,53 @@
+package ast
+
+import "sort"
+
+// sortedKeys returns the keys of values in sorted order, so String output
+// does not depend on map iteration order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// OrderedKeys returns the keys of values in the declaration order recorded in
+// order, followed in sorted order by any keys that were set without being
+// recorded
+func OrderedKeys[V any](values map[string]V, order []string) []string {
+	keys := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, key := range order {
+		if _, ok := values[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	if len(keys) == len(values) {
+		return keys
+	}
+
+	var rest []string
+	for key := range values {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// setOrdered sets values[key], recording key in order the first time it is
+// set, and returns the map, allocating it when nil
+func setOrdered(values map[string]string, order *[]string, key, value string) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	if _, exists := values[key]; !exists {
+		*order = append(*order, key)
+	}
+	values[key] = value
+	return values
+}
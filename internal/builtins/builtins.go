@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -55,12 +56,17 @@ var (
 // Registry holds all built-in functions
 var Registry = map[string]BuiltinFunction{
 	"current git commit":     getCurrentGitCommit,
+	"git commit short":       getShortGitCommit,
 	"current git branch":     getCurrentGitBranch,
 	"now.format":             formatCurrentTime,
 	"file exists":            checkFileExists,
 	"dir exists":             checkDirExists,
 	"env":                    getEnvironmentVariable,
 	"pwd":                    getCurrentDirectory,
+	"project root":           getProjectRoot,
+	"drun file dir":          getDrunFileDir,
+	"home dir":               getHomeDir,
+	"xdg cache dir":          getXDGCacheDir,
 	"hostname":               getHostname,
 	"start progress":         startProgress,
 	"update progress":        updateProgress,
@@ -73,6 +79,17 @@ var Registry = map[string]BuiltinFunction{
 	"docker compose status":  checkDockerComposeStatus,
 	"secret":                 getSecret,
 	"available tasks":        getAvailableTasks,
+	"task.elapsed":           getTaskElapsed,
+	"retries.used":           getRetriesUsed,
+	"run.id":                 getRunID,
+	"run.started_at":         getRunStartedAt,
+	"task.name":              getTaskName,
+	"task.file":              getTaskFile,
+	"drun.version":           getDrunVersion,
+	"stdin":                  getStdin,
+	"os":                     getOS,
+	"arch":                   getArch,
+	"cpu.count":              getCPUCount,
 	"dns_resolve":            getDNSResolve,
 	"dns_check":              getDNSCheck,
 	"dns_validate":           getDNSValidate,
@@ -114,6 +131,135 @@ func getAvailableTasks(ctx Context, args ...string) (string, error) {
 	return strings.Join(filtered, separator), nil
 }
 
+// getTaskElapsed returns how long the current task has been running. Like
+// available tasks, the timing comes from an optional context capability.
+func getTaskElapsed(ctx Context, args ...string) (string, error) {
+	provider, ok := ctx.(interface {
+		GetTaskElapsed() (string, bool)
+	})
+	if !ok {
+		return "", fmt.Errorf("task.elapsed requires task timing support")
+	}
+	elapsed, ok := provider.GetTaskElapsed()
+	if !ok {
+		return "", fmt.Errorf("task.elapsed is only available inside a task")
+	}
+	return elapsed, nil
+}
+
+// getRetriesUsed returns how many retries the run has taken from its retry
+// budget, through an optional context capability like task.elapsed
+func getRetriesUsed(ctx Context, args ...string) (string, error) {
+	provider, ok := ctx.(interface {
+		GetRetriesUsed() (int, bool)
+	})
+	if !ok {
+		return "", fmt.Errorf("retries.used requires retry budget support")
+	}
+	used, ok := provider.GetRetriesUsed()
+	if !ok {
+		return "", fmt.Errorf("retries.used is only available while a task runs")
+	}
+	return strconv.Itoa(used), nil
+}
+
+// runInfo returns the id and start time of the current run from an optional
+// context capability
+func runInfo(ctx Context, name string) (string, time.Time, error) {
+	provider, ok := ctx.(interface {
+		GetRunInfo() (string, time.Time, bool)
+	})
+	if !ok {
+		return "", time.Time{}, fmt.Errorf("%s requires run information support", name)
+	}
+	id, startedAt, ok := provider.GetRunInfo()
+	if !ok {
+		return "", time.Time{}, fmt.Errorf("%s is only available while a task runs", name)
+	}
+	return id, startedAt, nil
+}
+
+// getRunID returns the UUID of the current run, the same for every task of it
+func getRunID(ctx Context, args ...string) (string, error) {
+	id, _, err := runInfo(ctx, "run.id")
+	return id, err
+}
+
+// getRunStartedAt returns when the current run started, in RFC 3339 format
+func getRunStartedAt(ctx Context, args ...string) (string, error) {
+	_, startedAt, err := runInfo(ctx, "run.started_at")
+	if err != nil {
+		return "", err
+	}
+	return startedAt.Format(time.RFC3339), nil
+}
+
+// currentTask returns the name and drun file of the running task from an
+// optional context capability
+func currentTask(ctx Context, name string) (string, string, error) {
+	provider, ok := ctx.(interface {
+		GetCurrentTask() (string, string, bool)
+	})
+	if !ok {
+		return "", "", fmt.Errorf("%s requires task information support", name)
+	}
+	task, file, ok := provider.GetCurrentTask()
+	if !ok {
+		return "", "", fmt.Errorf("%s is only available inside a task", name)
+	}
+	return task, file, nil
+}
+
+// getTaskName returns the name of the running task
+func getTaskName(ctx Context, args ...string) (string, error) {
+	task, _, err := currentTask(ctx, "task.name")
+	return task, err
+}
+
+// getTaskFile returns the absolute path of the drun file the running task was loaded from
+func getTaskFile(ctx Context, args ...string) (string, error) {
+	_, file, err := currentTask(ctx, "task.file")
+	return file, err
+}
+
+// getDrunVersion returns the version of the running drun binary
+func getDrunVersion(ctx Context, args ...string) (string, error) {
+	provider, ok := ctx.(interface {
+		GetDrunVersion() string
+	})
+	if !ok {
+		return "", fmt.Errorf("drun.version requires version information support")
+	}
+	return provider.GetDrunVersion(), nil
+}
+
+// getStdin returns the content piped into drun, with trailing newlines
+// trimmed; it is empty when nothing was piped in
+func getStdin(ctx Context, args ...string) (string, error) {
+	provider, ok := ctx.(interface {
+		GetStdin() (string, error)
+	})
+	if !ok {
+		return "", fmt.Errorf("stdin requires standard input support")
+	}
+	return provider.GetStdin()
+}
+
+// getOS returns the operating system drun runs on, such as "linux" or "darwin"
+func getOS(ctx Context, args ...string) (string, error) {
+	return runtime.GOOS, nil
+}
+
+// getArch returns the processor architecture drun runs on, such as "amd64" or "arm64"
+func getArch(ctx Context, args ...string) (string, error) {
+	return runtime.GOARCH, nil
+}
+
+// getCPUCount returns the number of logical CPUs
+func getCPUCount(ctx Context, args ...string) (string, error) {
+	return strconv.Itoa(runtime.NumCPU()), nil
+}
+
 // getCurrentGitCommit returns the current git commit hash
 func getCurrentGitCommit(ctx Context, args ...string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "HEAD")
@@ -134,6 +280,11 @@ func getCurrentGitCommit(ctx Context, args ...string) (string, error) {
 	return commit, nil
 }
 
+// getShortGitCommit returns the short (7 character) current git commit hash
+func getShortGitCommit(ctx Context, args ...string) (string, error) {
+	return getCurrentGitCommit(ctx, "short")
+}
+
 // getCurrentGitBranch returns the current git branch name
 func getCurrentGitBranch(ctx Context, args ...string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -228,6 +379,51 @@ func getCurrentDirectory(ctx Context, args ...string) (string, error) {
 	return dir, nil
 }
 
+// getProjectRoot returns the directory relative file paths resolve from: the
+// directory of the drun file, or its parent when the file lives in .drun/.
+// Without a drun file it is the current working directory.
+func getProjectRoot(ctx Context, args ...string) (string, error) {
+	if provider, ok := ctx.(interface{ GetProjectRoot() string }); ok {
+		if dir := provider.GetProjectRoot(); dir != "" {
+			return dir, nil
+		}
+	}
+	return getCurrentDirectory(ctx)
+}
+
+// getDrunFileDir returns the directory of the drun file being executed,
+// falling back to the current working directory
+func getDrunFileDir(ctx Context, args ...string) (string, error) {
+	if provider, ok := ctx.(interface{ GetDrunFileDir() string }); ok {
+		if dir := provider.GetDrunFileDir(); dir != "" {
+			return dir, nil
+		}
+	}
+	return getCurrentDirectory(ctx)
+}
+
+// getHomeDir returns the home directory of the current user
+func getHomeDir(ctx Context, args ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return home, nil
+}
+
+// getXDGCacheDir returns $XDG_CACHE_HOME, or ~/.cache when it is not set to
+// an absolute path, as the XDG base directory specification prescribes
+func getXDGCacheDir(ctx Context, args ...string) (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := getHomeDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache"), nil
+}
+
 // getHostname returns the system hostname
 func getHostname(ctx Context, args ...string) (string, error) {
 	hostname, err := os.Hostname()
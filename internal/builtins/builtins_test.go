@@ -155,6 +155,32 @@ func TestGetCurrentDirectory(t *testing.T) {
 	}
 }
 
+func TestPathBuiltins(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	home, err := getHomeDir(nil)
+	if err != nil || home != "/home/dev" {
+		t.Fatalf("getHomeDir() = %q, %v", home, err)
+	}
+	cache, err := getXDGCacheDir(nil)
+	if err != nil || cache != filepath.Join("/home/dev", ".cache") {
+		t.Fatalf("getXDGCacheDir() = %q, %v", cache, err)
+	}
+	t.Setenv("XDG_CACHE_HOME", "/var/cache/dev")
+	if cache, _ = getXDGCacheDir(nil); cache != "/var/cache/dev" {
+		t.Fatalf("expected XDG_CACHE_HOME to win, got %q", cache)
+	}
+
+	cwd, _ := os.Getwd()
+	if root, _ := getProjectRoot(nil); root != cwd {
+		t.Fatalf("getProjectRoot() without a drun file = %q, want %q", root, cwd)
+	}
+	if dir, _ := getDrunFileDir(nil); dir != cwd {
+		t.Fatalf("getDrunFileDir() without a drun file = %q, want %q", dir, cwd)
+	}
+}
+
 func TestGetHostname(t *testing.T) {
 	result, err := getHostname(nil)
 	if err != nil {
@@ -192,6 +218,7 @@ func TestIsBuiltin(t *testing.T) {
 		"start progress", "update progress", "finish progress",
 		"start timer", "stop timer", "show elapsed time",
 		"compose_cmd", "docker compose command", "docker compose status", "current git branch",
+		"project root", "drun file dir", "home dir", "xdg cache dir",
 	}
 
 	for _, builtin := range builtins {
@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache categories, used as key prefixes so entries can be reported and
+// pruned by what stored them
+const (
+	CategoryIncludes  = "remote"   // remote includes
+	CategoryCatalogs  = "catalog"  // provisioning catalogs
+	CategoryTemplates = "template" // init templates
+)
+
+// CategoryNames describes every category for reports
+var CategoryNames = map[string]string{
+	CategoryIncludes:  "remote includes",
+	CategoryCatalogs:  "provisioning catalogs",
+	CategoryTemplates: "init templates",
+}
+
+// indexKey stores the entry index; SoloDB cannot list its keys
+const indexKey = "drun:index"
+
+// indexLifetime keeps the index record from ever expiring
+const indexLifetime = 100 * 365 * 24 * time.Hour
+
+// Entry describes one cached value
+type Entry struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	StoredAt  time.Time `json:"stored_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Category returns the category of the entry, taken from its key prefix
+func (e Entry) Category() string {
+	category, _, found := strings.Cut(e.Key, ":")
+	if !found {
+		return ""
+	}
+	return category
+}
+
+// Expired reports whether the entry is no longer served at now
+func (e Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// PruneOptions selects the entries removed by Prune. Expired entries are
+// always selected.
+type PruneOptions struct {
+	OlderThan time.Duration // also remove entries stored longer ago than this
+	MaxSize   int64         // then remove the oldest entries until the rest fit
+	DryRun    bool          // only report what would be removed
+	Now       time.Time     // defaults to time.Now()
+}
+
+// GenerateCategoryKey creates a cache key for a URL and optional ref in the
+// given category
+func GenerateCategoryKey(category, url, ref string) string {
+	h := sha256.New()
+	h.Write([]byte(url))
+	if ref != "" {
+		h.Write([]byte(":"))
+		h.Write([]byte(ref))
+	}
+	return category + ":" + hex.EncodeToString(h.Sum(nil))[:32] // Use first 32 chars
+}
+
+// Entries returns the indexed entries, oldest first
+func (m *Manager) Entries() []Entry {
+	if m.disabled || m.db == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.loadIndexLocked(); err != nil {
+		return nil
+	}
+
+	entries := make([]Entry, 0, len(m.index))
+	for _, entry := range m.index {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].StoredAt.Equal(entries[j].StoredAt) {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].StoredAt.Before(entries[j].StoredAt)
+	})
+	return entries
+}
+
+// Prune removes expired entries and the entries selected by opts, then
+// compacts the database. It returns the removed entries.
+func (m *Manager) Prune(opts PruneOptions) ([]Entry, error) {
+	if m.disabled || m.db == nil {
+		return nil, nil
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var removed, kept []Entry
+	var keptSize int64
+	for _, entry := range m.Entries() {
+		if entry.Expired(now) || (opts.OlderThan > 0 && now.Sub(entry.StoredAt) > opts.OlderThan) {
+			removed = append(removed, entry)
+			continue
+		}
+		kept = append(kept, entry)
+		keptSize += entry.Size
+	}
+	for len(kept) > 0 && opts.MaxSize > 0 && keptSize > opts.MaxSize {
+		removed = append(removed, kept[0])
+		keptSize -= kept[0].Size
+		kept = kept[1:]
+	}
+
+	if opts.DryRun || len(removed) == 0 {
+		return removed, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, entry := range removed {
+		if err := m.db.Delete(entry.Key); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", entry.Key, err)
+		}
+		delete(m.index, entry.Key)
+	}
+	if err := m.saveIndexLocked(); err != nil {
+		return nil, err
+	}
+	if err := m.db.Compact(); err != nil {
+		return nil, fmt.Errorf("failed to compact cache: %w", err)
+	}
+	return removed, nil
+}
+
+// recordEntry adds or replaces the index entry of key
+func (m *Manager) recordEntry(key string, size int64, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.loadIndexLocked(); err != nil {
+		return err
+	}
+	m.index[key] = Entry{Key: key, Size: size, StoredAt: time.Now(), ExpiresAt: expiresAt}
+	return m.saveIndexLocked()
+}
+
+// forgetEntry removes the index entry of key
+func (m *Manager) forgetEntry(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.loadIndexLocked(); err != nil {
+		return err
+	}
+	if _, ok := m.index[key]; !ok {
+		return nil
+	}
+	delete(m.index, key)
+	return m.saveIndexLocked()
+}
+
+// loadIndexLocked reads the index on first use
+func (m *Manager) loadIndexLocked() error {
+	if m.index != nil {
+		return nil
+	}
+	m.index = make(map[string]Entry)
+
+	rc, _, _, err := m.db.GetBlob(indexKey)
+	if err != nil {
+		// No index yet: entries stored before it existed are not listed
+		return nil
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("cache index read error: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		// A damaged index only loses the listing; start a new one
+		return nil
+	}
+	for _, entry := range entries {
+		m.index[entry.Key] = entry
+	}
+	return nil
+}
+
+// saveIndexLocked writes the index back to the database
+func (m *Manager) saveIndexLocked() error {
+	entries := make([]Entry, 0, len(m.index))
+	for _, entry := range m.index {
+		entries = append(entries, entry)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("cache index write error: %w", err)
+	}
+	if err := m.db.SetBlob(indexKey, bytes.NewReader(data), int64(len(data)), time.Now().Add(indexLifetime)); err != nil {
+		return fmt.Errorf("cache index write error: %w", err)
+	}
+	return nil
+}
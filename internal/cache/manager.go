@@ -3,12 +3,11 @@ package cache
 
 import (
 	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	solodb "github.com/phillarmonic/SoloDB"
@@ -19,6 +18,9 @@ type Manager struct {
 	db         *solodb.DB
 	expiration time.Duration
 	disabled   bool
+
+	mu    sync.Mutex
+	index map[string]Entry // loaded on first use, see index.go
 }
 
 // Stats provides cache statistics
@@ -39,20 +41,28 @@ func NewManager(expiration time.Duration, disabled bool) (*Manager, error) {
 		}, nil
 	}
 
-	// Get user home directory
+	dbPath, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerAt(dbPath, expiration)
+}
+
+// DefaultPath returns the location of the cache database, ~/.drun/cache.solo
+func DefaultPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
+	return filepath.Join(homeDir, ".drun", "cache.solo"), nil
+}
 
-	// Create ~/.drun directory
-	drunDir := filepath.Join(homeDir, ".drun")
-	if err := os.MkdirAll(drunDir, 0750); err != nil {
-		return nil, fmt.Errorf("failed to create .drun directory: %w", err)
+// NewManagerAt opens the cache database at dbPath
+func NewManagerAt(dbPath string, expiration time.Duration) (*Manager, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(dbPath), err)
 	}
 
-	// Open cache database
-	dbPath := filepath.Join(drunDir, "cache.solo")
 	db, err := solodb.Open(solodb.Options{
 		Path:       dbPath,
 		Durability: solodb.SyncBatch, // Balance between safety and performance
@@ -68,15 +78,14 @@ func NewManager(expiration time.Duration, disabled bool) (*Manager, error) {
 	}, nil
 }
 
-// GenerateKey creates a cache key from a URL and optional ref
+// Expiration returns how long entries stored by the manager stay fresh
+func (m *Manager) Expiration() time.Duration {
+	return m.expiration
+}
+
+// GenerateKey creates a remote include cache key from a URL and optional ref
 func GenerateKey(url, ref string) string {
-	h := sha256.New()
-	h.Write([]byte(url))
-	if ref != "" {
-		h.Write([]byte(":"))
-		h.Write([]byte(ref))
-	}
-	return "remote:" + hex.EncodeToString(h.Sum(nil))[:32] // Use first 32 chars
+	return GenerateCategoryKey(CategoryIncludes, url, ref)
 }
 
 // Get retrieves content from cache
@@ -147,7 +156,7 @@ func (m *Manager) Set(key string, content []byte, expiration time.Duration) erro
 		return fmt.Errorf("cache write error: %w", err)
 	}
 
-	return nil
+	return m.recordEntry(key, int64(len(content)), expiryTime)
 }
 
 // Delete removes a key from cache
@@ -156,7 +165,10 @@ func (m *Manager) Delete(key string) error {
 		return nil
 	}
 
-	return m.db.Delete(key)
+	if err := m.db.Delete(key); err != nil {
+		return err
+	}
+	return m.forgetEntry(key)
 }
 
 // Stats returns cache statistics
@@ -107,10 +107,33 @@ func debugStatement(stmt ast.Statement, indent string) {
 		if len(s.ElseBody) > 0 {
 			fmt.Printf("%s  Else: %d statements\n", indent, len(s.ElseBody))
 		}
+	case *ast.GuardedStatement:
+		fmt.Printf("%sGuarded: %q (unless: %t)\n", indent, s.Condition, s.Negate)
+		debugStatement(s.Statement, indent+"  ")
+	case *ast.CompareStatement:
+		fmt.Printf("%sCompare: %s %q with %q\n", indent, s.Kind, s.Left, s.Right)
+		if len(s.Ignore) > 0 {
+			fmt.Printf("%s  Ignoring: %q\n", indent, s.Ignore)
+		}
+	case *ast.VersionFilesStatement:
+		fmt.Printf("%sVersionFiles: %s %s %q (in sync: %t)\n", indent, s.Operation, s.Level, s.Files, s.InSync)
+	case *ast.ConfirmStatement:
+		fmt.Printf("%sConfirm: %q (timeout: %s, default: %s)\n", indent, s.Question, s.Timeout, s.Default)
+	case *ast.TodoStatement:
+		fmt.Printf("%sTodo: %q\n", indent, s.Message)
+	case *ast.WithinStatement:
+		fmt.Printf("%sWithin: %s (name: %q, fail: %t)\n", indent, s.Budget, s.Name, s.Fail)
+		fmt.Printf("%s  Body: %d statements\n", indent, len(s.Body))
+	case *ast.LockStatement:
+		fmt.Printf("%sLock: %q (backend: %q, wait: %q)\n", indent, s.Name, s.Backend, s.Wait)
+		fmt.Printf("%s  Body: %d statements\n", indent, len(s.Body))
 	case *ast.LoopStatement:
 		fmt.Printf("%sLoop: %s\n", indent, s.Type)
 		fmt.Printf("%s  Variable: %q\n", indent, s.Variable)
 		fmt.Printf("%s  Iterable: %q\n", indent, s.Iterable)
+		if s.Condition != "" {
+			fmt.Printf("%s  Condition: %q\n", indent, s.Condition)
+		}
 		if s.Parallel {
 			fmt.Printf("%s  Parallel: true (workers: %d, fail-fast: %t)\n",
 				indent, s.MaxWorkers, s.FailFast)
@@ -2,6 +2,7 @@ package detection
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"regexp"
@@ -77,7 +78,7 @@ func (d *Detector) IsToolAvailable(tool string) bool {
 	case "aws":
 		available = d.isCommandAvailable("aws")
 	default:
-		available = d.isCommandAvailable(tool)
+		available = d.isCommandAvailable(toolCommand(tool))
 	}
 
 	d.cache[cacheKey] = available
@@ -127,7 +128,7 @@ func (d *Detector) GetToolVersion(tool string) string {
 		version = d.getCommandVersion("npm", "--version", `(\d+\.\d+\.\d+)`)
 	case "yarn":
 		version = d.getCommandVersion("yarn", "--version", `(\d+\.\d+\.\d+)`)
-	case "python":
+	case "python", "python3":
 		version = d.getCommandVersion("python", "--version", `Python (\d+\.\d+\.\d+)`)
 		if version == "" {
 			version = d.getCommandVersion("python3", "--version", `Python (\d+\.\d+\.\d+)`)
@@ -145,11 +146,36 @@ func (d *Detector) GetToolVersion(tool string) string {
 	case "rust", "cargo":
 		version = d.getCommandVersion("cargo", "--version", `cargo (\d+\.\d+\.\d+)`)
 	case "kubectl":
-		version = d.getCommandVersion("kubectl", "version", `GitVersion:"v(\d+\.\d+\.\d+)"`)
+		version = d.getCommandVersionWithArgs("kubectl", []string{"version", "--client"}, `(?:GitVersion:"|Client Version: )v(\d+\.\d+\.\d+)`)
 	case "helm":
 		version = d.getCommandVersion("helm", "version", `Version:"v(\d+\.\d+\.\d+)"`)
 	case "terraform":
 		version = d.getCommandVersion("terraform", "version", `Terraform v(\d+\.\d+\.\d+)`)
+	case "pnpm":
+		version = d.getCommandVersion("pnpm", "--version", `(\d+\.\d+\.\d+)`)
+	case "bun":
+		version = d.getCommandVersion("bun", "--version", `(\d+\.\d+\.\d+)`)
+	case "pip", "pip3":
+		version = d.getCommandVersion("pip", "--version", `pip (\d+\.\d+(?:\.\d+)?)`)
+		if version == "" {
+			version = d.getCommandVersion("pip3", "--version", `pip (\d+\.\d+(?:\.\d+)?)`)
+		}
+	case "maven", "mvn":
+		version = d.getCommandVersion("mvn", "--version", `Apache Maven (\d+\.\d+\.\d+)`)
+	case "gradle":
+		version = d.getCommandVersion("gradle", "--version", `Gradle (\d+\.\d+(?:\.\d+)?)`)
+	case "gem":
+		version = d.getCommandVersion("gem", "--version", `(\d+\.\d+\.\d+)`)
+	case "composer":
+		version = d.getCommandVersion("composer", "--version", `Composer (?:version )?(\d+\.\d+\.\d+)`)
+	case "make":
+		version = d.getCommandVersion("make", "--version", `GNU Make (\d+\.\d+(?:\.\d+)?)`)
+	case "aws":
+		version = d.getCommandVersion("aws", "--version", `aws-cli/(\d+\.\d+\.\d+)`)
+	case "gcp", "gcloud":
+		version = d.getCommandVersion("gcloud", "--version", `Google Cloud SDK (\d+\.\d+\.\d+)`)
+	case "azure", "az":
+		version = d.getCommandVersion("az", "version", `"azure-cli": "(\d+\.\d+\.\d+)"`)
 	default:
 		version = d.getGenericToolVersion(tool)
 	}
@@ -158,6 +184,93 @@ func (d *Detector) GetToolVersion(tool string) string {
 	return version
 }
 
+// ToolPath returns the path of the command that provides a tool, or an empty
+// string when it is not on PATH
+func (d *Detector) ToolPath(tool string) string {
+	cacheKey := "path_" + tool
+	if cached, exists := d.cache[cacheKey]; exists {
+		return cached.(string)
+	}
+
+	path, err := exec.LookPath(toolCommand(tool))
+	if err != nil {
+		switch strings.ToLower(tool) {
+		case "python", "pip":
+			path, err = exec.LookPath(toolCommand(tool) + "3")
+		}
+	}
+	if err != nil {
+		path = ""
+	}
+
+	d.cache[cacheKey] = path
+	return path
+}
+
+// toolCommands maps tool keywords to the command that provides them when the
+// two differ
+var toolCommands = map[string]string{
+	"nodejs":         "node",
+	"golang":         "go",
+	"rust":           "cargo",
+	"maven":          "mvn",
+	"gcp":            "gcloud",
+	"azure":          "az",
+	"docker compose": "docker",
+	"docker buildx":  "docker",
+}
+
+// toolCommand returns the command that provides a tool
+func toolCommand(tool string) string {
+	if command, ok := toolCommands[strings.ToLower(tool)]; ok {
+		return command
+	}
+	return tool
+}
+
+// lockfiles maps lockfiles to the package manager that writes them, in the
+// order they are checked
+var lockfiles = []struct{ file, manager string }{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+	{"yarn.lock", "yarn"},
+	{"package-lock.json", "npm"},
+	{"poetry.lock", "poetry"},
+	{"uv.lock", "uv"},
+	{"Pipfile.lock", "pipenv"},
+	{"Gemfile.lock", "bundler"},
+	{"composer.lock", "composer"},
+	{"Cargo.lock", "cargo"},
+	{"go.sum", "go"},
+}
+
+// DetectPackageManagers returns the package managers whose lockfiles are
+// present, JavaScript managers first
+func (d *Detector) DetectPackageManagers() []string {
+	cacheKey := "package_managers"
+	if cached, exists := d.cache[cacheKey]; exists {
+		return cached.([]string)
+	}
+
+	var managers []string
+	for _, lockfile := range lockfiles {
+		if !d.fileExists(lockfile.file) {
+			continue
+		}
+		found := false
+		for _, manager := range managers {
+			found = found || manager == lockfile.manager
+		}
+		if !found {
+			managers = append(managers, lockfile.manager)
+		}
+	}
+
+	d.cache[cacheKey] = managers
+	return managers
+}
+
 // DetectEnvironment detects the current environment
 func (d *Detector) DetectEnvironment() string {
 	cacheKey := "environment"
@@ -238,7 +351,7 @@ func (d *Detector) DetectProjectType() []string {
 		}
 	}
 
-	if d.fileExists("pom.xml") || d.fileExists("build.gradle") {
+	if d.fileExists("pom.xml") || d.fileExists("build.gradle") || d.fileExists("build.gradle.kts") {
 		types = append(types, "java")
 
 		if d.pomXMLContains("spring") {
@@ -391,22 +504,60 @@ func (d *Detector) fileExists(filename string) bool {
 	return !os.IsNotExist(err)
 }
 
+// packageJSONContains reports whether package.json lists dependency in its
+// dependencies, devDependencies or peerDependencies
 func (d *Detector) packageJSONContains(dependency string) bool {
-	// This is a simplified check - in a real implementation,
-	// you would parse the package.json file and check dependencies
-	return false // Placeholder
+	data, err := os.ReadFile("package.json")
+	if err != nil {
+		return false
+	}
+
+	var manifest struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.PeerDependencies} {
+		if _, ok := deps[dependency]; ok {
+			return true
+		}
+	}
+	return false
 }
 
+// requirementsContains reports whether requirements.txt or pyproject.toml
+// names dependency
 func (d *Detector) requirementsContains(dependency string) bool {
-	// This is a simplified check - in a real implementation,
-	// you would read and parse requirements.txt
-	return false // Placeholder
+	if data, err := os.ReadFile("requirements.txt"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			name := strings.TrimSpace(line)
+			if i := strings.IndexAny(name, "=<>~![; #"); i >= 0 {
+				name = name[:i]
+			}
+			if strings.EqualFold(name, dependency) {
+				return true
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("pyproject.toml"); err == nil {
+		return strings.Contains(strings.ToLower(string(data)), `"`+strings.ToLower(dependency))
+	}
+	return false
 }
 
+// pomXMLContains reports whether pom.xml or build.gradle mentions dependency
 func (d *Detector) pomXMLContains(dependency string) bool {
-	// This is a simplified check - in a real implementation,
-	// you would parse the pom.xml file
-	return false // Placeholder
+	for _, file := range []string{"pom.xml", "build.gradle", "build.gradle.kts"} {
+		if data, err := os.ReadFile(file); err == nil && strings.Contains(strings.ToLower(string(data)), strings.ToLower(dependency)) {
+			return true
+		}
+	}
+	return false
 }
 
 func (d *Detector) parseVersion(version string) []int {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -286,3 +287,61 @@ func TestDetector_IsToolRunningDockerUnavailableDaemon(t *testing.T) {
 		t.Fatalf("expected docker compose running check to fail when daemon is unreachable")
 	}
 }
+
+func TestDetector_DetectPackageManagersFromLockfiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, file := range []string{"yarn.lock", "package-lock.json", "go.sum"} {
+		if err := os.WriteFile(file, nil, 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	managers := NewDetector().DetectPackageManagers()
+	if strings.Join(managers, ",") != "yarn,npm,go" {
+		t.Fatalf("DetectPackageManagers() = %v, want [yarn npm go]", managers)
+	}
+}
+
+func TestDetector_DetectProjectTypeReadsManifests(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"package.json":     `{"dependencies": {"react": "^18.0.0"}, "devDependencies": {"vue": "^3.0.0"}}`,
+		"requirements.txt": "# web\nDjango>=4.2\nrequests==2.31.0\n",
+		"build.gradle.kts": `implementation("org.springframework.boot:spring-boot-starter")`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	types := strings.Join(NewDetector().DetectProjectType(), ",")
+	if types != "node,react,vue,python,django,java,spring" {
+		t.Fatalf("DetectProjectType() = %s", types)
+	}
+}
+
+func TestDetector_ToolPathUsesToolCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on POSIX executable bits")
+	}
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "mvn"), []byte("#!/bin/sh\necho 'Apache Maven 3.9.6'\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake mvn: %v", err)
+	}
+	t.Setenv("PATH", tmpDir)
+
+	detector := NewDetector()
+	if path := detector.ToolPath("maven"); path != filepath.Join(tmpDir, "mvn") {
+		t.Errorf("ToolPath(maven) = %q", path)
+	}
+	if !detector.IsToolAvailable("maven") {
+		t.Error("expected maven to be available through mvn")
+	}
+	if version := detector.GetToolVersion("maven"); version != "3.9.6" {
+		t.Errorf("GetToolVersion(maven) = %q, want 3.9.6", version)
+	}
+	if path := detector.ToolPath("terraform"); path != "" {
+		t.Errorf("ToolPath(terraform) = %q, want empty", path)
+	}
+}
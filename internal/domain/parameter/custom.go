@@ -0,0 +1,93 @@
+package parameter
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Environment is what a custom validator can see of the run that checks a
+// parameter, for checks that depend on it, such as "must be an existing k8s
+// namespace of the current cluster"
+type Environment struct {
+	Task       string            // the task the parameter belongs to
+	WorkingDir string            // the task's working directory
+	Parameters map[string]string // the parameters set so far, by name
+	Env        []string          // the environment the task's commands see, as KEY=value
+}
+
+// CustomValidator checks parameter values against rules drun cannot know.
+// A parameter uses one with `validated by "name"`; the error, if any,
+// explains why the value was rejected.
+type CustomValidator interface {
+	ValidateParameter(name, value string, env Environment) error
+}
+
+// CustomValidatorFunc adapts a function to CustomValidator
+type CustomValidatorFunc func(name, value string, env Environment) error
+
+// ValidateParameter calls f
+func (f CustomValidatorFunc) ValidateParameter(name, value string, env Environment) error {
+	return f(name, value, env)
+}
+
+// ValidatorResolver finds the custom validator of a name that was not
+// registered, such as a validator plugin; false means there is none
+type ValidatorResolver func(name string) (CustomValidator, bool)
+
+// customValidators holds the registered custom validators of a Validator
+type customValidators struct {
+	mu       sync.RWMutex
+	byName   map[string]CustomValidator
+	resolver ValidatorResolver
+}
+
+// Register makes custom available to parameters as `validated by "name"`,
+// replacing a validator registered under the same name
+func (v *Validator) Register(name string, custom CustomValidator) {
+	v.custom.mu.Lock()
+	defer v.custom.mu.Unlock()
+	if v.custom.byName == nil {
+		v.custom.byName = make(map[string]CustomValidator)
+	}
+	v.custom.byName[name] = custom
+}
+
+// SetResolver sets how validators that were not registered are found
+func (v *Validator) SetResolver(resolver ValidatorResolver) {
+	v.custom.mu.Lock()
+	defer v.custom.mu.Unlock()
+	v.custom.resolver = resolver
+}
+
+// customValidator returns the validator named name, registered or resolved
+func (v *Validator) customValidator(name string) (CustomValidator, bool) {
+	v.custom.mu.RLock()
+	defer v.custom.mu.RUnlock()
+	if custom, ok := v.custom.byName[name]; ok {
+		return custom, true
+	}
+	if v.custom.resolver != nil {
+		return v.custom.resolver(name)
+	}
+	return nil, false
+}
+
+// validateCustom checks value with the parameter's custom validator
+func (v *Validator) validateCustom(param *Parameter, value string, env Environment) error {
+	custom, ok := v.customValidator(param.Validator)
+	if !ok {
+		return &ValidationError{
+			Parameter: param.Name,
+			Message:   fmt.Sprintf("unknown validator %q", param.Validator),
+			Value:     value,
+		}
+	}
+	if err := custom.ValidateParameter(param.Name, value, env); err != nil {
+		return &ValidationError{
+			Parameter: param.Name,
+			Message:   err.Error(),
+			Value:     value,
+		}
+	}
+	return nil
+}
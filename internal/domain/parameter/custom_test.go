@@ -0,0 +1,59 @@
+package parameter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/types"
+)
+
+func TestValidator_CustomValidators(t *testing.T) {
+	validator := NewValidator()
+	var seen Environment
+	validator.Register("k8s-namespace", CustomValidatorFunc(func(name, value string, env Environment) error {
+		seen = env
+		if value != "default" && value != "prod" {
+			return errors.New("must be an existing k8s namespace")
+		}
+		return nil
+	}))
+	validator.SetResolver(func(name string) (CustomValidator, bool) {
+		if name != "always-ok" {
+			return nil, false
+		}
+		return CustomValidatorFunc(func(string, string, Environment) error { return nil }), true
+	})
+
+	param := &Parameter{Name: "namespace", DataType: "string", Validator: "k8s-namespace"}
+	env := Environment{Task: "deploy", Parameters: map[string]string{"cluster": "eu"}}
+	if err := validator.ValidateIn(param, mustNewValue(types.StringType, "prod"), env); err != nil {
+		t.Fatalf("expected prod to be valid, got %v", err)
+	}
+	if seen.Task != "deploy" || seen.Parameters["cluster"] != "eu" {
+		t.Errorf("the custom validator saw %+v", seen)
+	}
+
+	err := validator.ValidateIn(param, mustNewValue(types.StringType, "staging"), env)
+	if err == nil || !strings.Contains(err.Error(), "must be an existing k8s namespace (value: 'staging')") {
+		t.Errorf("expected the custom validator to reject staging, got %v", err)
+	}
+
+	// Constraints are checked before the custom validator runs
+	constrained := &Parameter{Name: "namespace", DataType: "string", Constraints: []string{"prod"}, Validator: "k8s-namespace"}
+	err = validator.Validate(constrained, mustNewValue(types.StringType, "default"))
+	if err == nil || !strings.Contains(err.Error(), "must be one of") {
+		t.Errorf("expected the constraint to fail first, got %v", err)
+	}
+
+	resolved := &Parameter{Name: "anything", Validator: "always-ok"}
+	if err := validator.Validate(resolved, mustNewValue(types.StringType, "x")); err != nil {
+		t.Errorf("expected the resolved validator to accept the value, got %v", err)
+	}
+
+	unknown := &Parameter{Name: "anything", Validator: "missing"}
+	err = validator.Validate(unknown, mustNewValue(types.StringType, "x"))
+	if err == nil || !strings.Contains(err.Error(), `unknown validator "missing"`) {
+		t.Errorf("expected an unknown validator error, got %v", err)
+	}
+}
@@ -15,7 +15,9 @@ type Parameter struct {
 	Pattern      string
 	PatternMacro string
 	EmailFormat  bool
+	MustExist    bool // file parameters: the path must name an existing file
 	Variadic     bool
+	Validator    string // name of a custom validator ("validated by")
 }
 
 // NewParameter creates a new parameter
@@ -38,5 +40,6 @@ func (p *Parameter) HasConstraints() bool {
 		p.MaxValue != nil ||
 		p.Pattern != "" ||
 		p.PatternMacro != "" ||
-		p.EmailFormat
+		p.EmailFormat ||
+		p.Validator != ""
 }
@@ -2,6 +2,7 @@ package parameter
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,7 +13,7 @@ import (
 
 // Validator validates parameters
 type Validator struct {
-	// No state needed - patterns package provides functions
+	custom customValidators
 }
 
 // NewValidator creates a new parameter validator
@@ -22,6 +23,12 @@ func NewValidator() *Validator {
 
 // Validate validates a parameter value
 func (v *Validator) Validate(param *Parameter, value *types.Value) error {
+	return v.ValidateIn(param, value, Environment{})
+}
+
+// ValidateIn validates a parameter value; env is passed to the parameter's
+// custom validator
+func (v *Validator) ValidateIn(param *Parameter, value *types.Value, env Environment) error {
 	// Check data type
 	if err := v.validateDataType(param, value); err != nil {
 		return err
@@ -37,6 +44,11 @@ func (v *Validator) Validate(param *Parameter, value *types.Value) error {
 		return err
 	}
 
+	// Check the custom validator last, since it may be slow
+	if param.Validator != "" {
+		return v.validateCustom(param, value.String(), env)
+	}
+
 	return nil
 }
 
@@ -70,6 +82,11 @@ func (v *Validator) validateDataType(param *Parameter, value *types.Value) error
 			}
 		}
 
+	case "file":
+		if param.MustExist {
+			return v.validateFileExists(param, value)
+		}
+
 	case "list":
 		if value.Type != types.ListType {
 			return &ValidationError{
@@ -89,6 +106,26 @@ func (v *Validator) validateDataType(param *Parameter, value *types.Value) error
 	return nil
 }
 
+// validateFileExists validates that a file parameter names an existing file
+func (v *Validator) validateFileExists(param *Parameter, value *types.Value) error {
+	info, err := os.Stat(value.String())
+	if err != nil {
+		return &ValidationError{
+			Parameter: param.Name,
+			Message:   "must be an existing file",
+			Value:     value.String(),
+		}
+	}
+	if info.IsDir() {
+		return &ValidationError{
+			Parameter: param.Name,
+			Message:   "must be a file, not a directory",
+			Value:     value.String(),
+		}
+	}
+	return nil
+}
+
 // validateConstraints validates parameter constraints
 func (v *Validator) validateConstraints(param *Parameter, value *types.Value) error {
 	if len(param.Constraints) == 0 {
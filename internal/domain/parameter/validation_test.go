@@ -1,6 +1,8 @@
 package parameter
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/types"
@@ -237,6 +239,36 @@ func TestValidator_ValidateEmail(t *testing.T) {
 	}
 }
 
+func TestValidator_ValidateFileExists(t *testing.T) {
+	validator := NewValidator()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "values.json")
+	if err := os.WriteFile(file, []byte("{}"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		param   *Parameter
+		value   string
+		wantErr bool
+	}{
+		{"existing file", &Parameter{Name: "config", DataType: "file", MustExist: true}, file, false},
+		{"missing file", &Parameter{Name: "config", DataType: "file", MustExist: true}, filepath.Join(dir, "missing.json"), true},
+		{"directory", &Parameter{Name: "config", DataType: "file", MustExist: true}, dir, true},
+		{"missing file without must exist", &Parameter{Name: "config", DataType: "file"}, filepath.Join(dir, "missing.json"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(tt.param, mustNewValue(types.StringType, tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestParameter_Methods(t *testing.T) {
 	param := &Parameter{
 		Name:     "test",
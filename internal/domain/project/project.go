@@ -14,6 +14,9 @@ type Project struct {
 	Version             string
 	Settings            map[string]string
 	ProvisioningSources []string
+	PathEntries         []string
+	ShellEnvAllow       []string
+	ShellEnvDeny        []string
 	ShellConfigs        map[string]*ShellConfig
 	SetupHooks          []Hook
 	TeardownHooks       []Hook
@@ -54,6 +57,16 @@ func NewProject(stmt *ast.ProjectStatement) (*Project, error) {
 		case *ast.ProvisioningSourcesStatement:
 			project.ProvisioningSources = append(project.ProvisioningSources, s.Sources...)
 
+		case *ast.PathStatement:
+			project.PathEntries = append(project.PathEntries, s.Entries...)
+
+		case *ast.ShellEnvironmentStatement:
+			if s.Mode == "allow" {
+				project.ShellEnvAllow = append(project.ShellEnvAllow, s.Patterns...)
+			} else {
+				project.ShellEnvDeny = append(project.ShellEnvDeny, s.Patterns...)
+			}
+
 		case *ast.LifecycleHook:
 			// Convert hook body from AST to domain
 			body, err := statement.FromASTList(s.Body)
@@ -62,9 +75,10 @@ func NewProject(stmt *ast.ProjectStatement) (*Project, error) {
 			}
 
 			hook := Hook{
-				Type:  s.Type,
-				Scope: s.Scope,
-				Body:  body,
+				Type:   s.Type,
+				Scope:  s.Scope,
+				Target: s.Target,
+				Body:   body,
 			}
 
 			switch s.Type {
@@ -112,7 +126,8 @@ type ShellConfig struct {
 
 // Hook represents a lifecycle hook
 type Hook struct {
-	Type  string // "before", "after", "setup", "teardown"
-	Scope string // "any", "drun"
-	Body  []statement.Statement
+	Type   string // "before", "after", "setup", "teardown"
+	Scope  string // "any", "drun", "task" or "matching"
+	Target string // task name or glob pattern for "task"/"matching" scoped hooks
+	Body   []statement.Statement
 }
@@ -13,6 +13,8 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 		return &Action{
 			ActionType:      s.Action,
 			Message:         s.Message,
+			StepStyle:       s.StepStyle,
+			StepWidth:       s.StepWidth,
 			LineBreakBefore: s.LineBreakBefore,
 			LineBreakAfter:  s.LineBreakAfter,
 		}, nil
@@ -22,13 +24,32 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 			Action:               s.Action,
 			Command:              s.Command,
 			Commands:             s.Commands,
+			PipeInto:             s.PipeInto,
 			CaptureVar:           s.CaptureVar,
 			Attached:             s.Attached,
+			Interactive:          s.Interactive,
 			StreamOutput:         s.StreamOutput,
 			IsMultiline:          s.IsMultiline,
+			Line:                 s.Token.Line,
 			ServiceScoped:        s.ServiceScoped,
 			ServiceName:          s.ServiceName,
 			ServiceNameIsLiteral: s.ServiceNameIsLiteral,
+			Verbosity:            s.Verbosity,
+			Log:                  convertLogTarget(s.Log),
+			ExitCodes:            convertExitCodes(s.ExitCodes),
+			ExitStateVar:         s.ExitStateVar,
+			FailureMessage:       s.FailureMessage,
+			RunAs:                s.RunAs,
+			Host:                 s.Host,
+			HostGroup:            s.HostGroup,
+			HostParallel:         s.HostParallel,
+			HostWorkers:          s.HostWorkers,
+			LineFilters:          convertLineFilters(s.LineFilters),
+		}, nil
+
+	case *ast.LogOutputStatement:
+		return &LogOutput{
+			Target: *convertLogTarget(s.Target),
 		}, nil
 
 	case *ast.VariableStatement:
@@ -42,6 +63,9 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 			Value:     valueStr,
 			Function:  s.Function,
 			Arguments: s.Arguments,
+			Shadow:    s.Shadow,
+
+			LineFilters: convertLineFilters(s.LineFilters),
 		}, nil
 
 	case *ast.ConditionalStatement:
@@ -73,18 +97,33 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 				Value:    s.Filter.Value,
 			}
 		}
+		var pagination *Pagination
+		if s.Pagination != nil {
+			pagination = &Pagination{
+				Strategy:  s.Pagination.Strategy,
+				Name:      s.Pagination.Name,
+				Param:     s.Pagination.Param,
+				ItemsPath: s.Pagination.ItemsPath,
+			}
+		}
 		return &Loop{
-			LoopType:   s.Type,
-			Variable:   s.Variable,
-			Iterable:   s.Iterable,
-			RangeStart: s.RangeStart,
-			RangeEnd:   s.RangeEnd,
-			RangeStep:  s.RangeStep,
-			Filter:     filter,
-			Parallel:   s.Parallel,
-			MaxWorkers: s.MaxWorkers,
-			FailFast:   s.FailFast,
-			Body:       body,
+			LoopType:      s.Type,
+			Variable:      s.Variable,
+			Iterable:      s.Iterable,
+			RangeStart:    s.RangeStart,
+			RangeEnd:      s.RangeEnd,
+			RangeStep:     s.RangeStep,
+			Filter:        filter,
+			Parallel:      s.Parallel,
+			MaxWorkers:    s.MaxWorkers,
+			FailFast:      s.FailFast,
+			Pagination:    pagination,
+			Condition:     s.Condition,
+			MaxIterations: s.MaxIterations,
+			PollInterval:  s.PollInterval,
+			PollTimeout:   s.PollTimeout,
+			ChunkSize:     s.ChunkSize,
+			Body:          body,
 		}, nil
 
 	case *ast.TryStatement:
@@ -137,6 +176,8 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 		return &TaskCall{
 			TaskName:   s.TaskName,
 			Parameters: s.Parameters,
+			Silent:     s.Silent,
+			CaptureVar: s.CaptureVar,
 		}, nil
 
 	case *ast.DockerStatement:
@@ -186,6 +227,16 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 		}, nil
 
 	case *ast.HTTPStatement:
+		var captures []HTTPCapture
+		for _, capture := range s.Captures {
+			captures = append(captures, HTTPCapture{Part: capture.Part, Header: capture.Header, Variable: capture.Variable})
+		}
+		var expectations []HTTPExpectation
+		for _, expectation := range s.Expectations {
+			expectations = append(expectations, HTTPExpectation{
+				Part: expectation.Part, Header: expectation.Header, Operator: expectation.Operator, Values: expectation.Values,
+			})
+		}
 		return &HTTP{
 			Method:  s.Method,
 			URL:     s.URL,
@@ -193,6 +244,13 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 			Body:    s.Body,
 			Auth:    s.Auth,
 			Options: s.Options,
+			Client:  s.Client,
+
+			HeaderOrder: s.HeaderOrder,
+			AuthOrder:   s.AuthOrder,
+
+			Captures:     captures,
+			Expectations: expectations,
 		}, nil
 
 	case *ast.DownloadStatement:
@@ -209,6 +267,8 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 			AllowOverwrite:   s.AllowOverwrite,
 			AllowPermissions: permSpecs,
 			ExtractTo:        s.ExtractTo,
+			StripComponents:  s.StripComponents,
+			Only:             s.Only,
 			RemoveArchive:    s.RemoveArchive,
 			Headers:          s.Headers,
 			Auth:             s.Auth,
@@ -337,6 +397,83 @@ func FromAST(astStmt ast.Statement) (Statement, error) {
 			Path: s.Path,
 		}, nil
 
+	case *ast.NotifyStatement:
+		return &Notify{}, nil
+
+	case *ast.AssertStatement:
+		return &Assert{
+			Condition: s.Condition,
+			Message:   s.Message,
+		}, nil
+
+	case *ast.GuardedStatement:
+		guarded, err := FromAST(s.Statement)
+		if err != nil {
+			return nil, fmt.Errorf("converting guarded statement: %w", err)
+		}
+		return &Guarded{
+			Statement: guarded,
+			Condition: s.Condition,
+			Negate:    s.Negate,
+		}, nil
+
+	case *ast.WithinStatement:
+		body, err := FromASTList(s.Body)
+		if err != nil {
+			return nil, fmt.Errorf("converting within body: %w", err)
+		}
+		return &Within{
+			Budget: s.Budget,
+			Name:   s.Name,
+			Fail:   s.Fail,
+			Body:   body,
+		}, nil
+
+	case *ast.LockStatement:
+		body, err := FromASTList(s.Body)
+		if err != nil {
+			return nil, fmt.Errorf("converting lock body: %w", err)
+		}
+		return &Lock{
+			Name:    s.Name,
+			Backend: s.Backend,
+			Wait:    s.Wait,
+			Body:    body,
+		}, nil
+
+	case *ast.PluginStatement:
+		return &Plugin{
+			Tool: s.Tool,
+			Args: s.Args,
+		}, nil
+
+	case *ast.CompareStatement:
+		return &Compare{
+			Kind:   s.Kind,
+			Left:   s.Left,
+			Right:  s.Right,
+			Ignore: s.Ignore,
+		}, nil
+
+	case *ast.VersionFilesStatement:
+		return &VersionFiles{
+			Operation:  s.Operation,
+			Level:      s.Level,
+			Files:      s.Files,
+			InSync:     s.InSync,
+			CaptureVar: s.CaptureVar,
+		}, nil
+
+	case *ast.ConfirmStatement:
+		return &Confirm{
+			Question: s.Question,
+			Timeout:  s.Timeout,
+			Default:  s.Default,
+		}, nil
+
+	case *ast.TodoStatement:
+		return &Todo{Message: s.Message}, nil
+
 	case *ast.GitPolicyStatement:
 		return &GitPolicy{
 			DefaultBranches:      s.DefaultBranches,
@@ -376,3 +513,37 @@ func FromASTList(astStmts []ast.Statement) ([]Statement, error) {
 	}
 	return result, nil
 }
+
+// convertLogTarget converts an optional AST log target
+func convertLogTarget(target *ast.LogTarget) *LogTarget {
+	if target == nil {
+		return nil
+	}
+	return &LogTarget{
+		Path:   target.Path,
+		Keep:   target.Keep,
+		Append: target.Append,
+	}
+}
+
+func convertLineFilters(filters []ast.LineFilter) []LineFilter {
+	if len(filters) == 0 {
+		return nil
+	}
+	converted := make([]LineFilter, len(filters))
+	for i, filter := range filters {
+		converted[i] = LineFilter{Keep: filter.Keep(), Regexp: filter.Regexp, Pattern: filter.Pattern}
+	}
+	return converted
+}
+
+func convertExitCodes(mappings []ast.ExitCodeMapping) []ExitCodeMapping {
+	if len(mappings) == 0 {
+		return nil
+	}
+	converted := make([]ExitCodeMapping, len(mappings))
+	for i, mapping := range mappings {
+		converted[i] = ExitCodeMapping{Code: mapping.Code, Label: mapping.Label}
+	}
+	return converted
+}
@@ -39,12 +39,25 @@ const (
 	TypeRequiresTools    StatementType = "requires_tools"
 	TypeGitPolicy        StatementType = "git_policy"
 	TypeGitValidate      StatementType = "git_validate"
+	TypeLogOutput        StatementType = "log_output"
+	TypeNotify           StatementType = "notify"
+	TypeAssert           StatementType = "assert"
+	TypeGuarded          StatementType = "guarded"
+	TypeWithin           StatementType = "within"
+	TypePlugin           StatementType = "plugin"
+	TypeCompare          StatementType = "compare"
+	TypeLock             StatementType = "lock"
+	TypeVersionFiles     StatementType = "version_files"
+	TypeConfirm          StatementType = "confirm"
+	TypeTodo             StatementType = "todo"
 )
 
 // Action represents an action statement (info, step, success, etc.)
 type Action struct {
 	ActionType      string
 	Message         string
+	StepStyle       string
+	StepWidth       int
 	LineBreakBefore bool
 	LineBreakAfter  bool
 }
@@ -56,17 +69,70 @@ type Shell struct {
 	Action               string
 	Command              string
 	Commands             []string
+	PipeInto             []string // commands receiving Command's stdout, in order
 	CaptureVar           string
 	Attached             bool
+	Interactive          bool // Attached and requires a terminal
 	StreamOutput         bool
 	IsMultiline          bool
+	Line                 int // line of the statement in its drun file
 	ServiceScoped        bool
 	ServiceName          string
 	ServiceNameIsLiteral bool
+	Verbosity            string            // "quiet", "verbose", or "" (follow global settings)
+	Log                  *LogTarget        // optional tee target for the command output
+	ExitCodes            []ExitCodeMapping // exit codes that are named outcomes, not failures
+	ExitStateVar         string            // receives the mapped outcome
+	FailureMessage       string            // shown with the error when the command fails
+	RunAs                string            // user the command runs as through sudo, such as "root"
+	Host                 string            // host the command runs on over ssh
+	HostGroup            string            // host group the command runs on over ssh
+	HostParallel         bool              // runs on the hosts of the group at once
+	HostWorkers          int               // hosts at a time in parallel; 0 uses the default
+	LineFilters          []LineFilter      // filters of the output lines shown and captured, in order
 }
 
 func (s *Shell) Type() StatementType { return TypeShell }
 
+// ExitCodeMapping names an exit code that is an expected outcome
+type ExitCodeMapping struct {
+	Code  int
+	Label string
+}
+
+// ExitCodeLabel returns the outcome mapped to an exit code, if any
+func (s *Shell) ExitCodeLabel(code int) (string, bool) {
+	for _, mapping := range s.ExitCodes {
+		if mapping.Code == code {
+			return mapping.Label, true
+		}
+	}
+	return "", false
+}
+
+// LineFilter keeps or drops the output lines of a command that contain a
+// text, or match a regular expression when Regexp is set
+type LineFilter struct {
+	Keep    bool
+	Regexp  bool
+	Pattern string
+}
+
+// LogTarget describes a file that receives a copy of shell output.
+// Keep > 0 rotates previous logs to Path.1 .. Path.N; Append keeps existing content.
+type LogTarget struct {
+	Path   string
+	Keep   int
+	Append bool
+}
+
+// LogOutput tees the output of subsequent shell statements in a task to a file.
+type LogOutput struct {
+	Target LogTarget
+}
+
+func (lo *LogOutput) Type() StatementType { return TypeLogOutput }
+
 // Variable represents variable operations (let, set, transform)
 type Variable struct {
 	Operation string
@@ -74,6 +140,9 @@ type Variable struct {
 	Value     string // Interpolated value as string
 	Function  string
 	Arguments []string
+	Shadow    bool // let/set replaces the value of a task parameter
+
+	LineFilters []LineFilter // filters of the captured output lines
 }
 
 func (v *Variable) Type() StatementType { return TypeVariable }
@@ -90,21 +159,35 @@ func (c *Conditional) Type() StatementType { return TypeConditional }
 
 // Loop represents for each loops
 type Loop struct {
-	LoopType   string // "each", "range", "line", "match"
-	Variable   string
-	Iterable   string
-	RangeStart string
-	RangeEnd   string
-	RangeStep  string
-	Filter     *Filter
-	Parallel   bool
-	MaxWorkers int
-	FailFast   bool
-	Body       []Statement
+	LoopType      string // "each", "range", "line", "match", "http", "while"
+	Variable      string
+	Iterable      string
+	RangeStart    string
+	RangeEnd      string
+	RangeStep     string
+	Filter        *Filter
+	Parallel      bool
+	MaxWorkers    int
+	FailFast      bool
+	Pagination    *Pagination
+	Condition     string // while loops
+	MaxIterations int    // while loops; 0 uses the default limit
+	PollInterval  string // poll loops: time between attempts
+	PollTimeout   string // poll loops: time after which polling fails
+	ChunkSize     int    // chunk loops: files per batch
+	Body          []Statement
 }
 
 func (l *Loop) Type() StatementType { return TypeLoop }
 
+// Pagination describes how an "http" loop follows the pages of an API
+type Pagination struct {
+	Strategy  string // "header", "cursor" or "query"
+	Name      string
+	Param     string
+	ItemsPath string
+}
+
 // Filter represents filter conditions in loops
 type Filter struct {
 	Variable string
@@ -154,6 +237,8 @@ func (c *Continue) Type() StatementType { return TypeContinue }
 type TaskCall struct {
 	TaskName   string
 	Parameters map[string]string
+	Silent     bool
+	CaptureVar string
 }
 
 func (tc *TaskCall) Type() StatementType { return TypeTaskCall }
@@ -229,10 +314,33 @@ type HTTP struct {
 	Body    string
 	Auth    map[string]string
 	Options map[string]string
+	Client  string // project HTTP client the request uses; empty when none
+
+	// Keys of Headers and Auth in declaration order
+	HeaderOrder []string
+	AuthOrder   []string
+
+	Captures     []HTTPCapture
+	Expectations []HTTPExpectation
 }
 
 func (h *HTTP) Type() StatementType { return TypeHTTP }
 
+// HTTPCapture stores part of an HTTP response in a variable
+type HTTPCapture struct {
+	Part     string // "status", "body" or "header"
+	Header   string
+	Variable string
+}
+
+// HTTPExpectation asserts part of an HTTP response
+type HTTPExpectation struct {
+	Part     string // "status", "header" or "body"
+	Header   string
+	Operator string // "is", "in", "contains", "matches" or "exists"
+	Values   []string
+}
+
 // PermissionSpec represents a permission specification for downloaded files
 type PermissionSpec struct {
 	Permissions []string
@@ -246,6 +354,8 @@ type Download struct {
 	AllowOverwrite   bool
 	AllowPermissions []PermissionSpec
 	ExtractTo        string
+	StripComponents  int
+	Only             []string
 	RemoveArchive    bool
 	Headers          map[string]string
 	Auth             map[string]string
@@ -296,7 +406,7 @@ func (f *FileValue) Type() StatementType { return TypeFileValue }
 
 // Detection represents tool detection operations
 type Detection struct {
-	DetectionType string // "detect", "detect_available", "if_available", "when_environment", "if_version"
+	DetectionType string // "detect", "detect_available", "detect_environment", "if_available", "when_environment", "if_version"
 	Target        string
 	Alternatives  []string
 	Condition     string
@@ -336,6 +446,95 @@ type ChangeWorkdir struct {
 
 func (cw *ChangeWorkdir) Type() StatementType { return TypeChangeWorkdir }
 
+// Notify requests a desktop notification and a terminal bell when the run
+// finishes (notify me when done).
+type Notify struct{}
+
+func (n *Notify) Type() StatementType { return TypeNotify }
+
+// Assert fails the task when Condition does not hold
+type Assert struct {
+	Condition string
+	Message   string // optional failure message
+}
+
+func (a *Assert) Type() StatementType { return TypeAssert }
+
+// Guarded runs Statement only when Condition holds, or only when it does not
+// hold if Negate is set (only when / unless)
+type Guarded struct {
+	Statement Statement
+	Condition string
+	Negate    bool
+}
+
+func (g *Guarded) Type() StatementType { return TypeGuarded }
+
+// Within is a time-boxed section: Body is expected to finish within Budget
+type Within struct {
+	Budget string // Go duration syntax
+	Name   string
+	Fail   bool // fail instead of warning when over budget
+	Body   []Statement
+}
+
+func (w *Within) Type() StatementType { return TypeWithin }
+
+// Lock runs Body while holding the lock Name in a lock backend
+type Lock struct {
+	Name    string
+	Backend string // lock backend URL; the project's lock backend when empty
+	Wait    string // Go duration syntax; empty waits until the lock is free
+	Body    []Statement
+}
+
+func (l *Lock) Type() StatementType { return TypeLock }
+
+// Plugin delegates a statement to an external drun-x-<Tool> executable
+type Plugin struct {
+	Tool string
+	Args []string
+}
+
+func (p *Plugin) Type() StatementType { return TypePlugin }
+
+// Compare compares two files or directory trees
+type Compare struct {
+	Kind   string // "file" or "dir"
+	Left   string
+	Right  string
+	Ignore []string
+}
+
+func (c *Compare) Type() StatementType { return TypeCompare }
+
+// VersionFiles reads or bumps the version declared by several manifests
+type VersionFiles struct {
+	Operation  string // "get" or "bump"
+	Level      string // "major", "minor" or "patch"
+	Files      []string
+	InSync     bool
+	CaptureVar string
+}
+
+func (v *VersionFiles) Type() StatementType { return TypeVersionFiles }
+
+// Confirm asks a yes/no question before a destructive step
+type Confirm struct {
+	Question string
+	Timeout  string // empty waits for an answer
+	Default  string // "yes", "no" or empty
+}
+
+func (c *Confirm) Type() StatementType { return TypeConfirm }
+
+// Todo marks work that is not written yet
+type Todo struct {
+	Message string
+}
+
+func (t *Todo) Type() StatementType { return TypeTodo }
+
 // VersionConstraint represents a single version constraint (e.g., >= "2.27")
 type VersionConstraint struct {
 	Operator string // ">=", ">", "<=", "<"
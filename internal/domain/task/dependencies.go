@@ -67,6 +67,7 @@ func (dr *DependencyResolver) checkCircular(task *Task, visited, inStack map[str
 			return &TaskError{
 				Task:    task.Name,
 				Message: fmt.Sprintf("circular dependency detected: %s -> %s", task.Name, dep.Name),
+				Kind:    ErrCircularDependency,
 			}
 		}
 	}
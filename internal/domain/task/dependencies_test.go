@@ -1,6 +1,7 @@
 package task
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -76,6 +77,9 @@ func TestDependencyResolver_CircularDependency(t *testing.T) {
 	if err == nil {
 		t.Error("Resolve() should return error for circular dependency")
 	}
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Errorf("expected the error to match ErrCircularDependency, got %v", err)
+	}
 }
 
 func TestDependencyResolver_MissingDependency(t *testing.T) {
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -12,6 +13,7 @@ import (
 type Task struct {
 	Name         string
 	Mode         string
+	Container    string // image the task's shell statements run in
 	Description  string
 	Parameters   []Parameter
 	Dependencies []Dependency
@@ -19,6 +21,13 @@ type Task struct {
 	Namespace    string
 	Source       string // File where task is defined
 	Platforms    []string
+	Default      bool         // marked `default`; runs when xdrun is invoked without a task
+	Sources      []string     // globs of the files the task depends on
+	Once         bool         // declared `once per commit`
+	CPUs         float64      // cpus it needs while it runs (needs 2 cpus)
+	Memory       int64        // bytes of memory it needs while it runs
+	Outputs      []string     // variables it hands to tasks that need them, without the $
+	OutputNeeds  []OutputNeed // outputs of other tasks it reads
 }
 
 // NewTask creates a new task from AST
@@ -32,10 +41,18 @@ func NewTask(stmt *ast.TaskStatement, namespace, source string) (*Task, error) {
 	task := &Task{
 		Name:        stmt.Name,
 		Mode:        stmt.Mode,
+		Container:   stmt.Container,
 		Description: stmt.Description,
 		Namespace:   namespace,
 		Source:      source,
 		Body:        body,
+		Default:     stmt.Default,
+		Sources:     append([]string(nil), stmt.Sources...),
+		Once:        stmt.Once,
+		CPUs:        stmt.CPUs,
+		Memory:      stmt.Memory,
+		Outputs:     append([]string(nil), stmt.Outputs...),
+		OutputNeeds: NewOutputNeeds(stmt.OutputNeeds),
 	}
 
 	meta, err := platform.ValidateAnnotations("task", stmt.Name, stmt.Annotations)
@@ -60,9 +77,26 @@ func NewTask(stmt *ast.TaskStatement, namespace, source string) (*Task, error) {
 		}
 	}
 
+	// A task whose output is needed runs first, like a dependency
+	for _, need := range task.OutputNeeds {
+		if !task.dependsOn(need.Task) {
+			task.Dependencies = append(task.Dependencies, Dependency{Name: need.Task})
+		}
+	}
+
 	return task, nil
 }
 
+// dependsOn reports whether name is a dependency of the task
+func (t *Task) dependsOn(name string) bool {
+	for _, dep := range t.Dependencies {
+		if dep.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // FullName returns the fully qualified task name (with namespace)
 func (t *Task) FullName() string {
 	if t.Namespace == "" {
@@ -140,7 +174,9 @@ type Parameter struct {
 	Pattern      string
 	PatternMacro string
 	EmailFormat  bool
+	MustExist    bool
 	Variadic     bool
+	Validator    string // custom validator name ("validated by")
 }
 
 // NewParameter creates a parameter from AST
@@ -158,7 +194,9 @@ func NewParameter(stmt *ast.ParameterStatement) Parameter {
 		Pattern:      stmt.Pattern,
 		PatternMacro: stmt.PatternMacro,
 		EmailFormat:  stmt.EmailFormat,
+		MustExist:    stmt.MustExist,
 		Variadic:     stmt.Variadic,
+		Validator:    stmt.Validator,
 	}
 }
 
@@ -177,11 +215,39 @@ type Dependency struct {
 	Sequential bool
 }
 
+// OutputNeed is a data dependency on an output of another task, which the
+// task reads as the variable As
+type OutputNeed struct {
+	Output string
+	Task   string
+	As     string // without the $; the output's name unless renamed
+}
+
+// NewOutputNeeds converts the output needs of a task from AST
+func NewOutputNeeds(needs []ast.OutputNeed) []OutputNeed {
+	if len(needs) == 0 {
+		return nil
+	}
+	converted := make([]OutputNeed, len(needs))
+	for i, need := range needs {
+		converted[i] = OutputNeed{Output: need.Output, Task: need.Task, As: need.As}
+		if converted[i].As == "" {
+			converted[i].As = need.Output
+		}
+	}
+	return converted
+}
+
+// ErrCircularDependency is matched by errors.Is for errors reporting a
+// dependency cycle
+var ErrCircularDependency = errors.New("circular dependency")
+
 // TaskError represents a task-related error
 type TaskError struct {
 	Task    string
 	Message string
 	Cause   error
+	Kind    error // sentinel the error matches with errors.Is, such as ErrCircularDependency
 }
 
 func (e *TaskError) Error() string {
@@ -194,3 +260,7 @@ func (e *TaskError) Error() string {
 func (e *TaskError) Unwrap() error {
 	return e.Cause
 }
+
+func (e *TaskError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -53,3 +54,65 @@ task "fuzz":
 		t.Fatalf("unexpected step box output with line breaks:\n%s", got)
 	}
 }
+
+func TestStepActionStyleAndWidth(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  step "Deploying" with style "banner" width 15
+  step "デプロイ" with style "underline"
+  step "部署" with width 10`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "deploy", &buf); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	expected := "" +
+		"═══════════════\n" +
+		"   Deploying\n" +
+		"═══════════════\n" +
+		"デプロイ\n" +
+		"────────\n" +
+		"┌────────┐\n" +
+		"│ 部署   │\n" +
+		"└────────┘\n"
+
+	if got := buf.String(); got != expected {
+		t.Fatalf("unexpected styled step output:\n%s", got)
+	}
+}
+
+func TestStepActionProjectStepStyle(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  set step style to "minimal"
+
+task "deploy":
+  step "Deploying"
+  step "Done" with style "box"`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "deploy", &buf); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	expected := "▸ Deploying\n┌──────┐\n│ Done │\n└──────┘\n"
+	if got := buf.String(); got != expected {
+		t.Fatalf("unexpected project step style output:\n%s", got)
+	}
+}
+
+func TestStepActionUnknownStyleFails(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  step "Deploying" with style "fancy"`
+
+	var buf bytes.Buffer
+	err := ExecuteString(input, "deploy", &buf)
+	if err == nil || !strings.Contains(err.Error(), `unknown step style "fancy"`) {
+		t.Fatalf("expected unknown step style error, got %v", err)
+	}
+}
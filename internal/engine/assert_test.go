@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAssertFailsTheTaskWithItsMessage(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  given $replicas as number defaults to "0"
+  assert {replicas} > 0 otherwise "replicas must be positive, got {replicas}"
+  info "deploying"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	err = eng.ExecuteWithParams(program, "deploy", map[string]string{"replicas": "0"})
+	if err == nil || !strings.Contains(err.Error(), "assertion failed: replicas must be positive, got 0 ({replicas} > 0)") {
+		t.Fatalf("expected the assertion to fail the task, got %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	if strings.Contains(output, "deploying") {
+		t.Errorf("the task must stop at the failed assertion:\n%s", output)
+	}
+	if !strings.Contains(output, "Failed assertions (1):\n  deploy: replicas must be positive, got 0\n") {
+		t.Errorf("expected the failed assertion in the summary:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := eng.ExecuteWithParams(program, "deploy", map[string]string{"replicas": "3"}); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "deploying") || strings.Contains(buf.String(), "Failed assertions") {
+		t.Errorf("expected a passing assertion to continue silently:\n%s", buf.String())
+	}
+}
+
+func TestAssertFailuresCaughtByTryAreStillSummarized(t *testing.T) {
+	input := `version: 2.0
+
+task "check":
+  try:
+    assert folder "does-not-exist" exists
+  catch:
+    warn "continuing without the folder"
+  info "done"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "check"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Failed assertions (1):\n  check: folder does-not-exist exists\n") {
+		t.Errorf("expected the caught assertion in the summary:\n%s", buf.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -18,6 +19,8 @@ type BuiltinContext struct {
 	execCtx        *ExecutionContext
 	secretsManager SecretsManager
 	dryRun         bool
+	drunVersion    string
+	stdin          *stdinInput
 }
 
 // GetProjectName returns the current project name
@@ -43,6 +46,68 @@ func (bc *BuiltinContext) IsDryRun() bool {
 	return bc.dryRun
 }
 
+// GetDrunFileDir returns the absolute directory of the drun file being executed
+func (bc *BuiltinContext) GetDrunFileDir() string {
+	if bc.execCtx == nil || bc.execCtx.CurrentFile == "" {
+		return ""
+	}
+	return filepath.Dir(absPath(bc.execCtx.CurrentFile))
+}
+
+// GetProjectRoot returns the directory relative file paths resolve from
+func (bc *BuiltinContext) GetProjectRoot() string {
+	if bc.execCtx == nil || bc.execCtx.CurrentFile == "" {
+		return ""
+	}
+	return projectRootDir(absPath(bc.execCtx.CurrentFile))
+}
+
+// GetTaskElapsed returns how long the current task has been running
+func (bc *BuiltinContext) GetTaskElapsed() (string, bool) {
+	if bc.execCtx == nil || bc.execCtx.TaskStarted.IsZero() {
+		return "", false
+	}
+	return formatElapsed(time.Since(bc.execCtx.TaskStarted)), true
+}
+
+// GetRunInfo returns the id and start time of the current run
+func (bc *BuiltinContext) GetRunInfo() (string, time.Time, bool) {
+	if bc.execCtx == nil || bc.execCtx.Run == nil {
+		return "", time.Time{}, false
+	}
+	return bc.execCtx.Run.id, bc.execCtx.Run.startedAt, true
+}
+
+// GetCurrentTask returns the name and drun file of the running task
+func (bc *BuiltinContext) GetCurrentTask() (name, file string, ok bool) {
+	if bc.execCtx == nil || bc.execCtx.CurrentTask == "" {
+		return "", "", false
+	}
+	if bc.execCtx.CurrentFile != "" {
+		file = absPath(bc.execCtx.CurrentFile)
+	}
+	return bc.execCtx.CurrentTask, file, true
+}
+
+// GetDrunVersion returns the version of the running drun binary
+func (bc *BuiltinContext) GetDrunVersion() string {
+	return bc.drunVersion
+}
+
+// GetStdin returns the content piped into drun
+func (bc *BuiltinContext) GetStdin() (string, error) {
+	return bc.stdin.text()
+}
+
+// GetRetriesUsed returns how many retries the run has taken
+func (bc *BuiltinContext) GetRetriesUsed() (int, bool) {
+	if bc.execCtx == nil || bc.execCtx.Retries == nil {
+		return 0, false
+	}
+	used, _ := bc.execCtx.Retries.usage()
+	return used, true
+}
+
 // GetTaskNames returns all user-defined tasks available to the current
 // execution. Local tasks retain declaration order; included task names are
 // appended in lexical order because their backing store is a map.
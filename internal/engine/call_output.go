@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Call Output
+// This file implements `call task "x" silently` and
+// `call task "x" capturing output as $log`, which keep the output of a
+// called task out of the caller's. The called task's execution context
+// writes into a buffer of its own while it runs, so other tasks and loop
+// iterations keep writing to the engine output; a failing task still shows
+// what it wrote.
+
+// callOutput collects the output of a called task. Parallel loops of the
+// task write to it from several goroutines.
+type callOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (c *callOutput) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+func (c *callOutput) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+// runCalledTask runs a called task and returns its output when the call is
+// silent or captures it, keeping the output out of the caller's. A failing
+// task's output is written after all, since it explains the failure.
+func (e *Engine) runCalledTask(callStmt *statement.TaskCall, targetTask *ast.TaskStatement, callCtx *ExecutionContext) (string, error) {
+	if !callStmt.Silent && callStmt.CaptureVar == "" {
+		return "", e.executeTask(targetTask, callCtx)
+	}
+
+	callerOutput := callCtx.Output
+	captured := &callOutput{}
+	callCtx.Output = captured
+	err := e.executeTask(targetTask, callCtx)
+	callCtx.Output = callerOutput
+
+	if err != nil {
+		_, _ = io.WriteString(e.out(callCtx), captured.String())
+	}
+	return strings.TrimRight(captured.String(), "\n"), err
+}
+
+// describeCallOutput returns how a call handles the called task's output,
+// for dry runs
+func describeCallOutput(callStmt *statement.TaskCall) string {
+	switch {
+	case callStmt.CaptureVar != "":
+		return fmt.Sprintf(" capturing its output as $%s", callStmt.CaptureVar)
+	case callStmt.Silent:
+		return " silently"
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCaptureLinesStoresAListForLoops(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses printf from a POSIX shell")
+	}
+
+	input := `version: 2.0
+
+task "pods":
+  capture lines of "printf 'pod/api\n\n  pod/web  \npod/\"quoted\"\n'" as $pods
+  for each $pod in $pods:
+    info "pod=[{$pod}]"
+  capture lines of "printf 'a\n\n  b\n'" as $raw keeping empty lines without trimming
+  for each $line in $raw:
+    info "raw=[{$line}]"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "pods"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	var got []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if _, item, ok := strings.Cut(line, "=["); ok {
+			got = append(got, strings.TrimSuffix(item, "]"))
+		}
+	}
+	want := []string{"pod/api", "pod/web", `pod/"quoted"`, "a", "", "  b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loop items = %q, want %q\n%s", got, want, buf.String())
+	}
+}
+
+func TestCapturedLinesRoundTripThroughListLiterals(t *testing.T) {
+	lines := splitCapturedLines("one\r\n two \r\n\r\nback\\slash \"q\"\r\n", []string{"keep_empty", "no_trim"})
+	want := []string{"one", " two ", "", `back\slash "q"`}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("splitCapturedLines() = %q, want %q", lines, want)
+	}
+
+	eng := NewEngine(&bytes.Buffer{})
+	if got := eng.parseArrayLiteralString(formatListLiteral(lines)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseArrayLiteralString(formatListLiteral()) = %q, want %q", got, want)
+	}
+	if got := splitCapturedLines("", nil); len(got) != 0 {
+		t.Errorf("expected no lines for empty output, got %q", got)
+	}
+}
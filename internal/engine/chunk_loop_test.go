@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChunkLoopBatchesGlobMatches(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"001.sql", "002.sql", "003.sql", "004.sql", "005 init.sql", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested.sql"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `version: 2.0
+
+task "migrate":
+  for each chunk of 2 files in "` + filepath.ToSlash(dir) + `/*.sql":
+    info "batch {$chunk} args {$chunk_files}"
+    for each $file in $chunk:
+      info "file {$file}"
+  for each chunk of 10 files in "` + filepath.ToSlash(dir) + `/*.none":
+    info "never"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "migrate"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+
+	if got := strings.Count(output, "batch ["); got != 3 {
+		t.Errorf("expected 3 batches of at most 2 files, got %d:\n%s", got, output)
+	}
+	if got := strings.Count(output, "file "+dir); got != 5 {
+		t.Errorf("expected each of the 5 .sql files once, got %d:\n%s", got, output)
+	}
+	if !strings.Contains(output, "args '"+filepath.Join(dir, "005 init.sql")+"'") {
+		t.Errorf("expected file arguments to be shell-quoted:\n%s", output)
+	}
+	if strings.Contains(output, "nested.sql") || strings.Contains(output, "never") {
+		t.Errorf("expected directories and empty globs to be skipped:\n%s", output)
+	}
+}
+
+func TestChunkLoopResolvesRelativeGlobsFromTheWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "migrations"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.sql", "b.sql", "c.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, "migrations", name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input := `version: 2.0
+
+task "migrate":
+  use workdir "` + filepath.ToSlash(dir) + `"
+  for each chunk $batch of 2 files in "migrations/*.sql":
+    info "batch {$batch}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "migrate"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	if !strings.Contains(output, `batch ["migrations/a.sql", "migrations/b.sql"]`) || !strings.Contains(output, `batch ["migrations/c.sql"]`) {
+		t.Errorf("expected batches relative to the working directory:\n%s", output)
+	}
+}
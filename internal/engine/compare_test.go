@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffMatchesDiffU(t *testing.T) {
+	var left, right []string
+	for i := 1; i <= 20; i++ {
+		left = append(left, strconv.Itoa(i))
+		switch i {
+		case 2:
+			right = append(right, "two")
+		case 15:
+			right = append(right, "fifteen")
+		case 18:
+		default:
+			right = append(right, strconv.Itoa(i))
+		}
+	}
+
+	want := []string{
+		"--- a.txt", "+++ b.txt",
+		"@@ -1,5 +1,5 @@", " 1", "-2", "+two", " 3", " 4", " 5",
+		"@@ -12,9 +12,8 @@", " 12", " 13", " 14", "-15", "+fifteen", " 16", " 17", "-18", " 19", " 20",
+	}
+	if got := unifiedDiff(left, right, "a.txt", "b.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := unifiedDiff(nil, []string{"x", "y"}, "a", "b"); !reflect.DeepEqual(got, []string{"--- a", "+++ b", "@@ -0,0 +1,2 @@", "+x", "+y"}) {
+		t.Errorf("unifiedDiff() of an empty file = %q", got)
+	}
+}
+
+func TestCompareStatementsReportDifferences(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	dir := t.TempDir()
+	files := map[string]string{
+		"expected.json":       "{\n  \"name\": \"api\",\n  \"replicas\": 2\n}\n",
+		"actual.json":         "{\n  \"name\": \"api\",\n  \"replicas\": 3\n}\n",
+		"golden/a.txt":        "same\n",
+		"golden/sub/b.txt":    "old\n",
+		"golden/gone.txt":     "x\n",
+		"out/a.txt":           "same\n",
+		"out/sub/b.txt":       "new\n",
+		"out/run.log":         "noise\n",
+		"out/tmp/scratch.txt": "noise\n",
+		"out/image.bin":       "\x00\x01",
+		"golden/image.bin":    "\x00\x02",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input := `version: 2.0
+
+task "verify":
+  use workdir "` + dir + `"
+  compare file "expected.json" with "actual.json"
+  info "files identical: {comparison.identical}"
+  compare dir "golden" with "out" ignoring "*.log", "tmp"
+  info "dirs identical: {comparison.identical}"
+  compare dir "golden/sub" with "golden/sub"
+  info "same identical: {comparison.identical}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "verify"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"expected.json and actual.json differ",
+		"@@ -1,4 +1,4 @@\n {\n   \"name\": \"api\",\n-  \"replicas\": 2\n+  \"replicas\": 3\n }\n",
+		"files identical: false",
+		"Only in golden: gone.txt\nBinary files golden/image.bin and out/image.bin differ\n--- golden/sub/b.txt\n+++ out/sub/b.txt\n@@ -1 +1 @@\n-old\n+new\n",
+		"dirs identical: false",
+		"golden/sub and golden/sub are identical",
+		"same identical: true",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "run.log") || strings.Contains(output, "scratch.txt") {
+		t.Errorf("ignored entries were compared:\n%s", output)
+	}
+}
+
+func TestCompareFailsForMissingOrMismatchedPaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for statement, want := range map[string]string{
+		`compare file "a.txt" with "missing.txt"`: "reading missing.txt",
+		`compare file "." with "a.txt"`:           "is a directory; use compare dir",
+		`compare dir "a.txt" with "."`:            "is not a directory; use compare file",
+	} {
+		program, err := ParseString("version: 2.0\n\ntask \"verify\":\n  use workdir \"" + dir + "\"\n  " + statement + "\n")
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		err = NewEngine(&bytes.Buffer{}).Execute(program, "verify")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", statement, want, err)
+		}
+	}
+}
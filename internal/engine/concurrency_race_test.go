@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+)
+
+const concurrentProgram = `version: 2.0
+
+project "app":
+  set region to "eu"
+
+task "prepare":
+  set $ready to "yes"
+
+task "build" means "Build":
+  depends on prepare
+  given $name defaults to "api"
+  set $out to "{$name}-{region}"
+  for each $i in ["1", "2", "3"] in parallel:
+    info "build {$i} {$out}"
+  info "built {$out}"
+
+task "test" means "Test":
+  depends on prepare
+  for each $suite in ["unit", "e2e"]:
+    info "testing {$suite}"
+`
+
+// These tests share one engine, and its unsynchronized output buffer, between
+// goroutines; run them with -race
+
+func TestEngineExecutesTasksConcurrently(t *testing.T) {
+	program, err := ParseString(concurrentProgram)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		for _, task := range []string{"build", "test"} {
+			wg.Add(1)
+			go func(task string, i int) {
+				defer wg.Done()
+				params := map[string]string{}
+				if task == "build" {
+					params["name"] = fmt.Sprintf("svc%d", i)
+				}
+				if err := eng.ExecuteWithParams(program, task, params); err != nil {
+					errs <- fmt.Errorf("%s: %w", task, err)
+				}
+			}(task, i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestEngineExecutesParallelTargetsConcurrently(t *testing.T) {
+	program, err := ParseString(concurrentProgram)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			targets := []TaskTarget{
+				{Name: "build", Params: map[string]string{"name": fmt.Sprintf("svc%d", i)}},
+				{Name: "test"},
+			}
+			if err := eng.ExecuteTargets(program, targets, "", true); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if want := fmt.Sprintf("built svc%d-eu", i); !bytes.Contains(out.Bytes(), []byte(want)) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConcurrentProjectsKeepTheirOutputStyleAndCredentials(t *testing.T) {
+	t.Setenv("DRUN_TEST_RACE_CREDENTIAL", "r4ce-token")
+	plain, err := ParseString(`version: 2.0
+
+project "plain":
+  set output style to "plain"
+  set credential helper for "api.example.com" to "env:DRUN_TEST_RACE_CREDENTIAL"
+
+task "sync":
+  get "https://api.example.com/v1/items"
+  for each $i in ["1", "2", "3"] in parallel:
+    info "plain {$i} r4ce-token"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	emoji, err := ParseString(`version: 2.0
+
+project "emoji":
+  set language to "pt-BR"
+
+task "sync":
+  for each $i in ["1", "2", "3"] in parallel:
+    info "emoji {$i}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		for _, program := range []*ast.Program{plain, emoji} {
+			wg.Add(1)
+			go func(program *ast.Program) {
+				defer wg.Done()
+				if err := eng.Execute(program, "sync"); err != nil {
+					errs <- err
+				}
+			}(program)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for _, line := range strings.Split(out.String(), "\n") {
+		switch {
+		case strings.Contains(line, "plain "):
+			if !strings.HasPrefix(line, "[INFO] ") || !strings.HasSuffix(line, " ***") {
+				t.Errorf("expected a plain, masked line, got %q", line)
+			}
+		case strings.Contains(line, "emoji "):
+			if !strings.HasPrefix(line, "ℹ️  ") {
+				t.Errorf("expected an emoji line, got %q", line)
+			}
+		}
+	}
+	if strings.Contains(out.String(), "r4ce-token") {
+		t.Errorf("credential leaked into the output:\n%s", out.String())
+	}
+}
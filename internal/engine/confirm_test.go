@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// answerConfirmsOnTerminal makes confirm prompts see a terminal or not
+func answerConfirmsOnTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+	originalTerminal := stdinIsTerminal
+	stdinIsTerminal = func() bool { return terminal }
+	t.Cleanup(func() { stdinIsTerminal = originalTerminal })
+}
+
+func runConfirmTask(t *testing.T, input io.Reader, body string) (string, error) {
+	t.Helper()
+	program, err := ParseString("version: 2.0\n\ntask \"reset\":\n" + body + "  info \"database deleted\"\n")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngineWithOptions(WithOutput(&buf), WithConfirmInput(input)).Execute(program, "reset")
+	return buf.String(), err
+}
+
+func TestConfirmAnswers(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		statement string
+		confirmed bool
+	}{
+		{"yes", "yes\n", `confirm "Delete prod database?"`, true},
+		{"no", "n\n", `confirm "Delete prod database?" defaulting to yes`, false},
+		{"empty takes the default", "\n", `confirm "Delete prod database?" defaulting to yes`, true},
+		{"empty without a default is no", "\n", `confirm "Delete prod database?"`, false},
+		{"asks again", "maybe\nY\n", `confirm "Delete prod database?"`, true},
+		{"end of input takes the default", "", `confirm "Delete prod database?" defaulting to no`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answerConfirmsOnTerminal(t, true)
+			out, err := runConfirmTask(t, strings.NewReader(tt.input), "  "+tt.statement+"\n")
+			if tt.confirmed != (err == nil) {
+				t.Fatalf("confirmed = %v, want %v (err %v)\n%s", err == nil, tt.confirmed, err, out)
+			}
+			if !tt.confirmed && !strings.Contains(err.Error(), "not confirmed: Delete prod database?") {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if strings.Contains(out, "database deleted") != tt.confirmed {
+				t.Errorf("the task should only continue when confirmed:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestConfirmTimeoutTakesTheDefault(t *testing.T) {
+	reader, writer := io.Pipe()
+	t.Cleanup(func() { _ = writer.Close() })
+	answerConfirmsOnTerminal(t, true)
+
+	out, err := runConfirmTask(t, reader, "  confirm \"Delete prod database?\" within \"50ms\" defaulting to no\n")
+	if err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Fatalf("expected the default no to stop the task, got %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Delete prod database? [y/N, no in 50ms]",
+		`No answer to "Delete prod database?" within 50ms; taking the default: no`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	out, err = runConfirmTask(t, reader, "  confirm \"Delete prod database?\" within \"50ms\" defaulting to yes\n")
+	if err != nil || !strings.Contains(out, "database deleted") {
+		t.Fatalf("expected the default yes to continue, got %v\n%s", err, out)
+	}
+}
+
+func TestConfirmLateAnswerGoesToTheNextPrompt(t *testing.T) {
+	reader, writer := io.Pipe()
+	t.Cleanup(func() { _ = writer.Close() })
+	answerConfirmsOnTerminal(t, true)
+	time.AfterFunc(200*time.Millisecond, func() { _, _ = io.WriteString(writer, "no\n") })
+
+	out, err := runConfirmTask(t, reader, "  confirm \"Stop the service?\" within \"50ms\" defaulting to yes\n  confirm \"Delete prod database?\"\n")
+	if err == nil || !strings.Contains(err.Error(), "not confirmed: Delete prod database?") {
+		t.Fatalf("expected the late answer to stop the task at the next prompt, got %v\n%s", err, out)
+	}
+
+	read := make(chan struct{})
+	go func() {
+		_, _ = io.WriteString(writer, "input for the next command\n")
+		close(read)
+	}()
+	select {
+	case <-read:
+		t.Fatal("the confirm reader kept reading after the last prompt")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConfirmWithoutTerminal(t *testing.T) {
+	answerConfirmsOnTerminal(t, false)
+
+	out, err := runConfirmTask(t, strings.NewReader("yes\n"), "  confirm \"Delete prod database?\" defaulting to yes\n")
+	if err != nil || !strings.Contains(out, `No terminal to answer "Delete prod database?"; taking the default: yes`) {
+		t.Fatalf("expected the default to be taken and logged, got %v\n%s", err, out)
+	}
+
+	_, err = runConfirmTask(t, strings.NewReader("yes\n"), "  confirm \"Delete prod database?\"\n")
+	if err == nil || !strings.Contains(err.Error(), "no terminal to answer on") {
+		t.Fatalf("expected an error without a default, got %v", err)
+	}
+}
+
+func TestConfirmDryRun(t *testing.T) {
+	answerConfirmsOnTerminal(t, false)
+	program, err := ParseString("version: 2.0\n\ntask \"reset\":\n  confirm \"Delete prod database?\" within 30s defaulting to no\n")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	eng.SetDryRun(true)
+	if err := eng.Execute(program, "reset"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "[DRY RUN] Would ask: Delete prod database? [y/N, no in 30s]") {
+		t.Errorf("unexpected dry-run output:\n%s", buf.String())
+	}
+}
@@ -1,9 +1,14 @@
 package engine
 
 import (
+	"io"
+	"sync/atomic"
+	"time"
+
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/engine/hooks"
+	"github.com/phillarmonic/drun/v2/internal/engine/includes"
 	"github.com/phillarmonic/drun/v2/internal/engine/interpolation"
 	"github.com/phillarmonic/drun/v2/internal/types"
 )
@@ -20,6 +25,49 @@ type ExecutionContext struct {
 	Program            *ast.Program            // the AST program being executed
 	WorkingDir         string                  // override working directory for shell commands (empty = use process cwd)
 	OriginalWorkingDir string                  // the cwd captured at task start; relative paths are resolved from here
+	TaskLogFile        string                  // file receiving shell output for the current task (log output to), empty = none
+	Container          string                  // image the current task's shell statements run in (runs in container), empty = host
+	Monitor            *MemoryMonitor          // memory monitor of this execution; loops register with it
+	NotifyWhenDone     *atomic.Bool            // set by `notify me when done`; shared by every context of this execution
+	TaskStarted        time.Time               // when the current task started; {task.elapsed} measures from it
+	Timings            *taskTimings            // how long each task of this execution took; shared like NotifyWhenDone
+	Assertions         *failedAssertions       // assertions that failed in this execution; shared like Timings
+	Retries            *retryBudget            // retries taken in this execution (set retry budget); shared like Timings
+	Deadline           *runDeadline            // when this execution must finish by (--max-duration); nil when unlimited
+	Run                *runInfo                // id and start time of this execution ({run.id}); shared like Timings
+	LoopItems          *loopItems              // failed items of the parallel loops that ran (cmd:rerun); shared like Timings
+	Outputs            *taskOutputs            // declared outputs of the tasks that finished (outputs "x"); shared like Timings
+	Resources          *resourceNeeds          // cpus and memory reserved by the running task (needs 2 cpus); nil when none
+	Output             io.Writer               // where the running task writes; a buffer for calls that keep their task's output (call task silently)
+	Style              *outputStyle            // theme and language of this execution's messages; shared like Timings
+}
+
+// inheritExecution copies what identifies the running task and execution
+// from parent into a loop context, leaving parameters and variables alone
+func (ctx *ExecutionContext) inheritExecution(parent *ExecutionContext) {
+	ctx.Project = parent.Project
+	ctx.CurrentFile = parent.CurrentFile
+	ctx.CurrentTask = parent.CurrentTask
+	ctx.CurrentTaskMode = parent.CurrentTaskMode
+	ctx.CurrentNamespace = parent.CurrentNamespace
+	ctx.Program = parent.Program
+	ctx.WorkingDir = parent.WorkingDir
+	ctx.OriginalWorkingDir = parent.OriginalWorkingDir
+	ctx.TaskLogFile = parent.TaskLogFile
+	ctx.Container = parent.Container
+	ctx.Monitor = parent.Monitor
+	ctx.NotifyWhenDone = parent.NotifyWhenDone
+	ctx.TaskStarted = parent.TaskStarted
+	ctx.Timings = parent.Timings
+	ctx.Assertions = parent.Assertions
+	ctx.Retries = parent.Retries
+	ctx.Deadline = parent.Deadline
+	ctx.Run = parent.Run
+	ctx.LoopItems = parent.LoopItems
+	ctx.Outputs = parent.Outputs
+	ctx.Resources = parent.Resources
+	ctx.Output = parent.Output
+	ctx.Style = parent.Style
 }
 
 // Implement interpolation.Context interface
@@ -73,11 +121,41 @@ type ProjectContext struct {
 	IncludedSettings     map[string]string                         // namespaced settings: "docker.api_url" - accessible via $globals.docker.api_url
 	IncludedParams       map[string]*ast.ProjectParameterStatement // namespaced parameters: "docker.registry" - accessible via $params.docker.registry
 	IncludedFiles        map[string]bool                           // track included files to prevent circular includes
+	IncludedOrigins      map[string]includes.Origin                // "task:docker.deploy" -> where it was declared, for conflict diagnostics
+	IncludedMembers      map[string]string                         // "api" -> "services/api", the workspace members included by namespace
+	EncryptedSettings    map[string]bool                           // keys of Settings and IncludedSettings set with 'set encrypted'
+	DecryptError         error                                     // why an encrypted setting could not be decrypted; reported when tasks run
 	RequiredTools        []statement.ToolRequirement               // project-level required tools
 	RequiredToolTaskRefs []string                                  // project-level task refs for inherited required tools
 	ProvisioningSources  []string                                  // ordered project-level provisioning catalogs
 	GitPolicy            *statement.GitPolicy                      // project-level git policy
 	SCMRegistry          *ast.SCMRegistryStatement                 // project-level technology-oriented SCM registry
+	PathEntries          []string                                  // project-local PATH entries (absolute), searched before the inherited PATH
+	ShellEnvAllow        []string                                  // inherited variables shell commands receive (shell environment allow); empty inherits all
+	ShellEnvDeny         []string                                  // inherited variables never passed to shell commands (shell environment deny)
+	CredentialHelpers    map[string]string                         // host -> credential helper ("exec:gh auth token")
+	HostGroups           map[string][]string                       // host group -> hosts run statements reach over ssh
+	NamespaceDefaults    []*ast.NamespaceDefaultsStatement         // parameter defaults for included tasks, in declaration order
+	Conditions           map[string]string                         // named conditions ("is prod") -> their expressions
+	HTTPClients          map[string]*ast.HTTPClientStatement       // named HTTP clients (http client "api") HTTP statements use
+}
+
+// namespaceDefault returns the project's default for parameter param of the
+// included task taskName in namespace; later declarations win
+func (pc *ProjectContext) namespaceDefault(namespace, taskName, param string) (string, bool) {
+	if pc == nil {
+		return "", false
+	}
+	for i := len(pc.NamespaceDefaults) - 1; i >= 0; i-- {
+		defaults := pc.NamespaceDefaults[i]
+		if !defaults.Matches(namespace, taskName) {
+			continue
+		}
+		if value, ok := defaults.Params()[param]; ok {
+			return value, true
+		}
+	}
+	return "", false
 }
 
 // Implement interpolation.ProjectContext interface
@@ -151,3 +229,46 @@ func (pc *ProjectContext) GetIncludedParams() map[string]*ast.ProjectParameterSt
 	}
 	return pc.IncludedParams
 }
+
+func (pc *ProjectContext) GetIncludedOrigins() map[string]includes.Origin {
+	if pc == nil {
+		return nil
+	}
+	if pc.IncludedOrigins == nil {
+		pc.IncludedOrigins = make(map[string]includes.Origin)
+	}
+	return pc.IncludedOrigins
+}
+
+func (pc *ProjectContext) GetEncryptedSettings() map[string]bool {
+	if pc == nil {
+		return nil
+	}
+	if pc.EncryptedSettings == nil {
+		pc.EncryptedSettings = make(map[string]bool)
+	}
+	return pc.EncryptedSettings
+}
+
+func (pc *ProjectContext) GetIncludedMembers() map[string]string {
+	if pc == nil {
+		return nil
+	}
+	if pc.IncludedMembers == nil {
+		pc.IncludedMembers = make(map[string]string)
+	}
+	return pc.IncludedMembers
+}
+
+// AddIncludedHook registers a lifecycle hook declared in an included file
+func (pc *ProjectContext) AddIncludedHook(hook *ast.LifecycleHook, namespace, source string) error {
+	if pc == nil || pc.HookManager == nil {
+		return nil
+	}
+	registered, err := newLifecycleHook(hook, source, namespace)
+	if err != nil {
+		return err
+	}
+	pc.HookManager.RegisterHook(hook.Type, registered)
+	return nil
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialHelperRunsOnceAndIsMasked(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	input := `version: 2.0
+
+project "app":
+  set credential helper for "api.example.com" to "exec:echo called >> ` + calls + `; echo s3cr3t-token"
+
+task "sync":
+  get "https://api.example.com/v1/items"
+  get "https://api.example.com/v1/other"
+  get "https://other.example.com/"
+  info "leaked s3cr3t-token"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithVerbose(true))
+	if err := eng.Execute(program, "sync"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "s3cr3t-token") {
+		t.Fatalf("credential leaked into the output:\n%s", out)
+	}
+	if got := strings.Count(out, "Authorization: Bearer ***"); got != 2 {
+		t.Fatalf("expected 2 requests with the helper's token, got %d:\n%s", got, out)
+	}
+	if !strings.Contains(out, "leaked ***") {
+		t.Fatalf("expected the secret to be masked in messages:\n%s", out)
+	}
+
+	content, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(content), "called"); got != 1 {
+		t.Fatalf("credential helper ran %d times, want 1", got)
+	}
+}
+
+func TestCredentialHelperUserAndPassword(t *testing.T) {
+	t.Setenv("DRUN_TEST_CREDENTIAL", "robot\npa55word")
+	input := `version: 2.0
+
+project "app":
+  set credential helper for "api.example.com" to "env:DRUN_TEST_CREDENTIAL"
+
+task "sync":
+  get "https://api.example.com/v1/items"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithVerbose(true))
+	if err := eng.Execute(program, "sync"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "--user robot:***") {
+		t.Fatalf("expected basic auth with a masked password:\n%s", buf.String())
+	}
+}
+
+func TestCredentialHelperNotRunInDryRun(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  set credential helper for "api.example.com" to "env:DRUN_TEST_MISSING_CREDENTIAL"
+
+task "sync":
+  get "https://api.example.com/v1/items"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithDryRun(true))
+	if err := eng.Execute(program, "sync"); err != nil {
+		t.Fatalf("dry run should not run the helper: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Would use the credential helper for api.example.com") {
+		t.Fatalf("expected the dry run to mention the helper:\n%s", buf.String())
+	}
+
+	eng = NewEngineWithOptions(WithOutput(&buf))
+	if err := eng.Execute(program, "sync"); err == nil || !strings.Contains(err.Error(), "DRUN_TEST_MISSING_CREDENTIAL is not set") {
+		t.Fatalf("expected a missing variable error, got %v", err)
+	}
+}
+
+func TestCredentialHosts(t *testing.T) {
+	images := map[string]string{
+		"nginx":                  "",
+		"acme/api:1":             "",
+		"ghcr.io/acme/api:1":     "ghcr.io",
+		"localhost:5000/api":     "localhost:5000",
+		"Registry.Example.com/x": "registry.example.com",
+	}
+	for image, want := range images {
+		if got := imageRegistryHost(image); got != want {
+			t.Errorf("imageRegistryHost(%q) = %q, want %q", image, got, want)
+		}
+	}
+
+	remotes := map[string]string{
+		"https://GitHub.com/acme/api.git":   "github.com",
+		"ssh://git@github.com/acme/api.git": "",
+	}
+	for remote, want := range remotes {
+		if got := gitRemoteHost(remote, ""); got != want {
+			t.Errorf("gitRemoteHost(%q) = %q, want %q", remote, got, want)
+		}
+	}
+}
+
+func TestSecretMaskerMasksSecretsSplitAcrossWrites(t *testing.T) {
+	secrets := &secretList{}
+	secrets.add("s3cr3t-token")
+	var buf bytes.Buffer
+	masker := newSecretMasker(&buf, secrets)
+
+	for _, chunk := range []string{"token=s3c", "r3t", "-token\n", "tail s3"} {
+		if _, err := masker.Write([]byte(chunk)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if got := buf.String(); got != "token=***\ntail " {
+		t.Fatalf("expected the partial secret to be held back, got %q", got)
+	}
+	masker.flush()
+	if got := buf.String(); got != "token=***\ntail s3" {
+		t.Fatalf("expected flush to write the held back output, got %q", got)
+	}
+}
@@ -411,6 +411,35 @@ task "extract_remove":
 	}
 }
 
+func TestExtractedNameAndFilters(t *testing.T) {
+	for name, want := range map[string]string{
+		"tool-1.0/bin/tool":       "bin/tool",
+		"./tool-1.0/LICENSE":      "LICENSE",
+		"tool-1.0/../../etc/pass": "pass",
+		"tool-1.0/":               "",
+	} {
+		got, keep := extractedName(name, 1)
+		if got != want || keep != (want != "") {
+			t.Errorf("extractedName(%q, 1) = %q, %v; want %q", name, got, keep, want)
+		}
+	}
+
+	only := []string{"bin/*", "share/man/man1"}
+	for name, want := range map[string]bool{
+		"bin/tool":              true,
+		"share/man/man1/tool.1": true,
+		"LICENSE":               false,
+		"share/doc/README":      false,
+	} {
+		if got := matchesExtractFilters(name, false, only); got != want {
+			t.Errorf("matchesExtractFilters(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !matchesExtractFilters("share/man", true, only) {
+		t.Error("expected the parent directory of a filtered path to be kept")
+	}
+}
+
 func TestEngine_DownloadAuthAndHeaders(t *testing.T) {
 	input := `version: 2.0
 
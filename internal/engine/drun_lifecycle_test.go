@@ -2,6 +2,9 @@ package engine
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -121,3 +124,189 @@ task "test":
 		})
 	}
 }
+
+func TestScopedTaskHooks(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  before any task:
+    info "global before"
+
+  before task "deploy-api":
+    info "refreshing auth"
+
+  after tasks matching "deploy-*":
+    info "deploy finished for {$globals.current_task}"
+
+task "build":
+  info "building"
+
+task "deploy-api":
+  depends on build
+  info "deploying api"
+
+task "release":
+  depends on deploy-api
+  info "releasing"`
+
+	p := parser.NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parse errors: %v", p.Errors())
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	if err := eng.Execute(program, "release"); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+
+	outputStr := output.String()
+	expected := []string{
+		"building",
+		"refreshing auth",
+		"deploying api",
+		"deploy finished for deploy-api",
+		"global before",
+		"releasing",
+	}
+	lastIndex := -1
+	for _, want := range expected {
+		index := strings.Index(outputStr, want)
+		if index == -1 || index < lastIndex {
+			t.Fatalf("Expected %q in order, got:\n%s", want, outputStr)
+		}
+		lastIndex = index
+	}
+	if strings.Count(outputStr, "refreshing auth") != 1 || strings.Count(outputStr, "deploy finished") != 1 {
+		t.Errorf("Expected scoped hooks to run once, got:\n%s", outputStr)
+	}
+
+	output.Reset()
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("Unexpected execution error: %v", err)
+	}
+	if strings.Contains(output.String(), "refreshing auth") || strings.Contains(output.String(), "deploy finished") {
+		t.Errorf("Scoped hooks should not run for unrelated tasks, got:\n%s", output.String())
+	}
+}
+
+func TestHookPriorityOrdersIncludedAndLocalHooks(t *testing.T) {
+	root := t.TempDir()
+	shared := `version: 2.0
+
+project "shared":
+  before any task:
+    info "shared before"
+
+  before any task priority 10:
+    info "shared urgent before"
+
+  on drun setup priority 5:
+    info "shared setup"
+
+task "noop":
+  info "noop"
+`
+	if err := os.WriteFile(filepath.Join(root, "shared.drun"), []byte(shared), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	specFile := filepath.Join(root, "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  on drun setup:
+    info "local setup"
+
+  before any task priority -1:
+    info "local late before"
+
+  include "shared.drun"
+
+  before any task:
+    info "local before"
+
+task "build":
+  info "building"`
+
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	if err := eng.ExecuteWithParamsAndFile(program, "build", nil, specFile); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+
+	outputStr := output.String()
+	expected := []string{
+		"shared setup",
+		"local setup",
+		"shared urgent before",
+		"shared before",
+		"local before",
+		"local late before",
+		"building",
+	}
+	lastIndex := -1
+	for _, want := range expected {
+		index := strings.Index(outputStr, want)
+		if index == -1 || index < lastIndex {
+			t.Fatalf("Expected %q in order, got:\n%s", want, outputStr)
+		}
+		lastIndex = index
+	}
+}
+
+func TestSkipHooks(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+project "myapp":
+  on drun setup:
+    info "setup hook"
+
+  before any task:
+    info "before hook"
+
+  after any task:
+    info "after hook"
+
+  on drun teardown:
+    info "teardown hook"
+
+task "test":
+  info "Running test"`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	tests := []struct {
+		skip []string
+		want []string
+	}{
+		{nil, []string{"setup hook", "before hook", "after hook", "teardown hook"}},
+		{[]string{"setup", "before"}, []string{"after hook", "teardown hook"}},
+		{[]string{"teardown"}, []string{"setup hook", "before hook", "after hook"}},
+		{[]string{"setup", "teardown", "before", "after"}, nil},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		eng := NewEngineWithOptions(WithOutput(&buf), WithSkipHooks(tt.skip...))
+		if err := eng.Execute(program, "test"); err != nil {
+			t.Fatalf("skipping %v: %v", tt.skip, err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "Running test") {
+			t.Errorf("skipping %v: expected the task to run:\n%s", tt.skip, out)
+		}
+		for _, hook := range []string{"setup hook", "before hook", "after hook", "teardown hook"} {
+			want := slices.Contains(tt.want, hook)
+			if strings.Contains(out, hook) != want {
+				t.Errorf("skipping %v: %q ran = %v, want %v:\n%s", tt.skip, hook, !want, want, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/secrets"
+)
+
+const (
+	testAgeIdentity  = "AGE-SECRET-KEY-1VYZYGUHNUFZ9SDY90UE697EVHZFJ4WZ85RD2M7DH53CR8CFCKQLQLC059P"
+	testAgeRecipient = "age1acvhwtxe8rjpzn4n4qktwsj79vjx6j25z8wsdqryyv2502k3ly8qlqumfk"
+)
+
+func encryptedSettingProgram(t *testing.T, plaintext string) string {
+	t.Helper()
+	recipient, err := secrets.ParseAgeRecipient(testAgeRecipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := secrets.EncryptValue(plaintext, []*secrets.AgeRecipient{recipient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `version: 2.0
+
+project "app":
+  set encrypted db_password to "` + value + `"
+  set db_user to "admin"
+
+task "connect":
+  info "connecting as {db_user} with {db_password}"
+  run "echo password={db_password}"
+`
+}
+
+func TestEncryptedSettingsAreDecryptedAndMasked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("SOPS_AGE_KEY", testAgeIdentity)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	program, err := ParseString(encryptedSettingProgram(t, "hunter2"))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "connect"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "ENC[") {
+		t.Fatalf("the secret or its ciphertext leaked into the output:\n%s", out)
+	}
+	for _, want := range []string{"connecting as admin with ***", "password=***"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestEncryptedSettingsAreMaskedInLogFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("SOPS_AGE_KEY", testAgeIdentity)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	input := strings.Replace(encryptedSettingProgram(t, "hunter2"), `  run "echo password={db_password}"
+`, `  log output to "logs/task.log"
+  run "echo password={db_password}" logging to "logs/step.log"
+`, 1)
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "connect"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	for _, name := range []string{"task.log", "step.log"} {
+		content, err := os.ReadFile(filepath.Join("logs", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(content), "hunter2") || !strings.Contains(string(content), "password=***") {
+			t.Errorf("%s does not mask the secret:\n%s", name, content)
+		}
+	}
+}
+
+func TestEncryptedSettingsAreMaskedInEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("SOPS_AGE_KEY", testAgeIdentity)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	input := strings.Replace(encryptedSettingProgram(t, "hunter2"), `task "connect":
+`, `task "connect":
+  requires $password
+  let $dsn = "admin:{db_password}@db"
+`, 1)
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var events bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(NewJSONObserver(&events)))
+	if err := eng.ExecuteWithParams(program, "connect", map[string]string{"password": "hunter2"}); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	out := events.String()
+	if strings.Contains(out, "hunter2") {
+		t.Fatalf("the secret leaked into the events:\n%s", out)
+	}
+	for _, want := range []string{`"$dsn":"admin:***@db"`, `"password":"***"`, `"command":"echo password=***"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("events do not contain %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestEncryptedSettingsWithoutIdentityFailOnlyWhenRun(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	program, err := ParseString(encryptedSettingProgram(t, "hunter2"))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	eng := NewEngine(&bytes.Buffer{})
+	if _, err := eng.ListTasksWithIncludes(program, ""); err != nil {
+		t.Fatalf("listing tasks should not need the key: %v", err)
+	}
+	err = eng.Execute(program, "connect")
+	if err == nil || !strings.Contains(err.Error(), "cannot decrypt setting db_password: no age identity found") {
+		t.Fatalf("expected a decryption error, got %v", err)
+	}
+}
@@ -4,11 +4,12 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"regexp"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-	"unicode/utf8"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/builtins"
@@ -23,11 +24,13 @@ import (
 	"github.com/phillarmonic/drun/v2/internal/engine/interpolation"
 	"github.com/phillarmonic/drun/v2/internal/engine/planner"
 	"github.com/phillarmonic/drun/v2/internal/errors"
+	"github.com/phillarmonic/drun/v2/internal/i18n"
 	"github.com/phillarmonic/drun/v2/internal/lexer"
+	"github.com/phillarmonic/drun/v2/internal/netretry"
 	"github.com/phillarmonic/drun/v2/internal/parser"
 	"github.com/phillarmonic/drun/v2/internal/platform"
 	"github.com/phillarmonic/drun/v2/internal/provisioning"
-	"github.com/phillarmonic/drun/v2/internal/remote"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
 	"github.com/phillarmonic/drun/v2/internal/types"
 )
 
@@ -44,6 +47,10 @@ type SecretsManager interface {
 // Engine executes drun v2 programs directly
 type Engine struct {
 	output           io.Writer
+	translator       atomic.Pointer[i18n.Translator] // catalog of the language of the last planned project, for errors reported after a run (Localize)
+	defaultStyle     string                          // output style used when the project sets none
+	defaultSymbols   string                          // status symbols from the user configuration
+	defaultColors    string                          // status colors from the user configuration
 	dryRun           bool
 	verbose          bool
 	taskModeOverride string
@@ -54,30 +61,55 @@ type Engine struct {
 	paramValidator *parameter.Validator
 	depResolver    *task.DependencyResolver
 
-	// Execution components
+	// Execution components. The task registry and planner are shared by
+	// every execution of the engine, so registering and planning hold planMu;
+	// everything a running task changes lives in its ExecutionContext.
+	planMu   sync.Mutex
 	planner  *planner.Planner
 	executor *executor.Executor
 
-	// Remote includes support
+	// Remote includes support, built on first use by includes() and
+	// includeCache() so runs that never include or provision skip them
+	cacheTTL         time.Duration
+	cacheEnabled     bool
+	cacheOnce        sync.Once
 	cacheManager     *cache.Manager
-	githubFetcher    *remote.GitHubFetcher
-	httpsFetcher     *remote.HTTPSFetcher
-	drunhubFetcher   *remote.DrunhubFetcher
+	includesOnce     sync.Once
 	includesResolver *includes.Resolver
 
 	// Secrets management
 	secretsManager SecretsManager
-
+	credentials    *credentialStore // credential helper results for this run
+	escalation     escalationState  // sudo authentication for statements run as another user
+	answers        confirmAnswers   // terminal answers to confirm prompts
+	drunVersion    string           // version of the drun binary, for {drun.version}
+	logSink        *logSinkWriter   // structured copy of the output (set log sink); nil when none
+	stdin          *stdinInput      // content piped into drun, for {stdin}
+
+	defaultParallelism      int
+	maxCPU                  float64       // --max-cpu; 0 = detected
+	maxMemory               int64         // --max-memory in bytes; 0 = detected
+	maxDuration             time.Duration // --max-duration; 0 = the project's max duration
+	networkRetries          int           // retries of transient network failures unless the project sets them
+	resourcesOnce           sync.Once     // sizes resourcePool on first use
+	resourcePool            *resourcePool // cpus and memory reserved by tasks that declare needs
+	paramPrompter           ParamPrompter
+	runHistory              *runhistory.Store
+	loopReplay              map[string][]string // failed loop items of the replayed run (cmd:rerun); nil when not replaying
+	observers               []EngineObserver
+	watchVar                string // variable traced with --watch-var, without the $
+	force                   bool
+	notify                  bool            // --notify: notify when every run finishes
+	timings                 bool            // --timings: print task durations
+	checkShellSyntax        bool            // --check-syntax: parse shell commands in dry runs
+	skipHooks               map[string]bool // hook kinds not to run (--skip-hooks, --skip-setup, ...)
+	notifier                Notifier
 	allowToolVersionChanges bool
 	userProvisioningSources []string
 	embeddedProvisionings   []provisioning.EmbeddedSource
 	newToolDetector         func() toolDetector
 	newProvisioningResolver func(workingDir string) provisioningResolver
 	provisionCommandRunner  func(command string, execCtx *ExecutionContext) error
-
-	// Legacy regex patterns (still used by variable operations)
-	quotedArgRegex *regexp.Regexp
-	paramArgRegex  *regexp.Regexp
 }
 
 // ExecutionContext and ProjectContext moved to context.go
@@ -97,23 +129,24 @@ func NewEngineWithOptions(opts ...Option) *Engine {
 	}
 	options.applyDefaults()
 
-	// Create default fetchers and interpolator
-	githubFetcher := remote.NewGitHubFetcher()
-	httpsFetcher := remote.NewHTTPSFetcher()
-	drunhubFetcher := remote.NewDrunhubFetcher(githubFetcher)
 	interp := interpolation.NewInterpolator()
 	embeddedProvisionings := append([]provisioning.EmbeddedSource(nil), options.EmbeddedProvisioningSources...)
 	embeddedProvisionings = append(embeddedProvisionings, provisioning.DefaultEmbeddedSources()...)
 
 	e := &Engine{
 		output:           options.Output,
+		defaultStyle:     options.DefaultOutputStyle,
+		defaultSymbols:   options.DefaultStatusSymbols,
+		defaultColors:    options.DefaultStatusColors,
 		dryRun:           options.DryRun,
 		verbose:          options.Verbose,
 		taskModeOverride: options.TaskModeOverride,
+		drunVersion:      options.DrunVersion,
+		stdin:            &stdinInput{reader: options.Stdin},
+		answers:          confirmAnswers{input: options.ConfirmInput},
 		interpolator:     interp,
-		githubFetcher:    githubFetcher,
-		httpsFetcher:     httpsFetcher,
-		drunhubFetcher:   drunhubFetcher,
+		cacheTTL:         options.IncludeCacheTTL,
+		cacheManager:     options.CacheManager,
 
 		// Domain services
 		taskRegistry:   options.TaskRegistry,
@@ -122,17 +155,30 @@ func NewEngineWithOptions(opts ...Option) *Engine {
 
 		// Secrets management
 		secretsManager: options.SecretsManager,
-
+		credentials:    newCredentialStore(options.Output),
+
+		defaultParallelism:      options.DefaultParallelism,
+		maxCPU:                  options.MaxCPU,
+		maxMemory:               options.MaxMemory,
+		maxDuration:             options.MaxDuration,
+		networkRetries:          netretry.DefaultRetries,
+		paramPrompter:           options.ParamPrompter,
+		runHistory:              options.RunHistory,
+		loopReplay:              options.LoopReplay,
+		observers:               append([]EngineObserver(nil), options.Observers...),
+		force:                   options.Force,
+		watchVar:                options.WatchVariable,
+		notify:                  options.Notify,
+		timings:                 options.Timings,
+		checkShellSyntax:        options.CheckShellSyntax,
+		skipHooks:               hookKindSet(options.SkipHooks),
+		notifier:                options.Notifier,
 		allowToolVersionChanges: options.AllowToolVersionChanges,
 		userProvisioningSources: append([]string(nil), options.UserProvisioningSources...),
 		embeddedProvisionings:   embeddedProvisionings,
 
 		// Execution components
 		planner: planner.NewPlanner(options.TaskRegistry, options.DepResolver),
-
-		// Pre-compile regex patterns for performance (still used by variable operations)
-		quotedArgRegex: regexp.MustCompile(`^([^(]+)\((.+)\)$`),
-		paramArgRegex:  regexp.MustCompile(`^([^(]+)\(([^)]+)\)$`),
 	}
 
 	e.newToolDetector = func() toolDetector {
@@ -140,8 +186,8 @@ func NewEngineWithOptions(opts ...Option) *Engine {
 	}
 	e.newProvisioningResolver = func(workingDir string) provisioningResolver {
 		opts := []provisioning.Option{}
-		if e.cacheManager != nil {
-			opts = append(opts, provisioning.WithCacheManager(e.cacheManager))
+		if cacheManager := e.includeCache(); cacheManager != nil {
+			opts = append(opts, provisioning.WithCacheManager(cacheManager))
 		}
 		if len(e.embeddedProvisionings) > 0 {
 			opts = append(opts, provisioning.WithEmbeddedSources(e.embeddedProvisionings))
@@ -149,20 +195,18 @@ func NewEngineWithOptions(opts ...Option) *Engine {
 		return provisioning.NewResolver(workingDir, opts...)
 	}
 	e.provisionCommandRunner = e.runProvisioningCommand
+	e.paramValidator.SetResolver(e.resolveValidatorPlugin)
+	if options.DefaultNetworkRetries != nil {
+		e.networkRetries = *options.DefaultNetworkRetries
+	}
+	for _, observer := range e.observers {
+		if output, ok := observer.(OutputObserver); ok {
+			e.credentials.observeOutput(output)
+		}
+	}
 
 	// Set the engine as the domain statement executor
-	e.executor = executor.NewExecutor(options.Output, options.DryRun, e)
-
-	// Initialize includes resolver
-	e.includesResolver = includes.NewResolver(
-		options.CacheManager,
-		githubFetcher,
-		httpsFetcher,
-		drunhubFetcher,
-		options.Verbose,
-		options.Output,
-		ParseStringWithFilename,
-	)
+	e.executor = executor.NewExecutor(e.out(nil), options.DryRun, e)
 
 	// Set up interpolator callbacks for variable and builtin operations
 	interp.SetResolveVariableOpsCallback(func(expr string, ctx interface{}) string {
@@ -189,6 +233,8 @@ func NewEngineWithOptions(opts ...Option) *Engine {
 				execCtx:        execCtx,
 				secretsManager: e.secretsManager,
 				dryRun:         e.dryRun,
+				drunVersion:    e.drunVersion,
+				stdin:          e.stdin,
 			}
 			if result, err := builtins.CallBuiltin(funcName, builtinCtx); err == nil {
 				if chain, err := e.parseBuiltinOperations(operations); err == nil && chain != nil {
@@ -214,7 +260,11 @@ func NewEngineWithOptions(opts ...Option) *Engine {
 				execCtx:        execCtx,
 				secretsManager: e.secretsManager,
 				dryRun:         e.dryRun,
+				drunVersion:    e.drunVersion,
+				stdin:          e.stdin,
 			}
+			// Results are data and are never translated; errors are,
+			// where they are shown
 			return builtins.CallBuiltin(funcName, builtinCtx, args...)
 		}
 		return "", fmt.Errorf("no execution context available")
@@ -238,15 +288,12 @@ func (e *Engine) SetAllowUndefinedVars(allow bool) {
 	e.interpolator.SetAllowUndefined(allow)
 }
 
-// SetCacheEnabled enables or disables remote include caching
+// SetCacheEnabled enables or disables remote include caching. The cache is
+// opened when a remote include or provisioning source is first fetched, so
+// it must be called before the engine runs anything.
 func (e *Engine) SetCacheEnabled(enabled bool) error {
-	var err error
-	if enabled {
-		e.cacheManager, err = cache.NewManager(1*time.Minute, false)
-	} else {
-		e.cacheManager, err = cache.NewManager(0, true) // disabled
-	}
-	return err
+	e.cacheEnabled = enabled
+	return nil
 }
 
 // Cleanup removes temporary files created during execution
@@ -271,104 +318,319 @@ func (e *Engine) ExecuteWithParams(program *ast.Program, taskName string, params
 
 // ExecuteWithParamsAndFile runs a v2 program with the given parameters and current file path
 func (e *Engine) ExecuteWithParamsAndFile(program *ast.Program, taskName string, params map[string]string, currentFile string) error {
+	return e.ExecuteTargets(program, []TaskTarget{{Name: taskName, Params: params}}, currentFile, false)
+}
+
+// TaskTarget is a task requested for execution together with its parameters
+type TaskTarget struct {
+	Name   string
+	Params map[string]string
+}
+
+// ExecuteTargets runs one or more tasks in a single invocation. The project
+// context is built once and setup/teardown hooks run once around all targets;
+// before/after hooks run around each target. Targets run in the given order,
+// and a task already executed for an earlier target (as a dependency or as a
+// target) is not executed again. With parallel set, the dependencies of all
+// targets run first, then the remaining targets run concurrently.
+func (e *Engine) ExecuteTargets(program *ast.Program, targets []TaskTarget, currentFile string, parallel bool) (err error) {
 	if program == nil {
 		return fmt.Errorf("program is nil")
 	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no task specified")
+	}
+
+	// Registration and planning share the engine's task registry, so
+	// concurrent executions take turns; the plans they return are their own
+	e.planMu.Lock()
+	projectCtx, plans, style, err := e.planTargets(program, targets, currentFile)
+	e.planMu.Unlock()
+	if err != nil {
+		return err
+	}
 
 	// Start memory monitor to detect runaway execution
 	monitor := NewMemoryMonitor(program)
 	monitor.Start()
 	defer monitor.Stop()
 
+	hookPlan := plans[0].Hooks
+	retryLimit, _ := retryBudgetLimit(projectCtx) // validated while planning
+	durationLimit, _ := maxDurationLimit(projectCtx)
+	if e.maxDuration > 0 {
+		durationLimit = e.maxDuration
+	}
+
+	// Capture the process cwd once so that `use workdir` relative paths
+	// always resolve from this baseline regardless of how many times it's called.
+	originalCwd, err := os.Getwd()
+	if err != nil {
+		originalCwd = "" // fallback: will be re-resolved per call
+	}
+
+	// Create execution context with parameters
+	ctx := &ExecutionContext{
+		Parameters:         make(map[string]*types.Value, 8), // Pre-allocate for typical parameter count
+		Variables:          make(map[string]string, 16),      // Pre-allocate for typical variable count
+		Project:            projectCtx,
+		CurrentFile:        currentFile,
+		Program:            program,
+		OriginalWorkingDir: originalCwd,
+		Monitor:            monitor,
+		NotifyWhenDone:     &atomic.Bool{},
+		Timings:            &taskTimings{},
+		Assertions:         &failedAssertions{},
+		Retries:            &retryBudget{limit: retryLimit},
+		Deadline:           newRunDeadline(durationLimit),
+		Run:                newRunInfo(),
+		LoopItems:          &loopItems{},
+		Outputs:            &taskOutputs{},
+		Style:              style,
+	}
+	started := time.Now()
+	defer func() {
+		e.recordFailedLoopItems(ctx, targets, err)
+		e.reportTimings(ctx, started)
+		e.reportSectionTimings(ctx)
+		e.reportFailedAssertions(ctx)
+		e.notifyCompletion(ctx, targets, started, err)
+		e.credentials.masker.flush()
+		e.flushLogSink()
+	}()
+
+	// Execute drun setup hooks from the execution plan
+	if hookPlan != nil && e.runsHooks("setup", hookPlan.SetupHooks, ctx) {
+		if err := e.executor.ExecuteHooks("setup", hookPlan.SetupHooks, ctx, true); err != nil {
+			return fmt.Errorf("setup hook failed: %w", err)
+		}
+	}
+
+	if parallel && len(targets) > 1 {
+		err = e.executeTargetsParallel(plans, targets, ctx)
+	} else {
+		executed := make(map[string]bool)
+		for i, plan := range plans {
+			if err = e.executePlan(plan, plan.ExecutionOrder, targets[i].Params, ctx, executed); err != nil {
+				break
+			}
+		}
+	}
+	// A run stopped for taking too long still tears down
+	if err != nil && !isMaxDuration(err) {
+		return err
+	}
+
+	// Execute drun teardown hooks (best-effort)
+	if hookPlan != nil && e.runsHooks("teardown", hookPlan.TeardownHooks, ctx) {
+		endCleanup := ctx.Deadline.startCleanup()
+		if err := e.executor.ExecuteHooks("teardown", hookPlan.TeardownHooks, ctx, false); err != nil {
+			// Teardown hook failures are logged but don't fail the execution
+			e.iconf(ctx, "⚠️  ", "teardown hook failed: %v\n", err)
+		}
+		endCleanup()
+	}
+
+	return err
+}
+
+// hookKindSet returns the set of hook kinds to skip, nil when none
+func hookKindSet(kinds []string) map[string]bool {
+	if len(kinds) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		set[kind] = true
+	}
+	return set
+}
+
+// runsHooks reports whether hooks of kind should run: there are some, and
+// the invocation does not skip them
+func (e *Engine) runsHooks(kind string, hooks []statement.Statement, ctx *ExecutionContext) bool {
+	if len(hooks) == 0 {
+		return false
+	}
+	if e.skipHooks[kind] {
+		if e.verbose {
+			e.iconf(ctx, "⏭️  ", "Skipping %d %s hook(s)\n", len(hooks), kind)
+		}
+		return false
+	}
+	return true
+}
+
+// planTargets registers the program's tasks, builds the project context and
+// output style and plans every target before anything runs. Callers hold
+// planMu.
+func (e *Engine) planTargets(program *ast.Program, targets []TaskTarget, currentFile string) (*ProjectContext, []*planner.ExecutionPlan, *outputStyle, error) {
 	// Register all tasks with domain registry
 	e.taskRegistry.Clear() // Clear registry for fresh execution
 	e.taskRegistry.SetCurrentPlatform(platform.Current())
 	if err := e.registerTasks(program.Tasks, currentFile); err != nil {
-		return fmt.Errorf("task registration failed: %v", err)
+		return nil, nil, nil, fmt.Errorf("task registration failed: %v", err)
 	}
 	if err := task.ResolveInheritedToolRequirements(e.taskRegistry); err != nil {
-		return fmt.Errorf("resolving task tool requirements: %w", err)
+		return nil, nil, nil, fmt.Errorf("resolving task tool requirements: %w", err)
 	}
 
 	// Create project context for planning
 	projectCtx, err := e.BuildProjectContext(program.Project, currentFile)
 	if err != nil {
-		return fmt.Errorf("creating project context: %w", err)
+		return nil, nil, nil, fmt.Errorf("creating project context: %w", err)
+	}
+	if projectCtx != nil && projectCtx.DecryptError != nil {
+		return nil, nil, nil, projectCtx.DecryptError
 	}
 	if err := e.registerIncludedTasks(projectCtx, currentFile); err != nil {
-		return fmt.Errorf("included task registration failed: %w", err)
+		return nil, nil, nil, fmt.Errorf("included task registration failed: %w", err)
+	}
+	theme, err := e.outputTheme(projectCtx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	translator, err := e.outputTranslator(projectCtx, currentFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	e.translator.Store(translator)
+	if err := checkShellEscaping(projectCtx); err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err := retryBudgetLimit(projectCtx); err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err := maxDurationLimit(projectCtx); err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err := todoBehavior(projectCtx); err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err := e.networkRetryCount(projectCtx); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := e.installLogSink(projectCtx); err != nil {
+		return nil, nil, nil, err
 	}
-
 	// Check project-level tool requirements before planning/execution starts
 	if err := e.checkProjectToolRequirements(projectCtx); err != nil {
-		return err // Execution fails immediately if project tools are missing
+		return nil, nil, nil, err // Execution fails immediately if project tools are missing
 	}
 
 	// Build planner context from project
 	var plannerCtx *planner.ProjectContext
 	if projectCtx != nil && projectCtx.HookManager != nil {
 		plannerCtx = &planner.ProjectContext{
-			Name:          projectCtx.Name,
-			Version:       projectCtx.Version,
-			SetupHooks:    projectCtx.HookManager.GetSetupHooks(),
-			TeardownHooks: projectCtx.HookManager.GetTeardownHooks(),
-			BeforeHooks:   projectCtx.HookManager.GetBeforeHooks(),
-			AfterHooks:    projectCtx.HookManager.GetAfterHooks(),
+			Name:            projectCtx.Name,
+			Version:         projectCtx.Version,
+			SetupHooks:      projectCtx.HookManager.GetSetupHooks(),
+			TeardownHooks:   projectCtx.HookManager.GetTeardownHooks(),
+			BeforeHooks:     projectCtx.HookManager.GetBeforeHooks(),
+			AfterHooks:      projectCtx.HookManager.GetAfterHooks(),
+			BeforeHookChain: projectCtx.HookManager.Chain("before"),
+			AfterHookChain:  projectCtx.HookManager.Chain("after"),
 		}
 	}
 
-	// Create comprehensive execution plan
-	plan, err := e.planner.Plan(taskName, program, plannerCtx)
-	if err != nil {
-		return fmt.Errorf("execution planning failed: %w", err)
-	}
-
-	// Validate all secret references before execution starts
-	// This ensures we fail fast if any secrets are missing, similar to Docker's COPY behavior
-	// We validate after planning so we can check which secrets will be set during execution
 	projectName := ""
 	if projectCtx != nil {
 		projectName = projectCtx.Name
 	}
-	if err := e.validateSecrets(program, plan, projectName); err != nil {
-		return fmt.Errorf("secret validation failed: %w", err)
-	}
 
-	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Execution order: %v\n", plan.ExecutionOrder)
-		if e.verbose {
-			if planJSON, err := plan.ToJSON(); err == nil {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] Execution plan:\n%s\n", planJSON)
+	// Create a comprehensive execution plan for every target before anything runs
+	plans := make([]*planner.ExecutionPlan, len(targets))
+	for i, target := range targets {
+		plan, err := e.planner.Plan(target.Name, program, plannerCtx)
+		if err != nil {
+			return nil, nil, nil, errors.NewValidationError(fmt.Errorf("execution planning failed: %w", err))
+		}
+
+		// Validate all secret references before execution starts
+		// This ensures we fail fast if any secrets are missing, similar to Docker's COPY behavior
+		// We validate after planning so we can check which secrets will be set during execution
+		if err := e.validateSecrets(program, plan, projectName); err != nil {
+			return nil, nil, nil, fmt.Errorf("secret validation failed: %w", err)
+		}
+		if err := checkOutputNeeds(plan); err != nil {
+			return nil, nil, nil, errors.NewValidationError(err)
+		}
+
+		if e.dryRun {
+			_, _ = fmt.Fprintf(e.out(nil), "[DRY RUN] Execution order: %v\n", plan.ExecutionOrder)
+			if e.verbose {
+				if planJSON, err := plan.ToJSON(); err == nil {
+					_, _ = fmt.Fprintf(e.out(nil), "[DRY RUN] Execution plan:\n%s\n", planJSON)
+				}
 			}
 		}
+		plans[i] = plan
 	}
+	return projectCtx, plans, &outputStyle{theme: theme, translator: translator}, nil
+}
 
-	// Capture the process cwd once so that `use workdir` relative paths
-	// always resolve from this baseline regardless of how many times it's called.
-	originalCwd, err := os.Getwd()
-	if err != nil {
-		originalCwd = "" // fallback: will be re-resolved per call
+// executeTargetsParallel runs the dependencies of every target sequentially,
+// then runs the targets that are left concurrently, each in its own copy of ctx
+func (e *Engine) executeTargetsParallel(plans []*planner.ExecutionPlan, targets []TaskTarget, ctx *ExecutionContext) error {
+	executed := make(map[string]bool)
+	for i, plan := range plans {
+		deps := plan.ExecutionOrder[:len(plan.ExecutionOrder)-1]
+		if err := e.executePlan(plan, deps, targets[i].Params, ctx, executed); err != nil {
+			return err
+		}
 	}
 
-	// Create execution context with parameters
-	ctx := &ExecutionContext{
-		Parameters:         make(map[string]*types.Value, 8), // Pre-allocate for typical parameter count
-		Variables:          make(map[string]string, 16),      // Pre-allocate for typical variable count
-		Project:            projectCtx,
-		CurrentFile:        currentFile,
-		Program:            program,
-		OriginalWorkingDir: originalCwd,
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(plans))
+	for i, plan := range plans {
+		if executed[plan.TargetTask] {
+			continue // already ran as a dependency of another target
+		}
+		executed[plan.TargetTask] = true
+
+		wg.Add(1)
+		go func(plan *planner.ExecutionPlan, params map[string]string, targetCtx *ExecutionContext) {
+			defer wg.Done()
+			if err := e.executePlan(plan, []string{plan.TargetTask}, params, targetCtx, nil); err != nil {
+				errChan <- err
+			}
+		}(plan, targets[i].Params, cloneExecutionContext(ctx))
 	}
 
-	// Execute drun setup hooks from the execution plan
-	if plan.Hooks != nil && len(plan.Hooks.SetupHooks) > 0 {
-		if err := e.executor.ExecuteHooks("setup", plan.Hooks.SetupHooks, ctx, true); err != nil {
-			return fmt.Errorf("setup hook failed: %w", err)
-		}
+	wg.Wait()
+	close(errChan)
+
+	var failures []error
+	for err := range errChan {
+		failures = append(failures, err)
+	}
+	if len(failures) == 0 {
+		return nil
 	}
+	// Keep every failure matchable with errors.Is/As, in a stable order
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Error() < failures[j].Error() })
+	args := make([]any, len(failures))
+	for i, failure := range failures {
+		args[i] = failure
+	}
+	return fmt.Errorf(strings.TrimSuffix(strings.Repeat("%w; ", len(failures)), "; "), args...)
+}
+
+// executePlan runs the named tasks of plan in order. Tasks recorded in
+// executed are skipped, and each task that runs is recorded; a nil executed
+// map runs every task.
+func (e *Engine) executePlan(plan *planner.ExecutionPlan, order []string, params map[string]string, ctx *ExecutionContext, executed map[string]bool) error {
+	for _, currentTaskName := range order {
+		if executed != nil {
+			if executed[currentTaskName] {
+				if e.verbose {
+					e.iconf(ctx, "⏭️  ", "Skipping task '%s' (already executed in this run)\n", currentTaskName)
+				}
+				continue
+			}
+			executed[currentTaskName] = true
+		}
 
-	// Execute all tasks in the planned execution order
-	for _, currentTaskName := range plan.ExecutionOrder {
 		// Get the task plan from the execution plan
 		taskPlan, err := plan.GetTask(currentTaskName)
 		if err != nil {
@@ -380,49 +642,125 @@ func (e *Engine) ExecuteWithParamsAndFile(program *ast.Program, taskName string,
 			return err
 		}
 
+		historyRun, skip := e.checkRunHistory(taskPlan, currentTaskName, ctx)
+		if skip {
+			continue
+		}
+
 		// Set current task name for globals access
 		ctx.CurrentTask = currentTaskName
 		ctx.CurrentTaskMode = resolvedTaskMode(taskPlan.Mode, plan.Tasks[plan.TargetTask].Mode, e.taskModeOverride)
+		if err := e.useTaskOutputs(currentTaskName, taskPlan.OutputNeeds, ctx); err != nil {
+			return err
+		}
 
-		// Save workdir state so changes in this task don't leak to the next
+		releaseResources := e.reserveTaskResources(currentTaskName, newResourceNeeds(taskPlan.CPUs, taskPlan.Memory), ctx)
+
+		// Save workdir and output log state so changes in this task don't leak to the next
 		savedWorkingDir := ctx.WorkingDir
+		savedTaskLogFile := ctx.TaskLogFile
+		savedContainer := ctx.Container
+		restoreTask := func() {
+			ctx.WorkingDir = savedWorkingDir
+			ctx.TaskLogFile = savedTaskLogFile
+			ctx.Container = savedContainer
+		}
+		taskStart := e.notifyTaskStart(currentTaskName, ctx)
+		ctx.TaskStarted = taskStart
+
+		// finishTask ends the task however it finished: it restores the saved
+		// state, releases the task's resources and records and reports its end
+		finishTask := func(err error) error {
+			restoreTask()
+			releaseResources()
+			e.recordTaskTiming(currentTaskName, taskStart, err, ctx)
+			e.notifyTaskEnd(currentTaskName, taskStart, err, ctx)
+			return err
+		}
 
-		// Execute before hooks only for the target task
-		if currentTaskName == taskName && plan.Hooks != nil && len(plan.Hooks.BeforeHooks) > 0 {
-			if err := e.executor.ExecuteHooks("before", plan.Hooks.BeforeHooks, ctx, true); err != nil {
-				return fmt.Errorf("before hook failed: %w", err)
+		// Execute before hooks: "before any task" hooks for the target task and
+		// task-scoped hooks for every matching task, in priority order
+		if e.runsHooks("before", taskPlan.BeforeHooks, ctx) {
+			if err := e.executor.ExecuteHooks("before", taskPlan.BeforeHooks, ctx, true); err != nil {
+				return finishTask(fmt.Errorf("before hook failed: %w", err))
 			}
 		}
 
 		// Execute task body directly using domain statements
+		ctx.Container = taskPlan.Container
 		for _, stmt := range taskPlan.Body {
 			if err := e.executeStatement(stmt, ctx); err != nil {
-				ctx.WorkingDir = savedWorkingDir // restore on error too
-				return fmt.Errorf("task '%s' failed: %v", currentTaskName, err)
+				// A todo with `set todo behavior to "skip"` ends the task early
+				if isTodoSkipped(err) {
+					break
+				}
+				return finishTask(fmt.Errorf("task '%s' failed: %w", currentTaskName, err))
 			}
 		}
+		if err := e.recordTaskOutputs(currentTaskName, taskPlan.Outputs, ctx); err != nil {
+			return finishTask(err)
+		}
 
-		// Restore workdir after task completes
-		ctx.WorkingDir = savedWorkingDir
+		// Restore workdir, output log and container before the after hooks
+		restoreTask()
 
-		// Execute after hooks only for the target task (best-effort)
-		if currentTaskName == taskName && plan.Hooks != nil && len(plan.Hooks.AfterHooks) > 0 {
-			if err := e.executor.ExecuteHooks("after", plan.Hooks.AfterHooks, ctx, false); err != nil {
+		if historyRun != nil {
+			e.recordRunHistory(*historyRun, ctx)
+		}
+
+		// Execute after hooks (best-effort)
+		if e.runsHooks("after", taskPlan.AfterHooks, ctx) {
+			if err := e.executor.ExecuteHooks("after", taskPlan.AfterHooks, ctx, false); err != nil {
 				// After hooks failures are logged but don't fail the execution
-				_, _ = fmt.Fprintf(e.output, "⚠️  after hook failed: %v\n", err)
+				e.iconf(ctx, "⚠️  ", "after hook failed: %v\n", err)
 			}
 		}
+		_ = finishTask(nil)
 	}
+	return nil
+}
 
-	// Execute drun teardown hooks (best-effort)
-	if plan.Hooks != nil && len(plan.Hooks.TeardownHooks) > 0 {
-		if err := e.executor.ExecuteHooks("teardown", plan.Hooks.TeardownHooks, ctx, false); err != nil {
-			// Teardown hook failures are logged but don't fail the execution
-			_, _ = fmt.Fprintf(e.output, "⚠️  teardown hook failed: %v\n", err)
+// cloneExecutionContext copies ctx so a concurrently running target cannot
+// see or overwrite another target's parameters and variables
+func cloneExecutionContext(ctx *ExecutionContext) *ExecutionContext {
+	clone := *ctx
+	clone.Parameters = make(map[string]*types.Value, len(ctx.Parameters))
+	for k, v := range ctx.Parameters {
+		clone.Parameters[k] = v
+	}
+	clone.Variables = make(map[string]string, len(ctx.Variables))
+	for k, v := range ctx.Variables {
+		clone.Variables[k] = v
+	}
+	return &clone
+}
+
+// newLifecycleHook converts a lifecycle hook declaration into a registered
+// hook. Scoped hooks declared in an included file are namespaced like the
+// included tasks they refer to.
+func newLifecycleHook(s *ast.LifecycleHook, source, namespace string) (hooks.Hook, error) {
+	body, err := statement.FromASTList(s.Body)
+	if err != nil {
+		return hooks.Hook{}, fmt.Errorf("converting %s hook body: %w", s.Type, err)
+	}
+
+	hook := hooks.Hook{Priority: s.Priority, Source: source, Body: body}
+	if s.Scope == "task" || s.Scope == "matching" {
+		hook.Pattern = s.Target
+		hook.Glob = s.Scope == "matching"
+		if namespace != "" {
+			hook.Pattern = namespace + "." + hook.Pattern
 		}
 	}
+	return hook, nil
+}
 
-	return nil
+// hookSource describes where a hook was declared for debug output
+func hookSource(file string, line int) string {
+	if file == "" {
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
 }
 
 // registerTasks registers all tasks from the program into the domain registry
@@ -455,8 +793,12 @@ func (e *Engine) registerIncludedTasks(projectCtx *ProjectContext, currentFile s
 		if !ok || namespace == "" {
 			return fmt.Errorf("included task %q is missing namespace", namespacedName)
 		}
+		source := currentFile
+		if origin, ok := projectCtx.IncludedOrigins["task:"+namespacedName]; ok {
+			source = origin.File
+		}
 		for _, astTask := range projectCtx.IncludedTasks[namespacedName] {
-			domainTask, err := task.NewTask(astTask, namespace, currentFile)
+			domainTask, err := task.NewTask(astTask, namespace, source)
 			if err != nil {
 				return fmt.Errorf("converting included task %s: %w", namespacedName, err)
 			}
@@ -540,49 +882,53 @@ func (e *Engine) setupTaskParametersFromPlan(taskPlan *planner.TaskPlan, params
 		var rawValue string
 		var hasValue bool
 
+		domainParam := &parameter.Parameter{
+			Name:         param.Name,
+			Type:         param.Type,
+			DefaultValue: param.DefaultValue,
+			HasDefault:   param.HasDefault,
+			Required:     param.Required,
+			DataType:     param.DataType,
+			Constraints:  param.Constraints,
+			MinValue:     param.MinValue,
+			MaxValue:     param.MaxValue,
+			Pattern:      param.Pattern,
+			PatternMacro: param.PatternMacro,
+			EmailFormat:  param.EmailFormat,
+			MustExist:    param.MustExist,
+			Variadic:     param.Variadic,
+			Validator:    param.Validator,
+		}
+
 		if providedValue, exists := params[param.Name]; exists {
 			rawValue = providedValue
 			hasValue = true
+		} else if projectDefault, ok := ctx.Project.namespaceDefault(taskPlan.Namespace, taskPlan.Name, param.Name); ok {
+			rawValue = e.interpolateVariables(projectDefault, ctx)
+			hasValue = true
 		} else if param.HasDefault {
 			rawValue = e.interpolateVariables(param.DefaultValue, ctx)
 			hasValue = true
 		} else if param.Required {
-			return errors.NewParameterValidationError(fmt.Sprintf("required parameter '%s' not provided", param.Name))
-		}
-
-		if hasValue {
-			paramType, err := types.ParseParameterType(param.DataType)
-			if err != nil {
-				paramType = types.InferType(rawValue)
+			if e.paramPrompter == nil {
+				return errors.NewParameterValidationError(fmt.Sprintf("required parameter '%s' not provided", param.Name))
 			}
-
-			typedValue, err := types.NewValue(paramType, rawValue)
+			value, err := e.paramPrompter(domainParam, func(value string) error {
+				_, err := e.typedParameterValue(domainParam, value, taskPlan.Name, ctx)
+				return err
+			})
 			if err != nil {
-				return errors.NewParameterValidationError(fmt.Sprintf("parameter '%s': invalid %s value '%s': %v",
-					param.Name, paramType, rawValue, err))
-			}
-
-			// Use domain validator
-			domainParam := &parameter.Parameter{
-				Name:         param.Name,
-				Type:         param.Type,
-				DefaultValue: param.DefaultValue,
-				HasDefault:   param.HasDefault,
-				Required:     param.Required,
-				DataType:     param.DataType,
-				Constraints:  param.Constraints,
-				MinValue:     param.MinValue,
-				MaxValue:     param.MaxValue,
-				Pattern:      param.Pattern,
-				PatternMacro: param.PatternMacro,
-				EmailFormat:  param.EmailFormat,
-				Variadic:     param.Variadic,
+				return errors.NewParameterValidationError(fmt.Sprintf("required parameter '%s' not provided: %v", param.Name, err))
 			}
+			rawValue = value
+			hasValue = true
+		}
 
-			if err := e.paramValidator.Validate(domainParam, typedValue); err != nil {
+		if hasValue {
+			typedValue, err := e.typedParameterValue(domainParam, rawValue, taskPlan.Name, ctx)
+			if err != nil {
 				return errors.NewParameterValidationError(fmt.Sprintf("parameter '%s': %v", param.Name, err))
 			}
-
 			ctx.Parameters[param.Name] = typedValue
 		}
 	}
@@ -590,6 +936,25 @@ func (e *Engine) setupTaskParametersFromPlan(taskPlan *planner.TaskPlan, params
 	return nil
 }
 
+// typedParameterValue converts a raw value to the parameter's type and checks
+// it against the parameter's constraints
+func (e *Engine) typedParameterValue(param *parameter.Parameter, rawValue, task string, ctx *ExecutionContext) (*types.Value, error) {
+	paramType, err := types.ParseParameterType(param.DataType)
+	if err != nil {
+		paramType = types.InferType(rawValue)
+	}
+
+	typedValue, err := types.NewValue(paramType, rawValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value '%s': %v", paramType, rawValue, err)
+	}
+
+	if err := e.validateParameter(param, typedValue, task, ctx); err != nil {
+		return nil, err
+	}
+	return typedValue, nil
+}
+
 // setupTaskParameters sets up parameters for a specific task (deprecated - use setupTaskParametersFromPlan)
 func (e *Engine) setupTaskParameters(task *ast.TaskStatement, params map[string]string, ctx *ExecutionContext) error {
 	// First, add included/namespaced parameters from includes (e.g., docker.registry)
@@ -689,6 +1054,10 @@ func (e *Engine) setupTaskParameters(task *ast.TaskStatement, params map[string]
 		if providedValue, exists := params[param.Name]; exists {
 			rawValue = providedValue
 			hasValue = true
+		} else if projectDefault, ok := ctx.Project.namespaceDefault(ctx.CurrentNamespace, task.Name, param.Name); ok {
+			// "defaults for docker.*:" in the consuming project replaces the included task's default
+			rawValue = e.interpolateVariables(projectDefault, ctx)
+			hasValue = true
 		} else if param.HasDefault {
 			// For parameters with default values (both required and optional), use the default
 			// Interpolate the default value if it contains braces (for builtin function calls)
@@ -728,11 +1097,13 @@ func (e *Engine) setupTaskParameters(task *ast.TaskStatement, params map[string]
 				Pattern:      param.Pattern,
 				PatternMacro: param.PatternMacro,
 				EmailFormat:  param.EmailFormat,
+				MustExist:    param.MustExist,
 				Variadic:     param.Variadic,
+				Validator:    param.Validator,
 			}
 
 			// Use domain validator
-			if err := e.paramValidator.Validate(domainParam, typedValue); err != nil {
+			if err := e.validateParameter(domainParam, typedValue, task.Name, ctx); err != nil {
 				return errors.NewParameterValidationError(fmt.Sprintf("parameter '%s': %v", param.Name, err))
 			}
 
@@ -763,6 +1134,7 @@ func (e *Engine) BuildProjectContext(project *ast.ProjectStatement, currentFile
 		IncludedSettings:  make(map[string]string, 16),                         // Pre-allocate for included settings
 		IncludedParams:    make(map[string]*ast.ProjectParameterStatement, 16), // Pre-allocate for included parameters
 		IncludedFiles:     make(map[string]bool, 4),                            // Pre-allocate for included files
+		IncludedOrigins:   make(map[string]includes.Origin, 16),                // Pre-allocate for included declarations
 	}
 
 	// Process project settings
@@ -773,6 +1145,9 @@ func (e *Engine) BuildProjectContext(project *ast.ProjectStatement, currentFile
 			if s.Value != nil {
 				ctx.Settings[s.Key] = s.Value.String()
 			}
+			if s.Encrypted {
+				ctx.GetEncryptedSettings()[s.Key] = true
+			}
 		case *ast.ProjectParameterStatement:
 			// Store project-level parameter
 			ctx.Parameters[s.Name] = s
@@ -780,22 +1155,11 @@ func (e *Engine) BuildProjectContext(project *ast.ProjectStatement, currentFile
 			// Store snippet for later use
 			ctx.Snippets[s.Name] = s
 		case *ast.LifecycleHook:
-			// Convert AST statements to domain statements
-			domainBody, err := statement.FromASTList(s.Body)
+			hook, err := newLifecycleHook(s, hookSource(currentFile, s.Token.Line), "")
 			if err != nil {
-				return nil, fmt.Errorf("converting %s hook body: %w", s.Type, err)
-			}
-
-			switch s.Type {
-			case "before":
-				ctx.HookManager.RegisterBeforeHooks(domainBody)
-			case "after":
-				ctx.HookManager.RegisterAfterHooks(domainBody)
-			case "setup":
-				ctx.HookManager.RegisterSetupHooks(domainBody)
-			case "teardown":
-				ctx.HookManager.RegisterTeardownHooks(domainBody)
+				return nil, err
 			}
+			ctx.HookManager.RegisterHook(s.Type, hook)
 		case *ast.ShellConfigStatement:
 			// Store shell configurations for each platform
 			for platformName, config := range s.Platforms {
@@ -807,7 +1171,9 @@ func (e *Engine) BuildProjectContext(project *ast.ProjectStatement, currentFile
 			}
 		case *ast.IncludeStatement:
 			// Process include statement
-			e.includesResolver.ProcessInclude(ctx, s, currentFile)
+			if err := e.includes().ProcessInclude(ctx, s, currentFile); err != nil {
+				return nil, err
+			}
 		case *ast.RequiresToolsStatement:
 			// Store project-level tool requirements for startup validation
 			for _, astTool := range s.Tools {
@@ -829,6 +1195,41 @@ func (e *Engine) BuildProjectContext(project *ast.ProjectStatement, currentFile
 			}
 		case *ast.ProvisioningSourcesStatement:
 			ctx.ProvisioningSources = append(ctx.ProvisioningSources, s.Sources...)
+		case *ast.PathStatement:
+			// Resolve project-local PATH entries against the project root
+			rootDir := projectRootDir(currentFile)
+			for _, entry := range s.Entries {
+				if !filepath.IsAbs(entry) {
+					entry = filepath.Join(rootDir, entry)
+				}
+				ctx.PathEntries = append(ctx.PathEntries, filepath.Clean(entry))
+			}
+		case *ast.ShellEnvironmentStatement:
+			if s.Mode == "allow" {
+				ctx.ShellEnvAllow = append(ctx.ShellEnvAllow, s.Patterns...)
+			} else {
+				ctx.ShellEnvDeny = append(ctx.ShellEnvDeny, s.Patterns...)
+			}
+		case *ast.CredentialHelperStatement:
+			if ctx.CredentialHelpers == nil {
+				ctx.CredentialHelpers = make(map[string]string)
+			}
+			ctx.CredentialHelpers[s.Host] = s.Helper
+		case *ast.ConditionStatement:
+			if ctx.Conditions == nil {
+				ctx.Conditions = make(map[string]string)
+			}
+			ctx.Conditions[s.Name] = s.Expression
+		case *ast.HTTPClientStatement:
+			if ctx.HTTPClients == nil {
+				ctx.HTTPClients = make(map[string]*ast.HTTPClientStatement)
+			}
+			ctx.HTTPClients[s.Name] = s
+		case *ast.HostGroupStatement:
+			if ctx.HostGroups == nil {
+				ctx.HostGroups = make(map[string][]string)
+			}
+			ctx.HostGroups[s.Name] = s.Hosts
 		case *ast.GitPolicyStatement:
 			// Convert to domain statement immediately since it's small and pure data
 			domainStmt, err := statement.FromAST(s)
@@ -839,9 +1240,22 @@ func (e *Engine) BuildProjectContext(project *ast.ProjectStatement, currentFile
 			}
 		case *ast.SCMRegistryStatement:
 			ctx.SCMRegistry = s
+		case *ast.NamespaceDefaultsStatement:
+			ctx.NamespaceDefaults = append(ctx.NamespaceDefaults, s)
 		}
 	}
 
+	if err := checkNamedConditionCycles(ctx.Conditions); err != nil {
+		return nil, err
+	}
+
+	e.decryptSettings(ctx)
+
+	// Evaluate computed settings once, now that every setting is known
+	if err := e.resolveComputedSettings(ctx, currentFile); err != nil {
+		return nil, err
+	}
+
 	return ctx, nil
 }
 
@@ -851,16 +1265,23 @@ func (e *Engine) executeTask(task *ast.TaskStatement, ctx *ExecutionContext) err
 		return err
 	}
 
+	if err := e.useTaskOutputs(task.Name, astOutputNeeds(task), ctx); err != nil {
+		return err
+	}
+
 	prevTaskMode := ctx.CurrentTaskMode
+	prevContainer := ctx.Container
 	ctx.CurrentTaskMode = resolvedTaskMode(task.Mode, prevTaskMode, e.taskModeOverride)
+	ctx.Container = task.Container
 	defer func() {
 		ctx.CurrentTaskMode = prevTaskMode
+		ctx.Container = prevContainer
 	}()
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute task: %s\n", task.Name)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute task: %s\n", task.Name)
 		if task.Description != "" {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Description: %s\n", task.Description)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Description: %s\n", task.Description)
 		}
 		// Convert AST statements to domain and execute
 		for _, astStmt := range task.Body {
@@ -872,7 +1293,7 @@ func (e *Engine) executeTask(task *ast.TaskStatement, ctx *ExecutionContext) err
 				return err
 			}
 		}
-		return nil
+		return e.recordTaskOutputs(task.Name, task.Outputs, ctx)
 	}
 
 	// Execute each statement in the task body (convert AST to domain)
@@ -882,11 +1303,15 @@ func (e *Engine) executeTask(task *ast.TaskStatement, ctx *ExecutionContext) err
 			return fmt.Errorf("converting statement: %w", err)
 		}
 		if err := e.executeStatement(domainStmt, ctx); err != nil {
+			// A todo with `set todo behavior to "skip"` ends the task early
+			if isTodoSkipped(err) {
+				break
+			}
 			return err
 		}
 	}
 
-	return nil
+	return e.recordTaskOutputs(task.Name, task.Outputs, ctx)
 }
 
 // ExecuteStatement executes a single AST statement (implements executor.StatementExecutor)
@@ -919,6 +1344,11 @@ func (e *Engine) ExecuteDomainStatement(stmt statement.Statement, ctx interface{
 
 // executeStatement executes domain statements directly
 func (e *Engine) executeStatement(stmt statement.Statement, ctx *ExecutionContext) error {
+	if err := e.checkDeadline(ctx); err != nil {
+		return err
+	}
+	e.notifyStatement(stmt, ctx)
+
 	switch s := stmt.(type) {
 	case *statement.Action:
 		return e.executeAction(s, ctx)
@@ -970,10 +1400,33 @@ func (e *Engine) executeStatement(stmt statement.Statement, ctx *ExecutionContex
 		return e.executeOrchestration(s, ctx)
 	case *statement.ChangeWorkdir:
 		return e.executeChangeWorkdir(s, ctx)
+	case *statement.LogOutput:
+		return e.executeLogOutput(s, ctx)
 	case *statement.RequiresTools:
 		return e.executeRequiresTools(s, ctx)
 	case *statement.GitValidate:
 		return e.executeGitValidate(s, ctx)
+	case *statement.Notify:
+		return e.executeNotify(s, ctx)
+	case *statement.Assert:
+		return e.executeAssert(s, ctx)
+	case *statement.Guarded:
+		return e.executeGuarded(s, ctx)
+	case *statement.Within:
+		return e.executeWithin(s, ctx)
+
+	case *statement.Lock:
+		return e.executeLock(s, ctx)
+	case *statement.Plugin:
+		return e.executePlugin(s, ctx)
+	case *statement.Compare:
+		return e.executeCompare(s, ctx)
+	case *statement.VersionFiles:
+		return e.executeVersionFiles(s, ctx)
+	case *statement.Confirm:
+		return e.executeConfirm(s, ctx)
+	case *statement.Todo:
+		return e.executeTodo(s, ctx)
 	case *statement.GitPolicy:
 		// Processed during project setup, ignored during execution
 		return nil
@@ -991,54 +1444,44 @@ func (e *Engine) executeAction(action *statement.Action, ctx *ExecutionContext)
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] %s: %s\n", action.ActionType, interpolatedMessage)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] %s: %s\n", action.ActionType, interpolatedMessage)
 		return nil
 	}
 
 	// Map actions to output with appropriate formatting and emojis
 	switch action.ActionType {
 	case "info":
-		_, _ = fmt.Fprintf(e.output, "ℹ️  %s\n", interpolatedMessage)
+		e.iconf(ctx, "ℹ️  ", "%s\n", interpolatedMessage)
 	case "step":
-		// Optional line breaks - only add if explicitly requested
-		if action.LineBreakBefore {
-			_, _ = fmt.Fprintln(e.output)
+		renderer, err := e.style(ctx).theme.StepFor(action.StepStyle, action.StepWidth)
+		if err != nil {
+			return fmt.Errorf("in step statement: %w", err)
 		}
 
-		// Render each line within a box sized to the longest visible line.
-		lines := strings.Split(interpolatedMessage, "\n")
-		maxWidth := 0
-		for _, line := range lines {
-			if width := utf8.RuneCountInString(line); width > maxWidth {
-				maxWidth = width
-			}
+		// Optional line breaks - only add if explicitly requested
+		if action.LineBreakBefore {
+			_, _ = fmt.Fprintln(e.out(ctx))
 		}
 
-		horizontal := strings.Repeat("─", maxWidth+2)
-		_, _ = fmt.Fprintf(e.output, "┌%s┐\n", horizontal)
-		for _, line := range lines {
-			padding := maxWidth - utf8.RuneCountInString(line)
-			_, _ = fmt.Fprintf(e.output, "│ %s%s │\n", line, strings.Repeat(" ", padding))
-		}
-		_, _ = fmt.Fprintf(e.output, "└%s┘\n", horizontal)
+		renderer.RenderStep(e.out(ctx), strings.Split(interpolatedMessage, "\n"))
 
 		// Optional line break after
 		if action.LineBreakAfter {
-			_, _ = fmt.Fprintln(e.output)
+			_, _ = fmt.Fprintln(e.out(ctx))
 		}
 	case "warn", "warning":
-		_, _ = fmt.Fprintf(e.output, "⚠️  %s\n", interpolatedMessage)
+		e.iconf(ctx, "⚠️  ", "%s\n", interpolatedMessage)
 	case "error":
-		_, _ = fmt.Fprintf(e.output, "❌  %s\n", interpolatedMessage)
+		e.iconf(ctx, "❌  ", "%s\n", interpolatedMessage)
 	case "success":
-		_, _ = fmt.Fprintf(e.output, "✅  %s\n", interpolatedMessage)
+		e.iconf(ctx, "✅  ", "%s\n", interpolatedMessage)
 	case "fail":
-		_, _ = fmt.Fprintf(e.output, "💥  %s\n", interpolatedMessage)
+		e.iconf(ctx, "💥  ", "%s\n", interpolatedMessage)
 		return fmt.Errorf("task failed: %s", interpolatedMessage)
 	case "echo":
 		// Process \n escape sequences for newlines
 		processedMessage := strings.ReplaceAll(interpolatedMessage, "\\n", "\n")
-		_, _ = fmt.Fprintf(e.output, "%s\n", processedMessage)
+		_, _ = fmt.Fprintf(e.out(ctx), "%s\n", processedMessage)
 	default:
 		return fmt.Errorf("unknown action: %s", action.ActionType)
 	}
@@ -1049,9 +1492,12 @@ func (e *Engine) executeAction(action *statement.Action, ctx *ExecutionContext)
 // executeTaskCall executes a task call statement
 func (e *Engine) executeTaskCall(callStmt *statement.TaskCall, ctx *ExecutionContext) error {
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would call task: %s\n", callStmt.TaskName)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would call task: %s%s\n", callStmt.TaskName, describeCallOutput(callStmt))
 		if len(callStmt.Parameters) > 0 {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] With parameters: %v\n", callStmt.Parameters)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] With parameters: %v\n", callStmt.Parameters)
+		}
+		if callStmt.CaptureVar != "" {
+			e.assignVariable(ctx, callStmt.CaptureVar, "[DRY RUN] task output", "call task")
 		}
 		return nil
 	}
@@ -1071,6 +1517,20 @@ func (e *Engine) executeTaskCall(callStmt *statement.TaskCall, ctx *ExecutionCon
 		CurrentTaskMode:  resolvedTaskMode(targetTask.Mode, ctx.CurrentTaskMode, e.taskModeOverride),
 		CurrentNamespace: taskNamespace, // Set namespace for transitive resolution
 		Program:          ctx.Program,
+		TaskLogFile:      ctx.TaskLogFile, // called tasks write to the caller's output log
+		Monitor:          ctx.Monitor,
+		NotifyWhenDone:   ctx.NotifyWhenDone,
+		TaskStarted:      time.Now(),
+		Timings:          ctx.Timings,
+		Assertions:       ctx.Assertions,
+		Retries:          ctx.Retries,
+		Deadline:         ctx.Deadline,
+		Run:              ctx.Run,
+		LoopItems:        ctx.LoopItems,
+		Outputs:          ctx.Outputs,
+		Resources:        ctx.Resources,
+		Output:           ctx.Output,
+		Style:            ctx.Style,
 	}
 
 	// Copy current variables to the new context
@@ -1078,28 +1538,53 @@ func (e *Engine) executeTaskCall(callStmt *statement.TaskCall, ctx *ExecutionCon
 		callCtx.Variables[k] = v
 	}
 
+	// Tasks of an included workspace member see the member's path
+	delete(callCtx.Variables, memberPathVariable)
+	if memberPath, ok := ctx.Project.GetIncludedMembers()[taskNamespace]; ok {
+		callCtx.Variables[memberPathVariable] = memberPath
+	}
+
 	// Set up parameters for the called task
 	if err := e.setupTaskParameters(targetTask, callStmt.Parameters, callCtx); err != nil {
-		return fmt.Errorf("failed to setup parameters for task '%s': %v", callStmt.TaskName, err)
+		return fmt.Errorf("failed to setup parameters for task '%s': %w", callStmt.TaskName, err)
 	}
 
 	// Execute the called task
-	if err := e.executeTask(targetTask, callCtx); err != nil {
-		return fmt.Errorf("task '%s' failed: %v", callStmt.TaskName, err)
+	release := e.reserveTaskResources(callStmt.TaskName, newResourceNeeds(targetTask.CPUs, targetTask.Memory), callCtx)
+	defer release()
+	output, err := e.runCalledTask(callStmt, targetTask, callCtx)
+	if err != nil {
+		if callStmt.CaptureVar != "" {
+			e.assignVariable(ctx, callStmt.CaptureVar, output, "call task")
+		}
+		return fmt.Errorf("task '%s' failed: %w", callStmt.TaskName, err)
 	}
 
 	// Copy back any new variables that might have been set in the called task
 	for k, v := range callCtx.Variables {
+		if k == memberPathVariable {
+			continue
+		}
 		ctx.Variables[k] = v
 	}
+	if callStmt.CaptureVar != "" {
+		e.assignVariable(ctx, callStmt.CaptureVar, output, "call task")
+		if e.verbose {
+			e.iconf(ctx, "📦  ", "Captured the output of task '%s' in variable '%s'\n", callStmt.TaskName, callStmt.CaptureVar)
+		}
+	}
 
 	return nil
 }
 
+// memberPathVariable holds the relative path of the workspace member whose
+// task is running, read as {member.path}
+const memberPathVariable = "member.path"
+
 // executeUseSnippet executes a snippet by running its body statements
 func (e *Engine) executeUseSnippet(useStmt *statement.UseSnippet, ctx *ExecutionContext) error {
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute snippet: %s\n", useStmt.SnippetName)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute snippet: %s\n", useStmt.SnippetName)
 		return nil
 	}
 
@@ -1174,7 +1659,7 @@ func (e *Engine) executeUseSnippet(useStmt *statement.UseSnippet, ctx *Execution
 // executeTaskFromTemplate instantiates and executes a task from a template
 func (e *Engine) executeTaskFromTemplate(tfts *statement.TaskFromTemplate, ctx *ExecutionContext) error {
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would instantiate task '%s' from template '%s'\n", tfts.Name, tfts.TemplateName)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would instantiate task '%s' from template '%s'\n", tfts.Name, tfts.TemplateName)
 		return nil
 	}
 
@@ -1201,12 +1686,25 @@ func (e *Engine) executeTaskFromTemplate(tfts *statement.TaskFromTemplate, ctx *
 
 	// Create a new execution context for the instantiated task
 	taskCtx := &ExecutionContext{
-		Parameters:  make(map[string]*types.Value, 8),
-		Variables:   make(map[string]string, 16),
-		Project:     ctx.Project,
-		CurrentFile: ctx.CurrentFile,
-		CurrentTask: tfts.Name,
-		Program:     ctx.Program,
+		Parameters:     make(map[string]*types.Value, 8),
+		Variables:      make(map[string]string, 16),
+		Project:        ctx.Project,
+		CurrentFile:    ctx.CurrentFile,
+		CurrentTask:    tfts.Name,
+		Program:        ctx.Program,
+		Monitor:        ctx.Monitor,
+		NotifyWhenDone: ctx.NotifyWhenDone,
+		TaskStarted:    time.Now(),
+		Timings:        ctx.Timings,
+		Assertions:     ctx.Assertions,
+		Retries:        ctx.Retries,
+		Deadline:       ctx.Deadline,
+		Run:            ctx.Run,
+		LoopItems:      ctx.LoopItems,
+		Outputs:        ctx.Outputs,
+		Resources:      ctx.Resources,
+		Output:         ctx.Output,
+		Style:          ctx.Style,
 	}
 
 	// Copy current variables to the new context
@@ -1258,11 +1756,13 @@ func (e *Engine) executeTaskFromTemplate(tfts *statement.TaskFromTemplate, ctx *
 				Pattern:      param.Pattern,
 				PatternMacro: param.PatternMacro,
 				EmailFormat:  param.EmailFormat,
+				MustExist:    param.MustExist,
 				Variadic:     param.Variadic,
+				Validator:    param.Validator,
 			}
 
 			// Use domain validator
-			if err := e.paramValidator.Validate(domainParam, typedValue); err != nil {
+			if err := e.validateParameter(domainParam, typedValue, tfts.Name, taskCtx); err != nil {
 				return fmt.Errorf("parameter '%s': %v", param.Name, err)
 			}
 
@@ -1277,6 +1777,9 @@ func (e *Engine) executeTaskFromTemplate(tfts *statement.TaskFromTemplate, ctx *
 			return fmt.Errorf("converting template statement: %w", err)
 		}
 		if err := e.executeStatement(domainStmt, taskCtx); err != nil {
+			if isTodoSkipped(err) {
+				break
+			}
 			return fmt.Errorf("error executing template task '%s': %w", tfts.Name, err)
 		}
 	}
@@ -1305,23 +1808,72 @@ func (e ContinueError) Error() string {
 
 // ListTasks returns a list of available tasks in the program
 func (e *Engine) ListTasks(program *ast.Program) []TaskInfo {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
 	// Register tasks with domain registry for listing
 	e.taskRegistry.Clear()
 	_ = e.registerTasks(program.Tasks, "")
 
-	// Get tasks from domain registry
+	return e.registeredTaskInfos()
+}
+
+// ListTasksWithIncludes lists the program's tasks followed by the tasks its
+// includes bring in, each with its namespace and the file it came from
+func (e *Engine) ListTasksWithIncludes(program *ast.Program, currentFile string) ([]TaskInfo, error) {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	e.taskRegistry.Clear()
+	if err := e.registerTasks(program.Tasks, currentFile); err != nil {
+		return nil, fmt.Errorf("task registration failed: %v", err)
+	}
+
+	projectCtx, err := e.BuildProjectContext(program.Project, currentFile)
+	if err != nil {
+		return nil, fmt.Errorf("creating project context: %w", err)
+	}
+	if err := e.registerIncludedTasks(projectCtx, currentFile); err != nil {
+		return nil, fmt.Errorf("included task registration failed: %w", err)
+	}
+
+	return e.registeredTaskInfos(), nil
+}
+
+// registeredTaskInfos describes the tasks in the domain registry in
+// registration order
+func (e *Engine) registeredTaskInfos() []TaskInfo {
 	domainTasks := e.taskRegistry.List()
 
 	tasks := make([]TaskInfo, 0, len(domainTasks))
 	for _, domainTask := range domainTasks {
 		info := TaskInfo{
-			Name:        domainTask.Name,
+			Name:        domainTask.FullName(),
+			Namespace:   domainTask.Namespace,
+			Source:      domainTask.Source,
 			Description: domainTask.Description,
 			Platforms:   append([]string(nil), domainTask.Platforms...),
+			Mode:        domainTask.Mode,
+			Default:     domainTask.Default,
+			Parameters:  append([]task.Parameter(nil), domainTask.Parameters...),
+			Sources:     append([]string(nil), domainTask.Sources...),
 		}
 		if info.Description == "" {
 			info.Description = "No description"
 		}
+		// Consecutive parallel dependencies share a group, as in
+		// task.DependencyResolver.GetParallelGroups
+		inParallelGroup := false
+		for _, dep := range domainTask.Dependencies {
+			info.Dependencies = append(info.Dependencies, dep.Name)
+			parallel := dep.Parallel && !dep.Sequential
+			if last := len(info.DependencyGroups) - 1; parallel && inParallelGroup {
+				info.DependencyGroups[last] = append(info.DependencyGroups[last], dep.Name)
+			} else {
+				info.DependencyGroups = append(info.DependencyGroups, []string{dep.Name})
+			}
+			inParallelGroup = parallel
+		}
 		tasks = append(tasks, info)
 	}
 	return tasks
@@ -1329,9 +1881,20 @@ func (e *Engine) ListTasks(program *ast.Program) []TaskInfo {
 
 // TaskInfo represents information about a task
 type TaskInfo struct {
-	Name        string
-	Description string
-	Platforms   []string
+	Name         string // fully qualified name, e.g. "docker.build" for included tasks
+	Namespace    string // include namespace; empty for the program's own tasks
+	Source       string // file the task was declared in
+	Description  string
+	Platforms    []string
+	Mode         string
+	Default      bool
+	Parameters   []task.Parameter
+	Dependencies []string // dependency names as written in the task
+	Sources      []string // globs of the files the task depends on
+
+	// DependencyGroups holds Dependencies in scheduling groups; the tasks of
+	// a group with more than one member may run in parallel
+	DependencyGroups [][]string
 }
 
 // ExecuteString is a convenience function that parses and executes v2 source code
@@ -1355,6 +1918,10 @@ func ParseString(input string) (*ast.Program, error) {
 
 // ParseStringWithFilename parses v2 source code with filename for better error reporting
 func ParseStringWithFilename(input, filename string) (*ast.Program, error) {
+	if err := checkSpecVersion(input, filename); err != nil {
+		return nil, err
+	}
+
 	lexer := lexer.NewLexer(input)
 	parser := parser.NewParserWithSource(lexer, filename, input)
 	program := parser.ParseProgram()
@@ -1,7 +1,9 @@
 package engine
 
 import (
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/types"
@@ -112,6 +114,54 @@ func BenchmarkCreateLoopContext(b *testing.B) {
 	}
 }
 
+// largeProgramSource is a drun file with the given number of documented
+// tasks, each with a parameter and a dependency on the previous task
+func largeProgramSource(tasks int) string {
+	var b strings.Builder
+	b.WriteString("version: 2.0\n\nproject \"big\" version \"1.0\":\n  set registry to \"ghcr.io/acme\"\n\n")
+	for i := 0; i < tasks; i++ {
+		fmt.Fprintf(&b, "task \"task_%d\" means \"Task number %d\":\n", i, i)
+		if i > 0 {
+			fmt.Fprintf(&b, "  depends on task_%d\n", i-1)
+		}
+		b.WriteString("  given $target defaults to \"dev\"\n")
+		b.WriteString("  info \"Running {$target}\"\n")
+		b.WriteString("  run \"echo {$target}\"\n\n")
+	}
+	return b.String()
+}
+
+// BenchmarkNewEngine measures engine construction, which every command pays
+// before doing anything else
+func BenchmarkNewEngine(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		eng := NewEngine(io.Discard)
+		eng.Cleanup()
+	}
+}
+
+// BenchmarkListLargeProgram measures the work behind drun --list for a
+// 500-task file: parsing, engine construction and task listing
+func BenchmarkListLargeProgram(b *testing.B) {
+	source := largeProgramSource(500)
+	b.SetBytes(int64(len(source)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		program, err := ParseStringWithFilename(source, "spec.drun")
+		if err != nil {
+			b.Fatal(err)
+		}
+		eng := NewEngine(io.Discard)
+		if err := eng.SetCacheEnabled(true); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := eng.ListTasksWithIncludes(program, "spec.drun"); err != nil {
+			b.Fatal(err)
+		}
+		eng.Cleanup()
+	}
+}
+
 // Helper function to create typed values for benchmarks
 func mustCreateValue(value string) *types.Value {
 	v, err := types.NewValue(types.StringType, value)
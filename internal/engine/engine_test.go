@@ -2,9 +2,11 @@ package engine
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
 	"github.com/phillarmonic/drun/v2/internal/domain/task"
 	"github.com/phillarmonic/drun/v2/internal/provisioning"
 )
@@ -145,3 +147,64 @@ func TestEngineWithOptions_IsolatedTaskRegistry(t *testing.T) {
 		t.Error("Expected engines to have separate task registries")
 	}
 }
+
+func TestParamPrompterProvidesMissingRequiredParameters(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "deploy":
+  requires $env from ["dev", "prod"]
+  info "deploying to {$env}"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	var asked []string
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(
+		WithOutput(&buf),
+		WithParamPrompter(func(param *parameter.Parameter, validate func(string) error) (string, error) {
+			asked = append(asked, param.Name)
+			if err := validate("staging"); err == nil {
+				t.Error("expected staging to be rejected by the parameter constraints")
+			}
+			return "prod", validate("prod")
+		}),
+	)
+
+	if err := eng.Execute(program, "deploy"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(asked) != 1 || asked[0] != "env" {
+		t.Errorf("expected one prompt for env, got %v", asked)
+	}
+	if !strings.Contains(buf.String(), "deploying to prod") {
+		t.Errorf("expected the prompted value to be used, got: %s", buf.String())
+	}
+
+	if err := NewEngine(&buf).Execute(program, "deploy"); err == nil || !strings.Contains(err.Error(), "required parameter 'env' not provided") {
+		t.Errorf("expected missing parameter error without a prompter, got %v", err)
+	}
+}
+
+func TestDryRunHTTPListsHeadersInDeclarationOrder(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "api":
+  get "https://api.example.com" with header "X-Zeta: 1" with header "X-Alpha: 2" with header "X-Mid: 3" with auth bearer "tok"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	want := `[DRY RUN] Would execute HTTP command: curl -X GET -H "X-Zeta: 1" -H "X-Alpha: 2" -H "X-Mid: 3" -H "Authorization: Bearer tok" https://api.example.com`
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		if err := NewEngineWithOptions(WithOutput(&buf), WithDryRun(true)).Execute(program, "api"); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected dry-run output to contain\n%s\ngot:\n%s", want, buf.String())
+		}
+	}
+}
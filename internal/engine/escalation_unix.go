@@ -0,0 +1,22 @@
+//go:build !windows
+
+package engine
+
+import "os"
+
+// alreadyRunningAs reports whether drun itself runs as user, so commands
+// need no sudo
+func alreadyRunningAs(user string) bool {
+	return user == "root" && os.Geteuid() == 0
+}
+
+// escalationUnavailable reports why commands cannot run as user; sudo is
+// available on Unix
+func escalationUnavailable(string) error {
+	return nil
+}
+
+// escalatedCommandLine renders how command runs as user
+func escalatedCommandLine(user, shellPath, command string) string {
+	return sudoCommandLine(user, shellPath, command)
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// alreadyRunningAs reports whether drun itself runs elevated, which is what
+// running as root means on Windows
+func alreadyRunningAs(user string) bool {
+	return user == "root" && windows.GetCurrentProcessToken().IsElevated()
+}
+
+// escalationUnavailable explains why commands cannot run as user. Windows
+// cannot elevate a running process: elevation goes through a UAC prompt when
+// a program starts.
+func escalationUnavailable(user string) error {
+	if user != "root" {
+		return fmt.Errorf("running as user %q is not supported on Windows", user)
+	}
+	return errors.New("this command needs administrator rights: start drun from an elevated terminal (Run as administrator) and accept the UAC prompt")
+}
+
+// escalatedCommandLine renders how command runs as user
+func escalatedCommandLine(user, shellPath, command string) string {
+	return fmt.Sprintf("%s -c %s (elevated, requires Run as administrator)", shellPath, command)
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Assertions
+// This file implements `assert <condition> [otherwise "message"]`, which
+// fails the task as soon as a condition does not hold, and the list of
+// failed assertions printed when the run finishes.
+
+// failedAssertion is an assertion that did not hold
+type failedAssertion struct {
+	task    string
+	message string
+}
+
+// failedAssertions collects the failed assertions of an execution. Parallel
+// targets share it, so it is safe for concurrent use.
+type failedAssertions struct {
+	mu       sync.Mutex
+	failures []failedAssertion
+}
+
+func (f *failedAssertions) add(failure failedAssertion) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, failure)
+}
+
+func (f *failedAssertions) list() []failedAssertion {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]failedAssertion(nil), f.failures...)
+}
+
+// assertionError is returned by a failed assertion
+type assertionError struct {
+	condition string
+	message   string
+}
+
+func (e *assertionError) Error() string {
+	if e.message == e.condition {
+		return "assertion failed: " + e.condition
+	}
+	return fmt.Sprintf("assertion failed: %s (%s)", e.message, e.condition)
+}
+
+// executeAssert evaluates an assertion with the same rules as if and when,
+// so strict mode and file and version comparisons apply as well
+func (e *Engine) executeAssert(stmt *statement.Assert, ctx *ExecutionContext) error {
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would assert: %s\n", stmt.Condition)
+		return nil
+	}
+
+	if err := e.checkConditionVariables(stmt.Condition, ctx); err != nil {
+		return fmt.Errorf("in assert condition: %w", err)
+	}
+	holds, err := e.resolveCondition(stmt.Condition, ctx)
+	if err != nil {
+		return fmt.Errorf("in assert condition: %w", err)
+	}
+	if holds {
+		if e.verbose {
+			e.iconf(ctx, "✅ ", "Assertion passed: %s\n", stmt.Condition)
+		}
+		return nil
+	}
+
+	message := stmt.Condition
+	if stmt.Message != "" {
+		message = e.interpolateVariables(stmt.Message, ctx)
+	}
+	if ctx.Assertions != nil {
+		ctx.Assertions.add(failedAssertion{task: ctx.CurrentTask, message: message})
+	}
+	e.iconf(ctx, "❌ ", "Assertion failed: %s\n", message)
+	return &assertionError{condition: stmt.Condition, message: message}
+}
+
+// reportFailedAssertions lists the assertions that failed during the
+// execution, including those a try block or parallel target did not let
+// stop the run
+func (e *Engine) reportFailedAssertions(ctx *ExecutionContext) {
+	if ctx.Assertions == nil {
+		return
+	}
+	failures := ctx.Assertions.list()
+	if len(failures) == 0 {
+		return
+	}
+
+	e.iconf(ctx, "\n❌ ", "Failed assertions (%d):\n", len(failures))
+	for _, failure := range failures {
+		_, _ = fmt.Fprintf(e.out(ctx), "  %s: %s\n", failure.task, failure.message)
+	}
+}
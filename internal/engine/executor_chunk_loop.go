@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/engine/interpolation"
+)
+
+// Domain: Chunked File Loops
+// This file executes for each chunk loops
+// (for each chunk of 50 files in "migrations/*.sql"), which run their body
+// once per fixed-size batch of the files matching a glob, so commands with
+// argument length limits or rate-limited APIs can process a large file set.
+
+// executeChunkLoop groups the files matching the loop glob into batches and
+// runs the body once per batch. The loop variable holds the batch as a list;
+// <variable>_files holds it as shell-quoted, space-separated arguments.
+func (e *Engine) executeChunkLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
+	if stmt.ChunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", stmt.ChunkSize)
+	}
+	pattern, err := e.interpolateVariablesWithError(stmt.Iterable, ctx)
+	if err != nil {
+		return err
+	}
+
+	files, err := e.globFiles(pattern, ctx)
+	if err != nil {
+		return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	if len(files) == 0 {
+		e.iconf(ctx, "ℹ️  ", "No files match %s\n", pattern)
+		return nil
+	}
+
+	var chunks []string
+	for start := 0; start < len(files); start += stmt.ChunkSize {
+		end := min(start+stmt.ChunkSize, len(files))
+		chunks = append(chunks, formatListLiteral(files[start:end]))
+	}
+	if e.verbose {
+		e.iconf(ctx, "📦 ", "%d files in %s, %d chunks of up to %d\n", len(files), pattern, len(chunks), stmt.ChunkSize)
+	}
+
+	if stmt.Parallel {
+		return e.executeParallelLoop(stmt, chunks, ctx)
+	}
+	return e.executeSequentialLoop(stmt, chunks, ctx)
+}
+
+// globFiles returns the regular files matching pattern in lexical order.
+// Relative patterns are resolved against the task working directory and
+// their matches are returned relative to it, as written in the pattern.
+func (e *Engine) globFiles(pattern string, ctx *ExecutionContext) ([]string, error) {
+	var matches []string
+	var err error
+	base := ""
+	if filepath.IsAbs(pattern) {
+		matches, err = filepath.Glob(pattern)
+	} else {
+		base = e.resolveFilesystemPath(".", ctx)
+		matches, err = fs.Glob(os.DirFS(base), filepath.ToSlash(filepath.Clean(pattern)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := matches[:0]
+	for _, match := range matches {
+		match = filepath.FromSlash(match)
+		if info, err := os.Stat(filepath.Join(base, match)); err == nil && info.Mode().IsRegular() {
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// bindChunkFiles sets <variable>_files to the files of a chunk as
+// shell-quoted, space-separated arguments
+func (e *Engine) bindChunkFiles(ctx *ExecutionContext, variable, chunk string) {
+	files := e.parseArrayLiteralString(chunk)
+	args := make([]string, len(files))
+	for i, file := range files {
+		args[i] = interpolation.ShellQuote(file)
+	}
+	e.assignVariable(ctx, variable+"_files", strings.Join(args, " "), "for loop")
+}
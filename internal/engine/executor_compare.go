@@ -0,0 +1,399 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/ui"
+)
+
+// Domain: Comparisons
+// This file implements compare file and compare dir. Differences are printed
+// as a unified diff, and {comparison.identical} is set to true or false for
+// later conditions and asserts; a difference does not fail the task by
+// itself.
+
+// compareContextLines is how many unchanged lines surround each change
+const compareContextLines = 3
+
+// maxDiffCells bounds the table of the line diff. Beyond it, the lines
+// between the common prefix and suffix are shown as removed and added
+// instead of being matched up.
+const maxDiffCells = 1 << 22
+
+// binarySniffLength is how many leading bytes are checked for NUL bytes to
+// tell binary files from text
+const binarySniffLength = 8000
+
+// executeCompare compares the files or directories of a compare statement
+func (e *Engine) executeCompare(stmt *statement.Compare, ctx *ExecutionContext) error {
+	left, err := e.resolveFileComparisonOperand(stmt.Left, ctx)
+	if err != nil {
+		return fmt.Errorf("compare: resolving %q: %w", stmt.Left, err)
+	}
+	right, err := e.resolveFileComparisonOperand(stmt.Right, ctx)
+	if err != nil {
+		return fmt.Errorf("compare: resolving %q: %w", stmt.Right, err)
+	}
+	leftPath, rightPath := e.resolveFilesystemPath(left, ctx), e.resolveFilesystemPath(right, ctx)
+
+	var diff []string
+	if stmt.Kind == "dir" {
+		diff, err = compareTrees(leftPath, rightPath, left, right, stmt.Ignore)
+	} else {
+		diff, err = compareFiles(leftPath, rightPath, left, right)
+	}
+	if err != nil {
+		return fmt.Errorf("compare: %w", err)
+	}
+
+	identical := len(diff) == 0
+	e.assignVariable(ctx, "comparison.identical", strconv.FormatBool(identical), "compare")
+	if identical {
+		e.iconf(ctx, "✅  ", "%s and %s are identical\n", left, right)
+		return nil
+	}
+	e.iconf(ctx, "❌  ", "%s and %s differ\n", left, right)
+	e.writeDiff(diff, ctx)
+	return nil
+}
+
+// writeDiff prints diff lines, colored unless the output style is plain or
+// NO_COLOR is set
+func (e *Engine) writeDiff(lines []string, ctx *ExecutionContext) {
+	color := e.style(ctx).theme.Style() != ui.StylePlain && os.Getenv("NO_COLOR") == ""
+	for _, line := range lines {
+		ansi := ""
+		if color {
+			switch {
+			case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+				ansi = "1"
+			case strings.HasPrefix(line, "@@"):
+				ansi = "36"
+			case strings.HasPrefix(line, "-"):
+				ansi = "31"
+			case strings.HasPrefix(line, "+"):
+				ansi = "32"
+			}
+		}
+		if ansi != "" {
+			_, _ = fmt.Fprintf(e.out(ctx), "\033[%sm%s\033[0m\n", ansi, line)
+		} else {
+			_, _ = fmt.Fprintln(e.out(ctx), line)
+		}
+	}
+}
+
+// compareFiles returns the diff of two files, named leftName and rightName in
+// its header; it is empty when their contents are the same
+func compareFiles(leftPath, rightPath, leftName, rightName string) ([]string, error) {
+	leftData, err := readComparedFile(leftPath, leftName)
+	if err != nil {
+		return nil, err
+	}
+	rightData, err := readComparedFile(rightPath, rightName)
+	if err != nil {
+		return nil, err
+	}
+	return diffContents(leftData, rightData, leftName, rightName), nil
+}
+
+// readComparedFile reads a file to compare; a directory is an error
+func readComparedFile(path, name string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory; use compare dir", name)
+	}
+	// #nosec G304 -- compare explicitly reads the user-declared path.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// diffContents returns the unified diff of two contents, or a single line
+// for binary contents; it is empty when they are equal
+func diffContents(left, right []byte, leftName, rightName string) []string {
+	if bytes.Equal(left, right) {
+		return nil
+	}
+	if isBinaryContent(left) || isBinaryContent(right) {
+		return []string{fmt.Sprintf("Binary files %s and %s differ", leftName, rightName)}
+	}
+	return unifiedDiff(splitDiffLines(string(left)), splitDiffLines(string(right)), leftName, rightName)
+}
+
+// isBinaryContent reports whether data looks binary: it has a NUL byte near
+// the start, as git decides
+func isBinaryContent(data []byte) bool {
+	if len(data) > binarySniffLength {
+		data = data[:binarySniffLength]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// splitDiffLines splits text into lines; the newline ending the text does
+// not make an extra line
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// compareTrees returns the differences between two directory trees: files
+// found on one side only, and the diff of every file that differs. Entries
+// whose name or slash-separated relative path matches an ignore pattern are
+// skipped, with everything below them.
+func compareTrees(leftPath, rightPath, leftName, rightName string, ignore []string) ([]string, error) {
+	leftFiles, err := listTreeFiles(leftPath, leftName, ignore)
+	if err != nil {
+		return nil, err
+	}
+	rightFiles, err := listTreeFiles(rightPath, rightName, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(leftFiles)+len(rightFiles))
+	for name := range leftFiles {
+		names = append(names, name)
+	}
+	for name := range rightFiles {
+		if !leftFiles[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diff []string
+	for _, name := range names {
+		switch {
+		case !rightFiles[name]:
+			diff = append(diff, fmt.Sprintf("Only in %s: %s", leftName, name))
+		case !leftFiles[name]:
+			diff = append(diff, fmt.Sprintf("Only in %s: %s", rightName, name))
+		default:
+			fileDiff, err := compareFiles(
+				filepath.Join(leftPath, filepath.FromSlash(name)), filepath.Join(rightPath, filepath.FromSlash(name)),
+				path.Join(leftName, name), path.Join(rightName, name))
+			if err != nil {
+				return nil, err
+			}
+			diff = append(diff, fileDiff...)
+		}
+	}
+	return diff, nil
+}
+
+// listTreeFiles returns the slash-separated paths of the files below root
+// that are not ignored
+func listTreeFiles(root, name string, ignore []string) (map[string]bool, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory; use compare file", name)
+	}
+
+	files := make(map[string]bool)
+	err = filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if current == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if isIgnoredEntry(rel, ignore) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			files[rel] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return files, nil
+}
+
+// isIgnoredEntry reports whether the name or the relative path of an entry
+// matches one of the ignore patterns
+func isIgnoredEntry(rel string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// diffOp is one line of a line diff: kept (' '), removed ('-') or added
+// ('+'), with the 1-based positions in both sides where it applies
+type diffOp struct {
+	kind      byte
+	text      string
+	leftLine  int
+	rightLine int
+}
+
+// diffLines returns a shortest edit of left into right, line by line
+func diffLines(left, right []string) []diffOp {
+	prefix := 0
+	for prefix < len(left) && prefix < len(right) && left[prefix] == right[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(left)-prefix && suffix < len(right)-prefix &&
+		left[len(left)-1-suffix] == right[len(right)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(left)+len(right))
+	leftLine, rightLine := 1, 1
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, leftLine: leftLine, rightLine: rightLine})
+		if kind != '+' {
+			leftLine++
+		}
+		if kind != '-' {
+			rightLine++
+		}
+	}
+
+	for _, line := range left[:prefix] {
+		emit(' ', line)
+	}
+	a, b := left[prefix:len(left)-suffix], right[prefix:len(right)-suffix]
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			emit('-', line)
+		}
+		for _, line := range b {
+			emit('+', line)
+		}
+	} else {
+		// common[i][j] is the length of the longest common subsequence of
+		// a[i:] and b[j:]
+		common := make([][]int32, len(a)+1)
+		for i := range common {
+			common[i] = make([]int32, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					common[i][j] = common[i+1][j+1] + 1
+				} else {
+					common[i][j] = max(common[i+1][j], common[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(a) && j < len(b) {
+			switch {
+			case a[i] == b[j]:
+				emit(' ', a[i])
+				i++
+				j++
+			case common[i+1][j] >= common[i][j+1]:
+				emit('-', a[i])
+				i++
+			default:
+				emit('+', b[j])
+				j++
+			}
+		}
+		for ; i < len(a); i++ {
+			emit('-', a[i])
+		}
+		for ; j < len(b); j++ {
+			emit('+', b[j])
+		}
+	}
+	for _, line := range left[len(left)-suffix:] {
+		emit(' ', line)
+	}
+	return ops
+}
+
+// unifiedDiff formats the differences between two lists of lines as a
+// unified diff with compareContextLines lines of context
+func unifiedDiff(left, right []string, leftName, rightName string) []string {
+	ops := diffLines(left, right)
+	out := []string{"--- " + leftName, "+++ " + rightName}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// A hunk runs on while the next change is close enough for the
+		// context lines of both to touch
+		start := max(0, i-compareContextLines)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*compareContextLines {
+				break
+			}
+		}
+		stop := min(len(ops), end+compareContextLines)
+
+		leftCount, rightCount := 0, 0
+		for _, op := range ops[start:stop] {
+			if op.kind != '+' {
+				leftCount++
+			}
+			if op.kind != '-' {
+				rightCount++
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(ops[start].leftLine, leftCount), hunkRange(ops[start].rightLine, rightCount)))
+		for _, op := range ops[start:stop] {
+			out = append(out, string(op.kind)+op.text)
+		}
+		i = stop
+	}
+	return out
+}
+
+// hunkRange formats the line range of one side of a hunk; an empty range
+// names the line before it, as diff -u does
+func hunkRange(line, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return strconv.Itoa(line)
+	default:
+		return fmt.Sprintf("%d,%d", line, count)
+	}
+}
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Confirmation Prompts
+// This file implements `confirm "question" [within <duration>] [defaulting
+// to yes|no]`, which asks before a destructive step and stops the task when
+// the answer is no. When the timeout expires, or there is no terminal to
+// answer on, the declared default is taken and the decision is logged, so
+// unattended runs never wait forever. Ctrl-C cancels the prompt.
+
+// confirmAnswers reads answers from the terminal for one engine. A single
+// reader outlives each prompt, so a prompt that timed out does not swallow
+// the answer to the next one. The reader only reads a line when a prompt asks
+// for one, so it never takes input meant for the commands that run after.
+type confirmAnswers struct {
+	mu     sync.Mutex // one prompt at a time, even from parallel tasks
+	input  io.Reader
+	once   sync.Once
+	wanted chan struct{} // asks the reader for the next line
+	asked  bool          // a line was asked for and not yet received
+	lines  chan string   // closed when the input ends
+}
+
+// next returns the channel the next answer arrives on, starting the reader on
+// first use. Call received once a line is taken from it.
+func (a *confirmAnswers) next() <-chan string {
+	a.once.Do(func() {
+		a.wanted = make(chan struct{}, 1)
+		a.lines = make(chan string, 1)
+		go func() {
+			scanner := bufio.NewScanner(a.input)
+			for range a.wanted {
+				if !scanner.Scan() {
+					close(a.lines)
+					return
+				}
+				a.lines <- scanner.Text()
+			}
+		}()
+	})
+	if !a.asked {
+		a.asked = true
+		a.wanted <- struct{}{}
+	}
+	return a.lines
+}
+
+// received records that the line asked for by next was taken; once the input
+// has ended nothing more is asked for
+func (a *confirmAnswers) received() {
+	a.asked = false
+}
+
+// executeConfirm asks the statement's question and fails when it is not
+// confirmed
+func (e *Engine) executeConfirm(stmt *statement.Confirm, ctx *ExecutionContext) error {
+	question := e.interpolateVariables(stmt.Question, ctx)
+	var timeout time.Duration
+	if stmt.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(stmt.Timeout); err != nil {
+			return fmt.Errorf("confirm %q: invalid timeout %q: %w", question, stmt.Timeout, err)
+		}
+	}
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would ask: %s %s\n", question, confirmChoices(stmt))
+		return nil
+	}
+
+	e.answers.mu.Lock()
+	defer e.answers.mu.Unlock()
+
+	if !stdinIsTerminal() {
+		if stmt.Default == "" {
+			return fmt.Errorf("confirm %q: there is no terminal to answer on; add 'defaulting to yes' or 'defaulting to no' for unattended runs", question)
+		}
+		e.iconf(ctx, "🤖  ", "No terminal to answer %q; taking the default: %s\n", question, stmt.Default)
+		return confirmDecision(question, stmt.Default)
+	}
+
+	_, _ = fmt.Fprintf(e.out(ctx), "%s%s %s ", e.style(ctx).theme.Icon("❓  "), question, confirmChoices(stmt))
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case line, ok := <-e.answers.next():
+			if !ok {
+				answer := defaultAnswer(stmt)
+				_, _ = fmt.Fprintln(e.out(ctx))
+				e.iconf(ctx, "🤖  ", "No answer to %q; taking the default: %s\n", question, answer)
+				return confirmDecision(question, answer)
+			}
+			e.answers.received()
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "y", "yes":
+				return nil
+			case "n", "no":
+				return confirmDecision(question, "no")
+			case "":
+				return confirmDecision(question, defaultAnswer(stmt))
+			}
+			_, _ = fmt.Fprint(e.out(ctx), "Please answer yes or no: ")
+		case <-expired:
+			_, _ = fmt.Fprintln(e.out(ctx))
+			e.iconf(ctx, "⏱️  ", "No answer to %q within %s; taking the default: %s\n", question, stmt.Timeout, stmt.Default)
+			return confirmDecision(question, stmt.Default)
+		case <-interrupts:
+			_, _ = fmt.Fprintln(e.out(ctx))
+			return fmt.Errorf("confirm %q: cancelled", question)
+		}
+	}
+}
+
+// confirmChoices renders the choices of a prompt, the default in capitals,
+// as in [y/N] or [y/N, no in 30s]
+func confirmChoices(stmt *statement.Confirm) string {
+	choices := "y/n"
+	switch stmt.Default {
+	case "yes":
+		choices = "Y/n"
+	case "no":
+		choices = "y/N"
+	}
+	if stmt.Timeout != "" {
+		choices += fmt.Sprintf(", %s in %s", stmt.Default, stmt.Timeout)
+	}
+	return "[" + choices + "]"
+}
+
+// defaultAnswer is the answer an empty reply or the end of the input takes;
+// without a declared default it is no
+func defaultAnswer(stmt *statement.Confirm) string {
+	if stmt.Default == "" {
+		return "no"
+	}
+	return stmt.Default
+}
+
+// confirmDecision fails when the answer is no
+func confirmDecision(question, answer string) error {
+	if answer == "yes" {
+		return nil
+	}
+	return fmt.Errorf("not confirmed: %s", question)
+}
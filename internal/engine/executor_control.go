@@ -2,7 +2,9 @@ package engine
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/parallel"
@@ -13,7 +15,7 @@ import (
 // This file contains executors for:
 // - Break/Continue control flow
 // - Conditional statements (when/otherwise)
-// - Loop statements (for each, range, line, match, parallel)
+// - Loop statements (for each, range, line, match, parallel, while, poll)
 // - Loop filtering and context management
 
 // BreakError represents a break statement execution
@@ -32,9 +34,9 @@ func (e *Engine) executeBreak(breakStmt *statement.Break, ctx *ExecutionContext)
 
 	if e.dryRun {
 		if condition != "" {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would break when: %s\n", condition)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would break when: %s\n", condition)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would break\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would break\n")
 		}
 		return BreakError{Condition: condition}
 	}
@@ -42,13 +44,13 @@ func (e *Engine) executeBreak(breakStmt *statement.Break, ctx *ExecutionContext)
 	if condition != "" {
 		// Evaluate the condition
 		if e.evaluateSimpleCondition(condition, ctx) {
-			_, _ = fmt.Fprintf(e.output, "🔄  Breaking loop (condition: %s)\n", condition)
+			e.iconf(ctx, "🔄  ", "Breaking loop (condition: %s)\n", condition)
 			return BreakError{Condition: condition}
 		}
 		// Condition not met, don't break
 		return nil
 	} else {
-		_, _ = fmt.Fprintf(e.output, "🔄  Breaking loop\n")
+		e.iconf(ctx, "🔄  ", "Breaking loop\n")
 		return BreakError{Condition: condition}
 	}
 }
@@ -59,9 +61,9 @@ func (e *Engine) executeContinue(continueStmt *statement.Continue, ctx *Executio
 
 	if e.dryRun {
 		if condition != "" {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would continue if: %s\n", condition)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would continue if: %s\n", condition)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would continue\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would continue\n")
 		}
 		return ContinueError{Condition: condition}
 	}
@@ -69,13 +71,13 @@ func (e *Engine) executeContinue(continueStmt *statement.Continue, ctx *Executio
 	if condition != "" {
 		// Evaluate the condition
 		if e.evaluateSimpleCondition(condition, ctx) {
-			_, _ = fmt.Fprintf(e.output, "🔄  Continuing loop (condition: %s)\n", condition)
+			e.iconf(ctx, "🔄  ", "Continuing loop (condition: %s)\n", condition)
 			return ContinueError{Condition: condition}
 		}
 		// Condition not met, don't continue
 		return nil
 	} else {
-		_, _ = fmt.Fprintf(e.output, "🔄  Continuing loop\n")
+		e.iconf(ctx, "🔄  ", "Continuing loop\n")
 		return ContinueError{Condition: condition}
 	}
 }
@@ -88,36 +90,32 @@ func (e *Engine) evaluateSimpleCondition(condition string, ctx *ExecutionContext
 	return true
 }
 
+// checkConditionVariables reports undefined variables in a condition in
+// strict mode, both bare $var references and {var} interpolations
+func (e *Engine) checkConditionVariables(condition string, ctx *ExecutionContext) error {
+	if !e.interpolator.IsStrictMode() {
+		return nil
+	}
+	// Check for undefined variables in {var} interpolations
+	if _, err := e.interpolateVariablesWithError(condition, ctx); err != nil {
+		return err
+	}
+	// Check for undefined bare $var references (e.g., "when $var is value")
+	return e.checkConditionForUndefinedVars(condition, ctx)
+}
+
 func (e *Engine) executeConditional(stmt *statement.Conditional, ctx *ExecutionContext) error {
-	// In strict mode, check for undefined variables in the condition
-	// This checks both bare $var references and {var} interpolations
-	if e.interpolator.IsStrictMode() {
-		// Check for undefined variables in {var} interpolations
-		if _, err := e.interpolateVariablesWithError(stmt.Condition, ctx); err != nil {
-			return fmt.Errorf("in %s condition: %w", stmt.ConditionType, err)
-		}
-		// Check for undefined bare $var references (e.g., "when $var is value")
-		if err := e.checkConditionForUndefinedVars(stmt.Condition, ctx); err != nil {
-			return fmt.Errorf("in %s condition: %w", stmt.ConditionType, err)
-		}
+	if err := e.checkConditionVariables(stmt.Condition, ctx); err != nil {
+		return fmt.Errorf("in %s condition: %w", stmt.ConditionType, err)
 	}
 
 	// File comparisons are exact byte comparisons and may return actionable I/O
 	// errors. Version-aware conditions use numeric MAJOR.MINOR.PATCH ordering.
 	// Other condition families continue through the general evaluator.
-	conditionResult, handled, err := e.evaluateFileComparisonCondition(stmt.Condition, ctx)
+	conditionResult, err := e.resolveCondition(stmt.Condition, ctx)
 	if err != nil {
 		return fmt.Errorf("in %s condition: %w", stmt.ConditionType, err)
 	}
-	if !handled {
-		conditionResult, handled, err = e.evaluateSemanticVersionCondition(stmt.Condition, ctx)
-		if err != nil {
-			return fmt.Errorf("in %s condition: %w", stmt.ConditionType, err)
-		}
-	}
-	if !handled {
-		conditionResult = e.evaluateCondition(stmt.Condition, ctx)
-	}
 
 	if conditionResult {
 		// Execute the main body (domain statements)
@@ -138,6 +136,30 @@ func (e *Engine) executeConditional(stmt *statement.Conditional, ctx *ExecutionC
 	return nil
 }
 
+// executeGuarded runs a statement with a trailing only when / unless
+// condition, evaluated with the same rules as if and when
+func (e *Engine) executeGuarded(stmt *statement.Guarded, ctx *ExecutionContext) error {
+	keyword := "only when"
+	if stmt.Negate {
+		keyword = "unless"
+	}
+	if err := e.checkConditionVariables(stmt.Condition, ctx); err != nil {
+		return fmt.Errorf("in %s condition: %w", keyword, err)
+	}
+	holds, err := e.resolveCondition(stmt.Condition, ctx)
+	if err != nil {
+		return fmt.Errorf("in %s condition: %w", keyword, err)
+	}
+
+	if holds == stmt.Negate {
+		if e.verbose {
+			e.iconf(ctx, "⏭️  ", "Skipping statement (%s %s)\n", keyword, stmt.Condition)
+		}
+		return nil
+	}
+	return e.executeStatement(stmt.Statement, ctx)
+}
+
 // executeLoop executes loop statements (for each)
 func (e *Engine) executeLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
 	// If LoopType is not set, default to "each"
@@ -153,6 +175,16 @@ func (e *Engine) executeLoop(stmt *statement.Loop, ctx *ExecutionContext) error
 		return e.executeLineLoop(stmt, ctx)
 	case "match":
 		return e.executeMatchLoop(stmt, ctx)
+	case "http":
+		return e.executeHTTPLoop(stmt, ctx)
+	case "while":
+		return e.executeWhileLoop(stmt, ctx)
+	case "poll":
+		return e.executePollLoop(stmt, ctx)
+	case "chunk":
+		return e.executeChunkLoop(stmt, ctx)
+	case "stdin":
+		return e.executeStdinLoop(stmt, ctx)
 	default: // "each"
 		return e.executeEachLoop(stmt, ctx)
 	}
@@ -161,65 +193,98 @@ func (e *Engine) executeLoop(stmt *statement.Loop, ctx *ExecutionContext) error
 // executeSequentialLoop executes loop items sequentially
 func (e *Engine) executeSequentialLoop(stmt *statement.Loop, items []string, ctx *ExecutionContext) error {
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "🔄  Executing %d items sequentially\n", len(items))
+		e.iconf(ctx, "🔄  ", "Executing %d items sequentially\n", len(items))
 	}
 
 	for i, item := range items {
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "📋 Processing item %d/%d: %s\n", i+1, len(items), item)
+			e.iconf(ctx, "📋 ", "Processing item %d/%d: %s\n", i+1, len(items), item)
 		}
 
-		// Create a new context with the loop variable
-		loopCtx := e.createLoopContext(ctx, stmt.Variable, item)
-
-		// Execute the loop body (domain statements)
-		for _, bodyStmt := range stmt.Body {
-			if err := e.executeStatement(bodyStmt, loopCtx); err != nil {
-				// Check for break/continue control flow
-				if breakErr, ok := err.(BreakError); ok {
-					if e.verbose {
-						_, _ = fmt.Fprintf(e.output, "🔄  Breaking loop: %s\n", breakErr.Error())
-					}
-					return nil // Break out of the entire loop
-				}
-				if continueErr, ok := err.(ContinueError); ok {
-					if e.verbose {
-						_, _ = fmt.Fprintf(e.output, "🔄  Continuing loop: %s\n", continueErr.Error())
-					}
-					break // Break out of the body execution, continue to next item
-				}
-				return fmt.Errorf("error processing item '%s': %v", item, err)
-			}
+		done, err := e.executeLoopItem(stmt, item, ctx)
+		if err != nil || done {
+			return err
 		}
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "✅  Sequential loop completed: %d items processed\n", len(items))
+		e.iconf(ctx, "✅  ", "Sequential loop completed: %d items processed\n", len(items))
 	}
 	return nil
 }
 
+// executeLoopItem runs the loop body for one item; done is set when the
+// body breaks out of the loop
+func (e *Engine) executeLoopItem(stmt *statement.Loop, item string, ctx *ExecutionContext) (done bool, err error) {
+	// Create a new context with the loop variable
+	loopCtx := e.createLoopContext(ctx, stmt.Variable, item)
+	if stmt.LoopType == "chunk" {
+		e.bindChunkFiles(loopCtx, stmt.Variable, item)
+	}
+
+	// Execute the loop body (domain statements)
+	for _, bodyStmt := range stmt.Body {
+		if err := e.executeStatement(bodyStmt, loopCtx); err != nil {
+			// Check for break/continue control flow
+			if breakErr, ok := err.(BreakError); ok {
+				if e.verbose {
+					e.iconf(ctx, "🔄  ", "Breaking loop: %s\n", breakErr.Error())
+				}
+				return true, nil // Break out of the entire loop
+			}
+			if continueErr, ok := err.(ContinueError); ok {
+				if e.verbose {
+					e.iconf(ctx, "🔄  ", "Continuing loop: %s\n", continueErr.Error())
+				}
+				return false, nil // Skip the rest of the body, continue to next item
+			}
+			return false, fmt.Errorf("error processing item '%s': %w", item, err)
+		}
+	}
+	return false, nil
+}
+
 // executeParallelLoop executes loop items in parallel
 func (e *Engine) executeParallelLoop(stmt *statement.Loop, items []string, ctx *ExecutionContext) error {
 	// Determine parallel execution settings
 	maxWorkers := stmt.MaxWorkers
 	if maxWorkers <= 0 {
-		maxWorkers = 5 // reasonable default
+		maxWorkers = e.defaultParallelism
 	}
 
 	failFast := stmt.FailFast
+	items = e.replayLoopItems(stmt, items, ctx)
+
+	// In a task that declares its needs, every item needs as much as the
+	// task: the task's reservation is handed to the items while they run
+	held := ctx.Resources
+	if held != nil {
+		pool := e.resources()
+		if fit := pool.fits(*held); fit > 0 && fit < maxWorkers {
+			maxWorkers = fit
+		}
+		if !e.dryRun {
+			pool.release(*held)
+			defer pool.acquire(*held)
+		}
+	}
 
 	// Create parallel executor
-	executor := parallel.NewParallelExecutor(maxWorkers, failFast, e.output, e.dryRun, e.verbose)
+	executor := parallel.NewParallelExecutor(maxWorkers, failFast, e.out(ctx), e.dryRun, e.verbose)
 
 	// Define the execution function for each item (domain statements)
 	executeItem := func(body []statement.Statement, variables map[string]string) error {
+		if held != nil {
+			e.resources().acquire(*held)
+			defer e.resources().release(*held)
+		}
+
 		// Create a new context for this parallel execution
 		loopCtx := &ExecutionContext{
 			Parameters: make(map[string]*types.Value, len(ctx.Parameters)+len(variables)), // Pre-allocate for parent + new variables
 			Variables:  make(map[string]string, len(ctx.Variables)+len(variables)),        // Pre-allocate for parent + new variables
-			Project:    ctx.Project,                                                       // inherit project context
 		}
+		loopCtx.inheritExecution(ctx)
 
 		// Copy existing parameters and variables
 		for k, v := range ctx.Parameters {
@@ -238,6 +303,10 @@ func (e *Engine) executeParallelLoop(stmt *statement.Loop, items []string, ctx *
 			}
 		}
 
+		if stmt.LoopType == "chunk" {
+			e.bindChunkFiles(loopCtx, stmt.Variable, variables[stmt.Variable])
+		}
+
 		// Execute the loop body (domain statements)
 		for _, bodyStmt := range body {
 			if err := e.executeStatement(bodyStmt, loopCtx); err != nil {
@@ -250,6 +319,9 @@ func (e *Engine) executeParallelLoop(stmt *statement.Loop, items []string, ctx *
 
 	// Execute in parallel
 	results, err := executor.ExecuteLoop(items, stmt.Variable, stmt.Body, executeItem)
+	if ctx.LoopItems != nil && !e.dryRun {
+		ctx.LoopItems.add(loopKey(ctx, stmt), failedLoopItems(items, results))
+	}
 
 	// Report results
 	if err != nil {
@@ -262,7 +334,7 @@ func (e *Engine) executeParallelLoop(stmt *statement.Loop, items []string, ctx *
 		}
 
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "⚠️  Parallel loop completed with errors: %d/%d successful\n",
+			e.iconf(ctx, "⚠️  ", "Parallel loop completed with errors: %d/%d successful\n",
 				successCount, len(items))
 		}
 		return err
@@ -293,11 +365,11 @@ func (e *Engine) executeRangeLoop(stmt *statement.Loop, ctx *ExecutionContext) e
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute range loop from %s to %s step %s (%d items)\n", start, end, step, len(items))
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute range loop from %s to %s step %s (%d items)\n", start, end, step, len(items))
 		return nil
 	}
 
-	_, _ = fmt.Fprintf(e.output, "🔄  Executing range loop from %s to %s step %s (%d items)\n", start, end, step, len(items))
+	e.iconf(ctx, "🔄  ", "Executing range loop from %s to %s step %s (%d items)\n", start, end, step, len(items))
 
 	// Apply filter if present
 	if stmt.Filter != nil {
@@ -311,31 +383,156 @@ func (e *Engine) executeRangeLoop(stmt *statement.Loop, ctx *ExecutionContext) e
 	return e.executeSequentialLoop(stmt, items, ctx)
 }
 
-// executeLineLoop executes line-by-line file processing loops
-func (e *Engine) executeLineLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
-	filename := e.interpolateVariables(stmt.Iterable, ctx)
+// defaultMaxWhileIterations stops while loops whose condition never becomes
+// false; projects change it with set max_while_iterations to N, and single
+// loops with "at most N times"
+const defaultMaxWhileIterations = 10000
+
+// executeWhileLoop runs the body in the current scope, so assignments in the
+// body are seen by the next condition check, until the condition is false
+func (e *Engine) executeWhileLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
+	limit, err := e.whileIterationLimit(stmt, ctx)
+	if err != nil {
+		return err
+	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would read lines from file: %s\n", filename)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would loop while %s (at most %d iterations)\n", stmt.Condition, limit)
 		return nil
 	}
 
-	// In a real implementation, you would read the file
-	// For now, we'll simulate with some sample lines
-	lines := []string{"line1", "line2", "line3"}
+	description := "while " + stmt.Condition
+	if ctx.CurrentTask != "" {
+		description += " in task '" + ctx.CurrentTask + "'"
+	}
+	progress, stop := ctx.Monitor.TrackLoop(description)
+	defer stop()
 
-	_, _ = fmt.Fprintf(e.output, "📄 Reading lines from file: %s (%d lines)\n", filename, len(lines))
+	for {
+		if err := e.checkConditionVariables(stmt.Condition, ctx); err != nil {
+			return fmt.Errorf("in while condition: %w", err)
+		}
+		holds, err := e.resolveCondition(stmt.Condition, ctx)
+		if err != nil {
+			return fmt.Errorf("in while condition: %w", err)
+		}
+		if !holds {
+			break
+		}
+		if progress.Iterations() >= int64(limit) {
+			return fmt.Errorf("while loop stopped after %d iterations: %s is still true (raise the limit with 'at most N times' or set max_while_iterations)", limit, stmt.Condition)
+		}
+		progress.Iterate()
+		if e.verbose {
+			e.iconf(ctx, "🔄  ", "While iteration %d: %s\n", progress.Iterations(), stmt.Condition)
+		}
 
-	// Apply filter if present
-	if stmt.Filter != nil {
-		lines = e.applyFilter(lines, stmt.Filter, ctx)
+		if err := e.executeLoopBody(stmt.Body, ctx); err != nil {
+			if _, ok := err.(BreakError); ok {
+				break
+			}
+			return fmt.Errorf("while loop iteration %d: %w", progress.Iterations(), err)
+		}
 	}
 
-	// Execute loop
-	if stmt.Parallel {
-		return e.executeParallelLoop(stmt, lines, ctx)
+	if e.verbose {
+		e.iconf(ctx, "✅  ", "While loop completed after %d iterations\n", progress.Iterations())
+	}
+	return nil
+}
+
+// executePollLoop runs the body, then checks the until condition, waiting
+// the poll interval between attempts until the condition holds or the time
+// limit is spent
+func (e *Engine) executePollLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
+	interval, err := time.ParseDuration(stmt.PollInterval)
+	if err != nil {
+		return fmt.Errorf("invalid poll interval %q: %v", stmt.PollInterval, err)
+	}
+	timeout, err := time.ParseDuration(stmt.PollTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid poll time limit %q: %v", stmt.PollTimeout, err)
+	}
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would poll every %s for up to %s until %s\n", interval, timeout, stmt.Condition)
+		return nil
+	}
+
+	description := "poll until " + stmt.Condition
+	if ctx.CurrentTask != "" {
+		description += " in task '" + ctx.CurrentTask + "'"
+	}
+	progress, stop := ctx.Monitor.TrackLoop(description)
+	defer stop()
+
+	start := time.Now()
+	for {
+		progress.Iterate()
+		if err := e.executeLoopBody(stmt.Body, ctx); err != nil {
+			if _, ok := err.(BreakError); ok {
+				return nil
+			}
+			return fmt.Errorf("poll attempt %d: %w", progress.Iterations(), err)
+		}
+
+		if err := e.checkConditionVariables(stmt.Condition, ctx); err != nil {
+			return fmt.Errorf("in until condition: %w", err)
+		}
+		met, err := e.resolveCondition(stmt.Condition, ctx)
+		if err != nil {
+			return fmt.Errorf("in until condition: %w", err)
+		}
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if met {
+			if e.verbose {
+				e.iconf(ctx, "✅  ", "Poll succeeded after %d attempts (%s): %s\n", progress.Iterations(), elapsed, stmt.Condition)
+			}
+			return nil
+		}
+		if elapsed+interval > timeout {
+			return fmt.Errorf("poll gave up after %s (%d attempts): %s never became true", elapsed, progress.Iterations(), stmt.Condition)
+		}
+		if err := e.takeRetry(ctx, "poll until "+stmt.Condition); err != nil {
+			return fmt.Errorf("poll gave up after %d attempts: %w", progress.Iterations(), err)
+		}
+		if e.verbose {
+			e.iconf(ctx, "⏳  ", "Poll attempt %d: %s is not true yet, retrying in %s\n", progress.Iterations(), stmt.Condition, interval)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// executeLoopBody runs one iteration of a loop body; continue ends the
+// iteration early and break is returned to the loop
+func (e *Engine) executeLoopBody(body []statement.Statement, ctx *ExecutionContext) error {
+	for _, bodyStmt := range body {
+		if err := e.executeStatement(bodyStmt, ctx); err != nil {
+			if _, ok := err.(ContinueError); ok {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// whileIterationLimit returns the loop's own limit, the project's
+// max_while_iterations setting or the default
+func (e *Engine) whileIterationLimit(stmt *statement.Loop, ctx *ExecutionContext) (int, error) {
+	if stmt.MaxIterations > 0 {
+		return stmt.MaxIterations, nil
+	}
+	if ctx.Project != nil {
+		if value, ok := ctx.Project.Settings["max_while_iterations"]; ok {
+			limit, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || limit < 1 {
+				return 0, fmt.Errorf("max_while_iterations must be a positive number, got %q", value)
+			}
+			return limit, nil
+		}
 	}
-	return e.executeSequentialLoop(stmt, lines, ctx)
+	return defaultMaxWhileIterations, nil
 }
 
 // executeMatchLoop executes pattern matching loops
@@ -343,7 +540,7 @@ func (e *Engine) executeMatchLoop(stmt *statement.Loop, ctx *ExecutionContext) e
 	pattern := e.interpolateVariables(stmt.Iterable, ctx)
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would find matches for pattern: %s\n", pattern)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would find matches for pattern: %s\n", pattern)
 		return nil
 	}
 
@@ -351,7 +548,7 @@ func (e *Engine) executeMatchLoop(stmt *statement.Loop, ctx *ExecutionContext) e
 	// For now, we'll simulate with some sample matches
 	matches := []string{"match1", "match2"}
 
-	_, _ = fmt.Fprintf(e.output, "🔍  Finding matches for pattern: %s (%d matches)\n", pattern, len(matches))
+	e.iconf(ctx, "🔍  ", "Finding matches for pattern: %s (%d matches)\n", pattern, len(matches))
 
 	// Apply filter if present
 	if stmt.Filter != nil {
@@ -387,7 +584,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 					// It's a regular string, split by whitespace
 					iterableStr := strings.TrimSpace(projectValue)
 					if iterableStr == "" {
-						_, _ = fmt.Fprintf(e.output, "ℹ️  No items to process in loop\n")
+						e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
 						return nil
 					}
 					items = strings.Fields(iterableStr)
@@ -416,7 +613,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 		// Check if it's an array literal or a space-separated list
 		iterableStr = strings.TrimSpace(iterableStr)
 		if iterableStr == "" {
-			_, _ = fmt.Fprintf(e.output, "ℹ️  No items to process in loop\n")
+			e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
 			return nil
 		}
 
@@ -431,7 +628,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 		if ctx.Project != nil && ctx.Project.Settings != nil {
 			if projectValue, exists := ctx.Project.Settings[stmt.Iterable]; exists {
 				// Handle project setting (could be array or string) - but warn about deprecated usage
-				_, _ = fmt.Fprintf(e.output, "⚠️  Warning: Direct project setting access '%s' is deprecated. Use '$globals.%s' instead.\n", stmt.Iterable, stmt.Iterable)
+				e.iconf(ctx, "⚠️  ", "Warning: Direct project setting access '%s' is deprecated. Use '$globals.%s' instead.\n", stmt.Iterable, stmt.Iterable)
 				if strings.HasPrefix(projectValue, "[") && strings.HasSuffix(projectValue, "]") {
 					// It's an array literal stored as a string
 					items = e.parseArrayLiteralString(projectValue)
@@ -439,7 +636,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 					// It's a regular string, split by whitespace
 					iterableStr := strings.TrimSpace(projectValue)
 					if iterableStr == "" {
-						_, _ = fmt.Fprintf(e.output, "ℹ️  No items to process in loop\n")
+						e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
 						return nil
 					}
 					items = strings.Fields(iterableStr)
@@ -455,7 +652,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 				// Split by space to get items (for our variable operations system)
 				iterableStr = strings.TrimSpace(iterableStr)
 				if iterableStr == "" {
-					_, _ = fmt.Fprintf(e.output, "ℹ️  No items to process in loop\n")
+					e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
 					return nil
 				}
 
@@ -472,7 +669,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 			// Split by space to get items (for our variable operations system)
 			iterableStr = strings.TrimSpace(iterableStr)
 			if iterableStr == "" {
-				_, _ = fmt.Fprintf(e.output, "ℹ️  No items to process in loop\n")
+				e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
 				return nil
 			}
 
@@ -481,7 +678,7 @@ func (e *Engine) executeEachLoop(stmt *statement.Loop, ctx *ExecutionContext) er
 	}
 
 	if len(items) == 0 {
-		_, _ = fmt.Fprintf(e.output, "ℹ️  No items to process in loop\n")
+		e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
 		return nil
 	}
 
@@ -533,7 +730,7 @@ func (e *Engine) applyFilter(items []string, filter *statement.Filter, ctx *Exec
 	}
 
 	if len(filtered) != len(items) {
-		_, _ = fmt.Fprintf(e.output, "🔍  Filter applied: %d items match condition '%s %s %s'\n",
+		e.iconf(ctx, "🔍  ", "Filter applied: %d items match condition '%s %s %s'\n",
 			len(filtered), filter.Variable, filter.Operator, filterValue)
 	}
 
@@ -545,8 +742,8 @@ func (e *Engine) createLoopContext(ctx *ExecutionContext, variable, value string
 	loopCtx := &ExecutionContext{
 		Parameters: make(map[string]*types.Value, len(ctx.Parameters)+1), // Pre-allocate for parent + loop variable
 		Variables:  make(map[string]string, len(ctx.Variables)+1),        // Pre-allocate for parent + loop variable
-		Project:    ctx.Project,                                          // inherit project context
 	}
+	loopCtx.inheritExecution(ctx)
 
 	// Copy existing parameters and variables
 	for k, v := range ctx.Parameters {
@@ -559,7 +756,7 @@ func (e *Engine) createLoopContext(ctx *ExecutionContext, variable, value string
 	// Set the loop variable as a string type
 	itemValue, _ := types.NewValue(types.StringType, value)
 	loopCtx.Parameters[variable] = itemValue
-	loopCtx.Variables[variable] = value
+	e.assignVariable(loopCtx, variable, value, "for loop")
 
 	return loopCtx
 }
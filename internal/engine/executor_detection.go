@@ -21,6 +21,8 @@ func (e *Engine) executeDetection(detectionStmt *statement.Detection, ctx *Execu
 		return e.executeDetectOperation(detector, detectionStmt, ctx)
 	case "detect_available":
 		return e.executeDetectAvailable(detector, detectionStmt, ctx)
+	case "detect_environment":
+		return e.executeDetectEnvironment(detectionStmt, ctx)
 	case "if_available":
 		return e.executeIfAvailable(detector, detectionStmt, ctx)
 	case "if_version":
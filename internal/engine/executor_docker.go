@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
@@ -42,11 +43,26 @@ func (e *Engine) executeDocker(dockerStmt *statement.Docker, ctx *ExecutionConte
 		return fmt.Errorf("unable to build docker command for operation '%s'", operation)
 	}
 
+	// Pushes to a registry with a credential helper log in first
+	var registryHost string
+	if operation == "push" {
+		host := imageRegistryHost(name)
+		if to := options["to"]; to != "" {
+			host, _, _ = strings.Cut(strings.ToLower(to), "/")
+		}
+		if hasCredentialHelper(host, ctx) {
+			registryHost = host
+		}
+	}
+
 	if e.dryRun {
+		if registryHost != "" {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would log in to %s with its credential helper\n", registryHost)
+		}
 		if svcCtx != nil {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute Docker command in service '%s' (%s): %s\n", svcCtx.Name, svcCtx.Path, commandStr)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute Docker command in service '%s' (%s): %s\n", svcCtx.Name, svcCtx.Path, commandStr)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute Docker command: %s\n", commandStr)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute Docker command: %s\n", commandStr)
 		}
 		return nil
 	}
@@ -54,94 +70,116 @@ func (e *Engine) executeDocker(dockerStmt *statement.Docker, ctx *ExecutionConte
 	// Show what we're about to do with appropriate emoji
 	switch operation {
 	case "build":
-		_, _ = fmt.Fprintf(e.output, "🔨  Building Docker image")
+		e.iconf(ctx, "🔨  ", "Building Docker image")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "push":
-		_, _ = fmt.Fprintf(e.output, "📤 Pushing Docker image")
+		e.iconf(ctx, "📤 ", "Pushing Docker image")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
 		if registry, exists := options["to"]; exists {
-			_, _ = fmt.Fprintf(e.output, " to %s", registry)
+			_, _ = fmt.Fprintf(e.out(ctx), " to %s", registry)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "pull":
-		_, _ = fmt.Fprintf(e.output, "📥  Pulling Docker image")
+		e.iconf(ctx, "📥  ", "Pulling Docker image")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "run":
-		_, _ = fmt.Fprintf(e.output, "🚀  Running Docker container")
+		e.iconf(ctx, "🚀  ", "Running Docker container")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
 		if port, exists := options["port"]; exists {
-			_, _ = fmt.Fprintf(e.output, " on port %s", port)
+			_, _ = fmt.Fprintf(e.out(ctx), " on port %s", port)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "stop":
-		_, _ = fmt.Fprintf(e.output, "🛑  Stopping Docker container")
+		e.iconf(ctx, "🛑  ", "Stopping Docker container")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "remove":
-		_, _ = fmt.Fprintf(e.output, "🗑️  Removing Docker %s", resource)
+		e.iconf(ctx, "🗑️  ", "Removing Docker %s", resource)
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "compose":
 		command := options["command"]
 		switch command {
 		case "up":
-			_, _ = fmt.Fprintf(e.output, "🚀  Starting Docker Compose services\n")
+			e.iconf(ctx, "🚀  ", "Starting Docker Compose services\n")
 		case "down":
-			_, _ = fmt.Fprintf(e.output, "🛑  Stopping Docker Compose services\n")
+			e.iconf(ctx, "🛑  ", "Stopping Docker Compose services\n")
 		case "build":
-			_, _ = fmt.Fprintf(e.output, "🔨  Building Docker Compose services\n")
+			e.iconf(ctx, "🔨  ", "Building Docker Compose services\n")
 		default:
-			_, _ = fmt.Fprintf(e.output, "🐳 Running Docker Compose: %s\n", command)
+			e.iconf(ctx, "🐳 ", "Running Docker Compose: %s\n", command)
 		}
 	case "scale":
 		if resource == "compose" {
 			replicas := options["replicas"]
-			_, _ = fmt.Fprintf(e.output, "📊  Scaling Docker Compose service")
+			e.iconf(ctx, "📊  ", "Scaling Docker Compose service")
 			if name != "" {
-				_, _ = fmt.Fprintf(e.output, " %s", name)
+				_, _ = fmt.Fprintf(e.out(ctx), " %s", name)
 			}
 			if replicas != "" {
-				_, _ = fmt.Fprintf(e.output, " to %s replicas", replicas)
+				_, _ = fmt.Fprintf(e.out(ctx), " to %s replicas", replicas)
 			}
-			_, _ = fmt.Fprintf(e.output, "\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "\n")
 		}
 	default:
-		_, _ = fmt.Fprintf(e.output, "🐳 Running Docker %s", operation)
+		e.iconf(ctx, "🐳 ", "Running Docker %s", operation)
 		if resource != "" {
-			_, _ = fmt.Fprintf(e.output, " %s", resource)
+			_, _ = fmt.Fprintf(e.out(ctx), " %s", resource)
 		}
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, " %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), " %s", name)
+		}
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
+	}
+
+	// The login goes to a throwaway docker config so the credential is not
+	// stored in the user's ~/.docker
+	var loginEnv map[string]string
+	if registryHost != "" {
+		cred, err := e.credentialFor(registryHost, ctx)
+		if err != nil {
+			return err
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		configDir, err := os.MkdirTemp("", "drun-docker-config-*")
+		if err != nil {
+			return fmt.Errorf("failed to create docker config directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(configDir) }()
+
+		loginEnv = map[string]string{"DOCKER_CONFIG": configDir, "DRUN_REGISTRY_PASSWORD": cred.Secret}
+		commandStr = fmt.Sprintf(`printf '%%s' "$DRUN_REGISTRY_PASSWORD" | docker login %s --username %s --password-stdin && %s`,
+			shellQuote(registryHost), shellQuote(cred.user()), commandStr)
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "Command: %s\n", commandStr)
+		_, _ = fmt.Fprintf(e.out(ctx), "Command: %s\n", commandStr)
 	}
 
 	if svcCtx != nil {
 		opts := e.getPlatformShellConfig(ctx)
 		opts.StreamOutput = true
-		opts.Output = e.output
+		opts.Output = e.out(ctx)
 		opts.WorkingDir = svcCtx.Path
+		for key, value := range loginEnv {
+			opts.Environment[key] = value
+		}
 
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "📁 Working directory: %s\n", svcCtx.Path)
+			e.iconf(ctx, "📁 ", "Working directory: %s\n", svcCtx.Path)
 		}
 
 		result, err := shell.Execute(commandStr, opts)
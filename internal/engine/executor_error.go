@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/shell"
 )
 
 // executeTry executes try/catch/finally blocks
@@ -13,33 +15,33 @@ func (e *Engine) executeTry(tryStmt *statement.Try, ctx *ExecutionContext) error
 	var finallyError error
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute try block\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute try block\n")
 
 		// Execute try body in dry run (domain statements)
 		for _, stmt := range tryStmt.TryBody {
 			if err := e.executeStatement(stmt, ctx); err != nil {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would catch error: %v\n", err)
+				_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would catch error: %v\n", err)
 				break
 			}
 		}
 
 		if len(tryStmt.CatchClauses) > 0 {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute catch blocks if needed\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute catch blocks if needed\n")
 		}
 
 		if len(tryStmt.FinallyBody) > 0 {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute finally block\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute finally block\n")
 		}
 
 		return nil
 	}
 
 	// Execute try block (domain statements)
-	_, _ = fmt.Fprintf(e.output, "🔄  Executing try block\n")
+	e.iconf(ctx, "🔄  ", "Executing try block\n")
 	for _, stmt := range tryStmt.TryBody {
 		if err := e.executeStatement(stmt, ctx); err != nil {
 			tryError = err
-			_, _ = fmt.Fprintf(e.output, "⚠️  Error in try block: %v\n", err)
+			e.iconf(ctx, "⚠️  ", "Error in try block: %v\n", err)
 			break
 		}
 	}
@@ -49,12 +51,12 @@ func (e *Engine) executeTry(tryStmt *statement.Try, ctx *ExecutionContext) error
 		handled := false
 		for _, catchClause := range tryStmt.CatchClauses {
 			if e.shouldHandleError(tryError, catchClause) {
-				_, _ = fmt.Fprintf(e.output, "🔧 Handling error with catch block\n")
+				e.iconf(ctx, "🔧 ", "Handling error with catch block\n")
 
 				// Set error variable if specified
 				if catchClause.ErrorVar != "" {
-					ctx.Variables[catchClause.ErrorVar] = tryError.Error()
-					_, _ = fmt.Fprintf(e.output, "📦  Captured error in variable '%s'\n", catchClause.ErrorVar)
+					e.assignVariable(ctx, catchClause.ErrorVar, tryError.Error(), "catch")
+					e.iconf(ctx, "📦  ", "Captured error in variable '%s'\n", catchClause.ErrorVar)
 				}
 
 				// Execute catch body (domain statements)
@@ -72,28 +74,31 @@ func (e *Engine) executeTry(tryStmt *statement.Try, ctx *ExecutionContext) error
 		}
 
 		if !handled {
-			_, _ = fmt.Fprintf(e.output, "❌  Unhandled error: %v\n", tryError)
+			e.iconf(ctx, "❌  ", "Unhandled error: %v\n", tryError)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "✅  Error handled successfully\n")
+			e.iconf(ctx, "✅  ", "Error handled successfully\n")
 			tryError = nil // Error was handled
 		}
 	} else {
-		_, _ = fmt.Fprintf(e.output, "✅  Try block completed successfully\n")
+		e.iconf(ctx, "✅  ", "Try block completed successfully\n")
 	}
 
-	// Always execute finally block (domain statements)
+	// Always execute finally block (domain statements), even past the
+	// run's maximum duration
 	if len(tryStmt.FinallyBody) > 0 {
-		_, _ = fmt.Fprintf(e.output, "🔄  Executing finally block\n")
+		e.iconf(ctx, "🔄  ", "Executing finally block\n")
+		endCleanup := ctx.Deadline.startCleanup()
 		for _, stmt := range tryStmt.FinallyBody {
 			if err := e.executeStatement(stmt, ctx); err != nil {
 				finallyError = err
-				_, _ = fmt.Fprintf(e.output, "⚠️  Error in finally block: %v\n", err)
+				e.iconf(ctx, "⚠️  ", "Error in finally block: %v\n", err)
 				break
 			}
 		}
+		endCleanup()
 
 		if finallyError == nil {
-			_, _ = fmt.Fprintf(e.output, "✅  Finally block completed successfully\n")
+			e.iconf(ctx, "✅  ", "Finally block completed successfully\n")
 		}
 	}
 
@@ -109,11 +114,11 @@ func (e *Engine) executeThrow(throwStmt *statement.Throw, ctx *ExecutionContext)
 	if e.dryRun {
 		switch throwStmt.Action {
 		case "throw":
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would throw error: %s\n", throwStmt.Message)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would throw error: %s\n", throwStmt.Message)
 		case "rethrow":
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would rethrow current error\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would rethrow current error\n")
 		case "ignore":
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would ignore current error\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would ignore current error\n")
 		}
 		return nil
 	}
@@ -121,14 +126,14 @@ func (e *Engine) executeThrow(throwStmt *statement.Throw, ctx *ExecutionContext)
 	switch throwStmt.Action {
 	case "throw":
 		message := e.interpolateVariables(throwStmt.Message, ctx)
-		_, _ = fmt.Fprintf(e.output, "💥  Throwing error: %s\n", message)
+		e.iconf(ctx, "💥  ", "Throwing error: %s\n", message)
 		return fmt.Errorf("thrown error: %s", message)
 	case "rethrow":
-		_, _ = fmt.Fprintf(e.output, "🔄  Rethrowing current error\n")
+		e.iconf(ctx, "🔄  ", "Rethrowing current error\n")
 		// In a real implementation, we'd need to track the current error context
 		return fmt.Errorf("rethrown error")
 	case "ignore":
-		_, _ = fmt.Fprintf(e.output, "🤐 Ignoring current error\n")
+		e.iconf(ctx, "🤐 ", "Ignoring current error\n")
 		return nil // Ignore effectively suppresses the error
 	default:
 		return fmt.Errorf("unknown throw action: %s", throwStmt.Action)
@@ -137,6 +142,12 @@ func (e *Engine) executeThrow(throwStmt *statement.Throw, ctx *ExecutionContext)
 
 // shouldHandleError checks if a catch clause should handle the given error
 func (e *Engine) shouldHandleError(err error, catchClause statement.CatchClause) bool {
+	// Ctrl+C and the run's maximum duration stop the run, and a skipping
+	// todo stops the task; catch blocks do not swallow them
+	if errors.Is(err, shell.ErrInterrupted) || isMaxDuration(err) || isTodoSkipped(err) {
+		return false
+	}
+
 	// If no specific error type is specified, catch all errors
 	if catchClause.ErrorType == "" {
 		return true
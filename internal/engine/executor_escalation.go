@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/engine/interpolation"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+	"golang.org/x/term"
+)
+
+// Domain: Privilege Escalation
+// This file contains support for shell statements run as another user:
+// - run "systemctl restart nginx" as root
+// - run "whoami" as user "deploy"
+// Commands go through sudo on Unix. The password is asked for once, before
+// the first escalated command, and sudo's cached authentication is reused
+// for the rest of the run. Windows cannot elevate a running process, so there
+// drun must already run from an elevated terminal.
+
+// escalationState tracks sudo authentication for one engine
+type escalationState struct {
+	mu       sync.Mutex
+	prompted bool // the password notice was shown; it is shown once per run
+}
+
+// stdinIsTerminal reports whether sudo can prompt for a password; tests replace it
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) // #nosec G115 -- file descriptors fit in int
+}
+
+// applyRunAs makes opts run the statement's command as its RunAs user,
+// authenticating with sudo first when drun does not already run as that user
+func (e *Engine) applyRunAs(opts *shell.Options, shellStmt *statement.Shell, ctx *ExecutionContext) error {
+	if shellStmt.RunAs == "" || alreadyRunningAs(shellStmt.RunAs) {
+		return nil
+	}
+	if opts.Container != nil {
+		return fmt.Errorf("cannot run as %s inside the task's container", shellStmt.RunAs)
+	}
+	if err := escalationUnavailable(shellStmt.RunAs); err != nil {
+		return err
+	}
+	if err := e.authenticateSudo(ctx); err != nil {
+		return err
+	}
+	opts.RunAs = shellStmt.RunAs
+	return nil
+}
+
+// authenticateSudo makes sure sudo can run commands without prompting. A
+// valid cached authentication is refreshed; otherwise the user is asked for
+// their password on the terminal.
+func (e *Engine) authenticateSudo(ctx *ExecutionContext) error {
+	e.escalation.mu.Lock()
+	defer e.escalation.mu.Unlock()
+
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return errors.New("running as another user needs sudo, which was not found on PATH")
+	}
+	if exec.Command("sudo", "-n", "-v").Run() == nil {
+		return nil
+	}
+	if !stdinIsTerminal() {
+		return errors.New("sudo needs a password but drun is not attached to a terminal; authenticate with 'sudo -v' first or allow the command without a password in sudoers")
+	}
+
+	if !e.escalation.prompted {
+		e.iconf(ctx, "🔐 ", "Some commands run with sudo; enter your password once for this run\n")
+		e.escalation.prompted = true
+	}
+	cmd := exec.Command("sudo", "-v")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo authentication failed: %w", err)
+	}
+	return nil
+}
+
+// writeEscalationDryRun reports the escalated command a dry-run shell
+// statement would execute
+func (e *Engine) writeEscalationDryRun(shellStmt *statement.Shell, command string, ctx *ExecutionContext) {
+	if shellStmt.RunAs == "" {
+		return
+	}
+	shellPath := e.getPlatformShellConfig(ctx).Shell
+	_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would run as %s: %s\n", shellStmt.RunAs, escalatedCommandLine(shellStmt.RunAs, shellPath, command))
+}
+
+// sudoCommandLine renders the sudo invocation of command for display
+func sudoCommandLine(user, shellPath, command string) string {
+	args := shell.SudoArgs(user, shellPath, command)
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = interpolation.ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
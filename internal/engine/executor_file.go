@@ -26,11 +26,11 @@ func (e *Engine) executeFile(fileStmt *statement.File, ctx *ExecutionContext) er
 		replacements[resolvedOld] = resolvedNew
 	}
 
-	// Create file operation
+	// Create file operation; relative paths resolve against the project root
 	op := &fileops.FileOperation{
 		Type:         fileStmt.Action,
-		Target:       target,
-		Source:       source,
+		Target:       e.resolveFilesystemPath(target, ctx),
+		Source:       e.resolveFilesystemPath(source, ctx),
 		Content:      content,
 		IsDir:        fileStmt.IsDir,
 		Replacements: replacements,
@@ -39,19 +39,19 @@ func (e *Engine) executeFile(fileStmt *statement.File, ctx *ExecutionContext) er
 	if e.dryRun {
 		result, err := op.Execute(true) // dry run
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "❌  File operation failed: %v\n", err)
+			e.iconf(ctx, "❌  ", "File operation failed: %v\n", err)
 			return err
 		}
-		_, _ = fmt.Fprintf(e.output, "📁 %s\n", result.Message)
+		e.iconf(ctx, "📁 ", "%s\n", result.Message)
 		if fileStmt.Action == "replace" && len(replacements) > 0 {
 			for oldValue, newValue := range replacements {
-				_, _ = fmt.Fprintf(e.output, "    - %s → %s\n", oldValue, newValue)
+				_, _ = fmt.Fprintf(e.out(ctx), "    - %s → %s\n", oldValue, newValue)
 			}
 		}
 		if fileStmt.CaptureVar != "" {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would capture file content in variable '%s'\n", fileStmt.CaptureVar)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture file content in variable '%s'\n", fileStmt.CaptureVar)
 			// Set a placeholder value for the captured variable in dry-run mode
-			ctx.Variables[fileStmt.CaptureVar] = "[DRY RUN] file content"
+			e.assignVariable(ctx, fileStmt.CaptureVar, "[DRY RUN] file content", "read file")
 		}
 		return nil
 	}
@@ -64,24 +64,24 @@ func (e *Engine) executeFile(fileStmt *statement.File, ctx *ExecutionContext) er
 			timestamp := time.Now().Format("2006-01-02-15-04-05")
 			target = source + ".backup-" + timestamp
 		}
-		op.Target = target
+		op.Target = e.resolveFilesystemPath(target, ctx)
 		op.Type = "copy" // Backup is essentially a copy operation
 	case "check_exists":
 		// Check if file exists
 		if e.fileExists(target, ctx) {
-			_, _ = fmt.Fprintf(e.output, "✅  File exists: %s\n", target)
+			e.iconf(ctx, "✅  ", "File exists: %s\n", target)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "❌  File does not exist: %s\n", target)
+			e.iconf(ctx, "❌  ", "File does not exist: %s\n", target)
 		}
 		return nil
 	case "get_size":
 		// Get file size
 		size, err := e.getFileSize(target, ctx)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "❌  Failed to get file size: %v\n", err)
+			e.iconf(ctx, "❌  ", "Failed to get file size: %v\n", err)
 			return err
 		}
-		_, _ = fmt.Fprintf(e.output, "📏 File size: %s (%d bytes)\n", target, size)
+		e.iconf(ctx, "📏 ", "File size: %s (%d bytes)\n", target, size)
 		return nil
 	}
 
@@ -89,56 +89,65 @@ func (e *Engine) executeFile(fileStmt *statement.File, ctx *ExecutionContext) er
 	switch fileStmt.Action {
 	case "create":
 		if fileStmt.IsDir {
-			_, _ = fmt.Fprintf(e.output, "📁 Creating directory: %s\n", target)
+			e.iconf(ctx, "📁 ", "Creating directory: %s\n", target)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "📄 Creating file: %s\n", target)
+			e.iconf(ctx, "📄 ", "Creating file: %s\n", target)
 		}
 	case "copy":
-		_, _ = fmt.Fprintf(e.output, "📋 Copying: %s → %s\n", source, target)
+		e.iconf(ctx, "📋 ", "Copying: %s → %s\n", source, target)
 	case "move":
-		_, _ = fmt.Fprintf(e.output, "🚚 Moving: %s → %s\n", source, target)
+		e.iconf(ctx, "🚚 ", "Moving: %s → %s\n", source, target)
 	case "delete":
 		if fileStmt.IsDir {
-			_, _ = fmt.Fprintf(e.output, "🗑️  Deleting directory: %s\n", target)
+			e.iconf(ctx, "🗑️  ", "Deleting directory: %s\n", target)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "🗑️  Deleting file: %s\n", target)
+			e.iconf(ctx, "🗑️  ", "Deleting file: %s\n", target)
 		}
 	case "read":
-		_, _ = fmt.Fprintf(e.output, "📖 Reading file: %s\n", target)
+		e.iconf(ctx, "📖 ", "Reading file: %s\n", target)
+		if fileStmt.CaptureVar != "" {
+			if err := e.warnLargeCapture(op.Target, target, fileStmt.CaptureVar, ctx); err != nil {
+				return err
+			}
+		}
 	case "write":
-		_, _ = fmt.Fprintf(e.output, "✏️  Writing to file: %s\n", target)
+		e.iconf(ctx, "✏️  ", "Writing to file: %s\n", target)
 	case "append":
-		_, _ = fmt.Fprintf(e.output, "➕ Appending to file: %s\n", target)
+		if source != "" {
+			e.iconf(ctx, "➕ ", "Appending: %s → %s\n", source, target)
+		} else {
+			e.iconf(ctx, "➕ ", "Appending to file: %s\n", target)
+		}
 	case "backup":
-		_, _ = fmt.Fprintf(e.output, "💾 Backing up: %s → %s\n", source, target)
+		e.iconf(ctx, "💾 ", "Backing up: %s → %s\n", source, target)
 	case "replace":
-		_, _ = fmt.Fprintf(e.output, "🔁  Replacing content in: %s\n", target)
+		e.iconf(ctx, "🔁  ", "Replacing content in: %s\n", target)
 	}
 
 	// Execute the file operation
 	result, err := op.Execute(false)
 	if err != nil {
-		_, _ = fmt.Fprintf(e.output, "❌  File operation failed: %v\n", err)
+		e.iconf(ctx, "❌  ", "File operation failed: %v\n", err)
 		return err
 	}
 
 	// Handle capture for read operations
 	if fileStmt.CaptureVar != "" && fileStmt.Action == "read" {
-		ctx.Variables[fileStmt.CaptureVar] = result.Content
-		_, _ = fmt.Fprintf(e.output, "📦  Captured file content in variable '%s' (%d bytes)\n",
+		e.assignVariable(ctx, fileStmt.CaptureVar, result.Content, "read file")
+		e.iconf(ctx, "📦  ", "Captured file content in variable '%s' (%d bytes)\n",
 			fileStmt.CaptureVar, len(result.Content))
 	}
 
 	// Show success message
 	if result.Success {
-		_, _ = fmt.Fprintf(e.output, "✅  %s\n", result.Message)
+		e.iconf(ctx, "✅  ", "%s\n", result.Message)
 	} else {
-		_, _ = fmt.Fprintf(e.output, "⚠️  %s\n", result.Message)
+		e.iconf(ctx, "⚠️  ", "%s\n", result.Message)
 	}
 
 	if fileStmt.Action == "replace" && len(replacements) > 0 {
 		for oldValue, newValue := range replacements {
-			_, _ = fmt.Fprintf(e.output, "    - %s → %s\n", oldValue, newValue)
+			_, _ = fmt.Fprintf(e.out(ctx), "    - %s → %s\n", oldValue, newValue)
 		}
 	}
 
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: File Streaming
+// This file handles files too large to hold in memory: for each line loops
+// read their file one line at a time, and read file ... as warns before it
+// captures a file larger than the project's capture_warning_size.
+
+// captureWarningSizeSetting is the project setting key written by
+// `set capture_warning_size to "50MB"`; "0" turns the warning off
+const captureWarningSizeSetting = "capture_warning_size"
+
+// defaultCaptureWarningSize is the size above which capturing a file warns
+const defaultCaptureWarningSize = 10 << 20
+
+// lineReaderSize is the buffer size of line loops; longer lines still work
+const lineReaderSize = 64 << 10
+
+// executeLineLoop runs the body once per line of a file, without the line
+// ending. A sequential loop streams the file, running the body as each line
+// is read; a parallel loop reads every line first to schedule them.
+func (e *Engine) executeLineLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
+	filename := e.interpolateVariables(stmt.Iterable, ctx)
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would read lines from file: %s\n", filename)
+		return nil
+	}
+
+	// #nosec G304 -- the loop explicitly names the file it reads.
+	file, err := os.Open(e.resolveFilesystemPath(filename, ctx))
+	if err != nil {
+		return fmt.Errorf("for each line: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	reader := bufio.NewReaderSize(file, lineReaderSize)
+
+	if stmt.Parallel {
+		var lines []string
+		if err := readLines(reader, func(line string) (bool, error) {
+			lines = append(lines, line)
+			return false, nil
+		}); err != nil {
+			return fmt.Errorf("reading %s: %w", filename, err)
+		}
+		e.iconf(ctx, "📄 ", "Reading lines from file: %s (%d lines)\n", filename, len(lines))
+		if stmt.Filter != nil {
+			lines = e.applyFilter(lines, stmt.Filter, ctx)
+		}
+		return e.executeParallelLoop(stmt, lines, ctx)
+	}
+
+	e.iconf(ctx, "📄 ", "Streaming lines from file: %s\n", filename)
+	count := 0
+	err = readLines(reader, func(line string) (bool, error) {
+		count++
+		if stmt.Filter != nil && len(e.applyFilter([]string{line}, stmt.Filter, ctx)) == 0 {
+			return false, nil
+		}
+		done, err := e.executeLoopItem(stmt, line, ctx)
+		if err != nil {
+			return false, fmt.Errorf("line %d of %s: %w", count, filename, err)
+		}
+		return done, nil
+	})
+	if err != nil {
+		return err
+	}
+	if e.verbose {
+		e.iconf(ctx, "✅  ", "Sequential loop completed: %d lines read\n", count)
+	}
+	return nil
+}
+
+// readLines calls visit with each line read, without its line ending,
+// until visit is done or fails or the input ends
+func readLines(reader *bufio.Reader, visit func(line string) (done bool, err error)) error {
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			done, err := visit(line)
+			if err != nil || done {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// warnLargeCapture warns before a file larger than the capture warning size
+// is read into a variable
+func (e *Engine) warnLargeCapture(path, name, variable string, ctx *ExecutionContext) error {
+	limit, err := captureWarningSize(ctx)
+	if err != nil || limit == 0 {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= limit {
+		return nil
+	}
+	e.iconf(ctx, "⚠️  ", "Capturing %s (%s) in $%s holds all of it in memory; use 'for each line in file' to stream it\n",
+		name, formatBytes(info.Size()), variable)
+	return nil
+}
+
+// captureWarningSize returns the project's capture_warning_size or the
+// default
+func captureWarningSize(ctx *ExecutionContext) (int64, error) {
+	if ctx == nil || ctx.Project == nil {
+		return defaultCaptureWarningSize, nil
+	}
+	value, ok := ctx.Project.Settings[captureWarningSizeSetting]
+	if !ok {
+		return defaultCaptureWarningSize, nil
+	}
+	size, err := parseByteSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", captureWarningSizeSetting, err)
+	}
+	return size, nil
+}
+
+// parseByteSize parses a size such as 500KB, 50MB or 2GB; units are powers
+// of 1024 and a bare number is bytes
+func parseByteSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			text, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: expected a size such as 500KB or 50MB", value)
+	}
+	return n * multiplier, nil
+}
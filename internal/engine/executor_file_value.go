@@ -18,21 +18,22 @@ func (e *Engine) executeFileValue(stmt *statement.FileValue, ctx *ExecutionConte
 		}
 		target = ctx.CurrentFile
 	}
+	path := e.resolveFilesystemPath(target, ctx)
 
 	switch stmt.Operation {
 	case "get":
-		value, err := filevalue.ReadFile(format, selector, target)
+		value, err := filevalue.ReadFile(format, selector, path)
 		if err != nil {
 			return fmt.Errorf("get %s %q from %q: %w", format, selector, target, err)
 		}
-		ctx.Variables[stmt.CaptureVar] = value.Text
+		e.assignVariable(ctx, stmt.CaptureVar, value.Text, "get "+format)
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "📦  Captured %s %q from %s as $%s\n", format, selector, target, stmt.CaptureVar)
+			e.iconf(ctx, "📦  ", "Captured %s %q from %s as $%s\n", format, selector, target, stmt.CaptureVar)
 		}
 		return nil
 
 	case "check":
-		actual, err := filevalue.ReadFile(format, selector, target)
+		actual, err := filevalue.ReadFile(format, selector, path)
 		if err != nil {
 			return fmt.Errorf("check %s %q in %q: %w", format, selector, target, err)
 		}
@@ -49,7 +50,7 @@ func (e *Engine) executeFileValue(stmt *statement.FileValue, ctx *ExecutionConte
 			return fmt.Errorf("file value check failed: %s %q in %q expected to %s %q, actual %q", format, selector, target, operator, expected, actual.Text)
 		}
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "✅  File value check passed: %s %q in %s\n", format, selector, target)
+			e.iconf(ctx, "✅  ", "File value check passed: %s %q in %s\n", format, selector, target)
 		}
 		return nil
 
@@ -58,25 +59,25 @@ func (e *Engine) executeFileValue(stmt *statement.FileValue, ctx *ExecutionConte
 		if e.dryRun {
 			// Validate the complete prospective edit while leaving the file untouched.
 			// #nosec G304 -- the Drun program explicitly supplies the path.
-			data, err := os.ReadFile(target)
+			data, err := os.ReadFile(path)
 			if err != nil {
 				return err
 			}
 			if _, _, err := filevalue.Update(format, selector, data, value, stmt.MissingPolicy, stmt.ValueType); err != nil {
 				return fmt.Errorf("update %s %q in %q: %w", format, selector, target, err)
 			}
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would update %s %q in %s to %q\n", format, selector, target, value)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would update %s %q in %s to %q\n", format, selector, target, value)
 			return nil
 		}
-		changed, _, err := filevalue.UpdateFile(format, selector, target, value, stmt.MissingPolicy, stmt.ValueType)
+		changed, _, err := filevalue.UpdateFile(format, selector, path, value, stmt.MissingPolicy, stmt.ValueType)
 		if err != nil {
 			return fmt.Errorf("update %s %q in %q: %w", format, selector, target, err)
 		}
 		if e.verbose {
 			if changed {
-				_, _ = fmt.Fprintf(e.output, "✅  Updated %s %q in %s\n", format, selector, target)
+				e.iconf(ctx, "✅  ", "Updated %s %q in %s\n", format, selector, target)
 			} else {
-				_, _ = fmt.Fprintf(e.output, "✅  %s %q in %s already has the requested value\n", format, selector, target)
+				e.iconf(ctx, "✅  ", "%s %q in %s already has the requested value\n", format, selector, target)
 			}
 		}
 		return nil
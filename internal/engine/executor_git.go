@@ -24,8 +24,23 @@ func (e *Engine) executeGit(gitStmt *statement.Git, ctx *ExecutionContext) error
 		options[key] = e.interpolateVariables(value, ctx)
 	}
 
+	// Pushes to an https remote use the host's credential helper
+	var credentialHost string
+	if operation == "push" {
+		remote := options["remote"]
+		if remote == "" {
+			remote = "origin"
+		}
+		if host := gitRemoteHost(remote, ctx.WorkingDir); hasCredentialHelper(host, ctx) {
+			credentialHost = host
+		}
+	}
+
 	if e.dryRun {
-		return e.buildGitCommand(operation, resource, name, options, true)
+		if credentialHost != "" {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would use the credential helper for %s\n", credentialHost)
+		}
+		return e.buildGitCommand(ctx, operation, resource, name, options, true)
 	}
 
 	// Show what we're about to do with appropriate emoji
@@ -33,84 +48,93 @@ func (e *Engine) executeGit(gitStmt *statement.Git, ctx *ExecutionContext) error
 	case "create":
 		switch resource {
 		case "branch":
-			_, _ = fmt.Fprintf(e.output, "🌿  Creating Git branch")
+			e.iconf(ctx, "🌿  ", "Creating Git branch")
 			if name != "" {
-				_, _ = fmt.Fprintf(e.output, ": %s", name)
+				_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 			}
 		case "tag":
-			_, _ = fmt.Fprintf(e.output, "🏷️  Creating Git tag")
+			e.iconf(ctx, "🏷️  ", "Creating Git tag")
 			if name != "" {
-				_, _ = fmt.Fprintf(e.output, ": %s", name)
+				_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 			}
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "checkout":
-		_, _ = fmt.Fprintf(e.output, "🔀 Checking out Git branch")
+		e.iconf(ctx, "🔀 ", "Checking out Git branch")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "merge":
-		_, _ = fmt.Fprintf(e.output, "🔀 Merging Git branch")
+		e.iconf(ctx, "🔀 ", "Merging Git branch")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "commit":
-		_, _ = fmt.Fprintf(e.output, "💾 Committing Git changes")
+		e.iconf(ctx, "💾 ", "Committing Git changes")
 		if message, exists := options["message"]; exists {
-			_, _ = fmt.Fprintf(e.output, ": %s", message)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", message)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "push":
 		if resource == "tag" {
-			_, _ = fmt.Fprintf(e.output, "📤 Pushing Git tag")
+			e.iconf(ctx, "📤 ", "Pushing Git tag")
 			if name != "" {
-				_, _ = fmt.Fprintf(e.output, ": %s", name)
+				_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 			}
 		} else {
-			_, _ = fmt.Fprintf(e.output, "📤 Pushing Git changes")
+			e.iconf(ctx, "📤 ", "Pushing Git changes")
 			if remote, exists := options["remote"]; exists {
-				_, _ = fmt.Fprintf(e.output, " to %s", remote)
+				_, _ = fmt.Fprintf(e.out(ctx), " to %s", remote)
 			}
 			if branch, exists := options["branch"]; exists {
-				_, _ = fmt.Fprintf(e.output, "/%s", branch)
+				_, _ = fmt.Fprintf(e.out(ctx), "/%s", branch)
 			}
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "clone":
-		_, _ = fmt.Fprintf(e.output, "📥  Cloning Git repository")
+		e.iconf(ctx, "📥  ", "Cloning Git repository")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "init":
-		_, _ = fmt.Fprintf(e.output, "🆕 Initializing Git repository\n")
+		e.iconf(ctx, "🆕 ", "Initializing Git repository\n")
 	case "add":
-		_, _ = fmt.Fprintf(e.output, "➕ Adding files to Git")
+		e.iconf(ctx, "➕ ", "Adding files to Git")
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, ": %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), ": %s", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	case "status":
-		_, _ = fmt.Fprintf(e.output, "📊  Checking Git status\n")
+		e.iconf(ctx, "📊  ", "Checking Git status\n")
 	case "show":
 		if resource == "branch" {
-			_, _ = fmt.Fprintf(e.output, "🌿  Showing current Git branch\n")
+			e.iconf(ctx, "🌿  ", "Showing current Git branch\n")
 		} else {
-			_, _ = fmt.Fprintf(e.output, "📖 Showing Git information\n")
+			e.iconf(ctx, "📖 ", "Showing Git information\n")
 		}
 	default:
-		_, _ = fmt.Fprintf(e.output, "🔗 Running Git %s", operation)
+		e.iconf(ctx, "🔗 ", "Running Git %s", operation)
 		if resource != "" {
-			_, _ = fmt.Fprintf(e.output, " %s", resource)
+			_, _ = fmt.Fprintf(e.out(ctx), " %s", resource)
 		}
 		if name != "" {
-			_, _ = fmt.Fprintf(e.output, " %s", name)
+			_, _ = fmt.Fprintf(e.out(ctx), " %s", name)
+		}
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
+	}
+
+	var configArgs []string
+	if credentialHost != "" {
+		cred, err := e.credentialFor(credentialHost, ctx)
+		if err != nil {
+			return err
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		configArgs = gitCredentialArgs(cred)
 	}
 
 	// Build and execute the actual command
-	return e.buildGitCommand(operation, resource, name, options, false)
+	return e.buildGitCommand(ctx, operation, resource, name, options, false, configArgs...)
 }
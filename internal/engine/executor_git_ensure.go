@@ -38,7 +38,7 @@ func (e *Engine) executeGitEnsureVersion(guard *statement.GitEnsureVersion, ctx
 		return fmt.Errorf("git ensure source %q cannot be resolved: %w", guard.Source, err)
 	}
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would ensure version %s is newer than the latest stable version from Git source %s using %s%s%s\n",
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would ensure version %s is newer than the latest stable version from Git source %s using %s%s%s\n",
 			candidate.Raw, guard.Source, method, gitEnsureContractSummary(guard), gitEnsureCaptureSummary(guard))
 		return nil
 	}
@@ -72,10 +72,10 @@ func (e *Engine) executeGitEnsureVersion(guard *statement.GitEnsureVersion, ctx
 		return fmt.Errorf("release version %q is older than latest version %q from Git source %q", candidate.Raw, latest.Raw, guard.Source)
 	}
 	if guard.CaptureVar != "" {
-		ctx.Variables[guard.CaptureVar] = latest.Raw
-		_, _ = fmt.Fprintf(e.output, "✅  Version %s is newer than latest version %s from %s; captured latest as $%s\n", candidate.Raw, latest.Raw, guard.Source, guard.CaptureVar)
+		e.assignVariable(ctx, guard.CaptureVar, latest.Raw, "git version guard")
+		e.iconf(ctx, "✅  ", "Version %s is newer than latest version %s from %s; captured latest as $%s\n", candidate.Raw, latest.Raw, guard.Source, guard.CaptureVar)
 	} else {
-		_, _ = fmt.Fprintf(e.output, "✅  Version %s is newer than latest version %s from %s\n", candidate.Raw, latest.Raw, guard.Source)
+		e.iconf(ctx, "✅  ", "Version %s is newer than latest version %s from %s\n", candidate.Raw, latest.Raw, guard.Source)
 	}
 	return nil
 }
@@ -15,7 +15,7 @@ func (e *Engine) executeGitQuery(query *statement.GitQuery, ctx *ExecutionContex
 	}
 	if e.dryRun {
 		value := fmt.Sprintf("[DRY RUN] latest %s from %s", query.Result, query.Source)
-		ctx.Variables[query.CaptureVar] = value
+		e.assignVariable(ctx, query.CaptureVar, value, "git query")
 		method := query.AccessMethod
 		if method == "" {
 			method = "source default"
@@ -24,7 +24,7 @@ func (e *Engine) executeGitQuery(query *statement.GitQuery, ctx *ExecutionContex
 		if order == "" {
 			order = "version"
 		}
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would get latest %s from Git source %s using %s, ordered by %s, as $%s\n", query.Result, query.Source, method, order, query.CaptureVar)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would get latest %s from Git source %s using %s, ordered by %s, as $%s\n", query.Result, query.Source, method, order, query.CaptureVar)
 		return nil
 	}
 	registry, err := scm.RegistryFromAST(ctx.Project.SCMRegistry)
@@ -59,7 +59,7 @@ func (e *Engine) executeGitQuery(query *statement.GitQuery, ctx *ExecutionContex
 		return fmt.Errorf("querying Git source %q: %w", query.Source, err)
 	}
 	value := result.Value(query.Result)
-	ctx.Variables[query.CaptureVar] = value
-	_, _ = fmt.Fprintf(e.output, "📦  Captured latest Git %s %q from %s as $%s\n", query.Result, value, query.Source, query.CaptureVar)
+	e.assignVariable(ctx, query.CaptureVar, value, "git query")
+	e.iconf(ctx, "📦  ", "Captured latest Git %s %q from %s as $%s\n", query.Result, value, query.Source, query.CaptureVar)
 	return nil
 }
@@ -15,7 +15,7 @@ import (
 func (e *Engine) executeGitValidate(stmt *statement.GitValidate, ctx *ExecutionContext) error {
 	if ctx.Project == nil || ctx.Project.GitPolicy == nil {
 		if e.dryRun {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would validate git %s, but no git policy is configured\n", stmt.Target)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would validate git %s, but no git policy is configured\n", stmt.Target)
 			return nil
 		}
 		return fmt.Errorf("cannot validate git %s: no git policy configured in project settings", stmt.Target)
@@ -24,26 +24,26 @@ func (e *Engine) executeGitValidate(stmt *statement.GitValidate, ctx *ExecutionC
 	policy := toGitPolicyModel(ctx.Project.GitPolicy)
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] git validate %s\n", stmt.Target)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] git validate %s\n", stmt.Target)
 		return nil
 	}
 
 	switch stmt.Target {
 	case "branch_name":
-		return e.validateBranchName(policy)
+		return e.validateBranchName(policy, ctx)
 	case "commit_message":
-		return e.validateCommitMessage(policy, stmt.Value)
+		return e.validateCommitMessage(policy, stmt.Value, ctx)
 	case "signed_commits":
-		return e.validateSignedCommits()
+		return e.validateSignedCommits(ctx)
 	case "all":
-		if err := e.validateBranchName(policy); err != nil {
+		if err := e.validateBranchName(policy, ctx); err != nil {
 			return err
 		}
 		// For all, we only validate the last commit message if no explicit value is given
-		if err := e.validateCommitMessage(policy, stmt.Value); err != nil {
+		if err := e.validateCommitMessage(policy, stmt.Value, ctx); err != nil {
 			return err
 		}
-		if err := e.validateSignedCommits(); err != nil {
+		if err := e.validateSignedCommits(ctx); err != nil {
 			return err
 		}
 		return nil
@@ -66,7 +66,7 @@ func toGitPolicyModel(s *statement.GitPolicy) *gitpolicy.Policy {
 	}
 }
 
-func (e *Engine) validateBranchName(policy *gitpolicy.Policy) error {
+func (e *Engine) validateBranchName(policy *gitpolicy.Policy, ctx *ExecutionContext) error {
 	// Get current branch
 	branchName, err := e.RunGitCommandOutput("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
@@ -75,16 +75,16 @@ func (e *Engine) validateBranchName(policy *gitpolicy.Policy) error {
 	branchName = strings.TrimSpace(branchName)
 
 	if err := policy.ValidateBranchName(branchName); err != nil {
-		return err
+		return &gitpolicy.ViolationError{Err: err}
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "✅  Branch name '%s' is valid\n", branchName)
+		e.iconf(ctx, "✅  ", "Branch name '%s' is valid\n", branchName)
 	}
 	return nil
 }
 
-func (e *Engine) validateCommitMessage(policy *gitpolicy.Policy, explicitMsg string) error {
+func (e *Engine) validateCommitMessage(policy *gitpolicy.Policy, explicitMsg string, ctx *ExecutionContext) error {
 	var msg string
 	if explicitMsg != "" {
 		msg = explicitMsg
@@ -102,16 +102,16 @@ func (e *Engine) validateCommitMessage(policy *gitpolicy.Policy, explicitMsg str
 	branchName = strings.TrimSpace(branchName)
 
 	if err := policy.ValidateCommitMessage(msg, branchName); err != nil {
-		return err
+		return &gitpolicy.ViolationError{Err: err}
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "✅  Commit message is valid\n")
+		e.iconf(ctx, "✅  ", "Commit message is valid\n")
 	}
 	return nil
 }
 
-func (e *Engine) validateSignedCommits() error {
+func (e *Engine) validateSignedCommits(ctx *ExecutionContext) error {
 	// Let's check the last commit for a signature
 	// %G? outputs G for good signature, B for bad, U for good/untrusted, N for no signature, etc.
 	out, err := e.RunGitCommandOutput("log", "-1", "--format=%G?")
@@ -121,11 +121,11 @@ func (e *Engine) validateSignedCommits() error {
 
 	sigStatus := strings.TrimSpace(out)
 	if sigStatus != "G" && sigStatus != "U" {
-		return fmt.Errorf("commit is not properly signed (signature status: '%s')", sigStatus)
+		return &gitpolicy.ViolationError{Err: fmt.Errorf("commit is not properly signed (signature status: '%s')", sigStatus)}
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "✅  Commit is signed\n")
+		e.iconf(ctx, "✅  ", "Commit is signed\n")
 	}
 	return nil
 }
@@ -1,7 +1,13 @@
 package engine
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 )
@@ -36,37 +42,218 @@ func (e *Engine) executeHTTP(httpStmt *statement.HTTP, ctx *ExecutionContext) er
 		options[key] = e.interpolateVariables(value, ctx)
 	}
 
+	headerOrder := httpStmt.HeaderOrder
+	if httpStmt.Client != "" {
+		var err error
+		if url, headerOrder, err = e.applyHTTPClient(httpStmt.Client, url, headers, options, headerOrder, ctx); err != nil {
+			return err
+		}
+	}
+
+	// Interpolate the expected values of response assertions
+	expectations := make([]statement.HTTPExpectation, len(httpStmt.Expectations))
+	for i, expectation := range httpStmt.Expectations {
+		expectation.Header = e.interpolateVariables(expectation.Header, ctx)
+		values := make([]string, len(expectation.Values))
+		for j, value := range expectation.Values {
+			values[j] = e.interpolateVariables(value, ctx)
+		}
+		expectation.Values = values
+		expectations[i] = expectation
+	}
+
+	// Statements without their own authentication use the host's credential helper
+	authOrder := httpStmt.AuthOrder
+	host := urlHost(url)
+	useHelper := len(auth) == 0 && !hasAuthorizationHeader(headers) && hasCredentialHelper(host, ctx)
+
 	if e.dryRun {
-		return e.buildHTTPCommand(method, url, body, headers, auth, options, true)
+		if useHelper {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would use the credential helper for %s\n", host)
+		}
+		if err := e.buildHTTPCommand(method, url, body, headers, auth, options, headerOrder, authOrder, true, ctx); err != nil {
+			return err
+		}
+		for _, capture := range httpStmt.Captures {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture response %s as: %s\n", capture.Part, capture.Variable)
+			e.assignVariable(ctx, capture.Variable, "[DRY RUN] response "+capture.Part, "http capture")
+		}
+		for _, expectation := range expectations {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would expect response %s\n", httpExpectationString(expectation))
+		}
+		return nil
 	}
 
 	// Show what we're about to do with appropriate emoji
 	switch method {
 	case "GET":
-		_, _ = fmt.Fprintf(e.output, "📥  GET request to: %s\n", url)
+		e.iconf(ctx, "📥  ", "GET request to: %s\n", url)
 	case "POST":
-		_, _ = fmt.Fprintf(e.output, "📤 POST request to: %s\n", url)
+		e.iconf(ctx, "📤 ", "POST request to: %s\n", url)
 	case "PUT":
-		_, _ = fmt.Fprintf(e.output, "🔄  PUT request to: %s\n", url)
+		e.iconf(ctx, "🔄  ", "PUT request to: %s\n", url)
 	case "PATCH":
-		_, _ = fmt.Fprintf(e.output, "🔧 PATCH request to: %s\n", url)
+		e.iconf(ctx, "🔧 ", "PATCH request to: %s\n", url)
 	case "DELETE":
-		_, _ = fmt.Fprintf(e.output, "🗑️  DELETE request to: %s\n", url)
+		e.iconf(ctx, "🗑️  ", "DELETE request to: %s\n", url)
 	case "HEAD":
-		_, _ = fmt.Fprintf(e.output, "🔍  HEAD request to: %s\n", url)
+		e.iconf(ctx, "🔍  ", "HEAD request to: %s\n", url)
 	default:
-		_, _ = fmt.Fprintf(e.output, "🌐  %s request to: %s\n", method, url)
+		e.iconf(ctx, "🌐  ", "%s request to: %s\n", method, url)
 	}
 
 	// Handle special HTTP operations
 	if downloadPath, exists := options["download"]; exists {
-		_, _ = fmt.Fprintf(e.output, "💾 Downloading to: %s\n", downloadPath)
+		e.iconf(ctx, "💾 ", "Downloading to: %s\n", downloadPath)
 	}
 
 	if uploadPath, exists := options["upload"]; exists {
-		_, _ = fmt.Fprintf(e.output, "📤 Uploading from: %s\n", uploadPath)
+		e.iconf(ctx, "📤 ", "Uploading from: %s\n", uploadPath)
+	}
+
+	if useHelper {
+		cred, err := e.credentialFor(host, ctx)
+		if err != nil {
+			return err
+		}
+		if cred.Username != "" {
+			auth["basic"] = cred.Username + ":" + cred.Secret
+		} else {
+			auth["bearer"] = cred.Secret
+		}
 	}
 
 	// Build and execute the actual HTTP request
-	return e.buildHTTPCommand(method, url, body, headers, auth, options, false)
+	if err := e.buildHTTPCommand(method, url, body, headers, auth, options, headerOrder, authOrder, false, ctx); err != nil {
+		return err
+	}
+	if len(httpStmt.Captures) > 0 || len(expectations) > 0 {
+		return e.sendHTTPRequest(method, url, body, headers, auth, options, httpStmt.Captures, expectations, ctx)
+	}
+	return nil
+}
+
+// sendHTTPRequest sends a request whose response parts are captured into
+// variables or checked by assertions. When the status is captured, a request
+// that gets no response captures "000", as curl does, so polling loops can
+// wait for a service that is still starting.
+func (e *Engine) sendHTTPRequest(method, rawURL, body string, headers, auth, options map[string]string, captures []statement.HTTPCapture, expectations []statement.HTTPExpectation, ctx *ExecutionContext) error {
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, rawURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP request to %s: %w", rawURL, err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	for authType, value := range auth {
+		switch authType {
+		case "bearer":
+			req.Header.Set("Authorization", "Bearer "+value)
+		case "token":
+			req.Header.Set("Authorization", "Token "+value)
+		case "basic":
+			user, password, _ := strings.Cut(value, ":")
+			req.SetBasicAuth(user, password)
+		}
+	}
+
+	client := &http.Client{Timeout: httpRequestTimeout(options["timeout"])}
+	if options["insecure"] == "true" {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}} // #nosec G402 -- requested with the insecure option
+	}
+
+	// A captured status is the statement's to check (polls retry on their
+	// own), so only requests that do not capture it are retried
+	policy := e.networkPolicy(ctx, method+" "+rawURL)
+	if capturesHTTPStatus(captures) {
+		policy.Retries = 0
+	}
+	resp, err := policy.Do(client, req)
+	if err != nil {
+		if !capturesHTTPStatus(captures) || len(expectations) > 0 {
+			return fmt.Errorf("%s request to %s failed: %w", method, rawURL, err)
+		}
+		if e.verbose {
+			e.iconf(ctx, "⚠️  ", "No response from %s: %v\n", rawURL, err)
+		}
+		for _, capture := range captures {
+			value := ""
+			if capture.Part == "status" {
+				value = "000"
+			}
+			e.assignVariable(ctx, capture.Variable, value, "http capture")
+		}
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response from %s: %w", rawURL, err)
+	}
+	if e.verbose {
+		e.iconf(ctx, "📨 ", "%s %s: %s\n", method, rawURL, resp.Status)
+	}
+
+	for _, capture := range captures {
+		var value string
+		switch capture.Part {
+		case "status":
+			value = strconv.Itoa(resp.StatusCode)
+		case "body":
+			value = strings.TrimRight(string(data), "\r\n")
+		case "header":
+			value = resp.Header.Get(capture.Header)
+		}
+		e.assignVariable(ctx, capture.Variable, value, "http capture")
+	}
+
+	failures, err := checkHTTPExpectations(expectations, resp, data)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return e.httpExpectationError(method, rawURL, failures, ctx)
+	}
+	if len(expectations) > 0 && e.verbose {
+		e.iconf(ctx, "✅  ", "%s %s met %d response expectations\n", method, rawURL, len(expectations))
+	}
+	return nil
+}
+
+func capturesHTTPStatus(captures []statement.HTTPCapture) bool {
+	for _, capture := range captures {
+		if capture.Part == "status" {
+			return true
+		}
+	}
+	return false
+}
+
+// httpRequestTimeout reads the timeout option as a duration ("30s") or a
+// number of seconds, defaulting to 30 seconds
+func httpRequestTimeout(option string) time.Duration {
+	if option != "" {
+		if d, err := time.ParseDuration(option); err == nil {
+			return d
+		}
+		if seconds, err := strconv.Atoi(option); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 30 * time.Second
+}
+
+// hasAuthorizationHeader reports whether headers already set Authorization
+func hasAuthorizationHeader(headers map[string]string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, "Authorization") {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Domain: HTTP Clients
+// This file applies the project's named HTTP clients
+// (http client "api": base url "https://api.example.com", timeout 10s) to
+// the HTTP statements that use them with `using client "api"`.
+
+// applyHTTPClient applies the client named name to a request: its base URL
+// to a relative URL, its headers unless the statement sets them, and its
+// timeout unless the statement sets one. It returns the URL and the header
+// order, the client's headers first.
+func (e *Engine) applyHTTPClient(name, url string, headers, options map[string]string, headerOrder []string, ctx *ExecutionContext) (string, []string, error) {
+	if ctx.Project == nil {
+		return "", nil, fmt.Errorf("unknown HTTP client %q: no project declares one", name)
+	}
+	declared, ok := ctx.Project.HTTPClients[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown HTTP client %q", name)
+	}
+
+	if declared.BaseURL != "" && !strings.Contains(url, "://") {
+		url = joinBaseURL(e.interpolateVariables(declared.BaseURL, ctx), url)
+	}
+
+	var order []string
+	for _, key := range declared.HeaderOrder {
+		if hasHeader(headers, key) {
+			continue
+		}
+		headers[key] = e.interpolateVariables(declared.Headers[key], ctx)
+		order = append(order, key)
+	}
+	order = append(order, headerOrder...)
+
+	if _, set := options["timeout"]; !set && declared.Timeout != "" {
+		options["timeout"] = declared.Timeout
+	}
+	return url, order, nil
+}
+
+// joinBaseURL joins a base URL and a path with exactly one slash
+func joinBaseURL(base, path string) string {
+	if path == "" {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// hasHeader reports whether headers set key, in any case
+func hasHeader(headers map[string]string, key string) bool {
+	for existing := range headers {
+		if strings.EqualFold(existing, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: HTTP Response Assertions
+// This file contains the checks of "expect response ..." on HTTP statements
+
+// jsonPredicatePattern splits a body predicate like ".data.0.id == 42" into
+// its path, operator and expected value
+var jsonPredicatePattern = regexp.MustCompile(`^\.([^\s=!<>]*)\s*(?:(==|!=|>=|<=|>|<)\s*(.+))?$`)
+
+// httpExpectationFailure is a failed assertion and what the response had
+type httpExpectationFailure struct {
+	expectation statement.HTTPExpectation
+	got         string
+	diff        []string // expected and received body, for body is
+}
+
+// checkHTTPExpectations returns the assertions the response fails
+func checkHTTPExpectations(expectations []statement.HTTPExpectation, resp *http.Response, body []byte) ([]httpExpectationFailure, error) {
+	var failures []httpExpectationFailure
+	for _, expectation := range expectations {
+		got, diff, err := checkHTTPExpectation(expectation, resp, body)
+		if err != nil {
+			return nil, fmt.Errorf("expect response %s: %w", httpExpectationString(expectation), err)
+		}
+		if got != "" {
+			failures = append(failures, httpExpectationFailure{expectation: expectation, got: got, diff: diff})
+		}
+	}
+	return failures, nil
+}
+
+// checkHTTPExpectation returns what the response has instead when it fails
+// the assertion, or "" when it passes
+func checkHTTPExpectation(expectation statement.HTTPExpectation, resp *http.Response, body []byte) (string, []string, error) {
+	switch expectation.Part {
+	case "status":
+		for _, want := range expectation.Values {
+			if httpStatusMatches(resp.StatusCode, want) {
+				return "", nil, nil
+			}
+		}
+		return "got " + resp.Status, nil, nil
+
+	case "header":
+		values, present := resp.Header[http.CanonicalHeaderKey(expectation.Header)]
+		if !present {
+			return "the header is missing", nil, nil
+		}
+		if expectation.Operator == "exists" {
+			return "", nil, nil
+		}
+		value := strings.Join(values, ", ")
+		passed, err := textMatches(expectation.Operator, value, expectation.Values[0])
+		if err != nil || passed {
+			return "", nil, err
+		}
+		return fmt.Sprintf("got %q", value), nil, nil
+
+	case "body":
+		text := strings.TrimRight(string(body), "\r\n")
+		want := expectation.Values[0]
+		if expectation.Operator == "matches" && strings.HasPrefix(want, ".") {
+			return checkJSONPredicate(want, body)
+		}
+		passed, err := textMatches(expectation.Operator, text, want)
+		if err != nil || passed {
+			return "", nil, err
+		}
+		if expectation.Operator == "is" {
+			return "the body differs", unifiedDiff(splitDiffLines(want), splitDiffLines(text), "expected", "response"), nil
+		}
+		return fmt.Sprintf("got %s", abbreviateBody(text)), nil, nil
+	}
+	return "", nil, fmt.Errorf("unknown response part %q", expectation.Part)
+}
+
+// httpStatusMatches reports whether a status code is want: a code, or a
+// class like "2xx"
+func httpStatusMatches(code int, want string) bool {
+	if strings.HasSuffix(want, "xx") {
+		return strconv.Itoa(code/100) == strings.TrimSuffix(want, "xx")
+	}
+	return strconv.Itoa(code) == want
+}
+
+// textMatches applies is, contains or matches (a regular expression) to text
+func textMatches(operator, text, want string) (bool, error) {
+	switch operator {
+	case "is":
+		return text == want, nil
+	case "contains":
+		return strings.Contains(text, want), nil
+	case "matches":
+		re, err := regexp.Compile(want)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern: %w", err)
+		}
+		return re.MatchString(text), nil
+	}
+	return false, fmt.Errorf("unknown operator %q", operator)
+}
+
+// checkJSONPredicate checks a JSON body against ".path", which passes when
+// the field is there and not false or null, or ".path <op> value", which
+// compares the field with a JSON value
+func checkJSONPredicate(predicate string, body []byte) (string, []string, error) {
+	parts := jsonPredicatePattern.FindStringSubmatch(strings.TrimSpace(predicate))
+	if parts == nil {
+		return "", nil, fmt.Errorf("invalid predicate: expected .path or .path == value")
+	}
+	path, operator, literal := parts[1], parts[2], strings.TrimSpace(parts[3])
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Sprintf("the body is not JSON: %s", abbreviateBody(string(body))), nil, nil
+	}
+	value, found := document, true
+	if path != "" {
+		value, found = jsonField(document, path)
+	}
+	if !found {
+		return fmt.Sprintf(".%s is missing", path), nil, nil
+	}
+	got := fmt.Sprintf(".%s is %s", path, jsonText(value))
+
+	if operator == "" {
+		if value == nil || value == false {
+			return got, nil, nil
+		}
+		return "", nil, nil
+	}
+
+	var want any
+	literalDecoder := json.NewDecoder(strings.NewReader(literal))
+	literalDecoder.UseNumber()
+	if err := literalDecoder.Decode(&want); err != nil {
+		want = literal // a bare word compares as a string
+	}
+
+	var passed bool
+	switch operator {
+	case "==", "!=":
+		passed = jsonValuesEqual(value, want) == (operator == "==")
+	default:
+		gotNumber, gotOK := value.(json.Number)
+		wantNumber, wantOK := want.(json.Number)
+		if !gotOK || !wantOK {
+			return got + ", not a number", nil, nil
+		}
+		a, _ := gotNumber.Float64()
+		b, _ := wantNumber.Float64()
+		switch operator {
+		case ">":
+			passed = a > b
+		case ">=":
+			passed = a >= b
+		case "<":
+			passed = a < b
+		case "<=":
+			passed = a <= b
+		}
+	}
+	if passed {
+		return "", nil, nil
+	}
+	return got, nil, nil
+}
+
+// jsonValuesEqual compares decoded JSON values, numbers by value
+func jsonValuesEqual(a, b any) bool {
+	if x, ok := a.(json.Number); ok {
+		if y, ok := b.(json.Number); ok {
+			xf, errX := x.Float64()
+			yf, errY := y.Float64()
+			if errX == nil && errY == nil {
+				return xf == yf
+			}
+			return x == y
+		}
+	}
+	return jsonText(a) == jsonText(b)
+}
+
+// jsonText renders a decoded JSON value as compact JSON
+func jsonText(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// abbreviateBody quotes a response body, cut short when it is long
+func abbreviateBody(text string) string {
+	const limit = 200
+	if len(text) > limit {
+		return strconv.Quote(text[:limit]) + "..."
+	}
+	return strconv.Quote(text)
+}
+
+// httpExpectationString renders an assertion as it is written
+func httpExpectationString(expectation statement.HTTPExpectation) string {
+	out := expectation.Part
+	if expectation.Part == "header" {
+		out += fmt.Sprintf(" %q", expectation.Header)
+	}
+	out += " " + expectation.Operator
+	switch {
+	case expectation.Operator == "in":
+		out += " [" + strings.Join(expectation.Values, ", ") + "]"
+	case expectation.Part == "status" && len(expectation.Values) == 1:
+		out += " " + expectation.Values[0]
+	case len(expectation.Values) == 1:
+		out += fmt.Sprintf(" %q", expectation.Values[0])
+	}
+	return out
+}
+
+// httpExpectationError reports the failed assertions of a response, showing
+// the body diffs first
+func (e *Engine) httpExpectationError(method, rawURL string, failures []httpExpectationFailure, ctx *ExecutionContext) error {
+	lines := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		if len(failure.diff) > 0 {
+			e.writeDiff(failure.diff, ctx)
+		}
+		lines = append(lines, fmt.Sprintf("  expected %s: %s", httpExpectationString(failure.expectation), failure.got))
+	}
+	noun := "expectation"
+	if len(failures) > 1 {
+		noun = "expectations"
+	}
+	return fmt.Errorf("%s %s failed %d response %s:\n%s", method, rawURL, len(failures), noun, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,331 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: HTTP Pagination Loops
+// This file executes for each loops over paginated HTTP APIs
+// (for each $item in get "URL" paginated by ...). Pages are fetched one at a
+// time; every item of a page runs the loop body before the next page is
+// requested, so break stops fetching too.
+
+// maxPaginatedPages stops loops over APIs that never report a last page
+const maxPaginatedPages = 1000
+
+// paginatedRequestTimeout bounds each page request
+const paginatedRequestTimeout = 30 * time.Second
+
+// defaultItemFields are the fields searched for the item list when a
+// response is an object and the loop has no items at clause
+var defaultItemFields = []string{"items", "data", "results", "values", "nodes", "entries"}
+
+// linkNextPattern matches the rel="next" entry of an RFC 8288 Link header
+var linkNextPattern = regexp.MustCompile(`<([^>]*)>\s*;[^,]*\brel="?next"?`)
+
+// paginatedPage is one fetched page of a paginated API
+type paginatedPage struct {
+	items []any
+	next  string // URL of the next page, "" after the last one
+}
+
+// executeHTTPLoop runs the loop body for every item of every page
+func (e *Engine) executeHTTPLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
+	if stmt.Pagination == nil {
+		return fmt.Errorf("loop over %s has no pagination", stmt.Iterable)
+	}
+	pageURL := e.interpolateVariables(stmt.Iterable, ctx)
+	pagination := *stmt.Pagination
+	pagination.ItemsPath = e.interpolateVariables(pagination.ItemsPath, ctx)
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would iterate over the items of GET %s (paginated by %s %q)\n", pageURL, pagination.Strategy, pagination.Name)
+		return nil
+	}
+
+	client := &http.Client{Timeout: paginatedRequestTimeout}
+	seen := make(map[string]bool)
+	processed := 0
+
+	for page := 1; pageURL != ""; page++ {
+		if page > maxPaginatedPages {
+			return fmt.Errorf("stopped after %d pages of %s: the API never reported a last page", maxPaginatedPages, stmt.Iterable)
+		}
+		if seen[pageURL] {
+			return fmt.Errorf("pagination of %s loops back to %s", stmt.Iterable, pageURL)
+		}
+		seen[pageURL] = true
+
+		result, err := e.fetchPage(client, pageURL, &pagination, ctx)
+		if err != nil {
+			return err
+		}
+		if e.verbose {
+			e.iconf(ctx, "🌐 ", "Fetched page %d from %s (%d items)\n", page, pageURL, len(result.items))
+		}
+
+		for _, item := range result.items {
+			value := paginatedItemValue(item)
+			if stmt.Filter != nil && len(e.applyFilter([]string{value}, stmt.Filter, ctx)) == 0 {
+				continue
+			}
+			processed++
+
+			loopCtx := e.createLoopContext(ctx, stmt.Variable, value)
+			if fields, ok := item.(map[string]any); ok {
+				for key, field := range fields {
+					loopCtx.Variables[stmt.Variable+"."+key] = paginatedItemValue(field)
+				}
+			}
+
+			for _, bodyStmt := range stmt.Body {
+				if err := e.executeStatement(bodyStmt, loopCtx); err != nil {
+					if _, ok := err.(BreakError); ok {
+						if e.verbose {
+							e.iconf(ctx, "🔄  ", "Breaking loop after %d items\n", processed)
+						}
+						return nil
+					}
+					if _, ok := err.(ContinueError); ok {
+						break
+					}
+					return fmt.Errorf("error processing item %d of page %d: %v", processed, page, err)
+				}
+			}
+		}
+
+		pageURL = result.next
+	}
+
+	if e.verbose {
+		e.iconf(ctx, "✅  ", "Paginated loop completed: %d items processed\n", processed)
+	}
+	return nil
+}
+
+// fetchPage requests one page and works out its items and the next page
+func (e *Engine) fetchPage(client *http.Client, pageURL string, pagination *statement.Pagination, ctx *ExecutionContext) (*paginatedPage, error) {
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", pageURL, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	cred, err := e.credentialFor(urlHost(pageURL), ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cred != nil {
+		if cred.Username != "" {
+			req.SetBasicAuth(cred.Username, cred.Secret)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+cred.Secret)
+		}
+	}
+
+	resp, err := e.networkPolicy(ctx, "GET "+pageURL).Do(client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s returned %s", pageURL, resp.Status)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("response from %s is not JSON: %w", pageURL, err)
+	}
+
+	items, err := paginatedItems(body, pagination.ItemsPath)
+	if err != nil {
+		return nil, fmt.Errorf("response from %s: %w", pageURL, err)
+	}
+	result := &paginatedPage{items: items}
+
+	switch pagination.Strategy {
+	case "header":
+		result.next, err = nextPageFromHeader(resp.Header, pagination.Name, pageURL)
+	case "cursor":
+		result.next, err = nextPageFromCursor(body, pagination, pageURL)
+	case "query":
+		if len(items) > 0 {
+			result.next, err = nextPageFromQuery(pagination.Name, pageURL)
+		}
+	default:
+		err = fmt.Errorf("unknown pagination strategy %q", pagination.Strategy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// paginatedItems returns the item list of a response: the response itself
+// when it is an array, the field at itemsPath, or the first common list field
+func paginatedItems(body any, itemsPath string) ([]any, error) {
+	if itemsPath != "" {
+		value, ok := jsonField(body, itemsPath)
+		if !ok {
+			return nil, fmt.Errorf("no field %q", itemsPath)
+		}
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("field %q is not a list", itemsPath)
+		}
+		return items, nil
+	}
+
+	switch v := body.(type) {
+	case []any:
+		return v, nil
+	case map[string]any:
+		for _, field := range defaultItemFields {
+			if items, ok := v[field].([]any); ok {
+				return items, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no item list found; name its field with items at \"<field>\"")
+}
+
+// jsonField walks a dotted path ("meta.next_cursor", "data.0.id") into a
+// decoded JSON value
+func jsonField(value any, path string) (any, bool) {
+	for _, part := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			field, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			value = field
+		case []any:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// paginatedItemValue is what a loop variable holds for a JSON value:
+// strings as they are, everything else as compact JSON
+func paginatedItemValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// nextPageFromHeader reads the next page URL from a response header: the
+// rel="next" entry of a Link header, or the whole value of any other header
+func nextPageFromHeader(header http.Header, name, pageURL string) (string, error) {
+	value := strings.TrimSpace(header.Get(name))
+	if value == "" {
+		return "", nil
+	}
+	if strings.EqualFold(name, "Link") {
+		match := linkNextPattern.FindStringSubmatch(value)
+		if match == nil {
+			return "", nil
+		}
+		value = match[1]
+	}
+	return resolvePageURL(pageURL, value)
+}
+
+// nextPageFromCursor sends the cursor field of the body back as a query
+// parameter; an empty, null or false cursor ends the loop
+func nextPageFromCursor(body any, pagination *statement.Pagination, pageURL string) (string, error) {
+	value, ok := jsonField(body, pagination.Name)
+	if !ok {
+		return "", nil
+	}
+	cursor := paginatedItemValue(value)
+	if cursor == "" || cursor == "false" {
+		return "", nil
+	}
+
+	param := pagination.Param
+	if param == "" {
+		param = "cursor"
+	}
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	query.Set(param, cursor)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// nextPageFromQuery increments a numeric query parameter, starting from 1
+// when the URL does not set it
+func nextPageFromQuery(param, pageURL string) (string, error) {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	page := 1
+	if current := query.Get(param); current != "" {
+		page, err = strconv.Atoi(current)
+		if err != nil {
+			return "", fmt.Errorf("query parameter %s of %s is not a number", param, pageURL)
+		}
+	}
+	query.Set(param, strconv.Itoa(page+1))
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// resolvePageURL resolves a next page reference relative to the current page
+func resolvePageURL(pageURL, ref string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page %q: %w", ref, err)
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPaginatedServer serves three pages of two items each under every
+// pagination style the loop supports
+func newPaginatedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	pages := map[string]string{
+		"1": `[{"name":"api","stars":3},{"name":"web","stars":5}]`,
+		"2": `[{"name":"cli","stars":8},{"name":"docs","stars":1}]`,
+		"3": `[{"name":"infra","stars":2},{"name":"ops","stars":4}]`,
+	}
+	next := map[string]string{"1": "2", "2": "3"}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" && auth != "Bearer t0ken" {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/link":
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+			}
+			if n, ok := next[page]; ok {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/link?page=%s>; rel="next", <%s/link?page=3>; rel="last"`, server.URL, n, server.URL))
+			}
+			_, _ = fmt.Fprint(w, pages[page])
+		case "/cursor":
+			page := r.URL.Query().Get("after")
+			if page == "" {
+				page = "1"
+			}
+			_, _ = fmt.Fprintf(w, `{"data":{"repos":%s},"meta":{"next":%q}}`, pages[page], next[page])
+		case "/query":
+			items := pages[r.URL.Query().Get("page")]
+			if items == "" {
+				items = "[]"
+			}
+			_, _ = fmt.Fprintf(w, `{"results":%s}`, items)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runPaginatedTask(t *testing.T, input string, opts ...Option) string {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(append([]Option{WithOutput(&buf)}, opts...)...)
+	if err := eng.Execute(program, "inventory"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	return buf.String()
+}
+
+func TestPaginatedHTTPLoop(t *testing.T) {
+	server := newPaginatedServer(t)
+	all := []string{"api=3", "web=5", "cli=8", "docs=1", "infra=2", "ops=4"}
+
+	tests := []struct {
+		name string
+		loop string
+	}{
+		{"link header", `for each $repo in get "` + server.URL + `/link" paginated by header "Link":`},
+		{"cursor", `for each $repo in get "` + server.URL + `/cursor" paginated by cursor "meta.next" param "after" items at "data.repos":`},
+		{"query parameter", `for each $repo in get "` + server.URL + `/query?page=1" paginated by query "page":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := runPaginatedTask(t, "version: 2.0\n\ntask \"inventory\":\n  "+tt.loop+"\n    info \"{$repo.name}={$repo.stars}\"\n")
+
+			last := -1
+			for _, want := range all {
+				idx := strings.Index(out, want)
+				if idx < 0 {
+					t.Fatalf("expected %q in output:\n%s", want, out)
+				}
+				if idx < last {
+					t.Fatalf("items out of order at %q:\n%s", want, out)
+				}
+				last = idx
+			}
+		})
+	}
+}
+
+func TestPaginatedHTTPLoopItemJSONAndBreak(t *testing.T) {
+	server := newPaginatedServer(t)
+	out := runPaginatedTask(t, `version: 2.0
+
+task "inventory":
+  for each $repo in get "`+server.URL+`/link" paginated by header "Link":
+    info "repo {$repo}"
+    if $repo.name is "cli":
+      break
+`, WithVerbose(true))
+
+	if !strings.Contains(out, `repo {"name":"api","stars":3}`) {
+		t.Fatalf("expected the item as compact JSON:\n%s", out)
+	}
+	if strings.Contains(out, "docs") {
+		t.Fatalf("expected break to stop the loop:\n%s", out)
+	}
+	if strings.Contains(out, "Fetched page 3") {
+		t.Fatalf("expected break to stop fetching pages:\n%s", out)
+	}
+}
+
+func TestPaginatedHTTPLoopUsesCredentialHelper(t *testing.T) {
+	server := newPaginatedServer(t)
+	t.Setenv("DRUN_TEST_API_TOKEN", "t0ken")
+	out := runPaginatedTask(t, `version: 2.0
+
+project "app":
+  set credential helper for "127.0.0.1" to "env:DRUN_TEST_API_TOKEN"
+
+task "inventory":
+  for each $repo in get "`+server.URL+`/query?page=3" paginated by query "page":
+    info "{$repo.name}"
+`)
+
+	if !strings.Contains(out, "infra") || !strings.Contains(out, "ops") {
+		t.Fatalf("expected the items of page 3:\n%s", out)
+	}
+}
+
+func TestPaginatedHTTPLoopDryRunDoesNotFetch(t *testing.T) {
+	out := runPaginatedTask(t, `version: 2.0
+
+task "inventory":
+  for each $repo in get "http://127.0.0.1:1/repos" paginated by header "Link":
+    info "{$repo}"
+`, WithDryRun(true))
+
+	if !strings.Contains(out, `[DRY RUN] Would iterate over the items of GET http://127.0.0.1:1/repos (paginated by header "Link")`) {
+		t.Fatalf("unexpected dry run output:\n%s", out)
+	}
+}
+
+func TestPaginatedHTTPLoopFailsOnHTTPError(t *testing.T) {
+	server := newPaginatedServer(t)
+	program, err := ParseString(`version: 2.0
+
+task "inventory":
+  for each $repo in get "` + server.URL + `/missing" paginated by header "Link":
+    info "{$repo}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewEngineWithOptions(WithOutput(&buf)).Execute(program, "inventory")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/locks"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+)
+
+// Domain: Locks
+// This file implements `lock "name" [using "backend"] [waiting up to ...]:`
+// blocks, which run their statements while holding a named lock. Without a
+// backend on the statement, the project's `set lock backend to "..."` is
+// used, and without one the lock only coordinates runs on this machine.
+
+// lockBackendSetting is the project setting key written by
+// `set lock backend to "..."`
+const lockBackendSetting = "lock_backend"
+
+// executeLock runs the body of a lock block while holding the lock
+func (e *Engine) executeLock(stmt *statement.Lock, ctx *ExecutionContext) error {
+	name, err := e.interpolateVariablesWithError(stmt.Name, ctx)
+	if err != nil {
+		return fmt.Errorf("lock %q: %w", stmt.Name, err)
+	}
+	spec := stmt.Backend
+	if spec == "" && ctx.Project != nil {
+		spec = ctx.Project.Settings[lockBackendSetting]
+	}
+	if spec, err = e.interpolateVariablesWithError(spec, ctx); err != nil {
+		return fmt.Errorf("lock '%s': %w", name, err)
+	}
+
+	backend, err := locks.Open(strings.TrimSpace(spec))
+	if err != nil {
+		return fmt.Errorf("lock '%s': %w", name, err)
+	}
+	var wait time.Duration
+	if stmt.Wait != "" {
+		if wait, err = time.ParseDuration(stmt.Wait); err != nil {
+			return fmt.Errorf("lock '%s': invalid wait %q: %w", name, stmt.Wait, err)
+		}
+	}
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would hold lock '%s' in %s\n", name, backend)
+		return e.executeLockBody(stmt, ctx)
+	}
+
+	waitCtx, stopWaiting := waitContext(ctx)
+	lease, err := locks.Acquire(waitCtx, backend, name, locks.Options{
+		Wait: wait,
+		OnWait: func(holder string) {
+			if holder == "" {
+				holder = "another run"
+			}
+			e.iconf(ctx, "⏳  ", "Waiting for lock '%s' held by %s\n", name, holder)
+		},
+	})
+	stopWaiting()
+	if err != nil {
+		if deadlineErr := e.checkDeadline(ctx); deadlineErr != nil {
+			return deadlineErr
+		}
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("lock '%s': %w", name, shell.ErrInterrupted)
+		}
+		return fmt.Errorf("lock '%s': %w", name, err)
+	}
+	e.iconf(ctx, "🔒 ", "Acquired lock '%s'\n", name)
+
+	bodyErr := e.executeLockBody(stmt, ctx)
+	releaseErr := lease.Release()
+	if bodyErr != nil {
+		if releaseErr != nil {
+			e.iconf(ctx, "⚠️  ", "Could not release lock '%s': %v\n", name, releaseErr)
+		}
+		return bodyErr
+	}
+	if errors.Is(releaseErr, locks.ErrNotHeld) {
+		return fmt.Errorf("lock '%s' expired while its statements ran; another run may have taken it", name)
+	}
+	if releaseErr != nil {
+		e.iconf(ctx, "⚠️  ", "Could not release lock '%s': %v\n", name, releaseErr)
+		return nil
+	}
+	e.iconf(ctx, "🔓 ", "Released lock '%s'\n", name)
+	return nil
+}
+
+// executeLockBody runs the statements of a lock block
+func (e *Engine) executeLockBody(stmt *statement.Lock, ctx *ExecutionContext) error {
+	for _, bodyStmt := range stmt.Body {
+		if err := e.executeStatement(bodyStmt, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Output Logging
+// This file implements persistent output logs:
+// - `run "cmd" logging to "file"` (per statement)
+// - `log output to "file"` (rest of the task)
+
+// executeLogOutput handles the `log output to "path"` statement.
+//
+// The file is prepared once (rotated or truncated per the target options) and
+// every later shell statement in the task appends its output to it.
+func (e *Engine) executeLogOutput(stmt *statement.LogOutput, ctx *ExecutionContext) error {
+	path, err := e.resolveLogPath(stmt.Target.Path, ctx)
+	if err != nil {
+		return fmt.Errorf("log output: %w", err)
+	}
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would log task output to: %s\n", path)
+		return nil
+	}
+
+	file, err := openLogFile(path, stmt.Target)
+	if err != nil {
+		return fmt.Errorf("log output: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("log output: %w", err)
+	}
+
+	if e.verbose {
+		e.iconf(ctx, "📝 ", "Logging task output to: %s\n", path)
+	}
+
+	ctx.TaskLogFile = path
+	return nil
+}
+
+// openShellLogs returns the writer that receives a copy of a shell statement's
+// output (its own `logging to` file and/or the task log). The writer masks
+// the run's secrets like the console does. The returned close function must
+// always be called; the writer is nil when nothing is logged.
+func (e *Engine) openShellLogs(shellStmt *statement.Shell, ctx *ExecutionContext) (io.Writer, func(), error) {
+	var files []*os.File
+	var masker *secretMasker
+	closeAll := func() {
+		if masker != nil {
+			masker.flush()
+		}
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}
+
+	if shellStmt.Log != nil {
+		path, err := e.resolveLogPath(shellStmt.Log.Path, ctx)
+		if err != nil {
+			return nil, closeAll, fmt.Errorf("logging to: %w", err)
+		}
+		file, err := openLogFile(path, *shellStmt.Log)
+		if err != nil {
+			return nil, closeAll, fmt.Errorf("logging to: %w", err)
+		}
+		files = append(files, file)
+	}
+
+	if ctx != nil && ctx.TaskLogFile != "" && !shellStmt.Attached {
+		file, err := openLogFile(ctx.TaskLogFile, statement.LogTarget{Append: true})
+		if err != nil {
+			closeAll()
+			return nil, func() {}, fmt.Errorf("log output: %w", err)
+		}
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil, closeAll, nil
+	}
+	writers := make([]io.Writer, len(files))
+	for i, f := range files {
+		writers[i] = f
+	}
+	masker = newSecretMasker(io.MultiWriter(writers...), e.credentials.secrets)
+	return masker, closeAll, nil
+}
+
+// writeShellLogDryRun reports where a shell statement would log in dry-run mode
+func (e *Engine) writeShellLogDryRun(shellStmt *statement.Shell, ctx *ExecutionContext) {
+	if shellStmt.Log == nil {
+		return
+	}
+	path, err := e.resolveLogPath(shellStmt.Log.Path, ctx)
+	if err != nil {
+		path = shellStmt.Log.Path
+	}
+	_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would log output to: %s\n", path)
+}
+
+// resolveLogPath expands {task} and variables in a log path and resolves it
+// against the task working directory
+func (e *Engine) resolveLogPath(rawPath string, ctx *ExecutionContext) (string, error) {
+	if ctx != nil && strings.Contains(rawPath, "{task}") {
+		rawPath = strings.ReplaceAll(rawPath, "{task}", logFileTaskName(ctx.CurrentTask))
+	}
+	path, err := e.interpolateVariablesWithError(rawPath, ctx)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(path) == "" {
+		return "", fmt.Errorf("log path is empty")
+	}
+	return e.resolveFilesystemPath(path, ctx), nil
+}
+
+// logFileTaskName makes a task name safe to use as a file name
+func logFileTaskName(name string) string {
+	if name == "" {
+		return "task"
+	}
+	return strings.NewReplacer("/", "-", "\\", "-", ":", "-", " ", "-").Replace(name)
+}
+
+// openLogFile opens path for writing, creating parent directories.
+// Append keeps existing content; otherwise the file is truncated after
+// rotating up to Keep previous logs to path.1 .. path.N.
+func openLogFile(path string, target statement.LogTarget) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if target.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	} else if target.Keep > 0 {
+		if err := rotateLogFiles(path, target.Keep); err != nil {
+			return nil, err
+		}
+	}
+
+	// #nosec G304 -- log destinations are authored in the drun file.
+	file, err := os.OpenFile(path, flags, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+	return file, nil
+}
+
+// rotateLogFiles shifts path -> path.1 -> ... -> path.keep, dropping the oldest
+func rotateLogFiles(path string, keep int) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err := os.Remove(fmt.Sprintf("%s.%d", path, keep)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	for i := keep - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	return nil
+}
@@ -27,29 +27,29 @@ func (e *Engine) executeNetwork(networkStmt *statement.Network, ctx *ExecutionCo
 	}
 
 	if e.dryRun {
-		return e.buildNetworkCommand(networkStmt.Action, target, port, condition, options, true)
+		return e.buildNetworkCommand(networkStmt.Action, target, port, condition, options, true, ctx)
 	}
 
 	// Show what we're about to do with appropriate emoji
 	switch networkStmt.Action {
 	case "health_check":
-		_, _ = fmt.Fprintf(e.output, "🏥  Health check: %s\n", target)
+		e.iconf(ctx, "🏥  ", "Health check: %s\n", target)
 	case "wait_for_service":
-		_, _ = fmt.Fprintf(e.output, "⏳  Waiting for service: %s\n", target)
+		e.iconf(ctx, "⏳  ", "Waiting for service: %s\n", target)
 	case "port_check":
 		if port != "" {
-			_, _ = fmt.Fprintf(e.output, "🔌 Port check: %s:%s\n", target, port)
+			e.iconf(ctx, "🔌 ", "Port check: %s:%s\n", target, port)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "🔌 Connection test: %s\n", target)
+			e.iconf(ctx, "🔌 ", "Connection test: %s\n", target)
 		}
 	case "ping":
-		_, _ = fmt.Fprintf(e.output, "🏓 Ping: %s\n", target)
+		e.iconf(ctx, "🏓 ", "Ping: %s\n", target)
 	default:
-		_, _ = fmt.Fprintf(e.output, "🌐  Network operation: %s on %s\n", networkStmt.Action, target)
+		e.iconf(ctx, "🌐  ", "Network operation: %s on %s\n", networkStmt.Action, target)
 	}
 
 	// Build and execute the actual network command
-	return e.buildNetworkCommand(networkStmt.Action, target, port, condition, options, false)
+	return e.buildNetworkCommand(networkStmt.Action, target, port, condition, options, false, ctx)
 }
 
 // executeDownload executes file download operations using native Go HTTP client
@@ -79,81 +79,92 @@ func (e *Engine) executeDownload(downloadStmt *statement.Download, ctx *Executio
 	// Check if file exists and handle overwrite
 	if !downloadStmt.AllowOverwrite && e.fileExists(path, ctx) {
 		errMsg := fmt.Sprintf("file already exists: %s (use 'allow overwrite' to replace)", path)
-		_, _ = fmt.Fprintf(e.output, "❌  %s\n", errMsg)
+		e.iconf(ctx, "❌  ", "%s\n", errMsg)
 		return fmt.Errorf("%s", errMsg)
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would download %s to %s", url, path)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would download %s to %s", url, path)
 		if downloadStmt.AllowOverwrite {
-			_, _ = fmt.Fprintf(e.output, " (overwrite allowed)")
+			_, _ = fmt.Fprintf(e.out(ctx), " (overwrite allowed)")
 		}
 		if len(downloadStmt.AllowPermissions) > 0 {
-			_, _ = fmt.Fprintf(e.output, " with permissions: ")
+			_, _ = fmt.Fprintf(e.out(ctx), " with permissions: ")
 			for i, perm := range downloadStmt.AllowPermissions {
 				if i > 0 {
-					_, _ = fmt.Fprintf(e.output, ", ")
+					_, _ = fmt.Fprintf(e.out(ctx), ", ")
 				}
-				_, _ = fmt.Fprintf(e.output, "%v to %v", perm.Permissions, perm.Targets)
+				_, _ = fmt.Fprintf(e.out(ctx), "%v to %v", perm.Permissions, perm.Targets)
 			}
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 		return nil
 	}
 
+	// Relative paths resolve like those of file statements
+	path = e.resolveFilesystemPath(path, ctx)
+
 	// Show what we're about to do
-	_, _ = fmt.Fprintf(e.output, "⬇️  Downloading: %s\n", url)
-	_, _ = fmt.Fprintf(e.output, "   → %s\n", path)
+	e.iconf(ctx, "⬇️  ", "Downloading: %s\n", url)
+	_, _ = fmt.Fprintf(e.out(ctx), "   → %s\n", path)
 
 	// Perform the download with progress tracking
-	err := e.downloadFileWithProgress(url, path, headers, auth, options)
+	err := e.downloadFileWithProgress(url, path, headers, auth, options, ctx)
 	if err != nil {
-		_, _ = fmt.Fprintf(e.output, "❌  Download failed: %v\n", err)
+		e.iconf(ctx, "❌  ", "Download failed: %v\n", err)
 		return fmt.Errorf("download failed: %w", err)
 	}
 
+	// Convert domain PermissionSpec to ast.PermissionSpec
+	var astPerms []ast.PermissionSpec
+	for _, perm := range downloadStmt.AllowPermissions {
+		astPerms = append(astPerms, ast.PermissionSpec{
+			Permissions: perm.Permissions,
+			Targets:     perm.Targets,
+		})
+	}
+
 	// Extract archive if requested
 	if downloadStmt.ExtractTo != "" {
-		extractTo := e.interpolateVariables(downloadStmt.ExtractTo, ctx)
-		_, _ = fmt.Fprintf(e.output, "📦  Extracting archive to: %s\n", extractTo)
+		extractTo := e.resolveFilesystemPath(e.interpolateVariables(downloadStmt.ExtractTo, ctx), ctx)
+		e.iconf(ctx, "📦  ", "Extracting archive to: %s\n", extractTo)
 
-		err = e.extractArchive(path, extractTo)
+		only := make([]string, len(downloadStmt.Only))
+		for i, pattern := range downloadStmt.Only {
+			only[i] = e.interpolateVariables(pattern, ctx)
+		}
+		err = e.extractArchive(path, extractTo, extractOptions{
+			strip:       downloadStmt.StripComponents,
+			only:        only,
+			permissions: astPerms,
+		})
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "❌  Extraction failed: %v\n", err)
+			e.iconf(ctx, "❌  ", "Extraction failed: %v\n", err)
 			return fmt.Errorf("extraction failed: %w", err)
 		}
 
-		_, _ = fmt.Fprintf(e.output, "✅  Extraction completed\n")
+		e.iconf(ctx, "✅  ", "Extraction completed\n")
 
 		// Remove archive if requested
 		if downloadStmt.RemoveArchive {
-			_, _ = fmt.Fprintf(e.output, "🗑️  Removing archive: %s\n", path)
+			e.iconf(ctx, "🗑️  ", "Removing archive: %s\n", path)
 			err = os.Remove(path)
 			if err != nil {
-				_, _ = fmt.Fprintf(e.output, "⚠️  Warning: Failed to remove archive: %v\n", err)
+				e.iconf(ctx, "⚠️  ", "Warning: Failed to remove archive: %v\n", err)
 			} else {
-				_, _ = fmt.Fprintf(e.output, "✅  Archive removed\n")
+				e.iconf(ctx, "✅  ", "Archive removed\n")
 			}
 		}
-	} else {
-		// Apply file permissions if specified (only for non-extracted files)
-		if len(downloadStmt.AllowPermissions) > 0 {
-			// Convert domain PermissionSpec to ast.PermissionSpec
-			var astPerms []ast.PermissionSpec
-			for _, perm := range downloadStmt.AllowPermissions {
-				astPerms = append(astPerms, ast.PermissionSpec{
-					Permissions: perm.Permissions,
-					Targets:     perm.Targets,
-				})
-			}
-			err = e.applyFilePermissions(path, astPerms)
-			if err != nil {
-				_, _ = fmt.Fprintf(e.output, "⚠️  Warning: Failed to set permissions: %v\n", err)
-				// Don't fail the download, just warn
-			}
+	} else if len(astPerms) > 0 {
+		// Apply file permissions if specified; extracted files get them
+		// while they are extracted
+		err = e.applyFilePermissions(path, astPerms, ctx)
+		if err != nil {
+			e.iconf(ctx, "⚠️  ", "Warning: Failed to set permissions: %v\n", err)
+			// Don't fail the download, just warn
 		}
 	}
 
-	_, _ = fmt.Fprintf(e.output, "✅  Downloaded successfully to: %s\n", path)
+	e.iconf(ctx, "✅  ", "Downloaded successfully to: %s\n", path)
 	return nil
 }
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Completion Notification
+// This file implements the notification shown when a run finishes:
+// - `--notify` (every run of the invocation)
+// - `notify me when done` (runs that reach the statement)
+
+// Notifier shows a desktop notification with the given title and message
+type Notifier func(title, message string) error
+
+// executeNotify handles the `notify me when done` statement. It only marks the
+// execution; the notification is sent once every target has finished.
+func (e *Engine) executeNotify(_ *statement.Notify, ctx *ExecutionContext) error {
+	if e.dryRun {
+		_, _ = fmt.Fprintln(e.out(ctx), "[DRY RUN] Would notify when done")
+		return nil
+	}
+	if ctx.NotifyWhenDone != nil {
+		ctx.NotifyWhenDone.Store(true)
+	}
+	return nil
+}
+
+// notifyCompletion rings the terminal bell and shows a desktop notification
+// when --notify is set or a task ran `notify me when done`. A notification
+// that cannot be shown never fails the run.
+func (e *Engine) notifyCompletion(ctx *ExecutionContext, targets []TaskTarget, started time.Time, runErr error) {
+	if e.dryRun || (!e.notify && !ctx.NotifyWhenDone.Load()) {
+		return
+	}
+
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	elapsed := formatDuration(time.Since(started))
+
+	title := fmt.Sprintf("drun: %s finished", strings.Join(names, ", "))
+	message := "Completed successfully in " + elapsed
+	if runErr != nil {
+		title = fmt.Sprintf("drun: %s failed", strings.Join(names, ", "))
+		message = fmt.Sprintf("Failed after %s: %v", elapsed, runErr)
+	}
+
+	_, _ = fmt.Fprint(e.out(ctx), "\a")
+	if err := e.notifier(title, message); err != nil && e.verbose {
+		e.iconf(ctx, "⚠️  ", "could not show desktop notification: %v\n", err)
+	}
+}
+
+// desktopNotification shows a notification with the tool each platform ships:
+// osascript on macOS, notify-send on Linux and PowerShell on Windows.
+func desktopNotification(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(5000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, powershellQuote(title), powershellQuote(message))
+		// The balloon stays up for a few seconds; do not hold the run open
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).Start()
+	default:
+		cmd = exec.Command("notify-send", "--app-name=drun", title, message)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v: %s", cmd.Args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// powershellQuote quotes s as a PowerShell single-quoted string
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
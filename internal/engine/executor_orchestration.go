@@ -204,7 +204,7 @@ func (oe *OrchestrationExecutor) StartService(ctx context.Context, execCtx *Exec
 
 	alreadyHealthy, stateErr := oe.isServiceRunningAndHealthy(ctx, service)
 	if stateErr != nil && oe.engine != nil && oe.engine.verbose {
-		_, _ = fmt.Fprintf(oe.engine.output, "    [VERBOSE] Unable to confirm current state for %s: %v\n", serviceName, stateErr)
+		_, _ = fmt.Fprintf(oe.engine.out(execCtx), "    [VERBOSE] Unable to confirm current state for %s: %v\n", serviceName, stateErr)
 	}
 	if alreadyHealthy && stateErr == nil {
 		service.MarkHealthy()
@@ -448,8 +448,8 @@ func (oe *OrchestrationExecutor) executeCommand(ctx context.Context, cmdStr, wor
 		// For TTY allocation, connect stdin
 		cmd.Stdin = os.Stdin
 		if oe.engine != nil && oe.engine.output != nil {
-			cmd.Stdout = oe.engine.output
-			cmd.Stderr = oe.engine.output
+			cmd.Stdout = oe.engine.out(nil)
+			cmd.Stderr = oe.engine.out(nil)
 		}
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("command failed: %w", err)
@@ -24,12 +24,12 @@ import (
 // executeOrchestration executes orchestration action statements from task bodies
 func (e *Engine) executeOrchestration(orchestrStmt *statement.Orchestration, ctx *ExecutionContext) error {
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute orchestration: %s %s\n", orchestrStmt.GroupName, orchestrStmt.Action)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute orchestration: %s %s\n", orchestrStmt.GroupName, orchestrStmt.Action)
 		return nil
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "[VERBOSE] Orchestration: %s %s\n", orchestrStmt.GroupName, orchestrStmt.Action)
+		_, _ = fmt.Fprintf(e.out(ctx), "[VERBOSE] Orchestration: %s %s\n", orchestrStmt.GroupName, orchestrStmt.Action)
 	}
 
 	// Find the orchestration group
@@ -115,7 +115,7 @@ func (e *Engine) executeOrchestration(orchestrStmt *statement.Orchestration, ctx
 		}
 
 		// Check that all dependencies before the starting service are running and healthy
-		_, _ = fmt.Fprintf(e.output, "🔍  Checking dependencies before '%s'...\n", resolved)
+		e.iconf(ctx, "🔍  ", "Checking dependencies before '%s'...\n", resolved)
 		for i := 0; i < startIdx; i++ {
 			serviceName := orderedServices[i]
 			service := services[serviceName]
@@ -124,11 +124,11 @@ func (e *Engine) executeOrchestration(orchestrStmt *statement.Orchestration, ctx
 			if err != nil || !healthy {
 				return fmt.Errorf("cannot start from '%s': dependency '%s' is not running or healthy (run full 'up' first)", resolved, serviceName)
 			}
-			_, _ = fmt.Fprintf(e.output, "  ✓  %s is running and healthy\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "  ✓  %s is running and healthy\n", serviceName)
 		}
 
 		// Filter to start from the specified service onwards
-		_, _ = fmt.Fprintf(e.output, "✅  All dependencies satisfied. Starting from '%s'...\n\n", resolved)
+		e.iconf(ctx, "✅  ", "All dependencies satisfied. Starting from '%s'...\n\n", resolved)
 		orderedServices = orderedServices[startIdx:]
 	}
 
@@ -162,45 +162,45 @@ func (e *Engine) executeOrchestration(orchestrStmt *statement.Orchestration, ctx
 		useCache := resolveCacheOption(orchestrStmt.Options, true)
 		return e.orchestrateRecreate(ctx, orchestration, orderedServices, services, useCache)
 	case "status":
-		return e.orchestrateStatus(orchestration, orderedServices, services)
+		return e.orchestrateStatus(ctx, orchestration, orderedServices, services)
 	case "show endpoints", "endpoints":
-		return e.orchestrateShowEndpoints(orchestration, orderedServices, services)
+		return e.orchestrateShowEndpoints(ctx, orchestration, orderedServices, services)
 	case "health", "health_check":
-		return e.orchestrateHealth(orchestration, orderedServices, services)
+		return e.orchestrateHealth(ctx, orchestration, orderedServices, services)
 	case "logs":
 		return e.orchestrateLogs(ctx, orchestration, orderedServices, services)
 	case "build":
 		useCache := resolveCacheOption(orchestrStmt.Options, true)
 		return e.orchestrateBuild(ctx, orchestration, orderedServices, services, useCache)
 	case "pull":
-		return e.orchestratePull(orchestration, orderedServices, services)
+		return e.orchestratePull(ctx, orchestration, orderedServices, services)
 	case "down":
 		errDown := e.orchestrateDown(ctx, orchestration, orderedServices, services)
 		errHook := e.runOrchestrationHook(ctx, orchestration.PostTask, orchestration.Name, "post")
 		return errors.Join(errDown, errHook)
 	case "clone_repositories", "clone repositories":
-		return e.orchestrateCloneRepositories(orchestration, orderedServices, services)
+		return e.orchestrateCloneRepositories(ctx, orchestration, orderedServices, services)
 	case "update repositories":
 		branchFilter := ""
 		if branch, ok := orchestrStmt.Options["branch"]; ok {
 			branchFilter = branch
 		}
-		return e.orchestrateUpdateRepositories(context.Background(), orchestration, orderedServices, services, branchFilter)
+		return e.orchestrateUpdateRepositories(context.Background(), ctx, orchestration, orderedServices, services, branchFilter)
 	case "list branches":
 		branchFilter := ""
 		if branch, ok := orchestrStmt.Options["branch"]; ok {
 			branchFilter = e.interpolateVariables(branch, ctx)
 		}
-		return e.orchestrateListBranches(context.Background(), orchestration, orderedServices, services, branchFilter)
+		return e.orchestrateListBranches(context.Background(), ctx, orchestration, orderedServices, services, branchFilter)
 	case "switch branch to default":
 		// Check if a service filter was specified (e.g., "orchestrate group switch branch to default service name")
 		serviceFilter := ""
 		if len(orchestrStmt.ServiceFilters) > 0 {
 			serviceFilter = e.interpolateVariables(orchestrStmt.ServiceFilters[0], ctx)
 		}
-		return e.orchestrateSwitchToDefault(context.Background(), orchestration, orderedServices, services, serviceFilter)
+		return e.orchestrateSwitchToDefault(context.Background(), ctx, orchestration, orderedServices, services, serviceFilter)
 	case "set all branches to default":
-		return e.orchestrateSetAllDefault(context.Background(), orchestration, orderedServices, services)
+		return e.orchestrateSetAllDefault(context.Background(), ctx, orchestration, orderedServices, services)
 	default:
 		return fmt.Errorf("unknown orchestration action: %s", orchestrStmt.Action)
 	}
@@ -283,26 +283,26 @@ func (e *Engine) resolveServiceOrder(serviceNames []string, services map[string]
 
 // orchestrateStart starts services in dependency order
 func (e *Engine) orchestrateStart(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🚀  Starting orchestration: %s\n", orch.Name)
+	e.iconf(ctx, "🚀  ", "Starting orchestration: %s\n", orch.Name)
 
 	// Check and provision Docker networks before starting services
-	if err := e.checkAndProvisionNetworks(services); err != nil {
+	if err := e.checkAndProvisionNetworks(ctx, services); err != nil {
 		return fmt.Errorf("network provisioning failed: %w", err)
 	}
 
 	// Check DNS resolution for specified domains
-	if err := e.checkDNSResolution(orch); err != nil {
+	if err := e.checkDNSResolution(ctx, orch); err != nil {
 		// DNS check failures are warnings, not errors
-		_, _ = fmt.Fprintf(e.output, "⚠️  %v\n\n", err)
+		e.iconf(ctx, "⚠️  ", "%v\n\n", err)
 	}
 
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
-		_, _ = fmt.Fprintf(e.output, "  ▸ Starting %s...\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "  ▸ Starting %s...\n", serviceName)
 
 		alreadyHealthy, stateErr := e.serviceIsRunningAndHealthy(service)
 		if stateErr != nil && e.verbose {
-			_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Unable to confirm current state for %s: %v\n", serviceName, stateErr)
+			_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Unable to confirm current state for %s: %v\n", serviceName, stateErr)
 		}
 
 		// Check for repository updates first (if repository is configured)
@@ -340,14 +340,14 @@ func (e *Engine) orchestrateStart(ctx *ExecutionContext, orch *ast.OrchestrateSt
 			}
 
 			if e.verbose {
-				_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Checking repository at: %s\n", fullPath)
+				_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Checking repository at: %s\n", fullPath)
 			}
 
 			if _, err := os.Stat(filepath.Join(fullPath, ".git")); os.IsNotExist(err) {
 				// Repository doesn't exist, needs to be cloned
 				needsClone = true
 				if e.verbose {
-					_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Repository not found at %s, will clone\n", fullPath)
+					_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Repository not found at %s, will clone\n", fullPath)
 				}
 			} else {
 				// Repository exists - check if we should update it
@@ -355,22 +355,22 @@ func (e *Engine) orchestrateStart(ctx *ExecutionContext, orch *ast.OrchestrateSt
 				if !service.Repository.UpdateOnStart {
 					// Skip update check if explicitly disabled
 					if e.verbose {
-						_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Repository update disabled for %s (update on start: false)\n", serviceName)
+						_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Repository update disabled for %s (update on start: false)\n", serviceName)
 					}
 				} else {
 					// Check for updates
-					_, _ = fmt.Fprintf(e.output, "    🔍  Checking for repository updates for %s...\n", serviceName)
+					_, _ = fmt.Fprintf(e.out(ctx), "    🔍  Checking for repository updates for %s...\n", serviceName)
 
 					hasUpdates, err := repoManager.HasRemoteUpdates(context.Background(), repoConfig, service.Path)
 					if err != nil {
 						if e.verbose {
-							_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Unable to check for updates for %s: %v\n", serviceName, err)
+							_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Unable to check for updates for %s: %v\n", serviceName, err)
 						}
 						// If we can't check for updates, proceed with existing logic
 					} else {
 						hasRepoUpdates = hasUpdates
 						if hasUpdates {
-							_, _ = fmt.Fprintf(e.output, "    📥  Repository updates available for %s\n", serviceName)
+							_, _ = fmt.Fprintf(e.out(ctx), "    📥  Repository updates available for %s\n", serviceName)
 						}
 					}
 				}
@@ -379,7 +379,7 @@ func (e *Engine) orchestrateStart(ctx *ExecutionContext, orch *ast.OrchestrateSt
 
 		// If service is already healthy and no repository updates, skip it
 		if alreadyHealthy && stateErr == nil && !hasRepoUpdates && !needsClone {
-			_, _ = fmt.Fprintf(e.output, "    ✓  %s already running and healthy (no updates)\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s already running and healthy (no updates)\n", serviceName)
 			continue
 		}
 
@@ -409,21 +409,21 @@ func (e *Engine) orchestrateStart(ctx *ExecutionContext, orch *ast.OrchestrateSt
 
 			if needsClone {
 				// Repository doesn't exist, clone it
-				_, _ = fmt.Fprintf(e.output, "    📦  Cloning repository for %s...\n", serviceName)
-				_, _ = fmt.Fprintf(e.output, "    📂  Target directory: %s\n", service.Path)
+				_, _ = fmt.Fprintf(e.out(ctx), "    📦  Cloning repository for %s...\n", serviceName)
+				_, _ = fmt.Fprintf(e.out(ctx), "    📂  Target directory: %s\n", service.Path)
 				if err := repoManager.Clone(context.Background(), repoConfig, service.Path); err != nil {
 					return fmt.Errorf("failed to clone repository for service '%s': %w", serviceName, err)
 				}
 			} else if hasRepoUpdates {
 				// Repository exists and has updates, pull them
-				_, _ = fmt.Fprintf(e.output, "    📥  Pulling repository updates for %s...\n", serviceName)
-				_, _ = fmt.Fprintf(e.output, "    📂  Repository directory: %s\n", service.Path)
+				_, _ = fmt.Fprintf(e.out(ctx), "    📥  Pulling repository updates for %s...\n", serviceName)
+				_, _ = fmt.Fprintf(e.out(ctx), "    📂  Repository directory: %s\n", service.Path)
 				if err := repoManager.Update(context.Background(), repoConfig, service.Path); err != nil {
 					return fmt.Errorf("failed to update repository for service '%s': %w", serviceName, err)
 				}
 			}
 
-			_, _ = fmt.Fprintf(e.output, "    ✓  Repository ready for %s\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ✓  Repository ready for %s\n", serviceName)
 		}
 
 		// Run pre-task after repository is ready
@@ -432,82 +432,82 @@ func (e *Engine) orchestrateStart(ctx *ExecutionContext, orch *ast.OrchestrateSt
 		}
 
 		if service.Build != nil && service.Build.Required {
-			_, _ = fmt.Fprintf(e.output, "    🔨  Building %s...\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    🔨  Building %s...\n", serviceName)
 			if err := e.performServiceBuild(ctx, service, false, true); err != nil {
 				return fmt.Errorf("failed to build service '%s': %w", serviceName, err)
 			}
 		}
 
-		if err := e.startService(service); err != nil {
+		if err := e.startService(ctx, service); err != nil {
 			return fmt.Errorf("failed to start service '%s': %w", serviceName, err)
 		}
 
 		// Wait for health check if configured
 		if service.HealthCheck != nil {
-			_, _ = fmt.Fprintf(e.output, "    ⏳  Waiting for %s to become healthy...\n", serviceName)
-			if err := e.waitForHealth(service); err != nil {
-				_, _ = fmt.Fprintf(e.output, "    ⚠️  Health check failed for %s: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ⏳  Waiting for %s to become healthy...\n", serviceName)
+			if err := e.waitForHealth(ctx, service); err != nil {
+				_, _ = fmt.Fprintf(e.out(ctx), "    ⚠️  Health check failed for %s: %v\n", serviceName, err)
 				// Continue anyway unless circuit breaker is enabled
 			} else {
-				_, _ = fmt.Fprintf(e.output, "    ✓  %s is healthy\n", serviceName)
+				_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s is healthy\n", serviceName)
 			}
 		} else {
-			_, _ = fmt.Fprintf(e.output, "    ✓  %s started\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s started\n", serviceName)
 		}
 	}
 
-	_, _ = fmt.Fprintf(e.output, "✅  All services started successfully\n")
+	e.iconf(ctx, "✅  ", "All services started successfully\n")
 	return nil
 }
 
 // orchestrateStop stops services in reverse order
 func (e *Engine) orchestrateStop(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🛑  Stopping orchestration: %s\n", orch.Name)
+	e.iconf(ctx, "🛑  ", "Stopping orchestration: %s\n", orch.Name)
 
 	// Reverse order for shutdown
 	for i := len(orderedServices) - 1; i >= 0; i-- {
 		serviceName := orderedServices[i]
 		service := services[serviceName]
-		_, _ = fmt.Fprintf(e.output, "  ▸ Stopping %s...\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "  ▸ Stopping %s...\n", serviceName)
 
-		if err := e.stopService(service); err != nil {
-			_, _ = fmt.Fprintf(e.output, "    ⚠️  Failed to stop %s: %v\n", serviceName, err)
+		if err := e.stopService(ctx, service); err != nil {
+			_, _ = fmt.Fprintf(e.out(ctx), "    ⚠️  Failed to stop %s: %v\n", serviceName, err)
 			// Continue stopping other services
 		} else {
-			_, _ = fmt.Fprintf(e.output, "    ✓  %s stopped\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s stopped\n", serviceName)
 			if err := e.runServiceHook(ctx, service.PostTask, serviceName, "post"); err != nil {
 				return err
 			}
 		}
 	}
 
-	_, _ = fmt.Fprintf(e.output, "✅  All services stopped\n")
+	e.iconf(ctx, "✅  ", "All services stopped\n")
 	return nil
 }
 
 // orchestrateStatus shows status of all services
-func (e *Engine) orchestrateStatus(orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "📊  Status of orchestration: %s\n", orch.Name)
+func (e *Engine) orchestrateStatus(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
+	e.iconf(ctx, "📊  ", "Status of orchestration: %s\n", orch.Name)
 
 	// Check DNS resolution for specified domains
-	if err := e.checkDNSResolution(orch); err != nil {
+	if err := e.checkDNSResolution(ctx, orch); err != nil {
 		// DNS check failures are warnings, not errors
-		_, _ = fmt.Fprintf(e.output, "⚠️  %v\n\n", err)
+		e.iconf(ctx, "⚠️  ", "%v\n\n", err)
 	}
 
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
 		status := e.getServiceStatus(service)
-		_, _ = fmt.Fprintf(e.output, "  %s: %s\n", serviceName, status)
+		_, _ = fmt.Fprintf(e.out(ctx), "  %s: %s\n", serviceName, status)
 	}
 
 	return nil
 }
 
 // orchestrateShowEndpoints displays all service endpoints
-func (e *Engine) orchestrateShowEndpoints(orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🌐  Service endpoints for orchestration: %s\n", orch.Name)
-	_, _ = fmt.Fprintf(e.output, "\n")
+func (e *Engine) orchestrateShowEndpoints(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
+	e.iconf(ctx, "🌐  ", "Service endpoints for orchestration: %s\n", orch.Name)
+	_, _ = fmt.Fprintf(e.out(ctx), "\n")
 
 	var runningWithEndpoints []struct {
 		name     string
@@ -545,41 +545,41 @@ func (e *Engine) orchestrateShowEndpoints(orch *ast.OrchestrateStatement, ordere
 
 	// Display running services with endpoints
 	if len(runningWithEndpoints) > 0 {
-		_, _ = fmt.Fprintf(e.output, "✅  Running services:\n")
+		e.iconf(ctx, "✅  ", "Running services:\n")
 		for _, svc := range runningWithEndpoints {
-			_, _ = fmt.Fprintf(e.output, "   • %-20s %s\n", svc.name+":", svc.endpoint)
+			_, _ = fmt.Fprintf(e.out(ctx), "   • %-20s %s\n", svc.name+":", svc.endpoint)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	}
 
 	// Display running services without endpoints
 	if len(noEndpoint) > 0 {
-		_, _ = fmt.Fprintf(e.output, "ℹ️  Running (no endpoint configured):\n")
+		e.iconf(ctx, "ℹ️  ", "Running (no endpoint configured):\n")
 		for _, name := range noEndpoint {
-			_, _ = fmt.Fprintf(e.output, "   • %s\n", name)
+			_, _ = fmt.Fprintf(e.out(ctx), "   • %s\n", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	}
 
 	// Display stopped services
 	if len(stopped) > 0 {
-		_, _ = fmt.Fprintf(e.output, "⏹️  Stopped services:\n")
+		e.iconf(ctx, "⏹️  ", "Stopped services:\n")
 		for _, name := range stopped {
-			_, _ = fmt.Fprintf(e.output, "   • %s\n", name)
+			_, _ = fmt.Fprintf(e.out(ctx), "   • %s\n", name)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	}
 
 	if len(runningWithEndpoints) == 0 {
-		_, _ = fmt.Fprintf(e.output, "⚠️  No running services with endpoints found\n")
+		e.iconf(ctx, "⚠️  ", "No running services with endpoints found\n")
 	}
 
 	return nil
 }
 
 // orchestrateHealth checks health for all services
-func (e *Engine) orchestrateHealth(orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🏥  Health check for orchestration: %s\n", orch.Name)
+func (e *Engine) orchestrateHealth(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
+	e.iconf(ctx, "🏥  ", "Health check for orchestration: %s\n", orch.Name)
 
 	var unhealthy []string
 
@@ -587,16 +587,16 @@ func (e *Engine) orchestrateHealth(orch *ast.OrchestrateStatement, orderedServic
 		service := services[serviceName]
 
 		if service.HealthCheck == nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  no health check configured\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "  %s: ⚠️  no health check configured\n", serviceName)
 			continue
 		}
 
-		_, _ = fmt.Fprintf(e.output, "  %s: checking health...\n", serviceName)
-		if err := e.waitForHealth(service); err != nil {
-			_, _ = fmt.Fprintf(e.output, "    ⚠️  %s is unhealthy: %v\n", serviceName, err)
+		_, _ = fmt.Fprintf(e.out(ctx), "  %s: checking health...\n", serviceName)
+		if err := e.waitForHealth(ctx, service); err != nil {
+			_, _ = fmt.Fprintf(e.out(ctx), "    ⚠️  %s is unhealthy: %v\n", serviceName, err)
 			unhealthy = append(unhealthy, fmt.Sprintf("%s (%v)", serviceName, err))
 		} else {
-			_, _ = fmt.Fprintf(e.output, "    ✓  %s is healthy\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s is healthy\n", serviceName)
 		}
 	}
 
@@ -604,24 +604,24 @@ func (e *Engine) orchestrateHealth(orch *ast.OrchestrateStatement, orderedServic
 		return fmt.Errorf("services unhealthy: %s", strings.Join(unhealthy, ", "))
 	}
 
-	_, _ = fmt.Fprintf(e.output, "✅  All services healthy\n")
+	e.iconf(ctx, "✅  ", "All services healthy\n")
 	return nil
 }
 
 // orchestrateLogs displays logs for the selected services
 func (e *Engine) orchestrateLogs(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "📝  Logs for orchestration: %s\n", orch.Name)
+	e.iconf(ctx, "📝  ", "Logs for orchestration: %s\n", orch.Name)
 
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
 
 		if e.dryRun {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would show logs for %s\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would show logs for %s\n", serviceName)
 			continue
 		}
 
-		_, _ = fmt.Fprintf(e.output, "  ▸ Showing logs for %s...\n", serviceName)
-		if err := e.runDockerCompose(service, "logs"); err != nil {
+		_, _ = fmt.Fprintf(e.out(ctx), "  ▸ Showing logs for %s...\n", serviceName)
+		if err := e.runDockerCompose(ctx, service, "logs"); err != nil {
 			return fmt.Errorf("failed to retrieve logs for '%s': %w", serviceName, err)
 		}
 	}
@@ -630,24 +630,24 @@ func (e *Engine) orchestrateLogs(ctx *ExecutionContext, orch *ast.OrchestrateSta
 }
 
 // orchestrateCloneRepositories reports repository cloning order
-func (e *Engine) orchestrateCloneRepositories(orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "📦  Repository cloning plan for orchestration: %s\n", orch.Name)
+func (e *Engine) orchestrateCloneRepositories(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
+	e.iconf(ctx, "📦  ", "Repository cloning plan for orchestration: %s\n", orch.Name)
 
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
 		if service.Repository == nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ℹ️  no repository configured, skipping\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "  %s: ℹ️  no repository configured, skipping\n", serviceName)
 			continue
 		}
 
-		_, _ = fmt.Fprintf(e.output, "  %s: %s", serviceName, service.Repository.URL)
+		_, _ = fmt.Fprintf(e.out(ctx), "  %s: %s", serviceName, service.Repository.URL)
 		if service.Repository.Branch != "" {
-			_, _ = fmt.Fprintf(e.output, " (branch %s)", service.Repository.Branch)
+			_, _ = fmt.Fprintf(e.out(ctx), " (branch %s)", service.Repository.Branch)
 		}
 		if service.Repository.Tag != "" {
-			_, _ = fmt.Fprintf(e.output, " (tag %s)", service.Repository.Tag)
+			_, _ = fmt.Fprintf(e.out(ctx), " (tag %s)", service.Repository.Tag)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 	}
 
 	if !e.dryRun {
@@ -658,10 +658,10 @@ func (e *Engine) orchestrateCloneRepositories(orch *ast.OrchestrateStatement, or
 }
 
 // orchestrateUpdateRepositories updates repositories for services
-func (e *Engine) orchestrateUpdateRepositories(ctx context.Context, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, branchFilter string) error {
-	_, _ = fmt.Fprintf(e.output, "🔄  Updating repositories for orchestration: %s\n", orch.Name)
+func (e *Engine) orchestrateUpdateRepositories(ctx context.Context, execCtx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, branchFilter string) error {
+	e.iconf(execCtx, "🔄  ", "Updating repositories for orchestration: %s\n", orch.Name)
 	if branchFilter != "" {
-		_, _ = fmt.Fprintf(e.output, "  Filter: only updating services on branch '%s'\n", branchFilter)
+		_, _ = fmt.Fprintf(e.out(execCtx), "  Filter: only updating services on branch '%s'\n", branchFilter)
 	}
 
 	// Get working directory
@@ -680,7 +680,7 @@ func (e *Engine) orchestrateUpdateRepositories(ctx context.Context, orch *ast.Or
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
 		if service.Repository == nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ℹ️  no repository configured, skipping\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ℹ️  no repository configured, skipping\n", serviceName)
 			skippedCount++
 			continue
 		}
@@ -710,12 +710,12 @@ func (e *Engine) orchestrateUpdateRepositories(ctx context.Context, orch *ast.Or
 		}
 
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "  [VERBOSE] Checking repository for %s at: %s\n", serviceName, fullPath)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  [VERBOSE] Checking repository for %s at: %s\n", serviceName, fullPath)
 		}
 
 		gitPath := filepath.Join(fullPath, ".git")
 		if _, err := os.Stat(gitPath); os.IsNotExist(err) {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  repository not cloned locally at %s, skipping update\n", serviceName, fullPath)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  repository not cloned locally at %s, skipping update\n", serviceName, fullPath)
 			skippedCount++
 			continue
 		}
@@ -724,7 +724,7 @@ func (e *Engine) orchestrateUpdateRepositories(ctx context.Context, orch *ast.Or
 		if branchFilter != "" {
 			currentBranch, err := repoManager.GetCurrentBranch(ctx, service.Path)
 			if err != nil {
-				_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
+				_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
 				errorCount++
 				continue
 			}
@@ -734,26 +734,26 @@ func (e *Engine) orchestrateUpdateRepositories(ctx context.Context, orch *ast.Or
 			normalizedFilter := normalizeBranchName(branchFilter)
 
 			if normalizedCurrent != normalizedFilter {
-				_, _ = fmt.Fprintf(e.output, "  %s: ⏭️  on branch '%s' (not '%s'), skipping\n", serviceName, currentBranch, branchFilter)
+				_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⏭️  on branch '%s' (not '%s'), skipping\n", serviceName, currentBranch, branchFilter)
 				skippedCount++
 				continue
 			}
 		}
 
 		// Update the repository
-		_, _ = fmt.Fprintf(e.output, "  %s: 🔄  updating...", serviceName)
+		_, _ = fmt.Fprintf(e.out(execCtx), "  %s: 🔄  updating...", serviceName)
 		if err := repoManager.Update(ctx, repoConfig, service.Path); err != nil {
-			_, _ = fmt.Fprintf(e.output, " ❌  failed: %v\n", err)
+			_, _ = fmt.Fprintf(e.out(execCtx), " ❌  failed: %v\n", err)
 			errorCount++
 			continue
 		}
 
 		currentBranch, _ := repoManager.GetCurrentBranch(ctx, service.Path)
-		_, _ = fmt.Fprintf(e.output, " ✅  updated (branch: %s)\n", currentBranch)
+		_, _ = fmt.Fprintf(e.out(execCtx), " ✅  updated (branch: %s)\n", currentBranch)
 		updatedCount++
 	}
 
-	_, _ = fmt.Fprintf(e.output, "\n📊  Summary: %d updated, %d skipped, %d errors\n", updatedCount, skippedCount, errorCount)
+	e.iconf(execCtx, "\n📊  ", "Summary: %d updated, %d skipped, %d errors\n", updatedCount, skippedCount, errorCount)
 
 	if errorCount > 0 {
 		return fmt.Errorf("repository update completed with %d error(s)", errorCount)
@@ -774,11 +774,11 @@ func normalizeBranchName(branch string) string {
 
 // orchestrateListBranches lists repositories and their current branches
 // If branchFilter is provided, only shows repositories on that branch
-func (e *Engine) orchestrateListBranches(ctx context.Context, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, branchFilter string) error {
+func (e *Engine) orchestrateListBranches(ctx context.Context, execCtx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, branchFilter string) error {
 	if branchFilter != "" {
-		_, _ = fmt.Fprintf(e.output, "🌿  Repositories on branch '%s' for orchestration: %s\n", branchFilter, orch.Name)
+		e.iconf(execCtx, "🌿  ", "Repositories on branch '%s' for orchestration: %s\n", branchFilter, orch.Name)
 	} else {
-		_, _ = fmt.Fprintf(e.output, "🌿  Branch status for orchestration: %s\n", orch.Name)
+		e.iconf(execCtx, "🌿  ", "Branch status for orchestration: %s\n", orch.Name)
 	}
 
 	// Get working directory
@@ -814,7 +814,7 @@ func (e *Engine) orchestrateListBranches(ctx context.Context, orch *ast.Orchestr
 		gitPath := filepath.Join(fullPath, ".git")
 		if _, err := os.Stat(gitPath); os.IsNotExist(err) {
 			if branchFilter == "" {
-				_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  repository not cloned locally\n", serviceName)
+				_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  repository not cloned locally\n", serviceName)
 			}
 			errors = append(errors, fmt.Sprintf("%s (not cloned)", serviceName))
 			continue
@@ -824,7 +824,7 @@ func (e *Engine) orchestrateListBranches(ctx context.Context, orch *ast.Orchestr
 		currentBranch, err := repoManager.GetCurrentBranch(ctx, service.Path)
 		if err != nil {
 			if branchFilter == "" {
-				_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
+				_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
 			}
 			errors = append(errors, fmt.Sprintf("%s (%v)", serviceName, err))
 			continue
@@ -861,36 +861,36 @@ func (e *Engine) orchestrateListBranches(ctx context.Context, orch *ast.Orchestr
 	// Display results
 	if len(matchingRepos) > 0 {
 		if branchFilter != "" {
-			_, _ = fmt.Fprintf(e.output, "\n✅  Repositories on branch '%s':\n", branchFilter)
+			e.iconf(execCtx, "\n✅  ", "Repositories on branch '%s':\n", branchFilter)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "\n📋 Repository branches:\n")
+			e.iconf(execCtx, "\n📋 ", "Repository branches:\n")
 		}
 		for _, item := range matchingRepos {
-			_, _ = fmt.Fprintf(e.output, "  • %-20s  branch: %s\n", item.serviceName+":", item.currentBranch)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  • %-20s  branch: %s\n", item.serviceName+":", item.currentBranch)
 		}
 	} else if branchFilter != "" {
-		_, _ = fmt.Fprintf(e.output, "\n⚠️  No repositories found on branch '%s'\n", branchFilter)
+		e.iconf(execCtx, "\n⚠️  ", "No repositories found on branch '%s'\n", branchFilter)
 	}
 
 	if len(noRepo) > 0 && branchFilter == "" {
-		_, _ = fmt.Fprintf(e.output, "\nℹ️  Services without repository:\n")
+		e.iconf(execCtx, "\nℹ️  ", "Services without repository:\n")
 		for _, name := range noRepo {
-			_, _ = fmt.Fprintf(e.output, "  • %s\n", name)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  • %s\n", name)
 		}
 	}
 
 	if len(errors) > 0 && branchFilter == "" {
-		_, _ = fmt.Fprintf(e.output, "\n❌  Errors:\n")
+		e.iconf(execCtx, "\n❌  ", "Errors:\n")
 		for _, errMsg := range errors {
-			_, _ = fmt.Fprintf(e.output, "  • %s\n", errMsg)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  • %s\n", errMsg)
 		}
 	}
 
 	if branchFilter != "" {
-		_, _ = fmt.Fprintf(e.output, "\n📊  Summary: %d on branch '%s', %d skipped, %d without repo, %d errors\n",
+		e.iconf(execCtx, "\n📊  ", "Summary: %d on branch '%s', %d skipped, %d without repo, %d errors\n",
 			len(matchingRepos), branchFilter, len(skipped), len(noRepo), len(errors))
 	} else {
-		_, _ = fmt.Fprintf(e.output, "\n📊  Summary: %d repositories, %d without repo, %d errors\n",
+		e.iconf(execCtx, "\n📊  ", "Summary: %d repositories, %d without repo, %d errors\n",
 			len(matchingRepos), len(noRepo), len(errors))
 	}
 
@@ -898,10 +898,10 @@ func (e *Engine) orchestrateListBranches(ctx context.Context, orch *ast.Orchestr
 }
 
 // orchestrateSwitchToDefault switches a specific service (or all if no service specified) to the default branch
-func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, serviceFilter string) error {
-	_, _ = fmt.Fprintf(e.output, "🔄  Switching to default branch for orchestration: %s\n", orch.Name)
+func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, execCtx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, serviceFilter string) error {
+	e.iconf(execCtx, "🔄  ", "Switching to default branch for orchestration: %s\n", orch.Name)
 	if serviceFilter != "" {
-		_, _ = fmt.Fprintf(e.output, "  Filter: only switching service '%s'\n", serviceFilter)
+		_, _ = fmt.Fprintf(e.out(execCtx), "  Filter: only switching service '%s'\n", serviceFilter)
 	}
 
 	// Get working directory
@@ -925,7 +925,7 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 
 		service := services[serviceName]
 		if service.Repository == nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ℹ️  no repository configured, skipping\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ℹ️  no repository configured, skipping\n", serviceName)
 			skippedCount++
 			continue
 		}
@@ -953,7 +953,7 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 
 		gitPath := filepath.Join(fullPath, ".git")
 		if _, err := os.Stat(gitPath); os.IsNotExist(err) {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  repository not cloned locally, skipping\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  repository not cloned locally, skipping\n", serviceName)
 			skippedCount++
 			continue
 		}
@@ -961,7 +961,7 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 		// Get current branch
 		currentBranch, err := repoManager.GetCurrentBranch(ctx, service.Path)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
 			errorCount++
 			continue
 		}
@@ -969,7 +969,7 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 		// Get default branch
 		defaultBranch, err := repoManager.GetDefaultBranch(ctx, repoConfig, service.Path)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to get default branch: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to get default branch: %v\n", serviceName, err)
 			errorCount++
 			continue
 		}
@@ -979,7 +979,7 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 		normalizedDefault := normalizeBranchName(defaultBranch)
 
 		if normalizedCurrent == normalizedDefault {
-			_, _ = fmt.Fprintf(e.output, "  %s: ✓  already on default branch (%s), skipping\n", serviceName, currentBranch)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ✓  already on default branch (%s), skipping\n", serviceName, currentBranch)
 			skippedCount++
 			continue
 		}
@@ -987,35 +987,35 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 		// Check if repository has uncommitted changes
 		isClean, err := repoManager.IsClean(ctx, service.Path)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to check repository status: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to check repository status: %v\n", serviceName, err)
 			errorCount++
 			continue
 		}
 
 		if !isClean {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  has uncommitted changes, skipping (use 'git stash' or commit changes first)\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  has uncommitted changes, skipping (use 'git stash' or commit changes first)\n", serviceName)
 			skippedCount++
 			continue
 		}
 
 		// Switch to default branch
-		_, _ = fmt.Fprintf(e.output, "  %s: 🔄  switching from %s to %s...", serviceName, currentBranch, defaultBranch)
+		_, _ = fmt.Fprintf(e.out(execCtx), "  %s: 🔄  switching from %s to %s...", serviceName, currentBranch, defaultBranch)
 		if err := repoManager.Checkout(ctx, service.Path, defaultBranch); err != nil {
-			_, _ = fmt.Fprintf(e.output, " ❌  failed: %v\n", err)
+			_, _ = fmt.Fprintf(e.out(execCtx), " ❌  failed: %v\n", err)
 			errorCount++
 			continue
 		}
 
 		// Pull latest changes
 		if err := repoManager.Update(ctx, repoConfig, service.Path); err != nil {
-			_, _ = fmt.Fprintf(e.output, " ⚠️  switched but failed to pull: %v\n", err)
+			_, _ = fmt.Fprintf(e.out(execCtx), " ⚠️  switched but failed to pull: %v\n", err)
 		} else {
-			_, _ = fmt.Fprintf(e.output, " ✅  switched and updated\n")
+			_, _ = fmt.Fprintf(e.out(execCtx), " ✅  switched and updated\n")
 		}
 		switchedCount++
 	}
 
-	_, _ = fmt.Fprintf(e.output, "\n📊  Summary: %d switched, %d skipped, %d errors\n", switchedCount, skippedCount, errorCount)
+	e.iconf(execCtx, "\n📊  ", "Summary: %d switched, %d skipped, %d errors\n", switchedCount, skippedCount, errorCount)
 
 	if errorCount > 0 {
 		return fmt.Errorf("branch switch completed with %d error(s)", errorCount)
@@ -1029,8 +1029,8 @@ func (e *Engine) orchestrateSwitchToDefault(ctx context.Context, orch *ast.Orche
 }
 
 // orchestrateSetAllDefault sets all services to their default branch
-func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🔄  Setting all repositories to default branch for orchestration: %s\n", orch.Name)
+func (e *Engine) orchestrateSetAllDefault(ctx context.Context, execCtx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
+	e.iconf(execCtx, "🔄  ", "Setting all repositories to default branch for orchestration: %s\n", orch.Name)
 
 	// Get working directory
 	workDir, err := os.Getwd()
@@ -1048,7 +1048,7 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
 		if service.Repository == nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ℹ️  no repository configured, skipping\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ℹ️  no repository configured, skipping\n", serviceName)
 			skippedCount++
 			continue
 		}
@@ -1076,7 +1076,7 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 
 		gitPath := filepath.Join(fullPath, ".git")
 		if _, err := os.Stat(gitPath); os.IsNotExist(err) {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  repository not cloned locally, skipping\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  repository not cloned locally, skipping\n", serviceName)
 			skippedCount++
 			continue
 		}
@@ -1084,7 +1084,7 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 		// Get current branch
 		currentBranch, err := repoManager.GetCurrentBranch(ctx, service.Path)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to get current branch: %v\n", serviceName, err)
 			errorCount++
 			continue
 		}
@@ -1092,7 +1092,7 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 		// Get default branch
 		defaultBranch, err := repoManager.GetDefaultBranch(ctx, repoConfig, service.Path)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to get default branch: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to get default branch: %v\n", serviceName, err)
 			errorCount++
 			continue
 		}
@@ -1102,7 +1102,7 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 		normalizedDefault := normalizeBranchName(defaultBranch)
 
 		if normalizedCurrent == normalizedDefault {
-			_, _ = fmt.Fprintf(e.output, "  %s: ✓  already on default branch (%s), skipping\n", serviceName, currentBranch)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ✓  already on default branch (%s), skipping\n", serviceName, currentBranch)
 			skippedCount++
 			continue
 		}
@@ -1110,35 +1110,35 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 		// Check if repository has uncommitted changes
 		isClean, err := repoManager.IsClean(ctx, service.Path)
 		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  failed to check repository status: %v\n", serviceName, err)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  failed to check repository status: %v\n", serviceName, err)
 			errorCount++
 			continue
 		}
 
 		if !isClean {
-			_, _ = fmt.Fprintf(e.output, "  %s: ⚠️  has uncommitted changes, skipping (use 'git stash' or commit changes first)\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(execCtx), "  %s: ⚠️  has uncommitted changes, skipping (use 'git stash' or commit changes first)\n", serviceName)
 			skippedCount++
 			continue
 		}
 
 		// Switch to default branch
-		_, _ = fmt.Fprintf(e.output, "  %s: 🔄  switching from %s to %s...", serviceName, currentBranch, defaultBranch)
+		_, _ = fmt.Fprintf(e.out(execCtx), "  %s: 🔄  switching from %s to %s...", serviceName, currentBranch, defaultBranch)
 		if err := repoManager.Checkout(ctx, service.Path, defaultBranch); err != nil {
-			_, _ = fmt.Fprintf(e.output, " ❌  failed: %v\n", err)
+			_, _ = fmt.Fprintf(e.out(execCtx), " ❌  failed: %v\n", err)
 			errorCount++
 			continue
 		}
 
 		// Pull latest changes
 		if err := repoManager.Update(ctx, repoConfig, service.Path); err != nil {
-			_, _ = fmt.Fprintf(e.output, " ⚠️  switched but failed to pull: %v\n", err)
+			_, _ = fmt.Fprintf(e.out(execCtx), " ⚠️  switched but failed to pull: %v\n", err)
 		} else {
-			_, _ = fmt.Fprintf(e.output, " ✅  switched and updated\n")
+			_, _ = fmt.Fprintf(e.out(execCtx), " ✅  switched and updated\n")
 		}
 		switchedCount++
 	}
 
-	_, _ = fmt.Fprintf(e.output, "\n📊  Summary: %d switched, %d skipped, %d errors\n", switchedCount, skippedCount, errorCount)
+	e.iconf(execCtx, "\n📊  ", "Summary: %d switched, %d skipped, %d errors\n", switchedCount, skippedCount, errorCount)
 
 	if errorCount > 0 {
 		return fmt.Errorf("branch switch completed with %d error(s)", errorCount)
@@ -1149,35 +1149,35 @@ func (e *Engine) orchestrateSetAllDefault(ctx context.Context, orch *ast.Orchest
 
 // orchestrateBuild builds all services
 func (e *Engine) orchestrateBuild(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, useCache bool) error {
-	_, _ = fmt.Fprintf(e.output, "🔨  Building orchestration: %s\n", orch.Name)
+	e.iconf(ctx, "🔨  ", "Building orchestration: %s\n", orch.Name)
 
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
-		_, _ = fmt.Fprintf(e.output, "  ▸ Building %s...\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "  ▸ Building %s...\n", serviceName)
 
 		if err := e.performServiceBuild(ctx, service, true, useCache); err != nil {
 			return fmt.Errorf("failed to build service '%s': %w", serviceName, err)
 		}
 
-		_, _ = fmt.Fprintf(e.output, "    ✓  %s built\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s built\n", serviceName)
 	}
 
 	return nil
 }
 
 // orchestratePull pulls images for all services
-func (e *Engine) orchestratePull(orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "📥  Pulling images for orchestration: %s\n", orch.Name)
+func (e *Engine) orchestratePull(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
+	e.iconf(ctx, "📥  ", "Pulling images for orchestration: %s\n", orch.Name)
 
 	for _, serviceName := range orderedServices {
 		service := services[serviceName]
-		_, _ = fmt.Fprintf(e.output, "  ▸ Pulling %s...\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "  ▸ Pulling %s...\n", serviceName)
 
-		if err := e.pullService(service); err != nil {
+		if err := e.pullService(ctx, service); err != nil {
 			return fmt.Errorf("failed to pull service '%s': %w", serviceName, err)
 		}
 
-		_, _ = fmt.Fprintf(e.output, "    ✓  %s pulled\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s pulled\n", serviceName)
 	}
 
 	return nil
@@ -1185,7 +1185,7 @@ func (e *Engine) orchestratePull(orch *ast.OrchestrateStatement, orderedServices
 
 // orchestrateRecreate forces recreation of services by taking them down, rebuilding, and starting again
 func (e *Engine) orchestrateRecreate(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement, useCache bool) error {
-	_, _ = fmt.Fprintf(e.output, "🔁  Force recreating orchestration: %s\n", orch.Name)
+	e.iconf(ctx, "🔁  ", "Force recreating orchestration: %s\n", orch.Name)
 
 	errDown := e.orchestrateDown(ctx, orch, orderedServices, services)
 	errPost := e.runOrchestrationHook(ctx, orch.PostTask, orch.Name, "post")
@@ -1206,23 +1206,23 @@ func (e *Engine) orchestrateRecreate(ctx *ExecutionContext, orch *ast.Orchestrat
 
 // orchestrateDown stops and removes containers
 func (e *Engine) orchestrateDown(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🗑️  Taking down orchestration: %s\n", orch.Name)
+	e.iconf(ctx, "🗑️  ", "Taking down orchestration: %s\n", orch.Name)
 
 	// Check DNS resolution for specified domains (helpful before any orchestration action)
-	if err := e.checkDNSResolution(orch); err != nil {
+	if err := e.checkDNSResolution(ctx, orch); err != nil {
 		// DNS check failures are warnings, not errors
-		_, _ = fmt.Fprintf(e.output, "⚠️  %v\n\n", err)
+		e.iconf(ctx, "⚠️  ", "%v\n\n", err)
 	}
 
 	for i := len(orderedServices) - 1; i >= 0; i-- {
 		serviceName := orderedServices[i]
 		service := services[serviceName]
-		_, _ = fmt.Fprintf(e.output, "  ▸ Taking down %s...\n", serviceName)
+		_, _ = fmt.Fprintf(e.out(ctx), "  ▸ Taking down %s...\n", serviceName)
 
-		if err := e.downService(service); err != nil {
-			_, _ = fmt.Fprintf(e.output, "    ⚠️  Failed to take down %s: %v\n", serviceName, err)
+		if err := e.downService(ctx, service); err != nil {
+			_, _ = fmt.Fprintf(e.out(ctx), "    ⚠️  Failed to take down %s: %v\n", serviceName, err)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "    ✓  %s taken down\n", serviceName)
+			_, _ = fmt.Fprintf(e.out(ctx), "    ✓  %s taken down\n", serviceName)
 			if err := e.runServiceHook(ctx, service.PostTask, serviceName, "post"); err != nil {
 				return err
 			}
@@ -1234,20 +1234,20 @@ func (e *Engine) orchestrateDown(ctx *ExecutionContext, orch *ast.OrchestrateSta
 
 // Helper functions for Docker Compose operations
 
-func (e *Engine) startService(service *ast.ServiceStatement) error {
+func (e *Engine) startService(ctx *ExecutionContext, service *ast.ServiceStatement) error {
 	alreadyHealthy, err := e.serviceIsRunningAndHealthy(service)
 	if err == nil && alreadyHealthy {
 		return nil
 	}
 
-	return e.runDockerCompose(service, "up", "-d")
+	return e.runDockerCompose(ctx, service, "up", "-d")
 }
 
-func (e *Engine) stopService(service *ast.ServiceStatement) error {
-	return e.runDockerCompose(service, "stop")
+func (e *Engine) stopService(ctx *ExecutionContext, service *ast.ServiceStatement) error {
+	return e.runDockerCompose(ctx, service, "stop")
 }
 
-func (e *Engine) buildServiceWithOutput(service *ast.ServiceStatement, useCache bool) error {
+func (e *Engine) buildServiceWithOutput(ctx *ExecutionContext, service *ast.ServiceStatement, useCache bool) error {
 	args := []string{"build"}
 	if !useCache {
 		args = append(args, "--no-cache")
@@ -1256,12 +1256,12 @@ func (e *Engine) buildServiceWithOutput(service *ast.ServiceStatement, useCache
 	cmd := e.buildDockerComposeCmd(service, args...)
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Running: %s\n", strings.Join(cmd.Args, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Running: %s\n", strings.Join(cmd.Args, " "))
 	}
 
 	// Stream the output in real-time
-	cmd.Stdout = e.output
-	cmd.Stderr = e.output
+	cmd.Stdout = e.out(ctx)
+	cmd.Stderr = e.out(ctx)
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("docker compose build failed: %w", err)
@@ -1270,12 +1270,12 @@ func (e *Engine) buildServiceWithOutput(service *ast.ServiceStatement, useCache
 	return nil
 }
 
-func (e *Engine) pullService(service *ast.ServiceStatement) error {
-	return e.runDockerCompose(service, "pull")
+func (e *Engine) pullService(ctx *ExecutionContext, service *ast.ServiceStatement) error {
+	return e.runDockerCompose(ctx, service, "pull")
 }
 
-func (e *Engine) downService(service *ast.ServiceStatement) error {
-	return e.runDockerCompose(service, "down")
+func (e *Engine) downService(ctx *ExecutionContext, service *ast.ServiceStatement) error {
+	return e.runDockerCompose(ctx, service, "down")
 }
 
 func (e *Engine) getServiceStatus(service *ast.ServiceStatement) string {
@@ -1315,11 +1315,11 @@ func (e *Engine) serviceIsRunningAndHealthy(service *ast.ServiceStatement) (bool
 	return healthy, nil
 }
 
-func (e *Engine) runDockerCompose(service *ast.ServiceStatement, args ...string) error {
+func (e *Engine) runDockerCompose(ctx *ExecutionContext, service *ast.ServiceStatement, args ...string) error {
 	cmd := e.buildDockerComposeCmd(service, args...)
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Running: %s\n", strings.Join(cmd.Args, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Running: %s\n", strings.Join(cmd.Args, " "))
 	}
 
 	output, err := cmd.CombinedOutput()
@@ -1344,13 +1344,13 @@ func (e *Engine) buildDockerComposeCmd(service *ast.ServiceStatement, args ...st
 		// This should never happen - paths should be resolved earlier
 		// If we hit this, use Abs as a fallback but log a warning
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "    [WARNING] Service path is relative, resolving from CWD: %s\n", servicePath)
+			_, _ = fmt.Fprintf(e.out(nil), "    [WARNING] Service path is relative, resolving from CWD: %s\n", servicePath)
 		}
 		absPath, err := filepath.Abs(servicePath)
 		if err != nil {
 			// Fall back to using the relative path and let docker fail with a better error
 			if e.verbose {
-				_, _ = fmt.Fprintf(e.output, "    [WARNING] Failed to resolve absolute path: %v\n", err)
+				_, _ = fmt.Fprintf(e.out(nil), "    [WARNING] Failed to resolve absolute path: %v\n", err)
 			}
 		} else {
 			servicePath = absPath
@@ -1427,6 +1427,19 @@ func (e *Engine) runNamedTask(ctx *ExecutionContext, taskName string) error {
 		CurrentTask:      taskName,
 		CurrentNamespace: namespace,
 		Program:          ctx.Program,
+		Monitor:          ctx.Monitor,
+		NotifyWhenDone:   ctx.NotifyWhenDone,
+		TaskStarted:      ctx.TaskStarted,
+		Timings:          ctx.Timings,
+		Assertions:       ctx.Assertions,
+		Retries:          ctx.Retries,
+		Deadline:         ctx.Deadline,
+		Run:              ctx.Run,
+		LoopItems:        ctx.LoopItems,
+		Outputs:          ctx.Outputs,
+		Resources:        ctx.Resources,
+		Output:           ctx.Output,
+		Style:            ctx.Style,
 	}
 
 	for k, v := range ctx.Variables {
@@ -1511,7 +1524,7 @@ func (e *Engine) performServiceBuild(ctx *ExecutionContext, service *ast.Service
 	buildCfg := service.Build
 	if buildCfg == nil {
 		if allowFallback {
-			return e.buildServiceWithOutput(service, useCache)
+			return e.buildServiceWithOutput(ctx, service, useCache)
 		}
 		return nil
 	}
@@ -1525,7 +1538,7 @@ func (e *Engine) performServiceBuild(ctx *ExecutionContext, service *ast.Service
 	}
 
 	if allowFallback || buildCfg.Required {
-		return e.buildServiceWithOutput(service, useCache)
+		return e.buildServiceWithOutput(ctx, service, useCache)
 	}
 
 	return nil
@@ -1547,7 +1560,7 @@ func (e *Engine) executeBuildCommand(ctx *ExecutionContext, service *ast.Service
 		return fmt.Errorf("failed to interpolate build command: %w", err)
 	}
 
-	return e.runShellCommandInDir(interpolatedCommand, workDir, true, service.Build.AllocateTTY)
+	return e.runShellCommandInDir(ctx, interpolatedCommand, workDir, true, service.Build.AllocateTTY)
 }
 
 func (e *Engine) executeMakefileBuild(ctx *ExecutionContext, service *ast.ServiceStatement) error {
@@ -1574,7 +1587,7 @@ func (e *Engine) executeMakefileBuild(ctx *ExecutionContext, service *ast.Servic
 		if err != nil {
 			return fmt.Errorf("failed to interpolate pre-make command: %w", err)
 		}
-		if err := e.runShellCommandInDir(interpolatedCmd, workDir, service.Build.Verbose, false); err != nil {
+		if err := e.runShellCommandInDir(ctx, interpolatedCmd, workDir, service.Build.Verbose, false); err != nil {
 			return fmt.Errorf("pre-make command failed: %w", err)
 		}
 	}
@@ -1593,11 +1606,16 @@ func (e *Engine) executeMakefileBuild(ctx *ExecutionContext, service *ast.Servic
 
 	var lastErr error
 	for attempt := 1; attempt <= attempts; attempt++ {
-		if attempt > 1 && delay > 0 {
-			time.Sleep(delay)
+		if attempt > 1 {
+			if err := e.takeRetry(ctx, "the make build of "+service.Name); err != nil {
+				return fmt.Errorf("make command failed after %d attempts: %w (last error: %v)", attempt-1, err, lastErr)
+			}
+			if delay > 0 {
+				time.Sleep(delay)
+			}
 		}
 
-		if err := e.runMakeCommand(service.Build, workDir); err != nil {
+		if err := e.runMakeCommand(ctx, service.Build, workDir); err != nil {
 			lastErr = err
 			continue
 		}
@@ -1613,7 +1631,7 @@ func (e *Engine) executeMakefileBuild(ctx *ExecutionContext, service *ast.Servic
 			if err != nil {
 				return fmt.Errorf("failed to interpolate fallback command: %w", err)
 			}
-			if err := e.runShellCommandInDir(interpolatedFallback, workDir, true, service.Build.AllocateTTY); err != nil {
+			if err := e.runShellCommandInDir(ctx, interpolatedFallback, workDir, true, service.Build.AllocateTTY); err != nil {
 				return fmt.Errorf("make command failed and fallback command also failed: %w", err)
 			}
 		} else if service.Build.RetryOnFailure && service.Build.MaxRetries > 0 {
@@ -1629,7 +1647,7 @@ func (e *Engine) executeMakefileBuild(ctx *ExecutionContext, service *ast.Servic
 		if err != nil {
 			return fmt.Errorf("failed to interpolate post-make command: %w", err)
 		}
-		if err := e.runShellCommandInDir(interpolatedCmd, workDir, service.Build.Verbose, false); err != nil {
+		if err := e.runShellCommandInDir(ctx, interpolatedCmd, workDir, service.Build.Verbose, false); err != nil {
 			return fmt.Errorf("post-make command failed: %w", err)
 		}
 	}
@@ -1637,7 +1655,7 @@ func (e *Engine) executeMakefileBuild(ctx *ExecutionContext, service *ast.Servic
 	return nil
 }
 
-func (e *Engine) runMakeCommand(buildCfg *ast.BuildConfig, workDir string) error {
+func (e *Engine) runMakeCommand(ctx *ExecutionContext, buildCfg *ast.BuildConfig, workDir string) error {
 	args := []string{"-f", buildCfg.Makefile}
 
 	if buildCfg.ParallelJobs > 0 {
@@ -1666,8 +1684,8 @@ func (e *Engine) runMakeCommand(buildCfg *ast.BuildConfig, workDir string) error
 	cmd.Env = os.Environ()
 
 	if buildCfg.Verbose {
-		cmd.Stdout = e.output
-		cmd.Stderr = e.output
+		cmd.Stdout = e.out(ctx)
+		cmd.Stderr = e.out(ctx)
 		if err := cmd.Run(); err != nil {
 			if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
 				return fmt.Errorf("make command timed out after %s", buildCfg.MakefileTimeout)
@@ -1688,7 +1706,7 @@ func (e *Engine) runMakeCommand(buildCfg *ast.BuildConfig, workDir string) error
 	return nil
 }
 
-func (e *Engine) runShellCommandInDir(cmdStr, workDir string, verbose bool, allocateTTY bool) error {
+func (e *Engine) runShellCommandInDir(ctx *ExecutionContext, cmdStr, workDir string, verbose bool, allocateTTY bool) error {
 	if cmdStr == "" {
 		return fmt.Errorf("empty command")
 	}
@@ -1706,8 +1724,8 @@ func (e *Engine) runShellCommandInDir(cmdStr, workDir string, verbose bool, allo
 	cmd.Env = os.Environ()
 
 	if verbose {
-		cmd.Stdout = e.output
-		cmd.Stderr = e.output
+		cmd.Stdout = e.out(ctx)
+		cmd.Stderr = e.out(ctx)
 		if allocateTTY {
 			// For TTY allocation, also connect stdin
 			cmd.Stdin = os.Stdin
@@ -1761,7 +1779,7 @@ func (e *Engine) resolveBuildWorkingDir(service *ast.ServiceStatement) (string,
 }
 
 // waitForHealth waits for a service to become healthy based on its health check configuration
-func (e *Engine) waitForHealth(service *ast.ServiceStatement) error {
+func (e *Engine) waitForHealth(ctx *ExecutionContext, service *ast.ServiceStatement) error {
 	if service.HealthCheck == nil {
 		return nil
 	}
@@ -1794,7 +1812,7 @@ func (e *Engine) waitForHealth(service *ast.ServiceStatement) error {
 	// Perform health checks
 	for attempt := 1; attempt <= retries; attempt++ {
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "      [VERBOSE] Health check attempt %d/%d...\n", attempt, retries)
+			_, _ = fmt.Fprintf(e.out(ctx), "      [VERBOSE] Health check attempt %d/%d...\n", attempt, retries)
 		}
 
 		healthy, err := e.performHealthCheck(service)
@@ -1895,9 +1913,9 @@ func parseDurationToSeconds(durationStr string) (int, error) {
 }
 
 // checkAndProvisionNetworks checks and provisions Docker networks for services
-func (e *Engine) checkAndProvisionNetworks(services map[string]*ast.ServiceStatement) error {
+func (e *Engine) checkAndProvisionNetworks(ctx *ExecutionContext, services map[string]*ast.ServiceStatement) error {
 	networkManager := docker.NewNetworkManager()
-	ctx := context.Background()
+	dockerCtx := context.Background()
 
 	// Collect all required networks
 	requiredNetworks := make(map[string]*ast.DockerNetworkConfig)
@@ -1909,9 +1927,10 @@ func (e *Engine) checkAndProvisionNetworks(services map[string]*ast.ServiceState
 		}
 	}
 
-	// Check and provision each network
-	for networkName, networkConfig := range requiredNetworks {
-		exists, err := networkManager.CheckNetworkExists(ctx, networkName)
+	// Check and provision each network, in name order so output is stable
+	for _, networkName := range ast.OrderedKeys(requiredNetworks, nil) {
+		networkConfig := requiredNetworks[networkName]
+		exists, err := networkManager.CheckNetworkExists(dockerCtx, networkName)
 		if err != nil {
 			return fmt.Errorf("failed to check network %s: %w", networkName, err)
 		}
@@ -1920,20 +1939,20 @@ func (e *Engine) checkAndProvisionNetworks(services map[string]*ast.ServiceState
 			if networkConfig.Required {
 				if networkConfig.AutoProvision {
 					// Create the network
-					_, _ = fmt.Fprintf(e.output, "Creating Docker network: %s\n", networkName)
-					err = networkManager.CreateNetwork(ctx, networkName, networkConfig.Driver, networkConfig.Options)
+					_, _ = fmt.Fprintf(e.out(ctx), "Creating Docker network: %s\n", networkName)
+					err = networkManager.CreateNetwork(dockerCtx, networkName, networkConfig.Driver, networkConfig.Options)
 					if err != nil {
 						return fmt.Errorf("failed to create network %s: %w", networkName, err)
 					}
-					_, _ = fmt.Fprintf(e.output, "✓  Created network: %s\n", networkName)
+					e.iconf(ctx, "✓  ", "Created network: %s\n", networkName)
 				} else {
 					return fmt.Errorf("required network %s does not exist and autoprovision is disabled", networkName)
 				}
 			} else {
-				_, _ = fmt.Fprintf(e.output, "⚠️  Network %s does not exist (not required)\n", networkName)
+				e.iconf(ctx, "⚠️  ", "Network %s does not exist (not required)\n", networkName)
 			}
 		} else {
-			_, _ = fmt.Fprintf(e.output, "✓  Network %s exists\n", networkName)
+			e.iconf(ctx, "✓  ", "Network %s exists\n", networkName)
 		}
 	}
 
@@ -1941,7 +1960,7 @@ func (e *Engine) checkAndProvisionNetworks(services map[string]*ast.ServiceState
 }
 
 // checkDNSResolution checks if specified domains resolve correctly
-func (e *Engine) checkDNSResolution(orch *ast.OrchestrateStatement) error {
+func (e *Engine) checkDNSResolution(ctx *ExecutionContext, orch *ast.OrchestrateStatement) error {
 	if len(orch.DNSChecks) == 0 {
 		return nil
 	}
@@ -1974,11 +1993,11 @@ func (e *Engine) checkDNSResolution(orch *ast.OrchestrateStatement) error {
 
 	// Only show output if there are failures
 	if len(failedDomains) > 0 {
-		_, _ = fmt.Fprintf(e.output, "🔍  DNS resolution check:\n")
+		e.iconf(ctx, "🔍  ", "DNS resolution check:\n")
 		for _, domain := range failedDomains {
-			_, _ = fmt.Fprintf(e.output, "   ❌  %s - not resolvable\n", domain)
+			_, _ = fmt.Fprintf(e.out(ctx), "   ❌  %s - not resolvable\n", domain)
 		}
-		_, _ = fmt.Fprintf(e.output, "\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "\n")
 		return fmt.Errorf("DNS resolution failed for: %s\nThese domains may need to be added to your /etc/hosts file", strings.Join(failedDomains, ", "))
 	}
 
@@ -92,7 +92,7 @@ func TestBuildServiceWithOutput_NoCacheFlag(t *testing.T) {
 		Path: serviceDir,
 	}
 
-	if err := engine.buildServiceWithOutput(service, true); err != nil {
+	if err := engine.buildServiceWithOutput(nil, service, true); err != nil {
 		t.Fatalf("buildServiceWithOutput with cache failed: %v", err)
 	}
 
@@ -106,7 +106,7 @@ func TestBuildServiceWithOutput_NoCacheFlag(t *testing.T) {
 		t.Fatalf("did not expect --no-cache when cache enabled, got args: %s", argsStr)
 	}
 
-	if err := engine.buildServiceWithOutput(service, false); err != nil {
+	if err := engine.buildServiceWithOutput(nil, service, false); err != nil {
 		t.Fatalf("buildServiceWithOutput without cache failed: %v", err)
 	}
 
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Plugins
+// This file delegates `x <tool> ...` statements to an external drun-x-<tool>
+// executable found on PATH, like kubectl and git plugins. drun writes one
+// JSON request to the plugin's stdin and reads one JSON response from its
+// stdout; stderr is passed through for progress output.
+
+// pluginProtocolVersion is sent with every request so plugins can detect
+// changes to the protocol
+const pluginProtocolVersion = 1
+
+// pluginPrefix is the executable name prefix of plugins
+const pluginPrefix = "drun-x-"
+
+// pluginRequest is the JSON document written to a plugin's stdin
+type pluginRequest struct {
+	Protocol   int      `json:"protocol"`
+	Tool       string   `json:"tool"`
+	Args       []string `json:"args"`
+	Task       string   `json:"task"`
+	WorkingDir string   `json:"working_dir"`
+	Verbose    bool     `json:"verbose"`
+}
+
+// pluginResponse is the JSON document a plugin writes to its stdout. Every
+// field is optional; an empty stdout is a successful response.
+type pluginResponse struct {
+	Messages  []string          `json:"messages"`  // printed as info lines
+	Variables map[string]string `json:"variables"` // set as $name in the task
+	Error     string            `json:"error"`     // fails the statement
+}
+
+// executePlugin runs the plugin of a statement and applies its response
+func (e *Engine) executePlugin(stmt *statement.Plugin, ctx *ExecutionContext) error {
+	name := pluginPrefix + stmt.Tool
+	args := make([]string, len(stmt.Args))
+	for i, arg := range stmt.Args {
+		value, err := e.interpolateVariablesWithError(arg, ctx)
+		if err != nil {
+			return fmt.Errorf("in arguments of 'x %s': %w", stmt.Tool, err)
+		}
+		args[i] = value
+	}
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would run plugin %s %s\n", name, strings.Join(args, " "))
+		return nil
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("no plugin for 'x %s': %s was not found on PATH", stmt.Tool, name)
+	}
+
+	workingDir := ctx.WorkingDir
+	if workingDir == "" {
+		workingDir, _ = os.Getwd()
+	}
+	request, err := json.Marshal(pluginRequest{
+		Protocol:   pluginProtocolVersion,
+		Tool:       stmt.Tool,
+		Args:       args,
+		Task:       ctx.CurrentTask,
+		WorkingDir: workingDir,
+		Verbose:    e.verbose,
+	})
+	if err != nil {
+		return err
+	}
+
+	if e.verbose {
+		e.iconf(ctx, "🔌 ", "Running plugin %s %s\n", name, strings.Join(args, " "))
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Dir = workingDir
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = e.out(ctx)
+	if ctx.Project != nil {
+		if accept := shellEnvFilter(ctx.Project); accept != nil {
+			for _, entry := range os.Environ() {
+				if envName, _, _ := strings.Cut(entry, "="); accept(envName) {
+					cmd.Env = append(cmd.Env, entry)
+				}
+			}
+		}
+	}
+	runErr := cmd.Run()
+
+	var response pluginResponse
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, &response); err != nil {
+			if runErr != nil {
+				return fmt.Errorf("plugin %s failed: %w", name, runErr)
+			}
+			return fmt.Errorf("plugin %s returned an invalid response: %w", name, err)
+		}
+	}
+
+	for _, message := range response.Messages {
+		e.iconf(ctx, "ℹ️  ", "%s\n", message)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("plugin %s failed: %s", name, response.Error)
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Errorf("plugin %s failed with exit code %d", name, exitErr.ExitCode())
+		}
+		return fmt.Errorf("plugin %s failed: %w", name, runErr)
+	}
+
+	for variable, value := range response.Variables {
+		varName := "$" + strings.TrimPrefix(variable, "$")
+		if ctx.CurrentNamespace != "" {
+			varName = ctx.CurrentNamespace + "." + varName
+		}
+		e.assignVariable(ctx, varName, value, "x "+stmt.Tool)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Remote Execution
+// This file contains the executor of run statements on hosts and host
+// groups over ssh
+
+// sshCommandSetting names the project setting with the ssh command line
+// remote commands run through (set ssh_command to "ssh -p 2222")
+const sshCommandSetting = "ssh_command"
+
+// defaultSSHCommand never prompts, so a host that needs a password fails
+// instead of blocking the run
+const defaultSSHCommand = "ssh -o BatchMode=yes"
+
+// remoteHostFailure is a host a remote command failed on
+type remoteHostFailure struct {
+	host string
+	err  error
+}
+
+// executeRemoteShell runs a command on a host or on every host of a group.
+// Each output line is prefixed with its host. The command runs on every
+// host even when it fails on some; the failures are reported together.
+func (e *Engine) executeRemoteShell(shellStmt *statement.Shell, ctx *ExecutionContext) error {
+	command, err := e.interpolateShellCommand(shellStmt.Command, ctx)
+	if err != nil {
+		return err
+	}
+	hosts, target, err := e.remoteHosts(shellStmt, ctx)
+	if err != nil {
+		return err
+	}
+	sshCommand := defaultSSHCommand
+	if ctx != nil && ctx.Project != nil && strings.TrimSpace(ctx.Project.Settings[sshCommandSetting]) != "" {
+		sshCommand = ctx.Project.Settings[sshCommandSetting]
+	}
+	sshArgs := strings.Fields(sshCommand)
+
+	workers := 1
+	if shellStmt.HostParallel {
+		workers = shellStmt.HostWorkers
+		if workers <= 0 {
+			workers = e.defaultParallelism
+		}
+	}
+	if workers > len(hosts) {
+		workers = len(hosts)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	if e.dryRun {
+		how := ""
+		if shellStmt.HostParallel {
+			how = fmt.Sprintf(" in parallel with %d workers", workers)
+		}
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would run on %s%s: %s\n", target, how, command)
+		for _, host := range hosts {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN]   %s %s %s\n", sshCommand, host, command)
+		}
+		return nil
+	}
+
+	if shellStmt.Verbosity != "quiet" {
+		e.iconf(ctx, "🖥️  ", "Running on %s: %s\n", target, command)
+	}
+
+	var outputMu sync.Mutex
+	failures := make([]*remoteHostFailure, len(hosts))
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, host string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := e.runOnHost(sshArgs, host, command, shellStmt.Verbosity == "quiet", &outputMu, ctx); err != nil {
+				failures[i] = &remoteHostFailure{host: host, err: err}
+			}
+		}(i, host)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, failure := range failures {
+		if failure != nil {
+			failed = append(failed, fmt.Sprintf("  %s: %v", failure.host, failure.err))
+		}
+	}
+	if len(failed) > 0 {
+		err := fmt.Errorf("command failed on %d of %d hosts of %s:\n%s", len(failed), len(hosts), target, strings.Join(failed, "\n"))
+		if shellStmt.FailureMessage != "" {
+			err = fmt.Errorf("%s: %w", e.interpolateVariables(shellStmt.FailureMessage, ctx), err)
+		}
+		return err
+	}
+	if e.isVerboseShell(shellStmt) {
+		e.iconf(ctx, "✅  ", "Command succeeded on %d hosts of %s\n", len(hosts), target)
+	}
+	return nil
+}
+
+// remoteHosts returns the hosts a statement runs on, and how to name them
+func (e *Engine) remoteHosts(shellStmt *statement.Shell, ctx *ExecutionContext) ([]string, string, error) {
+	if shellStmt.Host != "" {
+		host, err := e.interpolateVariablesWithError(shellStmt.Host, ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		return []string{host}, fmt.Sprintf("host '%s'", host), nil
+	}
+
+	name, err := e.interpolateVariablesWithError(shellStmt.HostGroup, ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	var declared []string
+	if ctx != nil && ctx.Project != nil {
+		declared = ctx.Project.HostGroups[name]
+	}
+	if len(declared) == 0 {
+		return nil, "", fmt.Errorf("unknown host group '%s'; declare it in the project with hosts group \"%s\" = [\"host\", ...]", name, name)
+	}
+	hosts := make([]string, len(declared))
+	for i, host := range declared {
+		if hosts[i], err = e.interpolateVariablesWithError(host, ctx); err != nil {
+			return nil, "", fmt.Errorf("in host group '%s': %w", name, err)
+		}
+	}
+	return hosts, fmt.Sprintf("group '%s'", name), nil
+}
+
+// runOnHost runs a command on one host through ssh. Its output lines are
+// prefixed with the host; quiet output is only shown when the command fails.
+func (e *Engine) runOnHost(sshArgs []string, host, command string, quiet bool, outputMu *sync.Mutex, ctx *ExecutionContext) error {
+	var buffered bytes.Buffer
+	var dest io.Writer = &syncWriter{w: e.out(ctx), mu: outputMu}
+	if quiet {
+		dest = &buffered
+	}
+	out := &hostPrefixWriter{w: dest, prefix: "[" + host + "] "}
+
+	args := append(append([]string{}, sshArgs[1:]...), host, command)
+	// #nosec G204 -- remote commands intentionally run the ssh command the project declares.
+	cmd := exec.Command(sshArgs[0], args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	out.Flush()
+
+	if err != nil && quiet && buffered.Len() > 0 {
+		outputMu.Lock()
+		_, _ = e.out(ctx).Write(buffered.Bytes())
+		outputMu.Unlock()
+	}
+	return err
+}
+
+// syncWriter serializes writes of several goroutines to one writer
+type syncWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (sw *syncWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(p)
+}
+
+// hostPrefixWriter writes whole lines, each starting with prefix, so lines
+// of hosts running at once do not interleave
+type hostPrefixWriter struct {
+	w       io.Writer
+	prefix  string
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (hw *hostPrefixWriter) Write(p []byte) (int, error) {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	hw.partial = append(hw.partial, p...)
+	for {
+		i := bytes.IndexByte(hw.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := hw.partial[:i+1]
+		if _, err := hw.w.Write(append([]byte(hw.prefix), line...)); err != nil {
+			return len(p), err
+		}
+		hw.partial = hw.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a last line that has no newline
+func (hw *hostPrefixWriter) Flush() {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	if len(hw.partial) > 0 {
+		_, _ = hw.w.Write([]byte(hw.prefix + string(hw.partial) + "\n"))
+		hw.partial = nil
+	}
+}
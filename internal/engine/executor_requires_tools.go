@@ -78,13 +78,13 @@ func (e *Engine) checkSingleToolRequirement(detector toolDetector, tool statemen
 	if !detector.IsToolAvailable(tool.Name) {
 		if tool.AutoProvision {
 			if e.dryRun {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] 🔧 Would provision missing tool '%s'\n", tool.Name)
+				_, _ = fmt.Fprintf(e.out(execCtx), "[DRY RUN] 🔧 Would provision missing tool '%s'\n", tool.Name)
 				return nil
 			}
 			return e.provisionAndRecheck(tool, projectCtx, execCtx, "required tool is not installed")
 		}
 		if e.dryRun {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] ❌ Required tool '%s' is not installed\n", tool.Name)
+			_, _ = fmt.Fprintf(e.out(execCtx), "[DRY RUN] ❌ Required tool '%s' is not installed\n", tool.Name)
 			return nil
 		}
 		return fmt.Errorf("required tool '%s' is not installed", tool.Name)
@@ -93,7 +93,7 @@ func (e *Engine) checkSingleToolRequirement(detector toolDetector, tool statemen
 	currentVersion, mismatch, err := evaluateToolVersion(detector, tool)
 	if err != nil {
 		if e.dryRun {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] ⚠️  Could not determine version for '%s'\n", tool.Name)
+			_, _ = fmt.Fprintf(e.out(execCtx), "[DRY RUN] ⚠️  Could not determine version for '%s'\n", tool.Name)
 			return nil
 		}
 		return err
@@ -102,12 +102,12 @@ func (e *Engine) checkSingleToolRequirement(detector toolDetector, tool statemen
 	if mismatch != nil {
 		if tool.AutoProvision {
 			if e.dryRun {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] 🔧 Would provision '%s' to satisfy %s %s\n",
+				_, _ = fmt.Fprintf(e.out(execCtx), "[DRY RUN] 🔧 Would provision '%s' to satisfy %s %s\n",
 					tool.Name, mismatch.constraint.Operator, mismatch.constraint.Version)
 				return nil
 			}
 			if !e.allowToolVersionChanges {
-				_, _ = fmt.Fprintf(e.output, "⚠️  Tool '%s' version %s does not satisfy %s %s; refusing to change the installed version without --allow-tool-version-changes\n",
+				e.iconf(execCtx, "⚠️  ", "Tool '%s' version %s does not satisfy %s %s; refusing to change the installed version without --allow-tool-version-changes\n",
 					tool.Name, mismatch.currentVersion, mismatch.constraint.Operator, mismatch.constraint.Version)
 				return fmt.Errorf("required tool '%s' version %s does not satisfy constraint %s %s; rerun with --allow-tool-version-changes to allow provisioning to change installed versions",
 					tool.Name, mismatch.currentVersion, mismatch.constraint.Operator, mismatch.constraint.Version)
@@ -116,7 +116,7 @@ func (e *Engine) checkSingleToolRequirement(detector toolDetector, tool statemen
 				fmt.Sprintf("tool version %s does not satisfy %s %s", mismatch.currentVersion, mismatch.constraint.Operator, mismatch.constraint.Version))
 		}
 		if e.dryRun {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] ❌ Tool '%s' version %s does not satisfy %s %s\n",
+			_, _ = fmt.Fprintf(e.out(execCtx), "[DRY RUN] ❌ Tool '%s' version %s does not satisfy %s %s\n",
 				tool.Name, mismatch.currentVersion, mismatch.constraint.Operator, mismatch.constraint.Version)
 			return nil
 		}
@@ -126,14 +126,14 @@ func (e *Engine) checkSingleToolRequirement(detector toolDetector, tool statemen
 
 	if len(tool.Constraints) > 0 {
 		if e.verbose || e.dryRun {
-			_, _ = fmt.Fprintf(e.output, "✅  %s %s (%s)\n",
+			e.iconf(execCtx, "✅  ", "%s %s (%s)\n",
 				tool.Name, currentVersion, formatConstraints(tool.Constraints))
 		}
 		return nil
 	}
 
 	if e.verbose || e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "✅  %s is available\n", tool.Name)
+		e.iconf(execCtx, "✅  ", "%s is available\n", tool.Name)
 	}
 	return nil
 }
@@ -173,9 +173,9 @@ func (e *Engine) provisionAndRecheck(tool statement.ToolRequirement, projectCtx
 
 	command := resolution.InstallCommand()
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "🔧 Provisioning '%s' because %s\n", tool.Name, reason)
-		_, _ = fmt.Fprintf(e.output, "   source: %s\n", resolution.Source)
-		_, _ = fmt.Fprintf(e.output, "   command: %s\n", command)
+		e.iconf(execCtx, "🔧 ", "Provisioning '%s' because %s\n", tool.Name, reason)
+		_, _ = fmt.Fprintf(e.out(execCtx), "   source: %s\n", resolution.Source)
+		_, _ = fmt.Fprintf(e.out(execCtx), "   command: %s\n", command)
 	}
 
 	if err := e.provisionCommandRunner(command, execCtx); err != nil {
@@ -188,7 +188,7 @@ func (e *Engine) provisionAndRecheck(tool statement.ToolRequirement, projectCtx
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "✅  Provisioned '%s' successfully\n", tool.Name)
+		e.iconf(execCtx, "✅  ", "Provisioned '%s' successfully\n", tool.Name)
 	}
 	return nil
 }
@@ -200,7 +200,7 @@ func (e *Engine) runProvisioningCommand(command string, execCtx *ExecutionContex
 	}
 	opts.CaptureOutput = true
 	opts.StreamOutput = true
-	opts.Output = e.output
+	opts.Output = e.out(execCtx)
 	opts.WorkingDir = e.provisioningWorkingDir(execCtx)
 
 	_, err := shell.Execute(command, opts)
@@ -48,7 +48,7 @@ func (e *Engine) executeSecretSet(secretStmt *statement.Secret, ctx *ExecutionCo
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would set secret %s:%s = [REDACTED]\n", namespace, secretStmt.Key)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would set secret %s:%s = [REDACTED]\n", namespace, secretStmt.Key)
 		return nil
 	}
 
@@ -57,7 +57,7 @@ func (e *Engine) executeSecretSet(secretStmt *statement.Secret, ctx *ExecutionCo
 		if err := e.secretsManager.Set(namespace, secretStmt.Key, interpolatedValue); err != nil {
 			return fmt.Errorf("failed to set secret %s:%s: %w", namespace, secretStmt.Key, err)
 		}
-		_, _ = fmt.Fprintf(e.output, "🔐  Secret %s stored securely (namespace: %s)\n", secretStmt.Key, namespace)
+		e.iconf(ctx, "🔐  ", "Secret %s stored securely (namespace: %s)\n", secretStmt.Key, namespace)
 	} else {
 		return fmt.Errorf("secrets manager not initialized")
 	}
@@ -78,7 +78,7 @@ func (e *Engine) executeSecretGet(secretStmt *statement.Secret, ctx *ExecutionCo
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would get secret %s:%s\n", namespace, secretStmt.Key)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would get secret %s:%s\n", namespace, secretStmt.Key)
 		return nil
 	}
 
@@ -91,13 +91,13 @@ func (e *Engine) executeSecretGet(secretStmt *statement.Secret, ctx *ExecutionCo
 			if secretStmt.Default != "" {
 				interpolatedDefault := e.interpolateVariables(secretStmt.Default, ctx)
 				value = interpolatedDefault
-				_, _ = fmt.Fprintf(e.output, "🔓 Secret %s not found, using default value (namespace: %s)\n", secretStmt.Key, namespace)
+				e.iconf(ctx, "🔓 ", "Secret %s not found, using default value (namespace: %s)\n", secretStmt.Key, namespace)
 			} else {
 				return fmt.Errorf("failed to get secret %s:%s: %w", namespace, secretStmt.Key, err)
 			}
 		} else {
 			value = val
-			_, _ = fmt.Fprintf(e.output, "🔓 Retrieved secret %s (namespace: %s)\n", secretStmt.Key, namespace)
+			e.iconf(ctx, "🔓 ", "Retrieved secret %s (namespace: %s)\n", secretStmt.Key, namespace)
 		}
 	} else {
 		return fmt.Errorf("secrets manager not initialized")
@@ -125,7 +125,7 @@ func (e *Engine) executeSecretDelete(secretStmt *statement.Secret, ctx *Executio
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would delete secret %s:%s\n", namespace, secretStmt.Key)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would delete secret %s:%s\n", namespace, secretStmt.Key)
 		return nil
 	}
 
@@ -134,7 +134,7 @@ func (e *Engine) executeSecretDelete(secretStmt *statement.Secret, ctx *Executio
 		if err := e.secretsManager.Delete(namespace, secretStmt.Key); err != nil {
 			return fmt.Errorf("failed to delete secret %s:%s: %w", namespace, secretStmt.Key, err)
 		}
-		_, _ = fmt.Fprintf(e.output, "🗑️  Secret %s deleted (namespace: %s)\n", secretStmt.Key, namespace)
+		e.iconf(ctx, "🗑️  ", "Secret %s deleted (namespace: %s)\n", secretStmt.Key, namespace)
 	} else {
 		return fmt.Errorf("secrets manager not initialized")
 	}
@@ -155,7 +155,7 @@ func (e *Engine) executeSecretExists(secretStmt *statement.Secret, ctx *Executio
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would check if secret %s:%s exists\n", namespace, secretStmt.Key)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would check if secret %s:%s exists\n", namespace, secretStmt.Key)
 		return nil
 	}
 
@@ -167,9 +167,9 @@ func (e *Engine) executeSecretExists(secretStmt *statement.Secret, ctx *Executio
 		}
 
 		if exists {
-			_, _ = fmt.Fprintf(e.output, "✅  Secret %s exists (namespace: %s)\n", secretStmt.Key, namespace)
+			e.iconf(ctx, "✅  ", "Secret %s exists (namespace: %s)\n", secretStmt.Key, namespace)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "❌  Secret %s does not exist (namespace: %s)\n", secretStmt.Key, namespace)
+			e.iconf(ctx, "❌  ", "Secret %s does not exist (namespace: %s)\n", secretStmt.Key, namespace)
 		}
 	} else {
 		return fmt.Errorf("secrets manager not initialized")
@@ -191,7 +191,7 @@ func (e *Engine) executeSecretList(secretStmt *statement.Secret, ctx *ExecutionC
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would list secrets in namespace: %s\n", namespace)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would list secrets in namespace: %s\n", namespace)
 		return nil
 	}
 
@@ -217,11 +217,11 @@ func (e *Engine) executeSecretList(secretStmt *statement.Secret, ctx *ExecutionC
 		}
 
 		if len(keys) == 0 {
-			_, _ = fmt.Fprintf(e.output, "📋 No secrets found in namespace: %s\n", namespace)
+			e.iconf(ctx, "📋 ", "No secrets found in namespace: %s\n", namespace)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "📋 Secrets in namespace %s:\n", namespace)
+			e.iconf(ctx, "📋 ", "Secrets in namespace %s:\n", namespace)
 			for _, key := range keys {
-				_, _ = fmt.Fprintf(e.output, "   - %s\n", key)
+				_, _ = fmt.Fprintf(e.out(ctx), "   - %s\n", key)
 			}
 		}
 	} else {
@@ -1,9 +1,15 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/platform"
 	"github.com/phillarmonic/drun/v2/internal/shell"
@@ -17,6 +23,10 @@ import (
 
 // executeShell executes a shell command statement
 func (e *Engine) executeShell(shellStmt *statement.Shell, ctx *ExecutionContext) error {
+	if shellStmt.Host != "" || shellStmt.HostGroup != "" {
+		return e.executeRemoteShell(shellStmt, ctx)
+	}
+
 	var svcCtx *serviceContextInfo
 	var err error
 	if shellStmt.ServiceScoped {
@@ -37,26 +47,40 @@ func (e *Engine) executeMultilineShell(shellStmt *statement.Shell, ctx *Executio
 	// Interpolate variables in all commands
 	var interpolatedCommands []string
 	for _, cmd := range shellStmt.Commands {
-		interpolatedCmd := e.interpolateVariables(cmd, ctx)
+		interpolatedCmd, _ := e.interpolateShellCommand(cmd, ctx)
 		interpolatedCommands = append(interpolatedCommands, interpolatedCmd)
 	}
 
 	// Join commands with newlines to create a single script
 	script := strings.Join(interpolatedCommands, "\n")
+	keepLine, err := e.lineFilter(shellStmt.LineFilters, ctx)
+	if err != nil {
+		return err
+	}
 
 	if e.dryRun {
+		e.writeContainerDryRun(ctx)
 		if svcCtx != nil {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute multiline shell commands in service '%s' (%s):\n", svcCtx.Name, svcCtx.Path)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute multiline shell commands in service '%s' (%s):\n", svcCtx.Name, svcCtx.Path)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute multiline shell commands:\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute multiline shell commands:\n")
 		}
 		for i, cmd := range interpolatedCommands {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN]   %d: %s\n", i+1, cmd)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN]   %d: %s\n", i+1, cmd)
 		}
+		if err := e.checkDryRunSyntax(shellStmt, script, ctx); err != nil {
+			return err
+		}
+		e.writeEscalationDryRun(shellStmt, script, ctx)
+		e.writeShellLogDryRun(shellStmt, ctx)
+		e.writeLineFiltersDryRun(shellStmt.LineFilters, ctx)
 		if shellStmt.CaptureVar != "" {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would capture output as: %s\n", shellStmt.CaptureVar)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture output as: %s\n", shellStmt.CaptureVar)
 			// Set a placeholder value for the captured variable in dry-run mode
-			ctx.Variables[shellStmt.CaptureVar] = "[DRY RUN] command output"
+			e.assignVariable(ctx, shellStmt.CaptureVar, "[DRY RUN] command output", "capture")
+		}
+		if shellStmt.ExitStateVar != "" {
+			e.assignVariable(ctx, shellStmt.ExitStateVar, "", "exit code mapping")
 		}
 		return nil
 	}
@@ -68,85 +92,221 @@ func (e *Engine) executeMultilineShell(shellStmt *statement.Shell, ctx *Executio
 	if shouldBufferShellOutput(ctx, shellStmt) {
 		opts.StreamOutput = false
 	}
-	opts.Output = e.output
+	opts.Output = e.out(ctx)
+	opts.LineFilter = keepLine
 	if svcCtx != nil {
 		opts.WorkingDir = svcCtx.Path
 	} else if ctx != nil && ctx.WorkingDir != "" {
 		opts.WorkingDir = ctx.WorkingDir
 	}
+	if err := e.applyTaskContainer(opts, ctx); err != nil {
+		return err
+	}
+	if err := e.applyRunAs(opts, shellStmt, ctx); err != nil {
+		return err
+	}
 
 	// Show what we're about to execute (verbose mode only)
-	if e.verbose {
+	if e.isVerboseShell(shellStmt) {
 		switch shellStmt.Action {
 		case "run":
 			if svcCtx != nil {
-				_, _ = fmt.Fprintf(e.output, "🏃 Running multiline commands in service '%s' (%d lines):\n", svcCtx.Name, len(interpolatedCommands))
+				e.iconf(ctx, "🏃 ", "Running multiline commands in service '%s' (%d lines):\n", svcCtx.Name, len(interpolatedCommands))
 			} else {
-				_, _ = fmt.Fprintf(e.output, "🏃 Running multiline commands (%d lines):\n", len(interpolatedCommands))
+				e.iconf(ctx, "🏃 ", "Running multiline commands (%d lines):\n", len(interpolatedCommands))
 			}
 		case "exec":
-			_, _ = fmt.Fprintf(e.output, "⚡ Executing multiline commands (%d lines):\n", len(interpolatedCommands))
+			e.iconf(ctx, "⚡ ", "Executing multiline commands (%d lines):\n", len(interpolatedCommands))
 		case "shell":
-			_, _ = fmt.Fprintf(e.output, "🐚 Shell multiline commands (%d lines):\n", len(interpolatedCommands))
+			e.iconf(ctx, "🐚 ", "Shell multiline commands (%d lines):\n", len(interpolatedCommands))
 		case "capture":
-			_, _ = fmt.Fprintf(e.output, "📥  Capturing multiline commands (%d lines):\n", len(interpolatedCommands))
+			e.iconf(ctx, "📥  ", "Capturing multiline commands (%d lines):\n", len(interpolatedCommands))
 		}
 
 		// Show each command with line numbers
 		for i, cmd := range interpolatedCommands {
-			_, _ = fmt.Fprintf(e.output, "  %d: %s\n", i+1, cmd)
+			_, _ = fmt.Fprintf(e.out(ctx), "  %d: %s\n", i+1, cmd)
 		}
 	}
 
+	logWriter, closeLogs, err := e.openShellLogs(shellStmt, ctx)
+	defer closeLogs()
+	if err != nil {
+		return err
+	}
+	opts.LogWriter = logWriter
+
 	// Execute the script as a single shell session
+	shellStart := e.notifyShellStart(script, ctx)
 	result, err := shell.Execute(script, opts)
-	if err != nil {
+	e.notifyShellEnd(script, shellStart, result, err, ctx)
+	e.credentials.masker.flush()
+	if deadlineErr := e.checkDeadline(ctx); deadlineErr != nil {
+		return deadlineErr
+	}
+	if err = e.resolveShellExit(shellStmt, result, err, ctx); err != nil {
 		if shouldBufferShellOutput(ctx, shellStmt) {
-			writeBufferedShellFailure(e.output, result)
-			writeBufferedShellFailureSummary(e.output, script, result)
+			writeBufferedShellFailure(e.out(ctx), result)
+			writeBufferedShellFailureSummary(e.out(ctx), script, result)
 		}
-		_, _ = fmt.Fprintf(e.output, "❌  Multiline command failed: %v\n", err)
+		e.iconf(ctx, "❌  ", "Multiline command failed: %v\n", err)
 		return err
 	}
 
 	// Handle capture
 	if shellStmt.CaptureVar != "" && shellStmt.Action == "capture" {
-		ctx.Variables[shellStmt.CaptureVar] = result.Stdout
-		_, _ = fmt.Fprintf(e.output, "📦  Captured output in variable '%s'\n", shellStmt.CaptureVar)
+		e.assignVariable(ctx, shellStmt.CaptureVar, shell.FilterLines(result.Stdout, keepLine), "capture")
+		e.iconf(ctx, "📦  ", "Captured output in variable '%s'\n", shellStmt.CaptureVar)
 	}
 
 	// Show execution summary
 	if result.Success {
-		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "✅  Multiline commands completed successfully (exit code: %d, duration: %v)\n",
+		if e.isVerboseShell(shellStmt) {
+			e.iconf(ctx, "✅  ", "Multiline commands completed successfully (exit code: %d, duration: %v)\n",
 				result.ExitCode, result.Duration)
 		}
 	} else {
-		_, _ = fmt.Fprintf(e.output, "⚠️  Multiline commands completed with exit code: %d (duration: %v)\n",
+		e.iconf(ctx, "⚠️  ", "Multiline commands completed with exit code: %d (duration: %v)\n",
 			result.ExitCode, result.Duration)
 	}
 
 	return nil
 }
 
+// checkDryRunSyntax parses a command a dry run would execute with the shell
+// that would run it, when --check-syntax is given. A syntax error fails the
+// dry run with the interpolated command and the line of the statement.
+func (e *Engine) checkDryRunSyntax(shellStmt *statement.Shell, command string, ctx *ExecutionContext) error {
+	if !e.checkShellSyntax {
+		return nil
+	}
+	shellPath := e.getPlatformShellConfig(ctx).Shell
+	if ctx.Container != "" {
+		// Container tasks run their commands with the image's sh
+		shellPath = "sh"
+	}
+
+	err := shell.CheckSyntax(command, shellPath)
+	var syntaxErr *shell.SyntaxError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		location := fmt.Sprintf("line %d", shellStmt.Line)
+		if ctx.CurrentFile != "" {
+			location = fmt.Sprintf("%s:%d", ctx.CurrentFile, shellStmt.Line)
+		}
+		return fmt.Errorf("shell syntax error in task '%s' at %s: %s\n  command: %s",
+			ctx.CurrentTask, location, syntaxErr.Message, strings.ReplaceAll(command, "\n", "\n           "))
+	default:
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Syntax not checked: %v\n", err)
+		return nil
+	}
+}
+
+// resolveShellExit applies a statement's exit code mappings and failure
+// message to the outcome of its command. A mapped exit code is an expected
+// outcome: the command succeeds and the outcome is stored in the statement's
+// state variable.
+func (e *Engine) resolveShellExit(shellStmt *statement.Shell, result *shell.Result, err error, ctx *ExecutionContext) error {
+	if result != nil {
+		if label, ok := shellStmt.ExitCodeLabel(result.ExitCode); ok {
+			result.Success = true
+			e.iconf(ctx, "ℹ️  ", "Exit code %d: %s\n", result.ExitCode, label)
+			if shellStmt.ExitStateVar != "" {
+				e.assignVariable(ctx, shellStmt.ExitStateVar, label, "exit code mapping")
+			}
+			return nil
+		}
+	}
+	if err == nil && shellStmt.ExitStateVar != "" {
+		e.assignVariable(ctx, shellStmt.ExitStateVar, "", "exit code mapping")
+	}
+	if err != nil && shellStmt.FailureMessage != "" {
+		return fmt.Errorf("%s: %w", e.interpolateVariables(shellStmt.FailureMessage, ctx), err)
+	}
+	return err
+}
+
+// applyTaskContainer makes opts run inside the current task's container, if
+// it declares one. The workspace is the directory drun was started in, and
+// task parameters are passed to the container as environment variables.
+func (e *Engine) applyTaskContainer(opts *shell.Options, ctx *ExecutionContext) error {
+	if ctx == nil || ctx.Container == "" {
+		return nil
+	}
+
+	image, err := e.interpolateVariablesWithError(ctx.Container, ctx)
+	if err != nil {
+		return fmt.Errorf("in container image: %w", err)
+	}
+	workspace := ctx.OriginalWorkingDir
+	if workspace == "" {
+		if workspace, err = os.Getwd(); err != nil {
+			return fmt.Errorf("failed to determine the container workspace: %w", err)
+		}
+	}
+	if opts.WorkingDir != "" && !isWithinDir(workspace, opts.WorkingDir) {
+		return fmt.Errorf("working directory %s is outside the container workspace %s", opts.WorkingDir, workspace)
+	}
+
+	if opts.Environment == nil {
+		opts.Environment = make(map[string]string, len(ctx.Parameters))
+	}
+	for name, value := range ctx.Parameters {
+		if _, exists := opts.Environment[name]; !exists && value != nil {
+			opts.Environment[name] = value.AsString()
+		}
+	}
+	opts.Container = &shell.Container{Image: image, Workspace: workspace}
+	return nil
+}
+
+// writeContainerDryRun reports the container a dry-run shell statement would use
+func (e *Engine) writeContainerDryRun(ctx *ExecutionContext) {
+	if ctx != nil && ctx.Container != "" {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would run in container: %s\n", e.interpolateVariables(ctx.Container, ctx))
+	}
+}
+
+// isWithinDir reports whether path is dir or one of its descendants
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // getPlatformShellConfig returns the shell configuration for the current platform
 func (e *Engine) getPlatformShellConfig(ctx *ExecutionContext) *shell.Options {
 	opts := shell.DefaultOptions()
+	if remaining, ok := ctx.Deadline.remaining(); ok {
+		opts.Timeout = remaining
+	}
 
-	if ctx.Project == nil || len(ctx.Project.ShellConfigs) == 0 {
+	if ctx.Project == nil {
 		return opts
 	}
 
-	// Determine current platform
-	platformName := platform.Current()
-
 	// Get platform-specific configuration
-	config, exists := ctx.Project.ShellConfigs[platformName]
-	if !exists {
-		return opts
+	if config, exists := ctx.Project.ShellConfigs[platform.Current()]; exists {
+		applyShellConfig(opts, config)
 	}
+	opts.InheritEnv = shellEnvFilter(ctx.Project)
 
-	// Apply platform configuration
+	// Project PATH entries take precedence over both the shell config PATH
+	// and the inherited PATH
+	if len(ctx.Project.PathEntries) > 0 {
+		basePath, ok := opts.Environment["PATH"]
+		if !ok {
+			basePath = os.Getenv("PATH")
+		}
+		opts.Environment["PATH"] = shell.PrependPath(ctx.Project.PathEntries, basePath)
+	}
+
+	return opts
+}
+
+// applyShellConfig applies a platform shell configuration to opts
+func applyShellConfig(opts *shell.Options, config *ast.PlatformShellConfig) {
 	if config.Executable != "" {
 		opts.Shell = config.Executable
 	}
@@ -171,6 +331,67 @@ func (e *Engine) getPlatformShellConfig(ctx *ExecutionContext) *shell.Options {
 			opts.Environment[key] = value
 		}
 	}
+}
 
-	return opts
+// shellEscapingSetting is the project setting key written by
+// `set shell escaping to "strict"`
+const shellEscapingSetting = "shell_escaping"
+
+// checkShellEscaping rejects unknown `set shell escaping` modes before any
+// task runs
+func checkShellEscaping(projectCtx *ProjectContext) error {
+	if projectCtx == nil {
+		return nil
+	}
+	switch mode := projectCtx.Settings[shellEscapingSetting]; mode {
+	case "", "strict", "off":
+		return nil
+	default:
+		return fmt.Errorf("set shell escaping: expected \"strict\" or \"off\", got %q", mode)
+	}
+}
+
+// interpolateShellCommand interpolates a run/exec/shell/capture command. With
+// strict shell escaping every interpolated value is quoted for the shell,
+// unless it is written as {$var | raw}.
+func (e *Engine) interpolateShellCommand(command string, ctx *ExecutionContext) (string, error) {
+	if ctx != nil && ctx.Project != nil && ctx.Project.Settings[shellEscapingSetting] == "strict" {
+		return e.interpolator.InterpolateShellWithError(command, ctx)
+	}
+	return e.interpolateVariablesWithError(command, ctx)
+}
+
+// shellEnvFilter returns the filter for the variables shell commands inherit
+// from drun, or nil when the project does not restrict them. With an allow
+// list only matching variables are inherited; deny patterns win over allow
+// patterns. Variables drun sets itself, such as the shell config environment
+// and the project PATH, are passed regardless.
+func shellEnvFilter(projectCtx *ProjectContext) func(name string) bool {
+	if len(projectCtx.ShellEnvAllow) == 0 && len(projectCtx.ShellEnvDeny) == 0 {
+		return nil
+	}
+	allow, deny := projectCtx.ShellEnvAllow, projectCtx.ShellEnvDeny
+	return func(name string) bool {
+		if len(allow) > 0 && !matchesEnvPattern(allow, name) {
+			return false
+		}
+		return !matchesEnvPattern(deny, name)
+	}
+}
+
+// matchesEnvPattern reports whether the variable name matches one of the
+// patterns. Names are compared case-insensitively on Windows.
+func matchesEnvPattern(patterns []string, name string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, pattern := range patterns {
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
 }
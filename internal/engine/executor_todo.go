@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: TODO Statements
+// This file implements `todo "implement rollback"`, a placeholder for work
+// that is not written yet. It prints a notice, and the project's
+// `set todo behavior to "warn"|"skip"|"fail"` decides what follows: the task
+// goes on (the default), the rest of the task is skipped and the task counts
+// as done, or the task fails.
+
+// todoBehaviorSetting is the project setting key written by
+// `set todo behavior to "skip"`
+const todoBehaviorSetting = "todo_behavior"
+
+// Todo behaviors
+const (
+	todoWarn = "warn"
+	todoSkip = "skip"
+	todoFail = "fail"
+)
+
+// todoSkipped stops the rest of a task at a todo; executeTask turns it into
+// success
+type todoSkipped struct {
+	Message string
+}
+
+func (t todoSkipped) Error() string {
+	return "TODO: " + t.Message
+}
+
+// isTodoSkipped reports whether err skips the rest of a task at a todo
+func isTodoSkipped(err error) bool {
+	var skipped todoSkipped
+	return errors.As(err, &skipped)
+}
+
+// todoBehavior returns the project's todo behavior, warn when unset
+func todoBehavior(projectCtx *ProjectContext) (string, error) {
+	if projectCtx == nil {
+		return todoWarn, nil
+	}
+	behavior, ok := projectCtx.Settings[todoBehaviorSetting]
+	if !ok {
+		return todoWarn, nil
+	}
+	switch behavior {
+	case todoWarn, todoSkip, todoFail:
+		return behavior, nil
+	}
+	return "", fmt.Errorf("set todo behavior: expected \"warn\", \"skip\" or \"fail\", got %q", behavior)
+}
+
+// executeTodo announces unwritten work and applies the todo behavior
+func (e *Engine) executeTodo(stmt *statement.Todo, ctx *ExecutionContext) error {
+	message := e.interpolateVariables(stmt.Message, ctx)
+	behavior, err := todoBehavior(ctx.Project)
+	if err != nil {
+		return err
+	}
+
+	if e.dryRun {
+		switch behavior {
+		case todoSkip:
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] TODO: %s (would skip the rest of the task)\n", message)
+		case todoFail:
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] TODO: %s (would fail the task)\n", message)
+		default:
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] TODO: %s\n", message)
+		}
+		return nil
+	}
+
+	e.iconf(ctx, "⚠️  ", "TODO: %s\n", message)
+	switch behavior {
+	case todoSkip:
+		e.iconf(ctx, "⏭️  ", "Skipping the rest of task '%s'\n", ctx.CurrentTask)
+		return todoSkipped{Message: message}
+	case todoFail:
+		return fmt.Errorf("task '%s' is not finished: TODO: %s", ctx.CurrentTask, message)
+	}
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/shell"
+	"github.com/phillarmonic/drun/v2/internal/types"
+	"github.com/phillarmonic/drun/v2/internal/ui"
 )
 
 // Domain: Variable Operations Execution
@@ -26,7 +28,7 @@ func (e *Engine) executeVariable(varStmt *statement.Variable, ctx *ExecutionCont
 		return e.executeTransformStatement(varStmt, ctx)
 	case "capture":
 		return e.executeCaptureStatement(varStmt, ctx)
-	case "capture_shell":
+	case "capture_shell", "capture_lines":
 		return e.executeCaptureShellStatement(varStmt, ctx)
 	default:
 		return fmt.Errorf("unknown variable operation: %s", varStmt.Operation)
@@ -46,15 +48,21 @@ func (e *Engine) executeLetStatement(varStmt *statement.Variable, ctx *Execution
 	}
 
 	// Store the variable in the context even in dry run for interpolation
-	ctx.Variables[varName] = interpolatedValue
+	previous, existed := e.assignVariable(ctx, varName, interpolatedValue, "let")
+	if varStmt.Shadow {
+		if err := shadowParameter(ctx, varName, interpolatedValue); err != nil {
+			return err
+		}
+	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would set variable %s = %s\n", varName, interpolatedValue)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would set variable %s = %s\n", varName, interpolatedValue)
 		return nil
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "📝  Set variable %s = %s\n", varName, interpolatedValue)
+		e.iconf(ctx, "📝  ", "Set variable %s\n", varName)
+		e.reportVariableChange(varName, previous, existed, interpolatedValue, ctx)
 	}
 
 	return nil
@@ -73,20 +81,43 @@ func (e *Engine) executeSetStatement(varStmt *statement.Variable, ctx *Execution
 	}
 
 	// Store the variable in the context even in dry run for interpolation
-	ctx.Variables[varName] = interpolatedValue
+	previous, existed := e.assignVariable(ctx, varName, interpolatedValue, "set")
+	if varStmt.Shadow {
+		if err := shadowParameter(ctx, varName, interpolatedValue); err != nil {
+			return err
+		}
+	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would set variable %s to %s\n", varName, interpolatedValue)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would set variable %s to %s\n", varName, interpolatedValue)
 		return nil
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "📝  Set variable %s to %s\n", varName, interpolatedValue)
+		e.iconf(ctx, "📝  ", "Set variable %s\n", varName)
+		e.reportVariableChange(varName, previous, existed, interpolatedValue, ctx)
 	}
 
 	return nil
 }
 
+// shadowParameter replaces the value of the task parameter a `let shadow` or
+// `set shadow` reassigns, so conditions and loops that read the parameter see
+// the new value too. The value keeps the parameter's type.
+func shadowParameter(ctx *ExecutionContext, varName, value string) error {
+	name := strings.TrimPrefix(varName, "$")
+	param, ok := ctx.Parameters[name]
+	if !ok || param == nil {
+		return nil
+	}
+	shadowed, err := types.NewValue(param.Type, value)
+	if err != nil {
+		return fmt.Errorf("cannot shadow parameter $%s: %v", name, err)
+	}
+	ctx.Parameters[name] = shadowed
+	return nil
+}
+
 // executeTransformStatement executes "transform variable with function args" statements
 func (e *Engine) executeTransformStatement(varStmt *statement.Variable, ctx *ExecutionContext) error {
 	// Determine the variable name (namespace it if in an included snippet/task)
@@ -108,15 +139,20 @@ func (e *Engine) executeTransformStatement(varStmt *statement.Variable, ctx *Exe
 	}
 
 	// Update the variable with the transformed value even in dry run for interpolation
-	ctx.Variables[varName] = newValue
+	e.assignVariable(ctx, varName, newValue, "transform "+varStmt.Function)
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would transform variable %s with %s: %s -> %s\n",
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would transform variable %s with %s: %s -> %s\n",
 			varName, varStmt.Function, currentValue, newValue)
 		return nil
 	}
-	_, _ = fmt.Fprintf(e.output, "🔄  Transformed variable %s with %s: %s -> %s\n",
-		varName, varStmt.Function, currentValue, newValue)
+	if e.verbose {
+		e.iconf(ctx, "🔄  ", "Transformed variable %s with %s\n", varName, varStmt.Function)
+		e.reportVariableChange(varName, currentValue, true, newValue, ctx)
+		return nil
+	}
+	e.iconf(ctx, "🔄  ", "Transformed variable %s with %s: %s -> %s\n",
+		varName, varStmt.Function, traceValue(varName, currentValue), traceValue(varName, newValue))
 
 	return nil
 }
@@ -133,32 +169,40 @@ func (e *Engine) executeCaptureStatement(varStmt *statement.Variable, ctx *Execu
 	}
 
 	// Store the captured value in the context
-	ctx.Variables[varName] = value
+	e.assignVariable(ctx, varName, value, "capture")
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would capture %s: %s\n",
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture %s: %s\n",
 			varName, value)
 		return nil
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "📥  Captured %s: %s\n",
+		e.iconf(ctx, "📥  ", "Captured %s: %s\n",
 			varName, value)
 	}
 
 	return nil
 }
 
-// executeCaptureShellStatement executes "capture from shell command as $variable" statements
+// executeCaptureShellStatement executes "capture from shell command as $variable"
+// and "capture lines of command as $variable" statements
 func (e *Engine) executeCaptureShellStatement(varStmt *statement.Variable, ctx *ExecutionContext) error {
 	// Interpolate variables in the command (value contains the shell command)
-	command := e.interpolateVariables(varStmt.Value, ctx)
+	command, _ := e.interpolateShellCommand(varStmt.Value, ctx)
+	keepLine, err := e.lineFilter(varStmt.LineFilters, ctx)
+	if err != nil {
+		return err
+	}
 
 	// Execute the shell command
 	shellOpts := e.getPlatformShellConfig(ctx)
 	result, err := shell.Execute(command, shellOpts)
+	if deadlineErr := e.checkDeadline(ctx); deadlineErr != nil {
+		return deadlineErr
+	}
 	if err != nil {
-		return fmt.Errorf("failed to capture from shell command '%s': %v", command, err)
+		return fmt.Errorf("failed to capture from shell command '%s': %w", command, err)
 	}
 
 	// Determine the variable name (namespace it if in an included snippet/task)
@@ -167,24 +211,71 @@ func (e *Engine) executeCaptureShellStatement(varStmt *statement.Variable, ctx *
 		varName = ctx.CurrentNamespace + "." + varStmt.Name
 	}
 
-	// Store the captured output (trimmed)
-	value := strings.TrimSpace(result.Stdout)
-	ctx.Variables[varName] = value
+	// Store the captured output (trimmed), or its lines as a list
+	stdout := shell.FilterLines(result.Stdout, keepLine)
+	value := strings.TrimSpace(stdout)
+	if varStmt.Operation == "capture_lines" {
+		value = formatListLiteral(splitCapturedLines(stdout, varStmt.Arguments))
+	}
+	e.assignVariable(ctx, varName, value, "capture from shell")
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would capture %s from shell: %s\n",
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture %s from shell: %s\n",
 			varName, value)
 		return nil
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "📥  Captured %s from shell: %s\n",
+		e.iconf(ctx, "📥  ", "Captured %s from shell: %s\n",
 			varName, value)
 	}
 
 	return nil
 }
 
+// splitCapturedLines splits command output into lines. Lines are trimmed and
+// empty lines skipped unless options contain "no_trim" or "keep_empty";
+// the newline ending the output never makes an extra line.
+func splitCapturedLines(output string, options []string) []string {
+	trim, keepEmpty := true, false
+	for _, option := range options {
+		switch option {
+		case "no_trim":
+			trim = false
+		case "keep_empty":
+			keepEmpty = true
+		}
+	}
+
+	output = strings.TrimSuffix(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	if output == "" {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if trim {
+			line = strings.TrimSpace(line)
+		}
+		if !keepEmpty && strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatListLiteral formats items as a list literal like ["a", "b"], the
+// form list variables are stored in
+func formatListLiteral(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = `"` + listItemEscaper.Replace(item) + `"`
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+var listItemEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
 // applyTransformation applies a transformation function to a value
 func (e *Engine) applyTransformation(value, function string, args []string, ctx *ExecutionContext) (string, error) {
 	// Interpolate arguments
@@ -224,17 +315,54 @@ func (e *Engine) applyTransformation(value, function string, args []string, ctx
 		}
 		return value, nil
 	case "length":
+		// Characters as a reader sees them, not bytes or code points
+		return fmt.Sprintf("%d", len(ui.Graphemes(value))), nil
+	case "byte length":
 		return fmt.Sprintf("%d", len(value)), nil
 	case "slice":
 		if len(interpolatedArgs) >= 2 {
+			characters := ui.Graphemes(value)
 			start, err1 := strconv.Atoi(interpolatedArgs[0])
 			end, err2 := strconv.Atoi(interpolatedArgs[1])
-			if err1 == nil && err2 == nil && start >= 0 && end <= len(value) && start <= end {
-				return value[start:end], nil
+			if err1 == nil && err2 == nil && start >= 0 && end <= len(characters) && start <= end {
+				return strings.Join(characters[start:end], ""), nil
 			}
 		}
 		return value, nil
+	case "pad left", "pad right":
+		return padToWidth(value, function, interpolatedArgs)
 	default:
 		return "", fmt.Errorf("unknown transformation function: %s", function)
 	}
 }
+
+// padToWidth pads value to a number of terminal columns, so wide characters
+// and emoji count as two and combining marks as none. The fill defaults to
+// a space; a gap too narrow for another copy of a wide fill is finished
+// with spaces.
+func padToWidth(value, function string, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("%s: expected a width", function)
+	}
+	width, err := strconv.Atoi(args[0])
+	if err != nil || width < 0 {
+		return "", fmt.Errorf("%s: invalid width %q", function, args[0])
+	}
+	fill := " "
+	if len(args) > 1 {
+		fill = args[1]
+	}
+	fillWidth := ui.DisplayWidth(fill)
+	if fillWidth == 0 {
+		return "", fmt.Errorf("%s: the fill %q has no width", function, fill)
+	}
+	missing := width - ui.DisplayWidth(value)
+	if missing <= 0 {
+		return value, nil
+	}
+	padding := strings.Repeat(fill, missing/fillWidth)
+	if function == "pad left" {
+		return ui.PadLeft(padding+value, width), nil
+	}
+	return ui.PadRight(value+padding, width), nil
+}
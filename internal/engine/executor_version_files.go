@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/filevalue"
+	"github.com/phillarmonic/drun/v2/internal/scm"
+)
+
+// Domain: Version Files
+// This file implements `get version from files [...]` and
+// `bump version <level> in files [...]`, which read and bump the version
+// declared by several manifests at once. Every file is read and checked
+// before any is written, and bumped files are read back to verify them.
+
+// versionFile is the version one manifest declares
+type versionFile struct {
+	name     string // the file as written in the statement, interpolated
+	path     string
+	format   string
+	selector string
+	version  string
+}
+
+// executeVersionFiles reads or bumps the version of a statement's files
+func (e *Engine) executeVersionFiles(stmt *statement.VersionFiles, ctx *ExecutionContext) error {
+	files := make([]versionFile, 0, len(stmt.Files))
+	for _, file := range stmt.Files {
+		name := e.interpolateVariables(file, ctx)
+		current, err := readVersionFile(name, e.resolveFilesystemPath(name, ctx))
+		if err != nil {
+			return fmt.Errorf("%s version: %w", stmt.Operation, err)
+		}
+		files = append(files, current)
+	}
+	if stmt.InSync {
+		if err := checkVersionsInSync(files); err != nil {
+			return fmt.Errorf("%s version: %w", stmt.Operation, err)
+		}
+	}
+
+	if stmt.Operation == "get" {
+		e.assignVariable(ctx, stmt.CaptureVar, files[0].version, "get version")
+		if e.verbose {
+			e.iconf(ctx, "📦  ", "Captured version %s from %d files as $%s\n", files[0].version, len(files), stmt.CaptureVar)
+		}
+		return nil
+	}
+
+	bumped := make([]string, len(files))
+	for i, file := range files {
+		next, err := bumpVersion(file.version, stmt.Level)
+		if err != nil {
+			return fmt.Errorf("bump version: %s: %w", file.name, err)
+		}
+		bumped[i] = next
+	}
+
+	if e.dryRun {
+		for i, file := range files {
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would bump the version of %s from %s to %s\n", file.name, file.version, bumped[i])
+		}
+	} else {
+		for i, file := range files {
+			if _, _, err := filevalue.UpdateFile(file.format, file.selector, file.path, bumped[i], "fail", ""); err != nil {
+				return fmt.Errorf("bump version: updating %s: %w", file.name, err)
+			}
+		}
+		if err := verifyBumpedVersions(files, bumped, stmt.InSync); err != nil {
+			return fmt.Errorf("bump version: %w", err)
+		}
+		e.iconf(ctx, "🔖  ", "Bumped the %s version of %d files:\n", stmt.Level, len(files))
+		for i, file := range files {
+			_, _ = fmt.Fprintf(e.out(ctx), "  %s: %s -> %s\n", file.name, file.version, bumped[i])
+		}
+	}
+
+	if stmt.CaptureVar != "" {
+		e.assignVariable(ctx, stmt.CaptureVar, bumped[0], "bump version")
+	}
+	return nil
+}
+
+// readVersionFile reads the version a manifest declares
+func readVersionFile(name, path string) (versionFile, error) {
+	// #nosec G304 -- the Drun program explicitly supplies the path.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return versionFile{}, fmt.Errorf("reading %s: %w", name, err)
+	}
+	format, selector, err := filevalue.VersionLocation(path, data)
+	if err != nil {
+		return versionFile{}, fmt.Errorf("%s: %w", name, err)
+	}
+	value, err := filevalue.Read(format, selector, data)
+	if err != nil {
+		return versionFile{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return versionFile{name: name, path: path, format: format, selector: selector, version: value.Text}, nil
+}
+
+// checkVersionsInSync fails when the files declare different versions,
+// listing every file's
+func checkVersionsInSync(files []versionFile) error {
+	for _, file := range files[1:] {
+		if file.version != files[0].version {
+			return fmt.Errorf("the files declare different versions: %s", describeVersions(files))
+		}
+	}
+	return nil
+}
+
+// verifyBumpedVersions reads the bumped files back and fails when one does
+// not declare its new version, or, in sync, when they differ
+func verifyBumpedVersions(files []versionFile, bumped []string, inSync bool) error {
+	written := make([]versionFile, len(files))
+	for i, file := range files {
+		current, err := readVersionFile(file.name, file.path)
+		if err != nil {
+			return fmt.Errorf("verifying: %w", err)
+		}
+		if current.version != bumped[i] {
+			return fmt.Errorf("verifying: %s declares %s after the bump, expected %s", file.name, current.version, bumped[i])
+		}
+		written[i] = current
+	}
+	if inSync {
+		if err := checkVersionsInSync(written); err != nil {
+			return fmt.Errorf("verifying: %w", err)
+		}
+	}
+	return nil
+}
+
+// bumpVersion bumps a MAJOR.MINOR.PATCH version, keeping a leading "v"
+func bumpVersion(version, level string) (string, error) {
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	parsed, err := scm.ParseVersion(strings.TrimPrefix(version, prefix))
+	if err != nil {
+		return "", err
+	}
+	next, err := parsed.Bump(level)
+	if err != nil {
+		return "", err
+	}
+	return prefix + next.Raw, nil
+}
+
+func describeVersions(files []versionFile) string {
+	described := make([]string, len(files))
+	for i, file := range files {
+		described[i] = file.name + " " + file.version
+	}
+	return strings.Join(described, ", ")
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Time-Boxed Sections
+// This file implements `within <duration> [as "name"] [or fail]:` blocks,
+// which warn or fail when their statements take longer than the budget, and
+// the section durations reported when the run finishes.
+
+// sectionTiming is one finished time-boxed section of an execution
+type sectionTiming struct {
+	task     string
+	name     string
+	budget   string
+	duration time.Duration
+	over     bool
+	failed   bool
+}
+
+// executeWithin runs the body of a time-boxed section. The budget is checked
+// once the body finishes; running commands are not interrupted.
+func (e *Engine) executeWithin(stmt *statement.Within, ctx *ExecutionContext) error {
+	name := stmt.Name
+	if name == "" {
+		name = "within " + stmt.Budget
+	}
+	budget, err := time.ParseDuration(stmt.Budget)
+	if err != nil {
+		return fmt.Errorf("section '%s': invalid budget %q: %w", name, stmt.Budget, err)
+	}
+
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would time-box section '%s' to %s\n", name, stmt.Budget)
+	}
+
+	start := time.Now()
+	var bodyErr error
+	for _, bodyStmt := range stmt.Body {
+		if bodyErr = e.executeStatement(bodyStmt, ctx); bodyErr != nil {
+			break
+		}
+	}
+	elapsed := time.Since(start)
+	if e.dryRun {
+		return bodyErr
+	}
+
+	over := elapsed > budget
+	if ctx.Timings != nil {
+		ctx.Timings.addSection(sectionTiming{
+			task:     ctx.CurrentTask,
+			name:     name,
+			budget:   stmt.Budget,
+			duration: elapsed,
+			over:     over,
+			failed:   bodyErr != nil,
+		})
+	}
+	if bodyErr != nil || !over {
+		return bodyErr
+	}
+
+	if stmt.Fail {
+		return fmt.Errorf("section '%s' took %s, over its %s budget", name, formatElapsed(elapsed), stmt.Budget)
+	}
+	e.iconf(ctx, "⚠️  ", "Section '%s' took %s, over its %s budget\n", name, formatElapsed(elapsed), stmt.Budget)
+	return nil
+}
+
+// reportSectionTimings prints the time-boxed sections of the execution with
+// their budgets. It runs with --timings, and otherwise only when a section
+// went over its budget.
+func (e *Engine) reportSectionTimings(ctx *ExecutionContext) {
+	if ctx.Timings == nil {
+		return
+	}
+	sections := ctx.Timings.listSections()
+	overrun := false
+	for _, section := range sections {
+		overrun = overrun || section.over
+	}
+	if len(sections) == 0 || (!e.timings && !overrun) {
+		return
+	}
+
+	labels := make([]string, len(sections))
+	width := 0
+	for i, section := range sections {
+		labels[i] = section.task + " › " + section.name
+		width = max(width, len([]rune(labels[i])))
+	}
+
+	e.iconf(ctx, "\n⏱️  ", "Section durations:\n")
+	for i, section := range sections {
+		line := fmt.Sprintf("  %-*s  %8s / %s", width+len(labels[i])-len([]rune(labels[i])), labels[i], formatElapsed(section.duration), section.budget)
+		if section.over {
+			line += "  over budget"
+		}
+		if section.failed {
+			line += "  failed"
+		}
+		_, _ = fmt.Fprintln(e.out(ctx), line)
+	}
+}
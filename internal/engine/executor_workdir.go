@@ -29,7 +29,7 @@ func (e *Engine) executeChangeWorkdir(stmt *statement.ChangeWorkdir, ctx *Execut
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would set working directory to: %s\n", interpolatedPath)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would set working directory to: %s\n", interpolatedPath)
 		return nil
 	}
 
@@ -67,7 +67,7 @@ func (e *Engine) executeChangeWorkdir(stmt *statement.ChangeWorkdir, ctx *Execut
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "📁 Working directory set to: %s\n", resolved)
+		e.iconf(ctx, "📁 ", "Working directory set to: %s\n", resolved)
 	}
 
 	ctx.WorkingDir = resolved
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineLoopStreamsFileLines(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(data, []byte("alpha\r\n\nbeta skip\ngamma\nstop\nafter\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	program, err := ParseString(fmt.Sprintf(`version: 2.0
+
+task "lines":
+  for each line $row in file %q:
+    if $row is "stop":
+      break
+    if $row is "beta skip":
+      continue
+    info "row=[{$row}]"
+`, data))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := NewEngine(&out).Execute(program, "lines"); err != nil {
+		t.Fatalf("execution error: %v\n%s", err, out.String())
+	}
+	got := out.String()
+	for _, want := range []string{"row=[alpha]", "row=[]", "row=[gamma]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"beta", "after", "\r"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("unexpected %q in output:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestLineLoopReadsLongLinesAndParallelLoops(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data.txt")
+	long := strings.Repeat("x", 3*lineReaderSize)
+	if err := os.WriteFile(data, []byte("one\n"+long+"\nthree"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, parallel := range []string{"", " in parallel"} {
+		program, err := ParseString(fmt.Sprintf(`version: 2.0
+
+task "lines":
+  for each line $row in file %q%s:
+    transform $row with byte length
+    set $size to "{$row}"
+    info "size={$size}"
+`, data, parallel))
+		if err != nil {
+			t.Fatalf("parse error: %v", err)
+		}
+
+		var out bytes.Buffer
+		if err := NewEngine(&out).Execute(program, "lines"); err != nil {
+			t.Fatalf("execution error: %v\n%s", err, out.String())
+		}
+		for _, want := range []string{"size=3\n", fmt.Sprintf("size=%d\n", len(long)), "size=5\n"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%q: expected %q in output:\n%s", parallel, want, out.String())
+			}
+		}
+	}
+}
+
+func TestLineLoopMissingFile(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "lines":
+  for each line $row in file "/does/not/exist.txt":
+    info "{$row}"
+`)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if err := NewEngine(&bytes.Buffer{}).Execute(program, "lines"); err == nil || !strings.Contains(err.Error(), "for each line") {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+}
+
+func TestAppendFileStreamsIntoTarget(t *testing.T) {
+	dir := t.TempDir()
+	part := filepath.Join(dir, "part.log")
+	all := filepath.Join(dir, "all.log")
+	if err := os.WriteFile(part, []byte("part\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(all, []byte("start\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	program, err := ParseString(fmt.Sprintf(`version: 2.0
+
+task "merge":
+  append file %q to file %q
+  append file %q to file %q
+`, part, all, part, all))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if got := program.Tasks[0].Body[0].String(); got != fmt.Sprintf("append file %q to file %q", part, all) {
+		t.Errorf("String() = %q", got)
+	}
+
+	var out bytes.Buffer
+	if err := NewEngine(&out).Execute(program, "merge"); err != nil {
+		t.Fatalf("execution error: %v\n%s", err, out.String())
+	}
+	data, err := os.ReadFile(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "start\npart\npart\n" {
+		t.Errorf("all.log = %q", data)
+	}
+}
+
+func TestReadFileCaptureWarnsAboveThreshold(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(data, []byte(strings.Repeat("a", 2048)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		setting string
+		warns   bool
+		err     string
+	}{
+		{"", false, ""},
+		{"set capture_warning_size to \"1KB\"", true, ""},
+		{"set capture_warning_size to \"4KB\"", false, ""},
+		{"set capture_warning_size to \"0\"", false, ""},
+		{"set capture_warning_size to \"lots\"", false, "capture_warning_size"},
+	}
+	for _, tt := range tests {
+		program, err := ParseString(fmt.Sprintf(`version: 2.0
+
+project "app":
+  %s
+
+task "read":
+  read file %q as $content
+`, tt.setting, data))
+		if err != nil {
+			t.Fatalf("%s: parse error: %v", tt.setting, err)
+		}
+
+		var out bytes.Buffer
+		err = NewEngine(&out).Execute(program, "read")
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected a %q error, got %v", tt.setting, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: execution error: %v", tt.setting, err)
+		}
+		if warned := strings.Contains(out.String(), "holds all of it in memory"); warned != tt.warns {
+			t.Errorf("%s: warned = %v, want %v:\n%s", tt.setting, warned, tt.warns, out.String())
+		}
+	}
+}
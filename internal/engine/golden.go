@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+)
+
+// Domain: Golden Tests
+// This file runs the top-level test blocks of a program: each runs a task
+// on an engine of its own, normalizes what the task printed and compares it
+// with a golden file, or records the golden file when updating.
+
+// Outcomes of a golden test
+const (
+	TestPassed  = "passed"
+	TestFailed  = "failed"
+	TestUpdated = "updated"
+)
+
+// TestResult is the outcome of one golden test
+type TestResult struct {
+	Name   string
+	Golden string   // the golden file as the test declares it
+	Status string   // TestPassed, TestFailed or TestUpdated
+	Diff   []string // the unified diff of a failed test, golden first
+}
+
+var (
+	ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+	timestampPattern  = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?(?: [+-]\d{4}(?: [A-Z]{2,5})?)?`)
+	datePattern       = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+	clockPattern      = regexp.MustCompile(`\b\d{1,2}:\d{2}:\d{2}(?:\.\d+)?\b`)
+	durationPattern   = regexp.MustCompile(`\b(?:\d+h)?(?:\d+m)?\d+(?:\.\d+)?(?:ns|µs|us|ms|s)\b`)
+)
+
+// RunTest runs the task of a test block with the given engine options and
+// compares its normalized output with the test's golden file. With update
+// set, the golden file is written instead. A task that fails is not an
+// error: its error ends the output, so failures can be tested too.
+func RunTest(program *ast.Program, test *ast.TestStatement, currentFile string, update bool, opts ...Option) (TestResult, error) {
+	result := TestResult{Name: test.Name, Golden: test.Golden}
+
+	var output bytes.Buffer
+	opts = append(opts, WithOutput(&output), WithDryRun(test.DryRun))
+	eng := NewEngineWithOptions(opts...)
+	target := TaskTarget{Name: test.Call.TaskName, Params: test.Call.Parameters}
+	if err := eng.ExecuteTargets(program, []TaskTarget{target}, currentFile, false); err != nil {
+		_, _ = fmt.Fprintf(&output, "Error: %v\n", err)
+	}
+
+	root := projectRootDir(currentFile)
+	actual := normalizeTestOutput(output.String(), root)
+	path := test.Golden
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return result, fmt.Errorf("test %q: %w", test.Name, err)
+		}
+		// #nosec G306 -- golden files are committed with the project's sources.
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			return result, fmt.Errorf("test %q: %w", test.Name, err)
+		}
+		result.Status = TestUpdated
+		return result, nil
+	}
+
+	// #nosec G304 -- the test block explicitly names its golden file.
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return result, fmt.Errorf("test %q: golden file %s does not exist; record it with --update-golden", test.Name, test.Golden)
+	}
+	if err != nil {
+		return result, fmt.Errorf("test %q: %w", test.Name, err)
+	}
+	expected = bytes.ReplaceAll(expected, []byte("\r\n"), []byte("\n"))
+
+	result.Diff = diffContents(expected, []byte(actual), test.Golden, "output")
+	result.Status = TestPassed
+	if len(result.Diff) > 0 {
+		result.Status = TestFailed
+	}
+	return result, nil
+}
+
+// normalizeTestOutput removes what differs between machines and runs from a
+// task's output: colors, CRLF line endings and trailing spaces, and the
+// project root, home and temporary directories, host and user names,
+// timestamps, dates, clock times and durations, which become placeholders
+func normalizeTestOutput(output, projectRoot string) string {
+	output = ansiEscapePattern.ReplaceAllString(output, "")
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+
+	for _, replacement := range environmentPlaceholders(projectRoot) {
+		output = strings.ReplaceAll(output, replacement[0], replacement[1])
+	}
+	if host, err := os.Hostname(); err == nil {
+		output = replaceWord(output, host, "<host>")
+	}
+	if current, err := user.Current(); err == nil {
+		output = replaceWord(output, current.Username, "<user>")
+	}
+
+	output = timestampPattern.ReplaceAllString(output, "<timestamp>")
+	output = datePattern.ReplaceAllString(output, "<date>")
+	output = clockPattern.ReplaceAllString(output, "<time>")
+	output = durationPattern.ReplaceAllString(output, "<duration>")
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	normalized := strings.Join(lines, "\n")
+	if normalized == "" {
+		return ""
+	}
+	return normalized + "\n"
+}
+
+// environmentPlaceholders returns the directories to replace in test output
+// with their placeholders, longest first, so the project root wins over
+// the temporary directory or home it lives in. Directories are listed as
+// given and with symlinks resolved.
+func environmentPlaceholders(projectRoot string) [][2]string {
+	dirs := [][2]string{{projectRoot, "<project>"}, {os.TempDir(), "<tmp>"}}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, [2]string{home, "<home>"})
+	}
+
+	var placeholders [][2]string
+	for _, dir := range dirs {
+		if dir[0] == "" || dir[0] == string(filepath.Separator) {
+			continue
+		}
+		placeholders = append(placeholders, [2]string{filepath.Clean(dir[0]), dir[1]})
+		if resolved, err := filepath.EvalSymlinks(dir[0]); err == nil && resolved != filepath.Clean(dir[0]) {
+			placeholders = append(placeholders, [2]string{resolved, dir[1]})
+		}
+	}
+	sort.SliceStable(placeholders, func(i, j int) bool {
+		return len(placeholders[i][0]) > len(placeholders[j][0])
+	})
+	return placeholders
+}
+
+// replaceWord replaces the occurrences of word that are not part of a
+// longer name, such as a host name inside a domain
+func replaceWord(text, word, placeholder string) string {
+	if len(word) < 2 {
+		return text
+	}
+	var out strings.Builder
+	last := 0
+	for _, match := range regexp.MustCompile(regexp.QuoteMeta(word)).FindAllStringIndex(text, -1) {
+		if isNamePart(text, match[0]-1) || isNamePart(text, match[1]) {
+			continue
+		}
+		out.WriteString(text[last:match[0]])
+		out.WriteString(placeholder)
+		last = match[1]
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// isNamePart reports whether the byte at i continues a name; a dot does
+// when a name goes on after it, as in a domain
+func isNamePart(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	switch c := text[i]; {
+	case c == '-' || c == '_':
+		return true
+	case c == '.':
+		return i+1 < len(text) && text[i+1] != ' ' && text[i+1] != '\n'
+	default:
+		return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+}
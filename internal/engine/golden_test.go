@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTestOutput(t *testing.T) {
+	root := filepath.Join(os.TempDir(), "project")
+	output := "\x1b[32m✅ Built\x1b[0m in " + root + "/dist at 2026-03-04T05:06:07.123Z  \r\n" +
+		"Started 2026-03-04 10:11:12 +0000 UTC, finished 10:11:14 after 1m2.5s (12ms idle)\r\n" +
+		"Cache in " + filepath.Join(os.TempDir(), "drun-cache") + " from 2026-03-04\n\n\n"
+
+	want := "✅ Built in <project>/dist at <timestamp>\n" +
+		"Started <timestamp>, finished <time> after <duration> (<duration> idle)\n" +
+		"Cache in <tmp>/drun-cache from <date>\n"
+	if got := normalizeTestOutput(output, root); got != want {
+		t.Errorf("normalizeTestOutput() =\n%q\nwant\n%q", got, want)
+	}
+	if got := normalizeTestOutput("", root); got != "" {
+		t.Errorf("normalizeTestOutput(\"\") = %q", got)
+	}
+}
+
+func TestReplaceWord(t *testing.T) {
+	got := replaceWord("host: ci-box, ci-box.local, my-ci-box and ci-box.", "ci-box", "<host>")
+	if want := "host: <host>, ci-box.local, my-ci-box and <host>."; got != want {
+		t.Errorf("replaceWord() = %q, want %q", got, want)
+	}
+}
+
+func TestRunTestComparesAndUpdatesGoldenFiles(t *testing.T) {
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, ".drun", "spec.drun")
+	program, err := ParseString(`version: 2.0
+
+task "greet":
+  requires $name
+  info "Hello, {$name}"
+  run "echo written to {pwd}"
+
+test "greet":
+  call task "greet" with name="drun"
+  expect output to match golden "tests/greet.golden"
+
+test "greet dry" in dry run:
+  call task "greet" with name="drun"
+  expect output to match golden "tests/greet-dry.golden"
+
+test "missing":
+  call task "missing"
+  expect output to match golden "tests/missing.golden"
+`)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if _, err := RunTest(program, program.Tests[0], specFile, false); err == nil || !strings.Contains(err.Error(), "--update-golden") {
+		t.Fatalf("expected a missing golden file error, got %v", err)
+	}
+
+	for _, test := range program.Tests {
+		result, err := RunTest(program, test, specFile, true)
+		if err != nil || result.Status != TestUpdated {
+			t.Fatalf("RunTest(%s, update) = %+v, %v", test.Name, result, err)
+		}
+	}
+	golden, err := os.ReadFile(filepath.Join(dir, "tests", "greet-dry.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(golden), "[DRY RUN]") {
+		t.Errorf("expected the dry run output in the golden file, got:\n%s", golden)
+	}
+	missing, err := os.ReadFile(filepath.Join(dir, "tests", "missing.golden"))
+	if err != nil || !strings.HasPrefix(string(missing), "Error: ") {
+		t.Errorf("expected the task's error in the golden file, got %q, %v", missing, err)
+	}
+
+	for _, test := range program.Tests {
+		result, err := RunTest(program, test, specFile, false)
+		if err != nil || result.Status != TestPassed {
+			t.Fatalf("RunTest(%s) = %+v, %v", test.Name, result, err)
+		}
+	}
+
+	path := filepath.Join(dir, "tests", "greet.golden")
+	if err := os.WriteFile(path, []byte("ℹ️  Hello, world\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := RunTest(program, program.Tests[0], specFile, false)
+	if err != nil || result.Status != TestFailed {
+		t.Fatalf("RunTest() = %+v, %v; want a failure", result, err)
+	}
+	diff := strings.Join(result.Diff, "\n")
+	if !strings.Contains(diff, "-ℹ️  Hello, world") || !strings.Contains(diff, "+ℹ️  Hello, drun") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
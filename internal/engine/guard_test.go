@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrailingConditionModifiersGuardSingleStatements(t *testing.T) {
+	input := `version: 2.0
+
+task "lint":
+  given $verbose defaults to "false"
+  info "has manifest" only when file "missing-package.json" exists
+  info "quiet run" unless {verbose}
+  info "verbose run" only when {verbose} is "true"
+  for each $pkg in ["old-api", "web"]:
+    warn "legacy {$pkg}" unless {$pkg} is "web"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.ExecuteWithParams(program, "lint", map[string]string{"verbose": "false"}); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	for _, want := range []string{"quiet run", "legacy old-api"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"has manifest", "verbose run", "legacy web"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("did not expect %q in output:\n%s", unwanted, output)
+		}
+	}
+
+	buf.Reset()
+	if err := eng.ExecuteWithParams(program, "lint", map[string]string{"verbose": "true"}); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if output := buf.String(); strings.Contains(output, "quiet run") || !strings.Contains(output, "verbose run") {
+		t.Errorf("expected the guards to follow {verbose}:\n%s", output)
+	}
+}
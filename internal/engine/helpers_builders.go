@@ -3,6 +3,8 @@ package engine
 import (
 	"fmt"
 	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
 )
 
 // Domain: Command Builders
@@ -67,10 +69,12 @@ func (e *Engine) assembleDockerCommand(operation, resource, name string, options
 	return command
 }
 
-// buildGitCommand builds and displays the git command
-func (e *Engine) buildGitCommand(operation, resource, name string, options map[string]string, dryRun bool) error {
+// buildGitCommand builds and displays the git command; configArgs (such as
+// credential -c options) go before the subcommand
+func (e *Engine) buildGitCommand(ctx *ExecutionContext, operation, resource, name string, options map[string]string, dryRun bool, configArgs ...string) error {
 	var gitCmd []string
 	gitCmd = append(gitCmd, "git")
+	gitCmd = append(gitCmd, configArgs...)
 
 	switch operation {
 	case "create":
@@ -209,13 +213,13 @@ func (e *Engine) buildGitCommand(operation, resource, name string, options map[s
 	}
 
 	if dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute Git command: %s\n", strings.Join(gitCmd, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute Git command: %s\n", strings.Join(gitCmd, " "))
 		return nil
 	}
 
 	// Show the actual command being executed
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "Command: %s\n", strings.Join(gitCmd, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "Command: %s\n", strings.Join(gitCmd, " "))
 	}
 
 	// For now, we'll simulate the command execution
@@ -226,18 +230,20 @@ func (e *Engine) buildGitCommand(operation, resource, name string, options map[s
 	return nil
 }
 
-// buildHTTPCommand builds and displays the HTTP request details
-func (e *Engine) buildHTTPCommand(method, url, body string, headers, auth, options map[string]string, dryRun bool) error {
+// buildHTTPCommand builds and displays the HTTP request details. Headers and
+// authentication are listed in declaration order (headerOrder, authOrder).
+func (e *Engine) buildHTTPCommand(method, url, body string, headers, auth, options map[string]string, headerOrder, authOrder []string, dryRun bool, ctx *ExecutionContext) error {
 	var httpCmd []string
 	httpCmd = append(httpCmd, "curl", "-X", method)
 
 	// Add headers
-	for key, value := range headers {
-		httpCmd = append(httpCmd, "-H", fmt.Sprintf("\"%s: %s\"", key, value))
+	for _, key := range ast.OrderedKeys(headers, headerOrder) {
+		httpCmd = append(httpCmd, "-H", fmt.Sprintf("\"%s: %s\"", key, headers[key]))
 	}
 
 	// Add authentication
-	for authType, value := range auth {
+	for _, authType := range ast.OrderedKeys(auth, authOrder) {
+		value := auth[authType]
 		switch authType {
 		case "bearer":
 			httpCmd = append(httpCmd, "-H", fmt.Sprintf("\"Authorization: Bearer %s\"", value))
@@ -286,13 +292,13 @@ func (e *Engine) buildHTTPCommand(method, url, body string, headers, auth, optio
 	httpCmd = append(httpCmd, url)
 
 	if dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute HTTP command: %s\n", strings.Join(httpCmd, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute HTTP command: %s\n", strings.Join(httpCmd, " "))
 		return nil
 	}
 
 	// Show the actual command being executed
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "Command: %s\n", strings.Join(httpCmd, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "Command: %s\n", strings.Join(httpCmd, " "))
 	}
 
 	// For now, we'll simulate the HTTP request execution
@@ -305,7 +311,7 @@ func (e *Engine) buildHTTPCommand(method, url, body string, headers, auth, optio
 }
 
 // buildNetworkCommand builds and executes network commands
-func (e *Engine) buildNetworkCommand(action, target, port, condition string, options map[string]string, dryRun bool) error {
+func (e *Engine) buildNetworkCommand(action, target, port, condition string, options map[string]string, dryRun bool, ctx *ExecutionContext) error {
 	var networkCmd []string
 
 	switch action {
@@ -394,13 +400,13 @@ exit 1`, timeout, retryInterval, target)
 	}
 
 	if dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute network command: %s\n", strings.Join(networkCmd, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute network command: %s\n", strings.Join(networkCmd, " "))
 		return nil
 	}
 
 	// Show the actual command being executed
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "Command: %s\n", strings.Join(networkCmd, " "))
+		_, _ = fmt.Fprintf(e.out(ctx), "Command: %s\n", strings.Join(networkCmd, " "))
 	}
 
 	// For now, we'll simulate the network command execution
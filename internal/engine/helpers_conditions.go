@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/scm"
@@ -16,6 +17,95 @@ import (
 
 var semanticVersionConditionPattern = regexp.MustCompile(`^(.+?)\s+is\s+(older|newer)\s+than\s+version\s+(.+)$`)
 var fileComparisonConditionPattern = regexp.MustCompile(`^file\s+(.+?)\s+(not\s+)?matches\s+file\s+(.+?)\s*$`)
+var comparisonConditionPattern = regexp.MustCompile(`^(.+?)\s+(<=|>=|==|!=|<|>)\s+(.+)$`)
+
+// resolveCondition evaluates a condition of if, when or while: exact file
+// comparisons and semantic-version ordering first, which can fail with an
+// error, then the general evaluator
+func (e *Engine) resolveCondition(condition string, ctx *ExecutionContext) (bool, error) {
+	if result, handled, err := e.resolveNamedCondition(condition, ctx); handled {
+		return result, err
+	}
+	result, handled, err := e.evaluateFileComparisonCondition(condition, ctx)
+	if err != nil || handled {
+		return result, err
+	}
+	result, handled, err = e.evaluateSemanticVersionCondition(condition, ctx)
+	if err != nil || handled {
+		return result, err
+	}
+	return e.evaluateCondition(condition, ctx), nil
+}
+
+// splitLogicalCondition splits a condition at its first "or", or else at its
+// first "and", outside braces; or binds looser than and
+func splitLogicalCondition(condition string) (left, operator, right string, ok bool) {
+	for _, operator := range []string{" or ", " and "} {
+		depth := 0
+		for i := 0; i < len(condition); i++ {
+			switch condition[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			case ' ':
+				if depth == 0 && strings.HasPrefix(condition[i:], operator) {
+					left, right = strings.TrimSpace(condition[:i]), strings.TrimSpace(condition[i+len(operator):])
+					if left != "" && right != "" {
+						return left, strings.TrimSpace(operator), right, true
+					}
+				}
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// evaluateComparisonCondition handles <, <=, >, >=, == and != between two
+// operands, compared as numbers when both are numbers and as strings otherwise
+func (e *Engine) evaluateComparisonCondition(condition string, ctx *ExecutionContext) (bool, bool) {
+	match := comparisonConditionPattern.FindStringSubmatch(strings.TrimSpace(condition))
+	if match == nil {
+		return false, false
+	}
+	left, err := e.resolveConditionOperand(match[1], ctx)
+	if err != nil {
+		left = strings.TrimSpace(match[1])
+	}
+	right, err := e.resolveConditionOperand(match[3], ctx)
+	if err != nil {
+		right = strings.TrimSpace(match[3])
+	}
+
+	comparison := strings.Compare(left, right)
+	leftNumber, leftErr := strconv.ParseFloat(left, 64)
+	rightNumber, rightErr := strconv.ParseFloat(right, 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case leftNumber < rightNumber:
+			comparison = -1
+		case leftNumber > rightNumber:
+			comparison = 1
+		default:
+			comparison = 0
+		}
+	}
+
+	switch match[2] {
+	case "<":
+		return comparison < 0, true
+	case "<=":
+		return comparison <= 0, true
+	case ">":
+		return comparison > 0, true
+	case ">=":
+		return comparison >= 0, true
+	case "==":
+		return comparison == 0, true
+	default: // "!="
+		return comparison != 0, true
+	}
+}
 
 // evaluateFileComparisonCondition handles exact file-content comparisons:
 //
@@ -80,11 +170,11 @@ func (e *Engine) evaluateSemanticVersionCondition(condition string, ctx *Executi
 		return false, false, nil
 	}
 
-	left, err := e.resolveVersionConditionOperand(match[1], ctx)
+	left, err := e.resolveConditionOperand(match[1], ctx)
 	if err != nil {
 		return false, true, fmt.Errorf("resolving left version: %w", err)
 	}
-	right, err := e.resolveVersionConditionOperand(match[3], ctx)
+	right, err := e.resolveConditionOperand(match[3], ctx)
 	if err != nil {
 		return false, true, fmt.Errorf("resolving right version: %w", err)
 	}
@@ -110,7 +200,7 @@ func (e *Engine) evaluateSemanticVersionCondition(condition string, ctx *Executi
 	return comparison > 0, true, nil
 }
 
-func (e *Engine) resolveVersionConditionOperand(operand string, ctx *ExecutionContext) (string, error) {
+func (e *Engine) resolveConditionOperand(operand string, ctx *ExecutionContext) (string, error) {
 	operand = strings.TrimSpace(operand)
 	if strings.HasPrefix(operand, "$ ") {
 		operand = strings.Replace(operand, "$ ", "$", 1)
@@ -280,6 +370,17 @@ func (e *Engine) evaluateCondition(condition string, ctx *ExecutionContext) bool
 		return e.evaluateEnvCondition(strings.TrimPrefix(condition, "env "), ctx)
 	}
 
+	if left, operator, right, ok := splitLogicalCondition(condition); ok {
+		if operator == "or" {
+			return e.evaluateCondition(left, ctx) || e.evaluateCondition(right, ctx)
+		}
+		return e.evaluateCondition(left, ctx) && e.evaluateCondition(right, ctx)
+	}
+
+	if result, handled := e.evaluateComparisonCondition(condition, ctx); handled {
+		return result
+	}
+
 	if result, handled := e.evaluateFilesystemExistsCondition(condition, ctx); handled {
 		return result
 	}
@@ -0,0 +1,329 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/shell"
+)
+
+// Domain: Credential Helpers
+// This file resolves credentials from the project's credential helpers
+// (set credential helper for "host" to "exec:..."). Helpers run lazily, the
+// first time a docker push, git push or HTTP statement needs a host; the
+// result is cached for the rest of the run and masked in all later engine
+// output.
+
+// credential is what a credential helper printed: a token on one line, or a
+// user name and a password on two lines
+type credential struct {
+	Username string
+	Secret   string
+}
+
+// user returns the user name to log in with; helpers that print only a
+// token get a placeholder, which token-based registries and forges accept
+func (c *credential) user() string {
+	if c.Username != "" {
+		return c.Username
+	}
+	return "drun"
+}
+
+// credentialStore caches resolved credentials by host for one run
+type credentialStore struct {
+	mu      sync.Mutex
+	values  map[string]*credential
+	secrets *secretList
+	masker  *secretMasker // in front of the engine output from construction on (see Engine.out), so the output is never swapped
+}
+
+func newCredentialStore(output io.Writer) *credentialStore {
+	secrets := &secretList{}
+	return &credentialStore{values: make(map[string]*credential), secrets: secrets, masker: newSecretMasker(output, secrets)}
+}
+
+// teeOutput duplicates all later engine output into w. w sits behind the
+// secret masker, so it never sees a secret the console does not.
+func (s *credentialStore) teeOutput(w io.Writer) {
+	s.masker.mu.Lock()
+	defer s.masker.mu.Unlock()
+	s.masker.w = io.MultiWriter(s.masker.w, w)
+}
+
+// observeOutput passes all later engine output to observer, after secrets
+// are masked
+func (s *credentialStore) observeOutput(observer OutputObserver) {
+	s.masker.mu.Lock()
+	defer s.masker.mu.Unlock()
+	s.masker.observers = append(s.masker.observers, observer)
+}
+
+// maskSecret masks secret in all later engine output
+func (s *credentialStore) maskSecret(secret string) {
+	s.secrets.add(secret)
+}
+
+// credentialFor returns the credential for host from the project's helpers,
+// or nil when no helper is declared for it
+func (e *Engine) credentialFor(host string, ctx *ExecutionContext) (*credential, error) {
+	host = strings.ToLower(host)
+	if host == "" || ctx.Project == nil {
+		return nil, nil
+	}
+	helper, ok := ctx.Project.CredentialHelpers[host]
+	if !ok {
+		return nil, nil
+	}
+
+	store := e.credentials
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if cred, ok := store.values[host]; ok {
+		return cred, nil
+	}
+
+	output, err := e.runCredentialHelper(helper, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("credential helper for %s failed: %w", host, err)
+	}
+	cred := parseCredential(output)
+	if cred.Secret == "" {
+		return nil, fmt.Errorf("credential helper for %s returned no credential", host)
+	}
+	store.secrets.add(cred.Secret)
+	store.values[host] = cred
+
+	if e.verbose {
+		e.iconf(ctx, "🔑 ", "Using credential helper for %s\n", host)
+	}
+	return cred, nil
+}
+
+// hasCredentialHelper reports whether the project declares a credential
+// helper for host, without running it
+func hasCredentialHelper(host string, ctx *ExecutionContext) bool {
+	if host == "" || ctx.Project == nil {
+		return false
+	}
+	_, ok := ctx.Project.CredentialHelpers[strings.ToLower(host)]
+	return ok
+}
+
+// runCredentialHelper returns what helper prints: the captured output of an
+// exec: command or the value of an env: variable
+func (e *Engine) runCredentialHelper(helper string, ctx *ExecutionContext) (string, error) {
+	kind, target, _ := strings.Cut(helper, ":")
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(target)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", target)
+		}
+		return value, nil
+	case "exec":
+		opts := e.getPlatformShellConfig(ctx)
+		opts.WorkingDir = ctx.WorkingDir
+		opts.CaptureOutput = true
+		opts.StreamOutput = false
+		result, err := shell.Execute(target, opts)
+		if err != nil {
+			return "", err
+		}
+		return result.Stdout, nil
+	default:
+		return "", fmt.Errorf("unknown credential helper %q", helper)
+	}
+}
+
+// parseCredential splits helper output into a token, or a user name and a
+// password when the helper printed two lines
+func parseCredential(output string) *credential {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(output, "\r\n", "\n")), "\n")
+	if len(lines) >= 2 {
+		return &credential{Username: strings.TrimSpace(lines[0]), Secret: strings.TrimSpace(lines[1])}
+	}
+	return &credential{Secret: strings.TrimSpace(lines[0])}
+}
+
+// urlHost returns the lower-cased host name of an http(s) URL, or "" when
+// rawURL is not one
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// imageRegistryHost returns the registry host of a docker image reference,
+// or "" for images on Docker Hub ("nginx", "acme/api")
+func imageRegistryHost(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if !found {
+		return ""
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return strings.ToLower(first)
+	}
+	return ""
+}
+
+// gitRemoteHost returns the host of an https git remote, given as a URL or
+// as the name of a configured remote; ssh remotes have no host here
+func gitRemoteHost(remote, workingDir string) string {
+	if !strings.Contains(remote, "://") {
+		cmd := exec.Command("git", "remote", "get-url", remote) // #nosec G204 -- remote name comes from the task file
+		cmd.Dir = workingDir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		remote = strings.TrimSpace(string(out))
+	}
+	return urlHost(remote)
+}
+
+// gitCredentialArgs returns git -c options that answer credential requests
+// with cred; the secret itself is read from DRUN_GIT_PASSWORD
+func gitCredentialArgs(cred *credential) []string {
+	return []string{
+		"-c", "credential.helper=",
+		"-c", fmt.Sprintf(`'credential.helper=!f() { echo "username=%s"; echo "password=$DRUN_GIT_PASSWORD"; }; f'`, cred.user()),
+	}
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
+}
+
+// secretList holds the secrets of a run. Every secretMasker of the engine
+// shares it, so a secret added once is masked everywhere.
+type secretList struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+func (l *secretList) add(secret string) {
+	if secret == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.secrets = append(l.secrets, secret)
+}
+
+func (l *secretList) empty() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.secrets) == 0
+}
+
+// mask replaces every secret in s with ***
+func (l *secretList) mask(s string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, secret := range l.secrets {
+		s = strings.ReplaceAll(s, secret, "***")
+	}
+	return s
+}
+
+// partialSuffix returns the length of the longest end of s that is the
+// start of a secret, which the next write may complete
+func (l *secretList) partialSuffix(s string) int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	longest := 0
+	for _, secret := range l.secrets {
+		for n := min(len(secret)-1, len(s)); n > longest; n-- {
+			if strings.HasSuffix(s, secret[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// secretMasker replaces the secrets of its list with *** in everything written
+// through it. Writes are serialized, so concurrent tasks can share one masker
+// in front of a writer that is not safe for concurrent use. Output that ends
+// with the start of a secret is held back until the next write of the same
+// task shows whether the secret follows, so a secret split across writes is
+// masked too; flush writes it out when no more output is coming.
+type secretMasker struct {
+	secrets *secretList
+
+	mu        sync.Mutex
+	w         io.Writer
+	observers []OutputObserver  // also receive the masked output, with the task that wrote it
+	pending   map[string]string // masked output held back because it may start a secret, by task
+}
+
+func newSecretMasker(w io.Writer, secrets *secretList) *secretMasker {
+	return &secretMasker{w: w, secrets: secrets, pending: make(map[string]string)}
+}
+
+// taskOutput is the masker as one task writes to it (see Engine.out)
+type taskOutput struct {
+	masker *secretMasker
+	task   string
+}
+
+func (w taskOutput) Write(p []byte) (int, error) {
+	return w.masker.write(w.task, p)
+}
+
+// Write writes output that belongs to no task
+func (m *secretMasker) Write(p []byte) (int, error) {
+	return m.write("", p)
+}
+
+func (m *secretMasker) write(task string, p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending[task] == "" && m.secrets.empty() {
+		n, err := m.w.Write(p)
+		m.observe(task, p[:n])
+		return n, err
+	}
+	data := m.secrets.mask(m.pending[task] + string(p))
+	keep := m.secrets.partialSuffix(data)
+	if keep > 0 {
+		m.pending[task] = data[len(data)-keep:]
+	} else {
+		delete(m.pending, task)
+	}
+	if _, err := io.WriteString(m.w, data[:len(data)-keep]); err != nil {
+		return 0, err
+	}
+	m.observe(task, []byte(data[:len(data)-keep]))
+	return len(p), nil
+}
+
+// observe passes masked output to the output observers
+func (m *secretMasker) observe(task string, p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	for _, observer := range m.observers {
+		observer.OnOutput(task, p)
+	}
+}
+
+// flush writes the output held back as the possible start of a secret
+func (m *secretMasker) flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for task, pending := range m.pending {
+		_, _ = io.WriteString(m.w, pending)
+		m.observe(task, []byte(pending))
+		delete(m.pending, task)
+	}
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/detection"
@@ -18,35 +19,87 @@ func (e *Engine) executeDetectOperation(detector *detection.Detector, stmt *stat
 		if stmt.Condition == "type" {
 			types := detector.DetectProjectType()
 			if e.dryRun {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would detect project types: %v\n", types)
+				_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would detect project types: %v\n", types)
 			} else {
-				_, _ = fmt.Fprintf(e.output, "🔍  Detected project types: %v\n", types)
+				e.iconf(ctx, "🔍  ", "Detected project types: %v\n", types)
 			}
+			managers := detector.DetectPackageManagers()
+			packageManager := ""
+			if len(managers) > 0 {
+				packageManager = managers[0]
+			}
+			setDetected(ctx, "project_type", strings.Join(types, ","))
+			setDetected(ctx, "package_manager", packageManager)
+			setDetected(ctx, "package_managers", strings.Join(managers, ","))
 		}
 	default:
 		// Detect tool
+		key := detectedToolKey(stmt.Target)
 		if stmt.Condition == "version" {
 			version := detector.GetToolVersion(stmt.Target)
 			if e.dryRun {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would detect %s version: %s\n", stmt.Target, version)
+				_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would detect %s version: %s\n", stmt.Target, version)
 			} else {
-				_, _ = fmt.Fprintf(e.output, "🔍  Detected %s version: %s\n", stmt.Target, version)
+				e.iconf(ctx, "🔍  ", "Detected %s version: %s\n", stmt.Target, version)
 			}
 			// Set the detected version in variables (e.g., docker_version)
-			ctx.Variables[stmt.Target+"_version"] = version
+			e.assignVariable(ctx, stmt.Target+"_version", version, "detect")
+			setDetected(ctx, key+".version", version)
 		} else {
 			available := detector.IsToolAvailable(stmt.Target)
 			if e.dryRun {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would check if %s is available: %t\n", stmt.Target, available)
+				_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would check if %s is available: %t\n", stmt.Target, available)
 			} else {
-				_, _ = fmt.Fprintf(e.output, "🔍  %s available: %t\n", stmt.Target, available)
+				e.iconf(ctx, "🔍  ", "%s available: %t\n", stmt.Target, available)
 			}
+			setDetected(ctx, key+".available", strconv.FormatBool(available))
 		}
+		setDetected(ctx, key+".path", detector.ToolPath(stmt.Target))
 	}
 
 	return nil
 }
 
+// setDetected stores a detection result in the results table, which is read
+// with {detected.<key>} (for example {detected.node.version})
+func setDetected(ctx *ExecutionContext, key, value string) {
+	ctx.Variables["detected."+key] = value
+}
+
+// detectedToolKey returns the results table key of a tool: lowercase, with
+// spaces replaced by dashes ("docker compose" -> "docker-compose")
+func detectedToolKey(tool string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tool)), " ", "-")
+}
+
+// executeDetectEnvironment captures what the project's environment: block
+// declares for an entry, or an empty string when it declares nothing for it
+func (e *Engine) executeDetectEnvironment(stmt *statement.Detection, ctx *ExecutionContext) error {
+	value := ""
+	found := false
+	if ctx.Program != nil && ctx.Program.Project != nil {
+		if env := ctx.Program.Project.Environment(); env != nil {
+			for _, entry := range env.Entries {
+				if entry.Key() == stmt.Target || entry.Name == stmt.Target {
+					value, found = entry.Value(), true
+					break
+				}
+			}
+		}
+	}
+
+	e.assignVariable(ctx, stmt.CaptureVar, value, "detect")
+	switch {
+	case e.dryRun:
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would detect declared environment %s: %q\n", stmt.Target, value)
+	case found:
+		e.iconf(ctx, "🔍  ", "Declared environment %s: %s\n", stmt.Target, value)
+	default:
+		e.iconf(ctx, "🔍  ", "Environment does not declare %s\n", stmt.Target)
+	}
+	return nil
+}
+
 // executeIfAvailable executes "if tool is available" and "if tool is not available" conditions
 func (e *Engine) executeIfAvailable(detector *detection.Detector, stmt *statement.Detection, ctx *ExecutionContext) error {
 	// Build list of all tools to check (primary + alternatives)
@@ -132,16 +185,16 @@ func (e *Engine) executeIfAvailable(detector *detection.Detector, stmt *statemen
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would check if %s: %t\n", conditionText, conditionMet)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would check if %s: %t\n", conditionText, conditionMet)
 		if conditionMet {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute if body\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute if body\n")
 			for _, bodyStmt := range stmt.Body {
 				if err := e.executeStatement(bodyStmt, ctx); err != nil {
 					return err
 				}
 			}
 		} else if len(stmt.ElseBody) > 0 {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute else body\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute else body\n")
 			for _, elseStmt := range stmt.ElseBody {
 				if err := e.executeStatement(elseStmt, ctx); err != nil {
 					return err
@@ -152,7 +205,7 @@ func (e *Engine) executeIfAvailable(detector *detection.Detector, stmt *statemen
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "🔍  Checking if %s: %t\n", conditionText, conditionMet)
+		e.iconf(ctx, "🔍  ", "Checking if %s: %t\n", conditionText, conditionMet)
 	}
 
 	if conditionMet {
@@ -180,17 +233,17 @@ func (e *Engine) executeIfVersion(detector *detection.Detector, stmt *statement.
 	matches := detector.CompareVersion(version, stmt.Condition, targetVersion)
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would check if %s version %s %s %s: %t (current: %s)\n",
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would check if %s version %s %s %s: %t (current: %s)\n",
 			stmt.Target, version, stmt.Condition, targetVersion, matches, version)
 		if matches {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute if-version body for %s\n", stmt.Target)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute if-version body for %s\n", stmt.Target)
 			for _, bodyStmt := range stmt.Body {
 				if err := e.executeStatement(bodyStmt, ctx); err != nil {
 					return err
 				}
 			}
 		} else if len(stmt.ElseBody) > 0 {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute else body for %s\n", stmt.Target)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute else body for %s\n", stmt.Target)
 			for _, elseStmt := range stmt.ElseBody {
 				if err := e.executeStatement(elseStmt, ctx); err != nil {
 					return err
@@ -201,7 +254,7 @@ func (e *Engine) executeIfVersion(detector *detection.Detector, stmt *statement.
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "🔍  Checking %s version %s %s %s: %t (current: %s)\n",
+		e.iconf(ctx, "🔍  ", "Checking %s version %s %s %s: %t (current: %s)\n",
 			stmt.Target, version, stmt.Condition, targetVersion, matches, version)
 	}
 
@@ -228,10 +281,10 @@ func (e *Engine) executeWhenEnvironment(detector *detection.Detector, stmt *stat
 	matches := currentEnv == stmt.Target
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would check if in %s environment: %t (current: %s)\n",
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would check if in %s environment: %t (current: %s)\n",
 			stmt.Target, matches, currentEnv)
 		if matches {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute when-environment body\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute when-environment body\n")
 			for _, bodyStmt := range stmt.Body {
 				if err := e.executeStatement(bodyStmt, ctx); err != nil {
 					return err
@@ -242,7 +295,7 @@ func (e *Engine) executeWhenEnvironment(detector *detection.Detector, stmt *stat
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "🔍  Checking if in %s environment: %t (current: %s)\n",
+		e.iconf(ctx, "🔍  ", "Checking if in %s environment: %t (current: %s)\n",
 			stmt.Target, matches, currentEnv)
 	}
 
@@ -276,42 +329,42 @@ func (e *Engine) executeDetectAvailable(detector *detection.Detector, stmt *stat
 	}
 
 	if e.dryRun {
-		_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would detect available tool from: %v\n", toolsToTry)
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would detect available tool from: %v\n", toolsToTry)
 		if found {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would find: %s\n", workingTool)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would find: %s\n", workingTool)
 			if stmt.CaptureVar != "" {
-				_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would capture as %s: %s\n", stmt.CaptureVar, workingTool)
+				_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture as %s: %s\n", stmt.CaptureVar, workingTool)
 				// Set the variable in dry-run mode too
-				ctx.Variables[stmt.CaptureVar] = workingTool
+				e.assignVariable(ctx, stmt.CaptureVar, workingTool, "detect")
 			}
 		} else {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would find: none available\n")
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would find: none available\n")
 			if stmt.CaptureVar != "" {
 				// Set a placeholder in dry-run mode when no tool is found
-				ctx.Variables[stmt.CaptureVar] = "[DRY RUN] no tool available"
+				e.assignVariable(ctx, stmt.CaptureVar, "[DRY RUN] no tool available", "detect")
 			}
 		}
 		return nil
 	}
 
 	if e.verbose {
-		_, _ = fmt.Fprintf(e.output, "🔍  Detecting available tool from: %v\n", toolsToTry)
+		e.iconf(ctx, "🔍  ", "Detecting available tool from: %v\n", toolsToTry)
 	}
 
 	if found {
 		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "✅  Found: %s\n", workingTool)
+			e.iconf(ctx, "✅  ", "Found: %s\n", workingTool)
 		}
 
 		// Capture the working tool variant in a variable if specified
 		if stmt.CaptureVar != "" {
-			ctx.Variables[stmt.CaptureVar] = workingTool
+			e.assignVariable(ctx, stmt.CaptureVar, workingTool, "detect")
 			if e.verbose {
-				_, _ = fmt.Fprintf(e.output, "📝  Captured as %s: %s\n", stmt.CaptureVar, workingTool)
+				e.iconf(ctx, "📝  ", "Captured as %s: %s\n", stmt.CaptureVar, workingTool)
 			}
 		}
 	} else {
-		_, _ = fmt.Fprintf(e.output, "❌  None of the tools are available: %v\n", toolsToTry)
+		e.iconf(ctx, "❌  ", "None of the tools are available: %v\n", toolsToTry)
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -18,7 +19,7 @@ import (
 // This file contains helper methods for file downloads and archive extraction
 
 // downloadFileWithProgress downloads a file using native Go HTTP client with progress tracking
-func (e *Engine) downloadFileWithProgress(url, filePath string, headers, auth, options map[string]string) error {
+func (e *Engine) downloadFileWithProgress(url, filePath string, headers, auth, options map[string]string, ctx *ExecutionContext) error {
 	// Create HTTP client with timeout
 	timeout := 30 * time.Second
 	if timeoutStr, exists := options["timeout"]; exists {
@@ -62,8 +63,8 @@ func (e *Engine) downloadFileWithProgress(url, filePath string, headers, auth, o
 		}
 	}
 
-	// Perform request
-	resp, err := client.Do(req)
+	// Perform request, retrying transient failures
+	resp, err := e.networkPolicy(ctx, "download of "+url).Do(client, req)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
@@ -105,7 +106,7 @@ func (e *Engine) downloadFileWithProgress(url, filePath string, headers, auth, o
 			// Update progress every 100ms to avoid overwhelming output
 			if time.Since(lastUpdate) > 100*time.Millisecond || written == contentLength {
 				lastUpdate = time.Now()
-				e.showDownloadProgress(written, contentLength, time.Since(startTime))
+				e.showDownloadProgress(written, contentLength, time.Since(startTime), ctx)
 			}
 		},
 	})
@@ -117,12 +118,12 @@ func (e *Engine) downloadFileWithProgress(url, filePath string, headers, auth, o
 	}
 
 	// Final progress update
-	_, _ = fmt.Fprintf(e.output, "\r\033[K") // Clear line
+	_, _ = fmt.Fprintf(e.out(ctx), "\r\033[K") // Clear line
 
 	// Calculate final stats
 	duration := time.Since(startTime)
 	speed := float64(downloaded) / duration.Seconds()
-	_, _ = fmt.Fprintf(e.output, "   📊  %s in %s (%.2f MB/s)\n",
+	_, _ = fmt.Fprintf(e.out(ctx), "   📊  %s in %s (%.2f MB/s)\n",
 		formatBytes(downloaded),
 		duration.Round(time.Millisecond),
 		speed/1024/1024)
@@ -131,10 +132,10 @@ func (e *Engine) downloadFileWithProgress(url, filePath string, headers, auth, o
 }
 
 // showDownloadProgress displays download progress with speed and ETA
-func (e *Engine) showDownloadProgress(downloaded, total int64, elapsed time.Duration) {
+func (e *Engine) showDownloadProgress(downloaded, total int64, elapsed time.Duration, ctx *ExecutionContext) {
 	if total <= 0 {
 		// Unknown size, just show downloaded amount
-		_, _ = fmt.Fprintf(e.output, "\r   📥  Downloaded: %s", formatBytes(downloaded))
+		_, _ = fmt.Fprintf(e.out(ctx), "\r   📥  Downloaded: %s", formatBytes(downloaded))
 		return
 	}
 
@@ -157,7 +158,7 @@ func (e *Engine) showDownloadProgress(downloaded, total int64, elapsed time.Dura
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 
 	// Format output
-	_, _ = fmt.Fprintf(e.output, "\r   📥  [%s] %.1f%% | %s/%s | %.2f MB/s | ETA: %s",
+	_, _ = fmt.Fprintf(e.out(ctx), "\r   📥  [%s] %.1f%% | %s/%s | %.2f MB/s | ETA: %s",
 		bar,
 		percent,
 		formatBytes(downloaded),
@@ -167,7 +168,7 @@ func (e *Engine) showDownloadProgress(downloaded, total int64, elapsed time.Dura
 }
 
 // applyFilePermissions applies Unix file permissions based on permission specs
-func (e *Engine) applyFilePermissions(path string, permSpecs []ast.PermissionSpec) error {
+func (e *Engine) applyFilePermissions(path string, permSpecs []ast.PermissionSpec, ctx *ExecutionContext) error {
 	// Get current file info
 	info, err := os.Stat(path)
 	if err != nil {
@@ -175,64 +176,71 @@ func (e *Engine) applyFilePermissions(path string, permSpecs []ast.PermissionSpe
 	}
 
 	currentMode := info.Mode()
-	newMode := currentMode
+	newMode := currentMode | permissionBits(permSpecs)
 
-	// Build permission map
+	// Apply new permissions
+	if newMode != currentMode {
+		err = os.Chmod(path, newMode)
+		if err != nil {
+			return fmt.Errorf("failed to chmod: %w", err)
+		}
+		_, _ = fmt.Fprintf(e.out(ctx), "   🔒 Set permissions: %s\n", newMode.String())
+	}
+
+	return nil
+}
+
+// permissionBits returns the Unix file mode bits granted by permission specs
+func permissionBits(permSpecs []ast.PermissionSpec) os.FileMode {
+	var bits os.FileMode
 	for _, spec := range permSpecs {
 		for _, perm := range spec.Permissions {
 			for _, target := range spec.Targets {
 				// Map permission and target to Unix file mode bits
-				var permBits os.FileMode
 				switch perm {
 				case "read":
 					switch target {
 					case "user":
-						permBits = 0400
+						bits |= 0400
 					case "group":
-						permBits = 0040
+						bits |= 0040
 					case "others":
-						permBits = 0004
+						bits |= 0004
 					}
 				case "write":
 					switch target {
 					case "user":
-						permBits = 0200
+						bits |= 0200
 					case "group":
-						permBits = 0020
+						bits |= 0020
 					case "others":
-						permBits = 0002
+						bits |= 0002
 					}
 				case "execute":
 					switch target {
 					case "user":
-						permBits = 0100
+						bits |= 0100
 					case "group":
-						permBits = 0010
+						bits |= 0010
 					case "others":
-						permBits = 0001
+						bits |= 0001
 					}
 				}
-
-				// Add permission bits
-				newMode |= permBits
 			}
 		}
 	}
+	return bits
+}
 
-	// Apply new permissions
-	if newMode != currentMode {
-		err = os.Chmod(path, newMode)
-		if err != nil {
-			return fmt.Errorf("failed to chmod: %w", err)
-		}
-		_, _ = fmt.Fprintf(e.output, "   🔒 Set permissions: %s\n", newMode.String())
-	}
-
-	return nil
+// extractOptions select and adjust the entries of an extracted archive
+type extractOptions struct {
+	strip       int                  // leading directories dropped from every path
+	only        []string             // extract only paths matching one of these patterns
+	permissions []ast.PermissionSpec // permissions added to every extracted file
 }
 
 // extractArchive extracts an archive file to the specified directory using the archives library
-func (e *Engine) extractArchive(archivePath, extractTo string) error {
+func (e *Engine) extractArchive(archivePath, extractTo string, opts extractOptions) error {
 	// Create extract directory if it doesn't exist
 	err := os.MkdirAll(extractTo, 0750)
 	if err != nil {
@@ -258,15 +266,26 @@ func (e *Engine) extractArchive(archivePath, extractTo string) error {
 	if !ok {
 		// If it's just compressed (not archived), try to decompress it
 		if decompressor, ok := format.(archives.Decompressor); ok {
+			if opts.strip > 0 || len(opts.only) > 0 {
+				return fmt.Errorf("'strip' and 'only' need an archive, but %s is a single compressed file", archivePath)
+			}
 			return e.decompressFile(decompressor, archiveReader, archivePath, extractTo)
 		}
 		return fmt.Errorf("format does not support extraction: %s", archivePath)
 	}
 
+	extraBits := permissionBits(opts.permissions)
+	extracted := 0
+
 	// Extract the archive
 	handler := func(ctx context.Context, f archives.FileInfo) error {
+		name, keep := extractedName(f.NameInArchive, opts.strip)
+		if !keep || !matchesExtractFilters(name, f.IsDir(), opts.only) {
+			return nil
+		}
+
 		// Construct the output path
-		outputPath := filepath.Join(extractTo, f.NameInArchive)
+		outputPath := filepath.Join(extractTo, filepath.FromSlash(name))
 
 		// Handle directories
 		if f.IsDir() {
@@ -298,6 +317,12 @@ func (e *Engine) extractArchive(archivePath, extractTo string) error {
 			return fmt.Errorf("failed to extract file: %w", err)
 		}
 
+		if extraBits != 0 {
+			if err := os.Chmod(outputPath, f.Mode().Perm()|extraBits); err != nil {
+				return fmt.Errorf("failed to chmod: %w", err)
+			}
+		}
+		extracted++
 		return nil
 	}
 
@@ -306,10 +331,50 @@ func (e *Engine) extractArchive(archivePath, extractTo string) error {
 	if err != nil {
 		return fmt.Errorf("extraction failed: %w", err)
 	}
+	if extracted == 0 && len(opts.only) > 0 {
+		return fmt.Errorf("no files in the archive match %s", strings.Join(opts.only, ", "))
+	}
 
 	return nil
 }
 
+// extractedName returns the slash-separated path an archive entry is
+// extracted to after dropping strip leading directories. The path is
+// cleaned, so ".." cannot leave the destination; entries that are stripped
+// away entirely are not kept.
+func extractedName(nameInArchive string, strip int) (string, bool) {
+	name := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(nameInArchive)), "/")
+	if name == "" {
+		return "", false
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) <= strip {
+		return "", false
+	}
+	return strings.Join(parts[strip:], "/"), true
+}
+
+// matchesExtractFilters reports whether an entry passes the only patterns. A
+// pattern matches a path or any of its parent directories, so "bin" keeps
+// everything below bin/; directories are kept when a pattern may match
+// something below them.
+func matchesExtractFilters(name string, isDir bool, only []string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, pattern := range only {
+		for candidate := name; candidate != "."; candidate = path.Dir(candidate) {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+		if isDir && strings.HasPrefix(pattern, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // decompressFile decompresses a single compressed file (not an archive)
 func (e *Engine) decompressFile(decompressor archives.Decompressor, reader io.Reader, archivePath, extractTo string) error {
 	// Open decompression reader
@@ -10,6 +10,10 @@ import (
 // Domain: Filesystem Helpers
 // This file contains helper methods for filesystem operations
 
+// resolveFilesystemPath makes a path used by a file statement absolute.
+// Relative paths resolve against the use workdir directory when one is set,
+// then against the project root (the drun file's directory), or against the
+// directory drun was started from when the project sets relative_paths to cwd.
 func (e *Engine) resolveFilesystemPath(path string, ctx *ExecutionContext) string {
 	if path == "" {
 		return path
@@ -20,9 +24,12 @@ func (e *Engine) resolveFilesystemPath(path string, ctx *ExecutionContext) strin
 
 	base := ""
 	if ctx != nil {
-		if ctx.WorkingDir != "" {
+		switch {
+		case ctx.WorkingDir != "":
 			base = ctx.WorkingDir
-		} else if ctx.OriginalWorkingDir != "" {
+		case ctx.CurrentFile != "" && !relativePathsFromCwd(ctx):
+			base = projectRootDir(absPath(ctx.CurrentFile))
+		case ctx.OriginalWorkingDir != "":
 			base = ctx.OriginalWorkingDir
 		}
 	}
@@ -37,6 +44,23 @@ func (e *Engine) resolveFilesystemPath(path string, ctx *ExecutionContext) strin
 	return filepath.Clean(filepath.Join(base, path))
 }
 
+// relativePathsFromCwd reports whether the project opted into resolving
+// relative file paths against the directory drun was started from
+func relativePathsFromCwd(ctx *ExecutionContext) bool {
+	if ctx.Project == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(ctx.Project.Settings["relative_paths"]), "cwd")
+}
+
+// absPath returns path as an absolute path, or unchanged when that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 // fileExists checks if a file exists
 func (e *Engine) fileExists(path string, ctx *ExecutionContext) bool {
 	info, err := os.Stat(e.resolveFilesystemPath(path, ctx))
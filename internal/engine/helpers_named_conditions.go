@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Domain: Named Conditions
+// This file resolves conditions declared once in the project block with
+// `condition "is prod" means environment is "production"` and referenced by
+// name, as in `when is prod:`. A name can be negated with not and combined
+// with and and or, and a condition can refer to other named conditions.
+
+// lookupNamedCondition returns the name of the declared condition condition
+// refers to and whether it is negated with not
+func lookupNamedCondition(conditions map[string]string, condition string) (name string, negated, ok bool) {
+	name = strings.Join(strings.Fields(condition), " ")
+	if _, ok := conditions[name]; ok {
+		return name, false, true
+	}
+	if rest, found := strings.CutPrefix(name, "not "); found {
+		if _, ok := conditions[rest]; ok {
+			return rest, true, true
+		}
+	}
+	return "", false, false
+}
+
+// resolveNamedCondition evaluates condition when it is, or combines with and
+// and or, a named condition. Each side of and and or is resolved on its own,
+// so a named condition keeps its meaning next to other clauses.
+func (e *Engine) resolveNamedCondition(condition string, ctx *ExecutionContext) (result, handled bool, err error) {
+	if ctx == nil || ctx.Project == nil || len(ctx.Project.Conditions) == 0 {
+		return false, false, nil
+	}
+	conditions := ctx.Project.Conditions
+	if name, negated, ok := lookupNamedCondition(conditions, condition); ok {
+		expression := conditions[name]
+		if err := e.checkConditionVariables(expression, ctx); err != nil {
+			return false, true, err
+		}
+		holds, err := e.resolveCondition(expression, ctx)
+		return holds != negated, true, err
+	}
+
+	left, operator, right, ok := splitLogicalCondition(condition)
+	if !ok || !mentionsNamedCondition(conditions, condition) {
+		return false, false, nil
+	}
+	holds, err := e.resolveCondition(left, ctx)
+	if err != nil || holds == (operator == "or") {
+		return holds, true, err
+	}
+	holds, err = e.resolveCondition(right, ctx)
+	return holds, true, err
+}
+
+// mentionsNamedCondition reports whether a clause of condition, split at and
+// and or, is a named condition
+func mentionsNamedCondition(conditions map[string]string, condition string) bool {
+	if _, _, ok := lookupNamedCondition(conditions, condition); ok {
+		return true
+	}
+	left, _, right, ok := splitLogicalCondition(condition)
+	return ok && (mentionsNamedCondition(conditions, left) || mentionsNamedCondition(conditions, right))
+}
+
+// checkNamedConditionCycles fails when a named condition refers back to
+// itself, directly or through others
+func checkNamedConditionCycles(conditions map[string]string) error {
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		for i, seen := range path {
+			if seen == name {
+				return fmt.Errorf("condition %q refers to itself: %s", name, strings.Join(append(path[i:], name), " -> "))
+			}
+		}
+		path = append(path, name)
+		for _, clause := range conditionClauses(conditions[name]) {
+			if referenced, _, ok := lookupNamedCondition(conditions, clause); ok {
+				if err := visit(referenced, path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// conditionClauses splits a condition at and and or into its clauses
+func conditionClauses(condition string) []string {
+	left, _, right, ok := splitLogicalCondition(condition)
+	if !ok {
+		return []string{condition}
+	}
+	return append(conditionClauses(left), conditionClauses(right)...)
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/parallel"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+)
+
+// Domain: Rerun
+// This file records the items of parallel loops that failed, so
+// `xdrun cmd:rerun --last-failed` can replay only those items, and restricts
+// the loops of a replayed run to them.
+
+// loopItems collects, for every parallel loop that ran in an execution, the
+// items that failed (none when the loop passed). Parallel targets share it,
+// so it is safe for concurrent use.
+type loopItems struct {
+	mu    sync.Mutex
+	loops map[string][]string
+}
+
+// add records the failed items of a loop run; a loop that runs several
+// times accumulates its failures
+func (l *loopItems) add(key string, failed []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loops == nil {
+		l.loops = make(map[string][]string)
+	}
+	items := append([]string{}, l.loops[key]...)
+	for _, item := range failed {
+		if !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	l.loops[key] = items
+}
+
+// snapshot returns the loops recorded so far and how many items failed
+func (l *loopItems) snapshot() (map[string][]string, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	loops := make(map[string][]string, len(l.loops))
+	failed := 0
+	for key, items := range l.loops {
+		loops[key] = append([]string{}, items...)
+		failed += len(items)
+	}
+	return loops, failed
+}
+
+// loopKey identifies a loop across runs by its task and loop variable
+func loopKey(ctx *ExecutionContext, stmt *statement.Loop) string {
+	return ctx.CurrentTask + "/" + strings.TrimPrefix(stmt.Variable, "$")
+}
+
+// replayLoopItems returns the items a parallel loop runs. When replaying a
+// run, a loop that ran then only runs the items that failed in it, keeping
+// their order; a loop that did not run runs every item.
+func (e *Engine) replayLoopItems(stmt *statement.Loop, items []string, ctx *ExecutionContext) []string {
+	if e.loopReplay == nil {
+		return items
+	}
+	failed, ran := e.loopReplay[loopKey(ctx, stmt)]
+	if !ran {
+		return items
+	}
+	var replayed []string
+	for _, item := range items {
+		if slices.Contains(failed, item) {
+			replayed = append(replayed, item)
+		}
+	}
+	e.iconf(ctx, "🔁  ", "Replaying %d of %d items of the loop over %s that failed in the last run\n", len(replayed), len(items), strings.TrimPrefix(stmt.Variable, "$"))
+	return replayed
+}
+
+// failedLoopItems returns the items of a parallel loop that failed or, when
+// the loop failed fast, never ran
+func failedLoopItems(items []string, results []parallel.ExecutionResult) []string {
+	var failed []string
+	for i, item := range items {
+		if i >= len(results) || results[i].Error != nil || results[i].Item != item {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// recordFailedLoopItems keeps the failed items of the run's parallel loops
+// as the project's last failed run, and tells how to replay them. A replay
+// whose loops all passed clears the record. Dry runs are not recorded, and
+// failing to record does not fail the run.
+func (e *Engine) recordFailedLoopItems(ctx *ExecutionContext, targets []TaskTarget, runErr error) {
+	if e.dryRun || ctx.LoopItems == nil {
+		return
+	}
+	loops, failed := ctx.LoopItems.snapshot()
+	for key, items := range e.loopReplay {
+		if _, ran := loops[key]; !ran { // not reached in this replay
+			loops[key] = items
+			failed += len(items)
+		}
+	}
+	if failed == 0 && (runErr != nil || e.loopReplay == nil) {
+		return
+	}
+
+	store, err := e.runHistoryStore()
+	if err == nil {
+		project := projectRootDir(ctx.CurrentFile)
+		if failed == 0 {
+			err = store.ClearFailed(project)
+		} else {
+			run := runhistory.FailedRun{
+				Project:  project,
+				Run:      ctx.Run.id,
+				Loops:    loops,
+				FailedAt: time.Now(),
+			}
+			for _, target := range targets {
+				run.Targets = append(run.Targets, runhistory.Target{Task: target.Name, Params: target.Params})
+			}
+			err = store.RecordFailed(run)
+		}
+	}
+	if err != nil {
+		e.iconf(ctx, "⚠️  ", "Could not record the failed loop items: %v\n", err)
+		return
+	}
+	if failed > 0 {
+		format := "%d loop items failed; replay only those with: xdrun cmd:rerun --last-failed\n"
+		if failed == 1 {
+			format = "%d loop item failed; replay only those with: xdrun cmd:rerun --last-failed\n"
+		}
+		e.iconf(ctx, "🔁  ", format, failed)
+	}
+}
+
+// LastFailedRun returns the latest run of the project of currentFile whose
+// parallel loops had failed items
+func (e *Engine) LastFailedRun(currentFile string) (runhistory.FailedRun, bool, error) {
+	store, err := e.runHistoryStore()
+	if err != nil {
+		return runhistory.FailedRun{}, false, err
+	}
+	return store.LastFailed(projectRootDir(currentFile))
+}
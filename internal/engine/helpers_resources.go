@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+)
+
+// Domain: Resource Scheduling
+// This file contains the pool of cpus and memory that tasks declaring
+// `needs 2 cpus and 4GB memory` reserve while they run, so concurrent
+// targets and parallel loop items never need more than the machine has.
+
+// resourceNeeds is an amount of cpus, in thousandths, and memory, in bytes
+type resourceNeeds struct {
+	milliCPUs int64
+	memory    int64
+}
+
+// newResourceNeeds converts declared needs to an amount
+func newResourceNeeds(cpus float64, memory int64) resourceNeeds {
+	return resourceNeeds{milliCPUs: int64(cpus * 1000), memory: memory}
+}
+
+func (n resourceNeeds) isZero() bool {
+	return n.milliCPUs <= 0 && n.memory <= 0
+}
+
+func (n resourceNeeds) String() string {
+	return strings.TrimPrefix(ast.NeedsString(float64(n.milliCPUs)/1000, n.memory), "needs ")
+}
+
+// resourcePool hands out the cpus and memory of the machine, or of the
+// limits set with --max-cpu and --max-memory. A limit of zero (memory that
+// could not be detected) is not enforced.
+type resourcePool struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	capacity resourceNeeds
+	used     resourceNeeds
+}
+
+func newResourcePool(capacity resourceNeeds) *resourcePool {
+	pool := &resourcePool{capacity: capacity}
+	pool.cond = sync.NewCond(&pool.mu)
+	return pool
+}
+
+// clamp reduces needs larger than the pool to the whole pool, so a task
+// that asks for more than the machine has runs alone instead of never
+func (p *resourcePool) clamp(n resourceNeeds) resourceNeeds {
+	if p.capacity.milliCPUs > 0 && n.milliCPUs > p.capacity.milliCPUs {
+		n.milliCPUs = p.capacity.milliCPUs
+	}
+	if p.capacity.memory > 0 && n.memory > p.capacity.memory {
+		n.memory = p.capacity.memory
+	}
+	return n
+}
+
+// available reports whether n fits next to what is in use. Callers hold mu.
+func (p *resourcePool) available(n resourceNeeds) bool {
+	if p.capacity.milliCPUs > 0 && p.used.milliCPUs+n.milliCPUs > p.capacity.milliCPUs {
+		return false
+	}
+	if p.capacity.memory > 0 && p.used.memory+n.memory > p.capacity.memory {
+		return false
+	}
+	return true
+}
+
+// tryAcquire reserves n if it is free right away
+func (p *resourcePool) tryAcquire(n resourceNeeds) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.available(n) {
+		return false
+	}
+	p.used.milliCPUs += n.milliCPUs
+	p.used.memory += n.memory
+	return true
+}
+
+// acquire reserves n, waiting until enough is released. n must be clamped.
+func (p *resourcePool) acquire(n resourceNeeds) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for !p.available(n) {
+		p.cond.Wait()
+	}
+	p.used.milliCPUs += n.milliCPUs
+	p.used.memory += n.memory
+}
+
+func (p *resourcePool) release(n resourceNeeds) {
+	p.mu.Lock()
+	p.used.milliCPUs -= n.milliCPUs
+	p.used.memory -= n.memory
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+// fits returns how many reservations of n, which is clamped, the whole pool
+// holds; 0 when nothing limits them
+func (p *resourcePool) fits(n resourceNeeds) int {
+	count := 0
+	if p.capacity.milliCPUs > 0 && n.milliCPUs > 0 {
+		count = int(p.capacity.milliCPUs / n.milliCPUs)
+	}
+	if p.capacity.memory > 0 && n.memory > 0 {
+		if byMemory := int(p.capacity.memory / n.memory); count == 0 || byMemory < count {
+			count = byMemory
+		}
+	}
+	return count
+}
+
+// resources returns the engine's pool, sized on first use from the limits
+// given to the engine or detected from the machine
+func (e *Engine) resources() *resourcePool {
+	e.resourcesOnce.Do(func() {
+		capacity := newResourceNeeds(e.maxCPU, e.maxMemory)
+		if capacity.milliCPUs <= 0 {
+			capacity.milliCPUs = int64(runtime.NumCPU()) * 1000
+		}
+		if capacity.memory <= 0 {
+			capacity.memory = totalMemory()
+		}
+		e.resourcePool = newResourcePool(capacity)
+	})
+	return e.resourcePool
+}
+
+// reserveTaskResources reserves what a task needs before it runs and returns
+// the function releasing it. A task running within a reservation, called by
+// or run in a loop of a task that holds one, reserves nothing more.
+func (e *Engine) reserveTaskResources(taskName string, needs resourceNeeds, ctx *ExecutionContext) func() {
+	if needs.isZero() || ctx.Resources != nil {
+		return func() {}
+	}
+	pool := e.resources()
+	needs = pool.clamp(needs)
+	ctx.Resources = &needs
+	if e.dryRun {
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would reserve %s for task '%s'\n", needs, taskName)
+		return func() { ctx.Resources = nil }
+	}
+
+	if !pool.tryAcquire(needs) {
+		e.iconf(ctx, "⏳  ", "Task '%s' is waiting for %s\n", taskName, needs)
+		pool.acquire(needs)
+	}
+	return func() {
+		ctx.Resources = nil
+		pool.release(needs)
+	}
+}
+
+// totalMemory returns the machine's memory in bytes, or 0 when it cannot be
+// detected
+func totalMemory() int64 {
+	switch runtime.GOOS {
+	case "linux":
+		file, err := os.Open("/proc/meminfo")
+		if err != nil {
+			return 0
+		}
+		defer func() { _ = file.Close() }()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "MemTotal:" {
+				kb, err := strconv.ParseInt(fields[1], 10, 64)
+				if err != nil {
+					return 0
+				}
+				return kb * 1024
+			}
+		}
+	case "darwin":
+		output, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0
+		}
+		bytes, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return bytes
+	}
+	return 0
+}
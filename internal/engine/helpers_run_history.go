@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/engine/planner"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+	"github.com/phillarmonic/drun/v2/internal/types"
+)
+
+// Domain: Run History
+// This file contains the helpers that skip `once per commit` tasks which
+// already succeeded for the current commit and parameters, record the runs
+// that succeed, and explain the inputs a run is looked up with.
+
+// checkRunHistory returns the run to record if the task succeeds, and whether
+// the task already succeeded for the current commit and should be skipped.
+// Tasks that are not `once per commit`, or that run outside a git checkout,
+// are neither skipped nor recorded.
+func (e *Engine) checkRunHistory(taskPlan *planner.TaskPlan, taskName string, ctx *ExecutionContext) (*runhistory.Record, bool) {
+	if !taskPlan.Once {
+		return nil, false
+	}
+
+	run, err := runHistoryRecord(taskPlan, taskName, ctx)
+	if err != nil {
+		if e.verbose {
+			e.iconf(ctx, "⚠️  ", "Task '%s' is once per commit, but no git commit was found; running it\n", taskName)
+		}
+		return nil, false
+	}
+
+	if e.force {
+		return run, false
+	}
+
+	store, err := e.runHistoryStore()
+	if err == nil {
+		var found bool
+		if _, found, err = store.Lookup(*run); err == nil && found {
+			e.iconf(ctx, "⏭️  ", "Skipping task '%s' (already succeeded for commit %s; use --force to run it)\n", taskName, shortCommit(run.Commit))
+			return nil, true
+		}
+	}
+	if err != nil {
+		e.iconf(ctx, "⚠️  ", "Could not read the run history: %v\n", err)
+	}
+	return run, false
+}
+
+// runHistoryRecord returns the run of a task with the current commit and
+// parameter values; it fails outside a git checkout
+func runHistoryRecord(taskPlan *planner.TaskPlan, taskName string, ctx *ExecutionContext) (*runhistory.Record, error) {
+	projectDir := projectRootDir(ctx.CurrentFile)
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = projectDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("no git commit found in %s: %w", projectDir, err)
+	}
+
+	run := &runhistory.Record{
+		Project: projectDir,
+		Task:    taskName,
+		Commit:  strings.TrimSpace(string(output)),
+		Params:  make(map[string]string, len(taskPlan.Parameters)),
+	}
+	for _, param := range taskPlan.Parameters {
+		if value, ok := ctx.Parameters[param.Name]; ok && value != nil {
+			run.Params[param.Name] = value.AsString()
+		}
+	}
+	return run, nil
+}
+
+// RunHistoryExplanation describes how a `once per commit` task is looked up
+// in the run history
+type RunHistoryExplanation struct {
+	Current  runhistory.Record  // the run the task would record now
+	Cached   bool               // a run with the same inputs already succeeded
+	Previous *runhistory.Record // the latest recorded run of the task, if any
+}
+
+// ExplainRunHistory computes the inputs a `once per commit` task would be
+// looked up with, without running it, and finds its latest recorded run so
+// the inputs that changed since can be reported
+func (e *Engine) ExplainRunHistory(program *ast.Program, taskName string, params map[string]string, currentFile string) (*RunHistoryExplanation, error) {
+	if program == nil {
+		return nil, fmt.Errorf("program is nil")
+	}
+
+	e.planMu.Lock()
+	projectCtx, plans, _, err := e.planTargets(program, []TaskTarget{{Name: taskName, Params: params}}, currentFile)
+	e.planMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	plan := plans[0]
+	taskPlan, err := plan.GetTask(plan.TargetTask)
+	if err != nil {
+		return nil, err
+	}
+	if !taskPlan.Once {
+		return nil, fmt.Errorf("task '%s' is not declared once per commit, so its runs are not cached", taskName)
+	}
+
+	ctx := &ExecutionContext{
+		Parameters:  make(map[string]*types.Value),
+		Variables:   make(map[string]string),
+		Project:     projectCtx,
+		CurrentFile: currentFile,
+		Program:     program,
+		Run:         newRunInfo(),
+	}
+	if err := e.setupTaskParametersFromPlan(taskPlan, params, ctx); err != nil {
+		return nil, err
+	}
+	run, err := runHistoryRecord(taskPlan, plan.TargetTask, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := e.runHistoryStore()
+	if err != nil {
+		return nil, err
+	}
+	explanation := &RunHistoryExplanation{Current: *run}
+	if _, explanation.Cached, err = store.Lookup(*run); err != nil {
+		return nil, err
+	}
+	previous, found, err := store.Latest(run.Project, run.Task)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		explanation.Previous = &previous
+	}
+	return explanation, nil
+}
+
+// recordRunHistory records a successful run of a `once per commit` task.
+// Dry runs are not recorded, and failing to record does not fail the task.
+func (e *Engine) recordRunHistory(run runhistory.Record, ctx *ExecutionContext) {
+	if e.dryRun {
+		return
+	}
+
+	run.FinishedAt = time.Now()
+	store, err := e.runHistoryStore()
+	if err == nil {
+		err = store.Add(run)
+	}
+	if err != nil {
+		e.iconf(ctx, "⚠️  ", "Could not record the run of task '%s': %v\n", run.Task, err)
+	}
+}
+
+// runHistoryStore returns the configured run history, or the user's default
+func (e *Engine) runHistoryStore() (*runhistory.Store, error) {
+	if e.runHistory != nil {
+		return e.runHistory, nil
+	}
+	path, err := runhistory.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return runhistory.NewStore(path), nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/shell"
@@ -14,7 +15,9 @@ import (
 // Domain: Utility Helpers
 // This file contains miscellaneous utility helper methods
 
-// parseArrayLiteralString parses an array literal string like ["item1", "item2", "item3"] into a slice of strings
+// parseArrayLiteralString parses an array literal string like ["item1", "item2", "item3"] into a slice of strings.
+// Quoted items are kept exactly, including surrounding spaces and empty
+// strings; unquoted items are trimmed and skipped when empty.
 func (e *Engine) parseArrayLiteralString(arrayStr string) []string {
 	// Remove brackets
 	arrayStr = strings.TrimSpace(arrayStr)
@@ -33,8 +36,19 @@ func (e *Engine) parseArrayLiteralString(arrayStr string) []string {
 	var items []string
 	var current strings.Builder
 	inQuotes := false
+	quoted := false // the current item started with a quote
 	escaped := false
 
+	endItem := func() {
+		if quoted {
+			items = append(items, current.String())
+		} else if item := strings.TrimSpace(current.String()); item != "" {
+			items = append(items, item)
+		}
+		current.Reset()
+		quoted = false
+	}
+
 	for _, char := range content {
 		switch char {
 		case '\\':
@@ -44,33 +58,34 @@ func (e *Engine) parseArrayLiteralString(arrayStr string) []string {
 			}
 			current.WriteRune(char)
 		case '"':
-			if !escaped {
-				inQuotes = !inQuotes
-			} else {
+			if escaped {
 				current.WriteRune(char)
+				break
+			}
+			if !inQuotes && !quoted && strings.TrimSpace(current.String()) == "" {
+				// Drop the spaces before the opening quote
+				current.Reset()
+				quoted = true
 			}
+			inQuotes = !inQuotes
 		case ',':
 			if !inQuotes && !escaped {
-				// End of current item
-				item := strings.TrimSpace(current.String())
-				if item != "" {
-					items = append(items, item)
-				}
-				current.Reset()
+				endItem()
 			} else {
 				current.WriteRune(char)
 			}
 		default:
+			if quoted && !inQuotes && unicode.IsSpace(char) {
+				// Spaces after the closing quote are not part of the item
+				break
+			}
 			current.WriteRune(char)
 		}
 		escaped = false
 	}
 
 	// Add the last item
-	item := strings.TrimSpace(current.String())
-	if item != "" {
-		items = append(items, item)
-	}
+	endItem()
 
 	return items
 }
@@ -107,19 +122,7 @@ func (e *Engine) resolveServiceContext(rawName string, isLiteral bool, ctx *Exec
 
 	servicePath := targetServicePath
 	if !filepath.IsAbs(servicePath) {
-		baseDir := ""
-		if ctx.CurrentFile != "" {
-			baseDir = filepath.Dir(ctx.CurrentFile)
-			if filepath.Base(baseDir) == ".drun" {
-				baseDir = filepath.Dir(baseDir)
-			}
-		}
-		if baseDir == "" {
-			if cwd, err := os.Getwd(); err == nil {
-				baseDir = cwd
-			}
-		}
-		if baseDir != "" {
+		if baseDir := projectRootDir(ctx.CurrentFile); baseDir != "" {
 			servicePath = filepath.Join(baseDir, servicePath)
 		}
 	}
@@ -135,6 +138,23 @@ func (e *Engine) resolveServiceContext(rawName string, isLiteral bool, ctx *Exec
 	}, nil
 }
 
+// projectRootDir returns the directory project-relative paths resolve from:
+// the directory of the drun file (its parent when the file lives in .drun/),
+// falling back to the current working directory.
+func projectRootDir(currentFile string) string {
+	if currentFile != "" {
+		baseDir := filepath.Dir(currentFile)
+		if filepath.Base(baseDir) == ".drun" {
+			baseDir = filepath.Dir(baseDir)
+		}
+		return baseDir
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		return cwd
+	}
+	return ""
+}
+
 func (e *Engine) resolveServiceNameValue(rawName string, isLiteral bool, ctx *ExecutionContext) (string, error) {
 	if ctx == nil {
 		return "", fmt.Errorf("service-scoped commands require execution context")
@@ -198,21 +218,46 @@ func (e *Engine) resolveDynamicServiceName(identifier string, ctx *ExecutionCont
 // executeSingleLineShell executes a single-line shell command
 func (e *Engine) executeSingleLineShell(shellStmt *statement.Shell, ctx *ExecutionContext, svcCtx *serviceContextInfo) error {
 	// Interpolate variables in the command
-	interpolatedCommand, err := e.interpolateVariablesWithError(shellStmt.Command, ctx)
+	interpolatedCommand, err := e.interpolateShellCommand(shellStmt.Command, ctx)
 	if err != nil {
 		return fmt.Errorf("in shell command: %w", err)
 	}
 
+	// Piped runs stream the output of each command into the next
+	pipeline := []string{interpolatedCommand}
+	for _, command := range shellStmt.PipeInto {
+		interpolated, err := e.interpolateShellCommand(command, ctx)
+		if err != nil {
+			return fmt.Errorf("in shell command: %w", err)
+		}
+		pipeline = append(pipeline, interpolated)
+	}
+	interpolatedCommand = strings.Join(pipeline, " | ")
+	keepLine, err := e.lineFilter(shellStmt.LineFilters, ctx)
+	if err != nil {
+		return err
+	}
+
 	if e.dryRun {
+		e.writeContainerDryRun(ctx)
 		if svcCtx != nil {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute shell command in service '%s' (%s): %s\n", svcCtx.Name, svcCtx.Path, interpolatedCommand)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute shell command in service '%s' (%s): %s\n", svcCtx.Name, svcCtx.Path, interpolatedCommand)
 		} else {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would execute shell command: %s\n", interpolatedCommand)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would execute shell command: %s\n", interpolatedCommand)
 		}
+		if err := e.checkDryRunSyntax(shellStmt, interpolatedCommand, ctx); err != nil {
+			return err
+		}
+		e.writeEscalationDryRun(shellStmt, interpolatedCommand, ctx)
+		e.writeShellLogDryRun(shellStmt, ctx)
+		e.writeLineFiltersDryRun(shellStmt.LineFilters, ctx)
 		if shellStmt.CaptureVar != "" {
-			_, _ = fmt.Fprintf(e.output, "[DRY RUN] Would capture output as: %s\n", shellStmt.CaptureVar)
+			_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would capture output as: %s\n", shellStmt.CaptureVar)
 			// Set a placeholder value for the captured variable in dry-run mode
-			ctx.Variables[shellStmt.CaptureVar] = "[DRY RUN] command output"
+			e.assignVariable(ctx, shellStmt.CaptureVar, "[DRY RUN] command output", "capture")
+		}
+		if shellStmt.ExitStateVar != "" {
+			e.assignVariable(ctx, shellStmt.ExitStateVar, "", "exit code mapping")
 		}
 		return nil
 	}
@@ -220,69 +265,98 @@ func (e *Engine) executeSingleLineShell(shellStmt *statement.Shell, ctx *Executi
 	// Configure shell options based on the action type and platform configuration
 	opts := e.getPlatformShellConfig(ctx)
 	opts.Attached = shellStmt.Attached
+	opts.Interactive = shellStmt.Interactive
 	opts.CaptureOutput = !shellStmt.Attached
 	opts.StreamOutput = shellStmt.StreamOutput || shellStmt.Attached
 	if shouldBufferShellOutput(ctx, shellStmt) {
 		opts.StreamOutput = false
 	}
-	opts.Output = e.output
+	opts.Output = e.out(ctx)
+	opts.LineFilter = keepLine
 	if svcCtx != nil {
 		opts.WorkingDir = svcCtx.Path
 	} else if ctx != nil && ctx.WorkingDir != "" {
 		opts.WorkingDir = ctx.WorkingDir
 	}
+	if err := e.applyTaskContainer(opts, ctx); err != nil {
+		return err
+	}
+	if err := e.applyRunAs(opts, shellStmt, ctx); err != nil {
+		return err
+	}
 
 	// Show what we're about to execute (verbose mode only)
-	if e.verbose {
+	if e.isVerboseShell(shellStmt) {
 		switch shellStmt.Action {
 		case "run":
 			if svcCtx != nil {
-				_, _ = fmt.Fprintf(e.output, "🏃 Running in service '%s'%s: %s\n", svcCtx.Name, attachedLabel(shellStmt.Attached), interpolatedCommand)
+				e.iconf(ctx, "🏃 ", "Running in service '%s'%s: %s\n", svcCtx.Name, attachedLabel(shellStmt), interpolatedCommand)
 			} else {
-				_, _ = fmt.Fprintf(e.output, "🏃 Running%s: %s\n", attachedLabel(shellStmt.Attached), interpolatedCommand)
+				e.iconf(ctx, "🏃 ", "Running%s: %s\n", attachedLabel(shellStmt), interpolatedCommand)
 			}
 		case "exec":
-			_, _ = fmt.Fprintf(e.output, "⚡ Executing: %s\n", interpolatedCommand)
+			e.iconf(ctx, "⚡ ", "Executing: %s\n", interpolatedCommand)
 		case "shell":
-			_, _ = fmt.Fprintf(e.output, "🐚 Shell: %s\n", interpolatedCommand)
+			e.iconf(ctx, "🐚 ", "Shell: %s\n", interpolatedCommand)
 		case "capture":
-			_, _ = fmt.Fprintf(e.output, "📥  Capturing: %s\n", interpolatedCommand)
+			e.iconf(ctx, "📥  ", "Capturing: %s\n", interpolatedCommand)
 		}
 	}
 
-	// Execute the command
-	result, err := shell.Execute(interpolatedCommand, opts)
+	logWriter, closeLogs, err := e.openShellLogs(shellStmt, ctx)
+	defer closeLogs()
 	if err != nil {
+		return err
+	}
+	opts.LogWriter = logWriter
+
+	// Execute the command
+	var result *shell.Result
+	shellStart := e.notifyShellStart(interpolatedCommand, ctx)
+	if len(pipeline) > 1 {
+		result, err = shell.ExecutePipeline(pipeline, opts)
+	} else {
+		result, err = shell.Execute(interpolatedCommand, opts)
+	}
+	e.notifyShellEnd(interpolatedCommand, shellStart, result, err, ctx)
+	e.credentials.masker.flush()
+	if deadlineErr := e.checkDeadline(ctx); deadlineErr != nil {
+		return deadlineErr
+	}
+	if err = e.resolveShellExit(shellStmt, result, err, ctx); err != nil {
 		if shouldBufferShellOutput(ctx, shellStmt) {
-			writeBufferedShellFailure(e.output, result)
-			writeBufferedShellFailureSummary(e.output, interpolatedCommand, result)
+			writeBufferedShellFailure(e.out(ctx), result)
+			writeBufferedShellFailureSummary(e.out(ctx), interpolatedCommand, result)
 		}
-		_, _ = fmt.Fprintf(e.output, "❌  Command failed: %v\n", err)
+		e.iconf(ctx, "❌  ", "Command failed: %v\n", err)
 		return err
 	}
 
 	// Handle capture
 	if shellStmt.CaptureVar != "" && shellStmt.Action == "capture" {
-		ctx.Variables[shellStmt.CaptureVar] = result.Stdout
-		_, _ = fmt.Fprintf(e.output, "📦  Captured output in variable '%s'\n", shellStmt.CaptureVar)
+		e.assignVariable(ctx, shellStmt.CaptureVar, shell.FilterLines(result.Stdout, keepLine), "capture")
+		e.iconf(ctx, "📦  ", "Captured output in variable '%s'\n", shellStmt.CaptureVar)
 	}
 
 	// Show execution summary
 	if result.Success {
-		if e.verbose {
-			_, _ = fmt.Fprintf(e.output, "✅  Command completed successfully (exit code: %d, duration: %v)\n",
+		if e.isVerboseShell(shellStmt) {
+			e.iconf(ctx, "✅  ", "Command completed successfully (exit code: %d, duration: %v)\n",
 				result.ExitCode, result.Duration)
 		}
 	} else {
-		_, _ = fmt.Fprintf(e.output, "⚠️  Command completed with exit code: %d (duration: %v)\n",
+		e.iconf(ctx, "⚠️  ", "Command completed with exit code: %d (duration: %v)\n",
 			result.ExitCode, result.Duration)
 	}
 
 	return nil
 }
 
-func attachedLabel(attached bool) string {
-	if attached {
+func attachedLabel(shellStmt *statement.Shell) string {
+	if shellStmt.Interactive {
+		return " interactively"
+	}
+	if shellStmt.Attached {
 		return " attached"
 	}
 	return ""
@@ -295,9 +369,21 @@ func shouldBufferShellOutput(ctx *ExecutionContext, shellStmt *statement.Shell)
 	if shellStmt.Attached || shellStmt.Action == "capture" {
 		return false
 	}
+	switch shellStmt.Verbosity {
+	case "quiet":
+		return true
+	case "verbose":
+		return false
+	}
 	return strings.EqualFold(ctx.CurrentTaskMode, "ci")
 }
 
+// isVerboseShell reports whether execution details are shown for a shell
+// statement: globally with --verbose, or per statement with `verbosely`
+func (e *Engine) isVerboseShell(shellStmt *statement.Shell) bool {
+	return e.verbose || (shellStmt != nil && shellStmt.Verbosity == "verbose")
+}
+
 func resolvedTaskMode(taskMode, inheritedMode, override string) string {
 	if override != "" {
 		return override
@@ -1,91 +1,172 @@
 package hooks
 
 import (
+	"path"
+	"sort"
+
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 )
 
+// Hook is a registered lifecycle hook body. Hooks run in priority order
+// (higher first), then in the order they were registered (source order).
+// Before/after hooks with a Pattern only run for matching tasks
+// (before task "deploy": / after tasks matching "deploy-*":).
+type Hook struct {
+	Priority int
+	Source   string // where the hook was declared, e.g. "spec.drun:12"
+	Pattern  string // task name, or a glob pattern when Glob is set; empty = any task
+	Glob     bool
+	Body     []statement.Statement
+}
+
+// Scoped reports whether the hook only runs for matching tasks
+func (h Hook) Scoped() bool {
+	return h.Pattern != ""
+}
+
+// Matches reports whether the hook applies to taskName
+func (h Hook) Matches(taskName string) bool {
+	if !h.Scoped() {
+		return true
+	}
+	if !h.Glob {
+		return h.Pattern == taskName
+	}
+	matched, err := path.Match(h.Pattern, taskName)
+	return err == nil && matched
+}
+
+// ChainFor returns the bodies of the hooks in chain that run for taskName.
+// Unscoped hooks are only included when target is set, since "before any
+// task" hooks wrap the requested task and not its dependencies.
+func ChainFor(chain []Hook, taskName string, target bool) []statement.Statement {
+	var stmts []statement.Statement
+	for _, hook := range Sorted(chain) {
+		if hook.Scoped() && hook.Matches(taskName) || !hook.Scoped() && target {
+			stmts = append(stmts, hook.Body...)
+		}
+	}
+	return stmts
+}
+
+// Sorted returns a copy of hooks in execution order: priority, then source order
+func Sorted(hooks []Hook) []Hook {
+	sorted := make([]Hook, len(hooks))
+	copy(sorted, hooks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted
+}
+
 // Manager manages lifecycle hooks for drun execution
 type Manager struct {
-	setupHooks    []statement.Statement // on drun setup hooks
-	teardownHooks []statement.Statement // on drun teardown hooks
-	beforeHooks   []statement.Statement // before any task hooks
-	afterHooks    []statement.Statement // after any task hooks
+	setupHooks    []Hook // on drun setup hooks
+	teardownHooks []Hook // on drun teardown hooks
+	beforeHooks   []Hook // before any task / before task "x" hooks
+	afterHooks    []Hook // after any task / after task "x" hooks
 }
 
 // NewManager creates a new hook manager
 func NewManager() *Manager {
-	return &Manager{
-		setupHooks:    []statement.Statement{},
-		teardownHooks: []statement.Statement{},
-		beforeHooks:   []statement.Statement{},
-		afterHooks:    []statement.Statement{},
+	return &Manager{}
+}
+
+// RegisterHook registers a hook of the given type ("setup", "teardown",
+// "before" or "after")
+func (m *Manager) RegisterHook(hookType string, hook Hook) {
+	switch hookType {
+	case "setup":
+		m.setupHooks = append(m.setupHooks, hook)
+	case "teardown":
+		m.teardownHooks = append(m.teardownHooks, hook)
+	case "before":
+		m.beforeHooks = append(m.beforeHooks, hook)
+	case "after":
+		m.afterHooks = append(m.afterHooks, hook)
 	}
 }
 
 // RegisterSetupHook registers a setup hook statement
 func (m *Manager) RegisterSetupHook(stmt statement.Statement) {
-	m.setupHooks = append(m.setupHooks, stmt)
+	m.RegisterSetupHooks([]statement.Statement{stmt})
 }
 
 // RegisterSetupHooks registers multiple setup hook statements
 func (m *Manager) RegisterSetupHooks(stmts []statement.Statement) {
-	m.setupHooks = append(m.setupHooks, stmts...)
+	m.RegisterHook("setup", Hook{Body: stmts})
 }
 
 // RegisterTeardownHook registers a teardown hook statement
 func (m *Manager) RegisterTeardownHook(stmt statement.Statement) {
-	m.teardownHooks = append(m.teardownHooks, stmt)
+	m.RegisterTeardownHooks([]statement.Statement{stmt})
 }
 
 // RegisterTeardownHooks registers multiple teardown hook statements
 func (m *Manager) RegisterTeardownHooks(stmts []statement.Statement) {
-	m.teardownHooks = append(m.teardownHooks, stmts...)
+	m.RegisterHook("teardown", Hook{Body: stmts})
 }
 
 // RegisterBeforeHook registers a before-task hook statement
 func (m *Manager) RegisterBeforeHook(stmt statement.Statement) {
-	m.beforeHooks = append(m.beforeHooks, stmt)
+	m.RegisterBeforeHooks([]statement.Statement{stmt})
 }
 
 // RegisterBeforeHooks registers multiple before-task hook statements
 func (m *Manager) RegisterBeforeHooks(stmts []statement.Statement) {
-	m.beforeHooks = append(m.beforeHooks, stmts...)
+	m.RegisterHook("before", Hook{Body: stmts})
 }
 
 // RegisterAfterHook registers an after-task hook statement
 func (m *Manager) RegisterAfterHook(stmt statement.Statement) {
-	m.afterHooks = append(m.afterHooks, stmt)
+	m.RegisterAfterHooks([]statement.Statement{stmt})
 }
 
 // RegisterAfterHooks registers multiple after-task hook statements
 func (m *Manager) RegisterAfterHooks(stmts []statement.Statement) {
-	m.afterHooks = append(m.afterHooks, stmts...)
+	m.RegisterHook("after", Hook{Body: stmts})
 }
 
-// GetSetupHooks returns all setup hooks
+// GetSetupHooks returns all setup hook statements in execution order
 func (m *Manager) GetSetupHooks() []statement.Statement {
-	return m.setupHooks
+	return ChainFor(m.setupHooks, "", true)
 }
 
-// GetTeardownHooks returns all teardown hooks
+// GetTeardownHooks returns all teardown hook statements in execution order
 func (m *Manager) GetTeardownHooks() []statement.Statement {
-	return m.teardownHooks
+	return ChainFor(m.teardownHooks, "", true)
 }
 
-// GetBeforeHooks returns all before-task hooks
+// GetBeforeHooks returns the "before any task" hook statements in execution order
 func (m *Manager) GetBeforeHooks() []statement.Statement {
-	return m.beforeHooks
+	return ChainFor(m.beforeHooks, "", true)
 }
 
-// GetAfterHooks returns all after-task hooks
+// GetAfterHooks returns the "after any task" hook statements in execution order
 func (m *Manager) GetAfterHooks() []statement.Statement {
-	return m.afterHooks
+	return ChainFor(m.afterHooks, "", true)
+}
+
+// Chain returns the registered hooks of the given type, including task-scoped
+// hooks, in execution order
+func (m *Manager) Chain(hookType string) []Hook {
+	switch hookType {
+	case "setup":
+		return Sorted(m.setupHooks)
+	case "teardown":
+		return Sorted(m.teardownHooks)
+	case "before":
+		return Sorted(m.beforeHooks)
+	case "after":
+		return Sorted(m.afterHooks)
+	}
+	return nil
 }
 
 // Clear clears all registered hooks
 func (m *Manager) Clear() {
-	m.setupHooks = []statement.Statement{}
-	m.teardownHooks = []statement.Statement{}
-	m.beforeHooks = []statement.Statement{}
-	m.afterHooks = []statement.Statement{}
+	m.setupHooks = nil
+	m.teardownHooks = nil
+	m.beforeHooks = nil
+	m.afterHooks = nil
 }
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientAppliesBaseURLHeadersAndTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		_, _ = fmt.Fprintf(w, "%s %s auth=%s accept=%s", r.Method, r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Accept"))
+	}))
+	t.Cleanup(server.Close)
+
+	out, err := runSmokeTask(t, `version: 2.0
+
+project "deploys":
+  set token to "s3cret"
+  http client "api": base url "`+server.URL+`/v1/", header "Authorization: Bearer {$globals.token}", header "Accept: application/json", timeout 100ms
+
+task "smoke":
+  get "/deployments" using client "api" capture body as $first
+  info "first: {$first}"
+  get "deployments/1" using client "api" with header "Accept: text/plain" capture body as $second
+  info "second: {$second}"
+  get "`+server.URL+`/other" using client "api" capture body as $third
+  info "third: {$third}"
+`, false)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"first: GET /v1/deployments auth=Bearer s3cret accept=application/json",
+		"second: GET /v1/deployments/1 auth=Bearer s3cret accept=text/plain",
+		"third: GET /other auth=Bearer s3cret accept=application/json",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	_, err = runSmokeTask(t, `version: 2.0
+
+project "deploys":
+  http client "api": base url "`+server.URL+`", timeout 100ms
+
+task "smoke":
+  get "/slow" using client "api" capture body as $body
+`, false)
+	if err == nil || !strings.Contains(err.Error(), "/slow") {
+		t.Fatalf("expected the client's timeout to fail the request, got %v", err)
+	}
+
+	_, err = runSmokeTask(t, `version: 2.0
+
+task "smoke":
+  get "/x" using client "missing" capture body as $body
+`, false)
+	if err == nil || !strings.Contains(err.Error(), `unknown HTTP client "missing"`) {
+		t.Fatalf("expected an unknown client error, got %v", err)
+	}
+}
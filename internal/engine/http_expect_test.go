@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newSmokeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"ok": true, "checks": {"db": "up"}, "workers": 3}`)
+		case "/down":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprint(w, "line one\nmaintenance\n")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runSmokeTask(t *testing.T, input string, dryRun bool) (string, error) {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	eng.SetDryRun(dryRun)
+	err = eng.Execute(program, "smoke")
+	return buf.String(), err
+}
+
+func TestHTTPExpectationsPass(t *testing.T) {
+	server := newSmokeServer(t)
+	out, err := runSmokeTask(t, `version: 2.0
+
+task "smoke":
+  get "`+server.URL+`/health" expect response status in [200, 201] and header "Content-Type" contains "json" and body matches ".ok == true"
+  get "`+server.URL+`/health" expect response status "2xx" and body matches ".checks.db == up" and body matches ".workers >= 2" and body matches "\"ok\""
+  info "smoke test passed"
+`, false)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "smoke test passed") {
+		t.Fatalf("expected the task to finish:\n%s", out)
+	}
+}
+
+func TestHTTPExpectationsFail(t *testing.T) {
+	server := newSmokeServer(t)
+	out, err := runSmokeTask(t, `version: 2.0
+
+task "smoke":
+  get "`+server.URL+`/down" expect response status in [200, 201] and header "Content-Type" contains "json" and header "ETag" exists and body is "line one\nready"
+  info "not reached"
+`, false)
+	if err == nil {
+		t.Fatalf("expected the failed expectations to fail the task:\n%s", out)
+	}
+	for _, want := range []string{
+		"failed 4 response expectations",
+		"expected status in [200, 201]: got 503 Service Unavailable",
+		`expected header "Content-Type" contains "json": got "text/html"`,
+		`expected header "ETag" exists: the header is missing`,
+		"the body differs",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to contain %q, got:\n%v", want, err)
+		}
+	}
+	if !strings.Contains(out, "-ready") || !strings.Contains(out, "+maintenance") {
+		t.Errorf("expected a diff of the body:\n%s", out)
+	}
+	if strings.Contains(out, "not reached") {
+		t.Errorf("statements after the failed request should not run:\n%s", out)
+	}
+}
+
+func TestHTTPExpectationsDryRun(t *testing.T) {
+	out, err := runSmokeTask(t, `version: 2.0
+
+task "smoke":
+  get "https://api.example.com/health" expect response status 200 and body matches ".ok"
+`, true)
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if !strings.Contains(out, "[DRY RUN] Would expect response status is 200") || !strings.Contains(out, `[DRY RUN] Would expect response body matches ".ok"`) {
+		t.Errorf("expected the expectations in the dry run:\n%s", out)
+	}
+}
+
+func TestCheckJSONPredicate(t *testing.T) {
+	body := []byte(`{"ok": false, "count": 2, "items": [{"id": "a"}], "name": null}`)
+	for predicate, want := range map[string]string{
+		".count == 2":        "",
+		".count == 2.0":      "",
+		".count > 5":         ".count is 2",
+		".items.0.id == a":   "",
+		`.items.0.id != "a"`: `.items.0.id is "a"`,
+		".ok":                ".ok is false",
+		".name":              ".name is null",
+		".missing":           ".missing is missing",
+		".items > 1":         `.items is [{"id":"a"}], not a number`,
+	} {
+		got, _, err := checkJSONPredicate(predicate, body)
+		if err != nil || got != want {
+			t.Errorf("checkJSONPredicate(%s) = %q, %v; want %q", predicate, got, err, want)
+		}
+	}
+	if got, _, _ := checkJSONPredicate(".ok", []byte("<html>")); !strings.HasPrefix(got, "the body is not JSON") {
+		t.Errorf("checkJSONPredicate() of HTML = %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package includes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+)
+
+// Domain: Workspace Members
+// This file resolves includes of local monorepo packages, written as
+// include tasks from member "services/api". The member directory is relative
+// to the workspace root, its drun file is found the same way drun finds one in
+// a project, and its tasks land in a namespace named after the directory.
+
+// memberFiles are the places a member's drun file is looked up, in order
+var memberFiles = []string{
+	filepath.Join(".drun", "spec.drun"),
+	"spec.drun",
+	".drun",
+}
+
+// processMemberInclude merges the drun file of a workspace member and records
+// the member's relative path under its namespace, which is exposed to its
+// tasks as {member.path}. A member that cannot be found is an error, unlike a
+// missing include file, since the path names a package of the workspace.
+func (r *Resolver) processMemberInclude(ctx ProjectContext, include *ast.IncludeStatement, currentFile string) error {
+	memberPath := filepath.Clean(filepath.FromSlash(include.Path))
+	if filepath.IsAbs(memberPath) || memberPath == ".." || strings.HasPrefix(memberPath, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("workspace member %q must be a path inside the workspace", include.Path)
+	}
+
+	memberDir := filepath.Join(workspaceRoot(currentFile), memberPath)
+	memberFile, err := findMemberFile(memberDir)
+	if err != nil {
+		return fmt.Errorf("workspace member %q: %w", include.Path, err)
+	}
+
+	child := *include
+	child.Member = false
+	child.Path = memberFile
+	if child.Namespace == "" {
+		child.Namespace = memberNamespace(memberPath)
+	}
+	ctx.GetIncludedMembers()[child.Namespace] = filepath.ToSlash(memberPath)
+
+	return r.ProcessInclude(ctx, &child, currentFile)
+}
+
+// workspaceRoot returns the directory member paths are relative to: the
+// directory of the including file, or its parent when the file lives in .drun
+func workspaceRoot(currentFile string) string {
+	dir := filepath.Dir(currentFile)
+	if filepath.Base(dir) == ".drun" {
+		dir = filepath.Dir(dir)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// findMemberFile returns the drun file of the member in dir
+func findMemberFile(dir string) (string, error) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("directory %s does not exist", dir)
+	}
+	for _, name := range memberFiles {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no drun file in %s (looked for %s)", dir, strings.Join(memberFiles, ", "))
+}
+
+// memberNamespace derives the default namespace of a member from the last
+// element of its path; dots would be read as namespace separators
+func memberNamespace(memberPath string) string {
+	return strings.ReplaceAll(filepath.Base(memberPath), ".", "-")
+}
@@ -2,26 +2,34 @@ package includes
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/cache"
 	"github.com/phillarmonic/drun/v2/internal/remote"
+	"github.com/phillarmonic/drun/v2/internal/runtemp"
+	"github.com/phillarmonic/drun/v2/internal/stdlib"
 )
 
 // Resolver handles file inclusion, both local and remote
 type Resolver struct {
 	cacheManager   *cache.Manager
+	cacheProvider  func() *cache.Manager // opens the cache on the first remote fetch
 	githubFetcher  remote.Fetcher
 	httpsFetcher   remote.Fetcher
 	drunhubFetcher remote.Fetcher
 	verbose        bool
 	output         io.Writer
-	tempFiles      []string // Track temp files for cleanup
+	tempDir        *runtemp.Dir              // run directory holding the temp files, created on first use
+	tempFiles      []string                  // Track temp files for cleanup
+	libraries      map[string]remote.Library // library files by temp file path
+	sources        map[string]string         // remote URL by temp file path, for error messages
 	parseFunc      ParseFunc
 }
 
@@ -36,8 +44,26 @@ type ProjectContext interface {
 	GetIncludedTasks() map[string][]*ast.TaskStatement
 	GetIncludedSettings() map[string]string
 	GetIncludedParams() map[string]*ast.ProjectParameterStatement
+	GetIncludedOrigins() map[string]Origin
+	GetIncludedMembers() map[string]string
+	GetEncryptedSettings() map[string]bool
+	AddIncludedHook(hook *ast.LifecycleHook, namespace, source string) error
 }
 
+// Origin records where an included task, snippet or template was declared
+type Origin struct {
+	File string // the include path as written in the including file
+	Line int
+}
+
+func (o Origin) String() string {
+	return fmt.Sprintf("%s:%d", o.File, o.Line)
+}
+
+// ErrVendorMismatch is returned for a remote include whose vendored copy is
+// missing or does not match vendor/drun/drun.lock
+var ErrVendorMismatch = errors.New("vendored copy does not match " + remote.VendorLockFile + "; run cmd:vendor --verify")
+
 // NewResolver creates a new include resolver
 func NewResolver(
 	cacheManager *cache.Manager,
@@ -54,19 +80,55 @@ func NewResolver(
 		verbose:        verbose,
 		output:         output,
 		tempFiles:      []string{},
+		libraries:      make(map[string]remote.Library),
+		sources:        make(map[string]string),
 		parseFunc:      parseFunc,
 	}
 }
 
-// ProcessInclude loads and merges an included file into the project context
-func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatement, currentFile string) {
+// SetCacheManager sets the cache used for fetched remote includes
+func (r *Resolver) SetCacheManager(cacheManager *cache.Manager) {
+	r.cacheManager = cacheManager
+	r.cacheProvider = nil
+}
+
+// SetCacheProvider defers opening the cache used for fetched remote includes
+// until the first include that has to be fetched
+func (r *Resolver) SetCacheProvider(provider func() *cache.Manager) {
+	r.cacheManager = nil
+	r.cacheProvider = provider
+}
+
+// cache returns the remote include cache, opening it on first use
+func (r *Resolver) cache() *cache.Manager {
+	if r.cacheManager == nil && r.cacheProvider != nil {
+		r.cacheManager = r.cacheProvider()
+		r.cacheProvider = nil
+	}
+	return r.cacheManager
+}
+
+// ProcessInclude loads and merges an included file into the project context.
+// Files that cannot be found or read are reported in verbose mode and
+// skipped; a vendored copy that does not match drun.lock is an error. A file that does not parse or fails ValidateProgram is an error
+// naming its source, as is a task, snippet or template that is already
+// included into the namespace from another file unless the include says
+// override.
+func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatement, currentFile string) error {
+	if include.Member {
+		return r.processMemberInclude(ctx, include, currentFile)
+	}
+
 	// Resolve the include path relative to the current file
 	includePath, err := r.resolveIncludePath(include.Path, currentFile)
+	if errors.Is(err, ErrVendorMismatch) {
+		return err
+	}
 	if err != nil {
 		if r.verbose {
 			_, _ = fmt.Fprintf(r.output, "⚠️  Failed to resolve include path %s: %v\n", include.Path, err)
 		}
-		return
+		return nil
 	}
 
 	// Check for circular includes
@@ -74,7 +136,7 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 		if r.verbose {
 			_, _ = fmt.Fprintf(r.output, "⚠️  Circular include detected: %s (skipping)\n", includePath)
 		}
-		return
+		return nil
 	}
 
 	// Mark this file as included
@@ -87,16 +149,21 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 		if r.verbose {
 			_, _ = fmt.Fprintf(r.output, "⚠️  Failed to read included file %s: %v\n", includePath, err)
 		}
-		return
+		return nil
 	}
 
-	// Parse the included file
-	program, err := r.parseFunc(string(content), includePath)
+	// Parse and check the included file, attributing errors to the URL of
+	// remote includes rather than to their temp file
+	source := includePath
+	if url, ok := r.sources[includePath]; ok {
+		source = url
+	}
+	program, err := r.parseFunc(string(content), source)
 	if err != nil {
-		if r.verbose {
-			_, _ = fmt.Fprintf(r.output, "⚠️  Failed to parse included file %s: %v\n", includePath, err)
-		}
-		return
+		return parseProblems(source, err)
+	}
+	if err := ValidateProgram(program, source); err != nil {
+		return err
 	}
 
 	// Extract the namespace from the included project
@@ -104,7 +171,7 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 		if r.verbose {
 			_, _ = fmt.Fprintf(r.output, "⚠️  Included file %s has no project declaration (skipping)\n", includePath)
 		}
-		return
+		return nil
 	}
 
 	// Use custom namespace if provided via "as" clause, otherwise use project name
@@ -130,6 +197,11 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 		}
 	}
 
+	// Named imports limit a kind to the listed symbols, possibly renamed
+	snippetNames := include.SymbolNames("snippets")
+	templateNames := include.SymbolNames("templates")
+	found := make(map[string]bool, len(include.Symbols))
+
 	// Merge settings, parameters, and snippets from the included project
 	if program.Project != nil {
 		for _, setting := range program.Project.Settings {
@@ -139,6 +211,9 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 				namespacedKey := namespace + "." + s.Key
 				if s.Value != nil {
 					ctx.GetIncludedSettings()[namespacedKey] = s.Value.String()
+					if s.Encrypted {
+						ctx.GetEncryptedSettings()[namespacedKey] = true
+					}
 					if r.verbose {
 						_, _ = fmt.Fprintf(r.output, "  ✓  Loaded setting: %s\n", namespacedKey)
 					}
@@ -150,10 +225,31 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 				if r.verbose {
 					_, _ = fmt.Fprintf(r.output, "  ✓  Loaded parameter: %s\n", namespacedName)
 				}
+			case *ast.LifecycleHook:
+				// Included hooks come with the included tasks; they are
+				// ordered by priority, then by include position
+				if includeTasks {
+					source := fmt.Sprintf("%s (%s:%d)", namespace, filepath.Base(includePath), s.Token.Line)
+					if err := ctx.AddIncludedHook(s, namespace, source); err != nil {
+						if r.verbose {
+							_, _ = fmt.Fprintf(r.output, "⚠️  Failed to load %s hook from %s: %v\n", s.Type, includePath, err)
+						}
+					} else if r.verbose {
+						_, _ = fmt.Fprintf(r.output, "  ✓  Loaded %s hook: %s\n", s.Type, source)
+					}
+				}
 			case *ast.SnippetStatement:
 				// Namespace snippets (only if includeSnippets is true)
 				if includeSnippets {
-					namespacedName := namespace + "." + s.Name
+					name, ok := importedName(snippetNames, s.Name)
+					if !ok {
+						continue
+					}
+					found["snippets:"+s.Name] = true
+					namespacedName := namespace + "." + name
+					if _, err := claimIncluded(ctx, include, "snippet", namespacedName, s.Token.Line); err != nil {
+						return err
+					}
 					ctx.GetIncludedSnippets()[namespacedName] = s
 					if r.verbose {
 						_, _ = fmt.Fprintf(r.output, "  ✓  Loaded snippet: %s\n", namespacedName)
@@ -166,7 +262,15 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 	// Merge templates
 	if includeTemplates {
 		for _, template := range program.Templates {
-			namespacedName := namespace + "." + template.Name
+			name, ok := importedName(templateNames, template.Name)
+			if !ok {
+				continue
+			}
+			found["templates:"+template.Name] = true
+			namespacedName := namespace + "." + name
+			if _, err := claimIncluded(ctx, include, "template", namespacedName, template.Token.Line); err != nil {
+				return err
+			}
 			ctx.GetIncludedTemplates()[namespacedName] = template
 			if r.verbose {
 				_, _ = fmt.Fprintf(r.output, "  ✓  Loaded template: %s\n", namespacedName)
@@ -178,6 +282,13 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 	if includeTasks {
 		for _, task := range program.Tasks {
 			namespacedName := namespace + "." + task.Name
+			shadowed, err := claimIncluded(ctx, include, "task", namespacedName, task.Token.Line)
+			if err != nil {
+				return err
+			}
+			if shadowed {
+				delete(ctx.GetIncludedTasks(), namespacedName)
+			}
 			ctx.GetIncludedTasks()[namespacedName] = append(ctx.GetIncludedTasks()[namespacedName], task)
 			if r.verbose {
 				_, _ = fmt.Fprintf(r.output, "  ✓  Loaded task: %s\n", namespacedName)
@@ -185,16 +296,103 @@ func (r *Resolver) ProcessInclude(ctx ProjectContext, include *ast.IncludeStatem
 		}
 	}
 
+	// Files of a remote library include the rest of the library into the
+	// library's namespace
+	if lib, ok := r.libraries[includePath]; ok {
+		if err := r.processLibraryIncludes(ctx, program, lib, namespace, includePath); err != nil {
+			return err
+		}
+	}
+
 	if r.verbose {
+		for _, symbol := range include.Symbols {
+			if !found[symbol.Kind+":"+symbol.Name] {
+				_, _ = fmt.Fprintf(r.output, "⚠️  %s has no %s named %q\n", include.Path, strings.TrimSuffix(symbol.Kind, "s"), symbol.Name)
+			}
+		}
 		_, _ = fmt.Fprintf(r.output, "✓  Included %s as namespace '%s'\n", include.Path, namespace)
 	}
+	return nil
+}
+
+// processLibraryIncludes merges the files that a library file includes.
+// Relative paths resolve against the library directory, and each file of
+// the library is merged once.
+func (r *Resolver) processLibraryIncludes(ctx ProjectContext, program *ast.Program, lib remote.Library, namespace, libraryFile string) error {
+	ctx.GetIncludedFiles()["library:"+lib.URL()] = true
+
+	for _, setting := range program.Project.Settings {
+		nested, ok := setting.(*ast.IncludeStatement)
+		if !ok {
+			continue
+		}
+
+		child := *nested
+		child.Namespace = namespace
+		if !remote.IsRemoteURL(child.Path) {
+			url, err := lib.Resolve(child.Path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", lib.URL(), err)
+			}
+			child.Path = url
+		}
+
+		key := "library:" + child.Path
+		if ctx.GetIncludedFiles()[key] {
+			continue
+		}
+		ctx.GetIncludedFiles()[key] = true
+
+		if err := r.ProcessInclude(ctx, &child, libraryFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimIncluded records that include defines kind name (e.g. task
+// "docker.build") at line. Redefining a name that another file already
+// included is an error unless the include is marked override, in which case
+// shadowed reports that the earlier definition must be replaced.
+func claimIncluded(ctx ProjectContext, include *ast.IncludeStatement, kind, name string, line int) (shadowed bool, err error) {
+	origins := ctx.GetIncludedOrigins()
+	key := kind + ":" + name
+	origin := Origin{File: include.Path, Line: line}
+
+	previous, exists := origins[key]
+	if exists && previous.File == origin.File {
+		return false, nil
+	}
+	if exists && !include.Override {
+		return false, fmt.Errorf("%s %q is defined by both %s and %s; add 'override' to the include of %s to shadow it",
+			kind, name, previous, origin, include.Path)
+	}
+	origins[key] = origin
+	return exists, nil
+}
+
+// importedName returns the name a snippet or template is imported under, and
+// whether it is imported at all; names is nil when every symbol is imported
+func importedName(names map[string]string, name string) (string, bool) {
+	if names == nil {
+		return name, true
+	}
+	local, ok := names[name]
+	return local, ok
 }
 
 // resolveIncludePath resolves the include path relative to the current file
 func (r *Resolver) resolveIncludePath(includePath, currentFile string) (string, error) {
-	// Check if remote URL
+	// Check if remote URL, preferring a vendored copy when one is recorded
 	if remote.IsRemoteURL(includePath) {
-		return r.fetchRemoteInclude(includePath)
+		vendored, ok, err := r.vendoredInclude(includePath, currentFile)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return vendored, nil
+		}
+		return r.fetchRemoteInclude(includePath, currentFile)
 	}
 
 	// If absolute path, use as-is
@@ -226,19 +424,81 @@ func (r *Resolver) resolveIncludePath(includePath, currentFile string) (string,
 	return includePath, nil
 }
 
-// fetchRemoteInclude fetches a remote include and returns the path to a temp file
-func (r *Resolver) fetchRemoteInclude(url string) (string, error) {
+// vendoredInclude returns the vendored copy of url recorded in the project's
+// vendor/drun/drun.lock, if any. A vendored copy that does not match the
+// lock is an error rather than a reason to run it or fetch it again.
+func (r *Resolver) vendoredInclude(url, currentFile string) (string, bool, error) {
+	root := remote.VendorRoot(currentFile)
+	lock, err := remote.LoadVendorLock(root)
+	if err != nil {
+		if r.verbose && !errors.Is(err, os.ErrNotExist) {
+			_, _ = fmt.Fprintf(r.output, "⚠️  Ignoring vendor lock: %v\n", err)
+		}
+		return "", false, nil
+	}
+
+	entry, ok := lock.Lookup(url)
+	if !ok {
+		return "", false, nil
+	}
+	if err := entry.Verify(root); err != nil {
+		return "", false, fmt.Errorf("%w: %v", ErrVendorMismatch, err)
+	}
+
+	if r.verbose {
+		_, _ = fmt.Fprintf(r.output, "  ✓  Using vendored copy of %s\n", url)
+	}
+	return entry.Path(root), true, nil
+}
+
+// fetchRemoteInclude fetches a remote include and returns the path to a temp
+// file. A library include fetches the library file; a file of the library
+// that currentFile belongs to keeps that library's directory.
+func (r *Resolver) fetchRemoteInclude(url, currentFile string) (string, error) {
 	protocol, path, ref, err := remote.ParseRemoteURL(url)
 	if err != nil {
 		return "", err
 	}
 
+	lib, isLibrary, err := remote.ParseLibraryURL(url)
+	if err != nil {
+		return "", err
+	}
+	if isLibrary {
+		if parent, ok := r.libraries[currentFile]; ok && parent.Contains(lib) {
+			lib.Dir = parent.Dir
+		}
+		path = lib.FetchPath()
+		tmp, err := r.fetchRemoteContent(url, protocol, path, ref)
+		if err != nil {
+			return "", err
+		}
+		r.libraries[tmp] = lib
+		return tmp, nil
+	}
+	return r.fetchRemoteContent(url, protocol, path, ref)
+}
+
+// fetchRemoteContent fetches path with the protocol's fetcher, using the
+// include cache, and writes the content to a temp file
+func (r *Resolver) fetchRemoteContent(url, protocol, path, ref string) (string, error) {
+	// The standard library is built in; a pinned ref still fetches drun-hub
+	if protocol == "drunhub" && ref == "" {
+		if content, ok := stdlib.Lookup(path); ok {
+			if r.verbose {
+				_, _ = fmt.Fprintf(r.output, "  ✓  Using built-in %s\n", path)
+			}
+			return r.writeTempFile(content, url)
+		}
+	}
+
 	// Generate cache key
 	cacheKey := cache.GenerateKey(url, ref)
+	cacheManager := r.cache()
 
 	// Check cache (if enabled)
-	if r.cacheManager != nil {
-		if content, hit, err := r.cacheManager.Get(cacheKey); err == nil && hit {
+	if cacheManager != nil {
+		if content, hit, err := cacheManager.Get(cacheKey); err == nil && hit {
 			if r.verbose {
 				_, _ = fmt.Fprintf(r.output, "  ✓  Cache hit for %s\n", url)
 			}
@@ -272,8 +532,8 @@ func (r *Resolver) fetchRemoteInclude(url string) (string, error) {
 	content, err := fetcher.Fetch(ctx, path, ref)
 	if err != nil {
 		// Try stale cache as fallback
-		if r.cacheManager != nil {
-			if stale, ok := r.cacheManager.GetStale(cacheKey); ok {
+		if cacheManager != nil {
+			if stale, ok := cacheManager.GetStale(cacheKey); ok {
 				if r.verbose {
 					_, _ = fmt.Fprintf(r.output, "  ⚠️  Network error, using stale cache\n")
 				}
@@ -288,24 +548,34 @@ func (r *Resolver) fetchRemoteInclude(url string) (string, error) {
 	}
 
 	// Store in cache
-	if r.cacheManager != nil {
-		if err := r.cacheManager.Set(cacheKey, content, 1*time.Minute); err != nil {
+	if cacheManager != nil {
+		if err := cacheManager.Set(cacheKey, content, cacheManager.Expiration()); err != nil {
 			// Log but don't fail
 			if r.verbose {
 				_, _ = fmt.Fprintf(r.output, "  ⚠️  Failed to cache: %v\n", err)
 			}
 		} else if r.verbose {
-			_, _ = fmt.Fprintf(r.output, "  ✓  Cached with 1m expiration\n")
+			_, _ = fmt.Fprintf(r.output, "  ✓  Cached with %s expiration\n", cacheManager.Expiration())
 		}
 	}
 
 	return r.writeTempFile(content, url)
 }
 
-// writeTempFile writes content to a temporary file and tracks it for cleanup
+// writeTempFile writes content to a temporary file in the run directory and
+// tracks it for cleanup. A run that crashes leaves the directory behind for
+// the next run, or cmd:cache gc --temp, to sweep.
 func (r *Resolver) writeTempFile(content []byte, sourceURL string) (string, error) {
+	if r.tempDir == nil {
+		dir, err := runtemp.New()
+		if err != nil {
+			return "", err
+		}
+		r.tempDir = dir
+	}
+
 	// Create temp file with .drun extension
-	tmpFile, err := os.CreateTemp("", "drun-remote-*.drun")
+	tmpFile, err := r.tempDir.CreateFile("remote-*.drun")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -319,6 +589,7 @@ func (r *Resolver) writeTempFile(content []byte, sourceURL string) (string, erro
 
 	// Track for cleanup
 	r.tempFiles = append(r.tempFiles, tmpFile.Name())
+	r.sources[tmpFile.Name()] = sourceURL
 
 	// Return absolute path
 	return tmpFile.Name(), nil
@@ -329,7 +600,13 @@ func (r *Resolver) Cleanup() {
 	for _, f := range r.tempFiles {
 		_ = os.Remove(f)
 	}
+	if r.tempDir != nil {
+		_ = r.tempDir.Remove()
+		r.tempDir = nil
+	}
 	r.tempFiles = nil
+	r.libraries = make(map[string]remote.Library)
+	r.sources = make(map[string]string)
 }
 
 // GetTempFiles returns the list of temporary files created
@@ -0,0 +1,258 @@
+package includes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	drunErrors "github.com/phillarmonic/drun/v2/internal/errors"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+	"github.com/phillarmonic/drun/v2/internal/parser"
+)
+
+// mapFetcher serves files from memory and records the requested paths
+type mapFetcher struct {
+	files   map[string]string
+	fetched []string
+}
+
+func (f *mapFetcher) Protocol() string { return "github" }
+
+func (f *mapFetcher) Fetch(_ context.Context, path, ref string) ([]byte, error) {
+	f.fetched = append(f.fetched, path+"@"+ref)
+	content, ok := f.files[path]
+	if !ok {
+		return nil, fmt.Errorf("404 %s", path)
+	}
+	return []byte(content), nil
+}
+
+// testProjectContext is the part of the engine's project context that
+// include resolution fills in
+type testProjectContext struct {
+	files     map[string]bool
+	snippets  map[string]*ast.SnippetStatement
+	templates map[string]*ast.TaskTemplateStatement
+	tasks     map[string][]*ast.TaskStatement
+	settings  map[string]string
+	params    map[string]*ast.ProjectParameterStatement
+	origins   map[string]Origin
+	members   map[string]string
+	encrypted map[string]bool
+}
+
+func newTestProjectContext() *testProjectContext {
+	return &testProjectContext{
+		files:     map[string]bool{},
+		snippets:  map[string]*ast.SnippetStatement{},
+		templates: map[string]*ast.TaskTemplateStatement{},
+		tasks:     map[string][]*ast.TaskStatement{},
+		settings:  map[string]string{},
+		params:    map[string]*ast.ProjectParameterStatement{},
+		origins:   map[string]Origin{},
+		members:   map[string]string{},
+		encrypted: map[string]bool{},
+	}
+}
+
+func (c *testProjectContext) GetIncludedFiles() map[string]bool { return c.files }
+func (c *testProjectContext) GetIncludedSnippets() map[string]*ast.SnippetStatement {
+	return c.snippets
+}
+func (c *testProjectContext) GetIncludedTemplates() map[string]*ast.TaskTemplateStatement {
+	return c.templates
+}
+func (c *testProjectContext) GetIncludedTasks() map[string][]*ast.TaskStatement { return c.tasks }
+func (c *testProjectContext) GetIncludedSettings() map[string]string            { return c.settings }
+func (c *testProjectContext) GetIncludedParams() map[string]*ast.ProjectParameterStatement {
+	return c.params
+}
+func (c *testProjectContext) GetIncludedOrigins() map[string]Origin { return c.origins }
+func (c *testProjectContext) GetIncludedMembers() map[string]string { return c.members }
+func (c *testProjectContext) GetEncryptedSettings() map[string]bool { return c.encrypted }
+func (c *testProjectContext) AddIncludedHook(*ast.LifecycleHook, string, string) error {
+	return nil
+}
+
+func parseTestFile(input, _ string) (*ast.Program, error) {
+	p := parser.NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(p.Errors(), "; "))
+	}
+	return program, nil
+}
+
+func TestProcessIncludeLoadsRemoteLibrary(t *testing.T) {
+	fetcher := &mapFetcher{files: map[string]string{
+		"acme/ops/lib/docker/index.drun": `version: 2.0
+
+project "docker":
+  include "build.drun"
+  include "push/push.drun"
+
+task "ping":
+  info "ping"
+`,
+		"acme/ops/lib/docker/build.drun": `version: 2.0
+
+project "build":
+  include "index.drun"
+
+task "build":
+  info "build"
+`,
+		"acme/ops/lib/docker/push/push.drun": `version: 2.0
+
+project "push":
+  include "build.drun"
+
+task "push":
+  info "push"
+`,
+	}}
+	resolver := NewResolver(nil, fetcher, nil, nil, false, io.Discard, parseTestFile)
+	defer resolver.Cleanup()
+
+	ctx := newTestProjectContext()
+	include := &ast.IncludeStatement{Path: "github:acme/ops//lib/docker@v1", Namespace: "docker"}
+	if err := resolver.ProcessInclude(ctx, include, "/work/spec.drun"); err != nil {
+		t.Fatalf("ProcessInclude() error = %v", err)
+	}
+
+	var tasks []string
+	for name := range ctx.tasks {
+		tasks = append(tasks, name)
+	}
+	sort.Strings(tasks)
+	if got := strings.Join(tasks, ","); got != "docker.build,docker.ping,docker.push" {
+		t.Fatalf("included tasks = %s", got)
+	}
+
+	// Every file is fetched once, and push/push.drun's include resolves
+	// against the library directory
+	sort.Strings(fetcher.fetched)
+	want := "acme/ops/lib/docker/build.drun@v1,acme/ops/lib/docker/index.drun@v1,acme/ops/lib/docker/push/push.drun@v1"
+	if got := strings.Join(fetcher.fetched, ","); got != want {
+		t.Fatalf("fetched = %s\nwant %s", got, want)
+	}
+}
+
+func TestProcessIncludeRejectsPathsOutsideTheLibrary(t *testing.T) {
+	fetcher := &mapFetcher{files: map[string]string{
+		"acme/ops/lib/docker/index.drun": `version: 2.0
+
+project "docker":
+  include "../../secrets.drun"
+`,
+	}}
+	resolver := NewResolver(nil, fetcher, nil, nil, false, io.Discard, parseTestFile)
+	defer resolver.Cleanup()
+
+	include := &ast.IncludeStatement{Path: "github:acme/ops//lib/docker@v1"}
+	err := resolver.ProcessInclude(newTestProjectContext(), include, "/work/spec.drun")
+	if err == nil || !strings.Contains(err.Error(), "leaves the library directory") {
+		t.Fatalf("expected an error for an include outside the library, got %v", err)
+	}
+}
+
+func TestProcessIncludeReportsInvalidRemoteFileByURL(t *testing.T) {
+	fetcher := &mapFetcher{files: map[string]string{
+		"acme/ops/deploy.drun": `version: 2.0
+
+project "deploy":
+  parameter $retries as number defaults to "many"
+
+task "rollout":
+  given $replicas as number between 1 and 5 defaults to "9"
+  given $replicas as number defaults to "2"
+  info "rollout"
+`,
+		"acme/ops/broken.drun": `version: 2.0
+
+project "broken":
+
+task "rollout"
+  info "rollout"
+`,
+	}}
+	resolver := NewResolver(nil, fetcher, nil, nil, false, io.Discard, parseTestFile)
+	defer resolver.Cleanup()
+
+	ctx := newTestProjectContext()
+	err := resolver.ProcessInclude(ctx, &ast.IncludeStatement{Path: "github:acme/ops/deploy.drun@v1"}, "/work/spec.drun")
+	var validationErr *drunErrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	for _, want := range []string{
+		`github:acme/ops/deploy.drun@v1:4: parameter $retries: default "many" is invalid`,
+		`github:acme/ops/deploy.drun@v1:7: task "rollout": parameter $replicas: default "9" must be <= 5.00`,
+		`github:acme/ops/deploy.drun@v1:8: task "rollout": parameter $replicas is declared more than once`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	}
+	if len(ctx.tasks) != 0 {
+		t.Errorf("an invalid include must not be merged, got tasks %v", ctx.tasks)
+	}
+
+	err = resolver.ProcessInclude(ctx, &ast.IncludeStatement{Path: "github:acme/ops/broken.drun@v1"}, "/work/spec.drun")
+	if err == nil || !strings.HasPrefix(err.Error(), "included file github:acme/ops/broken.drun@v1 is invalid:") {
+		t.Fatalf("expected the parse error to name the include URL, got %v", err)
+	}
+}
+
+func TestProcessIncludeLoadsWorkspaceMember(t *testing.T) {
+	root := t.TempDir()
+	member := filepath.Join(root, "services", "api", ".drun")
+	if err := os.MkdirAll(member, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(member, "spec.drun"), []byte(`version: 2.0
+
+project "api-service":
+  set port to "8080"
+
+task "build":
+  info "build"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewResolver(nil, nil, nil, nil, false, io.Discard, parseTestFile)
+	defer resolver.Cleanup()
+
+	ctx := newTestProjectContext()
+	include := &ast.IncludeStatement{Path: "services/api", Selectors: []string{"tasks"}, Member: true}
+	currentFile := filepath.Join(root, ".drun", "spec.drun")
+	if err := resolver.ProcessInclude(ctx, include, currentFile); err != nil {
+		t.Fatalf("ProcessInclude() error = %v", err)
+	}
+
+	if _, ok := ctx.tasks["api.build"]; !ok {
+		t.Fatalf("expected task api.build, got %v", ctx.tasks)
+	}
+	if got := ctx.members["api"]; got != "services/api" {
+		t.Fatalf("member path = %q, want services/api", got)
+	}
+
+	missing := &ast.IncludeStatement{Path: "services/gone", Member: true}
+	err := resolver.ProcessInclude(ctx, missing, currentFile)
+	if err == nil || !strings.Contains(err.Error(), `workspace member "services/gone"`) {
+		t.Fatalf("expected an error for a missing member, got %v", err)
+	}
+
+	outside := &ast.IncludeStatement{Path: "../other", Member: true}
+	if err := resolver.ProcessInclude(ctx, outside, currentFile); err == nil {
+		t.Fatal("expected an error for a member outside the workspace")
+	}
+}
@@ -0,0 +1,169 @@
+package includes
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
+	drunErrors "github.com/phillarmonic/drun/v2/internal/errors"
+	"github.com/phillarmonic/drun/v2/internal/types"
+)
+
+// Domain: Include Validation
+// This file checks an included file before it is merged, so a broken library
+// fails when it is included, with errors pointing at its own source and lines
+// instead of failing later in the middle of a run.
+
+// Problem is one error found in an included file
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// IncludeError reports the problems of an included file. Source is the URL of
+// a remote include, or the path of a local one.
+type IncludeError struct {
+	Source   string
+	Problems []Problem
+	Err      error // the underlying parse error, if the file did not parse
+}
+
+func (e *IncludeError) Error() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "included file %s is invalid:", e.Source)
+	for _, problem := range e.Problems {
+		location := e.Source
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, problem.Line)
+			if problem.Column > 0 {
+				location = fmt.Sprintf("%s:%d", location, problem.Column)
+			}
+		}
+		fmt.Fprintf(&out, "\n  %s: %s", location, problem.Message)
+	}
+	return out.String()
+}
+
+func (e *IncludeError) Unwrap() error {
+	return e.Err
+}
+
+// parseProblems turns the error of parsing an included file into problems
+func parseProblems(source string, err error) *IncludeError {
+	includeErr := &IncludeError{Source: source, Err: err}
+	var list *drunErrors.ParseErrorList
+	if errors.As(err, &list) && len(list.Errors) > 0 {
+		for _, parseErr := range list.Errors {
+			includeErr.Problems = append(includeErr.Problems, Problem{
+				Line:    parseErr.Token.Line,
+				Column:  parseErr.Token.Column,
+				Message: parseErr.Message,
+			})
+		}
+		return includeErr
+	}
+	includeErr.Problems = []Problem{{Message: err.Error()}}
+	return includeErr
+}
+
+// ValidateProgram statically checks an included program: parameter
+// declarations must be consistent and literal defaults must satisfy their
+// own type and constraints. It returns nil when no problem was found.
+func ValidateProgram(program *ast.Program, source string) error {
+	var problems []Problem
+
+	if program.Project != nil {
+		for _, setting := range program.Project.Settings {
+			param, ok := setting.(*ast.ProjectParameterStatement)
+			if !ok {
+				continue
+			}
+			problems = append(problems, checkParameter(fmt.Sprintf("parameter $%s", param.Name), param.Token.Line, &parameter.Parameter{
+				Name:         param.Name,
+				DefaultValue: param.DefaultValue,
+				HasDefault:   param.HasDefault,
+				DataType:     param.DataType,
+				Constraints:  param.Constraints,
+				MinValue:     param.MinValue,
+				MaxValue:     param.MaxValue,
+				Pattern:      param.Pattern,
+				PatternMacro: param.PatternMacro,
+				EmailFormat:  param.EmailFormat,
+			})...)
+		}
+	}
+
+	for _, task := range program.Tasks {
+		seen := make(map[string]bool, len(task.Parameters))
+		for _, param := range task.Parameters {
+			subject := fmt.Sprintf("task %q: parameter $%s", task.Name, param.Name)
+			if seen[param.Name] {
+				problems = append(problems, Problem{Line: param.Token.Line, Message: subject + " is declared more than once"})
+				continue
+			}
+			seen[param.Name] = true
+			problems = append(problems, checkParameter(subject, param.Token.Line, &parameter.Parameter{
+				Name:         param.Name,
+				Type:         param.Type,
+				DefaultValue: param.DefaultValue,
+				HasDefault:   param.HasDefault,
+				Required:     param.Required,
+				DataType:     param.DataType,
+				Constraints:  param.Constraints,
+				MinValue:     param.MinValue,
+				MaxValue:     param.MaxValue,
+				Pattern:      param.Pattern,
+				PatternMacro: param.PatternMacro,
+				EmailFormat:  param.EmailFormat,
+				Variadic:     param.Variadic,
+			})...)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return drunErrors.NewValidationError(&IncludeError{Source: source, Problems: problems})
+}
+
+// checkParameter checks one parameter declaration. Defaults that are
+// interpolated or computed by a builtin are only known at run time and are
+// not checked; neither is whether a file default exists.
+func checkParameter(subject string, line int, param *parameter.Parameter) []Problem {
+	var problems []Problem
+	if param.MinValue != nil && param.MaxValue != nil && *param.MinValue > *param.MaxValue {
+		problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("%s: range minimum %g is above maximum %g", subject, *param.MinValue, *param.MaxValue)})
+	}
+	if param.Pattern != "" {
+		if _, err := regexp.Compile(param.Pattern); err != nil {
+			problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("%s: invalid pattern %q: %v", subject, param.Pattern, err)})
+			return problems
+		}
+	}
+	if !param.HasDefault || param.DefaultValue == "" || strings.Contains(param.DefaultValue, "{") {
+		return problems
+	}
+
+	paramType, err := types.ParseParameterType(param.DataType)
+	if err != nil {
+		paramType = types.InferType(param.DefaultValue)
+	}
+	value, err := types.NewValue(paramType, param.DefaultValue)
+	if err == nil {
+		literal := *param
+		literal.MustExist = false
+		err = parameter.NewValidator().Validate(&literal, value)
+	}
+	var validationErr *parameter.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("%s: default %q %s", subject, param.DefaultValue, validationErr.Message)})
+	case err != nil:
+		problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("%s: default %q is invalid: %v", subject, param.DefaultValue, err)})
+	}
+	return problems
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIncludeConflictLibs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	libs := map[string]string{
+		"base.drun": `version: 2.0
+
+project "base":
+  snippet "login":
+    info "base login"
+
+task "deploy":
+  info "base deploy"
+`,
+		"custom.drun": `version: 2.0
+
+project "custom":
+  snippet "login":
+    info "custom login"
+
+task "deploy":
+  info "custom deploy"
+`,
+	}
+	for name, content := range libs {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestIncludeConflictsAreReported(t *testing.T) {
+	root := writeIncludeConflictLibs(t)
+	specFile := filepath.Join(root, "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include "base.drun" as shared
+  include tasks from "custom.drun" as shared
+
+task "noop":
+  info "noop"`
+
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	eng := NewEngine(&bytes.Buffer{})
+	_, err = eng.BuildProjectContext(program.Project, specFile)
+	if err == nil {
+		t.Fatal("Expected a conflict error")
+	}
+	want := `task "shared.deploy" is defined by both base.drun:7 and custom.drun:7`
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected %q in error, got: %v", want, err)
+	}
+}
+
+func TestIncludeOverrideShadowsEarlierDefinitions(t *testing.T) {
+	root := writeIncludeConflictLibs(t)
+	specFile := filepath.Join(root, "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include "base.drun" as shared
+  include "custom.drun" as shared override
+
+task "release":
+  use snippet "shared.login"
+  call task "shared.deploy"`
+
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	if err := eng.ExecuteWithParamsAndFile(program, "release", nil, specFile); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+	outputStr := output.String()
+	if !strings.Contains(outputStr, "custom login") || !strings.Contains(outputStr, "custom deploy") {
+		t.Errorf("Expected the overriding include to win, got:\n%s", outputStr)
+	}
+	if strings.Contains(outputStr, "base ") {
+		t.Errorf("Shadowed definitions should not run, got:\n%s", outputStr)
+	}
+}
+
+func TestNamespaceDefaultsOverrideIncludedParameterDefaults(t *testing.T) {
+	root := t.TempDir()
+	lib := `version: 2.0
+
+project "docker":
+
+task "build":
+  requires $registry defaults to "docker.io"
+  given $tag defaults to "latest"
+  info "build {$registry}/app:{$tag}"
+
+task "push":
+  requires $registry
+  info "push to {$registry}"
+`
+	if err := os.WriteFile(filepath.Join(root, "docker.drun"), []byte(lib), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	specFile := filepath.Join(root, "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include "docker.drun" as docker
+  defaults for docker.*: set registry to "ghcr.io/acme"
+  defaults for docker.build: set tag to "edge"
+
+task "release":
+  call task "docker.push"
+  call task "docker.build"
+  call task "docker.build" with registry="quay.io" tag="v1"
+
+task "ship":
+  depends on "docker.build"
+  info "shipped"`
+
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	if err := eng.ExecuteWithParamsAndFile(program, "release", nil, specFile); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+	for _, want := range []string{"push to ghcr.io/acme", "build ghcr.io/acme/app:edge", "build quay.io/app:v1"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Expected %q in output:\n%s", want, output.String())
+		}
+	}
+
+	output.Reset()
+	if err := eng.ExecuteWithParamsAndFile(program, "ship", nil, specFile); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+	if !strings.Contains(output.String(), "build ghcr.io/acme/app:edge") {
+		t.Errorf("Expected the defaults to apply to dependencies:\n%s", output.String())
+	}
+}
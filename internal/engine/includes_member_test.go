@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncludedMemberTasksSeeMemberPath(t *testing.T) {
+	root := t.TempDir()
+	memberDir := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(memberDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(memberDir, "spec.drun"), []byte(`version: 2.0
+
+project "api-service":
+  set port to "8080"
+
+task "build":
+  info "building {member.path}"
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	specFile := filepath.Join(root, "spec.drun")
+	input := `version: 2.0
+
+project "shop":
+  include tasks from member "services/api"
+
+task "build all":
+  call task "api.build"
+  info "done with {member.path}"`
+
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	if err := eng.ExecuteWithParamsAndFile(program, "build all", nil, specFile); err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+
+	if !strings.Contains(output.String(), "building services/api") {
+		t.Errorf("member task should see its path, got:\n%s", output.String())
+	}
+	if strings.Contains(output.String(), "done with services/api") {
+		t.Errorf("member path should not leak to the caller, got:\n%s", output.String())
+	}
+}
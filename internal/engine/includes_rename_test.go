@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncludeRenamedSnippetsResolveCollisions(t *testing.T) {
+	root := t.TempDir()
+	libs := map[string]string{
+		"ghcr.drun": `version: 2.0
+
+project "ghcr":
+  snippet "docker login":
+    info "login to ghcr"
+
+  snippet "unused":
+    info "unused"
+`,
+		"ecr.drun": `version: 2.0
+
+project "ecr":
+  snippet "docker login":
+    info "login to ecr"
+
+template task "push":
+  info "pushing to ecr"
+`,
+	}
+	for name, content := range libs {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	specFile := filepath.Join(root, "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include snippets "docker login" as "ghcr-login" from "ghcr.drun" as images
+  include snippets "docker login" as "ecr-login", templates "push" as "ecr-push" from "ecr.drun" as images
+
+task "publish":
+  use snippet "images.ghcr-login"
+  use snippet "images.ecr-login"
+  call task "images.ecr-push"`
+
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	if err := eng.ExecuteWithParamsAndFile(program, "publish", nil, specFile); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+	for _, want := range []string{"login to ghcr", "login to ecr", "pushing to ecr"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Expected %q in output, got:\n%s", want, output.String())
+		}
+	}
+
+	projectCtx, err := eng.BuildProjectContext(program.Project, specFile)
+	if err != nil {
+		t.Fatalf("BuildProjectContext failed: %v", err)
+	}
+	for _, name := range []string{"images.docker login", "images.unused"} {
+		if _, exists := projectCtx.IncludedSnippets[name]; exists {
+			t.Errorf("snippet %q should not be imported", name)
+		}
+	}
+}
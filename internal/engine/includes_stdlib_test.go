@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncludeStandardLibraryWithoutNetwork(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), ".drun", "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include from drunhub std/core
+
+task "next":
+  call task "std.semver-bump" with current="v2.4.1" part="minor"
+`
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	defer eng.Cleanup()
+	if err := eng.ExecuteWithParamsAndFile(program, "next", nil, specFile); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "v2.5.0") {
+		t.Fatalf("expected the bumped version, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/remote"
+)
+
+func TestRemoteIncludeResolvesFromVendorDir(t *testing.T) {
+	root := t.TempDir()
+	url := "github:acme/ops/docker.drun@v1"
+	included := []byte(`version: 2.0
+
+project "docker":
+
+task "hello":
+  info "hello from vendor"
+`)
+
+	entry := remote.VendorEntry{URL: url, File: "github/acme/ops/docker@v1.drun", SHA256: remote.Checksum(included)}
+	if err := os.MkdirAll(filepath.Dir(entry.Path(root)), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry.Path(root), included, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&remote.VendorLock{Includes: []remote.VendorEntry{entry}}).Save(root); err != nil {
+		t.Fatal(err)
+	}
+
+	specFile := filepath.Join(root, ".drun", "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include "` + url + `"
+
+task "greet":
+  call task "docker.hello"
+`
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.ExecuteWithParamsAndFile(program, "greet", nil, specFile); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "hello from vendor") {
+		t.Fatalf("expected vendored task output, got:\n%s", buf.String())
+	}
+}
+
+func TestTamperedVendoredIncludeFails(t *testing.T) {
+	root := t.TempDir()
+	url := "github:acme/ops/docker.drun@v1"
+	included := []byte(`version: 2.0
+
+project "docker":
+
+task "hello":
+  info "hello from vendor"
+`)
+
+	entry := remote.VendorEntry{URL: url, File: "github/acme/ops/docker@v1.drun", SHA256: remote.Checksum(included)}
+	if err := os.MkdirAll(filepath.Dir(entry.Path(root)), 0750); err != nil {
+		t.Fatal(err)
+	}
+	tampered := []byte(strings.Replace(string(included), "hello from vendor", "hello from someone else", 1))
+	if err := os.WriteFile(entry.Path(root), tampered, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&remote.VendorLock{Includes: []remote.VendorEntry{entry}}).Save(root); err != nil {
+		t.Fatal(err)
+	}
+
+	specFile := filepath.Join(root, ".drun", "spec.drun")
+	input := `version: 2.0
+
+project "app":
+  include "` + url + `"
+
+task "greet":
+  call task "docker.hello"
+`
+	program, err := ParseStringWithFilename(input, specFile)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	err = eng.ExecuteWithParamsAndFile(program, "greet", nil, specFile)
+	if err == nil || !strings.Contains(err.Error(), "vendored copy does not match drun.lock; run cmd:vendor --verify") {
+		t.Fatalf("expected a vendored checksum error, got %v\n%s", err, buf.String())
+	}
+	if strings.Contains(buf.String(), "hello from someone else") {
+		t.Fatalf("tampered vendored include ran:\n%s", buf.String())
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/phillarmonic/drun/v2/internal/builtins"
 	"github.com/phillarmonic/drun/v2/internal/types"
 )
 
 // Interpolator handles string interpolation for variables and expressions
 type Interpolator struct {
 	allowUndefined bool
+	shellEscape    bool // quote interpolated values for the shell (InterpolateShellWithError)
 
 	// Cached regex patterns for performance
 	envVarRegex    *regexp.Regexp
@@ -32,12 +34,19 @@ type Interpolator struct {
 	allowedFailures map[string]bool //nolint:unused
 }
 
+// Patterns shared by every interpolator, compiled once per process
+var (
+	envVarPattern    = regexp.MustCompile(`\$\{([^}]+)\}`)
+	quotedArgPattern = regexp.MustCompile(`^([^(]+)\((.+)\)$`)
+	paramArgPattern  = regexp.MustCompile(`^([^(]+)\(([^)]+)\)$`)
+)
+
 // NewInterpolator creates a new interpolator
 func NewInterpolator() *Interpolator {
 	return &Interpolator{
-		envVarRegex:    regexp.MustCompile(`\$\{([^}]+)\}`),
-		quotedArgRegex: regexp.MustCompile(`^([^(]+)\((.+)\)$`),
-		paramArgRegex:  regexp.MustCompile(`^([^(]+)\(([^)]+)\)$`),
+		envVarRegex:    envVarPattern,
+		quotedArgRegex: quotedArgPattern,
+		paramArgRegex:  paramArgPattern,
 	}
 }
 
@@ -92,8 +101,15 @@ func (i *Interpolator) Interpolate(s string, ctx Context) string {
 
 // InterpolateWithError performs variable and environment variable interpolation with error reporting
 func (i *Interpolator) InterpolateWithError(message string, ctx Context) (string, error) {
-	// Reset error collection
-	i.builtinErrors = nil
+	// Collect builtin errors on a per-call copy so concurrent interpolations
+	// (parallel loops and targets) don't share error state
+	call := *i
+	call.builtinErrors = nil
+	return call.interpolate(message, ctx)
+}
+
+// interpolate implements InterpolateWithError on a per-call interpolator copy
+func (i *Interpolator) interpolate(message string, ctx Context) (string, error) {
 
 	// First pass: resolve ${VAR} environment variables (shell-style)
 	// Quick check: if there are no ${...} patterns, skip this phase
@@ -141,7 +157,11 @@ func (i *Interpolator) InterpolateWithError(message string, ctx Context) (string
 	// Second pass: resolve {$var} Drun variables. Placeholders use balanced { } so
 	// nested {$x} inside a ternary branch (e.g. {$a ? 'prefix-{$b}' : ''}) is one span.
 	var undefinedVars []string
-	result, err := i.expandDrunBraceInterpolations(message, ctx, &undefinedVars)
+	expand := i.expandDrunBraceInterpolations
+	if i.shellEscape {
+		expand = i.expandShellInterpolations
+	}
+	result, err := expand(message, ctx, &undefinedVars)
 	if err != nil {
 		return message, err
 	}
@@ -253,6 +273,12 @@ func (i *Interpolator) resolveDrunBraceContent(content, match string, ctx Contex
 	if content == "" {
 		return match
 	}
+	// `| raw` only matters to shell escaping; elsewhere values are never quoted
+	content, _ = cutRawModifier(content)
+	// JSON objects in expanded values ({"name":"api"}) are literal text, not placeholders
+	if strings.HasPrefix(content, `"`) {
+		return match
+	}
 
 	// Try to resolve simple variables first (most common case)
 	if resolved, found := i.resolveSimpleVariableDirectly(content, ctx); found {
@@ -275,9 +301,14 @@ func (i *Interpolator) resolveDrunBraceContent(content, match string, ctx Contex
 	}
 
 	// Fall back to complex expression resolution
+	builtinErrors := len(i.builtinErrors)
 	if resolved := i.resolveExpression(content, ctx); resolved != "" {
 		return resolved
 	}
+	// A builtin that succeeded may be empty, like {stdin} when nothing was piped in
+	if builtins.IsBuiltin(content) && len(i.builtinErrors) == builtinErrors {
+		return ""
+	}
 
 	// If nothing worked, check if we should be strict about undefined variables
 	if !i.allowUndefined {
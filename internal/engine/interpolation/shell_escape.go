@@ -0,0 +1,140 @@
+package interpolation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Domain: Shell Escaping
+// This file interpolates shell commands with `set shell escaping to "strict"`:
+// every {...} value is quoted for the place it appears in, so parameter values
+// cannot split into several words or inject commands. `{$var | raw}` opts out.
+
+// quoteState is the shell quoting context at a position of a command
+type quoteState int
+
+const (
+	unquoted quoteState = iota
+	singleQuoted
+	doubleQuoted
+)
+
+// shellSafeValue matches values the shell reads as one literal word
+var shellSafeValue = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// InterpolateShellWithError interpolates a shell command, escaping every
+// interpolated value for the quotes around it
+func (i *Interpolator) InterpolateShellWithError(command string, ctx Context) (string, error) {
+	call := *i
+	call.builtinErrors = nil
+	call.shellEscape = true
+	return call.interpolate(command, ctx)
+}
+
+// expandShellInterpolations expands each {...} placeholder of command fully,
+// then escapes the value for the quoting context the placeholder is in.
+// Values are inserted once, so a value containing braces is never expanded
+// again after it was quoted.
+func (i *Interpolator) expandShellInterpolations(command string, ctx Context, undefinedVars *[]string) (string, error) {
+	var b strings.Builder
+	state := unquoted
+	pos := 0
+	for pos < len(command) {
+		begin, end, ok := findBalancedInterpolationSpan(command, pos)
+		if !ok {
+			b.WriteString(command[pos:])
+			break
+		}
+		state = advanceQuoteState(state, command[pos:begin])
+		b.WriteString(command[pos:begin])
+		pos = end
+
+		match := command[begin:end]
+		content, raw := cutRawModifier(match[1 : len(match)-1])
+		placeholder := "{" + content + "}"
+		value, err := i.expandDrunBraceInterpolations(placeholder, ctx, undefinedVars)
+		if err != nil {
+			return command, err
+		}
+		if value == placeholder {
+			// Not a drun placeholder (or undefined): keep the shell text as written
+			b.WriteString(match)
+			state = advanceQuoteState(state, match)
+			continue
+		}
+		if !raw {
+			value = escapeShellValue(value, state)
+		}
+		b.WriteString(value)
+	}
+	return b.String(), nil
+}
+
+// cutRawModifier removes a trailing `| raw` from placeholder content and
+// reports whether it was present
+func cutRawModifier(content string) (string, bool) {
+	trimmed := strings.TrimSpace(content)
+	idx := strings.LastIndex(trimmed, "|")
+	if idx < 0 || strings.TrimSpace(trimmed[idx+1:]) != "raw" {
+		return content, false
+	}
+	return strings.TrimSpace(trimmed[:idx]), true
+}
+
+// advanceQuoteState returns the quoting context after the shell text s,
+// starting in state
+func advanceQuoteState(state quoteState, s string) quoteState {
+	for k := 0; k < len(s); k++ {
+		c := s[k]
+		switch state {
+		case unquoted:
+			switch c {
+			case '\\':
+				k++
+			case '\'':
+				state = singleQuoted
+			case '"':
+				state = doubleQuoted
+			}
+		case singleQuoted:
+			if c == '\'' {
+				state = unquoted
+			}
+		case doubleQuoted:
+			switch c {
+			case '\\':
+				k++
+			case '"':
+				state = unquoted
+			}
+		}
+	}
+	return state
+}
+
+// escapeShellValue makes value a literal in the given quoting context
+func escapeShellValue(value string, state quoteState) string {
+	switch state {
+	case singleQuoted:
+		// Close the quotes, add an escaped quote and reopen them
+		return strings.ReplaceAll(value, "'", `'\''`)
+	case doubleQuoted:
+		return doubleQuoteEscaper.Replace(value)
+	default:
+		return ShellQuote(value)
+	}
+}
+
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+
+// ShellQuote returns value as a single POSIX shell word. Values made only of
+// safe characters are returned unchanged; others are single-quoted.
+func ShellQuote(value string) string {
+	if value == "" {
+		return "''"
+	}
+	if shellSafeValue.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Output Line Filters
+// This file implements the line filters of shell statements and captures:
+// `keeping lines containing "ERROR"`, `dropping lines matching "^\s*$"`.
+// Filters run in drun rather than through grep, so they behave the same on
+// every platform and shell.
+
+// lineFilter returns the function keeping the output lines that pass every
+// filter, in order, with the patterns interpolated; nil without filters
+func (e *Engine) lineFilter(filters []statement.LineFilter, ctx *ExecutionContext) (func(line string) bool, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	checks := make([]func(line string) bool, len(filters))
+	for i, filter := range filters {
+		pattern := e.interpolateVariables(filter.Pattern, ctx)
+		matches := func(line string) bool { return strings.Contains(line, pattern) }
+		if filter.Regexp {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q in line filter: %v", pattern, err)
+			}
+			matches = re.MatchString
+		}
+		keep := filter.Keep
+		checks[i] = func(line string) bool { return matches(line) == keep }
+	}
+	return func(line string) bool {
+		for _, check := range checks {
+			if !check(line) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// writeLineFiltersDryRun shows the line filters a command's output would go
+// through in dry-run mode
+func (e *Engine) writeLineFiltersDryRun(filters []statement.LineFilter, ctx *ExecutionContext) {
+	for _, filter := range filters {
+		verb, match := "keep", "containing"
+		if !filter.Keep {
+			verb = "drop"
+		}
+		if filter.Regexp {
+			match = "matching"
+		}
+		_, _ = fmt.Fprintf(e.out(ctx), "[DRY RUN] Would %s lines %s %q\n", verb, match, e.interpolateVariables(filter.Pattern, ctx))
+	}
+}
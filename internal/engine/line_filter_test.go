@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLineFiltersReduceShownAndCapturedOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses printf")
+	}
+	program, err := ParseString(`version: 2.0
+
+task "images":
+  given $app defaults to "myapp"
+  run "printf 'REPOSITORY TAG\nmyapp 1.0\nother 2.0\nmyapp-db 3.1\n'" filtering lines matching "^{$app} "
+  capture from shell "printf 'INFO start\nERROR disk full\nINFO retry\nERROR timeout\n'" keeping lines containing "ERROR" as $errors
+  info "errors: {$errors}"
+  capture lines of "printf 'a.go\nb_test.go\nc.go\n'" as $files dropping lines containing "_test"
+  for each $file in $files:
+    info "file {$file}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "images"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	out := buf.String()
+
+	for _, want := range []string{"myapp 1.0", "errors: ERROR disk full\nERROR timeout", "file a.go", "file c.go"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"REPOSITORY", "other 2.0", "myapp-db", "INFO", "b_test.go"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("expected %q to be filtered out:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestLineFilterWithInvalidInterpolatedRegexpFails(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "grep":
+  given $pattern defaults to "("
+  run "echo hello" filtering lines matching "{$pattern}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "grep")
+	if err == nil || !strings.Contains(err.Error(), `invalid regular expression "(" in line filter`) {
+		t.Fatalf("expected an invalid regular expression error, got %v\n%s", err, buf.String())
+	}
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/locks"
+)
+
+func TestLockHoldsTheLockWhileTheBodyRuns(t *testing.T) {
+	dir := t.TempDir()
+	input := `version: 2.0
+
+project "shop":
+  set lock backend to "file://` + filepath.ToSlash(dir) + `"
+
+task "deploy":
+  given $environment defaults to "staging"
+  lock "deploy-{$environment}":
+    info "deploying {$environment}"
+  info "done"
+
+task "migrate":
+  lock "migrations" waiting up to 1 second:
+    info "not reached"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.ExecuteWithParams(program, "deploy", map[string]string{"environment": "prod"}); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	for _, want := range []string{"Acquired lock 'deploy-prod'", "deploying prod", "Released lock 'deploy-prod'", "done"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in the output:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "deploy-prod.lock")); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+
+	if acquired, _, err := locks.NewFileBackend(dir).TryAcquire(context.Background(), "migrations", "bob@ci", time.Minute); err != nil || !acquired {
+		t.Fatalf("TryAcquire() = %v, %v", acquired, err)
+	}
+	buf.Reset()
+	err = eng.Execute(program, "migrate")
+	if err == nil || !strings.Contains(err.Error(), "timed out waiting for lock 'migrations' held by bob@ci") {
+		t.Fatalf("expected a timeout naming the holder, got %v\n%s", err, buf.String())
+	}
+	if output := buf.String(); !strings.Contains(output, "Waiting for lock 'migrations' held by bob@ci") || strings.Contains(output, "not reached") {
+		t.Errorf("unexpected output while the lock is held:\n%s", output)
+	}
+}
+
+func TestLockDryRunDoesNotAcquire(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "deploy":
+  lock "deploy" using "redis://127.0.0.1:1":
+    info "deploying"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	eng.SetDryRun(true)
+	if err := eng.Execute(program, "deploy"); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, buf.String())
+	}
+	if output := buf.String(); !strings.Contains(output, "[DRY RUN] Would hold lock 'deploy' in redis://127.0.0.1:1") || !strings.Contains(output, "deploying") {
+		t.Errorf("unexpected dry run output:\n%s", output)
+	}
+}
+
+func TestLockWaitStopsAtTheMaximumRunDuration(t *testing.T) {
+	dir := t.TempDir()
+	program, err := ParseString(`version: 2.0
+
+project "shop":
+  set lock backend to "file://` + filepath.ToSlash(dir) + `"
+
+task "migrate":
+  lock "migrations":
+    info "not reached"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if acquired, _, err := locks.NewFileBackend(dir).TryAcquire(context.Background(), "migrations", "bob@ci", time.Minute); err != nil || !acquired {
+		t.Fatalf("TryAcquire() = %v, %v", acquired, err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithMaxDuration(300*time.Millisecond))
+	started := time.Now()
+	err = eng.Execute(program, "migrate")
+	if !errors.Is(err, ErrMaxDuration) {
+		t.Fatalf("expected ErrMaxDuration, got %v\n%s", err, buf.String())
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Fatalf("expected the wait to stop at the maximum duration, the run took %s", elapsed)
+	}
+	if strings.Contains(buf.String(), "not reached") {
+		t.Errorf("the body ran without the lock:\n%s", buf.String())
+	}
+}
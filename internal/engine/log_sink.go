@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Domain: Log Sinks
+// This file duplicates all engine output to a structured sink, written as
+// JSON lines, with `set log sink to "..."`:
+// - "file:./drun.log" appends to a file
+// - "syslog:" writes to the local syslog, "syslog:udp://host:514" to a remote one
+// - "http://collector/..." posts batches of lines to a collector
+// Console colors and styles do not reach the sink, and secrets are masked
+// before either sees a line.
+
+// logSinkSetting is the project setting key written by `set log sink to "..."`
+const logSinkSetting = "log_sink"
+
+// httpSinkBatchSize is how many lines an HTTP sink collects before posting them
+const httpSinkBatchSize = 100
+
+// ansiEscape matches terminal color and style sequences
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// logRecord is one line of engine output as written to a sink
+type logRecord struct {
+	Time    string `json:"time"`
+	Project string `json:"project,omitempty"`
+	Message string `json:"message"`
+}
+
+// logSink receives encoded log records
+type logSink interface {
+	write(record []byte) error
+	flush() error
+}
+
+// logSinkWriter splits engine output into lines and writes each one to the
+// sink as a JSON record. Sink failures never fail the run; the first one is
+// reported when the sink is flushed.
+type logSinkWriter struct {
+	mu      sync.Mutex
+	spec    string
+	project string
+	sink    logSink
+	partial []byte
+	err     error
+}
+
+func (w *logSinkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.record(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// record writes line to the sink
+func (w *logSinkWriter) record(line string) {
+	line = strings.TrimRight(ansiEscape.ReplaceAllString(line, ""), "\r")
+	data, err := json.Marshal(logRecord{
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Project: w.project,
+		Message: line,
+	})
+	if err == nil {
+		err = w.sink.write(append(data, '\n'))
+	}
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// flush writes a trailing partial line and flushes the sink. It returns the
+// first error since the last flush.
+func (w *logSinkWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.record(string(w.partial))
+		w.partial = nil
+	}
+	if err := w.sink.flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+	err := w.err
+	w.err = nil
+	return err
+}
+
+// installLogSink opens the project's log sink, if it declares one, and
+// duplicates engine output into it. An engine keeps the first sink it opens
+// for later runs.
+func (e *Engine) installLogSink(projectCtx *ProjectContext) error {
+	if projectCtx == nil || e.logSink != nil {
+		return nil
+	}
+	spec, ok := projectCtx.Settings[logSinkSetting]
+	if !ok {
+		return nil
+	}
+	sink, err := openLogSink(spec)
+	if err != nil {
+		return fmt.Errorf("set log sink: %w", err)
+	}
+	e.logSink = &logSinkWriter{spec: spec, project: projectCtx.Name, sink: sink}
+	e.credentials.teeOutput(e.logSink)
+	return nil
+}
+
+// flushLogSink flushes the log sink at the end of a run, warning on the
+// console when the sink could not be written
+func (e *Engine) flushLogSink() {
+	if e.logSink == nil {
+		return
+	}
+	if err := e.logSink.flush(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: log sink %s: %v\n", e.logSink.spec, err)
+	}
+}
+
+// openLogSink opens the sink described by spec. Relative file paths are
+// resolved from the current directory, like other file paths.
+func openLogSink(spec string) (logSink, error) {
+	kind, target, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "file":
+		if target == "" {
+			return nil, fmt.Errorf("file sink needs a path, such as \"file:./drun.log\"")
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		// #nosec G302,G304 -- the log file is named by the drun file author
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &writerSink{w: file}, nil
+	case "syslog":
+		return openSyslogSink(target)
+	case "http", "https":
+		return &httpSink{url: spec, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink %q: expected \"file:path\", \"syslog:\" or an http(s) URL", spec)
+	}
+}
+
+// writerSink writes records to a file
+type writerSink struct {
+	w io.Writer
+}
+
+func (s *writerSink) write(record []byte) error {
+	_, err := s.w.Write(record)
+	return err
+}
+
+func (s *writerSink) flush() error {
+	if file, ok := s.w.(*os.File); ok {
+		return file.Sync()
+	}
+	return nil
+}
+
+// httpSink posts records in batches, as newline-delimited JSON
+type httpSink struct {
+	url     string
+	client  *http.Client
+	pending bytes.Buffer
+	lines   int
+}
+
+func (s *httpSink) write(record []byte) error {
+	s.pending.Write(record)
+	s.lines++
+	if s.lines >= httpSinkBatchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *httpSink) flush() error {
+	if s.lines == 0 {
+		return nil
+	}
+	body := bytes.NewReader(s.pending.Bytes())
+	s.pending.Reset()
+	s.lines = 0
+
+	resp, err := s.client.Post(s.url, "application/x-ndjson", body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"log/syslog"
+	"net/url"
+)
+
+// syslogSink writes each record as a syslog message
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// openSyslogSink connects to the local syslog, or to the server of an
+// address such as "udp://logs:514"
+func openSyslogSink(address string) (logSink, error) {
+	var w *syslog.Writer
+	var err error
+	if address == "" {
+		w, err = syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "drun")
+	} else {
+		u, parseErr := url.Parse(address)
+		if parseErr != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog address %q: expected \"udp://host:port\" or \"tcp://host:port\"", address)
+		}
+		w, err = syslog.Dial(u.Scheme, u.Host, syslog.LOG_INFO|syslog.LOG_USER, "drun")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to syslog: %w", err)
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) write(record []byte) error {
+	return s.w.Info(string(bytes.TrimSuffix(record, []byte("\n"))))
+}
+
+func (s *syslogSink) flush() error {
+	return nil
+}
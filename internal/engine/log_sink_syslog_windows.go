@@ -0,0 +1,10 @@
+//go:build windows
+
+package engine
+
+import "errors"
+
+// openSyslogSink reports that Windows has no syslog
+func openSyslogSink(string) (logSink, error) {
+	return nil, errors.New("syslog sinks are not supported on Windows")
+}
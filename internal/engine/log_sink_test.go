@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// readLogRecords decodes the JSON lines in data
+func readLogRecords(t *testing.T, data []byte) []logRecord {
+	t.Helper()
+	var records []logRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record logRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func hasLogMessage(records []logRecord, message string) bool {
+	for _, record := range records {
+		if record.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLogSinkWritesJSONLinesToAFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "logs", "drun.log")
+	program, err := ParseString(`version: 2.0
+
+project "sinks":
+  set log sink to "file:` + filepath.ToSlash(logFile) + `"
+
+task "build":
+  info "building"
+  run "echo from-shell"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "building") {
+		t.Fatalf("console output should be unchanged:\n%s", buf.String())
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := readLogRecords(t, data)
+	if !hasLogMessage(records, "ℹ️  building") || !hasLogMessage(records, "from-shell") {
+		t.Fatalf("expected every output line in the sink, got:\n%s", data)
+	}
+	for _, record := range records {
+		if record.Project != "sinks" || record.Time == "" {
+			t.Fatalf("expected the project and time on every record, got %+v", record)
+		}
+	}
+}
+
+func TestLogSinkPostsBatchesAndStripsColors(t *testing.T) {
+	var mu sync.Mutex
+	var received bytes.Buffer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = io.Copy(&received, r.Body)
+	}))
+	t.Cleanup(server.Close)
+
+	writer := &logSinkWriter{project: "sinks", sink: &httpSink{url: server.URL, client: server.Client()}}
+	_, _ = writer.Write([]byte("\x1b[32mgreen\x1b[0m line\npartial"))
+	if err := writer.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	records := readLogRecords(t, received.Bytes())
+	if len(records) != 2 || records[0].Message != "green line" || records[1].Message != "partial" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+}
+
+func TestLogSinkRejectsUnknownSinks(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+project "sinks":
+  set log sink to "kafka:topic"
+
+task "build":
+  info "never"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	err = NewEngine(&bytes.Buffer{}).Execute(program, "build")
+	if err == nil || !strings.Contains(err.Error(), "set log sink") {
+		t.Fatalf("expected an unknown sink error, got %v", err)
+	}
+}
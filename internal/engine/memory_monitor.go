@@ -7,6 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -25,6 +28,31 @@ type MemoryMonitor struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	warningLogged bool
+
+	loopsMu sync.Mutex
+	loops   map[*LoopProgress]struct{} // while loops currently running
+}
+
+// LoopProgress is a running loop as the monitor reports it when memory runs
+// out: what the loop is and how many iterations it has done
+type LoopProgress struct {
+	Description string
+	iterations  atomic.Int64
+}
+
+// Iterate records the start of another iteration
+func (l *LoopProgress) Iterate() {
+	if l != nil {
+		l.iterations.Add(1)
+	}
+}
+
+// Iterations returns how many iterations have started
+func (l *LoopProgress) Iterations() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.iterations.Load()
 }
 
 // MemoryStats holds memory usage information
@@ -41,6 +69,7 @@ type DiagnosticDump struct {
 	MemoryStats MemoryStats            `json:"memory_stats"`
 	Program     *ast.Program           `json:"program"`
 	RuntimeInfo map[string]interface{} `json:"runtime_info"`
+	ActiveLoops []string               `json:"active_loops,omitempty"`
 }
 
 // NewMemoryMonitor creates a new memory monitor
@@ -50,9 +79,39 @@ func NewMemoryMonitor(program *ast.Program) *MemoryMonitor {
 		program: program,
 		ctx:     ctx,
 		cancel:  cancel,
+		loops:   make(map[*LoopProgress]struct{}),
 	}
 }
 
+// TrackLoop registers a running loop until its returned stop function is
+// called; a nil monitor tracks nothing
+func (m *MemoryMonitor) TrackLoop(description string) (*LoopProgress, func()) {
+	progress := &LoopProgress{Description: description}
+	if m == nil {
+		return progress, func() {}
+	}
+	m.loopsMu.Lock()
+	m.loops[progress] = struct{}{}
+	m.loopsMu.Unlock()
+	return progress, func() {
+		m.loopsMu.Lock()
+		delete(m.loops, progress)
+		m.loopsMu.Unlock()
+	}
+}
+
+// activeLoops describes the running loops, sorted
+func (m *MemoryMonitor) activeLoops() []string {
+	m.loopsMu.Lock()
+	defer m.loopsMu.Unlock()
+	var loops []string
+	for progress := range m.loops {
+		loops = append(loops, fmt.Sprintf("%s (iteration %d)", progress.Description, progress.Iterations()))
+	}
+	sort.Strings(loops)
+	return loops
+}
+
 // Start begins monitoring memory usage
 func (m *MemoryMonitor) Start() {
 	go m.monitorLoop()
@@ -90,7 +149,14 @@ func (m *MemoryMonitor) checkMemory() {
 		m.dumpDiagnostics(mem)
 		fmt.Fprintf(os.Stderr, "\n❌  CRITICAL: Memory usage exceeded %d MB (current: %d MB)\n", CriticalThresholdMB, allocMB)
 		fmt.Fprintf(os.Stderr, "Diagnostic information dumped to drun-crash-dump.json\n")
-		fmt.Fprintf(os.Stderr, "This likely indicates an infinite loop or runaway recursion.\n")
+		if loops := m.activeLoops(); len(loops) > 0 {
+			fmt.Fprintf(os.Stderr, "This likely indicates a runaway loop. Loops running:\n")
+			for _, loop := range loops {
+				fmt.Fprintf(os.Stderr, "  - %s\n", loop)
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "This likely indicates an infinite loop or runaway recursion.\n")
+		}
 		os.Exit(1)
 	}
 
@@ -121,6 +187,7 @@ func (m *MemoryMonitor) dumpDiagnostics(mem runtime.MemStats) {
 			"os":            runtime.GOOS,
 			"arch":          runtime.GOARCH,
 		},
+		ActiveLoops: m.activeLoops(),
 	}
 
 	// Create dump file
@@ -166,6 +233,12 @@ func (m *MemoryMonitor) dumpDiagnostics(mem runtime.MemStats) {
 		if m.program.Project != nil {
 			_, _ = fmt.Fprintf(f, "  Project: %s\n", m.program.Project.Name)
 		}
+		if len(dump.ActiveLoops) > 0 {
+			_, _ = fmt.Fprintf(f, "\nLoops Running:\n")
+			for _, loop := range dump.ActiveLoops {
+				_, _ = fmt.Fprintf(f, "  %s\n", loop)
+			}
+		}
 		_, _ = fmt.Fprintf(f, "\nFull details in: %s\n", filename)
 	}
 
@@ -67,3 +67,29 @@ func TestMemoryStatsCreation(t *testing.T) {
 	t.Logf("Raw memory: Alloc=%d bytes, TotalAlloc=%d bytes, Sys=%d bytes",
 		mem.Alloc, mem.TotalAlloc, mem.Sys)
 }
+
+func TestMemoryMonitorTracksLoops(t *testing.T) {
+	monitor := NewMemoryMonitor(&ast.Program{})
+
+	progress, stop := monitor.TrackLoop("while {$status} != ready in task 'poll'")
+	progress.Iterate()
+	progress.Iterate()
+
+	loops := monitor.activeLoops()
+	if len(loops) != 1 || loops[0] != "while {$status} != ready in task 'poll' (iteration 2)" {
+		t.Fatalf("unexpected active loops: %v", loops)
+	}
+
+	stop()
+	if loops := monitor.activeLoops(); len(loops) != 0 {
+		t.Fatalf("expected no active loops after stop, got %v", loops)
+	}
+
+	var none *MemoryMonitor
+	untracked, stopUntracked := none.TrackLoop("while true")
+	untracked.Iterate()
+	stopUntracked()
+	if untracked.Iterations() != 1 {
+		t.Fatalf("expected a nil monitor to still count iterations, got %d", untracked.Iterations())
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const multiTargetProgram = `version: 2.0
+
+project "myapp":
+  on drun setup:
+    info "setup hook"
+
+  before any task:
+    info "before {$globals.current_task}"
+
+  on drun teardown:
+    info "teardown hook"
+
+task "prepare":
+  info "preparing"
+
+task "build":
+  depends on prepare
+  given $target defaults to "local"
+  info "building for {$target}"
+
+task "test":
+  depends on prepare
+  info "testing"
+
+task "package":
+  depends on build
+  info "packaging"
+`
+
+func TestExecuteTargetsRunsTasksInOrderSharingOneRun(t *testing.T) {
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+
+	program, err := ParseStringWithFilename(multiTargetProgram, "")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	targets := []TaskTarget{
+		{Name: "build", Params: map[string]string{"target": "linux"}},
+		{Name: "test", Params: map[string]string{}},
+		{Name: "package", Params: map[string]string{}},
+	}
+	if err := eng.ExecuteTargets(program, targets, "", false); err != nil {
+		t.Fatalf("execution failed: %v\nOutput: %s", err, buf.String())
+	}
+
+	output := buf.String()
+	for _, once := range []string{"setup hook", "teardown hook", "preparing", "building for linux"} {
+		if count := strings.Count(output, once); count != 1 {
+			t.Errorf("expected %q once, got %d times\nOutput: %s", once, count, output)
+		}
+	}
+
+	order := []string{"setup hook", "preparing", "before build", "building for linux", "before test", "testing", "before package", "packaging", "teardown hook"}
+	last := -1
+	for _, want := range order {
+		idx := strings.Index(output, want)
+		if idx <= last {
+			t.Fatalf("expected %q after previous output\nOutput: %s", want, output)
+		}
+		last = idx
+	}
+}
+
+func TestExecuteTargetsParallelRunsDependenciesFirst(t *testing.T) {
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+
+	program, err := ParseStringWithFilename(multiTargetProgram, "")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	targets := []TaskTarget{
+		{Name: "test", Params: map[string]string{}},
+		{Name: "package", Params: map[string]string{}},
+	}
+	if err := eng.ExecuteTargets(program, targets, "", true); err != nil {
+		t.Fatalf("execution failed: %v\nOutput: %s", err, buf.String())
+	}
+
+	output := buf.String()
+	for _, once := range []string{"setup hook", "preparing", "building for local", "testing", "packaging", "teardown hook"} {
+		if count := strings.Count(output, once); count != 1 {
+			t.Errorf("expected %q once, got %d times\nOutput: %s", once, count, output)
+		}
+	}
+	if strings.Index(output, "building for local") > strings.Index(output, "packaging") {
+		t.Errorf("expected dependencies to run before targets\nOutput: %s", output)
+	}
+}
+
+func TestExecuteTargetsReportsParallelFailures(t *testing.T) {
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+
+	program, err := ParseStringWithFilename(`version: 2.0
+
+task "ok":
+  info "fine"
+
+task "broken":
+  fail "broken on purpose"
+`, "")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	targets := []TaskTarget{{Name: "ok"}, {Name: "broken"}}
+	err = eng.ExecuteTargets(program, targets, "", true)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected failure from 'broken', got %v", err)
+	}
+}
@@ -179,3 +179,49 @@ task "test":
 		t.Fatalf("expected output to mention docker daemon not reachable, got:\n%s", output.String())
 	}
 }
+
+func TestDetectionResultsTable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on POSIX executable bits")
+	}
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "terraform"), []byte("#!/bin/sh\necho 'Terraform v1.7.4'\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake terraform: %v", err)
+	}
+	t.Setenv("PATH", tmpDir)
+
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	for _, file := range []string{"package.json", "pnpm-lock.yaml"} {
+		if err := os.WriteFile(file, []byte("{}"), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	input := `version: 2.0
+
+task "test":
+  detect project type
+  detect terraform version
+  detect "docker compose"
+  info "type={detected.project_type} pm={detected.package_manager}"
+  info "terraform={detected.terraform.version} at {detected.terraform.path}"
+  info "compose={detected.docker-compose.available}"
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "test", &buf); err != nil {
+		t.Fatalf("execution failed: %v\n%s", err, buf.String())
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"type=node pm=pnpm",
+		"terraform=1.7.4 at " + filepath.Join(tmpDir, "terraform"),
+		"compose=false",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
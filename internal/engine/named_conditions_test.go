@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNamedConditions(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  condition "is prod" means $environment is "production"
+  condition "is release" means is prod and $tag is not empty
+
+task "deploy":
+  requires $environment
+  given $tag defaults to ""
+  when is prod:
+    info "production rules apply"
+  otherwise:
+    info "relaxed rules apply"
+  if is release:
+    info "publishing release {$tag}"
+  if not is prod and $tag is "rc":
+    info "publishing a candidate"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	tests := []struct {
+		params map[string]string
+		want   []string
+		absent []string
+	}{
+		{map[string]string{"environment": "production", "tag": "v1"}, []string{"production rules apply", "publishing release v1"}, []string{"relaxed", "candidate"}},
+		{map[string]string{"environment": "production"}, []string{"production rules apply"}, []string{"publishing"}},
+		{map[string]string{"environment": "staging", "tag": "rc"}, []string{"relaxed rules apply", "publishing a candidate"}, []string{"publishing release"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := NewEngine(&buf).ExecuteWithParams(program, "deploy", tt.params); err != nil {
+			t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+		}
+		out := buf.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%v: expected %q in output:\n%s", tt.params, want, out)
+			}
+		}
+		for _, absent := range tt.absent {
+			if strings.Contains(out, absent) {
+				t.Errorf("%v: unexpected %q in output:\n%s", tt.params, absent, out)
+			}
+		}
+	}
+}
+
+func TestNamedConditionCyclesAreRejected(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  condition "is prod" means is live and $environment is "production"
+  condition "is live" means not is prod
+
+task "deploy":
+  when is prod:
+    info "prod"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "deploy")
+	if err == nil || !strings.Contains(err.Error(), `condition "is live" refers to itself: is live -> is prod -> is live`) {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/netretry"
+)
+
+// Domain: Network Retries
+// This file retries downloads, HTTP statements, paginated loops and remote
+// include fetches that fail transiently: timeouts, reset connections and
+// 408, 429 or 5xx responses. `set network retries to N` (or the
+// networkRetries user setting) says how often; retries back off with jitter,
+// take from the run's retry budget, and are traced with --verbose.
+
+// networkRetriesSetting is the project setting key written by
+// `set network retries to 3`
+const networkRetriesSetting = "network_retries"
+
+// networkRetryCount returns how often transient network failures are
+// retried: the project's `set network retries`, else the engine default
+func (e *Engine) networkRetryCount(projectCtx *ProjectContext) (int, error) {
+	if projectCtx == nil {
+		return e.networkRetries, nil
+	}
+	raw, ok := projectCtx.Settings[networkRetriesSetting]
+	if !ok {
+		return e.networkRetries, nil
+	}
+	retries, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || retries < 0 {
+		return 0, fmt.Errorf("set network retries: expected a whole number of retries, got %q", raw)
+	}
+	return retries, nil
+}
+
+// networkPolicy returns the retry policy for the requests of what (such as
+// "GET https://example.com") made by a statement running in ctx
+func (e *Engine) networkPolicy(ctx *ExecutionContext, what string) netretry.Policy {
+	policy := netretry.DefaultPolicy()
+	var projectCtx *ProjectContext
+	if ctx != nil {
+		projectCtx = ctx.Project
+	}
+	policy.Retries, _ = e.networkRetryCount(projectCtx) // validated when the run is planned
+	policy.BeforeRetry = func(attempt int, reason string, wait time.Duration) error {
+		if err := e.takeRetry(ctx, what); err != nil {
+			if e.verbose {
+				e.iconf(ctx, "🔁 ", "%s failed (%s); %v\n", what, reason, err)
+			}
+			return err
+		}
+		if e.verbose {
+			e.iconf(ctx, "🔁 ", "%s failed (%s); retrying in %s (attempt %d of %d)\n",
+				what, reason, wait.Round(time.Millisecond), attempt, policy.Retries+1)
+		}
+		return nil
+	}
+	return policy
+}
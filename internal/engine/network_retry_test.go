@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPStatementRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ready"))
+	}))
+	t.Cleanup(server.Close)
+
+	program, err := ParseString(`version: 2.0
+
+project "retries":
+  set network retries to 1
+
+task "smoke":
+  get "` + server.URL + `" expect response status 200
+  info "smoke test passed"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithVerbose(true))
+	if err := eng.Execute(program, "smoke"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	if calls.Load() != 2 {
+		t.Fatalf("expected one retry, got %d requests:\n%s", calls.Load(), out)
+	}
+	for _, want := range []string{
+		"GET " + server.URL + " failed (status 503 Service Unavailable); retrying in",
+		"(attempt 2 of 2)",
+		"smoke test passed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNetworkRetriesCanBeTurnedOff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	program, err := ParseString(`version: 2.0
+
+task "smoke":
+  get "` + server.URL + `" expect response status 200
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithDefaultNetworkRetries(0))
+	if err := eng.Execute(program, "smoke"); err == nil {
+		t.Fatalf("expected the 502 to fail the task:\n%s", buf.String())
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single request, got %d", calls.Load())
+	}
+}
+
+func TestNetworkRetriesMustBeAWholeNumber(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+project "retries":
+  set network retries to "often"
+
+task "smoke":
+  info "never"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "smoke")
+	if err == nil || !strings.Contains(err.Error(), "set network retries") {
+		t.Fatalf("expected an invalid network retries error, got %v", err)
+	}
+}
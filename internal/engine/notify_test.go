@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type recordedNotification struct {
+	title, message string
+}
+
+func recordNotifications(sent *[]recordedNotification) Option {
+	return WithNotifier(func(title, message string) error {
+		*sent = append(*sent, recordedNotification{title, message})
+		return nil
+	})
+}
+
+func TestNotifyMeWhenDoneNotifiesOnceAfterTheRun(t *testing.T) {
+	input := `version: 2.0
+
+task "prepare":
+  notify me when done
+  info "preparing"
+
+task "release":
+  depends on prepare
+  notify me when done
+  info "releasing"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	var sent []recordedNotification
+	eng := NewEngineWithOptions(WithOutput(&buf), recordNotifications(&sent))
+	if err := eng.Execute(program, "release"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("expected one notification, got %+v", sent)
+	}
+	if sent[0].title != "drun: release finished" || !strings.HasPrefix(sent[0].message, "Completed successfully in ") {
+		t.Errorf("unexpected notification: %+v", sent[0])
+	}
+	if !strings.HasSuffix(buf.String(), "\a") {
+		t.Errorf("expected the terminal bell at the end of the output:\n%q", buf.String())
+	}
+}
+
+func TestNotifyFlagReportsFailures(t *testing.T) {
+	input := `version: 2.0
+
+task "quiet":
+  info "no notification requested"
+
+task "broken":
+  fail "boom"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	var sent []recordedNotification
+	eng := NewEngineWithOptions(WithOutput(&buf), recordNotifications(&sent))
+	if err := eng.Execute(program, "quiet"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if len(sent) != 0 || strings.Contains(buf.String(), "\a") {
+		t.Fatalf("runs without --notify or the statement must not notify: %+v", sent)
+	}
+
+	eng = NewEngineWithOptions(WithOutput(&buf), WithNotify(true), recordNotifications(&sent))
+	if err := eng.Execute(program, "broken"); err == nil {
+		t.Fatal("expected the task to fail")
+	}
+	if len(sent) != 1 || sent[0].title != "drun: broken failed" || !strings.Contains(sent[0].message, "boom") {
+		t.Fatalf("unexpected notifications: %+v", sent)
+	}
+
+	// Dry runs never notify
+	sent = nil
+	eng = NewEngineWithOptions(WithOutput(&buf), WithNotify(true), WithDryRun(true), recordNotifications(&sent))
+	if err := eng.Execute(program, "quiet"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("dry runs must not notify: %+v", sent)
+	}
+}
@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+	"github.com/phillarmonic/drun/v2/internal/shell"
+)
+
+// Domain: Execution Events
+// This file contains the observer interface that lets embedders follow an
+// execution through structured events instead of parsing its output.
+
+// EngineObserver receives structured events while the engine runs tasks.
+// Callbacks run synchronously on the goroutine executing the task, so they
+// should return quickly; with parallel targets or parallel loops they are
+// called concurrently. Embed BaseObserver to implement only some callbacks.
+type EngineObserver interface {
+	OnTaskStart(event TaskEvent)
+	OnTaskEnd(event TaskEvent)
+	OnStatement(event StatementEvent)
+	OnShellStart(event ShellEvent)
+	OnShellEnd(event ShellEvent)
+	OnError(event ErrorEvent)
+}
+
+// OutputObserver is an observer that also receives the output of the run,
+// after secrets are masked, with the task that wrote it (empty for output
+// outside of any task). OnOutput must not keep p.
+type OutputObserver interface {
+	EngineObserver
+	OnOutput(task string, p []byte)
+}
+
+// BaseObserver implements EngineObserver with callbacks that do nothing
+type BaseObserver struct{}
+
+func (BaseObserver) OnTaskStart(TaskEvent)      {}
+func (BaseObserver) OnTaskEnd(TaskEvent)        {}
+func (BaseObserver) OnStatement(StatementEvent) {}
+func (BaseObserver) OnShellStart(ShellEvent)    {}
+func (BaseObserver) OnShellEnd(ShellEvent)      {}
+func (BaseObserver) OnError(ErrorEvent)         {}
+
+// ContextSnapshot is a copy of the execution context when an event fired;
+// later changes to the context do not affect it
+type ContextSnapshot struct {
+	Task       string
+	Namespace  string
+	File       string
+	WorkingDir string
+	Parameters map[string]string
+	Variables  map[string]string
+}
+
+// TaskEvent reports a task starting or ending. On OnTaskEnd, Duration is the
+// time the task body took and Err is nil when it succeeded.
+type TaskEvent struct {
+	Task     string
+	Time     time.Time
+	Duration time.Duration
+	Err      error
+	Context  ContextSnapshot
+}
+
+// StatementEvent reports a statement about to be executed
+type StatementEvent struct {
+	Task      string
+	Statement statement.Statement
+	Kind      statement.StatementType
+	Time      time.Time
+	Context   ContextSnapshot
+}
+
+// ShellEvent reports a shell command starting or ending. On OnShellEnd,
+// Result is nil when the command could not be started.
+type ShellEvent struct {
+	Task     string
+	Command  string
+	Time     time.Time
+	Duration time.Duration
+	Result   *shell.Result
+	Err      error
+	Context  ContextSnapshot
+}
+
+// ErrorEvent reports a task failing
+type ErrorEvent struct {
+	Task    string
+	Err     error
+	Time    time.Time
+	Context ContextSnapshot
+}
+
+// observing reports whether any observer is registered, so events and their
+// context snapshots are only built when someone listens
+func (e *Engine) observing() bool {
+	return len(e.observers) > 0
+}
+
+// snapshotContext copies the context for an event, with the secrets of the
+// run masked as they are in the output
+func (e *Engine) snapshotContext(ctx *ExecutionContext) ContextSnapshot {
+	if ctx == nil {
+		return ContextSnapshot{}
+	}
+	snapshot := ContextSnapshot{
+		Task:       ctx.CurrentTask,
+		Namespace:  ctx.CurrentNamespace,
+		File:       ctx.CurrentFile,
+		WorkingDir: ctx.WorkingDir,
+		Parameters: make(map[string]string, len(ctx.Parameters)),
+		Variables:  make(map[string]string, len(ctx.Variables)),
+	}
+	for name, value := range ctx.Parameters {
+		if value != nil {
+			snapshot.Parameters[name] = e.credentials.secrets.mask(value.AsString())
+		}
+	}
+	for name, value := range ctx.Variables {
+		snapshot.Variables[name] = e.credentials.secrets.mask(value)
+	}
+	return snapshot
+}
+
+func (e *Engine) notifyTaskStart(task string, ctx *ExecutionContext) time.Time {
+	start := time.Now()
+	if e.observing() {
+		event := TaskEvent{Task: task, Time: start, Context: e.snapshotContext(ctx)}
+		for _, observer := range e.observers {
+			observer.OnTaskStart(event)
+		}
+	}
+	return start
+}
+
+func (e *Engine) notifyTaskEnd(task string, start time.Time, err error, ctx *ExecutionContext) {
+	if !e.observing() {
+		return
+	}
+	now := time.Now()
+	snapshot := e.snapshotContext(ctx)
+	if err != nil {
+		for _, observer := range e.observers {
+			observer.OnError(ErrorEvent{Task: task, Err: err, Time: now, Context: snapshot})
+		}
+	}
+	event := TaskEvent{Task: task, Time: now, Duration: now.Sub(start), Err: err, Context: snapshot}
+	for _, observer := range e.observers {
+		observer.OnTaskEnd(event)
+	}
+}
+
+func (e *Engine) notifyStatement(stmt statement.Statement, ctx *ExecutionContext) {
+	if !e.observing() {
+		return
+	}
+	event := StatementEvent{Statement: stmt, Kind: stmt.Type(), Time: time.Now(), Context: e.snapshotContext(ctx)}
+	if ctx != nil {
+		event.Task = ctx.CurrentTask
+	}
+	for _, observer := range e.observers {
+		observer.OnStatement(event)
+	}
+}
+
+func (e *Engine) notifyShellStart(command string, ctx *ExecutionContext) time.Time {
+	start := time.Now()
+	if e.observing() {
+		event := ShellEvent{Command: e.credentials.secrets.mask(command), Time: start, Context: e.snapshotContext(ctx)}
+		if ctx != nil {
+			event.Task = ctx.CurrentTask
+		}
+		for _, observer := range e.observers {
+			observer.OnShellStart(event)
+		}
+	}
+	return start
+}
+
+func (e *Engine) notifyShellEnd(command string, start time.Time, result *shell.Result, err error, ctx *ExecutionContext) {
+	if !e.observing() {
+		return
+	}
+	now := time.Now()
+	event := ShellEvent{Command: e.credentials.secrets.mask(command), Time: now, Duration: now.Sub(start), Result: result, Err: err, Context: e.snapshotContext(ctx)}
+	if ctx != nil {
+		event.Task = ctx.CurrentTask
+	}
+	for _, observer := range e.observers {
+		observer.OnShellEnd(event)
+	}
+}
@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// HTMLObserver records a run and writes it as a standalone HTML page: a
+// timeline of the tasks, the parameters of each task, and the output of every
+// statement in a collapsible section, with failures highlighted. It is meant
+// as a CI artifact for readers without a terminal. Output is recorded with
+// the task that wrote it, so tasks running in parallel keep their own logs.
+type HTMLObserver struct {
+	mu    sync.Mutex
+	start time.Time
+	tasks []*reportTask
+	open  map[string][]*reportTask // running tasks by name, innermost last
+	sinks map[string]*bytes.Buffer // where the output of a task goes: its running statement's log
+	log   bytes.Buffer             // output outside of any task
+}
+
+// reportTask is one run of a task
+type reportTask struct {
+	name       string
+	start, end time.Time
+	err        error
+	parameters map[string]string
+	statements []*reportStatement
+	log        bytes.Buffer // output before the first statement
+}
+
+// reportStatement is one executed statement and the output it wrote
+type reportStatement struct {
+	label  string
+	start  time.Time
+	failed bool
+	log    bytes.Buffer
+}
+
+// Limits that keep the report of long runs readable and small
+const (
+	maxReportStatements = 500       // per task; later output joins the last statement
+	maxReportLog        = 256 << 10 // bytes of output per statement
+)
+
+// NewHTMLObserver returns an observer that records a run for WriteHTML
+func NewHTMLObserver() *HTMLObserver {
+	return &HTMLObserver{start: time.Now(), open: make(map[string][]*reportTask), sinks: make(map[string]*bytes.Buffer)}
+}
+
+// OnOutput records output of the run, after secrets are masked
+func (o *HTMLObserver) OnOutput(task string, p []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	sink := o.sinks[task]
+	if sink == nil {
+		sink = &o.log
+	}
+	if room := maxReportLog - sink.Len(); room > 0 {
+		sink.Write(p[:min(len(p), room)])
+		if len(p) > room {
+			sink.WriteString("\n… output truncated\n")
+		}
+	}
+}
+
+func (o *HTMLObserver) OnTaskStart(event TaskEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	task := &reportTask{name: event.Task, start: event.Time, parameters: event.Context.Parameters}
+	o.tasks = append(o.tasks, task)
+	o.open[event.Task] = append(o.open[event.Task], task)
+	o.sinks[event.Task] = &task.log
+}
+
+func (o *HTMLObserver) OnTaskEnd(event TaskEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	running := o.open[event.Task]
+	if len(running) == 0 {
+		return
+	}
+	task := running[len(running)-1]
+	o.open[event.Task] = running[:len(running)-1]
+	task.end = event.Time
+	task.err = event.Err
+	if event.Err != nil && len(task.statements) > 0 {
+		task.statements[len(task.statements)-1].failed = true
+	}
+	delete(o.sinks, event.Task)
+	if outer := o.open[event.Task]; len(outer) > 0 {
+		o.sinks[event.Task] = outer[len(outer)-1].sink()
+	}
+}
+
+// sink returns where the task's output goes: the log of its latest statement
+func (t *reportTask) sink() *bytes.Buffer {
+	if len(t.statements) == 0 {
+		return &t.log
+	}
+	return &t.statements[len(t.statements)-1].log
+}
+
+// running returns the run of the named task in progress; statements of
+// called tasks, which have no task events, belong to the latest running task
+func (o *HTMLObserver) running(name string) *reportTask {
+	if running := o.open[name]; len(running) > 0 {
+		return running[len(running)-1]
+	}
+	var latest *reportTask
+	for _, task := range o.tasks {
+		if task.end.IsZero() {
+			latest = task
+		}
+	}
+	return latest
+}
+
+func (o *HTMLObserver) OnStatement(event StatementEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	task := o.running(event.Task)
+	if task == nil {
+		return
+	}
+	if len(task.statements) >= maxReportStatements {
+		o.sinks[event.Task] = task.sink()
+		return
+	}
+	label := describeStatement(event.Statement)
+	if event.Task != task.name && event.Task != "" {
+		label = event.Task + ": " + label
+	}
+	stmt := &reportStatement{label: label, start: event.Time}
+	task.statements = append(task.statements, stmt)
+	o.sinks[event.Task] = &stmt.log
+}
+
+func (o *HTMLObserver) OnShellStart(ShellEvent) {}
+
+func (o *HTMLObserver) OnShellEnd(event ShellEvent) {
+	if event.Err == nil && (event.Result == nil || event.Result.ExitCode == 0) {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if task := o.running(event.Task); task != nil && len(task.statements) > 0 {
+		task.statements[len(task.statements)-1].failed = true
+	}
+}
+
+func (o *HTMLObserver) OnError(ErrorEvent) {}
+
+// describeStatement returns a one-line label for a statement
+func describeStatement(stmt statement.Statement) string {
+	label := string(stmt.Type())
+	switch s := stmt.(type) {
+	case *statement.Shell:
+		command := s.Command
+		if command == "" && len(s.Commands) > 0 {
+			command = strings.Join(s.Commands, "; ")
+		}
+		label = s.Action + " " + command
+	case *statement.Action:
+		label = s.ActionType + " " + s.Message
+	case *statement.TaskCall:
+		label = "call task " + s.TaskName
+	}
+	if first, _, multiline := strings.Cut(label, "\n"); multiline {
+		label = first + " …"
+	}
+	return label
+}
+
+// htmlReport is the data of the report template
+type htmlReport struct {
+	Title     string
+	Generated string
+	Duration  string
+	Failed    bool
+	Tasks     []htmlReportTask
+	Log       string
+}
+
+type htmlReportTask struct {
+	Name       string
+	Duration   string
+	Error      string
+	Failed     bool
+	Running    bool
+	Offset     string // left edge of the timeline bar, in percent
+	Width      string // length of the timeline bar, in percent
+	Parameters []htmlReportParameter
+	Statements []htmlReportStatement
+}
+
+type htmlReportParameter struct {
+	Name, Value string
+}
+
+type htmlReportStatement struct {
+	Label  string
+	At     string // time since the task started
+	Log    string
+	Failed bool
+}
+
+// WriteHTML writes the report of everything recorded so far to w
+func (o *HTMLObserver) WriteHTML(w io.Writer, title string) error {
+	o.mu.Lock()
+	now := time.Now()
+	total := now.Sub(o.start)
+	report := htmlReport{
+		Title:     title,
+		Generated: now.Format(time.RFC1123),
+		Duration:  formatReportDuration(total),
+		Log:       cleanReportLog(o.log.String()),
+	}
+	for _, task := range o.tasks {
+		end := task.end
+		if end.IsZero() {
+			end = now
+		}
+		view := htmlReportTask{
+			Name:     task.name,
+			Duration: formatReportDuration(end.Sub(task.start)),
+			Failed:   task.err != nil,
+			Running:  task.end.IsZero(),
+			Offset:   reportPercent(task.start.Sub(o.start), total),
+			Width:    reportPercent(max(end.Sub(task.start), total/200), total),
+		}
+		if task.err != nil {
+			view.Error = task.err.Error()
+			report.Failed = true
+		}
+		for name, value := range task.parameters {
+			view.Parameters = append(view.Parameters, htmlReportParameter{Name: name, Value: reportParameterValue(name, value)})
+		}
+		sort.Slice(view.Parameters, func(i, j int) bool { return view.Parameters[i].Name < view.Parameters[j].Name })
+		if preamble := cleanReportLog(task.log.String()); preamble != "" {
+			view.Statements = append(view.Statements, htmlReportStatement{Label: "task output", At: "+0s", Log: preamble})
+		}
+		for _, stmt := range task.statements {
+			view.Statements = append(view.Statements, htmlReportStatement{
+				Label:  stmt.label,
+				At:     "+" + formatReportDuration(stmt.start.Sub(task.start)),
+				Log:    cleanReportLog(stmt.log.String()),
+				Failed: stmt.failed,
+			})
+		}
+		report.Tasks = append(report.Tasks, view)
+	}
+	o.mu.Unlock()
+
+	return htmlReportTemplate.Execute(w, report)
+}
+
+// reportParameterValue hides the values of parameters that look sensitive
+func reportParameterValue(name, value string) string {
+	lower := strings.ToLower(name)
+	for _, pattern := range sensitiveVariablePatterns {
+		if strings.Contains(lower, pattern) {
+			return "***"
+		}
+	}
+	return value
+}
+
+func cleanReportLog(log string) string {
+	return strings.TrimRight(ansiEscape.ReplaceAllString(log, ""), "\n")
+}
+
+func reportPercent(part, total time.Duration) string {
+	if total <= 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.2f", min(100, 100*float64(part)/float64(total)))
+}
+
+func formatReportDuration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(100 * time.Millisecond).String()
+	}
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; background: #fff; }
+  h1 { font-size: 1.5rem; margin-bottom: .25rem; }
+  h2 { font-size: 1.15rem; margin: 2rem 0 .5rem; }
+  .meta { color: #59636e; margin-bottom: 1.5rem; }
+  .badge { display: inline-block; padding: .1rem .6rem; border-radius: 1rem; font-size: .85rem; font-weight: 600; color: #fff; background: #1a7f37; }
+  .failed .badge, .badge.failed { background: #cf222e; }
+  .badge.running { background: #9a6700; }
+  .timeline { border: 1px solid #d1d9e0; border-radius: 6px; padding: .75rem; }
+  .row { display: flex; align-items: center; margin: .3rem 0; }
+  .row .name { width: 12rem; flex: none; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+  .row .track { position: relative; flex: 1; height: 1.1rem; background: #f6f8fa; border-radius: 3px; }
+  .row .bar { position: absolute; top: 0; bottom: 0; min-width: 2px; border-radius: 3px; background: #2da44e; }
+  .row.failed .bar { background: #cf222e; }
+  .row .time { width: 6rem; flex: none; text-align: right; color: #59636e; font-variant-numeric: tabular-nums; }
+  section.task { border: 1px solid #d1d9e0; border-left: 4px solid #2da44e; border-radius: 6px; padding: .5rem 1rem 1rem; margin-top: 1rem; }
+  section.task.failed { border-left-color: #cf222e; }
+  .error { color: #cf222e; font-weight: 600; white-space: pre-wrap; }
+  table { border-collapse: collapse; margin: .5rem 0; }
+  th, td { text-align: left; padding: .2rem .8rem .2rem 0; border-bottom: 1px solid #eef1f4; font-size: .9rem; }
+  td code { white-space: pre-wrap; }
+  details { margin: .25rem 0; }
+  summary { cursor: pointer; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .9rem; }
+  summary .at { color: #59636e; margin-right: .5rem; }
+  details.failed summary { color: #cf222e; font-weight: 600; }
+  pre { background: #f6f8fa; padding: .5rem .75rem; border-radius: 6px; overflow-x: auto; font-size: .85rem; }
+  .empty { color: #59636e; font-style: italic; }
+</style>
+</head>
+<body{{if .Failed}} class="failed"{{end}}>
+<h1>{{.Title}}</h1>
+<div class="meta"><span class="badge">{{if .Failed}}failed{{else}}passed{{end}}</span> {{.Duration}} · generated {{.Generated}}</div>
+
+<h2>Timeline</h2>
+<div class="timeline">
+{{- range .Tasks}}
+  <div class="row{{if .Failed}} failed{{end}}"><span class="name" title="{{.Name}}">{{.Name}}</span><span class="track"><span class="bar" style="left: {{.Offset}}%; width: {{.Width}}%"></span></span><span class="time">{{.Duration}}</span></div>
+{{- else}}
+  <p class="empty">No tasks ran.</p>
+{{- end}}
+</div>
+
+<h2>Tasks</h2>
+{{- range .Tasks}}
+<section class="task{{if .Failed}} failed{{end}}">
+  <h3>{{.Name}} {{if .Failed}}<span class="badge failed">failed</span>{{else if .Running}}<span class="badge running">running</span>{{else}}<span class="badge">passed</span>{{end}} <small>{{.Duration}}</small></h3>
+  {{- if .Error}}
+  <p class="error">{{.Error}}</p>
+  {{- end}}
+  {{- if .Parameters}}
+  <table>
+    <tr><th>Parameter</th><th>Value</th></tr>
+    {{- range .Parameters}}
+    <tr><td>{{.Name}}</td><td><code>{{.Value}}</code></td></tr>
+    {{- end}}
+  </table>
+  {{- end}}
+  {{- range .Statements}}
+  <details{{if .Failed}} class="failed" open{{end}}><summary><span class="at">{{.At}}</span>{{.Label}}</summary>{{if .Log}}<pre>{{.Log}}</pre>{{else}}<p class="empty">No output.</p>{{end}}</details>
+  {{- end}}
+</section>
+{{- end}}
+{{- if .Log}}
+
+<h2>Other output</h2>
+<pre>{{.Log}}</pre>
+{{- end}}
+</body>
+</html>
+`))
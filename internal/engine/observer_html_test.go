@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHTMLObserverReportsTasksStatementsAndFailures(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "prepare":
+  info "preparing <assets>"
+  run "echo built"
+
+task "deploy":
+  depends on prepare
+  requires $target
+  requires $api_token
+  run "echo deploying to {$target}; exit 3"
+  info "not reached"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	observer := NewHTMLObserver()
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(observer))
+	err = eng.ExecuteWithParams(program, "deploy", map[string]string{"target": "staging", "api_token": "hunter2"})
+	if err == nil {
+		t.Fatal("expected deploy to fail")
+	}
+
+	var buf bytes.Buffer
+	if err := observer.WriteHTML(&buf, "xdrun deploy"); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	report := buf.String()
+
+	for _, want := range []string{
+		"<title>xdrun deploy</title>",
+		`<body class="failed">`,
+		`<span class="name" title="prepare">prepare</span>`,
+		`<div class="row failed"><span class="name" title="deploy">deploy</span>`,
+		"info preparing &lt;assets&gt;",
+		"preparing &lt;assets&gt;",
+		"<pre>built</pre>",
+		`<details class="failed" open><summary>`,
+		"deploying to staging",
+		"<tr><td>target</td><td><code>staging</code></td></tr>",
+		"<tr><td>api_token</td><td><code>***</code></td></tr>",
+		`<p class="error">task &#39;deploy&#39; failed`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected the report to contain %q:\n%s", want, report)
+		}
+	}
+	for _, unwanted := range []string{"hunter2", "not reached", "\x1b["} {
+		if strings.Contains(report, unwanted) {
+			t.Errorf("expected the report not to contain %q:\n%s", unwanted, report)
+		}
+	}
+}
+
+func TestHTMLObserverReportsPassingRun(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "hello":
+  info "hello"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	observer := NewHTMLObserver()
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(observer))
+	if err := eng.Execute(program, "hello"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := observer.WriteHTML(&buf, "xdrun hello"); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	report := buf.String()
+	if strings.Contains(report, `class="failed"`) || !strings.Contains(report, `<span class="badge">passed</span>`) {
+		t.Fatalf("expected a passing report:\n%s", report)
+	}
+}
+
+func TestHTMLObserverKeepsTheOutputOfParallelTasksApart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	program, err := ParseString(`version: 2.0
+
+task "alpha":
+  run "for i in 1 2 3; do echo alpha-$i; sleep 0.05; done"
+
+task "beta":
+  run "for i in 1 2 3; do echo beta-$i; sleep 0.05; done"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	observer := NewHTMLObserver()
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(observer))
+	if err := eng.ExecuteTargets(program, []TaskTarget{{Name: "alpha"}, {Name: "beta"}}, "", true); err != nil {
+		t.Fatalf("ExecuteTargets() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := observer.WriteHTML(&buf, "xdrun alpha beta"); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	for _, task := range []string{"alpha", "beta"} {
+		_, section, _ := strings.Cut(buf.String(), "<h3>"+task+" ")
+		section, _, _ = strings.Cut(section, "</section>")
+		if want := "<pre>" + task + "-1\n" + task + "-2\n" + task + "-3</pre>"; !strings.Contains(section, want) {
+			t.Errorf("expected the %s section to hold only its own output %q:\n%s", task, want, section)
+		}
+	}
+}
+
+func TestHTMLObserverMasksSecretParameterValues(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("SOPS_AGE_KEY", testAgeIdentity)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	input := strings.Replace(encryptedSettingProgram(t, "hunter2"), `task "connect":
+`, `task "connect":
+  requires $login
+`, 1)
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	observer := NewHTMLObserver()
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(observer))
+	if err := eng.ExecuteWithParams(program, "connect", map[string]string{"login": "admin:hunter2"}); err != nil {
+		t.Fatalf("ExecuteWithParams() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := observer.WriteHTML(&buf, "xdrun connect"); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	report := buf.String()
+	if strings.Contains(report, "hunter2") || !strings.Contains(report, "<tr><td>login</td><td><code>admin:***</code></td></tr>") {
+		t.Fatalf("expected the secret to be masked in the parameters:\n%s", report)
+	}
+}
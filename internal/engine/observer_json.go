@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// JSONObserver writes every execution event to a writer as one JSON object
+// per line, for tools that follow a run without parsing its output
+type JSONObserver struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONObserver returns an observer that writes JSON lines to w
+func NewJSONObserver(w io.Writer) *JSONObserver {
+	return &JSONObserver{enc: json.NewEncoder(w)}
+}
+
+// jsonEvent is the line written for an event; fields that do not apply to
+// the event are omitted
+type jsonEvent struct {
+	Event      string            `json:"event"`
+	Task       string            `json:"task,omitempty"`
+	Time       time.Time         `json:"time"`
+	DurationMS *int64            `json:"duration_ms,omitempty"`
+	Statement  string            `json:"statement,omitempty"`
+	Command    string            `json:"command,omitempty"`
+	ExitCode   *int              `json:"exit_code,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	WorkingDir string            `json:"working_dir,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
+}
+
+func (o *JSONObserver) write(event jsonEvent, ctx ContextSnapshot, err error) {
+	if err != nil {
+		event.Error = err.Error()
+	}
+	event.WorkingDir = ctx.WorkingDir
+	event.Parameters = ctx.Parameters
+	event.Variables = ctx.Variables
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_ = o.enc.Encode(event)
+}
+
+func durationMS(d time.Duration) *int64 {
+	ms := d.Milliseconds()
+	return &ms
+}
+
+func (o *JSONObserver) OnTaskStart(event TaskEvent) {
+	o.write(jsonEvent{Event: "task_start", Task: event.Task, Time: event.Time}, event.Context, nil)
+}
+
+func (o *JSONObserver) OnTaskEnd(event TaskEvent) {
+	o.write(jsonEvent{Event: "task_end", Task: event.Task, Time: event.Time, DurationMS: durationMS(event.Duration)}, event.Context, event.Err)
+}
+
+func (o *JSONObserver) OnStatement(event StatementEvent) {
+	o.write(jsonEvent{Event: "statement", Task: event.Task, Time: event.Time, Statement: string(event.Kind)}, event.Context, nil)
+}
+
+func (o *JSONObserver) OnShellStart(event ShellEvent) {
+	o.write(jsonEvent{Event: "shell_start", Task: event.Task, Time: event.Time, Command: event.Command}, event.Context, nil)
+}
+
+func (o *JSONObserver) OnShellEnd(event ShellEvent) {
+	line := jsonEvent{Event: "shell_end", Task: event.Task, Time: event.Time, Command: event.Command, DurationMS: durationMS(event.Duration)}
+	if event.Result != nil {
+		exitCode := event.Result.ExitCode
+		line.ExitCode = &exitCode
+	}
+	o.write(line, event.Context, event.Err)
+}
+
+func (o *JSONObserver) OnError(event ErrorEvent) {
+	o.write(jsonEvent{Event: "error", Task: event.Task, Time: event.Time}, event.Context, event.Err)
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type recordingObserver struct {
+	BaseObserver
+	events []string
+	ends   []TaskEvent
+}
+
+func (o *recordingObserver) OnTaskStart(event TaskEvent) {
+	o.events = append(o.events, "task_start "+event.Task)
+}
+
+func (o *recordingObserver) OnTaskEnd(event TaskEvent) {
+	o.events = append(o.events, fmt.Sprintf("task_end %s err=%v", event.Task, event.Err != nil))
+	o.ends = append(o.ends, event)
+}
+
+func (o *recordingObserver) OnStatement(event StatementEvent) {
+	o.events = append(o.events, "statement "+string(event.Kind))
+}
+
+func (o *recordingObserver) OnShellStart(event ShellEvent) {
+	o.events = append(o.events, "shell_start "+event.Command)
+}
+
+func (o *recordingObserver) OnShellEnd(event ShellEvent) {
+	o.events = append(o.events, fmt.Sprintf("shell_end %s exit=%d", event.Command, event.Result.ExitCode))
+}
+
+func (o *recordingObserver) OnError(event ErrorEvent) {
+	o.events = append(o.events, "error "+event.Task)
+}
+
+func TestObserverReceivesExecutionEvents(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "prepare":
+  let $greeting = "hi"
+  run "echo {$greeting}"
+
+task "build":
+  depends on prepare
+  requires $target
+  run "exit 3"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	observer := &recordingObserver{}
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(observer))
+	if err := eng.ExecuteWithParams(program, "build", map[string]string{"target": "linux"}); err == nil {
+		t.Fatal("expected build to fail")
+	}
+
+	want := []string{
+		"task_start prepare",
+		"statement variable",
+		"statement shell",
+		"shell_start echo hi",
+		"shell_end echo hi exit=0",
+		"task_end prepare err=false",
+		"task_start build",
+		"statement shell",
+		"shell_start exit 3",
+		"shell_end exit 3 exit=3",
+		"error build",
+		"task_end build err=true",
+	}
+	if strings.Join(observer.events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events =\n%s\nwant:\n%s", strings.Join(observer.events, "\n"), strings.Join(want, "\n"))
+	}
+
+	if prepare := observer.ends[0]; prepare.Context.Variables["$greeting"] != "hi" {
+		t.Errorf("expected the prepare snapshot to include $greeting, got %v", prepare.Context.Variables)
+	}
+	if build := observer.ends[1]; build.Context.Parameters["target"] != "linux" || build.Duration <= 0 {
+		t.Errorf("expected the build snapshot to include target and a duration, got %+v", build)
+	}
+}
+
+func TestJSONObserverWritesOneEventPerLine(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "hello":
+  run "echo hello"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	var events bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(io.Discard), WithObserver(NewJSONObserver(&events)))
+	if err := eng.Execute(program, "hello"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var kinds []string
+	for _, line := range strings.Split(strings.TrimSpace(events.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		kinds = append(kinds, event["event"].(string))
+		if event["event"] == "shell_end" && event["exit_code"] != float64(0) {
+			t.Errorf("shell_end exit_code = %v, want 0", event["exit_code"])
+		}
+	}
+	if got := strings.Join(kinds, ","); got != "task_start,statement,shell_start,shell_end,task_end" {
+		t.Errorf("events = %s", got)
+	}
+}
@@ -3,11 +3,14 @@ package engine
 import (
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/cache"
 	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
 	"github.com/phillarmonic/drun/v2/internal/domain/task"
 	"github.com/phillarmonic/drun/v2/internal/provisioning"
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
 )
 
 // EngineOptions configures the engine with optional dependencies
@@ -32,6 +35,10 @@ type EngineOptions struct {
 	// DryRun mode
 	DryRun bool
 
+	// In dry-run mode, parse each interpolated shell command with the shell
+	// (sh -n or the PowerShell parser) and fail on a syntax error
+	CheckShellSyntax bool
+
 	// Verbose mode
 	Verbose bool
 
@@ -49,8 +56,86 @@ type EngineOptions struct {
 
 	// Secrets manager
 	SecretsManager SecretsManager
+
+	// Output style used when the project does not set one (emoji, plain,
+	// ascii or high-contrast)
+	DefaultOutputStyle string
+
+	// Status symbols and colors from the user configuration, such as
+	// "ok=✔, warn=▲"; project settings override them per category
+	DefaultStatusSymbols string
+	DefaultStatusColors  string
+
+	// How long fetched remote includes stay cached (defaults to one minute)
+	IncludeCacheTTL time.Duration
+
+	// Worker count for parallel loops that do not set one (defaults to 5)
+	DefaultParallelism int
+
+	// Cpus and bytes of memory the tasks that declare their needs share
+	// (defaults to 0: detected from the machine)
+	MaxCPU    float64
+	MaxMemory int64
+
+	// Retries of downloads, HTTP requests and remote fetches that fail
+	// transiently when the project does not `set network retries` (defaults
+	// to nil: 2)
+	DefaultNetworkRetries *int
+
+	// How long a whole run may take before it is stopped; overrides the
+	// project's `set max duration` (defaults to 0: the project's, if any)
+	MaxDuration time.Duration
+
+	// Asks for required parameters that were not provided (defaults to nil:
+	// missing required parameters are an error)
+	ParamPrompter ParamPrompter
+
+	// Where successful runs of `once per commit` tasks are recorded (defaults
+	// to ~/.drun/run-history.json)
+	RunHistory *runhistory.Store
+
+	// Run `once per commit` tasks even when they already succeeded
+	Force bool
+
+	// Failed items of the parallel loops of a run being replayed by
+	// `cmd:rerun --last-failed`, by loop key (defaults to nil: loops run
+	// every item)
+	LoopReplay map[string][]string
+
+	// Receive structured execution events, in registration order
+	Observers []EngineObserver
+
+	// Name of a variable whose every assignment is traced (without the $)
+	WatchVariable string
+
+	// Print how long each task took, and a summary when the run finishes
+	Timings bool
+
+	// Hooks not to run, by kind: "setup", "teardown", "before" or "after"
+	// (defaults to none)
+	SkipHooks []string
+
+	// Notify when the run finishes, as if a task ran `notify me when done`
+	Notify bool
+
+	// Shows the completion notification (defaults to the platform's desktop
+	// notification)
+	Notifier Notifier
+
+	// Version of the drun binary, exposed as {drun.version} (defaults to "dev")
+	DrunVersion string
+
+	// Content piped into drun, exposed as {stdin} (defaults to none)
+	Stdin io.Reader
+
+	// Where answers to confirm prompts are read from (defaults to os.Stdin)
+	ConfirmInput io.Reader
 }
 
+// ParamPrompter asks the user for the value of a missing required parameter.
+// It calls validate on each answer and asks again until one is accepted.
+type ParamPrompter func(param *parameter.Parameter, validate func(value string) error) (string, error)
+
 // Option is a functional option for configuring the Engine
 type Option func(*EngineOptions)
 
@@ -89,6 +174,14 @@ func WithDryRun(dryRun bool) Option {
 	}
 }
 
+// WithShellSyntaxCheck makes dry runs check the syntax of every shell command
+// they would run, after interpolation
+func WithShellSyntaxCheck(check bool) Option {
+	return func(o *EngineOptions) {
+		o.CheckShellSyntax = check
+	}
+}
+
 // WithVerbose sets verbose mode
 func WithVerbose(verbose bool) Option {
 	return func(o *EngineOptions) {
@@ -132,12 +225,179 @@ func WithSecretsManager(sm SecretsManager) Option {
 	}
 }
 
+// WithDefaultOutputStyle sets the output style used when the project does not
+// set one
+func WithDefaultOutputStyle(style string) Option {
+	return func(o *EngineOptions) {
+		o.DefaultOutputStyle = style
+	}
+}
+
+// WithDefaultStatusSymbols sets the status symbols of the user
+// configuration, such as "ok=✔, warn=▲"
+func WithDefaultStatusSymbols(symbols string) Option {
+	return func(o *EngineOptions) {
+		o.DefaultStatusSymbols = symbols
+	}
+}
+
+// WithDefaultStatusColors sets the status colors of the user configuration,
+// such as "ok=bold blue, error=magenta"
+func WithDefaultStatusColors(colors string) Option {
+	return func(o *EngineOptions) {
+		o.DefaultStatusColors = colors
+	}
+}
+
+// WithIncludeCacheTTL sets how long fetched remote includes stay cached
+func WithIncludeCacheTTL(ttl time.Duration) Option {
+	return func(o *EngineOptions) {
+		o.IncludeCacheTTL = ttl
+	}
+}
+
+// WithDefaultParallelism sets the worker count for parallel loops that do not
+// set one
+func WithDefaultParallelism(workers int) Option {
+	return func(o *EngineOptions) {
+		o.DefaultParallelism = workers
+	}
+}
+
+// WithResourceLimits sets the cpus and bytes of memory that tasks declaring
+// `needs` share when they run concurrently; 0 detects them from the machine
+func WithResourceLimits(cpus float64, memory int64) Option {
+	return func(o *EngineOptions) {
+		o.MaxCPU = cpus
+		o.MaxMemory = memory
+	}
+}
+
+// WithDefaultNetworkRetries sets how often downloads, HTTP requests and
+// remote fetches that fail transiently are retried when the project does not
+// set it; 0 turns retries off
+func WithDefaultNetworkRetries(retries int) Option {
+	return func(o *EngineOptions) {
+		o.DefaultNetworkRetries = &retries
+	}
+}
+
+// WithMaxDuration sets how long a whole run may take before it is stopped;
+// 0 leaves it to the project's `set max duration`
+func WithMaxDuration(limit time.Duration) Option {
+	return func(o *EngineOptions) {
+		o.MaxDuration = limit
+	}
+}
+
+// WithSkipHooks skips the hooks of the given kinds ("setup", "teardown",
+// "before" or "after")
+func WithSkipHooks(kinds ...string) Option {
+	return func(o *EngineOptions) {
+		o.SkipHooks = append(o.SkipHooks, kinds...)
+	}
+}
+
+// WithParamPrompter sets how missing required parameters are asked for
+func WithParamPrompter(prompter ParamPrompter) Option {
+	return func(o *EngineOptions) {
+		o.ParamPrompter = prompter
+	}
+}
+
+// WithRunHistory sets where successful runs of `once per commit` tasks are
+// recorded
+func WithRunHistory(store *runhistory.Store) Option {
+	return func(o *EngineOptions) {
+		o.RunHistory = store
+	}
+}
+
+// WithLoopReplay makes parallel loops run only the items that failed in the
+// replayed run; loops that did not run then run every item
+func WithLoopReplay(loops map[string][]string) Option {
+	return func(o *EngineOptions) {
+		o.LoopReplay = loops
+	}
+}
+
+// WithForce runs `once per commit` tasks even when they already succeeded for
+// the current commit
+func WithForce(force bool) Option {
+	return func(o *EngineOptions) {
+		o.Force = force
+	}
+}
+
+// WithWatchVariable traces every assignment to the named variable
+func WithWatchVariable(name string) Option {
+	return func(o *EngineOptions) {
+		o.WatchVariable = strings.TrimPrefix(strings.TrimSpace(name), "$")
+	}
+}
+
+// WithTimings prints how long each task took and a per-task duration summary
+// when the run finishes
+func WithTimings(timings bool) Option {
+	return func(o *EngineOptions) {
+		o.Timings = timings
+	}
+}
+
+// WithNotify shows a desktop notification and rings the terminal bell when the
+// run finishes
+func WithNotify(notify bool) Option {
+	return func(o *EngineOptions) {
+		o.Notify = notify
+	}
+}
+
+// WithNotifier sets how the completion notification is shown
+func WithNotifier(notifier Notifier) Option {
+	return func(o *EngineOptions) {
+		o.Notifier = notifier
+	}
+}
+
+// WithObserver registers an observer for execution events; it can be given
+// several times
+func WithObserver(observer EngineObserver) Option {
+	return func(o *EngineOptions) {
+		o.Observers = append(o.Observers, observer)
+	}
+}
+
+// WithDrunVersion sets the version of the drun binary reported by {drun.version}
+func WithDrunVersion(version string) Option {
+	return func(o *EngineOptions) {
+		o.DrunVersion = version
+	}
+}
+
+// WithStdin sets the input read by {stdin} and lines of stdin
+func WithStdin(stdin io.Reader) Option {
+	return func(o *EngineOptions) {
+		o.Stdin = stdin
+	}
+}
+
+// WithConfirmInput sets where answers to confirm prompts are read from
+func WithConfirmInput(input io.Reader) Option {
+	return func(o *EngineOptions) {
+		o.ConfirmInput = input
+	}
+}
+
 // applyDefaults applies default values to unset options
 func (opts *EngineOptions) applyDefaults() {
 	if opts.Output == nil {
 		opts.Output = os.Stdout
 	}
 
+	if opts.ConfirmInput == nil {
+		opts.ConfirmInput = os.Stdin
+	}
+
 	if opts.TaskRegistry == nil {
 		opts.TaskRegistry = task.NewRegistry()
 	}
@@ -150,5 +410,21 @@ func (opts *EngineOptions) applyDefaults() {
 		opts.DepResolver = task.NewDependencyResolver(opts.TaskRegistry)
 	}
 
+	if opts.IncludeCacheTTL <= 0 {
+		opts.IncludeCacheTTL = time.Minute
+	}
+
+	if opts.DefaultParallelism <= 0 {
+		opts.DefaultParallelism = 5
+	}
+
+	if opts.Notifier == nil {
+		opts.Notifier = desktopNotification
+	}
+
+	if opts.DrunVersion == "" {
+		opts.DrunVersion = "dev"
+	}
+
 	// Note: CacheManager defaults to nil and is created on demand in the engine
 }
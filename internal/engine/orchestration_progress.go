@@ -212,25 +212,25 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 	if updateRepos || forceBuild {
 		actionVerb = "Bringing up"
 	}
-	_, _ = fmt.Fprintf(e.output, "🚀  %s orchestration: %s\n", actionVerb, orch.Name)
-	_, _ = fmt.Fprintf(e.output, "   %d services in dependency order\n", len(orderedServices))
+	e.iconf(ctx, "🚀  ", "%s orchestration: %s\n", actionVerb, orch.Name)
+	_, _ = fmt.Fprintf(e.out(ctx), "   %d services in dependency order\n", len(orderedServices))
 	if orch.CircuitBreaker || orch.StopOnFailure {
-		_, _ = fmt.Fprintf(e.output, "   🔴  Circuit breaker: ENABLED - will stop all on failure\n")
+		_, _ = fmt.Fprintf(e.out(ctx), "   🔴  Circuit breaker: ENABLED - will stop all on failure\n")
 	}
-	_, _ = fmt.Fprintf(e.output, "\n")
+	_, _ = fmt.Fprintf(e.out(ctx), "\n")
 
-	progress := NewProgressDisplay(e.output)
+	progress := NewProgressDisplay(e.out(ctx))
 	pd := progress // Alias for use in nested scope
 
 	// Check and provision Docker networks before starting services
-	if err := e.checkAndProvisionNetworks(services); err != nil {
+	if err := e.checkAndProvisionNetworks(ctx, services); err != nil {
 		return fmt.Errorf("network provisioning failed: %w", err)
 	}
 
 	// Check DNS resolution for specified domains
-	if err := e.checkDNSResolution(orch); err != nil {
+	if err := e.checkDNSResolution(ctx, orch); err != nil {
 		// DNS check failures are warnings, not errors
-		_, _ = fmt.Fprintf(e.output, "⚠️  %v\n\n", err)
+		e.iconf(ctx, "⚠️  ", "%v\n\n", err)
 	}
 
 	// Initialize all services as pending
@@ -243,7 +243,7 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 
 	// Show initial state
 	progress.Render()
-	_, _ = fmt.Fprintf(e.output, "\n")
+	_, _ = fmt.Fprintf(e.out(ctx), "\n")
 
 	// Start services one by one
 	for _, serviceName := range orderedServices {
@@ -256,7 +256,7 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 
 		alreadyHealthy, stateErr := e.serviceIsRunningAndHealthy(service)
 		if stateErr != nil && e.verbose {
-			_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Unable to confirm current state for %s: %v\n", serviceName, stateErr)
+			_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Unable to confirm current state for %s: %v\n", serviceName, stateErr)
 		}
 
 		// Check for repository updates first (if repository is configured)
@@ -289,14 +289,14 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 			}
 
 			if e.verbose {
-				_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Checking repository at: %s\n", fullPath)
+				_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Checking repository at: %s\n", fullPath)
 			}
 
 			if _, err := os.Stat(filepath.Join(fullPath, ".git")); os.IsNotExist(err) {
 				// Repository doesn't exist, needs to be cloned
 				needsClone = true
 				if e.verbose {
-					_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Repository not found at %s, will clone\n", fullPath)
+					_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Repository not found at %s, will clone\n", fullPath)
 				}
 			} else {
 				// Repository exists - check if we should update it
@@ -304,14 +304,14 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 				if !service.Repository.UpdateOnStart {
 					// Skip update check if explicitly disabled
 					if e.verbose {
-						_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Repository update disabled for %s (update on start: false)\n", serviceName)
+						_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Repository update disabled for %s (update on start: false)\n", serviceName)
 					}
 				} else if updateRepos {
 					// For "up" command: check if on default branch and force update
 					currentBranch, err := repoManager.GetCurrentBranch(context.Background(), service.Path)
 					if err == nil && (currentBranch == "main" || currentBranch == "master") {
 						hasRepoUpdates = true
-						_, _ = fmt.Fprintf(e.output, "\n  📥  Updating repository on default branch (%s) for %s\n", currentBranch, serviceName)
+						_, _ = fmt.Fprintf(e.out(ctx), "\n  📥  Updating repository on default branch (%s) for %s\n", currentBranch, serviceName)
 					}
 				} else {
 					// For "start" command: only check for updates, don't force
@@ -321,13 +321,13 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 					hasUpdates, err := repoManager.HasRemoteUpdates(context.Background(), repoConfig, service.Path)
 					if err != nil {
 						if e.verbose {
-							_, _ = fmt.Fprintf(e.output, "    [VERBOSE] Unable to check for updates for %s: %v\n", serviceName, err)
+							_, _ = fmt.Fprintf(e.out(ctx), "    [VERBOSE] Unable to check for updates for %s: %v\n", serviceName, err)
 						}
 						// If we can't check for updates, proceed with existing logic
 					} else {
 						hasRepoUpdates = hasUpdates
 						if hasUpdates {
-							_, _ = fmt.Fprintf(e.output, "\n  📥  Repository updates available for %s\n", serviceName)
+							_, _ = fmt.Fprintf(e.out(ctx), "\n  📥  Repository updates available for %s\n", serviceName)
 						}
 					}
 				}
@@ -365,14 +365,14 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 				progress.UpdateService(serviceName, "cloning", "Cloning repository...")
 				progress.RenderInline(serviceName)
 
-				_, _ = fmt.Fprintf(e.output, "\n  📂  Cloning to: %s\n", service.Path)
+				_, _ = fmt.Fprintf(e.out(ctx), "\n  📂  Cloning to: %s\n", service.Path)
 
 				if err := repoManager.Clone(context.Background(), repoConfig, service.Path); err != nil {
 					progress.FailService(serviceName, fmt.Errorf("repository clone failed: %w", err))
 					progress.RenderInline(serviceName)
 
 					if orch.StopOnFailure || orch.CircuitBreaker {
-						_, _ = fmt.Fprintf(e.output, "\n🔴  Circuit breaker triggered! Rolling back dependent services...\n\n")
+						e.iconf(ctx, "\n🔴  ", "Circuit breaker triggered! Rolling back dependent services...\n\n")
 						return fmt.Errorf("failed to clone repository for service '%s': %w", serviceName, err)
 					}
 					return fmt.Errorf("failed to clone repository for service '%s': %w", serviceName, err)
@@ -382,19 +382,19 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 				progress.UpdateService(serviceName, "updating", "Pulling repository updates...")
 				progress.RenderInline(serviceName)
 
-				_, _ = fmt.Fprintf(e.output, "\n  📂  Updating repository at: %s\n", service.Path)
+				_, _ = fmt.Fprintf(e.out(ctx), "\n  📂  Updating repository at: %s\n", service.Path)
 
 				if err := repoManager.Update(context.Background(), repoConfig, service.Path); err != nil {
 					progress.FailService(serviceName, fmt.Errorf("repository update failed: %w", err))
 					progress.RenderInline(serviceName)
 
 					if orch.StopOnFailure || orch.CircuitBreaker {
-						_, _ = fmt.Fprintf(e.output, "\n🔴  Circuit breaker triggered! Rolling back dependent services...\n\n")
+						e.iconf(ctx, "\n🔴  ", "Circuit breaker triggered! Rolling back dependent services...\n\n")
 						return fmt.Errorf("failed to update repository for service '%s': %w", serviceName, err)
 					}
 					return fmt.Errorf("failed to update repository for service '%s': %w", serviceName, err)
 				}
-				_, _ = fmt.Fprintf(e.output, "  ✓  Repository updated for %s\n\n", serviceName)
+				_, _ = fmt.Fprintf(e.out(ctx), "  ✓  Repository updated for %s\n\n", serviceName)
 			}
 
 			progress.UpdateService(serviceName, "starting", "Repository ready")
@@ -427,7 +427,7 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 			progress.RenderInline(serviceName)
 
 			// Show build output header
-			_, _ = fmt.Fprintf(e.output, "\n🔨  Building %s:\n", serviceName)
+			e.iconf(ctx, "\n🔨  ", "Building %s:\n", serviceName)
 
 			if err := e.performServiceBuild(ctx, service, false, true); err != nil {
 				progress.FailService(serviceName, err)
@@ -435,7 +435,7 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 
 				// Check if we should stop on failure
 				if orch.StopOnFailure || orch.CircuitBreaker {
-					_, _ = fmt.Fprintf(e.output, "\n🔴  Circuit breaker triggered! Rolling back dependent services...\n\n")
+					e.iconf(ctx, "\n🔴  ", "Circuit breaker triggered! Rolling back dependent services...\n\n")
 
 					// Only stop services that depend on the failed service
 					startedServices := []string{}
@@ -468,12 +468,12 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 						svcName := startedServices[i]
 						pd.UpdateService(svcName, "stopping", "Rolling back...")
 						pd.RenderInline(svcName)
-						_ = e.stopService(services[svcName]) // Ignore error during rollback
+						_ = e.stopService(ctx, services[svcName]) // Ignore error during rollback
 						pd.UpdateService(svcName, "stopped", "Stopped (rollback)")
 						pd.RenderInline(svcName)
 					}
 
-					_, _ = fmt.Fprintf(e.output, "\n")
+					_, _ = fmt.Fprintf(e.out(ctx), "\n")
 					progress.RenderSummary()
 					return fmt.Errorf("circuit breaker: failed to build '%s', dependent services stopped", serviceName)
 				}
@@ -483,19 +483,19 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 			}
 
 			// Show build completion and update progress
-			_, _ = fmt.Fprintf(e.output, "✅  Build completed for %s\n\n", serviceName)
+			e.iconf(ctx, "✅  ", "Build completed for %s\n\n", serviceName)
 			progress.UpdateService(serviceName, "starting", "Build complete, starting...")
 			progress.RenderInline(serviceName)
 		}
 
 		// Start the service
-		if err := e.startService(service); err != nil {
+		if err := e.startService(ctx, service); err != nil {
 			progress.FailService(serviceName, err)
 			progress.RenderInline(serviceName)
 
 			// Check if we should stop on failure
 			if orch.StopOnFailure || orch.CircuitBreaker {
-				_, _ = fmt.Fprintf(e.output, "\n🔴  Circuit breaker triggered! Rolling back dependent services...\n\n")
+				e.iconf(ctx, "\n🔴  ", "Circuit breaker triggered! Rolling back dependent services...\n\n")
 
 				// Only stop services that depend on the failed service
 				// For now, we'll stop all services that were started after the failed one
@@ -531,12 +531,12 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 					svcName := startedServices[i]
 					pd.UpdateService(svcName, "stopping", "Rolling back...")
 					pd.RenderInline(svcName)
-					_ = e.stopService(services[svcName]) // Ignore error during rollback
+					_ = e.stopService(ctx, services[svcName]) // Ignore error during rollback
 					pd.UpdateService(svcName, "stopped", "Stopped (rollback)")
 					pd.RenderInline(svcName)
 				}
 
-				_, _ = fmt.Fprintf(e.output, "\n")
+				_, _ = fmt.Fprintf(e.out(ctx), "\n")
 				progress.RenderSummary()
 				return fmt.Errorf("circuit breaker: failed to start '%s', dependent services stopped", serviceName)
 			}
@@ -550,13 +550,13 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 			progress.UpdateService(serviceName, "starting", "Waiting for health check...")
 			progress.RenderInline(serviceName)
 
-			if err := e.waitForHealth(service); err != nil {
+			if err := e.waitForHealth(ctx, service); err != nil {
 				progress.FailService(serviceName, err)
 				progress.RenderInline(serviceName)
 
 				// Check if we should stop on failure
 				if orch.StopOnFailure || orch.CircuitBreaker {
-					_, _ = fmt.Fprintf(e.output, "\n🔴  Circuit breaker triggered! Rolling back dependent services...\n\n")
+					e.iconf(ctx, "\n🔴  ", "Circuit breaker triggered! Rolling back dependent services...\n\n")
 
 					// Only stop services that depend on the failed service
 					startedServices := []string{}
@@ -589,12 +589,12 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 						svcName := startedServices[i]
 						pd.UpdateService(svcName, "stopping", "Rolling back...")
 						pd.RenderInline(svcName)
-						_ = e.stopService(services[svcName])
+						_ = e.stopService(ctx, services[svcName])
 						pd.UpdateService(svcName, "stopped", "Stopped (rollback)")
 						pd.RenderInline(svcName)
 					}
 
-					_, _ = fmt.Fprintf(e.output, "\n")
+					_, _ = fmt.Fprintf(e.out(ctx), "\n")
 					progress.RenderSummary()
 					return fmt.Errorf("circuit breaker: health check failed for '%s', dependent services stopped", serviceName)
 				}
@@ -614,17 +614,17 @@ func (e *Engine) orchestrateStartWithProgress(ctx *ExecutionContext, orch *ast.O
 	progress.RenderSummary()
 
 	// Display HTTP service URLs
-	e.displayServiceURLs(orderedServices, services)
+	e.displayServiceURLs(ctx, orderedServices, services)
 
 	return nil
 }
 
 // orchestrateStopWithProgress stops services with progress display
 func (e *Engine) orchestrateStopWithProgress(ctx *ExecutionContext, orch *ast.OrchestrateStatement, orderedServices []string, services map[string]*ast.ServiceStatement) error {
-	_, _ = fmt.Fprintf(e.output, "🛑  Stopping orchestration: %s\n", orch.Name)
-	_, _ = fmt.Fprintf(e.output, "   %d services in reverse order\n\n", len(orderedServices))
+	e.iconf(ctx, "🛑  ", "Stopping orchestration: %s\n", orch.Name)
+	_, _ = fmt.Fprintf(e.out(ctx), "   %d services in reverse order\n\n", len(orderedServices))
 
-	progress := NewProgressDisplay(e.output)
+	progress := NewProgressDisplay(e.out(ctx))
 
 	// Reverse order for shutdown
 	for i := len(orderedServices) - 1; i >= 0; i-- {
@@ -635,7 +635,7 @@ func (e *Engine) orchestrateStopWithProgress(ctx *ExecutionContext, orch *ast.Or
 		progress.UpdateService(serviceName, "stopping", "Stopping service...")
 		progress.RenderInline(serviceName)
 
-		if err := e.stopService(service); err != nil {
+		if err := e.stopService(ctx, service); err != nil {
 			progress.FailService(serviceName, err)
 			progress.RenderInline(serviceName)
 			// Continue stopping other services
@@ -656,12 +656,12 @@ func (e *Engine) orchestrateStopWithProgress(ctx *ExecutionContext, orch *ast.Or
 		}
 	}
 
-	_, _ = fmt.Fprintf(e.output, "\n✅  All services stopped\n")
+	e.iconf(ctx, "\n✅  ", "All services stopped\n")
 	return nil
 }
 
 // displayServiceURLs displays HTTP health check URLs for all services
-func (e *Engine) displayServiceURLs(orderedServices []string, services map[string]*ast.ServiceStatement) {
+func (e *Engine) displayServiceURLs(ctx *ExecutionContext, orderedServices []string, services map[string]*ast.ServiceStatement) {
 	httpServices := []struct {
 		name string
 		url  string
@@ -683,9 +683,9 @@ func (e *Engine) displayServiceURLs(orderedServices []string, services map[strin
 
 	// Display URLs if any found
 	if len(httpServices) > 0 {
-		_, _ = fmt.Fprintf(e.output, "\n🌐  Service URLs:\n")
+		e.iconf(ctx, "\n🌐  ", "Service URLs:\n")
 		for _, svc := range httpServices {
-			_, _ = fmt.Fprintf(e.output, "   • %s: %s\n", svc.name, svc.url)
+			_, _ = fmt.Fprintf(e.out(ctx), "   • %s: %s\n", svc.name, svc.url)
 		}
 	}
 }
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/phillarmonic/drun/v2/internal/i18n"
+)
+
+// Domain: Output Language
+// This file selects the message catalog that status messages, built-in
+// results and errors are translated with.
+
+// languageSetting is the project setting key written by
+// `set language to "pt-BR"`
+const languageSetting = "language"
+
+// outputTranslator loads the translator of the project's language. Catalogs
+// in <project>/.drun/locales add to and override the built-in ones.
+func (e *Engine) outputTranslator(projectCtx *ProjectContext, currentFile string) (*i18n.Translator, error) {
+	language := ""
+	if projectCtx != nil {
+		language = projectCtx.Settings[languageSetting]
+	}
+	translator, err := i18n.Load(language, filepath.Join(projectRootDir(currentFile), ".drun", "locales"))
+	if err != nil {
+		return nil, fmt.Errorf("set language: %w", err)
+	}
+	return translator, nil
+}
+
+// Localize translates a message rendered in English, such as the text of an
+// error reported after a run, to the language of the project the engine ran
+// last. Messages missing from the catalog are returned unchanged.
+func (e *Engine) Localize(message string) string {
+	return e.translator.Load().Message(message)
+}
+
+// localizeArgs translates the errors among the arguments of a status message
+func localizeArgs(translator *i18n.Translator, args []any) []any {
+	if translator == nil {
+		return args
+	}
+	localized := make([]any, len(args))
+	for i, arg := range args {
+		if err, ok := arg.(error); ok && err != nil {
+			arg = translator.Message(err.Error())
+		}
+		localized[i] = arg
+	}
+	return localized
+}
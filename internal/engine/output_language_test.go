@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetLanguageTranslatesStatusMessagesAndErrors(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  set language to "pt-BR"
+
+task "deploy":
+  given $replicas as number defaults to "0"
+  assert {replicas} > 0 otherwise "replicas must be positive"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	err = eng.ExecuteWithParams(program, "deploy", map[string]string{"replicas": "0"})
+	if err == nil {
+		t.Fatalf("expected the assertion to fail the task:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Asserções com falha (1):\n  deploy: replicas must be positive\n") {
+		t.Errorf("expected the summary in Portuguese:\n%s", buf.String())
+	}
+	want := "asserção falhou: replicas must be positive ({replicas} > 0)"
+	if got := eng.Localize(err.Error()); !strings.Contains(got, want) {
+		t.Errorf("Localize(%q) = %q; want it to contain %q", err.Error(), got, want)
+	}
+}
+
+func TestSetLanguageReadsProjectCatalogs(t *testing.T) {
+	dir := t.TempDir()
+	localesDir := filepath.Join(dir, ".drun", "locales")
+	if err := os.MkdirAll(localesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	catalog := `{"Failed assertions (%d):": "Échecs (%d) :"}`
+	if err := os.WriteFile(filepath.Join(localesDir, "fr.json"), []byte(catalog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `version: 2.0
+
+project "app":
+  set language to "fr"
+
+task "check":
+  assert 1 > 2
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	_ = eng.ExecuteWithParamsAndFile(program, "check", nil, filepath.Join(dir, ".drun", "spec.drun"))
+	if !strings.Contains(buf.String(), "Échecs (1) :") {
+		t.Errorf("expected the project catalog to translate the summary:\n%s", buf.String())
+	}
+
+	program, err = ParseString(strings.Replace(input, `"fr"`, `"xx"`, 1))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	err = eng.ExecuteWithParamsAndFile(program, "check", nil, filepath.Join(dir, ".drun", "spec.drun"))
+	if err == nil || !strings.Contains(err.Error(), `set language: no message catalog for language "xx"`) {
+		t.Errorf("expected an unknown language to fail, got %v", err)
+	}
+}
+
+func TestSetLanguageLeavesBuiltinValuesUnchanged(t *testing.T) {
+	// "Error: %s" is in the pt-BR catalog; a value that happens to match it
+	// is data and must come back as is
+	t.Setenv("DRUN_TEST_LANGUAGE_VALUE", "Error: disk full")
+	input := `version: 2.0
+
+project "app":
+  set language to "pt-BR"
+
+task "show":
+  let $value = "{env('DRUN_TEST_LANGUAGE_VALUE')}"
+  info "value=[{$value}]"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "show"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "value=[Error: disk full]") {
+		t.Errorf("expected the builtin value unchanged:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestShellLoggingToTeesOutput verifies that `logging to` keeps streaming output
+// while writing a copy to the log file, and that `keeping N` rotates old logs.
+func TestShellLoggingToTeesOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	input := `version: 2.0
+
+task "build":
+    run "echo build-output" logging to "logs/build.log" keeping 2
+`
+	program := parseForWorkdirTest(t, input)
+
+	for run := 1; run <= 3; run++ {
+		var out bytes.Buffer
+		eng := NewEngine(&out)
+		if err := eng.Execute(program, "build"); err != nil {
+			t.Fatalf("run %d failed: %v\nOutput:\n%s", run, err, out.String())
+		}
+		if !strings.Contains(out.String(), "build-output") {
+			t.Errorf("run %d: expected streamed output, got:\n%s", run, out.String())
+		}
+	}
+
+	logPath := filepath.Join(tmpDir, "logs", "build.log")
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "build-output" {
+		t.Errorf("log content = %q, want build-output", string(content))
+	}
+	for _, rotated := range []string{logPath + ".1", logPath + ".2"} {
+		if _, err := os.Stat(rotated); err != nil {
+			t.Errorf("expected rotated log %s: %v", rotated, err)
+		}
+	}
+	if _, err := os.Stat(logPath + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated logs to be kept, found %s", logPath+".3")
+	}
+}
+
+// TestTaskLogOutputCollectsShellOutput verifies that `log output to` collects the
+// output of every later shell statement in the task, including failures.
+func TestTaskLogOutputCollectsShellOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	input := `version: 2.0
+
+task "ci":
+    info "not logged"
+    log output to "logs/{task}.log"
+    run "echo first"
+    run:
+        echo second
+        echo third
+    run "echo on-stderr >&2"
+    run "echo boom && exit 3"
+`
+	program := parseForWorkdirTest(t, input)
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+	if err := eng.Execute(program, "ci"); err == nil {
+		t.Fatalf("expected failure from last statement\nOutput:\n%s", out.String())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "logs", "ci.log"))
+	if err != nil {
+		t.Fatalf("reading task log: %v", err)
+	}
+	log := string(content)
+	for _, want := range []string{"first", "second", "third", "on-stderr", "boom"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("task log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "not logged") {
+		t.Errorf("task log should only contain shell output:\n%s", log)
+	}
+}
+
+// TestOutputLoggingDryRun verifies that dry-run reports log destinations without creating files.
+func TestOutputLoggingDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	input := `version: 2.0
+
+task "build":
+    log output to "logs/{task}.log"
+    run "make build" logging to "logs/make.log"
+`
+	program := parseForWorkdirTest(t, input)
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+	eng.SetDryRun(true)
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("dry run failed: %v\nOutput:\n%s", err, out.String())
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "[DRY RUN] Would log task output to: "+filepath.Join(tmpDir, "logs", "build.log")) {
+		t.Errorf("expected task log dry-run line, got:\n%s", output)
+	}
+	if !strings.Contains(output, "[DRY RUN] Would log output to: "+filepath.Join(tmpDir, "logs", "make.log")) {
+		t.Errorf("expected statement log dry-run line, got:\n%s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "logs")); !os.IsNotExist(err) {
+		t.Errorf("dry run should not create the log directory")
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/phillarmonic/drun/v2/internal/i18n"
+	"github.com/phillarmonic/drun/v2/internal/ui"
+)
+
+// Domain: Output Style
+// This file selects the ui theme used for status prefixes and step headers.
+
+// Project setting keys written by `set output style to "..."`,
+// `set step style to "..."`, `set step width to N`,
+// `set status symbols to "..."` and `set status colors to "..."`
+const (
+	outputStyleSetting   = "output_style"
+	stepStyleSetting     = "step_style"
+	stepWidthSetting     = "step_width"
+	statusSymbolsSetting = "status_symbols"
+	statusColorsSetting  = "status_colors"
+)
+
+// outputStyle is how an execution renders its messages: the theme of its
+// output style and the catalog of its language. Each execution has its own,
+// so concurrent runs of different projects keep their own style.
+type outputStyle struct {
+	theme      *ui.Theme
+	translator *i18n.Translator // nil for English
+}
+
+// defaultOutputStyle is used for messages that belong to no execution
+var defaultOutputStyle = &outputStyle{theme: ui.DefaultTheme()}
+
+// outputTheme selects the output theme from the project settings,
+// falling back to the configured default style and then the emoji theme.
+// Status symbols and colors from the user configuration apply first, and
+// the project's override them category by category.
+func (e *Engine) outputTheme(projectCtx *ProjectContext) (*ui.Theme, error) {
+	var settings map[string]string
+	if projectCtx != nil {
+		settings = projectCtx.Settings
+	}
+
+	style, ok := settings[outputStyleSetting]
+	if !ok {
+		style = e.defaultStyle
+	}
+	theme, err := ui.NewTheme(style)
+	if err != nil {
+		return nil, fmt.Errorf("set output style: %w", err)
+	}
+
+	stepWidth := 0
+	if raw, ok := settings[stepWidthSetting]; ok {
+		stepWidth, err = strconv.Atoi(raw)
+		if err != nil || stepWidth <= 0 {
+			return nil, fmt.Errorf("set step width: expected a positive whole number, got %q", raw)
+		}
+	}
+	if err := theme.SetStepStyle(settings[stepStyleSetting], stepWidth); err != nil {
+		return nil, fmt.Errorf("set step style: %w", err)
+	}
+
+	for _, symbols := range []string{e.defaultSymbols, settings[statusSymbolsSetting]} {
+		if err := theme.SetStatusSymbols(symbols); err != nil {
+			return nil, fmt.Errorf("set status symbols: %w", err)
+		}
+	}
+	for _, colors := range []string{e.defaultColors, settings[statusColorsSetting]} {
+		if err := theme.SetStatusColors(colors); err != nil {
+			return nil, fmt.Errorf("set status colors: %w", err)
+		}
+	}
+
+	return theme, nil
+}
+
+// iconf writes a status message of the execution ctx prefixed with icon,
+// rendered by the active theme (the emoji itself by default, a tag such as
+// "[WARN] " for the plain style) and translated to the project's language.
+// ctx may be nil for messages that belong to no execution.
+func (e *Engine) iconf(ctx *ExecutionContext, icon, format string, args ...any) {
+	style := e.style(ctx)
+	format = style.translator.Format(format)
+	_, _ = fmt.Fprintf(e.out(ctx), style.theme.Icon(icon)+format, localizeArgs(style.translator, args)...)
+}
+
+// style returns the output style of the execution ctx
+func (e *Engine) style(ctx *ExecutionContext) *outputStyle {
+	if ctx != nil && ctx.Style != nil {
+		return ctx.Style
+	}
+	return defaultOutputStyle
+}
+
+// out returns the writer the execution ctx writes its output to: the engine
+// output behind the secret masker, as written by ctx's task, unless a call
+// keeps the called task's output (call task silently). ctx may be nil.
+func (e *Engine) out(ctx *ExecutionContext) io.Writer {
+	if ctx != nil && ctx.Output != nil {
+		return ctx.Output
+	}
+	if ctx != nil && ctx.CurrentTask != "" {
+		return taskOutput{masker: e.credentials.masker, task: ctx.CurrentTask}
+	}
+	return e.credentials.masker
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPlainOutputStyleUsesStablePrefixes(t *testing.T) {
+	input := `
+version: 2.0
+
+project "app":
+  set output style to "plain"
+
+task "release":
+  step "Releasing"
+  info "starting"
+  warn "careful"
+  success "done"
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "release", &buf); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	expected := "[STEP] Releasing\n[INFO] starting\n[WARN] careful\n[OK] done\n"
+	if buf.String() != expected {
+		t.Fatalf("output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestDefaultOutputStyleKeepsEmoji(t *testing.T) {
+	input := `
+version: 2.0
+
+task "release":
+  step "Go"
+  info "starting"
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "release", &buf); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "┌────┐\n│ Go │\n└────┘\n") || !strings.Contains(buf.String(), "ℹ️  starting") {
+		t.Fatalf("expected default emoji output, got %q", buf.String())
+	}
+}
+
+func TestUnknownOutputStyleFails(t *testing.T) {
+	input := `
+version: 2.0
+
+project "app":
+  set output style to "fancy"
+
+task "release":
+  info "starting"
+`
+
+	var buf bytes.Buffer
+	err := ExecuteString(input, "release", &buf)
+	if err == nil || !strings.Contains(err.Error(), `unknown output style "fancy"`) {
+		t.Fatalf("expected unknown output style error, got %v", err)
+	}
+}
+
+func TestDefaultOutputStyleOptionAppliesWhenProjectSetsNone(t *testing.T) {
+	program, err := ParseString(`
+version: 2.0
+
+task "release":
+  warn "careful"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithDefaultOutputStyle("plain"))
+	if err := eng.Execute(program, "release"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if buf.String() != "[WARN] careful\n" {
+		t.Fatalf("output = %q, want plain output", buf.String())
+	}
+}
+
+func TestASCIIOutputStyleWithStatusOverrides(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	program, err := ParseString(`
+version: 2.0
+
+project "app":
+  set output style to "ascii"
+  set status symbols to "ok=PASS"
+  set status colors to "warn=bold yellow"
+
+task "release":
+  step "Go"
+  info "starting"
+  warn "careful"
+  success "done"
+  error "broken"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithDefaultStatusSymbols("ok=OK, error=E"))
+	if err := eng.Execute(program, "release"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	expected := "+----+\n| Go |\n+----+\n[i] starting\n\033[1;33m[!]\033[0m careful\nPASS done\nE broken\n"
+	if buf.String() != expected {
+		t.Fatalf("output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestUnknownStatusCategoryFails(t *testing.T) {
+	input := `
+version: 2.0
+
+project "app":
+  set status symbols to "okay=+"
+
+task "release":
+  info "starting"
+`
+
+	var buf bytes.Buffer
+	err := ExecuteString(input, "release", &buf)
+	if err == nil || !strings.Contains(err.Error(), `set status symbols: unknown status category "okay"`) {
+		t.Fatalf("expected unknown status category error, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestShadowReplacesTheParameterValueInTheTask(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "deploy":
+  given $env defaults to "dev"
+  given $replicas as number defaults to 1
+  let shadow $env = "{$env}-eu"
+  set shadow $replicas to "3"
+  if $env is "dev-eu":
+    info "deploying {$replicas} replicas to {$env}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "deploy"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "deploying 3 replicas to dev-eu") {
+		t.Fatalf("expected the shadowed values in conditions and interpolation:\n%s", buf.String())
+	}
+}
+
+func TestShadowKeepsTheParameterType(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "scale":
+  given $replicas as number defaults to 1
+  set shadow $replicas to "many"
+  info "never"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "scale")
+	if err == nil || !strings.Contains(err.Error(), "cannot shadow parameter $replicas") {
+		t.Fatalf("expected a type error, got %v\n%s", err, buf.String())
+	}
+	if strings.Contains(buf.String(), "never") {
+		t.Fatalf("expected the task to stop:\n%s", buf.String())
+	}
+}
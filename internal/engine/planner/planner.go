@@ -7,6 +7,7 @@ import (
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/domain/statement"
 	"github.com/phillarmonic/drun/v2/internal/domain/task"
+	"github.com/phillarmonic/drun/v2/internal/engine/hooks"
 )
 
 // HookPlan represents hooks to execute at various lifecycle points
@@ -21,11 +22,19 @@ type HookPlan struct {
 type TaskPlan struct {
 	Name        string
 	Mode        string
+	Container   string
+	Once        bool    // skipped when it already succeeded for the current commit and parameters
+	CPUs        float64 // cpus reserved while it runs (needs 2 cpus)
+	Memory      int64   // bytes of memory reserved while it runs
 	Description string
 	Namespace   string
 	Source      string
 	Parameters  []task.Parameter
+	Outputs     []string          // variables it hands to tasks that need them
+	OutputNeeds []task.OutputNeed // outputs of other tasks it reads
 	Body        []statement.Statement
+	BeforeHooks []statement.Statement // before hooks that wrap this task, in execution order
+	AfterHooks  []statement.Statement // after hooks that wrap this task, in execution order
 }
 
 // ExecutionPlan represents a complete, deterministic execution plan
@@ -67,12 +76,14 @@ func NewPlanner(taskRegistry *task.Registry, depResolver *task.DependencyResolve
 
 // ProjectContext provides project-level information for planning
 type ProjectContext struct {
-	Name          string
-	Version       string
-	SetupHooks    []statement.Statement
-	TeardownHooks []statement.Statement
-	BeforeHooks   []statement.Statement
-	AfterHooks    []statement.Statement
+	Name            string
+	Version         string
+	SetupHooks      []statement.Statement
+	TeardownHooks   []statement.Statement
+	BeforeHooks     []statement.Statement
+	AfterHooks      []statement.Statement
+	BeforeHookChain []hooks.Hook // all before hooks, including task-scoped ones; BeforeHooks is used when empty
+	AfterHookChain  []hooks.Hook // all after hooks, including task-scoped ones; AfterHooks is used when empty
 }
 
 // Plan creates a comprehensive execution plan for the given task
@@ -83,6 +94,13 @@ func (p *Planner) Plan(taskName string, program *ast.Program, projectCtx *Projec
 		return nil, fmt.Errorf("dependency resolution failed: %w", err)
 	}
 
+	// Resolve the before/after hook chains that wrap each planned task
+	var beforeChain, afterChain []hooks.Hook
+	if projectCtx != nil {
+		beforeChain = hookChain(projectCtx.BeforeHookChain, projectCtx.BeforeHooks)
+		afterChain = hookChain(projectCtx.AfterHookChain, projectCtx.AfterHooks)
+	}
+
 	// Build execution order
 	executionOrder := make([]string, len(domainTasks))
 	taskPlans := make(map[string]*TaskPlan)
@@ -95,12 +113,23 @@ func (p *Planner) Plan(taskName string, program *ast.Program, projectCtx *Projec
 		taskPlans[domainTask.Name] = &TaskPlan{
 			Name:        domainTask.Name,
 			Mode:        domainTask.Mode,
+			Container:   domainTask.Container,
+			Once:        domainTask.Once,
+			CPUs:        domainTask.CPUs,
+			Memory:      domainTask.Memory,
 			Description: domainTask.Description,
 			Namespace:   domainTask.Namespace,
 			Source:      domainTask.Source,
 			Parameters:  domainTask.Parameters,
+			Outputs:     domainTask.Outputs,
+			OutputNeeds: domainTask.OutputNeeds,
 			Body:        domainTask.Body,
 		}
+		// Scoped hooks match the namespaced name of included tasks
+		fullName := domainTask.FullName()
+		isTarget := domainTask.Name == taskName || fullName == taskName
+		taskPlans[domainTask.Name].BeforeHooks = hooks.ChainFor(beforeChain, fullName, isTarget)
+		taskPlans[domainTask.Name].AfterHooks = hooks.ChainFor(afterChain, fullName, isTarget)
 
 		// Track namespaces
 		if domainTask.Namespace != "" {
@@ -136,6 +165,15 @@ func (p *Planner) Plan(taskName string, program *ast.Program, projectCtx *Projec
 	return plan, nil
 }
 
+// hookChain returns chain, or stmts as a single unscoped hook when no chain
+// was provided
+func hookChain(chain []hooks.Hook, stmts []statement.Statement) []hooks.Hook {
+	if len(chain) > 0 || len(stmts) == 0 {
+		return chain
+	}
+	return []hooks.Hook{{Body: stmts}}
+}
+
 // GetTask retrieves a task plan from the execution plan
 func (ep *ExecutionPlan) GetTask(name string) (*TaskPlan, error) {
 	t, ok := ep.Tasks[name]
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// installPlugin writes a shell script plugin named drun-x-<tool> to a
+// directory put first on PATH
+func installPlugin(t *testing.T, tool, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "drun-x-"+tool), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestPluginStatementSendsRequestAndAppliesResponse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins in this test are POSIX shell scripts")
+	}
+	installPlugin(t, "sentry", `request=$(cat)
+echo "$request" > "$PWD/request.json"
+echo "creating release" >&2
+printf '{"messages":["release created"],"variables":{"release_id":"r-42"}}'
+`)
+
+	dir := t.TempDir()
+	input := `version: 2.0
+
+task "release":
+  use workdir "` + filepath.ToSlash(dir) + `"
+  set $version to "1.2.3"
+  x sentry release new "{$version}" --finalize
+  info "release id {$release_id}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "release"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	for _, want := range []string{"creating release", "release created", "release id r-42"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	request, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatalf("plugin did not run in the task working directory: %v", err)
+	}
+	for _, want := range []string{`"protocol":1`, `"tool":"sentry"`, `"args":["release","new","1.2.3","--finalize"]`, `"task":"release"`} {
+		if !strings.Contains(string(request), want) {
+			t.Errorf("expected %s in the request: %s", want, request)
+		}
+	}
+}
+
+func TestPluginStatementFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins in this test are POSIX shell scripts")
+	}
+	installPlugin(t, "reject", `cat > /dev/null
+printf '{"error":"release already exists"}'
+exit 1
+`)
+	installPlugin(t, "crash", "exit 3\n")
+
+	tests := []struct {
+		statement string
+		want      string
+	}{
+		{"x reject release", "plugin drun-x-reject failed: release already exists"},
+		{"x crash release", "plugin drun-x-crash failed with exit code 3"},
+		{"x not-installed release", "drun-x-not-installed was not found on PATH"},
+	}
+	for _, tt := range tests {
+		program, err := ParseString("version: 2.0\n\ntask \"release\":\n  " + tt.statement + "\n")
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		var buf bytes.Buffer
+		err = NewEngine(&buf).Execute(program, "release")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.statement, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/parameter"
+	"github.com/phillarmonic/drun/v2/internal/types"
+)
+
+// Domain: Validator Plugins
+// This file checks parameters declared `validated by "k8s-namespace"`.
+// Validators registered with the parameter validator come first; any other
+// name runs the drun-validate-<name> executable found on PATH, which speaks
+// the plugin protocol: one JSON request on stdin, one JSON response on
+// stdout, and an "error" in the response rejects the value.
+
+// validatorPluginPrefix is the executable name prefix of validator plugins
+const validatorPluginPrefix = "drun-validate-"
+
+// validatorNamePattern keeps validator names from reaching outside PATH
+var validatorNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// validatorRequest is the JSON document written to a validator plugin's stdin
+type validatorRequest struct {
+	Protocol   int               `json:"protocol"`
+	Validator  string            `json:"validator"`
+	Parameter  string            `json:"parameter"`
+	Value      string            `json:"value"`
+	Task       string            `json:"task"`
+	WorkingDir string            `json:"working_dir"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// validateParameter checks a parameter value of task, giving its custom
+// validator, if any, the environment of the run
+func (e *Engine) validateParameter(param *parameter.Parameter, value *types.Value, task string, ctx *ExecutionContext) error {
+	if param.Validator == "" {
+		return e.paramValidator.Validate(param, value)
+	}
+	return e.paramValidator.ValidateIn(param, value, validationEnvironment(task, ctx))
+}
+
+// validationEnvironment describes the run to a custom validator
+func validationEnvironment(task string, ctx *ExecutionContext) parameter.Environment {
+	env := parameter.Environment{Task: task, Parameters: make(map[string]string)}
+	if ctx == nil {
+		return env
+	}
+	env.WorkingDir = ctx.WorkingDir
+	if env.WorkingDir == "" {
+		env.WorkingDir, _ = os.Getwd()
+	}
+	for name, value := range ctx.Parameters {
+		if value != nil {
+			env.Parameters[name] = value.String()
+		}
+	}
+	if ctx.Project != nil {
+		if accept := shellEnvFilter(ctx.Project); accept != nil {
+			env.Env = []string{}
+			for _, entry := range os.Environ() {
+				if name, _, _ := strings.Cut(entry, "="); accept(name) {
+					env.Env = append(env.Env, entry)
+				}
+			}
+		}
+	}
+	return env
+}
+
+// resolveValidatorPlugin finds the drun-validate-<name> plugin on PATH
+func (e *Engine) resolveValidatorPlugin(name string) (parameter.CustomValidator, bool) {
+	if !validatorNamePattern.MatchString(name) {
+		return nil, false
+	}
+	path, err := exec.LookPath(validatorPluginPrefix + name)
+	if err != nil {
+		return nil, false
+	}
+	return parameter.CustomValidatorFunc(func(param, value string, env parameter.Environment) error {
+		return e.runValidatorPlugin(path, name, param, value, env)
+	}), true
+}
+
+// runValidatorPlugin asks a validator plugin about a value; an error means
+// the value was rejected or the plugin failed
+func (e *Engine) runValidatorPlugin(path, name, param, value string, env parameter.Environment) error {
+	plugin := validatorPluginPrefix + name
+	request, err := json.Marshal(validatorRequest{
+		Protocol:   pluginProtocolVersion,
+		Validator:  name,
+		Parameter:  param,
+		Value:      value,
+		Task:       env.Task,
+		WorkingDir: env.WorkingDir,
+		Parameters: env.Parameters,
+	})
+	if err != nil {
+		return err
+	}
+
+	if e.verbose {
+		e.iconf(nil, "🔌 ", "Validating $%s with %s\n", param, plugin)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Dir = env.WorkingDir
+	cmd.Env = env.Env
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = e.out(nil)
+	runErr := cmd.Run()
+
+	var response pluginResponse
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, &response); err != nil {
+			if runErr != nil {
+				return fmt.Errorf("validator %s failed: %w", plugin, runErr)
+			}
+			return fmt.Errorf("validator %s returned an invalid response: %w", plugin, err)
+		}
+	}
+
+	for _, message := range response.Messages {
+		e.iconf(nil, "ℹ️  ", "%s\n", message)
+	}
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Errorf("rejected by %s (exit code %d)", plugin, exitErr.ExitCode())
+		}
+		return fmt.Errorf("validator %s failed: %w", plugin, runErr)
+	}
+	return nil
+}
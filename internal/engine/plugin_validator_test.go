@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// installValidatorPlugin writes a shell script named drun-validate-<name> to
+// a directory put first on PATH
+func installValidatorPlugin(t *testing.T, name, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "drun-validate-"+name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestValidatorPluginChecksParameters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins in this test are POSIX shell scripts")
+	}
+	dir := t.TempDir()
+	installValidatorPlugin(t, "k8s-namespace", `request=$(cat)
+echo "$request" > "`+filepath.ToSlash(dir)+`/request.json"
+case "$request" in
+  *'"value":"prod"'*) printf '{"messages":["namespace prod exists"]}' ;;
+  *) printf '{"error":"no such namespace in cluster eu"}'; exit 1 ;;
+esac
+`)
+
+	program, err := ParseString(`version: 2.0
+
+task "deploy":
+  requires $cluster
+  requires $namespace validated by "k8s-namespace"
+  info "deploying to {$namespace}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewEngine(&buf).ExecuteWithParams(program, "deploy", map[string]string{"cluster": "eu", "namespace": "prod"})
+	if err != nil {
+		t.Fatalf("expected prod to be accepted, got %v\n%s", err, buf.String())
+	}
+	for _, want := range []string{"namespace prod exists", "deploying to prod"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, buf.String())
+		}
+	}
+	request, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"protocol":1`, `"validator":"k8s-namespace"`, `"parameter":"namespace"`, `"task":"deploy"`, `"parameters":{"cluster":"eu"}`} {
+		if !strings.Contains(string(request), want) {
+			t.Errorf("expected %s in the request: %s", want, request)
+		}
+	}
+
+	buf.Reset()
+	err = NewEngine(&buf).ExecuteWithParams(program, "deploy", map[string]string{"cluster": "eu", "namespace": "staging"})
+	if err == nil || !strings.Contains(err.Error(), "no such namespace in cluster eu") {
+		t.Fatalf("expected staging to be rejected, got %v\n%s", err, buf.String())
+	}
+	if strings.Contains(buf.String(), "deploying") {
+		t.Errorf("the task ran with a rejected parameter:\n%s", buf.String())
+	}
+}
+
+func TestValidatorPluginNotInstalled(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "deploy":
+  requires $namespace validated by "../not-installed"
+  info "deploying"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngine(&buf).ExecuteWithParams(program, "deploy", map[string]string{"namespace": "prod"})
+	if err == nil || !strings.Contains(err.Error(), `unknown validator "../not-installed"`) {
+		t.Fatalf("expected an unknown validator error, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newHealthServer answers 503 until it has been asked healthyAfter times
+func newHealthServer(t *testing.T, healthyAfter int64) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("X-Attempt", fmt.Sprint(n))
+		if n < healthyAfter {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprint(w, "starting\n")
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok\n")
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func runPollTask(t *testing.T, input string, opts ...Option) (string, error) {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(append([]Option{WithOutput(&buf)}, opts...)...)
+	err = eng.Execute(program, "wait")
+	return buf.String(), err
+}
+
+func TestPollUntilConditionHolds(t *testing.T) {
+	server, calls := newHealthServer(t, 3)
+	out, err := runPollTask(t, `version: 2.0
+
+task "wait":
+  poll every 10ms up to 5s:
+    get "`+server.URL+`/health" capture status as $code
+  until {$code} is "200"
+  info "healthy after {$code}"
+`)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 requests, got %d", calls.Load())
+	}
+	if !strings.Contains(out, "healthy after 200") {
+		t.Fatalf("expected the captured status after the poll:\n%s", out)
+	}
+}
+
+func TestPollGivesUpAfterTimeLimit(t *testing.T) {
+	server, calls := newHealthServer(t, 1000)
+	out, err := runPollTask(t, `version: 2.0
+
+task "wait":
+  poll every 20ms up to 100ms:
+    get "`+server.URL+`/health" capture status as $code
+  until {$code} is "200"
+  info "healthy"
+`)
+	if err == nil || !strings.Contains(err.Error(), "poll gave up") || !strings.Contains(err.Error(), "{$code} is 200") {
+		t.Fatalf("expected a poll time limit error, got %v", err)
+	}
+	if strings.Contains(out, "healthy") {
+		t.Fatalf("statements after the poll should not run:\n%s", out)
+	}
+	if n := calls.Load(); n < 2 || n > 6 {
+		t.Fatalf("expected a handful of attempts within the time limit, got %d", n)
+	}
+}
+
+func TestPollDryRun(t *testing.T) {
+	out, err := runPollTask(t, `version: 2.0
+
+task "wait":
+  poll every 10s up to 5m:
+    get "http://127.0.0.1:1/health" capture status as $code
+  until {$code} is "200"
+`, WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "[DRY RUN] Would poll every 10s for up to 5m0s until {$code} is 200") {
+		t.Fatalf("unexpected dry run output:\n%s", out)
+	}
+}
+
+func TestHTTPCaptureStatusBodyAndHeader(t *testing.T) {
+	server, _ := newHealthServer(t, 2)
+	out, err := runPollTask(t, `version: 2.0
+
+task "wait":
+  get "`+server.URL+`/health" capture status as $code capture body as $body capture header "X-Attempt" as $attempt
+  info "first: {$code} {$body} {$attempt}"
+  get "`+server.URL+`/health" capture status as $code capture body as $body
+  info "second: {$code} {$body}"
+`)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"first: 503 starting 1", "second: 200 ok"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTTPCaptureWithoutResponse(t *testing.T) {
+	out, err := runPollTask(t, `version: 2.0
+
+task "wait":
+  get "http://127.0.0.1:1/health" capture status as $code
+  info "status {$code}"
+`)
+	if err != nil {
+		t.Fatalf("a refused connection should be captured as a status, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "status 000") {
+		t.Fatalf("expected status 000 for a refused connection:\n%s", out)
+	}
+
+	_, err = runPollTask(t, `version: 2.0
+
+task "wait":
+  get "http://127.0.0.1:1/health" capture body as $body
+`)
+	if err == nil {
+		t.Fatal("expected an error when only the body is captured and there is no response")
+	}
+}
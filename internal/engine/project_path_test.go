@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestProjectPathEntriesPrependedToShellPath verifies that `set path to include`
+// entries resolve against the project root and are searched before the inherited PATH.
+func TestProjectPathEntriesPrependedToShellPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script as the project-local tool")
+	}
+
+	projectDir := t.TempDir()
+	toolsDir := filepath.Join(projectDir, ".drun", "tools")
+	if err := os.MkdirAll(toolsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho project-local-tool\n"
+	if err := os.WriteFile(filepath.Join(toolsDir, "drun-path-probe"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `version: 2.0
+
+project "app":
+    set path to include ".drun/tools" and "node_modules/.bin"
+
+task "probe":
+    run "drun-path-probe"
+    run "echo PATH=$PATH"
+`
+	program := parseForWorkdirTest(t, input)
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+	specFile := filepath.Join(projectDir, ".drun", "spec.drun")
+	if err := eng.ExecuteWithParamsAndFile(program, "probe", nil, specFile); err != nil {
+		t.Fatalf("Execution failed: %v\nOutput:\n%s", err, out.String())
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "project-local-tool") {
+		t.Errorf("expected project-local tool to run, got:\n%s", output)
+	}
+
+	expectedPrefix := "PATH=" + toolsDir + string(os.PathListSeparator) + filepath.Join(projectDir, "node_modules", ".bin") + string(os.PathListSeparator)
+	if !strings.Contains(output, expectedPrefix) {
+		t.Errorf("expected PATH to start with project entries %q, got:\n%s", expectedPrefix, output)
+	}
+}
+
+// TestShellEnvironmentAllowDenyFiltersInheritedVariables verifies that shell
+// commands only inherit allowed variables and never denied ones.
+func TestShellEnvironmentAllowDenyFiltersInheritedVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell variable expansion")
+	}
+	t.Setenv("DRUN_ENV_CI_BUILD", "42")
+	t.Setenv("DRUN_ENV_CI_TOKEN", "secret")
+	t.Setenv("DRUN_ENV_OTHER", "leaked")
+
+	input := `version: 2.0
+
+project "app":
+    shell environment allow ["PATH", "DRUN_ENV_CI_*"]
+    shell environment deny ["*_TOKEN"]
+
+task "probe":
+    run "echo build=$DRUN_ENV_CI_BUILD token=$DRUN_ENV_CI_TOKEN other=$DRUN_ENV_OTHER"
+`
+	program := parseForWorkdirTest(t, input)
+
+	var out bytes.Buffer
+	eng := NewEngine(&out)
+	if err := eng.Execute(program, "probe"); err != nil {
+		t.Fatalf("Execution failed: %v\nOutput:\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "build=42 token= other=\n") {
+		t.Errorf("expected only the allowed variable to be inherited, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/secrets"
+	"github.com/phillarmonic/drun/v2/internal/types"
+)
+
+// settingRefRegex matches references to other project settings inside a
+// setting value: {$globals.key}, {key} and {$key}
+var settingRefRegex = regexp.MustCompile(`\$globals\.([A-Za-z_][A-Za-z0-9_]*)|\{\s*\$?([A-Za-z_][A-Za-z0-9_]*)\s*\}`)
+
+// resolveComputedSettings evaluates project settings that contain
+// interpolations or builtins (set image_tag to "{current git commit 'short'}")
+// once, when the project context is created. Settings are resolved in
+// dependency order so one setting can build on another; a reference cycle is
+// reported as an error. Values that refer to something only known at task
+// time (parameters, variables) are kept as written and interpolated on use.
+func (e *Engine) resolveComputedSettings(project *ProjectContext, currentFile string) error {
+	keys := make([]string, 0, len(project.Settings))
+	for key, value := range project.Settings {
+		if strings.Contains(value, "{") && !project.EncryptedSettings[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	ctx := &ExecutionContext{
+		Parameters:  make(map[string]*types.Value),
+		Variables:   make(map[string]string),
+		Project:     project,
+		CurrentFile: currentFile,
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(keys))
+	var path []string
+
+	var resolve func(key string) error
+	resolve = func(key string) error {
+		switch state[key] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for i, name := range path {
+				if name == key {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), key)
+			return fmt.Errorf("project settings form a cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[key] = visiting
+		path = append(path, key)
+		value := project.Settings[key]
+
+		for _, match := range settingRefRegex.FindAllStringSubmatch(value, -1) {
+			ref := match[1]
+			if ref == "" {
+				ref = match[2]
+			}
+			if _, isSetting := project.Settings[ref]; isSetting {
+				if err := resolve(ref); err != nil {
+					return err
+				}
+			}
+		}
+
+		if strings.Contains(value, "{") {
+			resolved, err := e.interpolator.InterpolateWithError(value, ctx)
+			switch {
+			case err == nil:
+				project.Settings[key] = resolved
+			case !strings.HasPrefix(err.Error(), "undefined "):
+				return fmt.Errorf("computing setting %q: %w", key, err)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[key] = done
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := resolve(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decryptSettings replaces the ENC[...] values of encrypted settings, of the
+// project and of its includes, with their plaintext, and masks every
+// plaintext in the engine output. The user's age identities are only loaded
+// when there is something to decrypt. A value that cannot be decrypted is
+// recorded in DecryptError rather than returned, so that listing or
+// inspecting tasks still works for users without the key; running them does
+// not.
+func (e *Engine) decryptSettings(project *ProjectContext) {
+	if len(project.EncryptedSettings) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(project.EncryptedSettings))
+	for key := range project.EncryptedSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	identities, err := secrets.LoadAgeIdentities()
+	if err != nil {
+		project.DecryptError = fmt.Errorf("cannot decrypt setting %s: %w", keys[0], err)
+		return
+	}
+	for _, key := range keys {
+		settings := project.Settings
+		if _, ok := settings[key]; !ok {
+			settings = project.IncludedSettings
+		}
+		plaintext, err := secrets.DecryptValue(settings[key], identities)
+		if err != nil {
+			project.DecryptError = fmt.Errorf("cannot decrypt setting %s: %w", key, err)
+			return
+		}
+		settings[key] = plaintext
+		e.credentials.maskSecret(plaintext)
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+	"github.com/phillarmonic/drun/v2/internal/parser"
+)
+
+func TestComputedProjectSettings(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set image_tag to "{registry}/app:{build_date}"
+  set build_date to "{now.format('20060102')}"
+  set registry to "ghcr.io/acme"
+  set deploy_target to "{$env}-cluster"
+
+task "show":
+  requires $env
+  info "tag={$globals.image_tag}"
+  info "target={$globals.deploy_target}"`
+
+	p := parser.NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parse errors: %v", p.Errors())
+	}
+
+	var output bytes.Buffer
+	eng := NewEngine(&output)
+	projectCtx, err := eng.BuildProjectContext(program.Project, "")
+	if err != nil {
+		t.Fatalf("BuildProjectContext failed: %v", err)
+	}
+
+	wantTag := "ghcr.io/acme/app:" + time.Now().Format("20060102")
+	if got := projectCtx.Settings["image_tag"]; got != wantTag {
+		t.Errorf("image_tag = %q, want %q", got, wantTag)
+	}
+	if got := projectCtx.Settings["deploy_target"]; got != "{$env}-cluster" {
+		t.Errorf("settings referring to parameters should stay lazy, got %q", got)
+	}
+
+	if err := eng.ExecuteWithParams(program, "show", map[string]string{"env": "prod"}); err != nil {
+		t.Fatalf("Unexpected execution error: %v\n%s", err, output.String())
+	}
+	for _, want := range []string{"tag=" + wantTag, "target=prod-cluster"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Expected %q in output, got:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestComputedProjectSettingsCycle(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set a to "{b}-x"
+  set b to "{$globals.c}"
+  set c to "{a}"
+
+task "show":
+  info "{a}"`
+
+	p := parser.NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parse errors: %v", p.Errors())
+	}
+
+	eng := NewEngine(&bytes.Buffer{})
+	_, err := eng.BuildProjectContext(program.Project, "")
+	if err == nil {
+		t.Fatal("Expected a cycle error")
+	}
+	if !strings.Contains(err.Error(), "project settings form a cycle: a -> b -> c -> a") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := eng.Execute(program, "show"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected execution to report the cycle, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runFromDrunFile(t *testing.T, input, drunFile string) string {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf))
+	if err := eng.ExecuteWithParamsAndFile(program, "paths", nil, drunFile); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	return buf.String()
+}
+
+func TestFileStatementsResolveAgainstProjectRoot(t *testing.T) {
+	project := t.TempDir()
+	drunFile := filepath.Join(project, ".drun", "spec.drun")
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+
+	out := runFromDrunFile(t, `version: 2.0
+
+task "paths":
+  info "root={project root} dir={drun file dir}"
+  write "hello" to file "notes.txt"
+  if file "notes.txt" exists:
+    info "found"
+`, drunFile)
+
+	if !strings.Contains(out, "root="+project+" dir="+filepath.Dir(drunFile)) {
+		t.Fatalf("unexpected path builtins:\n%s", out)
+	}
+	if data, err := os.ReadFile(filepath.Join(project, "notes.txt")); err != nil || string(data) != "hello" {
+		t.Fatalf("expected notes.txt in the project root: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, "notes.txt")); err == nil {
+		t.Fatal("did not expect notes.txt in the working directory")
+	}
+	if !strings.Contains(out, "found") {
+		t.Fatalf("expected the file condition to resolve against the project root:\n%s", out)
+	}
+}
+
+func TestFileStatementsResolveAgainstCwdWhenConfigured(t *testing.T) {
+	project := t.TempDir()
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+
+	runFromDrunFile(t, `version: 2.0
+
+project "app":
+  set relative_paths to "cwd"
+
+task "paths":
+  write "hello" to file "notes.txt"
+`, filepath.Join(project, "spec.drun"))
+
+	if _, err := os.Stat(filepath.Join(cwd, "notes.txt")); err != nil {
+		t.Fatalf("expected notes.txt in the working directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, "notes.txt")); err == nil {
+		t.Fatal("did not expect notes.txt in the project root")
+	}
+}
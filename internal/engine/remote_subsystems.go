@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/phillarmonic/drun/v2/internal/cache"
+	"github.com/phillarmonic/drun/v2/internal/engine/includes"
+	"github.com/phillarmonic/drun/v2/internal/remote"
+)
+
+// Domain: Remote Subsystems
+// This file builds the parts of the engine that only some programs need:
+// the include resolver with its remote fetchers, and the cache database they
+// share with provisioning sources. Listing tasks of a file without includes
+// creates neither.
+
+// includes returns the include resolver, creating it and its remote fetchers
+// on first use
+func (e *Engine) includes() *includes.Resolver {
+	e.includesOnce.Do(func() {
+		// Includes are fetched before a run starts, so the project's
+		// `set network retries` does not apply to them
+		githubFetcher := remote.NewGitHubFetcher()
+		githubFetcher.SetRetryPolicy(e.networkPolicy(nil, "fetching include from GitHub"))
+		httpsFetcher := remote.NewHTTPSFetcher()
+		httpsFetcher.SetRetryPolicy(e.networkPolicy(nil, "fetching include"))
+		e.includesResolver = includes.NewResolver(
+			nil,
+			githubFetcher,
+			httpsFetcher,
+			remote.NewDrunhubFetcher(githubFetcher),
+			e.verbose,
+			e.out(nil),
+			ParseStringWithFilename,
+		)
+		e.includesResolver.SetCacheProvider(e.includeCache)
+	})
+	return e.includesResolver
+}
+
+// includeCache returns the cache for remote includes and provisioning
+// sources, opening its database on first use; nil when caching is off
+func (e *Engine) includeCache() *cache.Manager {
+	e.cacheOnce.Do(func() {
+		if e.cacheManager != nil || !e.cacheEnabled {
+			return
+		}
+		cacheManager, err := cache.NewManager(e.cacheTTL, false)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to initialize remote include cache: %v\n", err)
+			return
+		}
+		e.cacheManager = cacheManager
+	})
+	return e.cacheManager
+}
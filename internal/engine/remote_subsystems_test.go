@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"io"
+	"testing"
+)
+
+func TestListingWithoutIncludesSkipsRemoteSubsystems(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	program, err := ParseString(largeProgramSource(3))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	eng := NewEngine(io.Discard)
+	defer eng.Cleanup()
+	if err := eng.SetCacheEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := eng.ListTasksWithIncludes(program, "spec.drun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(tasks))
+	}
+	if eng.includesResolver != nil {
+		t.Error("the include resolver should not be created for a file without includes")
+	}
+	if eng.cacheManager != nil {
+		t.Error("the remote include cache should not be opened for a file without includes")
+	}
+}
+
+func TestIncludeCacheOpensOnFirstUse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	eng := NewEngine(io.Discard)
+	defer eng.Cleanup()
+
+	if eng.includeCache() != nil {
+		t.Fatal("the cache should stay closed while caching is disabled")
+	}
+
+	eng = NewEngine(io.Discard)
+	defer eng.Cleanup()
+	if err := eng.SetCacheEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+	first := eng.includeCache()
+	if first == nil || eng.includeCache() != first {
+		t.Fatal("expected the cache to open once and be reused")
+	}
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeSSH writes a script standing in for ssh: it runs the command locally
+// with HOST set, and fails on hosts named "bad-*"
+func fakeSSH(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ssh is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "fake-ssh")
+	script := "#!/bin/sh\nHOST=\"$1\"; export HOST\ncase \"$1\" in bad-*) echo \"connection refused\" >&2; exit 255;; esac\nexec sh -c \"$2\"\n"
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runFleetTask(t *testing.T, input string, dryRun bool) (string, error) {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	eng.SetDryRun(dryRun)
+	err = eng.Execute(program, "fleet")
+	return buf.String(), err
+}
+
+func TestRunOnHostGroup(t *testing.T) {
+	ssh := fakeSSH(t)
+	out, err := runFleetTask(t, `version: 2.0
+
+project "ops":
+  set ssh_command to "`+ssh+`"
+  hosts group "web" = ["web-1", "web-2", "web-3"]
+
+task "fleet":
+  run "echo up on $HOST; printf last" on group "web" in parallel with 2 workers
+`, false)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	for _, host := range []string{"web-1", "web-2", "web-3"} {
+		if !strings.Contains(out, "["+host+"] up on "+host+"\n") || !strings.Contains(out, "["+host+"] last\n") {
+			t.Errorf("expected prefixed output of %s:\n%s", host, out)
+		}
+	}
+}
+
+func TestRunOnHostGroupReportsEveryFailure(t *testing.T) {
+	ssh := fakeSSH(t)
+	out, err := runFleetTask(t, `version: 2.0
+
+project "ops":
+  set ssh_command to "`+ssh+`"
+  hosts group "web" = ["bad-1", "web-2", "bad-3"]
+
+task "fleet":
+  run "echo up on $HOST" on group "web" quietly
+`, false)
+	if err == nil {
+		t.Fatalf("expected the failed hosts to fail the task:\n%s", out)
+	}
+	for _, want := range []string{"command failed on 2 of 3 hosts of group 'web'", "bad-1: exit status 255", "bad-3: exit status 255"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to contain %q, got:\n%v", want, err)
+		}
+	}
+	// Quiet output is only shown for the hosts that failed
+	if !strings.Contains(out, "[bad-1] connection refused") || strings.Contains(out, "up on web-2") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestRunOnHostGroupDryRun(t *testing.T) {
+	out, err := runFleetTask(t, `version: 2.0
+
+project "ops":
+  hosts group "web" = ["web-1", "web-2"]
+
+task "fleet":
+  run "uptime" on group "web" in parallel
+  run "uptime" on group "db"
+`, true)
+	if err == nil || !strings.Contains(err.Error(), "unknown host group 'db'") {
+		t.Errorf("expected an unknown group error, got %v", err)
+	}
+	for _, want := range []string{
+		"[DRY RUN] Would run on group 'web' in parallel with 2 workers: uptime",
+		"[DRY RUN]   ssh -o BatchMode=yes web-2 uptime",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the dry run:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+)
+
+func TestParallelLoopFailuresAreReplayed(t *testing.T) {
+	input := `version: 2.0
+task "check":
+	given $bad defaults to "b"
+	for each $item in ["a", "b", "c", "d"] in parallel:
+		run "echo checked {$item}; test {$item} != {$bad} && test {$item} != d"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".drun", "spec.drun")
+	store := runhistory.NewStore(filepath.Join(dir, "history", "run-history.json"))
+	params := map[string]string{"bad": "b"}
+
+	var out bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&out), WithRunHistory(store))
+	if err := eng.ExecuteWithParamsAndFile(program, "check", params, file); err == nil {
+		t.Fatal("expected the loop to fail")
+	}
+	if !strings.Contains(out.String(), "2 loop items failed; replay only those with: xdrun cmd:rerun --last-failed") {
+		t.Errorf("output does not offer the rerun:\n%s", out.String())
+	}
+
+	failed, found, err := store.LastFailed(dir)
+	if err != nil || !found {
+		t.Fatalf("LastFailed() = %v, %v; want the failed run", found, err)
+	}
+	if got := failed.Loops["check/item"]; !reflect.DeepEqual(got, []string{"b", "d"}) {
+		t.Errorf("failed items = %v, want [b d]", got)
+	}
+	if len(failed.Targets) != 1 || failed.Targets[0].Task != "check" || failed.Targets[0].Params["bad"] != "b" {
+		t.Errorf("targets = %+v, want check with bad=b", failed.Targets)
+	}
+
+	// The replay runs only the failed items; d still fails and stays recorded
+	out.Reset()
+	eng = NewEngineWithOptions(WithOutput(&out), WithRunHistory(store), WithLoopReplay(failed.Loops))
+	if err := eng.ExecuteWithParamsAndFile(program, "check", map[string]string{"bad": "x"}, file); err == nil {
+		t.Fatal("expected d to fail again")
+	}
+	if !strings.Contains(out.String(), "Replaying 2 of 4 items") || strings.Contains(out.String(), "checked a") || !strings.Contains(out.String(), "checked b") {
+		t.Errorf("replay did not run only the failed items:\n%s", out.String())
+	}
+	if failed, _, _ = store.LastFailed(dir); !reflect.DeepEqual(failed.Loops["check/item"], []string{"d"}) {
+		t.Errorf("failed items after the replay = %v, want [d]", failed.Loops["check/item"])
+	}
+
+	// A replay whose items all pass clears the record
+	program, _ = ParseString(strings.Replace(input, " && test {$item} != d", "", 1))
+	eng = NewEngineWithOptions(WithOutput(&out), WithRunHistory(store), WithLoopReplay(failed.Loops))
+	if err := eng.ExecuteWithParamsAndFile(program, "check", map[string]string{"bad": "x"}, file); err != nil {
+		t.Fatalf("replay error = %v", err)
+	}
+	if _, found, _ := store.LastFailed(dir); found {
+		t.Error("a passing replay did not clear the failed run")
+	}
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResourcePool(t *testing.T) {
+	pool := newResourcePool(newResourceNeeds(4, 8<<30))
+
+	if got := pool.clamp(newResourceNeeds(6, 1<<30)); got != newResourceNeeds(4, 1<<30) {
+		t.Errorf("clamp() = %+v, want the cpus reduced to the pool", got)
+	}
+	if got := pool.fits(newResourceNeeds(1, 4<<30)); got != 2 {
+		t.Errorf("fits() = %d, want 2 (limited by memory)", got)
+	}
+	if got := newResourcePool(resourceNeeds{}).fits(newResourceNeeds(1, 0)); got != 0 {
+		t.Errorf("fits() on an unlimited pool = %d, want 0", got)
+	}
+
+	needs := newResourceNeeds(3, 0)
+	if !pool.tryAcquire(needs) {
+		t.Fatal("tryAcquire() on an empty pool failed")
+	}
+	if pool.tryAcquire(needs) {
+		t.Fatal("tryAcquire() succeeded beyond the pool")
+	}
+	acquired := make(chan struct{})
+	go func() {
+		pool.acquire(needs)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire() did not wait for the release")
+	case <-time.After(50 * time.Millisecond):
+	}
+	pool.release(needs)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire() did not return after the release")
+	}
+}
+
+func TestConcurrentTargetsWaitForTheirNeeds(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+task "a":
+	needs 2 cpus
+	run "sleep 0.2"
+	info "a done"
+
+task "b":
+	needs 2 cpus
+	run "sleep 0.2"
+	info "b done"
+`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithResourceLimits(3, 0))
+	targets := []TaskTarget{{Name: "a", Params: map[string]string{}}, {Name: "b", Params: map[string]string{}}}
+	started := time.Now()
+	if err := eng.ExecuteTargets(program, targets, "", true); err != nil {
+		t.Fatalf("execution failed: %v\nOutput: %s", err, buf.String())
+	}
+	if elapsed := time.Since(started); elapsed < 400*time.Millisecond {
+		t.Errorf("targets ran together in %v although only one fits", elapsed)
+	}
+	if count := strings.Count(buf.String(), "is waiting for 2 cpus"); count != 1 {
+		t.Errorf("expected one target to wait, got %d\nOutput: %s", count, buf.String())
+	}
+}
+
+func TestParallelLoopWorkersFitTheTaskNeeds(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+task "build":
+	needs 2 cpus and 1GB memory
+	for each $pkg in ["a", "b", "c", "d", "e"] in parallel:
+		info "building {$pkg}"
+`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithResourceLimits(4, 8<<30))
+	eng.SetDryRun(true)
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	for _, want := range []string{"Would reserve 2 cpus and 1GB memory for task 'build'", "Would execute 5 items in parallel (max workers: 2)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	// Items take over the task's reservation, so a loop never waits for it
+	buf.Reset()
+	eng = NewEngineWithOptions(WithOutput(&buf), WithResourceLimits(2, 0))
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("execution failed: %v\nOutput: %s", err, buf.String())
+	}
+	if strings.Count(buf.String(), "building") != 5 {
+		t.Errorf("expected every item to run:\n%s", buf.String())
+	}
+}
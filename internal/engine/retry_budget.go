@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Domain: Retry Budget
+// This file caps the retries of a whole run with `set retry budget to N`.
+// Every statement that tries again (poll attempts, service build retries)
+// takes one retry from the budget; once it is spent, further retries fail
+// at once instead of spinning. {retries.used} reports the retries so far.
+
+// retryBudgetSetting is the project setting key written by
+// `set retry budget to N`
+const retryBudgetSetting = "retry_budget"
+
+// retryBudgetWarnRatio is the share of the budget after which a warning is printed
+const retryBudgetWarnRatio = 0.8
+
+// retryBudget counts the retries of an execution. Parallel targets share it,
+// so it is safe for concurrent use.
+type retryBudget struct {
+	mu     sync.Mutex
+	limit  int // 0 means unlimited
+	used   int
+	warned bool
+}
+
+// take records one retry. It reports false, without recording it, when the
+// budget is spent, and whether this retry crossed the warning threshold.
+func (b *retryBudget) take() (ok bool, warn bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit > 0 && b.used >= b.limit {
+		return false, false
+	}
+	b.used++
+	if b.limit > 0 && !b.warned && float64(b.used) >= float64(b.limit)*retryBudgetWarnRatio {
+		b.warned = true
+		return true, true
+	}
+	return true, false
+}
+
+// usage returns the retries used so far and the limit
+func (b *retryBudget) usage() (used, limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used, b.limit
+}
+
+// retryBudgetLimit returns the `set retry budget` of the project, 0 when unset
+func retryBudgetLimit(projectCtx *ProjectContext) (int, error) {
+	if projectCtx == nil {
+		return 0, nil
+	}
+	raw, ok := projectCtx.Settings[retryBudgetSetting]
+	if !ok {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("set retry budget: expected a whole number of retries, got %q", raw)
+	}
+	return limit, nil
+}
+
+// takeRetry takes one retry of what from the run's budget, warning when the
+// budget is nearly spent and failing once it is
+func (e *Engine) takeRetry(ctx *ExecutionContext, what string) error {
+	if ctx == nil || ctx.Retries == nil {
+		return nil
+	}
+	ok, warn := ctx.Retries.take()
+	used, limit := ctx.Retries.usage()
+	if !ok {
+		return fmt.Errorf("retry budget of %d exhausted; not retrying %s", limit, what)
+	}
+	if warn {
+		e.iconf(ctx, "⚠️  ", "Retry budget nearly spent: %d of %d retries used\n", used, limit)
+	}
+	return nil
+}
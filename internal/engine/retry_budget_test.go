@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRetryBudgetStopsRetriesAcrossTheRun(t *testing.T) {
+	server, calls := newHealthServer(t, 1000)
+	out, err := runPollTask(t, `version: 2.0
+
+project "budget":
+  set retry budget to 4
+
+task "wait":
+  poll every 5ms up to 5s:
+    get "`+server.URL+`/health" capture status as $code
+  until {$code} is "200"
+  info "healthy"
+`)
+	if err == nil || !strings.Contains(err.Error(), "retry budget of 4 exhausted") {
+		t.Fatalf("expected the retry budget to stop the poll, got %v\n%s", err, out)
+	}
+	if n := calls.Load(); n != 5 {
+		t.Fatalf("expected the first attempt and 4 retries, got %d requests", n)
+	}
+	if !strings.Contains(out, "Retry budget nearly spent: 4 of 4 retries used") {
+		t.Fatalf("expected a warning before the budget ran out:\n%s", out)
+	}
+}
+
+func TestRetriesUsedBuiltin(t *testing.T) {
+	server, _ := newHealthServer(t, 3)
+	out, err := runPollTask(t, `version: 2.0
+
+task "wait":
+  info "before: {retries.used}"
+  poll every 5ms up to 5s:
+    get "`+server.URL+`/health" capture status as $code
+  until {$code} is "200"
+  info "after: {retries.used}"
+`)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"before: 0", "after: 2"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Retry budget") {
+		t.Fatalf("runs without a budget should not warn:\n%s", out)
+	}
+}
+
+func TestRetryBudgetMustBeAWholeNumber(t *testing.T) {
+	_, err := runPollTask(t, `version: 2.0
+
+project "budget":
+  set retry budget to "lots"
+
+task "wait":
+  info "never"
+`)
+	if err == nil || !strings.Contains(err.Error(), "set retry budget") {
+		t.Fatalf("expected an invalid retry budget error, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// installFakeSudo puts a sudo on PATH that logs its arguments to the returned
+// file, answers "sudo -n -v" with validateExit and otherwise runs the command
+// after "--"
+func installFakeSudo(t *testing.T, validateExit string) string {
+	t.Helper()
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "sudo.log")
+	script := `#!/bin/sh
+echo "$*" >> "` + logFile + `"
+if [ "$1" = "-n" ] && [ "$2" = "-v" ]; then exit ` + validateExit + `; fi
+while [ "$1" != "--" ]; do shift; done
+shift
+exec "$@"
+`
+	if err := os.WriteFile(filepath.Join(dir, "sudo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logFile
+}
+
+func TestRunAsUserGoesThroughSudo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sudo from a POSIX system")
+	}
+	logFile := installFakeSudo(t, "0")
+
+	input := `version: 2.0
+
+task "restart":
+  run "echo escalated" as user "nobody"
+  run as user "nobody":
+    echo from-block
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "restart"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "escalated") || !strings.Contains(buf.String(), "from-block") {
+		t.Errorf("expected the commands' output, got:\n%s", buf.String())
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if !strings.Contains(log, "-n -v\n") {
+		t.Errorf("expected sudo authentication to be checked, got:\n%s", log)
+	}
+	if !strings.Contains(log, "-n -u nobody -- ") || !strings.Contains(log, " -c echo escalated") || !strings.Contains(log, " -c echo from-block") {
+		t.Errorf("expected the command to run through sudo as nobody, got:\n%s", log)
+	}
+}
+
+func TestRunAsWithoutTerminalExplainsHowToAuthenticate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sudo from a POSIX system")
+	}
+	installFakeSudo(t, "1")
+	original := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	t.Cleanup(func() { stdinIsTerminal = original })
+
+	program, err := ParseString("version: 2.0\n\ntask \"restart\":\n  run \"echo escalated\" as user \"nobody\"\n")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "restart")
+	if err == nil || !strings.Contains(err.Error(), "not attached to a terminal") {
+		t.Fatalf("expected an error about the missing terminal, got %v", err)
+	}
+	if strings.Contains(buf.String(), "escalated\n") {
+		t.Errorf("the command must not run without authentication:\n%s", buf.String())
+	}
+}
+
+func TestRunAsDryRunShowsTheEscalatedCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("renders a sudo command line")
+	}
+
+	program, err := ParseString("version: 2.0\n\ntask \"restart\":\n  run \"systemctl restart nginx\" as root\n")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	eng.SetDryRun(true)
+	if err := eng.Execute(program, "restart"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "[DRY RUN] Would run as root: sudo -n -- ") || !strings.Contains(buf.String(), " -c 'systemctl restart nginx'") {
+		t.Errorf("expected the sudo command line, got:\n%s", buf.String())
+	}
+}
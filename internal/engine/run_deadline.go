@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Domain: Run Duration
+// This file caps how long a whole run may take with --max-duration or
+// `set max duration to "30m"`. Once the budget is spent, running commands
+// are killed and no further statement starts, except in finally blocks and
+// teardown hooks, which still run so the run cleans up after itself.
+
+// maxDurationSetting is the project setting key written by
+// `set max duration to "30m"`
+const maxDurationSetting = "max_duration"
+
+// ErrMaxDuration is returned when a run takes longer than its maximum
+// duration
+var ErrMaxDuration = errors.New("maximum run duration exceeded")
+
+// runDeadline is the point in time a run must finish by. Parallel targets
+// share it, so it is safe for concurrent use.
+type runDeadline struct {
+	limit    time.Duration
+	at       time.Time
+	cleanup  atomic.Int32 // finally blocks and teardown hooks running
+	reported atomic.Bool
+}
+
+// newRunDeadline starts the clock of a run limited to limit; it returns nil
+// for no limit
+func newRunDeadline(limit time.Duration) *runDeadline {
+	if limit <= 0 {
+		return nil
+	}
+	return &runDeadline{limit: limit, at: time.Now().Add(limit)}
+}
+
+// remaining returns the time left for a command to run, and false when the
+// run has no limit or cleanup is running
+func (d *runDeadline) remaining() (time.Duration, bool) {
+	if d == nil || d.cleanup.Load() > 0 {
+		return 0, false
+	}
+	// A zero timeout means none, so a spent budget still kills at once
+	return max(time.Until(d.at), time.Millisecond), true
+}
+
+// exceeded reports whether the budget is spent outside cleanup
+func (d *runDeadline) exceeded() bool {
+	return d != nil && d.cleanup.Load() == 0 && !time.Now().Before(d.at)
+}
+
+// startCleanup lets statements run past the deadline until the returned
+// function is called
+func (d *runDeadline) startCleanup() func() {
+	if d == nil {
+		return func() {}
+	}
+	d.cleanup.Add(1)
+	return func() { d.cleanup.Add(-1) }
+}
+
+// waitContext returns a context for waits that run no command, such as for a
+// lock: it is cancelled once the run exceeds its maximum duration or drun is
+// interrupted. Call stop when the wait is over.
+func waitContext(ctx *ExecutionContext) (wait context.Context, stop func()) {
+	wait, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	remaining, limited := ctx.Deadline.remaining()
+	if !limited {
+		return wait, stopSignals
+	}
+	wait, cancel := context.WithTimeout(wait, remaining)
+	return wait, func() {
+		cancel()
+		stopSignals()
+	}
+}
+
+// maxDurationLimit returns the `set max duration` of the project, 0 when unset
+func maxDurationLimit(projectCtx *ProjectContext) (time.Duration, error) {
+	if projectCtx == nil {
+		return 0, nil
+	}
+	raw, ok := projectCtx.Settings[maxDurationSetting]
+	if !ok {
+		return 0, nil
+	}
+	limit, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("set max duration: expected a duration such as \"30m\", got %q", raw)
+	}
+	return limit, nil
+}
+
+// isMaxDuration reports whether err stopped a run for taking too long
+func isMaxDuration(err error) bool {
+	return errors.Is(err, ErrMaxDuration)
+}
+
+// checkDeadline fails once the run has taken longer than its maximum
+// duration, announcing it the first time
+func (e *Engine) checkDeadline(ctx *ExecutionContext) error {
+	if ctx == nil || !ctx.Deadline.exceeded() {
+		return nil
+	}
+	if ctx.Deadline.reported.CompareAndSwap(false, true) {
+		e.iconf(ctx, "⏱️  ", "Run exceeded its maximum duration of %s; stopping\n", ctx.Deadline.limit)
+	}
+	return fmt.Errorf("%w (%s)", ErrMaxDuration, ctx.Deadline.limit)
+}
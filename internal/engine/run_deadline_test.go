@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxDurationStopsTheRunButStillCleansUp(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	started := time.Now()
+	out, err := runPollTask(t, `version: 2.0
+
+project "ci":
+  on drun teardown:
+    info "teardown ran"
+
+task "wait":
+  try:
+    run "sleep 5"
+    info "after sleep"
+  catch:
+    info "caught"
+  finally:
+    run "sleep 0.3"
+    info "finally ran"
+  info "after try"
+`, WithMaxDuration(500*time.Millisecond))
+	if !errors.Is(err, ErrMaxDuration) {
+		t.Fatalf("expected ErrMaxDuration, got %v\n%s", err, out)
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Fatalf("expected the sleep to be killed, the run took %s", elapsed)
+	}
+	for _, want := range []string{"Run exceeded its maximum duration of 500ms", "finally ran", "teardown ran"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"after sleep", "caught", "after try"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("did not expect %q in output:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestMaxDurationProjectSetting(t *testing.T) {
+	out, err := runPollTask(t, `version: 2.0
+
+project "ci":
+  set max duration to "1h"
+
+task "wait":
+  info "done"
+`)
+	if err != nil || !strings.Contains(out, "done") {
+		t.Fatalf("expected a run within its max duration to succeed, got %v\n%s", err, out)
+	}
+
+	_, err = runPollTask(t, `version: 2.0
+
+project "ci":
+  set max duration to "soon"
+
+task "wait":
+  info "never"
+`)
+	if err == nil || !strings.Contains(err.Error(), "set max duration") {
+		t.Fatalf("expected an invalid max duration error, got %v", err)
+	}
+}
+
+func TestMaxDurationFlagOverridesProjectSetting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	out, err := runPollTask(t, `version: 2.0
+
+project "ci":
+  set max duration to "100ms"
+
+task "wait":
+  run "sleep 0.3"
+  info "done"
+`, WithMaxDuration(time.Minute))
+	if err != nil || !strings.Contains(out, "done") {
+		t.Fatalf("expected --max-duration to win over the project setting, got %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/runhistory"
+)
+
+func TestOncePerCommitSkipsTasksThatAlreadySucceeded(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=drun", "-c", "user.email=drun@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	program, err := ParseString(`version: 2.0
+
+task "verify":
+  once per commit
+  given $env defaults to "dev"
+  info "verifying {$env}"
+`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	store := runhistory.NewStore(filepath.Join(t.TempDir(), "run-history.json"))
+	run := func(params map[string]string, force bool) string {
+		t.Helper()
+		var buf bytes.Buffer
+		eng := NewEngineWithOptions(WithOutput(&buf), WithRunHistory(store), WithForce(force))
+		if err := eng.ExecuteWithParamsAndFile(program, "verify", params, filepath.Join(dir, "spec.drun")); err != nil {
+			t.Fatalf("Execute() error = %v\n%s", err, buf.String())
+		}
+		return buf.String()
+	}
+
+	if out := run(nil, false); !strings.Contains(out, "verifying dev") {
+		t.Fatalf("expected the first run to execute, got: %s", out)
+	}
+	if out := run(nil, false); strings.Contains(out, "verifying") || !strings.Contains(out, "already succeeded for commit") {
+		t.Errorf("expected the second run to be skipped, got: %s", out)
+	}
+	if out := run(map[string]string{"env": "prod"}, false); !strings.Contains(out, "verifying prod") {
+		t.Errorf("expected a run with other parameters to execute, got: %s", out)
+	}
+	if out := run(nil, true); !strings.Contains(out, "verifying dev") {
+		t.Errorf("expected --force to run the task again, got: %s", out)
+	}
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Domain: Run Context
+// This file describes the current invocation for the {run.id} and
+// {run.started_at} builtins. Both are computed once when a run starts and
+// shared by every task, hook and called task of the run.
+
+// runInfo identifies one run of the engine
+type runInfo struct {
+	id        string    // random UUID
+	startedAt time.Time // when the run started
+}
+
+// newRunInfo starts describing a run that begins now
+func newRunInfo() *runInfo {
+	return &runInfo{id: newRunID(), startedAt: time.Now()}
+}
+
+// newRunID returns a random (version 4) UUID
+func newRunID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // never fails on supported platforms
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"bytes"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunContextBuiltins(t *testing.T) {
+	input := `version: 2.0
+
+task "release":
+  info "run={run.id} started={run.started_at} task={task.name} file={task.file}"
+  info "version={drun.version} platform={os}/{arch} cpus={cpu.count}"
+  call task "publish"
+
+task "publish":
+  info "run={run.id} task={task.name}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithDrunVersion("2.7.1"))
+	if err := eng.ExecuteWithParamsAndFile(program, "release", nil, "/work/app/.drun/spec.drun"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	out := buf.String()
+
+	ids := regexp.MustCompile(`run=([0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}) `).FindAllStringSubmatch(out, -1)
+	if len(ids) != 2 || ids[0][1] != ids[1][1] {
+		t.Fatalf("expected the same run UUID in both tasks:\n%s", out)
+	}
+	started := regexp.MustCompile(`started=(\S+)`).FindStringSubmatch(out)
+	if started == nil {
+		t.Fatalf("expected the start time:\n%s", out)
+	}
+	if _, err := time.Parse(time.RFC3339, started[1]); err != nil {
+		t.Errorf("run.started_at = %q is not RFC 3339: %v", started[1], err)
+	}
+
+	for _, want := range []string{
+		"task=release file=" + absPath("/work/app/.drun/spec.drun"),
+		"version=2.7.1 platform=" + runtime.GOOS + "/" + runtime.GOARCH + " cpus=" + strconv.Itoa(runtime.NumCPU()),
+		"task=publish",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunContextBuiltinsYieldToParameters(t *testing.T) {
+	input := `version: 2.0
+
+task "build":
+  given $os defaults to "plan9"
+  info "target={os}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "build"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "target=plan9") {
+		t.Errorf("expected the parameter to win over {os}:\n%s", buf.String())
+	}
+}
@@ -180,6 +180,9 @@ func extractFromStatement(stmt ast.Statement, extractFromString func(string)) {
 		for _, cmd := range s.Commands {
 			extractFromString(cmd)
 		}
+		for _, cmd := range s.PipeInto {
+			extractFromString(cmd)
+		}
 		if s.ServiceName != "" && !s.ServiceNameIsLiteral {
 			extractFromString(s.ServiceName)
 		}
@@ -313,6 +316,10 @@ func extractFromStatement(stmt ast.Statement, extractFromString func(string)) {
 			extractFromStatement(stmt, extractFromString)
 		}
 
+	case *ast.GuardedStatement:
+		extractFromString(s.Condition)
+		extractFromStatement(s.Statement, extractFromString)
+
 	case *ast.ConditionalStatement:
 		if s.Condition != "" {
 			extractFromString(s.Condition)
@@ -340,6 +347,41 @@ func extractFromStatement(stmt ast.Statement, extractFromString func(string)) {
 		if s.Filter != nil && s.Filter.Value != "" {
 			extractFromString(s.Filter.Value)
 		}
+		if s.Condition != "" {
+			extractFromString(s.Condition)
+		}
+		for _, stmt := range s.Body {
+			extractFromStatement(stmt, extractFromString)
+		}
+
+	case *ast.PluginStatement:
+		for _, arg := range s.Args {
+			extractFromString(arg)
+		}
+
+	case *ast.CompareStatement:
+		extractFromString(s.Left)
+		extractFromString(s.Right)
+
+	case *ast.VersionFilesStatement:
+		for _, file := range s.Files {
+			extractFromString(file)
+		}
+
+	case *ast.ConfirmStatement:
+		extractFromString(s.Question)
+
+	case *ast.TodoStatement:
+		extractFromString(s.Message)
+
+	case *ast.WithinStatement:
+		for _, stmt := range s.Body {
+			extractFromStatement(stmt, extractFromString)
+		}
+
+	case *ast.LockStatement:
+		extractFromString(s.Name)
+		extractFromString(s.Backend)
 		for _, stmt := range s.Body {
 			extractFromStatement(stmt, extractFromString)
 		}
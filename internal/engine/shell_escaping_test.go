@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStrictShellEscapingQuotesInterpolatedValues(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  set shell escaping to "strict"
+
+task "escape":
+  given $msg defaults to "a b; echo injected"
+  capture from shell "printf '[%s]' {$msg}" as $bare
+  capture from shell "printf '[%s]' \"{$msg}\"" as $double
+  capture from shell "printf '[%s]' 'it'\\''s {$msg}'" as $single
+  capture from shell "printf '[%s]' {$msg | raw}" as $raw
+  info "bare={$bare}"
+  info "double={$double}"
+  info "single={$single}"
+  info "raw={$raw}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "escape"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"bare=[a b; echo injected]",
+		"double=[a b; echo injected]",
+		"single=[it's a b; echo injected]",
+		"raw=[a][b]injected",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestShellEscapingRejectsUnknownMode(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  set shell escaping to "paranoid"
+
+task "noop":
+  info "never runs"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "noop")
+	if err == nil || !strings.Contains(err.Error(), `set shell escaping: expected "strict" or "off", got "paranoid"`) {
+		t.Fatalf("expected an unknown mode error, got %v", err)
+	}
+}
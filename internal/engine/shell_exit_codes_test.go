@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMappedExitCodeIsAnOutcome(t *testing.T) {
+	input := `
+version: 2.0
+
+task "plan":
+  run "exit 2" mapping exit code 0 to "no changes" and exit code 2 to "changes pending" as $plan
+  info "plan: {$plan}"
+  run mapping exit code 1 to "dirty" as $tree:
+    echo checking
+    exit 1
+  info "after {$tree}"
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "plan", &buf); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Exit code 2: changes pending") || !strings.Contains(output, "plan: changes pending") {
+		t.Fatalf("expected mapped outcome in output, got: %s", output)
+	}
+	if !strings.Contains(output, "Exit code 1: dirty") || !strings.Contains(output, "after dirty") {
+		t.Fatalf("expected multiline mapping to continue the task, got: %s", output)
+	}
+}
+
+func TestUnmappedExitCodeFailsWithCustomMessage(t *testing.T) {
+	input := `
+version: 2.0
+
+task "plan":
+  run "exit 3" mapping exit code 2 to "changes pending" failing with "could not plan; run terraform init first"
+`
+
+	var buf bytes.Buffer
+	err := ExecuteString(input, "plan", &buf)
+	if err == nil {
+		t.Fatal("expected an unmapped exit code to fail")
+	}
+	if !strings.Contains(err.Error(), "could not plan") || !strings.Contains(err.Error(), "exit code 3") {
+		t.Fatalf("expected custom message with the exit code, got: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDryRunSyntaxCheckReportsTheCommandAndLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+	program, err := ParseString(`version: 2.0
+
+task "greet":
+  given $name defaults to "it's me"
+  run "echo ok"
+  run "echo '{$name}'"
+  run "echo never checked"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithDryRun(true), WithShellSyntaxCheck(true))
+	err = eng.Execute(program, "greet")
+	if err == nil {
+		t.Fatalf("expected a syntax error:\n%s", buf.String())
+	}
+	for _, want := range []string{"shell syntax error in task 'greet' at line 6: ", "\n  command: echo 'it's me'"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in the error, got: %v", want, err)
+		}
+	}
+	if strings.Contains(buf.String(), "never checked") {
+		t.Errorf("expected the dry run to stop at the syntax error:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewEngineWithOptions(WithOutput(&buf), WithDryRun(true)).Execute(program, "greet"); err != nil {
+		t.Fatalf("expected dry runs without the check to pass, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQuietlyHidesOutputOnSuccess(t *testing.T) {
+	input := `
+version: 2.0
+
+task "install":
+  run "echo noisy install output" quietly
+  run:
+    echo visible output
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "install", &buf); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "noisy install output") {
+		t.Fatalf("Expected quietly to suppress successful output, got: %s", output)
+	}
+	if !strings.Contains(output, "visible output") {
+		t.Fatalf("Expected other statements to keep streaming, got: %s", output)
+	}
+}
+
+func TestQuietlyDumpsCapturedOutputOnFailure(t *testing.T) {
+	input := `
+version: 2.0
+
+task "install":
+  run quietly:
+    echo resolving packages
+    echo missing peer dependency >&2
+    exit 2
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "install", &buf); err == nil {
+		t.Fatal("Expected quiet statement to fail")
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "stdout:\nresolving packages") {
+		t.Fatalf("Expected captured stdout to be dumped on failure, got: %s", output)
+	}
+	if !strings.Contains(output, "failed with exit code 2") {
+		t.Fatalf("Expected failure summary, got: %s", output)
+	}
+}
+
+func TestVerboselyShowsDetailsAndOverridesCIBuffering(t *testing.T) {
+	input := `
+version: 2.0
+
+task "ci" mode "ci":
+  run "echo listing files" verbosely
+  run "echo buffered output"
+`
+
+	var buf bytes.Buffer
+	if err := ExecuteString(input, "ci", &buf); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Running: echo listing files") {
+		t.Fatalf("Expected verbosely to show the command, got: %s", output)
+	}
+	if !strings.Contains(output, "listing files\n") {
+		t.Fatalf("Expected verbosely to stream output in ci mode, got: %s", output)
+	}
+	if !strings.Contains(output, "Command completed successfully") {
+		t.Fatalf("Expected verbosely to show the completion summary, got: %s", output)
+	}
+	if strings.Contains(output, "Running: echo buffered output") || strings.Contains(output, "buffered output\n") {
+		t.Fatalf("Expected statements without modifiers to follow ci mode, got: %s", output)
+	}
+}
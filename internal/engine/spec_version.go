@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// Domain: Spec Versions
+// This file contains the front-end check that routes a drun file by the spec
+// version it declares before it is parsed.
+
+// SupportedSpecVersions lists the major spec versions this binary can run
+var SupportedSpecVersions = []string{"2"}
+
+// SpecVersion returns the version declared by the `version:` statement that
+// opens source, or "" when the file does not start with one. Only the header
+// is read, so files written for another spec version are identified without
+// parsing them.
+func SpecVersion(source string) string {
+	source = lexer.Normalize(source)
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rest, ok := strings.CutPrefix(line, "version:")
+		if !ok {
+			return ""
+		}
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+		return ""
+	}
+	return ""
+}
+
+// SpecMajorVersion returns the major part of a spec version, "2" for "2.0"
+func SpecMajorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// IsSupportedSpecVersion reports whether this binary runs files declaring
+// version
+func IsSupportedSpecVersion(version string) bool {
+	major := SpecMajorVersion(version)
+	for _, supported := range SupportedSpecVersions {
+		if major == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSpecVersion rejects files declaring a spec version this binary does
+// not run, before the parser reports confusing syntax errors for them.
+// Files without a version statement are left to the parser.
+func checkSpecVersion(source, filename string) error {
+	version := SpecVersion(source)
+	if version == "" || IsSupportedSpecVersion(version) {
+		return nil
+	}
+
+	return fmt.Errorf("%s declares spec version %s, but this binary runs version %s specs; run it with a drun release for version %s, or migrate it (see xdrun --spec-versions)",
+		filename, version, strings.Join(SupportedSpecVersions, ", "), SpecMajorVersion(version))
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpecVersion(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"version: 2.0\n\ntask \"a\":\n  info \"x\"\n", "2.0"},
+		{"# Tasks\n\n  version: 1\ntasks:\n  build: make\n", "1"},
+		{"\ufeffversion: 2.0\n", "2.0"},
+		{"task \"a\":\n  info \"version: 1\"\n", ""},
+		{"version:\n", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := SpecVersion(tt.source); got != tt.want {
+			t.Errorf("SpecVersion(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejectsUnsupportedSpecVersions(t *testing.T) {
+	_, err := ParseStringWithFilename("version: 1.0\n\ntasks:\n  build: make build\n", "legacy.drun")
+	if err == nil || !strings.Contains(err.Error(), "legacy.drun declares spec version 1.0") {
+		t.Fatalf("expected an unsupported spec version error, got %v", err)
+	}
+
+	if _, err := ParseStringWithFilename("version: 2.1\n\ntask \"a\":\n  info \"x\"\n", "spec.drun"); err != nil {
+		t.Errorf("expected minor versions of a supported spec to parse, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/domain/statement"
+)
+
+// Domain: Standard Input
+// This file exposes content piped into drun, as in drun import-data < data.csv,
+// to tasks as {stdin} and to loops as lines of stdin, so a task can be a stage
+// of a shell pipeline. The input is read when a task first uses it, so runs
+// that never do don't wait for an open pipe to close.
+
+// stdinInput is the standard input of a run, read at most once
+type stdinInput struct {
+	once    sync.Once
+	reader  io.Reader // nil when stdin is a terminal or not given
+	content string
+	err     error
+}
+
+// text returns the whole input with trailing newlines trimmed, as for a
+// parameter read with @-; it is empty when nothing was piped in
+func (s *stdinInput) text() (string, error) {
+	if s == nil || s.reader == nil {
+		return "", nil
+	}
+	s.once.Do(func() {
+		data, err := io.ReadAll(s.reader)
+		if err != nil {
+			s.err = fmt.Errorf("failed to read stdin: %w", err)
+		}
+		s.content = string(data)
+	})
+	return strings.TrimRight(s.content, "\r\n"), s.err
+}
+
+// lines returns the input split into lines the way capture lines of splits
+// command output: trimmed, with empty lines skipped
+func (s *stdinInput) lines() ([]string, error) {
+	content, err := s.text()
+	if err != nil {
+		return nil, err
+	}
+	return splitCapturedLines(content, nil), nil
+}
+
+// executeStdinLoop executes for each $line in lines of stdin loops
+func (e *Engine) executeStdinLoop(stmt *statement.Loop, ctx *ExecutionContext) error {
+	lines, err := e.stdin.lines()
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		e.iconf(ctx, "ℹ️  ", "No items to process in loop\n")
+		return nil
+	}
+
+	if stmt.Filter != nil {
+		lines = e.applyFilter(lines, stmt.Filter, ctx)
+	}
+	if stmt.Parallel {
+		return e.executeParallelLoop(stmt, lines, ctx)
+	}
+	return e.executeSequentialLoop(stmt, lines, ctx)
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStdinIsExposedToTasks(t *testing.T) {
+	input := `version: 2.0
+
+task "import-data":
+  for each $row in lines of stdin where $row contains ",":
+    info "row=[{$row}]"
+  info "all=[{stdin}]"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithStdin(strings.NewReader("name,size\r\n  api,3  \n\nnotes\nweb,5\n\n")))
+	if err := eng.Execute(program, "import-data"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	output := buf.String()
+	for _, want := range []string{"row=[name,size]", "row=[api,3]", "row=[web,5]", "all=[name,size\r\n  api,3  \n\nnotes\nweb,5]"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "row=[notes]") {
+		t.Errorf("the where filter was not applied:\n%s", output)
+	}
+}
+
+func TestStdinIsEmptyWhenNothingIsPipedIn(t *testing.T) {
+	input := `version: 2.0
+
+task "import-data":
+  info "all=[{stdin}]"
+  for each $row in lines of stdin:
+    info "row=[{$row}]"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "import-data"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "all=[]") || strings.Contains(buf.String(), "row=") {
+		t.Errorf("expected empty input and no iterations:\n%s", buf.String())
+	}
+}
@@ -370,3 +370,88 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestTaskCallOutputControl(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "noisy":
+  info "noisy line"
+  echo "second line"
+
+task "broken":
+  info "about to break"
+  fail "broken on purpose"
+
+task "main":
+  call task "noisy" silently
+  call task "noisy" capturing output as $log
+  info "captured [{$log}]"
+
+task "wrapper":
+  try:
+    call task "broken" silently
+  catch:
+    info "recovered"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "main"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	if strings.HasPrefix(output, "ℹ️  noisy line") || strings.Contains(output, "\nℹ️  noisy line") {
+		t.Errorf("expected the called task's output to be hidden:\n%s", output)
+	}
+	if !strings.Contains(output, "captured [ℹ️  noisy line\nsecond line]") {
+		t.Errorf("expected the captured output in $log:\n%s", output)
+	}
+
+	// A failing silent call shows what the task wrote
+	buf.Reset()
+	if err := NewEngine(&buf).Execute(program, "wrapper"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output = buf.String()
+	for _, want := range []string{"about to break", "recovered"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+// Run with -race: a silent call in one iteration of a parallel loop must not
+// hide or race with the output of the others
+func TestSilentCallInParallelLoopKeepsOtherOutput(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+task "noisy":
+  info "noisy line"
+
+task "main":
+  for each $i in ["1", "2", "3", "4", "5", "6"] in parallel:
+    when $i is "1":
+      call task "noisy" silently
+    otherwise:
+      info "visible {$i}"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "main"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	for _, i := range []string{"2", "3", "4", "5", "6"} {
+		if !strings.Contains(output, "visible "+i) {
+			t.Errorf("expected the output of iteration %s:\n%s", i, output)
+		}
+	}
+	if strings.Contains(output, "noisy line") {
+		t.Errorf("expected the silent call's output to be hidden:\n%s", output)
+	}
+}
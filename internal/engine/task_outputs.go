@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/domain/task"
+	"github.com/phillarmonic/drun/v2/internal/engine/planner"
+)
+
+// Domain: Task Outputs
+// This file implements data dependencies between tasks. A task declares the
+// variables it hands on with `outputs "image_tag"`; another task reads one
+// with `needs output "image_tag" from task "build"`, which runs build first
+// and sets $image_tag from the value build left, instead of relying on
+// variables leaking from one task to the next.
+
+// taskOutputs holds the declared outputs of the tasks that finished in an
+// execution. Parallel targets share it, so it is safe for concurrent use.
+type taskOutputs struct {
+	mu     sync.Mutex
+	values map[string]map[string]string // task -> output -> value
+}
+
+func (o *taskOutputs) record(taskName string, values map[string]string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.values == nil {
+		o.values = make(map[string]map[string]string)
+	}
+	o.values[taskName] = values
+}
+
+// get returns an output of a task, and whether the task recorded outputs at all
+func (o *taskOutputs) get(taskName, output string) (value string, ok bool, ran bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	values, ran := o.values[taskName]
+	value, ok = values[output]
+	return value, ok, ran
+}
+
+// astOutputNeeds returns the output needs of a task that runs from its AST
+func astOutputNeeds(stmt *ast.TaskStatement) []task.OutputNeed {
+	return task.NewOutputNeeds(stmt.OutputNeeds)
+}
+
+// checkOutputNeeds fails when a planned task needs an output that the task
+// it names does not declare
+func checkOutputNeeds(plan *planner.ExecutionPlan) error {
+	for _, name := range plan.ExecutionOrder {
+		consumer := plan.Tasks[name]
+		for _, need := range consumer.OutputNeeds {
+			producer, ok := plan.Tasks[need.Task]
+			if !ok {
+				return fmt.Errorf("task '%s' needs output '%s' of task '%s', which is not planned", name, need.Output, need.Task)
+			}
+			if !slices.Contains(producer.Outputs, need.Output) {
+				return fmt.Errorf("task '%s' needs output '%s' of task '%s', which does not declare it (add `outputs \"%s\"` to task '%s')",
+					name, need.Output, need.Task, need.Output, need.Task)
+			}
+		}
+	}
+	return nil
+}
+
+// recordTaskOutputs records the declared outputs of a task that finished,
+// failing when the task did not set one
+func (e *Engine) recordTaskOutputs(taskName string, outputs []string, ctx *ExecutionContext) error {
+	if len(outputs) == 0 || ctx.Outputs == nil {
+		return nil
+	}
+	values := make(map[string]string, len(outputs))
+	for _, output := range outputs {
+		value, ok := ctx.Variables["$"+output]
+		if !ok {
+			value, ok = ctx.Variables[output]
+		}
+		if !ok {
+			if !e.dryRun {
+				return fmt.Errorf("task '%s' declares output '%s' but did not set $%s", taskName, output, output)
+			}
+			value = fmt.Sprintf("[DRY RUN] output %s of task %s", output, taskName)
+		}
+		values[output] = value
+	}
+	ctx.Outputs.record(taskName, values)
+	return nil
+}
+
+// useTaskOutputs sets the variables of the outputs a task needs from the
+// tasks that produced them
+func (e *Engine) useTaskOutputs(taskName string, needs []task.OutputNeed, ctx *ExecutionContext) error {
+	if len(needs) == 0 || ctx.Outputs == nil {
+		return nil
+	}
+	for _, need := range needs {
+		value, ok, ran := ctx.Outputs.get(need.Task, need.Output)
+		if !ok {
+			if !ran {
+				return fmt.Errorf("task '%s' needs output '%s' of task '%s', which has not run", taskName, need.Output, need.Task)
+			}
+			return fmt.Errorf("task '%s' needs output '%s' of task '%s', which it does not declare", taskName, need.Output, need.Task)
+		}
+		e.assignVariable(ctx, "$"+need.As, value, "output of task "+need.Task)
+		if e.verbose {
+			e.iconf(ctx, "📥 ", "Using output '%s' of task '%s' as $%s\n", need.Output, need.Task, need.As)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runOutputsTask(t *testing.T, input, target string) (string, error) {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, target)
+	return buf.String(), err
+}
+
+func TestNeedsOutputRunsTheProducerAndExposesItsOutputs(t *testing.T) {
+	out, err := runOutputsTask(t, `version: 2.0
+
+task "build":
+  outputs "image_tag", "digest"
+  let $image_tag = "app:1.2.3"
+  let $digest = "sha256:abc"
+
+task "deploy":
+  needs output "image_tag" from task "build"
+  needs output "digest" from task "build" as $image_digest
+  info "deploying {$image_tag} ({$image_digest})"
+`, "deploy")
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "deploying app:1.2.3 (sha256:abc)") {
+		t.Fatalf("expected the outputs of build in deploy:\n%s", out)
+	}
+}
+
+func TestCalledTaskReadsOutputsOfTasksThatRan(t *testing.T) {
+	out, err := runOutputsTask(t, `version: 2.0
+
+task "stamp":
+  outputs "release_name"
+  let $release_name = "2.0.1"
+
+task "announce":
+  needs output "release_name" from task "stamp" as $release
+  info "releasing {$release}"
+
+task "release":
+  depends on stamp
+  call task "announce"
+`, "release")
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "releasing 2.0.1") {
+		t.Fatalf("expected the called task to read the output:\n%s", out)
+	}
+}
+
+func TestNeedsOutputErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		target  string
+		wantErr string
+	}{
+		{
+			name: "undeclared output",
+			input: `version: 2.0
+
+task "build":
+  let $image_tag = "app:1"
+
+task "deploy":
+  needs output "image_tag" from task "build"
+  info "never"
+`,
+			target:  "deploy",
+			wantErr: "task 'deploy' needs output 'image_tag' of task 'build', which does not declare it",
+		},
+		{
+			name: "output not set",
+			input: `version: 2.0
+
+task "build":
+  outputs "image_tag"
+  info "forgot to set it"
+
+task "deploy":
+  needs output "image_tag" from task "build"
+  info "never"
+`,
+			target:  "deploy",
+			wantErr: "task 'build' declares output 'image_tag' but did not set $image_tag",
+		},
+		{
+			name: "producer has not run",
+			input: `version: 2.0
+
+task "build":
+  outputs "image_tag"
+  let $image_tag = "app:1"
+
+task "deploy":
+  needs output "image_tag" from task "build"
+  info "never"
+
+task "main":
+  call task "deploy"
+`,
+			target:  "main",
+			wantErr: "needs output 'image_tag' of task 'build', which has not run",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runOutputsTask(t, tt.input, tt.target)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error %q, got %v\n%s", tt.wantErr, err, out)
+			}
+			if strings.Contains(out, "never") {
+				t.Fatalf("expected deploy not to run:\n%s", out)
+			}
+		})
+	}
+}
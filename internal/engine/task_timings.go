@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Domain: Task Timings
+// This file records how long each task of an execution took, for --timings
+// and the {task.elapsed} builtin, along with its time-boxed sections.
+
+// taskTiming is one finished task of an execution
+type taskTiming struct {
+	task     string
+	duration time.Duration
+	failed   bool
+}
+
+// taskTimings collects the finished tasks of an execution. Parallel targets
+// share it, so it is safe for concurrent use.
+type taskTimings struct {
+	mu       sync.Mutex
+	tasks    []taskTiming
+	sections []sectionTiming
+}
+
+func (t *taskTimings) add(timing taskTiming) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tasks = append(t.tasks, timing)
+}
+
+func (t *taskTimings) list() []taskTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]taskTiming(nil), t.tasks...)
+}
+
+func (t *taskTimings) addSection(section sectionTiming) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sections = append(t.sections, section)
+}
+
+func (t *taskTimings) listSections() []sectionTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]sectionTiming(nil), t.sections...)
+}
+
+// recordTaskTiming records a finished task and, with --timings, prints how
+// long it took
+func (e *Engine) recordTaskTiming(task string, start time.Time, err error, ctx *ExecutionContext) {
+	elapsed := time.Since(start)
+	if ctx.Timings != nil {
+		ctx.Timings.add(taskTiming{task: task, duration: elapsed, failed: err != nil})
+	}
+	if e.timings {
+		e.iconf(ctx, "⏱️  ", "Task '%s' finished in %s\n", task, formatElapsed(elapsed))
+	}
+}
+
+// reportTimings prints the duration of every task of the execution, in the
+// order they finished, followed by the total
+func (e *Engine) reportTimings(ctx *ExecutionContext, started time.Time) {
+	if !e.timings || ctx.Timings == nil {
+		return
+	}
+	tasks := ctx.Timings.list()
+	if len(tasks) == 0 {
+		return
+	}
+
+	width := len("total")
+	for _, timing := range tasks {
+		width = max(width, len(timing.task))
+	}
+
+	e.iconf(ctx, "\n⏱️  ", "Task durations:\n")
+	for _, timing := range tasks {
+		line := fmt.Sprintf("  %-*s  %8s", width, timing.task, formatElapsed(timing.duration))
+		if timing.failed {
+			line += "  failed"
+		}
+		_, _ = fmt.Fprintln(e.out(ctx), line)
+	}
+	_, _ = fmt.Fprintf(e.out(ctx), "  %-*s  %8s\n", width, "total", formatElapsed(time.Since(started)))
+}
+
+// formatElapsed formats a task duration with more precision than
+// formatDuration, since most tasks finish within seconds
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return formatDuration(d)
+	}
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingsPrintsPerTaskDurationSummary(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	input := `version: 2.0
+
+task "prepare":
+  info "preparing"
+
+task "release":
+  depends on prepare
+  info "release took {task.elapsed}"
+  fail "boom"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithTimings(true))
+	if err := eng.Execute(program, "release"); err == nil {
+		t.Fatal("expected the task to fail")
+	}
+
+	out := buf.String()
+	for _, pattern := range []string{
+		`release took \d+ms`,
+		`Task 'prepare' finished in \d+ms`,
+		`Task durations:\n  prepare +\d+ms\n  release +\d+ms  failed\n  total +\d+ms\n`,
+	} {
+		if !regexp.MustCompile(pattern).MatchString(out) {
+			t.Errorf("expected %q in output:\n%s", pattern, out)
+		}
+	}
+
+	// Without --timings only {task.elapsed} reports durations
+	buf.Reset()
+	if err := NewEngineWithOptions(WithOutput(&buf)).Execute(program, "prepare"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "finished in") || strings.Contains(buf.String(), "Task durations") {
+		t.Errorf("timings printed without --timings:\n%s", buf.String())
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{350 * time.Millisecond, "350ms"},
+		{12400 * time.Millisecond, "12.4s"},
+		{3*time.Minute + 5*time.Second, "3m5s"},
+	}
+	for _, tt := range tests {
+		if got := formatElapsed(tt.d); got != tt.want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runTodoTask(t *testing.T, behavior string) (string, error) {
+	t.Helper()
+	setting := ""
+	if behavior != "" {
+		setting = "  set todo behavior to \"" + behavior + "\"\n"
+	}
+	program, err := ParseString(`version: 2.0
+
+project "demo":
+` + setting + `  set region to "eu"
+
+task "deploy":
+  info "deploying"
+  for each $target in ["a"]:
+    todo "implement rollback in {$region}"
+  info "after todo"
+
+task "release":
+  call task "deploy"
+  info "release done"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "release")
+	return buf.String(), err
+}
+
+func TestTodoBehaviors(t *testing.T) {
+	out, err := runTodoTask(t, "")
+	if err != nil {
+		t.Fatalf("expected a todo to warn by default, got %v\n%s", err, out)
+	}
+	for _, want := range []string{"TODO: implement rollback in eu", "after todo", "release done"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("warn: expected %q in output:\n%s", want, out)
+		}
+	}
+
+	out, err = runTodoTask(t, "skip")
+	if err != nil {
+		t.Fatalf("expected a skipping todo to succeed, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Skipping the rest of task 'deploy'") || !strings.Contains(out, "release done") {
+		t.Errorf("skip: expected the caller to go on after the skipped task:\n%s", out)
+	}
+	if strings.Contains(out, "after todo") {
+		t.Errorf("skip: expected the rest of the task to be skipped:\n%s", out)
+	}
+
+	out, err = runTodoTask(t, "fail")
+	if err == nil || !strings.Contains(err.Error(), "task 'deploy' is not finished: TODO: implement rollback in eu") {
+		t.Fatalf("fail: expected the task to fail, got %v\n%s", err, out)
+	}
+
+	_, err = runTodoTask(t, "later")
+	if err == nil || !strings.Contains(err.Error(), "set todo behavior") {
+		t.Fatalf("expected an invalid todo behavior error, got %v", err)
+	}
+}
+
+func TestTodoSkipsTargetTaskAndIsNotCaught(t *testing.T) {
+	program, err := ParseString(`version: 2.0
+
+project "demo":
+  set todo behavior to "skip"
+
+task "deploy":
+  try:
+    todo "implement rollback"
+    info "after todo"
+  catch:
+    info "caught"
+  info "after try"
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "deploy"); err != nil {
+		t.Fatalf("expected a skipped target task to succeed, got %v\n%s", err, buf.String())
+	}
+	for _, unwanted := range []string{"after todo", "caught", "after try"} {
+		if strings.Contains(buf.String(), unwanted) {
+			t.Errorf("did not expect %q in output:\n%s", unwanted, buf.String())
+		}
+	}
+
+	buf.Reset()
+	eng := NewEngine(&buf)
+	eng.SetDryRun(true)
+	if err := eng.Execute(program, "deploy"); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "[DRY RUN] TODO: implement rollback (would skip the rest of the task)") {
+		t.Errorf("expected the dry run to describe the todo:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTransformCountsCharactersNotBytes(t *testing.T) {
+	input := `version: 2.0
+
+task "text":
+  set $length to "José 🇧🇷"
+  transform $length with length
+  set $bytes to "José 🇧🇷"
+  transform $bytes with byte length
+  set $slice to "José 🇧🇷!"
+  transform $slice with slice 3 6
+  set $right to "部署"
+  transform $right with pad right 6 "."
+  set $left to "café"
+  transform $left with pad left 6
+  set $wide to "deploy"
+  transform $wide with pad left 3
+  set $fire to "go"
+  transform $fire with pad right 7 "🔥"
+  set $dots to "ab"
+  transform $dots with pad left 5 ".:"
+  set $names to "ééé ab 👍🏽"
+  info "length={$length} bytes={$bytes} slice={$slice}"
+  info "right=[{$right}] left=[{$left}] wide=[{$wide}]"
+  info "fire=[{$fire}] dots=[{$dots}]"
+  info "sorted={$names sorted by length}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	if err := eng.Execute(program, "text"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"length=6 bytes=14 slice=é 🇧🇷",
+		"right=[部署..] left=[  café] wide=[deploy]",
+		"fire=[go🔥🔥 ] dots=[ .:ab]",
+		"sorted=👍🏽 ab ééé",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTransformPadRejectsInvalidWidth(t *testing.T) {
+	input := `version: 2.0
+
+task "text":
+  set $name to "drun"
+  transform $name with pad left "wide"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewEngine(&buf).Execute(program, "text")
+	if err == nil || !strings.Contains(err.Error(), `pad left: invalid width "wide"`) {
+		t.Fatalf("expected an invalid width error, got %v", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ui"
 )
 
 // VariableOperation represents a single operation on a variable
@@ -290,7 +292,7 @@ func (e *Engine) applySortedOperation(value string, args []string) (string, erro
 
 	case "length":
 		sort.Slice(items, func(i, j int) bool {
-			return len(items[i]) < len(items[j])
+			return len(ui.Graphemes(items[i])) < len(ui.Graphemes(items[j]))
 		})
 
 	default:
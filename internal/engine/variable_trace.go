@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/phillarmonic/drun/v2/internal/ui"
+)
+
+// Domain: Variable Tracing
+// This file reports variable changes: a before/after diff for let, set and
+// transform in verbose mode, and a line for every assignment to the variable
+// watched with --watch-var.
+
+// maxTracedValueLength is how many characters of a value are shown before it
+// is truncated
+const maxTracedValueLength = 80
+
+// sensitiveVariablePatterns mark variables whose values are never printed
+var sensitiveVariablePatterns = []string{"password", "passwd", "secret", "token", "credential", "api_key", "apikey", "private"}
+
+// assignVariable stores value in ctx.Variables and reports the assignment to
+// the watched variable. via names the statement that assigned it.
+func (e *Engine) assignVariable(ctx *ExecutionContext, name, value, via string) (previous string, existed bool) {
+	previous, existed = ctx.Variables[name]
+	ctx.Variables[name] = value
+
+	if e.watchVar != "" && strings.TrimPrefix(name, "$") == e.watchVar {
+		location := via
+		if ctx.CurrentTask != "" {
+			location += " in task '" + ctx.CurrentTask + "'"
+		}
+		if existed {
+			e.iconf(ctx, "👁️  ", "%s: %s → %s (%s)\n", name, traceValue(name, previous), traceValue(name, value), location)
+		} else {
+			e.iconf(ctx, "👁️  ", "%s = %s (%s)\n", name, traceValue(name, value), location)
+		}
+	}
+	return previous, existed
+}
+
+// reportVariableChange prints the before and after values of a variable as a
+// diff, colored unless the output style is plain or NO_COLOR is set
+func (e *Engine) reportVariableChange(name, previous string, existed bool, value string, ctx *ExecutionContext) {
+	color := e.style(ctx).theme.Style() != ui.StylePlain && os.Getenv("NO_COLOR") == ""
+	line := func(sign, ansi, text string) {
+		if color {
+			_, _ = fmt.Fprintf(e.out(ctx), "    \033[%sm%s %s\033[0m\n", ansi, sign, text)
+		} else {
+			_, _ = fmt.Fprintf(e.out(ctx), "    %s %s\n", sign, text)
+		}
+	}
+
+	switch {
+	case existed && previous == value:
+		line("=", "2", traceValue(name, value)+" (unchanged)")
+	case existed:
+		line("-", "31", traceValue(name, previous))
+		line("+", "32", traceValue(name, value))
+	default:
+		line("+", "32", traceValue(name, value))
+	}
+}
+
+// traceValue formats a variable value for traces: masked for sensitive
+// names, shortened when long and quoted so whitespace stays visible
+func traceValue(name, value string) string {
+	lower := strings.ToLower(name)
+	for _, pattern := range sensitiveVariablePatterns {
+		if strings.Contains(lower, pattern) {
+			return "***"
+		}
+	}
+
+	if utf8.RuneCountInString(value) > maxTracedValueLength {
+		runes := []rune(value)
+		return strconv.Quote(string(runes[:maxTracedValueLength])) + fmt.Sprintf("… (%d characters)", len(runes))
+	}
+	return strconv.Quote(value)
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVerboseShowsVariableChangesAsDiff(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	input := `version: 2.0
+
+task "build":
+  let $version = "1.0"
+  set $version to "1.1"
+  set $api_token to "abc123"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithVerbose(true))
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Set variable $version\n    + \"1.0\"\n",
+		"Set variable $version\n    - \"1.0\"\n    + \"1.1\"\n",
+		"Set variable $api_token\n    + ***\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "abc123") {
+		t.Errorf("sensitive value leaked into the output:\n%s", out)
+	}
+}
+
+func TestWatchVariableTracesEveryAssignment(t *testing.T) {
+	input := `version: 2.0
+
+task "build":
+  let $version = "1.0"
+  let $other = "x"
+  transform $version with uppercase
+  capture from shell "echo 2.0-rc" as $version
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(WithOutput(&buf), WithWatchVariable("$version"))
+	if err := eng.Execute(program, "build"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`$version = "1.0" (let in task 'build')`,
+		`$version: "1.0" → "1.0" (transform uppercase in task 'build')`,
+		`$version: "1.0" → "2.0-rc" (capture from shell in task 'build')`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "$other") {
+		t.Errorf("only the watched variable should be traced:\n%s", out)
+	}
+}
+
+func TestTraceValueTruncatesLongValues(t *testing.T) {
+	got := traceValue("$notes", strings.Repeat("a", maxTracedValueLength+20))
+	if !strings.HasSuffix(got, "… (100 characters)") || len(got) > maxTracedValueLength+30 {
+		t.Errorf("unexpected truncated value: %s", got)
+	}
+	if got := traceValue("$db_password", "hunter2"); got != "***" {
+		t.Errorf("expected a masked value, got %s", got)
+	}
+}
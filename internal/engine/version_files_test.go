@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeVersionManifests(t *testing.T, dir, version string) {
+	t.Helper()
+	files := map[string]string{
+		"package.json": "{\n  \"name\": \"app\",\n  \"version\": \"" + version + "\"\n}\n",
+		"Chart.yaml":   "apiVersion: v2\nname: app\nversion: " + version + "\n",
+		"VERSION":      version + "\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBumpVersionKeepsManifestsInSync(t *testing.T) {
+	dir := t.TempDir()
+	writeVersionManifests(t, dir, "1.2.3")
+	input := `version: 2.0
+
+task "release":
+  get version from files ["package.json", "Chart.yaml", "VERSION"] as $current
+  bump version minor in files ["package.json", "Chart.yaml", "VERSION"] keeping them in sync as $next
+  info "{$current} -> {$next}"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	eng.SetDryRun(true)
+	if err := eng.ExecuteWithParamsAndFile(program, "release", nil, filepath.Join(dir, "spec.drun")); err != nil {
+		t.Fatalf("dry run failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Would bump the version of Chart.yaml from 1.2.3 to 1.3.0") {
+		t.Errorf("expected the dry run to list the bumps:\n%s", buf.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "VERSION")); string(data) != "1.2.3\n" {
+		t.Errorf("dry run changed VERSION to %q", data)
+	}
+
+	buf.Reset()
+	eng.SetDryRun(false)
+	if err := eng.ExecuteWithParamsAndFile(program, "release", nil, filepath.Join(dir, "spec.drun")); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	output := buf.String()
+	for _, want := range []string{"Bumped the minor version of 3 files:\n", "  package.json: 1.2.3 -> 1.3.0\n", "  VERSION: 1.2.3 -> 1.3.0\n", "1.2.3 -> 1.3.0"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "package.json")); !strings.Contains(string(data), `"version": "1.3.0"`) {
+		t.Errorf("package.json = %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "Chart.yaml")); !strings.Contains(string(data), "version: 1.3.0\n") {
+		t.Errorf("Chart.yaml = %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "VERSION")); string(data) != "1.3.0\n" {
+		t.Errorf("VERSION = %q", data)
+	}
+}
+
+func TestBumpVersionRefusesManifestsOutOfSync(t *testing.T) {
+	dir := t.TempDir()
+	writeVersionManifests(t, dir, "1.2.3")
+	if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte("v2.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := `version: 2.0
+
+task "release":
+  bump version patch in files ["package.json", "VERSION"] keeping them in sync
+
+task "each":
+  bump version patch in files ["package.json", "VERSION"]
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	err = eng.ExecuteWithParamsAndFile(program, "release", nil, filepath.Join(dir, "spec.drun"))
+	if err == nil || !strings.Contains(err.Error(), "the files declare different versions: package.json 1.2.3, VERSION v2.0.0") {
+		t.Fatalf("expected the versions to be out of sync, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "package.json")); !strings.Contains(string(data), `"version": "1.2.3"`) {
+		t.Errorf("a refused bump changed package.json: %s", data)
+	}
+
+	// Without keeping them in sync, every file is bumped from its own version
+	if err := eng.ExecuteWithParamsAndFile(program, "each", nil, filepath.Join(dir, "spec.drun")); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "VERSION")); string(data) != "v2.0.1\n" {
+		t.Errorf("VERSION = %q, want v2.0.1", data)
+	}
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runWhileTask(t *testing.T, input string, opts ...Option) (string, error) {
+	t.Helper()
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var buf bytes.Buffer
+	eng := NewEngineWithOptions(append([]Option{WithOutput(&buf)}, opts...)...)
+	err = eng.Execute(program, "poll")
+	return buf.String(), err
+}
+
+func TestWhileLoopRunsUntilConditionIsFalse(t *testing.T) {
+	out, err := runWhileTask(t, `version: 2.0
+
+task "poll":
+  set $attempts to 0
+  set $status to "pending"
+  while {$attempts} < 5 and {$status} != "ready":
+    capture from shell "expr {$attempts} + 1" as $attempts
+    info "attempt {$attempts}"
+    if {$attempts} is "3":
+      set $status to "ready"
+  info "done after {$attempts} with {$status}"
+`)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "attempt 4") || !strings.Contains(out, "done after 3 with ready") {
+		t.Fatalf("expected the loop to stop once the status is ready:\n%s", out)
+	}
+}
+
+func TestWhileLoopBreakAndContinue(t *testing.T) {
+	out, err := runWhileTask(t, `version: 2.0
+
+task "poll":
+  set $n to 0
+  while {$n} < 10:
+    capture from shell "expr {$n} + 1" as $n
+    if {$n} is "2":
+      continue
+    if {$n} is "4":
+      break
+    info "n={$n}"
+`)
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"n=1", "n=3"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"n=2", "n=4", "n=5"} {
+		if strings.Contains(out, unwanted) {
+			t.Fatalf("did not expect %q in output:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestWhileLoopIterationLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		limit string
+	}{
+		{
+			name: "loop limit",
+			input: `version: 2.0
+
+task "poll":
+  while "up" is "up" at most 3 times:
+    info "tick"
+`,
+			limit: "stopped after 3 iterations",
+		},
+		{
+			name: "project setting",
+			input: `version: 2.0
+
+project "app":
+  set max_while_iterations to 2
+
+task "poll":
+  while "up" is "up":
+    info "tick"
+`,
+			limit: "stopped after 2 iterations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runWhileTask(t, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.limit) {
+				t.Fatalf("expected %q error, got %v", tt.limit, err)
+			}
+			if !strings.Contains(err.Error(), "at most N times") {
+				t.Fatalf("expected the error to explain how to raise the limit, got %v", err)
+			}
+			if strings.Count(out, "tick") > 3 {
+				t.Fatalf("loop ran past its limit:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestWhileLoopDryRun(t *testing.T) {
+	out, err := runWhileTask(t, `version: 2.0
+
+task "poll":
+  while "up" is "up" at most 20 times:
+    info "tick"
+`, WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "[DRY RUN] Would loop while up is up (at most 20 iterations)") {
+		t.Fatalf("unexpected dry run output:\n%s", out)
+	}
+}
+
+func TestComparisonConditions(t *testing.T) {
+	tests := []struct {
+		condition string
+		expected  bool
+	}{
+		{"{$count} < 10", true},
+		{"{$count} > 10", false},
+		{"{$count} >= 9", true},
+		{"{$count} <= 8", false},
+		{"{$count} == 9", true},
+		{"{$count} != 9", false},
+		{"$count < 10", true},
+		{"{$name} == api", true},
+		{"{$name} != web", true},
+		{"{$count} < 10 and {$name} == web", false},
+		{"{$count} < 5 or {$name} == api", true},
+		{"{$count} < 5 or {$name} == web and {$count} > 1", false},
+	}
+
+	eng := NewEngine(&bytes.Buffer{})
+	ctx := &ExecutionContext{Variables: map[string]string{"$count": "9", "$name": "api"}}
+	for _, tt := range tests {
+		if got := eng.evaluateCondition(tt.condition, ctx); got != tt.expected {
+			t.Errorf("evaluateCondition(%q) = %v, want %v", tt.condition, got, tt.expected)
+		}
+	}
+}
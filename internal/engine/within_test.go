@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWithinWarnsOrFailsWhenOverBudget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep from a POSIX shell")
+	}
+
+	input := `version: 2.0
+
+task "ci":
+  within "10m" as "quick":
+    info "fast"
+  within "1ms" as "unit tests":
+    run "sleep 0.05"
+  info "after unit tests"
+  within "1ms" as "lint" or fail:
+    run "sleep 0.05"
+  info "not reached"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	eng := NewEngine(&buf)
+	err = eng.Execute(program, "ci")
+	if err == nil || !strings.Contains(err.Error(), "section 'lint' took") || !strings.Contains(err.Error(), "over its 1ms budget") {
+		t.Fatalf("expected the lint section to fail the task, got %v\n%s", err, buf.String())
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Section 'unit tests' took") || !strings.Contains(output, "after unit tests") {
+		t.Errorf("expected a warning for the unit tests section and the task to continue:\n%s", output)
+	}
+	if strings.Contains(output, "not reached") {
+		t.Errorf("the task must stop after the failed section:\n%s", output)
+	}
+
+	summary := output[strings.Index(output, "Section durations:"):]
+	for _, want := range []string{"ci › quick", "ci › unit tests", "ci › lint"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected %q in the section summary:\n%s", want, summary)
+		}
+	}
+	if strings.Count(summary, "over budget") != 2 {
+		t.Errorf("expected two sections over budget:\n%s", summary)
+	}
+}
+
+func TestWithinUnderBudgetPrintsNoSummaryWithoutTimings(t *testing.T) {
+	input := `version: 2.0
+
+task "ci":
+  within 10 minutes:
+    info "fast"
+`
+	program, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEngine(&buf).Execute(program, "ci"); err != nil {
+		t.Fatalf("Execution failed: %v\n%s", err, buf.String())
+	}
+	if strings.Contains(buf.String(), "Section durations") || strings.Contains(buf.String(), "budget") {
+		t.Errorf("expected no section report for a section within budget:\n%s", buf.String())
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/lexer"
 	parserPkg "github.com/phillarmonic/drun/v2/internal/parser"
+	"github.com/phillarmonic/drun/v2/internal/types"
 )
 
 // parseForWorkdirTest is a helper to parse a drun string in engine tests.
@@ -396,3 +398,88 @@ task "plain-negated":
 		t.Fatalf("Expected unquoted negated dotfile check to skip missing branch, got:\n%s", out.String())
 	}
 }
+
+// TestFailedTasksRestoreWorkdirAndRecordTheirEnd verifies that a task that
+// fails in its body or in a before hook restores the working directory and is
+// recorded as finished, the same as a task that succeeds.
+func TestFailedTasksRestoreWorkdirAndRecordTheirEnd(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "subdir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name: "body",
+			input: `version: 2.0
+
+task "check":
+  use workdir "subdir"
+  fail "not ready"
+`,
+			wantErr: "task 'check' failed",
+		},
+		{
+			name: "before hook",
+			input: `version: 2.0
+
+project "app":
+  before any task:
+    fail "not ready"
+
+task "check":
+  info "checking"
+`,
+			wantErr: "before hook failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parseForWorkdirTest(t, tt.input)
+			var out bytes.Buffer
+			eng := NewEngine(&out)
+			projectCtx, plans, style, err := eng.planTargets(program, []TaskTarget{{Name: "check"}}, "")
+			if err != nil {
+				t.Fatalf("planTargets() error = %v", err)
+			}
+			ctx := &ExecutionContext{
+				Parameters:         map[string]*types.Value{},
+				Variables:          map[string]string{},
+				Project:            projectCtx,
+				Program:            program,
+				OriginalWorkingDir: tmpDir,
+				WorkingDir:         tmpDir,
+				NotifyWhenDone:     &atomic.Bool{},
+				Timings:            &taskTimings{},
+				Assertions:         &failedAssertions{},
+				Retries:            &retryBudget{},
+				Deadline:           newRunDeadline(0),
+				Run:                newRunInfo(),
+				LoopItems:          &loopItems{},
+				Outputs:            &taskOutputs{},
+				Style:              style,
+			}
+
+			err = eng.executePlan(plans[0], plans[0].ExecutionOrder, nil, ctx, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected %q, got %v\nOutput:\n%s", tt.wantErr, err, out.String())
+			}
+			if ctx.WorkingDir != tmpDir {
+				t.Errorf("working directory leaked from the failed task: %s", ctx.WorkingDir)
+			}
+			if timings := ctx.Timings.list(); len(timings) != 1 || !timings[0].failed {
+				t.Errorf("expected one failed task timing, got %+v", timings)
+			}
+		})
+	}
+}
@@ -216,3 +216,24 @@ func NewParameterValidationError(message string) *ParameterValidationError {
 		Message: message,
 	}
 }
+
+// ValidationError reports a task file that parsed but cannot run as
+// requested, such as an unknown task or a missing dependency
+type ValidationError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// NewValidationError marks err as a validation error
+func NewValidationError(err error) *ValidationError {
+	return &ValidationError{Err: err}
+}
@@ -12,7 +12,7 @@ import (
 type FileOperation struct {
 	Type         string            // "create", "copy", "move", "delete", "read", "write", "append"
 	Target       string            // target file/directory path
-	Source       string            // source path (for copy/move operations)
+	Source       string            // source path (for copy/move operations, or the file an append streams)
 	Content      string            // content (for write/append operations)
 	IsDir        bool              // whether the operation is on a directory
 	Replacements map[string]string // replacements for replace operations
@@ -87,6 +87,9 @@ func (op *FileOperation) getDescription() string {
 	case "write":
 		return fmt.Sprintf("content to file '%s'", op.Target)
 	case "append":
+		if op.Source != "" {
+			return fmt.Sprintf("file '%s' to file '%s'", op.Source, op.Target)
+		}
 		return fmt.Sprintf("content to file '%s'", op.Target)
 	case "replace":
 		return fmt.Sprintf("values in file '%s'", op.Target)
@@ -171,13 +174,13 @@ func (op *FileOperation) executeCopy() (*Result, error) {
 		result.Message = fmt.Sprintf("Copied directory '%s' to '%s'", op.Source, op.Target)
 	} else {
 		// Copy file
-		err = copyFile(op.Source, op.Target)
+		written, err := copyFile(op.Source, op.Target)
 		if err != nil {
 			result.Message = fmt.Sprintf("Failed to copy file '%s' to '%s': %v", op.Source, op.Target, err)
 			return result, err
 		}
 		result.Success = true
-		result.Message = fmt.Sprintf("Copied file '%s' to '%s'", op.Source, op.Target)
+		result.Message = fmt.Sprintf("Copied file '%s' to '%s' (%d bytes)", op.Source, op.Target, written)
 	}
 
 	return result, nil
@@ -306,7 +309,8 @@ func (op *FileOperation) executeWrite() (*Result, error) {
 	return result, nil
 }
 
-// executeAppend appends content to a file
+// executeAppend appends content, or the content of the source file, to a
+// file. A source file is streamed, so its size does not matter.
 func (op *FileOperation) executeAppend() (*Result, error) {
 	result := &Result{
 		Operation: op.Type,
@@ -330,14 +334,28 @@ func (op *FileOperation) executeAppend() (*Result, error) {
 	}
 	defer func() { _ = file.Close() }()
 
-	_, err = file.WriteString(op.Content)
+	var written int64
+	if op.Source != "" {
+		written, err = appendFrom(file, op.Source)
+	} else {
+		var n int
+		n, err = file.WriteString(op.Content)
+		written = int64(n)
+	}
+	if err == nil {
+		err = file.Close()
+	}
 	if err != nil {
 		result.Message = fmt.Sprintf("Failed to append to file '%s': %v", op.Target, err)
 		return result, err
 	}
 
 	result.Success = true
-	result.Message = fmt.Sprintf("Appended %d bytes to file '%s'", len(op.Content), op.Target)
+	if op.Source != "" {
+		result.Message = fmt.Sprintf("Appended file '%s' to file '%s' (%d bytes)", op.Source, op.Target, written)
+	} else {
+		result.Message = fmt.Sprintf("Appended %d bytes to file '%s'", written, op.Target)
+	}
 	return result, nil
 }
 
@@ -394,42 +412,67 @@ func (op *FileOperation) executeReplace() (*Result, error) {
 
 // Helper functions
 
-// copyFile copies a single file
-func copyFile(src, dst string) error {
+// copyFile copies a single file and returns the number of bytes copied.
+// The content is streamed, never held in memory as a whole.
+func copyFile(src, dst string) (int64, error) {
 	// Create parent directory for destination if needed
 	dir := filepath.Dir(dst)
 	if dir != "." {
 		err := os.MkdirAll(dir, 0750)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
 	// #nosec G304 -- copy source is explicitly provided by the caller.
 	srcFile, err := os.Open(src)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() { _ = srcFile.Close() }()
 
 	// #nosec G304 -- copy destination is explicitly provided by the caller.
 	dstFile, err := os.Create(dst)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() { _ = dstFile.Close() }()
 
-	_, err = io.Copy(dstFile, srcFile)
+	written, err := io.Copy(dstFile, srcFile)
 	if err != nil {
-		return err
+		return written, err
+	}
+	// A failed close can lose buffered writes, so it fails the copy
+	if err := dstFile.Close(); err != nil {
+		return written, err
 	}
 
 	// Copy file permissions
 	srcInfo, err := os.Stat(src)
 	if err != nil {
-		return err
+		return written, err
+	}
+	return written, os.Chmod(dst, srcInfo.Mode())
+}
+
+// appendFrom streams the content of the file src to the end of dst
+func appendFrom(dst *os.File, src string) (int64, error) {
+	// #nosec G304 -- the appended file is explicitly provided by the caller.
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = srcFile.Close() }()
+
+	// A file appended to itself would grow while it is read
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if dstInfo, err := dst.Stat(); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return 0, fmt.Errorf("cannot append '%s' to itself", src)
 	}
-	return os.Chmod(dst, srcInfo.Mode())
+	return io.Copy(dst, srcFile)
 }
 
 // copyDir copies a directory recursively
@@ -465,7 +508,7 @@ func copyDir(src, dst string) error {
 			}
 		} else {
 			// Copy file
-			err = copyFile(srcPath, dstPath)
+			_, err = copyFile(srcPath, dstPath)
 			if err != nil {
 				return err
 			}
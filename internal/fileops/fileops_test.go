@@ -401,3 +401,36 @@ func TestDryRun(t *testing.T) {
 		t.Errorf("Expected dry run message to contain '[DRY RUN]', got: %s", result.Message)
 	}
 }
+
+func TestAppendFileFromSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := filepath.Join(tmpDir, "part.txt")
+	target := filepath.Join(tmpDir, "all.txt")
+	if err := os.WriteFile(source, []byte("part\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("start\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	op := &FileOperation{Type: "append", Source: source, Target: target}
+	result, err := op.Execute(false)
+	if err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+	if !strings.Contains(result.Message, "(5 bytes)") {
+		t.Errorf("unexpected message: %s", result.Message)
+	}
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "start\npart\n" {
+		t.Errorf("Expected content %q, got %q", "start\npart\n", string(content))
+	}
+
+	self := &FileOperation{Type: "append", Source: target, Target: target}
+	if _, err := self.Execute(false); err == nil || !strings.Contains(err.Error(), "itself") {
+		t.Errorf("expected appending a file to itself to fail, got %v", err)
+	}
+}
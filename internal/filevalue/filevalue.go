@@ -62,6 +62,7 @@ var adapters = map[string]Adapter{
 	"json": adapterFuncs{read: func(s string, d []byte) (Scalar, error) { _, v, e := findJSONScalar(d, s); return v, e }, update: updateJSON},
 	"yaml": adapterFuncs{read: readYAML, update: updateYAML},
 	"toml": adapterFuncs{read: readTOML, update: updateTOML},
+	"text": adapterFuncs{read: readText, update: updateText},
 }
 
 var drunProjectDeclarationPattern = regexp.MustCompile(`(?m)^[\t ]*project[\t ]+"(?:\\.|[^"\\\r\n])*"[\t ]+version[\t ]+"((?:\\.|[^"\\\r\n])*)"[\t ]*:[^\r\n]*\r?$`)
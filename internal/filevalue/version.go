@@ -0,0 +1,74 @@
+package filevalue
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// tomlVersionSelectors are the tables Cargo, PEP 621 and Poetry manifests
+// declare their version in, tried in order
+var tomlVersionSelectors = []string{"package.version", "project.version", "tool.poetry.version", "version"}
+
+// VersionLocation returns the format and selector of the version a manifest
+// declares, chosen from its file name: the top-level "version" of JSON and
+// YAML files, the package or project version of TOML files, the project
+// version of drun files and the whole content of any other file, such as
+// VERSION.
+func VersionLocation(path string, data []byte) (string, string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json", "/version", nil
+	case ".yaml", ".yml":
+		return "yaml", "version", nil
+	case ".toml":
+		for _, selector := range tomlVersionSelectors {
+			if _, err := Read("toml", selector, data); err == nil {
+				return "toml", selector, nil
+			}
+		}
+		return "", "", fmt.Errorf("no version found (looked for %s)", strings.Join(tomlVersionSelectors, ", "))
+	case ".drun":
+		return "drun", "project.version", nil
+	default:
+		return "text", "", nil
+	}
+}
+
+// readText reads a file whose whole content, surrounding whitespace aside,
+// is one value
+func readText(selector string, data []byte) (Scalar, error) {
+	if selector != "" {
+		return Scalar{}, fmt.Errorf("plain text files do not support selector %q", selector)
+	}
+	text := string(bytes.TrimSpace(data))
+	if text == "" {
+		return Scalar{}, fmt.Errorf("file is empty")
+	}
+	if strings.ContainsAny(text, "\r\n") {
+		return Scalar{}, fmt.Errorf("expected a single line, found several")
+	}
+	return Scalar{Text: text, Kind: String}, nil
+}
+
+// updateText replaces the value of a plain text file, keeping the
+// whitespace around it
+func updateText(selector string, data []byte, value, missingPolicy, valueType string) ([]byte, Scalar, error) {
+	if missingPolicy == "add" || valueType != "" {
+		return nil, Scalar{}, fmt.Errorf("plain text updates do not support additions or scalar types")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return nil, Scalar{}, fmt.Errorf("plain text value cannot contain newlines")
+	}
+	current, err := readText(selector, data)
+	if err != nil {
+		return nil, Scalar{}, err
+	}
+	start := bytes.Index(data, []byte(current.Text))
+	updated := make([]byte, 0, len(data)-len(current.Text)+len(value))
+	updated = append(updated, data[:start]...)
+	updated = append(updated, value...)
+	updated = append(updated, data[start+len(current.Text):]...)
+	return updated, Scalar{Text: value, Kind: String}, nil
+}
@@ -22,6 +22,20 @@ type Policy struct {
 	EnforceSignedCommits bool
 }
 
+// ViolationError reports a branch or commit that breaks the policy, as
+// opposed to a policy that could not be checked
+type ViolationError struct {
+	Err error
+}
+
+func (e *ViolationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ViolationError) Unwrap() error {
+	return e.Err
+}
+
 // ValidationResult indicates whether validation passed, and if not, the reason.
 type ValidationResult struct {
 	Valid   bool
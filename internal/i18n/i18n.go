@@ -0,0 +1,243 @@
+// Package i18n translates the messages drun shows. A message catalog maps
+// the English format of a message, as passed to fmt, to its format in one
+// language; messages missing from a catalog are shown in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language messages are written in
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var builtinLocales embed.FS
+
+// Catalog maps English message formats to translated formats
+type Catalog map[string]string
+
+// verbPattern matches the fmt verbs of a message format, and indexPattern
+// the explicit argument index of one
+var (
+	verbPattern  = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+	indexPattern = regexp.MustCompile(`^%(\[\d+\])`)
+)
+
+// Translator translates messages to one language. A nil Translator, or one
+// for English, returns messages unchanged.
+type Translator struct {
+	language string
+	catalog  Catalog
+
+	patternsOnce sync.Once
+	patterns     []messagePattern
+}
+
+// messagePattern recognizes a message rendered from an English format
+type messagePattern struct {
+	re         *regexp.Regexp
+	translated string // the translated format, with every verb printing a string
+	wrapped    []bool // whether each argument may be an error (%w, %v), translated in turn
+}
+
+// Languages returns the languages drun has a built-in catalog for
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	entries, _ := builtinLocales.ReadDir("locales")
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Load returns the translator for a language such as "pt-BR". The built-in
+// catalog of the language, or of its base language ("pt"), comes first; a
+// <language>.json catalog in dir, when dir is given, adds and overrides
+// messages. A language without any catalog is an error.
+func Load(language, dir string) (*Translator, error) {
+	language = strings.TrimSpace(language)
+	if language == "" || strings.EqualFold(language, DefaultLanguage) {
+		return nil, nil
+	}
+
+	catalog := Catalog{}
+	found := false
+	for _, name := range candidateNames(language) {
+		data, err := builtinLocales.ReadFile("locales/" + name + ".json")
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("built-in message catalog %s: %w", name, err)
+		}
+		found = true
+		break
+	}
+
+	if dir != "" {
+		for _, name := range candidateNames(language) {
+			path := filepath.Join(dir, name+".json")
+			// #nosec G304 -- message catalogs are read from the project's locales directory.
+			data, err := os.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read message catalog: %w", err)
+			}
+			var project Catalog
+			if err := json.Unmarshal(data, &project); err != nil {
+				return nil, fmt.Errorf("failed to parse message catalog %s: %w", path, err)
+			}
+			for format, translated := range project {
+				catalog[format] = translated
+			}
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no message catalog for language %q (built in: %s)", language, strings.Join(Languages(), ", "))
+	}
+	return &Translator{language: language, catalog: catalog}, nil
+}
+
+// candidateNames returns the catalog names of a language, most specific
+// first: "pt-BR", then "pt"
+func candidateNames(language string) []string {
+	names := []string{language}
+	if base, _, ok := strings.Cut(language, "-"); ok {
+		names = append(names, base)
+	}
+	return names
+}
+
+// Language returns the language messages are translated to
+func (t *Translator) Language() string {
+	if t == nil {
+		return DefaultLanguage
+	}
+	return t.language
+}
+
+// Format returns the translation of a message format, or format itself when
+// the catalog has none. A trailing newline is kept out of the lookup.
+func (t *Translator) Format(format string) string {
+	if t == nil {
+		return format
+	}
+	text, newlines := splitNewlines(format)
+	if translated, ok := t.catalog[text]; ok {
+		return translated + newlines
+	}
+	return format
+}
+
+// Message translates a message that was already rendered, such as the text
+// of an error, by finding the catalog format it was rendered from. The
+// errors rendered with "%w" or "%v" are translated too. A message that
+// matches no format is returned unchanged.
+func (t *Translator) Message(message string) string {
+	if t == nil || message == "" {
+		return message
+	}
+	text, newlines := splitNewlines(message)
+	if translated, ok := t.catalog[text]; ok && !verbPattern.MatchString(text) {
+		return translated + newlines
+	}
+
+	t.patternsOnce.Do(t.compilePatterns)
+	for _, pattern := range t.patterns {
+		match := pattern.re.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		args := make([]any, len(match)-1)
+		for i, arg := range match[1:] {
+			if pattern.wrapped[i] {
+				arg = t.Message(arg)
+			}
+			args[i] = arg
+		}
+		return fmt.Sprintf(pattern.translated, args...) + newlines
+	}
+	return message
+}
+
+// compilePatterns builds the patterns of the catalog formats with verbs,
+// longest first so the most specific format wins. Every verb matches the
+// shortest text that lets the rest of the message match.
+func (t *Translator) compilePatterns() {
+	formats := make([]string, 0, len(t.catalog))
+	for format := range t.catalog {
+		if verbPattern.MatchString(format) {
+			formats = append(formats, format)
+		}
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		if len(formats[i]) != len(formats[j]) {
+			return len(formats[i]) > len(formats[j])
+		}
+		return formats[i] < formats[j]
+	})
+
+	for _, format := range formats {
+		var expr strings.Builder
+		var wrapped []bool
+		expr.WriteString("^")
+		last := 0
+		for _, loc := range verbPattern.FindAllStringIndex(format, -1) {
+			expr.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+			verb := format[loc[0]:loc[1]]
+			if verb == "%%" {
+				expr.WriteString("%")
+			} else {
+				if strings.HasSuffix(verb, "s") || strings.HasSuffix(verb, "v") {
+					expr.WriteString("(.*?)") // strings may be empty
+				} else {
+					expr.WriteString("(.+?)")
+				}
+				wrapped = append(wrapped, strings.HasSuffix(verb, "w") || strings.HasSuffix(verb, "v"))
+			}
+			last = loc[1]
+		}
+		expr.WriteString(regexp.QuoteMeta(format[last:]))
+		expr.WriteString("$")
+
+		re, err := regexp.Compile(expr.String())
+		if err != nil {
+			continue
+		}
+		t.patterns = append(t.patterns, messagePattern{re: re, translated: stringVerbs(t.catalog[format]), wrapped: wrapped})
+	}
+}
+
+// stringVerbs rewrites the verbs of a format to print strings, keeping
+// explicit argument indexes, since rendered arguments are re-inserted as text
+func stringVerbs(format string) string {
+	return verbPattern.ReplaceAllStringFunc(format, func(verb string) string {
+		if verb == "%%" {
+			return verb
+		}
+		if index := indexPattern.FindStringSubmatch(verb); index != nil {
+			return "%" + index[1] + "s"
+		}
+		return "%s"
+	})
+}
+
+func splitNewlines(s string) (string, string) {
+	text := strings.TrimRight(s, "\n")
+	return text, s[len(text):]
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBuiltInAndProjectCatalogs(t *testing.T) {
+	if translator, err := Load("en", ""); err != nil || translator != nil {
+		t.Fatalf("Load(en) = %v, %v; want no translator", translator, err)
+	}
+	if _, err := Load("xx", ""); err == nil || !strings.Contains(err.Error(), "pt-BR") {
+		t.Errorf("Load(xx) error = %v; want one listing the built-in languages", err)
+	}
+
+	translator, err := Load("pt-BR", "")
+	if err != nil {
+		t.Fatalf("Load(pt-BR) error = %v", err)
+	}
+	if got := translator.Format("Task durations:\n"); got != "Duração das tarefas:\n" {
+		t.Errorf("Format() = %q; want the translation with its newline", got)
+	}
+	if got := translator.Format("Not in the catalog: %s\n"); got != "Not in the catalog: %s\n" {
+		t.Errorf("Format() = %q; want the English format", got)
+	}
+
+	dir := t.TempDir()
+	catalog := `{"Task durations:": "Tempos:", "Hello %s": "Olá %s"}`
+	if err := os.WriteFile(filepath.Join(dir, "pt.json"), []byte(catalog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	translator, err = Load("pt-BR", dir)
+	if err != nil {
+		t.Fatalf("Load(pt-BR, dir) error = %v", err)
+	}
+	if got := translator.Format("Task durations:"); got != "Tempos:" {
+		t.Errorf("Format() = %q; want the project's override", got)
+	}
+	if got := translator.Format("Task '%s' finished in %s"); got != "Tarefa '%s' concluída em %s" {
+		t.Errorf("Format() = %q; want the built-in translation", got)
+	}
+
+	// A project catalog alone is enough for a language drun does not ship
+	if err := os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{"Task durations:": "Durées :"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if translator, err := Load("fr", dir); err != nil || translator.Format("Task durations:") != "Durées :" {
+		t.Errorf("Load(fr, dir) = %v, %v; want the project catalog", translator, err)
+	}
+}
+
+func TestMessageTranslatesRenderedErrors(t *testing.T) {
+	translator, err := Load("pt-BR", "")
+	if err != nil {
+		t.Fatalf("Load(pt-BR) error = %v", err)
+	}
+
+	cause := fmt.Errorf("command failed with exit code %d%s", 3, ": boom")
+	wrapped := fmt.Errorf("task '%s' failed: %w", "build", cause)
+	got := translator.Message(fmt.Sprintf("Error: execution failed: %v", wrapped))
+	want := "Erro: a execução falhou: a tarefa 'build' falhou: o comando falhou com o código de saída 3: boom"
+	if got != want {
+		t.Errorf("Message() = %q; want %q", got, want)
+	}
+
+	if got := translator.Message("no task specified\n"); got != "nenhuma tarefa especificada\n" {
+		t.Errorf("Message() = %q; want the translation", got)
+	}
+	if got := translator.Message("something else entirely"); got != "something else entirely" {
+		t.Errorf("Message() = %q; want the message unchanged", got)
+	}
+
+	var english *Translator
+	if got := english.Message("no task specified"); got != "no task specified" || english.Language() != DefaultLanguage {
+		t.Errorf("nil translator changed %q to %q", "no task specified", got)
+	}
+}
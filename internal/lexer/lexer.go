@@ -1,17 +1,24 @@
 package lexer
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
-// No imports needed for basic lexer
+// byteOrderMark is the UTF-8 encoding of U+FEFF, which Windows editors
+// often write at the start of a file
+const byteOrderMark = "\ufeff"
 
 // Lexer tokenizes drun v2 source code
 type Lexer struct {
-	input        string
-	position     int  // current position in input (points to current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
-	line         int  // current line number
-	column       int  // current column number
+	source       string // the input as given, which token positions refer to
+	removed      []int  // offsets in input at which normalization removed a byte of source
+	input        string // the source with LF line endings and no byte order mark
+	position     int    // current position in input (points to current char)
+	readPosition int    // current reading position in input (after current char)
+	ch           byte   // current char under examination
+	line         int    // current line number
+	column       int    // current column number
 
 	// Indentation tracking for Python-style blocks
 	indentStack    []int // stack of indentation levels
@@ -19,10 +26,14 @@ type Lexer struct {
 	pendingDedents int   // number of DEDENT tokens to emit
 }
 
-// NewLexer creates a new lexer instance
+// NewLexer creates a new lexer instance. Sources with a byte order mark or
+// CRLF or CR line endings tokenize like LF sources.
 func NewLexer(input string) *Lexer {
+	normalized, removed := normalizeSource(input)
 	l := &Lexer{
-		input:       input,
+		source:      input,
+		removed:     removed,
+		input:       normalized,
 		line:        1,
 		column:      0,
 		indentStack: []int{0}, // start with zero indentation
@@ -51,6 +62,56 @@ func (l *Lexer) readChar() {
 	}
 }
 
+// Offset returns the byte offset just past the most recently returned token
+func (l *Lexer) Offset() int {
+	if len(l.removed) == 0 {
+		return l.position
+	}
+	// An end offset stays before a carriage return removed there
+	return l.position + sort.SearchInts(l.removed, l.position)
+}
+
+// Normalize returns source with a leading byte order mark removed and CRLF
+// and CR line endings converted to LF, as the lexer reads it
+func Normalize(source string) string {
+	normalized, _ := normalizeSource(source)
+	return normalized
+}
+
+// normalizeSource normalizes source like Normalize and returns, for every
+// byte it removed, the offset in the normalized text where it was
+func normalizeSource(source string) (string, []int) {
+	if !strings.HasPrefix(source, byteOrderMark) && !strings.Contains(source, "\r") {
+		return source, nil
+	}
+	var removed []int
+	if strings.HasPrefix(source, byteOrderMark) {
+		source = source[len(byteOrderMark):]
+		removed = []int{0, 0, 0}
+	}
+	var out strings.Builder
+	out.Grow(len(source))
+	for i := 0; i < len(source); i++ {
+		if source[i] != '\r' {
+			out.WriteByte(source[i])
+		} else if i+1 < len(source) && source[i+1] == '\n' {
+			removed = append(removed, out.Len())
+		} else {
+			out.WriteByte('\n')
+		}
+	}
+	return out.String(), removed
+}
+
+// sourceOffset maps the offset of a character of the normalized input to
+// the source
+func (l *Lexer) sourceOffset(offset int) int {
+	if len(l.removed) == 0 {
+		return offset
+	}
+	return offset + sort.SearchInts(l.removed, offset+1)
+}
+
 // peekChar returns the next character without advancing position
 func (l *Lexer) peekChar() byte {
 	if l.readPosition >= len(l.input) {
@@ -61,6 +122,13 @@ func (l *Lexer) peekChar() byte {
 
 // NextToken scans and returns the next token
 func (l *Lexer) NextToken() Token {
+	tok := l.scanToken()
+	tok.Position = l.sourceOffset(tok.Position)
+	return tok
+}
+
+// scanToken scans the next token; its position is in the normalized input
+func (l *Lexer) scanToken() Token {
 	var tok Token
 
 	// Handle pending DEDENT tokens first
@@ -126,6 +194,9 @@ func (l *Lexer) NextToken() Token {
 	case '*':
 		tok.Type = STAR
 		tok.Literal = string(l.ch)
+	case '|':
+		tok.Type = PIPE
+		tok.Literal = string(l.ch)
 	case '/':
 		if l.peekChar() == '*' {
 			tok.Type = MULTILINE_COMMENT
@@ -259,12 +330,12 @@ func (l *Lexer) handleIndentation() Token {
 			l.readChar()
 			l.atLineStart = true
 		}
-		return l.NextToken()
+		return l.scanToken()
 	}
 
 	// Comments are tokens, but their leading whitespace is indentation-neutral.
 	if firstContent == '#' || (firstContent == '/' && pos+1 < len(l.input) && l.input[pos+1] == '*') {
-		return l.NextToken()
+		return l.scanToken()
 	}
 
 	currentIndent := l.indentStack[len(l.indentStack)-1]
@@ -314,7 +385,7 @@ func (l *Lexer) handleIndentation() Token {
 	}
 
 	// Same indentation level - continue with normal tokenization
-	return l.NextToken()
+	return l.scanToken()
 }
 
 // readString reads a string literal (supports multi-line strings)
@@ -369,6 +440,100 @@ func (l *Lexer) readString() string {
 	return result.String()
 }
 
+// ReadRawBlock consumes the lines that follow the current line and are
+// indented deeper than the current block, returning them verbatim with the
+// common indentation removed. It is meant to be called once the last token of
+// a line (such as the colon of "doc:") has been read; ok is false when other
+// content follows on that line. Blank lines inside the block are kept and
+// trailing blank lines are dropped. The block is invisible to indentation
+// tracking, so no INDENT or DEDENT tokens are produced for it.
+func (l *Lexer) ReadRawBlock() (block string, ok bool) {
+	rest := l.input[l.position:]
+	lineEnd := strings.IndexByte(rest, '\n')
+	if lineEnd < 0 {
+		lineEnd = len(rest)
+	}
+	if strings.TrimSpace(rest[:lineEnd]) != "" {
+		return "", false
+	}
+
+	level := l.indentStack[len(l.indentStack)-1]
+	start := min(l.position+lineEnd+1, len(l.input))
+	end := start
+	var lines []string
+
+	for pos := start; pos < len(l.input); {
+		next := len(l.input)
+		lineEnd := len(l.input)
+		if idx := strings.IndexByte(l.input[pos:], '\n'); idx >= 0 {
+			lineEnd = pos + idx
+			next = lineEnd + 1
+		}
+		line := strings.TrimSuffix(l.input[pos:lineEnd], "\r")
+
+		if strings.TrimSpace(line) != "" {
+			indent := 0
+			for _, ch := range line {
+				if ch == ' ' {
+					indent++
+				} else if ch == '\t' {
+					indent += 4
+				} else {
+					break
+				}
+			}
+			if indent <= level {
+				break
+			}
+		}
+
+		lines = append(lines, line)
+		pos = next
+		end = next
+	}
+
+	// Move to the start of the first line after the block, keeping line
+	// numbers in sync
+	l.line += strings.Count(l.input[l.position:start], "\n") + strings.Count(l.input[start:end], "\n")
+	l.readPosition = end
+	l.column = 0
+	l.readChar()
+	l.atLineStart = true
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return dedentLines(lines), true
+}
+
+// dedentLines removes the leading whitespace shared by all non-blank lines
+func dedentLines(lines []string) string {
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // readComment reads a comment until end of line (but doesn't consume the newline)
 func (l *Lexer) readComment() string {
 	position := l.position
@@ -478,9 +643,9 @@ func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-// GetInput returns the input string being lexed
+// GetInput returns the input string being lexed, as given
 func (l *Lexer) GetInput() string {
-	return l.input
+	return l.source
 }
 
 // AllTokens returns all tokens from the input (useful for testing)
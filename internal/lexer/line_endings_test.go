@@ -0,0 +1,72 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLexer_WindowsLineEndingsAndByteOrderMark(t *testing.T) {
+	input := "# release tasks\nversion: 2.0\n\ntask \"build\":\n  # compile\n  run:\n    go build ./...\n    go vet ./...\n  info \"a\\\\b\"\n\n  if true:\n    info \"done\"\n"
+
+	variants := map[string]string{
+		"crlf":  strings.ReplaceAll(input, "\n", "\r\n"),
+		"cr":    strings.ReplaceAll(input, "\n", "\r"),
+		"bom":   "\ufeff" + input,
+		"mixed": "\ufeff" + strings.Replace(strings.ReplaceAll(input, "\n", "\r\n"), "\r\n", "\n", 3),
+	}
+
+	expected := NewLexer(input).AllTokens()
+	for name, variant := range variants {
+		got := NewLexer(variant).AllTokens()
+		if len(got) != len(expected) {
+			t.Errorf("%s: got %d tokens, want %d", name, len(got), len(expected))
+			continue
+		}
+		for i := range expected {
+			if got[i].Type != expected[i].Type || got[i].Literal != expected[i].Literal || got[i].Line != expected[i].Line {
+				t.Errorf("%s: token %d = %s %q (line %d), want %s %q (line %d)", name, i,
+					got[i].Type, got[i].Literal, got[i].Line, expected[i].Type, expected[i].Literal, expected[i].Line)
+				break
+			}
+		}
+	}
+}
+
+func TestLexer_PositionsReferToTheSourceAsGiven(t *testing.T) {
+	source := "\ufeffversion: 2.0\r\n\r\ntask \"build\":\r\n  info \"hi\"\r\n"
+	l := NewLexer(source)
+	for {
+		tok := l.NextToken()
+		if tok.Type == EOF {
+			break
+		}
+		switch tok.Type {
+		case VERSION, TASK, INFO:
+			if !strings.HasPrefix(source[tok.Position:], tok.Literal) {
+				t.Errorf("%s at %d points at %q", tok.Literal, tok.Position, source[tok.Position:min(tok.Position+10, len(source))])
+			}
+		case STRING:
+			if !strings.HasPrefix(source[tok.Position:], `"`+tok.Literal+`"`) || l.Offset() != tok.Position+len(tok.Literal)+2 {
+				t.Errorf("string %q spans %d-%d", tok.Literal, tok.Position, l.Offset())
+			}
+		}
+	}
+	if l.GetInput() != source {
+		t.Errorf("GetInput() = %q, want the source as given", l.GetInput())
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"a\nb\n":           "a\nb\n",
+		"a\r\nb\r\n":       "a\nb\n",
+		"a\rb\r":           "a\nb\n",
+		"\ufeffa\r\n\r\nb": "a\n\nb",
+		"a\r\r\nb":         "a\n\nb",
+	}
+	for input, want := range tests {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -364,6 +364,9 @@ const (
 	ELSE      // else
 	OTHERWISE // otherwise
 	FOR       // for
+	WHILE     // while
+	POLL      // poll
+	UNTIL     // until
 	EACH      // each
 	IN        // in
 	PARALLEL  // parallel
@@ -436,6 +439,7 @@ const (
 	STAR   // *
 	SLASH  // /
 	EQUALS // =
+	PIPE   // |
 
 	// Punctuation
 	COLON     // :
@@ -1112,6 +1116,12 @@ func (t TokenType) String() string {
 		return "OTHERWISE"
 	case FOR:
 		return "FOR"
+	case WHILE:
+		return "WHILE"
+	case POLL:
+		return "POLL"
+	case UNTIL:
+		return "UNTIL"
 	case EACH:
 		return "EACH"
 	case IN:
@@ -1226,6 +1236,8 @@ func (t TokenType) String() string {
 		return "SLASH"
 	case EQUALS:
 		return "EQUALS"
+	case PIPE:
+		return "PIPE"
 	case COLON:
 		return "COLON"
 	case COMMA:
@@ -1583,6 +1595,9 @@ var keywords = map[string]TokenType{
 	"else":          ELSE,
 	"otherwise":     OTHERWISE,
 	"for":           FOR,
+	"while":         WHILE,
+	"poll":          POLL,
+	"until":         UNTIL,
 	"each":          EACH,
 	"in":            IN,
 	"parallel":      PARALLEL,
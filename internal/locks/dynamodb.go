@@ -0,0 +1,234 @@
+package locks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dynamoDBBackend keeps each lock in an item of a DynamoDB table whose
+// partition key is a string attribute, "name" unless the URL sets key=.
+// Conditional writes make taking, renewing and releasing atomic. The
+// credentials come from $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY and
+// $AWS_SESSION_TOKEN; the region from region= or $AWS_REGION.
+type dynamoDBBackend struct {
+	url      *url.URL
+	table    string
+	key      string
+	region   string
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+}
+
+func newDynamoDBBackend(u *url.URL) (*dynamoDBBackend, error) {
+	query := u.Query()
+	b := &dynamoDBBackend{
+		url:      u,
+		table:    u.Host,
+		key:      query.Get("key"),
+		region:   query.Get("region"),
+		endpoint: query.Get("endpoint"),
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}
+	if b.table == "" {
+		return nil, fmt.Errorf("invalid lock backend %q: expected dynamodb://table", u.String())
+	}
+	if b.key == "" {
+		b.key = "name"
+	}
+	if b.region == "" {
+		b.region = os.Getenv("AWS_REGION")
+	}
+	if b.region == "" {
+		b.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if b.region == "" {
+		return nil, fmt.Errorf("lock backend %s: no AWS region; add ?region= or set AWS_REGION", u.String())
+	}
+	if b.endpoint == "" {
+		b.endpoint = "https://dynamodb." + b.region + ".amazonaws.com"
+	}
+	return b, nil
+}
+
+func (b *dynamoDBBackend) String() string {
+	return redactURL(b.url)
+}
+
+// attributeNames are the attribute names used by the lock expressions
+func (b *dynamoDBBackend) attributeNames() map[string]string {
+	return map[string]string{"#k": b.key, "#o": "owner", "#e": "expires"}
+}
+
+// TryAcquire puts the item of the lock unless an unexpired lease of someone
+// else is in it
+func (b *dynamoDBBackend) TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, string, error) {
+	now := b.now()
+	resp, err := b.call(ctx, "PutItem", map[string]any{
+		"TableName": b.table,
+		"Item": map[string]any{
+			b.key:     map[string]string{"S": name},
+			"owner":   map[string]string{"S": owner},
+			"expires": map[string]string{"N": strconv.FormatInt(now.Add(ttl).UnixMilli(), 10)},
+		},
+		"ConditionExpression":                 "attribute_not_exists(#k) OR #e < :now OR #o = :owner",
+		"ExpressionAttributeNames":            b.attributeNames(),
+		"ExpressionAttributeValues":           map[string]any{":now": map[string]string{"N": strconv.FormatInt(now.UnixMilli(), 10)}, ":owner": map[string]string{"S": owner}},
+		"ReturnValuesOnConditionCheckFailure": "ALL_OLD",
+	})
+	if err == nil {
+		return true, "", nil
+	}
+	if resp != nil && resp.conditionFailed() {
+		return false, resp.Item["owner"].S, nil
+	}
+	return false, "", err
+}
+
+// Renew moves the expiry of the item while owner holds it
+func (b *dynamoDBBackend) Renew(ctx context.Context, name, owner string, ttl time.Duration) error {
+	resp, err := b.call(ctx, "UpdateItem", map[string]any{
+		"TableName":                 b.table,
+		"Key":                       map[string]any{b.key: map[string]string{"S": name}},
+		"UpdateExpression":          "SET #e = :expires",
+		"ConditionExpression":       "#o = :owner",
+		"ExpressionAttributeNames":  map[string]string{"#o": "owner", "#e": "expires"},
+		"ExpressionAttributeValues": map[string]any{":expires": map[string]string{"N": strconv.FormatInt(b.now().Add(ttl).UnixMilli(), 10)}, ":owner": map[string]string{"S": owner}},
+	})
+	if resp != nil && resp.conditionFailed() {
+		return ErrNotHeld
+	}
+	return err
+}
+
+// Release deletes the item while owner holds it
+func (b *dynamoDBBackend) Release(ctx context.Context, name, owner string) error {
+	resp, err := b.call(ctx, "DeleteItem", map[string]any{
+		"TableName":                 b.table,
+		"Key":                       map[string]any{b.key: map[string]string{"S": name}},
+		"ConditionExpression":       "#o = :owner",
+		"ExpressionAttributeNames":  map[string]string{"#o": "owner"},
+		"ExpressionAttributeValues": map[string]any{":owner": map[string]string{"S": owner}},
+	})
+	if resp != nil && resp.conditionFailed() {
+		return nil
+	}
+	return err
+}
+
+// dynamoDBResponse is the part of a DynamoDB response the backend reads
+type dynamoDBResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+	Item    map[string]struct {
+		S string `json:"S"`
+	} `json:"Item"`
+}
+
+func (r *dynamoDBResponse) conditionFailed() bool {
+	return strings.HasSuffix(r.Type, "ConditionalCheckFailedException")
+}
+
+// call sends a signed DynamoDB request. The response is returned with the
+// error of a failed request, so conditional check failures can be told
+// apart.
+func (b *dynamoDBBackend) call(ctx context.Context, operation string, payload map[string]any) (*dynamoDBResponse, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("no AWS credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "DynamoDB_20120810."+operation)
+	signAWSRequest(req, body, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), b.region, "dynamodb", b.now())
+
+	httpResp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	data, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+
+	resp := &dynamoDBResponse{}
+	_ = json.Unmarshal(data, resp)
+	if httpResp.StatusCode != http.StatusOK {
+		if resp.Type == "" {
+			return resp, fmt.Errorf("DynamoDB %s returned %d %s", operation, httpResp.StatusCode, http.StatusText(httpResp.StatusCode))
+		}
+		return resp, fmt.Errorf("DynamoDB %s: %s: %s", operation, resp.Type[strings.LastIndex(resp.Type, "#")+1:], resp.Message)
+	}
+	return resp, nil
+}
+
+// signAWSRequest adds an AWS Signature Version 4 to a request
+func signAWSRequest(req *http.Request, body []byte, accessKey, secretKey, sessionToken, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	names := []string{"content-type", "host", "x-amz-date"}
+	if sessionToken != "" {
+		names = append(names, "x-amz-security-token")
+	}
+	names = append(names, "x-amz-target")
+	var headers strings.Builder
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, headers.String(), signedHeaders, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
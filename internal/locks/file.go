@@ -0,0 +1,179 @@
+package locks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// guardStaleAfter is how old the guard directory of a lock file may get
+// before it is taken to be left behind by a crashed process
+const guardStaleAfter = 10 * time.Second
+
+// FileBackend keeps each lock in a file of a directory. It coordinates the
+// processes of one machine, or of every machine that mounts the directory.
+type FileBackend struct {
+	dir string
+}
+
+// fileLease is the content of a lock file
+type fileLease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewFileBackend returns a backend keeping its locks in dir
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+// DefaultDir returns the directory of the locks of this machine,
+// ~/.drun/locks
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".drun", "locks"), nil
+}
+
+func (b *FileBackend) String() string {
+	return b.dir
+}
+
+// TryAcquire takes the lock if its file is missing, expired or already
+// owned by owner
+func (b *FileBackend) TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, string, error) {
+	acquired, holder := false, ""
+	err := b.guarded(ctx, name, func(path string) error {
+		current, err := readFileLease(path)
+		if err != nil {
+			return err
+		}
+		if current != nil && current.Owner != owner && time.Now().Before(current.Expires) {
+			holder = current.Owner
+			return nil
+		}
+		acquired = true
+		return writeFileLease(path, fileLease{Owner: owner, Expires: time.Now().Add(ttl)})
+	})
+	return acquired, holder, err
+}
+
+// Renew extends the lease of owner
+func (b *FileBackend) Renew(ctx context.Context, name, owner string, ttl time.Duration) error {
+	return b.guarded(ctx, name, func(path string) error {
+		current, err := readFileLease(path)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.Owner != owner {
+			return ErrNotHeld
+		}
+		return writeFileLease(path, fileLease{Owner: owner, Expires: time.Now().Add(ttl)})
+	})
+}
+
+// Release removes the lock file if owner holds the lock
+func (b *FileBackend) Release(ctx context.Context, name, owner string) error {
+	return b.guarded(ctx, name, func(path string) error {
+		current, err := readFileLease(path)
+		if err != nil || current == nil || current.Owner != owner {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// guarded runs fn with the path of the lock file while holding its guard, a
+// directory next to it whose creation is atomic
+func (b *FileBackend) guarded(ctx context.Context, name string, fn func(path string) error) error {
+	if err := os.MkdirAll(b.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	path := filepath.Join(b.dir, lockFileName(name))
+	guard := path + ".guard"
+
+	for {
+		err := os.Mkdir(guard, 0750)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(guard); statErr == nil && time.Since(info.ModTime()) > guardStaleAfter {
+			_ = os.Remove(guard)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	defer func() { _ = os.Remove(guard) }()
+	return fn(path)
+}
+
+// lockFileName returns the file name of a lock. Characters that are not
+// safe in file names are replaced, and a hash of the name keeps names that
+// differ only in those characters apart.
+func lockFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if safe != name || safe == "" {
+		sum := sha256.Sum256([]byte(name))
+		safe += "-" + hex.EncodeToString(sum[:4])
+	}
+	return safe + ".lock"
+}
+
+// readFileLease reads a lock file; a missing file is a free lock
+func readFileLease(path string) (*fileLease, error) {
+	// #nosec G304 -- lock files live in the configured lock directory.
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	var lease fileLease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		// A lock file cut short by a crash is treated as expired
+		return nil, nil
+	}
+	return &lease, nil
+}
+
+// writeFileLease replaces a lock file through a temporary file
+func writeFileLease(path string, lease fileLease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package locks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// httpBackend keeps locks in an HTTP lock service. Every lock is a
+// resource below the base URL:
+//
+//	PUT    <base>/<name>  {"owner": "...", "ttl_seconds": 60}
+//	       200 or 201 when taken or renewed, 409 or 423 when held by
+//	       someone else, with {"owner": "..."} naming the holder
+//	DELETE <base>/<name>  {"owner": "..."}
+//
+// The URL's user and password are sent as basic auth, and
+// $DRUN_LOCK_TOKEN as a bearer token.
+type httpBackend struct {
+	url    *url.URL
+	base   string
+	client *http.Client
+}
+
+// httpLockRequest is the body of the requests to an HTTP lock service
+type httpLockRequest struct {
+	Owner      string `json:"owner"`
+	TTLSeconds int64  `json:"ttl_seconds,omitempty"`
+}
+
+func newHTTPBackend(u *url.URL) *httpBackend {
+	return &httpBackend{
+		url:    u,
+		base:   strings.TrimSuffix(u.String(), "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (b *httpBackend) String() string {
+	return redactURL(b.url)
+}
+
+// TryAcquire puts the lock
+func (b *httpBackend) TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, string, error) {
+	status, body, err := b.send(ctx, http.MethodPut, name, httpLockRequest{Owner: owner, TTLSeconds: ttlSeconds(ttl)})
+	if err != nil {
+		return false, "", err
+	}
+	switch status {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return true, "", nil
+	case http.StatusConflict, http.StatusLocked:
+		var holder httpLockRequest
+		_ = json.Unmarshal(body, &holder)
+		return false, holder.Owner, nil
+	default:
+		return false, "", httpLockError(status, body)
+	}
+}
+
+// Renew puts the lock again; the service refuses it when owner lost it
+func (b *httpBackend) Renew(ctx context.Context, name, owner string, ttl time.Duration) error {
+	status, body, err := b.send(ctx, http.MethodPut, name, httpLockRequest{Owner: owner, TTLSeconds: ttlSeconds(ttl)})
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusConflict, http.StatusLocked:
+		return ErrNotHeld
+	default:
+		return httpLockError(status, body)
+	}
+}
+
+// Release deletes the lock; a lock that is already gone is released
+func (b *httpBackend) Release(ctx context.Context, name, owner string) error {
+	status, body, err := b.send(ctx, http.MethodDelete, name, httpLockRequest{Owner: owner})
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound, http.StatusConflict:
+		return nil
+	default:
+		return httpLockError(status, body)
+	}
+}
+
+func (b *httpBackend) send(ctx context.Context, method, name string, payload httpLockRequest) (int, []byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.base+"/"+url.PathEscape(name), bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.url.User != nil {
+		password, _ := b.url.User.Password()
+		req.SetBasicAuth(b.url.User.Username(), password)
+	}
+	if token := os.Getenv("DRUN_LOCK_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, body, nil
+}
+
+func httpLockError(status int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		return fmt.Errorf("lock service returned %d %s", status, http.StatusText(status))
+	}
+	return fmt.Errorf("lock service returned %d %s: %s", status, http.StatusText(status), message)
+}
+
+// ttlSeconds rounds a lease length up to whole seconds
+func ttlSeconds(ttl time.Duration) int64 {
+	return int64((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,244 @@
+// Package locks implements the named locks of the lock statement. A lock is
+// a lease kept in a backend — a directory on this machine, Redis, DynamoDB
+// or an HTTP lock service — and renewed while it is held, so the lock of a
+// holder that dies expires on its own.
+package locks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long a lease lasts without being renewed
+const DefaultTTL = time.Minute
+
+// DefaultPollInterval is how often a held lock is tried again
+const DefaultPollInterval = 2 * time.Second
+
+// ErrNotHeld is returned when renewing a lock that expired and was taken by
+// someone else, or was removed
+var ErrNotHeld = errors.New("lock is no longer held")
+
+// ErrTimeout is returned when a lock is still held by someone else once the
+// wait is over
+var ErrTimeout = errors.New("timed out waiting for lock")
+
+// Backend stores leases. Every operation is atomic in the backend, so two
+// machines never hold the same lock.
+type Backend interface {
+	// TryAcquire takes the lock for owner for ttl when it is free or its
+	// lease expired. When someone else holds it, it returns false and the
+	// holder, if the backend knows it.
+	TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (acquired bool, holder string, err error)
+	// Renew extends the lease of owner; it returns ErrNotHeld when owner
+	// does not hold the lock anymore
+	Renew(ctx context.Context, name, owner string, ttl time.Duration) error
+	// Release frees the lock if owner holds it
+	Release(ctx context.Context, name, owner string) error
+	// String describes the backend for messages, without credentials
+	String() string
+}
+
+// Open returns the backend of a lock URL:
+//
+//	""  or "file"              a directory in ~/.drun/locks, for this machine only
+//	file:///path/to/dir        another directory, such as one on a shared volume
+//	redis://[:password@]host[:port][/db], rediss://...
+//	dynamodb://table[?region=eu-west-1][&endpoint=http://localhost:8000]
+//	http://host/locks, https://...  an HTTP lock service
+func Open(spec string) (Backend, error) {
+	if spec == "" || spec == "file" {
+		dir, err := DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+		return NewFileBackend(dir), nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock backend %q: %w", spec, err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("invalid lock backend %q: expected file:///path/to/dir", spec)
+		}
+		return NewFileBackend(u.Path), nil
+	case "redis", "rediss":
+		return newRedisBackend(u)
+	case "dynamodb":
+		return newDynamoDBBackend(u)
+	case "http", "https":
+		return newHTTPBackend(u), nil
+	default:
+		return nil, fmt.Errorf("unsupported lock backend %q (supported: file, redis, rediss, dynamodb, http, https)", u.Scheme)
+	}
+}
+
+// Owner returns a name for this process that is unique among the holders
+// of a lock, such as alice@build-3 (pid 4121, 9f2c1a7e)
+func Owner() string {
+	name := "drun"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		name = current.Username
+	}
+	host, _ := os.Hostname()
+	nonce := make([]byte, 4)
+	_, _ = rand.Read(nonce)
+	return fmt.Sprintf("%s@%s (pid %d, %s)", name, host, os.Getpid(), hex.EncodeToString(nonce))
+}
+
+// Options control how Acquire waits for a lock
+type Options struct {
+	Owner        string        // holder name; Owner() when empty
+	TTL          time.Duration // lease length; DefaultTTL when zero
+	Wait         time.Duration // how long to wait for a held lock; zero waits until it is free
+	PollInterval time.Duration // DefaultPollInterval when zero
+	// OnWait is called once, when the lock is first found held
+	OnWait func(holder string)
+}
+
+// Lease is a held lock. It is renewed in the background until Release.
+type Lease struct {
+	backend Backend
+	name    string
+	owner   string
+	ttl     time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+	mu   sync.Mutex
+	err  error
+}
+
+// Acquire waits until the lock is free, takes it and keeps renewing it
+func Acquire(ctx context.Context, backend Backend, name string, opts Options) (*Lease, error) {
+	if opts.Owner == "" {
+		opts.Owner = Owner()
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	var deadline time.Time
+	if opts.Wait > 0 {
+		deadline = time.Now().Add(opts.Wait)
+	}
+	waited := false
+	for {
+		acquired, holder, err := backend.TryAcquire(ctx, name, opts.Owner, opts.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("acquiring lock '%s' in %s: %w", name, backend, err)
+		}
+		if acquired {
+			break
+		}
+		if !waited && opts.OnWait != nil {
+			opts.OnWait(holder)
+		}
+		waited = true
+		if !deadline.IsZero() && time.Now().Add(opts.PollInterval).After(deadline) {
+			if holder != "" {
+				return nil, fmt.Errorf("%w '%s' held by %s", ErrTimeout, name, holder)
+			}
+			return nil, fmt.Errorf("%w '%s'", ErrTimeout, name)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(opts.PollInterval):
+		}
+	}
+
+	lease := &Lease{
+		backend: backend,
+		name:    name,
+		owner:   opts.Owner,
+		ttl:     opts.TTL,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go lease.renew()
+	return lease, nil
+}
+
+// renew extends the lease every third of its length until it is released
+// or lost
+func (l *Lease) renew() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			err := l.backend.Renew(ctx, l.name, l.owner, l.ttl)
+			cancel()
+			if errors.Is(err, ErrNotHeld) {
+				l.setErr(err)
+				return
+			}
+			// Other errors are retried on the next tick; the lease is lost
+			// only once it expired
+		}
+	}
+}
+
+func (l *Lease) setErr(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.err = err
+}
+
+// Err returns ErrNotHeld once the lease was lost while held
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Release stops renewing the lease and frees the lock. It returns
+// ErrNotHeld when the lease was lost while held.
+func (l *Lease) Release() error {
+	close(l.stop)
+	<-l.done
+
+	if err := l.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := l.backend.Release(ctx, l.name, l.owner); err != nil {
+		return fmt.Errorf("releasing lock '%s' in %s: %w", l.name, l.backend, err)
+	}
+	return nil
+}
+
+// redactURL returns u without its password, for messages
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.RawQuery = ""
+	if redacted.User != nil {
+		if name := redacted.User.Username(); name != "" {
+			redacted.User = url.User(name)
+		} else {
+			redacted.User = nil
+		}
+	}
+	return strings.TrimSuffix(redacted.String(), "?")
+}
@@ -0,0 +1,216 @@
+package locks
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisKeyPrefix namespaces the keys of drun's locks in a shared Redis
+const redisKeyPrefix = "drun:lock:"
+
+// Lua scripts that only touch the key while owner holds it
+const (
+	redisRenewScript   = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	redisReleaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// redisBackend keeps each lock in a Redis key that expires with its lease
+type redisBackend struct {
+	url      *url.URL
+	address  string
+	username string
+	password string
+	db       int
+	tls      bool
+}
+
+func newRedisBackend(u *url.URL) (*redisBackend, error) {
+	b := &redisBackend{url: u, address: u.Host, tls: u.Scheme == "rediss"}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid lock backend %q: missing host", redactURL(u))
+	}
+	if u.Port() == "" {
+		b.address = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		b.username = u.User.Username()
+		b.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		n, err := strconv.Atoi(db)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid lock backend %q: database must be a number", redactURL(u))
+		}
+		b.db = n
+	}
+	return b, nil
+}
+
+func (b *redisBackend) String() string {
+	return redactURL(b.url)
+}
+
+// TryAcquire sets the key of the lock unless it exists
+func (b *redisBackend) TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, string, error) {
+	var acquired bool
+	var holder string
+	err := b.session(ctx, func(conn *redisConn) error {
+		reply, err := conn.do("SET", redisKeyPrefix+name, owner, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+		if err != nil {
+			return err
+		}
+		if reply != nil {
+			acquired = true
+			return nil
+		}
+		current, err := conn.do("GET", redisKeyPrefix+name)
+		if err != nil {
+			return err
+		}
+		if current == owner {
+			// Taken by an earlier attempt of ours whose reply was lost
+			acquired = true
+			_, err = conn.do("PEXPIRE", redisKeyPrefix+name, strconv.FormatInt(ttl.Milliseconds(), 10))
+			return err
+		}
+		holder, _ = current.(string)
+		return nil
+	})
+	return acquired, holder, err
+}
+
+// Renew extends the expiry of the key while owner holds it
+func (b *redisBackend) Renew(ctx context.Context, name, owner string, ttl time.Duration) error {
+	return b.session(ctx, func(conn *redisConn) error {
+		reply, err := conn.do("EVAL", redisRenewScript, "1", redisKeyPrefix+name, owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+		if err != nil {
+			return err
+		}
+		if reply != int64(1) {
+			return ErrNotHeld
+		}
+		return nil
+	})
+}
+
+// Release deletes the key while owner holds it
+func (b *redisBackend) Release(ctx context.Context, name, owner string) error {
+	return b.session(ctx, func(conn *redisConn) error {
+		_, err := conn.do("EVAL", redisReleaseScript, "1", redisKeyPrefix+name, owner)
+		return err
+	})
+}
+
+// session connects, authenticates, selects the database and runs fn
+func (b *redisBackend) session(ctx context.Context, fn func(conn *redisConn) error) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var netConn net.Conn
+	var err error
+	if b.tls {
+		netConn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", b.address)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", b.address)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = netConn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	} else {
+		_ = netConn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+
+	conn := &redisConn{rw: bufio.NewReadWriter(bufio.NewReader(netConn), bufio.NewWriter(netConn))}
+	if b.password != "" {
+		args := []string{"AUTH", b.password}
+		if b.username != "" {
+			args = []string{"AUTH", b.username, b.password}
+		}
+		if _, err := conn.do(args...); err != nil {
+			return fmt.Errorf("authenticating: %w", err)
+		}
+	}
+	if b.db != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(b.db)); err != nil {
+			return err
+		}
+	}
+	return fn(conn)
+}
+
+// redisConn speaks the Redis protocol (RESP) on a connection
+type redisConn struct {
+	rw *bufio.ReadWriter
+}
+
+// do sends a command and returns its reply: a string, an int64, nil for a
+// null reply, or a []any for an array
+func (c *redisConn) do(args ...string) (any, error) {
+	_, _ = fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		_, _ = fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+func (c *redisConn) read() (any, error) {
+	line, err := c.rw.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply from redis")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply from redis: %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rw, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply from redis: %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("invalid reply from redis: %q", line)
+	}
+}
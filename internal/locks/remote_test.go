@@ -0,0 +1,297 @@
+package locks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBackendContract runs the operations every backend supports against
+// a fresh lock service
+func testBackendContract(t *testing.T, backend Backend) {
+	t.Helper()
+	ctx := context.Background()
+
+	if acquired, _, err := backend.TryAcquire(ctx, "deploy", "alice", time.Minute); err != nil || !acquired {
+		t.Fatalf("TryAcquire() = %v, %v; want the free lock", acquired, err)
+	}
+	acquired, holder, err := backend.TryAcquire(ctx, "deploy", "bob", time.Minute)
+	if err != nil || acquired || holder != "alice" {
+		t.Fatalf("TryAcquire() by another owner = %v, %q, %v; want held by alice", acquired, holder, err)
+	}
+	if err := backend.Renew(ctx, "deploy", "alice", time.Minute); err != nil {
+		t.Errorf("Renew() error = %v", err)
+	}
+	if err := backend.Renew(ctx, "deploy", "bob", time.Minute); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Renew() by another owner = %v, want ErrNotHeld", err)
+	}
+	if err := backend.Release(ctx, "deploy", "bob"); err != nil {
+		t.Errorf("Release() by another owner = %v", err)
+	}
+	if err := backend.Release(ctx, "deploy", "alice"); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+	if acquired, _, err := backend.TryAcquire(ctx, "deploy", "bob", time.Minute); err != nil || !acquired {
+		t.Errorf("TryAcquire() after the release = %v, %v; want the free lock", acquired, err)
+	}
+}
+
+func TestRedisBackend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	server := &fakeRedis{password: "hunter2", keys: make(map[string]string)}
+	go server.serve(listener)
+
+	backend, err := Open("redis://:hunter2@" + listener.Addr().String() + "/3")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	testBackendContract(t, backend)
+	if server.db != "3" {
+		t.Errorf("SELECT %q, want 3", server.db)
+	}
+	if _, ok := server.keys[redisKeyPrefix+"deploy"]; !ok {
+		t.Errorf("keys = %v, want the prefixed lock key", server.keys)
+	}
+
+	wrong, _ := Open("redis://:wrong@" + listener.Addr().String())
+	if _, _, err := wrong.TryAcquire(context.Background(), "deploy", "alice", time.Minute); err == nil || !strings.Contains(err.Error(), "authenticating") {
+		t.Errorf("TryAcquire() with a wrong password = %v", err)
+	}
+}
+
+// fakeRedis understands the commands of the Redis backend; expiry is not
+// simulated
+type fakeRedis struct {
+	password string
+	mu       sync.Mutex
+	keys     map[string]string
+	db       string
+}
+
+func (s *fakeRedis) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			header, _ := reader.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			args[i] = string(data[:size])
+		}
+		_, _ = io.WriteString(conn, s.reply(args))
+	}
+}
+
+func (s *fakeRedis) reply(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch args[0] {
+	case "AUTH":
+		if args[len(args)-1] != s.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		return "+OK\r\n"
+	case "SELECT":
+		s.db = args[1]
+		return "+OK\r\n"
+	case "SET":
+		if _, exists := s.keys[args[1]]; exists {
+			return "$-1\r\n"
+		}
+		s.keys[args[1]] = args[2]
+		return "+OK\r\n"
+	case "GET":
+		value, exists := s.keys[args[1]]
+		if !exists {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "PEXPIRE":
+		return ":1\r\n"
+	case "EVAL":
+		if s.keys[args[3]] != args[4] {
+			return ":0\r\n"
+		}
+		if args[1] == redisReleaseScript {
+			delete(s.keys, args[3])
+		}
+		return ":1\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func TestHTTPBackend(t *testing.T) {
+	var mu sync.Mutex
+	owners := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ops" || password != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload httpLockRequest
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		name := strings.TrimPrefix(r.URL.Path, "/locks/")
+
+		mu.Lock()
+		defer mu.Unlock()
+		holder, held := owners[name]
+		switch {
+		case held && holder != payload.Owner:
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(httpLockRequest{Owner: holder})
+		case r.Method == http.MethodPut:
+			if payload.TTLSeconds != 60 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			owners[name] = payload.Owner
+		case r.Method == http.MethodDelete:
+			delete(owners, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	backend, err := Open("http://ops:hunter2@" + u.Host + "/locks/")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	testBackendContract(t, backend)
+
+	unauthorized, _ := Open(server.URL + "/locks")
+	if _, _, err := unauthorized.TryAcquire(context.Background(), "other", "alice", time.Minute); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("TryAcquire() without credentials = %v, want a 401 error", err)
+	}
+}
+
+func TestDynamoDBBackend(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	type item struct{ owner, expires string }
+	var mu sync.Mutex
+	items := make(map[string]item)
+	conditionFailed := func(w http.ResponseWriter, old *item) {
+		w.WriteHeader(http.StatusBadRequest)
+		resp := map[string]any{"__type": "com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException", "message": "The conditional request failed"}
+		if old != nil {
+			resp["Item"] = map[string]any{"owner": map[string]string{"S": old.owner}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/dynamodb/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req struct {
+			TableName                 string
+			Item, Key                 map[string]map[string]string
+			ExpressionAttributeValues map[string]map[string]string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.TableName != "drun-locks" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		owner := req.ExpressionAttributeValues[":owner"]["S"]
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Header.Get("X-Amz-Target") {
+		case "DynamoDB_20120810.PutItem":
+			name := req.Item["lock"]["S"]
+			if old, exists := items[name]; exists && old.owner != owner {
+				conditionFailed(w, &old)
+				return
+			}
+			items[name] = item{owner: owner, expires: req.Item["expires"]["N"]}
+		case "DynamoDB_20120810.UpdateItem", "DynamoDB_20120810.DeleteItem":
+			name := req.Key["lock"]["S"]
+			old, exists := items[name]
+			if !exists || old.owner != owner {
+				conditionFailed(w, nil)
+				return
+			}
+			if strings.HasSuffix(r.Header.Get("X-Amz-Target"), "DeleteItem") {
+				delete(items, name)
+			}
+		}
+		_, _ = io.WriteString(w, "{}")
+	}))
+	defer server.Close()
+
+	backend, err := Open("dynamodb://drun-locks?region=eu-west-1&key=lock&endpoint=" + url.QueryEscape(server.URL))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	testBackendContract(t, backend)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	if _, _, err := backend.TryAcquire(context.Background(), "other", "alice", time.Minute); err == nil || !strings.Contains(err.Error(), "no AWS credentials") {
+		t.Errorf("TryAcquire() without credentials = %v", err)
+	}
+}
+
+func TestSignAWSRequest(t *testing.T) {
+	// The signing key derivation example of the AWS Signature Version 4
+	// documentation
+	key := hmacSHA256([]byte("AWS4wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"), "20120215")
+	key = hmacSHA256(key, "us-east-1")
+	key = hmacSHA256(key, "iam")
+	key = hmacSHA256(key, "aws4_request")
+	if got := fmt.Sprintf("%x", key); got != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Errorf("signing key = %s", got)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://dynamodb.eu-west-1.amazonaws.com/", nil)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "DynamoDB_20120810.PutItem")
+	signAWSRequest(req, []byte("{}"), "AKID", "secret", "token", "eu-west-1", "dynamodb", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20260102/eu-west-1/dynamodb/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=") {
+		t.Errorf("Authorization = %s", auth)
+	}
+	if req.Header.Get("X-Amz-Date") != "20260102T030405Z" || req.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Errorf("headers = %v", req.Header)
+	}
+}
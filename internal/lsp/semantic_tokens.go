@@ -0,0 +1,206 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// SemanticTokenTypes is the token type legend, in the order used by the LSP
+// semanticTokens encoding
+var SemanticTokenTypes = []string{
+	"keyword",
+	"action",
+	"variable",
+	"string",
+	"number",
+	"comment",
+	"namespace",
+}
+
+// SemanticToken classifies a range of a drun source. Line and Character are
+// zero-based and, like Length, counted in UTF-16 code units as in LSP. A token
+// never spans lines; multi-line strings and comments are split per line.
+type SemanticToken struct {
+	Line      int    `json:"line"`
+	Character int    `json:"character"`
+	Length    int    `json:"length"`
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+}
+
+// actionTokens are the built-in actions highlighted separately from keywords
+var actionTokens = map[lexer.TokenType]bool{
+	lexer.INFO:    true,
+	lexer.STEP:    true,
+	lexer.WARN:    true,
+	lexer.ERROR:   true,
+	lexer.SUCCESS: true,
+	lexer.FAIL:    true,
+	lexer.ECHO:    true,
+	lexer.RUN:     true,
+	lexer.EXEC:    true,
+	lexer.SHELL:   true,
+	lexer.CAPTURE: true,
+}
+
+// SemanticTokens classifies the tokens of a drun source using the lexer, so
+// editors can highlight it without reimplementing the lexer
+func SemanticTokens(text string) []SemanticToken {
+	lines := newLineIndex(text)
+	l := lexer.NewLexer(text)
+
+	var tokens []SemanticToken
+	var prev, prevPrev lexer.Token
+	includeLine := 0
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			break
+		}
+		start := tok.Position
+		end := l.Offset()
+
+		switch tokenType := classifyToken(tok); {
+		case tok.Type == lexer.INCLUDE:
+			includeLine = tok.Line
+			tokens = lines.appendRange(tokens, text, start, end, tokenType)
+		case tok.Type == lexer.IDENT && prev.Type == lexer.AS && tok.Line == includeLine:
+			// include "lib.drun" as shared
+			tokens = lines.appendRange(tokens, text, start, end, "namespace")
+		case tok.Type == lexer.STRING && isQualifiedReference(prevPrev, prev) && strings.Contains(tok.Literal, "."):
+			// call task "docker.build" / use snippet "docker.login"
+			namespaceEnd := start + 1 + strings.Index(tok.Literal, ".")
+			tokens = lines.appendRange(tokens, text, start, start+1, "string")
+			tokens = lines.appendRange(tokens, text, start+1, namespaceEnd, "namespace")
+			tokens = lines.appendRange(tokens, text, namespaceEnd, end, "string")
+		case tokenType != "":
+			tokens = lines.appendRange(tokens, text, start, end, tokenType)
+		}
+
+		if tok.Type != lexer.INDENT && tok.Type != lexer.DEDENT && tok.Type != lexer.NEWLINE {
+			prevPrev, prev = prev, tok
+		}
+	}
+
+	return tokens
+}
+
+// classifyToken returns the semantic token type of tok, or "" when the token
+// is not highlighted (identifiers, punctuation, layout)
+func classifyToken(tok lexer.Token) string {
+	switch tok.Type {
+	case lexer.STRING:
+		return "string"
+	case lexer.NUMBER:
+		return "number"
+	case lexer.VARIABLE:
+		return "variable"
+	case lexer.BOOLEAN:
+		return "keyword"
+	case lexer.COMMENT, lexer.MULTILINE_COMMENT:
+		return "comment"
+	case lexer.IDENT, lexer.ILLEGAL:
+		return ""
+	}
+	if actionTokens[tok.Type] {
+		return "action"
+	}
+	if tok.Literal != "" && lexer.LookupIdent(tok.Literal) == tok.Type {
+		return "keyword"
+	}
+	return ""
+}
+
+// isQualifiedReference reports whether the two preceding tokens introduce a
+// task or snippet reference that may be namespaced
+func isQualifiedReference(prevPrev, prev lexer.Token) bool {
+	return prevPrev.Type == lexer.CALL && prev.Type == lexer.TASK ||
+		prevPrev.Type == lexer.USE && prev.Type == lexer.SNIPPET
+}
+
+// EncodeSemanticTokens encodes tokens in the LSP relative format: five
+// integers per token (delta line, delta start, length, type index, modifiers)
+func EncodeSemanticTokens(tokens []SemanticToken) []int {
+	typeIndex := make(map[string]int, len(SemanticTokenTypes))
+	for i, name := range SemanticTokenTypes {
+		typeIndex[name] = i
+	}
+
+	data := make([]int, 0, len(tokens)*5)
+	prevLine, prevChar := 0, 0
+	for _, tok := range tokens {
+		deltaLine := tok.Line - prevLine
+		deltaChar := tok.Character
+		if deltaLine == 0 {
+			deltaChar = tok.Character - prevChar
+		}
+		data = append(data, deltaLine, deltaChar, tok.Length, typeIndex[tok.Type], 0)
+		prevLine, prevChar = tok.Line, tok.Character
+	}
+	return data
+}
+
+// lineIndex maps byte offsets to zero-based lines
+type lineIndex struct {
+	starts []int
+}
+
+func newLineIndex(text string) lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return lineIndex{starts: starts}
+}
+
+// appendRange appends the byte range [start, end) of text as tokens of
+// tokenType, one per line it covers; line breaks are not included
+func (li lineIndex) appendRange(tokens []SemanticToken, text string, start, end int, tokenType string) []SemanticToken {
+	if end > len(text) {
+		end = len(text)
+	}
+	line := 0
+	for line+1 < len(li.starts) && li.starts[line+1] <= start {
+		line++
+	}
+
+	for start < end {
+		lineEnd := len(text)
+		if line+1 < len(li.starts) {
+			lineEnd = li.starts[line+1] - 1 // the '\n'
+		}
+		segmentEnd := end
+		if segmentEnd > lineEnd {
+			segmentEnd = lineEnd
+		}
+		segment := strings.TrimRight(text[start:segmentEnd], "\r")
+		if segment != "" {
+			tokens = append(tokens, SemanticToken{
+				Line:      line,
+				Character: utf16Len(text[li.starts[line]:start]),
+				Length:    utf16Len(segment),
+				Type:      tokenType,
+				Text:      segment,
+			})
+		}
+		if line+1 >= len(li.starts) {
+			break
+		}
+		line++
+		start = li.starts[line]
+	}
+	return tokens
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSemanticTokensClassifiesSource(t *testing.T) {
+	source := "version: 2.0\n\n# build things\ntask \"build\":\n  info \"é {$name}\"\n  run \"make\"\n"
+	tokens := SemanticTokens(source)
+
+	want := []SemanticToken{
+		{Line: 0, Character: 0, Length: 7, Type: "keyword", Text: "version"},
+		{Line: 0, Character: 9, Length: 3, Type: "number", Text: "2.0"},
+		{Line: 2, Character: 0, Length: 14, Type: "comment", Text: "# build things"},
+		{Line: 3, Character: 0, Length: 4, Type: "keyword", Text: "task"},
+		{Line: 3, Character: 5, Length: 7, Type: "string", Text: `"build"`},
+		{Line: 4, Character: 2, Length: 4, Type: "action", Text: "info"},
+		{Line: 4, Character: 7, Length: 11, Type: "string", Text: `"é {$name}"`},
+		{Line: 5, Character: 2, Length: 3, Type: "action", Text: "run"},
+		{Line: 5, Character: 6, Length: 6, Type: "string", Text: `"make"`},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokens =\n%#v\nwant\n%#v", tokens, want)
+	}
+}
+
+func TestSemanticTokensMarksNamespaces(t *testing.T) {
+	source := "version: 2.0\n\nproject \"app\":\n  include \"lib.drun\" as shared\n\ntask \"go\":\n  call task \"shared.build\"\n"
+	tokens := SemanticTokens(source)
+
+	var namespaces []SemanticToken
+	for _, tok := range tokens {
+		if tok.Type == "namespace" {
+			namespaces = append(namespaces, tok)
+		}
+	}
+	want := []SemanticToken{
+		{Line: 3, Character: 24, Length: 6, Type: "namespace", Text: "shared"},
+		{Line: 6, Character: 13, Length: 6, Type: "namespace", Text: "shared"},
+	}
+	if !reflect.DeepEqual(namespaces, want) {
+		t.Fatalf("namespace tokens = %#v, want %#v", namespaces, want)
+	}
+}
+
+func TestSemanticTokensSplitsMultilineTokens(t *testing.T) {
+	source := "/* first\r\n   second */\n"
+	tokens := SemanticTokens(source)
+
+	want := []SemanticToken{
+		{Line: 0, Character: 0, Length: 8, Type: "comment", Text: "/* first"},
+		{Line: 1, Character: 0, Length: 12, Type: "comment", Text: "   second */"},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokens = %#v, want %#v", tokens, want)
+	}
+}
+
+func TestEncodeSemanticTokensUsesRelativePositions(t *testing.T) {
+	tokens := []SemanticToken{
+		{Line: 0, Character: 0, Length: 4, Type: "keyword"},
+		{Line: 0, Character: 5, Length: 7, Type: "string"},
+		{Line: 2, Character: 2, Length: 4, Type: "action"},
+	}
+	want := []int{
+		0, 0, 4, 0, 0,
+		0, 5, 7, 3, 0,
+		2, 2, 4, 1, 0,
+	}
+	if got := EncodeSemanticTokens(tokens); !reflect.DeepEqual(got, want) {
+		t.Fatalf("EncodeSemanticTokens = %v, want %v", got, want)
+	}
+}
@@ -63,11 +63,28 @@ var keywordCompletions = []completionItem{
 	{Label: "update yaml", Kind: completionItemKindKeyword, Detail: "Update a YAML value"},
 	{Label: "update toml", Kind: completionItemKindKeyword, Detail: "Update a TOML value"},
 	{Label: "update match", Kind: completionItemKindKeyword, Detail: "Update a regular-expression capture"},
+	{Label: "get version from files", Kind: completionItemKindKeyword, Detail: "Read the version several manifests declare"},
+	{Label: "bump version", Kind: completionItemKindKeyword, Detail: "Bump the version of several manifests"},
+	{Label: "confirm", Kind: completionItemKindKeyword, Detail: "Ask before a destructive step, with an optional timeout"},
+	{Label: "validated by", Kind: completionItemKindKeyword, Detail: "Check a parameter with a drun-validate-<name> plugin"},
+	{Label: "todo", Kind: completionItemKindKeyword, Detail: "Placeholder for unwritten work (see set todo behavior)"},
+	{Label: "let shadow", Kind: completionItemKindKeyword, Detail: "Replace the value of a read-only task parameter on purpose"},
+	{Label: "keeping lines containing", Kind: completionItemKindKeyword, Detail: "Keep only the output lines that contain a text"},
+	{Label: "dropping lines containing", Kind: completionItemKindKeyword, Detail: "Leave out the output lines that contain a text"},
+	{Label: "filtering lines matching", Kind: completionItemKindKeyword, Detail: "Keep only the output lines that match a regular expression"},
+	{Label: "http client", Kind: completionItemKindKeyword, Detail: "Named base URL, headers and timeout for HTTP statements"},
+	{Label: "using client", Kind: completionItemKindKeyword, Detail: "Send an HTTP request with a project HTTP client"},
 	{Label: "use workdir", Kind: completionItemKindKeyword, Detail: "Change working directory"},
 	{Label: "call task", Kind: completionItemKindKeyword, Detail: "Call another task"},
 	{Label: "orchestrate", Kind: completionItemKindKeyword, Detail: "Orchestration definition or action"},
 	{Label: "service", Kind: completionItemKindKeyword, Detail: "Service definition"},
+	{Label: "test", Kind: completionItemKindKeyword, Detail: "Golden-file test of a task's output"},
+	{Label: "expect output to match golden", Kind: completionItemKindKeyword, Detail: "Compare a test's output with a golden file"},
 	{Label: "attached", Kind: completionItemKindKeyword, Detail: "Interactive run modifier"},
+	{Label: "interactively", Kind: completionItemKindKeyword, Detail: "Run modifier that requires a terminal (PTY)"},
+	{Label: "mapping exit code", Kind: completionItemKindKeyword, Detail: "Treat a command exit code as a named outcome"},
+	{Label: "failing with", Kind: completionItemKindKeyword, Detail: "Custom message when a command fails"},
+	{Label: "pipe output of", Kind: completionItemKindKeyword, Detail: "Stream one command's output into another"},
 	{Label: "git policy", Kind: completionItemKindKeyword, Detail: "Git conventions policy block"},
 	{Label: "git validate", Kind: completionItemKindKeyword, Detail: "Validate git conventions"},
 	{Label: "branch", Kind: completionItemKindKeyword, Detail: "Branch policy block"},
@@ -113,14 +130,29 @@ type initializeResult struct {
 }
 
 type serverCapabilities struct {
-	TextDocumentSync   int                `json:"textDocumentSync"`
-	CompletionProvider *completionOptions `json:"completionProvider,omitempty"`
+	TextDocumentSync       int                    `json:"textDocumentSync"`
+	CompletionProvider     *completionOptions     `json:"completionProvider,omitempty"`
+	SemanticTokensProvider *semanticTokensOptions `json:"semanticTokensProvider,omitempty"`
 }
 
 type completionOptions struct {
 	ResolveProvider bool `json:"resolveProvider"`
 }
 
+type semanticTokensOptions struct {
+	Legend semanticTokensLegend `json:"legend"`
+	Full   bool                 `json:"full"`
+}
+
+type semanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+type semanticTokensResult struct {
+	Data []int `json:"data"`
+}
+
 type serverInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -163,6 +195,10 @@ type completionParams struct {
 	TextDocument textDocumentIdentifier `json:"textDocument"`
 }
 
+type semanticTokensParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
 type publishDiagnosticsParams struct {
 	URI         string       `json:"uri"`
 	Diagnostics []diagnostic `json:"diagnostics"`
@@ -246,6 +282,13 @@ func (s *Server) handleMessage(msg message) (bool, error) {
 					CompletionProvider: &completionOptions{
 						ResolveProvider: false,
 					},
+					SemanticTokensProvider: &semanticTokensOptions{
+						Legend: semanticTokensLegend{
+							TokenTypes:     SemanticTokenTypes,
+							TokenModifiers: []string{},
+						},
+						Full: true,
+					},
 				},
 				ServerInfo: serverInfo{
 					Name:    "xdrun-lsp",
@@ -305,6 +348,17 @@ func (s *Server) handleMessage(msg message) (bool, error) {
 			ID:      msg.ID,
 			Result:  items,
 		})
+	case "textDocument/semanticTokens/full":
+		var params semanticTokensParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return false, err
+		}
+		text := s.docs[params.TextDocument.URI]
+		return false, s.writeResponse(message{
+			JSONRPC: "2.0",
+			ID:      msg.ID,
+			Result:  semanticTokensResult{Data: EncodeSemanticTokens(SemanticTokens(text))},
+		})
 	default:
 		if len(msg.ID) == 0 {
 			return false, nil
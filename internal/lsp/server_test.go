@@ -99,6 +99,56 @@ func TestServerCompletionIncludesKeywordsAndTasks(t *testing.T) {
 	assertFileValueCompletions(t, items)
 }
 
+func TestServerSemanticTokens(t *testing.T) {
+	input := joinFrames(
+		frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`),
+		frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///workspace/spec.drun","languageId":"drun","version":1,"text":"task \"deploy\":\n  info \"ok\"\n"}}}`),
+		frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/semanticTokens/full","params":{"textDocument":{"uri":"file:///workspace/spec.drun"}}}`),
+		frame(`{"jsonrpc":"2.0","id":3,"method":"shutdown","params":{}}`),
+		frame(`{"jsonrpc":"2.0","method":"exit","params":{}}`),
+	)
+
+	var output bytes.Buffer
+	server := NewServer(bytes.NewReader(input), &output)
+	if err := server.Run(); err != nil {
+		t.Fatalf("server run failed: %v", err)
+	}
+
+	var initResult initializeResult
+	var tokensResult semanticTokensResult
+	foundTokens := false
+	for _, msg := range decodeFrames(t, output.Bytes()) {
+		switch string(msg.ID) {
+		case "1":
+			if err := json.Unmarshal(mustMarshal(msg.Result), &initResult); err != nil {
+				t.Fatalf("unmarshal initialize result: %v", err)
+			}
+		case "2":
+			if err := json.Unmarshal(mustMarshal(msg.Result), &tokensResult); err != nil {
+				t.Fatalf("unmarshal semantic tokens: %v", err)
+			}
+			foundTokens = true
+		}
+	}
+
+	provider := initResult.Capabilities.SemanticTokensProvider
+	if provider == nil || !provider.Full || len(provider.Legend.TokenTypes) != len(SemanticTokenTypes) {
+		t.Fatalf("expected semantic tokens capability, got %#v", provider)
+	}
+	if !foundTokens {
+		t.Fatal("expected semantic tokens response")
+	}
+	want := []int{
+		0, 0, 4, 0, 0, // task
+		0, 5, 8, 3, 0, // "deploy"
+		1, 2, 4, 1, 0, // info
+		0, 5, 4, 3, 0, // "ok"
+	}
+	if fmt.Sprint(tokensResult.Data) != fmt.Sprint(want) {
+		t.Fatalf("semantic tokens = %v, want %v", tokensResult.Data, want)
+	}
+}
+
 func TestFileValueDiagnosticsAreLocalized(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,148 @@
+// Package netretry retries HTTP requests that failed for a reason that is
+// likely to pass: a timeout, a reset or refused connection, or a 408, 429 or
+// 5xx response. Retries wait with exponential backoff and jitter, and only
+// requests that are safe to repeat (GET, HEAD, OPTIONS, PUT, DELETE) are
+// retried.
+package netretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// DefaultRetries is how many times a request is retried unless configured
+const DefaultRetries = 2
+
+// Policy decides how often and how long apart requests are retried. The
+// zero Policy does not retry.
+type Policy struct {
+	Retries   int           // retries after the first attempt; 0 turns retries off
+	BaseDelay time.Duration // wait before the first retry, doubled for each next one
+	MaxDelay  time.Duration // longest wait between attempts
+
+	// BeforeRetry, when set, is called before each retry with the attempt
+	// about to start (2 for the first retry), the reason for it and the
+	// wait. An error gives up, returning the failed attempt's outcome.
+	BeforeRetry func(attempt int, reason string, wait time.Duration) error
+}
+
+// DefaultPolicy returns the policy used unless one is configured: two
+// retries, half a second then about a second apart
+func DefaultPolicy() Policy {
+	return Policy{Retries: DefaultRetries, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+}
+
+// Do sends req with client, retrying transient failures. A request with a
+// body is only retried when the body can be read again (req.GetBody), as
+// for requests created from strings, bytes or readers of them.
+func (p Policy) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		reason := Transient(resp, err)
+		if reason == "" || attempt > p.Retries || !Repeatable(req) {
+			return resp, err
+		}
+
+		wait := p.delay(attempt)
+		if p.BeforeRetry != nil {
+			if stop := p.BeforeRetry(attempt+1, reason, wait); stop != nil {
+				return resp, err
+			}
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			_ = resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// delay returns the wait before the retry after attempt: the base delay
+// doubled for each earlier retry, capped, with the upper half randomized so
+// clients that failed together do not retry together
+func (p Policy) delay(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		return 0
+	}
+	wait := base << min(attempt-1, 30)
+	if p.MaxDelay > 0 && (wait > p.MaxDelay || wait <= 0) {
+		wait = p.MaxDelay
+	}
+	half := wait / 2
+	return half + rand.N(half+1) // #nosec G404 -- jitter does not need a secure source
+}
+
+// sleep waits for d unless ctx ends first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Repeatable reports whether req may be sent again without side effects
+// beyond those of sending it once
+func Repeatable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, "":
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// Transient returns why the outcome of a request is worth retrying, or ""
+// when it is not: a success, a client error, or a failure that a retry
+// would repeat
+func Transient(resp *http.Response, err error) string {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return ""
+		}
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return "timeout"
+		case errors.Is(err, syscall.ECONNRESET):
+			return "connection reset"
+		case errors.Is(err, syscall.ECONNREFUSED):
+			return "connection refused"
+		case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+			return "connection closed"
+		}
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsTimeout) {
+			return "DNS lookup failed"
+		}
+		return ""
+	}
+	switch code := resp.StatusCode; {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests,
+		code >= 500 && code != http.StatusNotImplemented && code != http.StatusHTTPVersionNotSupported:
+		return fmt.Sprintf("status %s", resp.Status)
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package netretry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status and answers
+// "ok" after that; it returns the server and its request count
+func flakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(append([]byte("ok"), body...))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func quickPolicy(retries int) Policy {
+	return Policy{Retries: retries, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestDoRetriesTransientStatuses(t *testing.T) {
+	server, calls := flakyServer(t, 2, http.StatusServiceUnavailable)
+
+	var trace []string
+	policy := quickPolicy(2)
+	policy.BeforeRetry = func(attempt int, reason string, wait time.Duration) error {
+		if wait > 5*time.Millisecond {
+			t.Errorf("wait %s exceeds the maximum delay", wait)
+		}
+		trace = append(trace, reason)
+		return nil
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(" body"))
+	resp, err := policy.Do(http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || string(body) != "ok body" {
+		t.Fatalf("expected the third attempt to succeed with the body resent, got %d %q", resp.StatusCode, body)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+	if len(trace) != 2 || trace[0] != "status 503 Service Unavailable" {
+		t.Fatalf("unexpected retry trace: %q", trace)
+	}
+}
+
+func TestDoGivesUpAfterItsRetries(t *testing.T) {
+	server, calls := flakyServer(t, 5, http.StatusBadGateway)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := quickPolicy(1).Do(http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 2 {
+		t.Fatalf("expected the last failed response after 2 attempts, got %d after %d", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDoDoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+		policy Policy
+	}{
+		{name: "post", method: http.MethodPost, status: http.StatusServiceUnavailable, policy: quickPolicy(2)},
+		{name: "client error", method: http.MethodGet, status: http.StatusNotFound, policy: quickPolicy(2)},
+		{name: "not implemented", method: http.MethodGet, status: http.StatusNotImplemented, policy: quickPolicy(2)},
+		{name: "zero policy", method: http.MethodGet, status: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flakyServer(t, 1, tt.status)
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader("x"))
+			resp, err := tt.policy.Do(http.DefaultClient, req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close()
+			if calls.Load() != 1 || resp.StatusCode != tt.status {
+				t.Fatalf("expected one attempt returning %d, got %d attempts and %d", tt.status, calls.Load(), resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestBeforeRetryCanGiveUp(t *testing.T) {
+	server, calls := flakyServer(t, 1, http.StatusTooManyRequests)
+
+	policy := quickPolicy(3)
+	policy.BeforeRetry = func(int, string, time.Duration) error { return errors.New("budget spent") }
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := policy.Do(http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls.Load() != 1 || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the first response to be returned, got %d after %d attempts", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestDoRetriesRefusedConnections(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	var reasons []string
+	policy := quickPolicy(1)
+	policy.BeforeRetry = func(_ int, reason string, _ time.Duration) error {
+		reasons = append(reasons, reason)
+		return nil
+	}
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if _, err := policy.Do(http.DefaultClient, req); err == nil {
+		t.Fatal("expected the request to a closed server to fail")
+	}
+	if len(reasons) != 1 || reasons[0] != "connection refused" {
+		t.Fatalf("expected one retry of the refused connection, got %q", reasons)
+	}
+}
+
+func TestDelayBacksOffWithJitter(t *testing.T) {
+	policy := Policy{Retries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for range 20 {
+		if d := policy.delay(1); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("first delay %s outside [50ms, 100ms]", d)
+		}
+		if d := policy.delay(2); d < 100*time.Millisecond || d > 200*time.Millisecond {
+			t.Fatalf("second delay %s outside [100ms, 200ms]", d)
+		}
+		if d := policy.delay(5); d < 150*time.Millisecond || d > 300*time.Millisecond {
+			t.Fatalf("capped delay %s outside [150ms, 300ms]", d)
+		}
+	}
+}
@@ -226,3 +226,46 @@ task "test":
 		})
 	}
 }
+
+func TestParser_FilePathParameter(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  requires $config as file path which must exist
+  given $notes as file defaults to "NOTES.md"
+  info "deploying"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	params := program.Tasks[0].Parameters
+	if len(params) != 2 {
+		t.Fatalf("Expected 2 parameters, got %d", len(params))
+	}
+	if params[0].DataType != "file" || !params[0].MustExist {
+		t.Errorf("Expected existing file parameter, got %+v", params[0])
+	}
+	if params[1].DataType != "file" || params[1].MustExist || params[1].DefaultValue != "NOTES.md" {
+		t.Errorf("Expected optional file parameter with default, got %+v", params[1])
+	}
+}
+
+func TestParser_FilePathParameterRequiresExist(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  requires $config as file path which should exist
+  info "deploying"`
+
+	p := NewParser(lexer.NewLexer(input))
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatal("Expected a parser error for an incomplete 'which must exist'")
+	}
+}
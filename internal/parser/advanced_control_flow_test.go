@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -432,3 +433,277 @@ task "complex_loop_test":
 		t.Errorf("break condition should not be empty")
 	}
 }
+
+func TestParser_PaginatedHTTPLoop(t *testing.T) {
+	tests := []struct {
+		name     string
+		loop     string
+		expected ast.PaginationClause
+	}{
+		{
+			name:     "link header",
+			loop:     `for each $repo in get "https://api.example.com/repos" paginated by header "Link":`,
+			expected: ast.PaginationClause{Strategy: "header", Name: "Link"},
+		},
+		{
+			name:     "cursor with param and items",
+			loop:     `for each $node in get "https://api.example.com/nodes" paginated by cursor "meta.next" param "after" items at "data.nodes":`,
+			expected: ast.PaginationClause{Strategy: "cursor", Name: "meta.next", Param: "after", ItemsPath: "data.nodes"},
+		},
+		{
+			name:     "query parameter",
+			loop:     `for each $item in get "https://api.example.com/items?page=1" paginated by query "page":`,
+			expected: ast.PaginationClause{Strategy: "query", Name: "page"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "version: 2.0\n\ntask \"inventory\":\n  " + tt.loop + "\n    info \"{$item}\"\n"
+
+			p := NewParser(lexer.NewLexer(input))
+			program := p.ParseProgram()
+			checkParserErrors(t, p)
+
+			loopStmt, ok := program.Tasks[0].Body[0].(*ast.LoopStatement)
+			if !ok {
+				t.Fatalf("first statement should be LoopStatement. got=%T", program.Tasks[0].Body[0])
+			}
+			if loopStmt.Type != "http" {
+				t.Errorf("loop type not 'http'. got=%q", loopStmt.Type)
+			}
+			if loopStmt.Pagination == nil || *loopStmt.Pagination != tt.expected {
+				t.Errorf("pagination = %+v, want %+v", loopStmt.Pagination, tt.expected)
+			}
+			if !strings.HasPrefix(loopStmt.String(), strings.TrimSuffix(tt.loop, ":")) {
+				t.Errorf("String() = %q, want it to start with %q", loopStmt.String(), tt.loop)
+			}
+		})
+	}
+}
+
+func TestParser_StdinLinesLoop(t *testing.T) {
+	input := "version: 2.0\n\ntask \"import-data\":\n  for each $row in lines of stdin in parallel:\n    info \"{$row}\"\n"
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	loopStmt, ok := program.Tasks[0].Body[0].(*ast.LoopStatement)
+	if !ok {
+		t.Fatalf("first statement should be LoopStatement. got=%T", program.Tasks[0].Body[0])
+	}
+	if loopStmt.Type != "stdin" || loopStmt.Variable != "$row" || !loopStmt.Parallel {
+		t.Errorf("loop = %+v, want a parallel stdin loop over $row", loopStmt)
+	}
+	if !strings.HasPrefix(loopStmt.String(), "for each $row in lines of stdin") {
+		t.Errorf("String() = %q", loopStmt.String())
+	}
+
+	for _, loop := range []string{"for each $row in lines of input:", "for each $row in rows of stdin:"} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"import-data\":\n  " + loop + "\n    info \"{$row}\"\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %s", loop)
+		}
+	}
+}
+
+func TestParser_PaginatedHTTPLoopErrors(t *testing.T) {
+	tests := []string{
+		`for each $item in get "https://api.example.com/items":`,
+		`for each $item in get "https://api.example.com/items" paginated by offset "start":`,
+		`for each $item in get "https://api.example.com/items" paginated by header "Link" in parallel:`,
+	}
+
+	for _, loop := range tests {
+		input := "version: 2.0\n\ntask \"inventory\":\n  " + loop + "\n    info \"{$item}\"\n"
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %s", loop)
+		}
+	}
+}
+
+func TestParser_WhileLoop(t *testing.T) {
+	tests := []struct {
+		name      string
+		loop      string
+		condition string
+		limit     int
+	}{
+		{"comparison", `while {$attempts} < 5 and {$status} != "ready":`, "{$attempts} < 5 and {$status} != ready", 0},
+		{"with limit", `while {$status} is "pending" at most 30 times:`, "{$status} is pending", 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "version: 2.0\n\ntask \"poll\":\n  " + tt.loop + "\n    info \"waiting\"\n  info \"done\"\n"
+
+			p := NewParser(lexer.NewLexer(input))
+			program := p.ParseProgram()
+			checkParserErrors(t, p)
+
+			task := program.Tasks[0]
+			if len(task.Body) != 2 {
+				t.Fatalf("expected the loop and one statement after it, got %d statements", len(task.Body))
+			}
+			loopStmt, ok := task.Body[0].(*ast.LoopStatement)
+			if !ok {
+				t.Fatalf("first statement should be LoopStatement. got=%T", task.Body[0])
+			}
+			if loopStmt.Type != "while" {
+				t.Errorf("loop type not 'while'. got=%q", loopStmt.Type)
+			}
+			if loopStmt.Condition != tt.condition {
+				t.Errorf("condition = %q, want %q", loopStmt.Condition, tt.condition)
+			}
+			if loopStmt.MaxIterations != tt.limit {
+				t.Errorf("max iterations = %d, want %d", loopStmt.MaxIterations, tt.limit)
+			}
+			if len(loopStmt.Body) != 1 {
+				t.Errorf("expected 1 body statement, got %d", len(loopStmt.Body))
+			}
+		})
+	}
+}
+
+func TestParser_WhileLoopWithoutCondition(t *testing.T) {
+	p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"poll\":\n  while:\n    info \"waiting\"\n"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatal("expected a parse error for a while loop without a condition")
+	}
+}
+
+func TestParser_PollStatement(t *testing.T) {
+	input := `version: 2.0
+
+task "wait":
+  poll every 10s up to 5 minutes:
+    get "http://svc/health" capture status as $code capture header "Retry-After" as $retry
+  until {$code} is "200"
+  info "healthy"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	task := program.Tasks[0]
+	if len(task.Body) != 2 {
+		t.Fatalf("expected the poll block and one statement after it, got %d statements", len(task.Body))
+	}
+	loopStmt, ok := task.Body[0].(*ast.LoopStatement)
+	if !ok {
+		t.Fatalf("first statement should be LoopStatement. got=%T", task.Body[0])
+	}
+	if loopStmt.Type != "poll" || loopStmt.PollInterval != "10s" || loopStmt.PollTimeout != "5m" {
+		t.Errorf("unexpected poll loop: type=%q every=%q up to=%q", loopStmt.Type, loopStmt.PollInterval, loopStmt.PollTimeout)
+	}
+	if loopStmt.Condition != "{$code} is 200" {
+		t.Errorf("condition = %q, want %q", loopStmt.Condition, "{$code} is 200")
+	}
+
+	httpStmt, ok := loopStmt.Body[0].(*ast.HTTPStatement)
+	if !ok {
+		t.Fatalf("poll body should hold an HTTPStatement. got=%T", loopStmt.Body[0])
+	}
+	want := []ast.HTTPCapture{
+		{Part: "status", Variable: "$code"},
+		{Part: "header", Header: "Retry-After", Variable: "$retry"},
+	}
+	if len(httpStmt.Captures) != len(want) {
+		t.Fatalf("expected %d captures, got %+v", len(want), httpStmt.Captures)
+	}
+	for i := range want {
+		if httpStmt.Captures[i] != want[i] {
+			t.Errorf("capture %d = %+v, want %+v", i, httpStmt.Captures[i], want[i])
+		}
+	}
+}
+
+func TestParser_PollStatementErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"missing until", "  poll every 10s up to 5m:\n    info \"waiting\"\n  info \"done\"\n", "no until condition"},
+		{"missing limit", "  poll every 10s:\n    info \"waiting\"\n  until \"a\" is \"a\"\n", "time limit"},
+		{"unknown unit", "  poll every 10 fortnights up to 5m:\n    info \"waiting\"\n  until \"a\" is \"a\"\n", "unknown time unit"},
+		{"capture without variable", "  poll every 10s up to 5m:\n    get \"http://svc\" capture status\n  until \"a\" is \"a\"\n", "AS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"wait\":\n" + tt.input))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatalf("expected a parse error containing %q", tt.err)
+			}
+			if !strings.Contains(strings.Join(p.Errors(), "\n"), tt.err) {
+				t.Fatalf("expected an error containing %q, got %v", tt.err, p.Errors())
+			}
+		})
+	}
+}
+
+func TestParser_ChunkLoop(t *testing.T) {
+	input := `version: 2.0
+
+task "migrate":
+  for each chunk of 50 files in "migrations/*.sql":
+    run "psql {$chunk_files}"
+  for each chunk $batch of 1 file in "{$dir}/*.json" in parallel:
+    info "{$batch}"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	tests := []struct {
+		variable string
+		size     int
+		glob     string
+		parallel bool
+	}{
+		{"$chunk", 50, "migrations/*.sql", false},
+		{"$batch", 1, "{$dir}/*.json", true},
+	}
+	for i, tt := range tests {
+		loopStmt, ok := program.Tasks[0].Body[i].(*ast.LoopStatement)
+		if !ok {
+			t.Fatalf("statement %d should be a LoopStatement. got=%T", i, program.Tasks[0].Body[i])
+		}
+		if loopStmt.Type != "chunk" || loopStmt.Variable != tt.variable || loopStmt.ChunkSize != tt.size || loopStmt.Iterable != tt.glob || loopStmt.Parallel != tt.parallel {
+			t.Errorf("unexpected chunk loop %d: %+v", i, loopStmt)
+		}
+	}
+
+	if got := program.Tasks[0].Body[0].String(); !strings.HasPrefix(got, `for each chunk $chunk of 50 files in "migrations/*.sql":`) {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParser_ChunkLoopErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"zero size", "  for each chunk of 0 files in \"*.sql\":\n    info \"x\"\n", "positive whole number"},
+		{"missing files", "  for each chunk of 5 in \"*.sql\":\n    info \"x\"\n", "expected 'file'"},
+		{"filtered", "  for each chunk of 5 files in \"*.sql\" where $chunk contains \"a\":\n    info \"x\"\n", "cannot be filtered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"migrate\":\n" + tt.input))
+			p.ParseProgram()
+			if !strings.Contains(strings.Join(p.Errors(), "\n"), tt.err) {
+				t.Fatalf("expected an error containing %q, got %v", tt.err, p.Errors())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_AssertStatements(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  assert {replicas} > 0 otherwise "replicas must be positive"
+  assert file "Dockerfile" exists
+  if $ci is "true":
+    assert env CI_TOKEN exists otherwise "CI_TOKEN is required"
+  info "deploying"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 4 {
+		t.Fatalf("Expected 4 statements in task body, got %d", len(body))
+	}
+
+	tests := []struct {
+		stmt      ast.Statement
+		condition string
+		message   string
+	}{
+		{body[0], "{replicas} > 0", "replicas must be positive"},
+		{body[1], "file Dockerfile exists", ""},
+		{body[2].(*ast.ConditionalStatement).Body[0], "env CI_TOKEN exists", "CI_TOKEN is required"},
+	}
+	for _, tt := range tests {
+		assert, ok := tt.stmt.(*ast.AssertStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.AssertStatement, got %T", tt.stmt)
+		}
+		if assert.Condition != tt.condition || assert.Message != tt.message {
+			t.Errorf("assert = (%q, %q), want (%q, %q)", assert.Condition, assert.Message, tt.condition, tt.message)
+		}
+	}
+
+	if got := body[0].String(); got != `assert {replicas} > 0 otherwise "replicas must be positive"` {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParser_AssertRequiresConditionAndMessage(t *testing.T) {
+	for _, line := range []string{
+		`assert file "Dockerfile" exists otherwise`,
+		`assert otherwise "no condition"`,
+	} {
+		input := "version: 2.0\n\ntask \"deploy\":\n  " + line + "\n  info \"deploying\""
+
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", line)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_CompareStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ast.CompareStatement
+	}{
+		{`compare file "expected.json" with "actual.json"`, ast.CompareStatement{Kind: "file", Left: "expected.json", Right: "actual.json"}},
+		{`compare dir "golden" with "out" ignoring "*.log", "tmp"`, ast.CompareStatement{Kind: "dir", Left: "golden", Right: "out", Ignore: []string{"*.log", "tmp"}}},
+		{`compare directory "a" with "{$target}"`, ast.CompareStatement{Kind: "dir", Left: "a", Right: "{$target}"}},
+	}
+
+	for _, tt := range tests {
+		input := "version: 2.0\n\ntask \"verify\":\n  " + tt.input + "\n  if true:\n    " + tt.input + "\n"
+		p := NewParser(lexer.NewLexer(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Tasks[0].Body[0].(*ast.CompareStatement)
+		if !ok {
+			t.Fatalf("%s: expected CompareStatement, got %T", tt.input, program.Tasks[0].Body[0])
+		}
+		if stmt.Kind != tt.expected.Kind || stmt.Left != tt.expected.Left || stmt.Right != tt.expected.Right || !reflect.DeepEqual(stmt.Ignore, tt.expected.Ignore) {
+			t.Errorf("%s: got %+v", tt.input, stmt)
+		}
+		if tt.expected.Kind == "file" && stmt.String() != tt.input {
+			t.Errorf("String() = %q, want %q", stmt.String(), tt.input)
+		}
+		ifStmt, ok := program.Tasks[0].Body[1].(*ast.ConditionalStatement)
+		if !ok || len(ifStmt.Body) != 1 {
+			t.Fatalf("%s: expected the comparison inside the if block, got %T", tt.input, program.Tasks[0].Body[1])
+		}
+		if _, ok := ifStmt.Body[0].(*ast.CompareStatement); !ok {
+			t.Errorf("%s: expected CompareStatement in the if block, got %T", tt.input, ifStmt.Body[0])
+		}
+	}
+}
+
+func TestParser_CompareStatementErrors(t *testing.T) {
+	for _, input := range []string{
+		`compare file "a.json" with "b.json" ignoring "*.log"`,
+		`compare dir "a" "b"`,
+		`compare dir "a" with "b" ignoring`,
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"verify\":\n  " + input + "\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_ConfirmStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ast.ConfirmStatement
+		str      string
+	}{
+		{`confirm "Delete prod database?"`, ast.ConfirmStatement{Question: "Delete prod database?"}, ""},
+		{`confirm "Delete prod database?" within 30s defaulting to no`, ast.ConfirmStatement{Question: "Delete prod database?", Timeout: "30s", Default: "no"}, ""},
+		{`confirm "Restart {$service}?" within 2 minutes defaulting to yes`, ast.ConfirmStatement{Question: "Restart {$service}?", Timeout: "2m", Default: "yes"}, `confirm "Restart {$service}?" within 2m defaulting to yes`},
+		{`confirm "Wipe cache?" defaulting to yes`, ast.ConfirmStatement{Question: "Wipe cache?", Default: "yes"}, ""},
+	}
+
+	for _, tt := range tests {
+		input := "version: 2.0\n\ntask \"reset\":\n  " + tt.input + "\n  if true:\n    " + tt.input + "\n"
+		p := NewParser(lexer.NewLexer(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Tasks[0].Body[0].(*ast.ConfirmStatement)
+		if !ok {
+			t.Fatalf("%s: expected ConfirmStatement, got %T", tt.input, program.Tasks[0].Body[0])
+		}
+		stmt.Token = lexer.Token{}
+		if *stmt != tt.expected {
+			t.Errorf("%s: got %+v", tt.input, stmt)
+		}
+		want := tt.str
+		if want == "" {
+			want = tt.input
+		}
+		if stmt.String() != want {
+			t.Errorf("String() = %q, want %q", stmt.String(), want)
+		}
+		ifStmt, ok := program.Tasks[0].Body[1].(*ast.ConditionalStatement)
+		if !ok || len(ifStmt.Body) != 1 {
+			t.Fatalf("%s: expected the statement inside the if block, got %T", tt.input, program.Tasks[0].Body[1])
+		}
+		if _, ok := ifStmt.Body[0].(*ast.ConfirmStatement); !ok {
+			t.Errorf("%s: expected ConfirmStatement in the if block, got %T", tt.input, ifStmt.Body[0])
+		}
+	}
+}
+
+func TestParser_ConfirmStatementErrors(t *testing.T) {
+	for _, input := range []string{
+		`confirm "Delete prod database?" within 30s`,
+		`confirm "Delete prod database?" within 30s defaulting to maybe`,
+		`confirm "Delete prod database?" defaulting no`,
+		`confirm "Delete prod database?" within 30 fortnights defaulting to no`,
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"reset\":\n  " + input + "\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %s", input)
+		}
+	}
+}
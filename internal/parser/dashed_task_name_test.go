@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -113,3 +114,41 @@ task "caller":
 		t.Fatalf("expected iterations parameter to be %q, got %q", "100", got)
 	}
 }
+
+func TestParseTaskCallOutputControl(t *testing.T) {
+	input := `version: 2.0
+
+task "caller":
+  call task "lint" silently
+  call task build with target="linux" capturing output as $log
+  info "{$log}"
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(body))
+	}
+	silent, ok := body[0].(*ast.TaskCallStatement)
+	if !ok || !silent.Silent || silent.CaptureVar != "" {
+		t.Fatalf("expected a silent call, got %#v", body[0])
+	}
+	capturing, ok := body[1].(*ast.TaskCallStatement)
+	if !ok || capturing.CaptureVar != "log" || capturing.Parameters["target"] != "linux" {
+		t.Fatalf("expected a call capturing $log, got %#v", body[1])
+	}
+	if got := capturing.String(); got != `call task "build" with target="linux" capturing output as $log` {
+		t.Errorf("String() = %q", got)
+	}
+
+	p = NewParser(lexer.NewLexer("version: 2.0\n\ntask \"caller\":\n  call task \"lint\" capturing as $log\n"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.Contains(strings.Join(p.Errors(), "\n"), "capturing output as $variable") {
+		t.Fatalf("expected an error for a call capturing without 'output', got %v", p.Errors())
+	}
+}
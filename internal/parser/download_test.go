@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -261,6 +262,41 @@ task "download_extract_remove":
 	}
 }
 
+func TestParser_DownloadExtractionOptions(t *testing.T) {
+	input := `version: 2.0
+
+task "install":
+  download "https://example.com/tool-1.2.0.tar.gz" to "tool.tar.gz" extract to "bin/" strip 1 component only "bin/*", "LICENSE" allow permissions ["execute"] to ["user"] remove archive`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	downloadStmt, ok := program.Tasks[0].Body[0].(*ast.DownloadStatement)
+	if !ok {
+		t.Fatalf("first statement should be DownloadStatement. got=%T", program.Tasks[0].Body[0])
+	}
+	if downloadStmt.StripComponents != 1 || len(downloadStmt.Only) != 2 || downloadStmt.Only[1] != "LICENSE" || !downloadStmt.RemoveArchive {
+		t.Errorf("unexpected options: strip %d, only %q, remove archive %t", downloadStmt.StripComponents, downloadStmt.Only, downloadStmt.RemoveArchive)
+	}
+	if got := downloadStmt.String(); !strings.Contains(got, `extract to "bin/" strip 1 component only "bin/*", "LICENSE" remove archive`) {
+		t.Errorf("String() = %s", got)
+	}
+
+	for line, want := range map[string]string{
+		`download "https://example.com/a.tgz" to "a.tgz" strip 1 component`:                   "'strip' requires 'extract to'",
+		`download "https://example.com/a.tgz" to "a.tgz" extract to "a/" strip 0 components`:  "invalid component count",
+		`download "https://example.com/a.tgz" to "a.tgz" extract to "a/" strip 2 directories`: "expected 'components' after 'strip 2'",
+		`download "https://example.com/a.tgz" to "a.tgz" extract to "a/" only "bin/[a"`:       "invalid pattern",
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"install\":\n  " + line + "\n"))
+		p.ParseProgram()
+		if !strings.Contains(strings.Join(p.Errors(), "\n"), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", line, want, p.Errors())
+		}
+	}
+}
+
 func TestParser_DownloadCompleteFeatures(t *testing.T) {
 	input := `version: 2.0
 
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_TestStatement(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  requires $environment
+  call task "build" with target="web"
+  info "deploying"
+
+test "deploy to staging" in dry run:
+  # The dry run shows the commands deploy would run
+  call task "deploy" with environment="staging"
+  expect output to match golden "tests/deploy.golden"
+
+test "build":
+  call task build
+  expect output to match golden "tests/build.golden"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Tasks) != 1 || len(program.Tasks[0].Body) != 2 {
+		t.Fatalf("expected the task call to end at its line, got %d tasks", len(program.Tasks))
+	}
+	if len(program.Tests) != 2 {
+		t.Fatalf("expected 2 tests, got %d", len(program.Tests))
+	}
+
+	test := program.Tests[0]
+	if test.Name != "deploy to staging" || !test.DryRun || test.Golden != "tests/deploy.golden" {
+		t.Errorf("unexpected test: %+v", test)
+	}
+	if test.Call == nil || test.Call.TaskName != "deploy" || test.Call.Parameters["environment"] != "staging" {
+		t.Errorf("unexpected call: %+v", test.Call)
+	}
+	want := "test \"deploy to staging\" in dry run:\n  call task \"deploy\" with environment=\"staging\"\n  expect output to match golden \"tests/deploy.golden\"\n"
+	if test.String() != want {
+		t.Errorf("String() = %q, want %q", test.String(), want)
+	}
+
+	if program.Tests[1].DryRun || program.Tests[1].Call.TaskName != "build" {
+		t.Errorf("unexpected test: %+v", program.Tests[1])
+	}
+}
+
+func TestParser_TestStatementErrors(t *testing.T) {
+	for _, body := range []string{
+		"test \"a\":\n  expect output to match golden \"a.golden\"\n",
+		"test \"a\":\n  call task \"build\"\n",
+		"test \"a\":\n  call task \"build\"\n  call task \"lint\"\n  expect output to match golden \"a.golden\"\n",
+		"test \"a\":\n  call task \"build\"\n  expect output to match \"a.golden\"\n",
+		"test \"a\" in dry:\n  call task \"build\"\n  expect output to match golden \"a.golden\"\n",
+		"test \"a\":\n  info \"hi\"\n",
+		"test \"a\":\n  call task \"build\"\n  expect output to match golden \"a.golden\"\n\ntest \"a\":\n  call task \"build\"\n  expect output to match golden \"b.golden\"\n",
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"build\":\n  info \"build\"\n\n" + body))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", body)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_TrailingConditionModifiers(t *testing.T) {
+	input := `version: 2.0
+
+task "lint":
+  run "npm run lint" only when file "package.json" exists
+  info "skipping" unless {verbose}
+  for each $pkg in $packages:
+    warn "legacy {$pkg}" only when {$pkg} starts with "old-"
+  info "done"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 4 {
+		t.Fatalf("Expected 4 statements in task body, got %d", len(body))
+	}
+
+	tests := []struct {
+		stmt      ast.Statement
+		condition string
+		negate    bool
+		str       string
+	}{
+		{body[0], "file package.json exists", false, `run "npm run lint" only when file package.json exists`},
+		{body[1], "{verbose}", true, `info "skipping" unless {verbose}`},
+		{body[2].(*ast.LoopStatement).Body[0], "{$pkg} starts with old-", false, `warn "legacy {$pkg}" only when {$pkg} starts with old-`},
+	}
+	for _, tt := range tests {
+		guarded, ok := tt.stmt.(*ast.GuardedStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.GuardedStatement, got %T", tt.stmt)
+		}
+		if guarded.Condition != tt.condition || guarded.Negate != tt.negate {
+			t.Errorf("guard = (%q, %t), want (%q, %t)", guarded.Condition, guarded.Negate, tt.condition, tt.negate)
+		}
+		if got := guarded.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+	}
+
+	if _, ok := body[3].(*ast.ActionStatement); !ok {
+		t.Errorf("Expected an unguarded *ast.ActionStatement, got %T", body[3])
+	}
+}
+
+func TestParser_TrailingConditionModifierRequiresCondition(t *testing.T) {
+	for _, line := range []string{
+		`info "a" unless`,
+		`info "a" only when`,
+		`info "a" only {x}`,
+	} {
+		input := "version: 2.0\n\ntask \"lint\":\n  " + line + "\n  info \"done\""
+
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", line)
+		}
+	}
+}
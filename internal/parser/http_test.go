@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -295,3 +296,141 @@ task "api_workflow":
 		}
 	}
 }
+
+func TestParser_HTTPStringKeepsDeclarationOrder(t *testing.T) {
+	input := `version: 2.0
+
+task "api":
+  post "https://api.example.com/data" with header "X-Zeta: 1" with header "X-Alpha: 2" with header "X-Mid: 3" content type json with auth bearer "tok" timeout "30s" retry "3"
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	httpStmt, ok := program.Tasks[0].Body[0].(*ast.HTTPStatement)
+	if !ok {
+		t.Fatalf("first statement should be HTTPStatement. got=%T", program.Tasks[0].Body[0])
+	}
+
+	want := `post request to "https://api.example.com/data"` +
+		` with header "X-Zeta: 1" with header "X-Alpha: 2" with header "X-Mid: 3" with header "Content-Type: application/json"` +
+		` with bearer "tok" timeout "30s" retry "3"`
+	for i := 0; i < 20; i++ {
+		if got := httpStmt.String(); got != want {
+			t.Fatalf("String() = %q\nwant %q", got, want)
+		}
+	}
+}
+
+func TestParser_HTTPExpectations(t *testing.T) {
+	input := `version: 2.0
+
+task "smoke":
+  get "https://api.example.com/health" expect response status in [200, 201] and header "Content-Type" contains "json" and body matches ".ok == true"
+  get "https://api.example.com/" expect status "2xx" and header "ETag" exists
+`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	httpStmt, ok := program.Tasks[0].Body[0].(*ast.HTTPStatement)
+	if !ok {
+		t.Fatalf("first statement should be HTTPStatement. got=%T", program.Tasks[0].Body[0])
+	}
+	want := []ast.HTTPExpectation{
+		{Part: "status", Operator: "in", Values: []string{"200", "201"}},
+		{Part: "header", Header: "Content-Type", Operator: "contains", Values: []string{"json"}},
+		{Part: "body", Operator: "matches", Values: []string{".ok == true"}},
+	}
+	if len(httpStmt.Expectations) != len(want) {
+		t.Fatalf("expected %d expectations, got %+v", len(want), httpStmt.Expectations)
+	}
+	for i := range want {
+		got := httpStmt.Expectations[i]
+		if got.Part != want[i].Part || got.Header != want[i].Header || got.Operator != want[i].Operator || strings.Join(got.Values, ",") != strings.Join(want[i].Values, ",") {
+			t.Errorf("expectation %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if got := httpStmt.String(); !strings.HasSuffix(got, ` expect response status in [200, 201] and header "Content-Type" contains "json" and body matches ".ok == true"`) {
+		t.Errorf("String() = %s", got)
+	}
+
+	second := program.Tasks[0].Body[1].(*ast.HTTPStatement)
+	if len(second.Expectations) != 2 || second.Expectations[0].Values[0] != "2xx" || second.Expectations[1].Operator != "exists" {
+		t.Errorf("unexpected expectations: %+v", second.Expectations)
+	}
+}
+
+func TestParser_HTTPExpectationErrors(t *testing.T) {
+	for line, want := range map[string]string{
+		`get "https://svc" expect response latency is 5`:      "expected status, header or body",
+		`get "https://svc" expect response status contains 2`: "expected is, in after 'status'",
+		`get "https://svc" expect response body exists`:       "expected is, contains, matches after 'body'",
+		`get "https://svc" expect response status is 700`:     "invalid status",
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"smoke\":\n  " + line + "\n"))
+		p.ParseProgram()
+		if !strings.Contains(strings.Join(p.Errors(), "\n"), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", line, want, p.Errors())
+		}
+	}
+}
+
+func TestParser_HTTPClients(t *testing.T) {
+	input := `version: 2.0
+
+project "deploys":
+  http client "api": base url "https://api.example.com", header "Authorization: Bearer {$token}", header "Accept: application/json", timeout 10s
+
+task "status":
+  get "/deployments" using client "api" capture body as $deployments
+  info "{$deployments}"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	var client *ast.HTTPClientStatement
+	for _, setting := range program.Project.Settings {
+		if c, ok := setting.(*ast.HTTPClientStatement); ok {
+			client = c
+		}
+	}
+	if client == nil {
+		t.Fatalf("expected an HTTP client in the project settings")
+	}
+	want := `http client "api": base url "https://api.example.com", header "Authorization: Bearer {$token}", header "Accept: application/json", timeout 10s`
+	if got := client.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	httpStmt, ok := program.Tasks[0].Body[0].(*ast.HTTPStatement)
+	if !ok {
+		t.Fatalf("expected an HTTPStatement, got %T", program.Tasks[0].Body[0])
+	}
+	if httpStmt.Client != "api" || httpStmt.URL != "/deployments" || len(httpStmt.Captures) != 1 {
+		t.Errorf("expected a GET of /deployments using client api, got %s", httpStmt.String())
+	}
+}
+
+func TestParser_HTTPClientErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`http client "api": base url "https://a", retries 3`, `unknown HTTP client option "retries"`},
+		{`http client "api":`, `HTTP client "api" has no options`},
+		{"http client \"api\": timeout 5s\n  http client \"api\": timeout 6s", `HTTP client "api" is declared more than once`},
+	}
+	for _, tt := range tests {
+		input := "version: 2.0\n\nproject \"p\":\n  " + tt.input + "\n\ntask \"t\":\n  info \"hi\"\n"
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+		if errs := strings.Join(p.Errors(), "\n"); !strings.Contains(errs, tt.want) {
+			t.Errorf("%s: expected error %q, got:\n%s", tt.input, tt.want, errs)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_LockBlocks(t *testing.T) {
+	input := `version: 2.0
+
+project "shop":
+  set lock backend to "redis://locks.internal:6379"
+
+task "deploy":
+  lock "deploy-{$environment}":
+    run "make deploy"
+  if $ci is "true":
+    lock "migrations" using "dynamodb://drun-locks?region=eu-west-1" waiting up to 10 minutes:
+      run "make migrate"
+  info "done"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	var backend string
+	for _, setting := range program.Project.Settings {
+		if set, ok := setting.(*ast.SetStatement); ok && set.Key == "lock_backend" {
+			backend = set.Value.String()
+		}
+	}
+	if !strings.Contains(backend, "redis://locks.internal:6379") {
+		t.Errorf("lock_backend setting = %q", backend)
+	}
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements in task body, got %d", len(body))
+	}
+	tests := []struct {
+		stmt                ast.Statement
+		name, backend, wait string
+	}{
+		{body[0], "deploy-{$environment}", "", ""},
+		{body[1].(*ast.ConditionalStatement).Body[0], "migrations", "dynamodb://drun-locks?region=eu-west-1", "10m"},
+	}
+	for _, tt := range tests {
+		lock, ok := tt.stmt.(*ast.LockStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.LockStatement, got %T", tt.stmt)
+		}
+		if lock.Name != tt.name || lock.Backend != tt.backend || lock.Wait != tt.wait {
+			t.Errorf("lock = (%q, %q, %q), want (%q, %q, %q)", lock.Name, lock.Backend, lock.Wait, tt.name, tt.backend, tt.wait)
+		}
+		if len(lock.Body) != 1 {
+			t.Errorf("Expected 1 statement in the lock block, got %d", len(lock.Body))
+		}
+	}
+
+	if got := body[0].String(); got != "lock \"deploy-{$environment}\":\n  run \"make deploy\"\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParser_LockRejectsInvalidBlocks(t *testing.T) {
+	for line, want := range map[string]string{
+		`lock "deploy" waiting up to 10 parsecs:`: "unknown time unit",
+		`lock "deploy" waiting 10 minutes:`:       "expected next token to be UP",
+		`lock "":`:                                "lock name cannot be empty",
+	} {
+		input := "version: 2.0\n\ntask \"deploy\":\n  " + line + "\n    run \"make deploy\"\n"
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+		if !strings.Contains(strings.Join(p.Errors(), "\n"), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", line, want, p.Errors())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_NotifyMeWhenDone(t *testing.T) {
+	input := `version: 2.0
+
+task "release":
+  notify me when done
+  if $ci is "false":
+    notify me when done
+  info "releasing"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements in task body, got %d", len(body))
+	}
+	notify, ok := body[0].(*ast.NotifyStatement)
+	if !ok {
+		t.Fatalf("Expected *ast.NotifyStatement, got %T", body[0])
+	}
+	if got := notify.String(); got != "notify me when done" {
+		t.Errorf("String() = %q", got)
+	}
+
+	conditional, ok := body[1].(*ast.ConditionalStatement)
+	if !ok || len(conditional.Body) != 1 {
+		t.Fatalf("Expected a conditional with one statement, got %T", body[1])
+	}
+	if _, ok := conditional.Body[0].(*ast.NotifyStatement); !ok {
+		t.Errorf("Expected *ast.NotifyStatement in the if block, got %T", conditional.Body[0])
+	}
+}
+
+func TestParser_NotifyRequiresWhenDone(t *testing.T) {
+	input := `version: 2.0
+
+task "release":
+  notify me when finished`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	p.ParseProgram()
+
+	errs := p.Errors()
+	if len(errs) == 0 {
+		t.Fatal("Expected a parse error")
+	}
+	if !strings.Contains(errs[0], "expected 'notify me when done'") {
+		t.Errorf("Unexpected error: %s", errs[0])
+	}
+}
@@ -18,6 +18,7 @@ type Parser struct {
 	errors             []string // Legacy error list for backward compatibility
 	errorList          *errors.ParseErrorList
 	pendingAnnotations []ast.Annotation
+	taskParameters     map[string]bool // parameters of the task being parsed, read-only unless shadowed
 }
 
 // New creates a new parser instance
@@ -79,7 +80,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.skipComments()
 	}
 
-	// Parse task, template, service, and orchestration statements
+	// Parse task, template, service, orchestration and test statements
 	for p.curToken.Type != lexer.EOF {
 		switch p.curToken.Type {
 		case lexer.DECORATOR:
@@ -128,10 +129,39 @@ func (p *Parser) ParseProgram() *ast.Program {
 				// Error recovery: skip to next statement or EOF
 				p.synchronize()
 			}
+		case lexer.TEST:
+			if len(p.pendingAnnotations) > 0 {
+				p.addError("annotation(s) must be followed by task, template task, or snippet")
+				p.pendingAnnotations = nil
+			}
+			test := p.parseTestStatement()
+			if test == nil {
+				// Error recovery: skip to next statement or EOF
+				p.synchronize()
+				break
+			}
+			for _, existing := range program.Tests {
+				if existing.Name == test.Name {
+					p.addError(fmt.Sprintf("test %q is declared more than once", test.Name))
+					break
+				}
+			}
+			program.Tests = append(program.Tests, test)
 		case lexer.TASK:
 			task := p.parseTaskStatement()
 			if task != nil {
 				task.Annotations = append(task.Annotations, p.consumePendingAnnotations()...)
+				if task.Default {
+					for _, existing := range program.Tasks {
+						if existing.Default {
+							p.addErrorWithHelp(
+								fmt.Sprintf("task %q is marked default, but %q is already the default task", task.Name, existing.Name),
+								"Only one task can be marked 'default'",
+							)
+							break
+						}
+					}
+				}
 				program.Tasks = append(program.Tasks, task)
 			} else {
 				// Error recovery: skip to next task or EOF
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -22,8 +23,15 @@ func (p *Parser) parseActionStatement() *ast.ActionStatement {
 
 	stmt.Message = p.curToken.Literal
 
-	// Check for optional "add line break before/after" for step actions
+	// Check for optional "with style "banner" width 80" and
+	// "add line break before/after" for step actions
 	if stmt.Action == "step" {
+		if p.peekToken.Type == lexer.WITH {
+			p.nextToken() // consume WITH
+			if !p.parseStepFormat(stmt) {
+				return nil
+			}
+		}
 		for {
 			if p.peekToken.Type == lexer.ADD {
 				p.nextToken() // consume ADD
@@ -50,7 +58,53 @@ func (p *Parser) parseActionStatement() *ast.ActionStatement {
 	return stmt
 }
 
-// parseTaskCallStatement parses a task call statement (call task "name" with param="value")
+// parseStepFormat parses the options after "step "..." with":
+// style "box|banner|underline|minimal" and/or width N, in any order
+func (p *Parser) parseStepFormat(stmt *ast.ActionStatement) bool {
+	if !p.peekIsStepFormatOption() {
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected 'style' or 'width' after 'with', got %s instead", p.peekToken.Literal),
+			"Example: step \"Deploying\" with style \"banner\" width 80",
+		)
+		return false
+	}
+
+	for p.peekIsStepFormatOption() {
+		p.nextToken() // consume style/width
+		if p.curToken.Literal == "style" {
+			if !p.expectPeek(lexer.STRING) {
+				return false
+			}
+			stmt.StepStyle = p.curToken.Literal
+			continue
+		}
+
+		if !p.expectPeek(lexer.NUMBER) {
+			return false
+		}
+		width, err := strconv.Atoi(p.curToken.Literal)
+		if err != nil || width <= 0 {
+			p.addError(fmt.Sprintf("step width must be a positive whole number, got %s", p.curToken.Literal))
+			return false
+		}
+		stmt.StepWidth = width
+	}
+	return true
+}
+
+// peekIsStepFormatOption reports whether the next token is a step format option
+func (p *Parser) peekIsStepFormatOption() bool {
+	return p.peekToken.Type == lexer.IDENT && (p.peekToken.Literal == "style" || p.peekToken.Literal == "width")
+}
+
+// peekIsCallOutputControl reports whether the next token starts a call's
+// output control (silently, capturing output as $var)
+func (p *Parser) peekIsCallOutputControl() bool {
+	return p.peekToken.Type == lexer.IDENT && (p.peekToken.Literal == "silently" || p.peekToken.Literal == "capturing")
+}
+
+// parseTaskCallStatement parses a task call statement
+// (call task "name" with param="value" [silently | capturing output as $var])
 func (p *Parser) parseTaskCallStatement() *ast.TaskCallStatement {
 	stmt := &ast.TaskCallStatement{
 		Token:      p.curToken,
@@ -91,8 +145,9 @@ func (p *Parser) parseTaskCallStatement() *ast.TaskCallStatement {
 
 		// Parse parameters - continue while we see parameter names (IDENT or keywords)
 		// We allow both IDENT and keywords as parameter names
-		// Stop when we hit tokens that indicate end of parameters
+		// Stop at the end of the line or when we hit tokens that indicate end of parameters
 		for (p.peekToken.Type == lexer.IDENT || p.isKeywordToken(p.peekToken.Type)) &&
+			p.peekToken.Line == p.curToken.Line && !p.peekIsCallOutputControl() &&
 			p.peekToken.Type != lexer.NEWLINE && p.peekToken.Type != lexer.COMMENT &&
 			p.peekToken.Type != lexer.DEDENT && p.peekToken.Type != lexer.EOF {
 
@@ -115,5 +170,22 @@ func (p *Parser) parseTaskCallStatement() *ast.TaskCallStatement {
 		}
 	}
 
+	// Optional output control: silently, or capturing output as $var
+	if p.peekToken.Line == p.curToken.Line && p.peekIsCallOutputControl() {
+		switch p.peekToken.Literal {
+		case "silently":
+			p.nextToken()
+			stmt.Silent = true
+		case "capturing":
+			p.nextToken()
+			if !p.expectPeek(lexer.OUTPUT) || !p.expectPeek(lexer.AS) || !p.expectPeekVariableName() {
+				p.addErrorWithHelp("expected 'capturing output as $variable'",
+					`Use: call task "build" capturing output as $log`)
+				return nil
+			}
+			stmt.CaptureVar = p.getVariableName()
+		}
+	}
+
 	return stmt
 }
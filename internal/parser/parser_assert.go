@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isAssertStatementStart reports whether the current token starts an
+// assert statement
+func (p *Parser) isAssertStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "assert" && p.peekToken.Line == p.curToken.Line
+}
+
+// parseAssertStatement parses an assertion; the condition runs to
+// 'otherwise' or the end of the line
+// Syntax: assert <condition> [otherwise "message"]
+func (p *Parser) parseAssertStatement() *ast.AssertStatement {
+	stmt := &ast.AssertStatement{Token: p.curToken}
+
+	stmt.Condition = p.collectLineCondition(lexer.OTHERWISE)
+	if stmt.Condition == "" {
+		p.addError("expected a condition after 'assert'")
+		return nil
+	}
+
+	if p.peekToken.Type == lexer.OTHERWISE {
+		p.nextToken() // consume OTHERWISE
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Message = p.curToken.Literal
+	}
+	return stmt
+}
+
+// collectLineCondition joins the tokens after the current one into a
+// condition, up to the end of the line or a token of type stop
+func (p *Parser) collectLineCondition(stop lexer.TokenType) string {
+	line := p.curToken.Line
+	var builder strings.Builder
+	prevLiteral := ""
+	for p.peekToken.Line == line && p.peekToken.Type != stop && p.peekToken.Type != lexer.DEDENT && p.peekToken.Type != lexer.EOF {
+		p.nextToken()
+		currentLiteral := p.curToken.Literal
+		if builder.Len() > 0 && shouldInsertConditionSpace(prevLiteral, currentLiteral) {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(currentLiteral)
+		prevLiteral = currentLiteral
+	}
+	return builder.String()
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isCompareStatementStart reports whether the current token starts a file or
+// directory comparison
+func (p *Parser) isCompareStatementStart() bool {
+	if p.curToken.Type != lexer.IDENT || p.curToken.Literal != "compare" {
+		return false
+	}
+	switch p.peekToken.Type {
+	case lexer.FILE, lexer.DIR, lexer.DIRECTORY:
+		return true
+	}
+	return false
+}
+
+// parseCompareStatement parses a file or directory comparison; ignoring is
+// only accepted for directories
+// Syntax: compare file|dir "left" with "right" [ignoring "pattern", ...]
+func (p *Parser) parseCompareStatement() *ast.CompareStatement {
+	stmt := &ast.CompareStatement{Token: p.curToken, Kind: "file"}
+	p.nextToken() // consume "compare"
+	if p.curToken.Type != lexer.FILE {
+		stmt.Kind = "dir"
+	}
+
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Left = p.curToken.Literal
+	if !p.expectPeek(lexer.WITH) || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Right = p.curToken.Literal
+
+	if p.peekToken.Type != lexer.IDENT || p.peekToken.Literal != "ignoring" {
+		return stmt
+	}
+	p.nextToken() // consume "ignoring"
+	if stmt.Kind == "file" {
+		p.addErrorWithHelp("'ignoring' only applies to directory comparisons",
+			`Use: compare dir "expected" with "actual" ignoring "*.log"`)
+		return nil
+	}
+	for {
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Ignore = append(stmt.Ignore, p.curToken.Literal)
+		if p.peekToken.Type != lexer.COMMA {
+			return stmt
+		}
+		p.nextToken() // consume ","
+	}
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isConfirmStatementStart reports whether the current token starts a
+// confirmation prompt
+func (p *Parser) isConfirmStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "confirm" &&
+		p.peekToken.Type == lexer.STRING
+}
+
+// parseConfirmStatement parses a confirmation prompt; a timeout needs a
+// default to take when it expires
+// Syntax: confirm "question" [within <duration>] [defaulting to yes|no]
+func (p *Parser) parseConfirmStatement() *ast.ConfirmStatement {
+	stmt := &ast.ConfirmStatement{Token: p.curToken}
+	p.nextToken() // consume "confirm"
+	stmt.Question = p.curToken.Literal
+
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "within" {
+		p.nextToken() // consume "within"
+		stmt.Timeout = p.parsePollDuration("within")
+		if stmt.Timeout == "" {
+			return nil
+		}
+	}
+
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "defaulting" {
+		p.nextToken() // consume "defaulting"
+		if !p.expectPeek(lexer.TO) {
+			return nil
+		}
+		p.nextToken()
+		switch {
+		case p.curToken.Type == lexer.NO:
+			stmt.Default = "no"
+		case p.curToken.Type == lexer.IDENT && p.curToken.Literal == "yes":
+			stmt.Default = "yes"
+		default:
+			p.addErrorWithHelp("expected yes or no after 'defaulting to', got "+p.curToken.Literal,
+				`Use: confirm "Delete prod database?" within 30s defaulting to no`)
+			return nil
+		}
+	}
+
+	if stmt.Timeout != "" && stmt.Default == "" {
+		p.addErrorWithHelp("a confirmation with a timeout needs a default to take when it expires",
+			`Use: confirm "Delete prod database?" within 30s defaulting to no`)
+		return nil
+	}
+	return stmt
+}
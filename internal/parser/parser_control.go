@@ -2,6 +2,10 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -15,6 +19,10 @@ func (p *Parser) parseControlFlowStatement() ast.Statement {
 		return p.parseIfStatement()
 	case lexer.FOR:
 		return p.parseForStatement()
+	case lexer.WHILE:
+		return p.parseWhileStatement()
+	case lexer.POLL:
+		return p.parsePollStatement()
 	default:
 		p.addError(fmt.Sprintf("unexpected control flow token: %s", p.curToken.Type))
 		return nil
@@ -100,6 +108,182 @@ func (p *Parser) parseIfStatement() *ast.ConditionalStatement {
 	return stmt
 }
 
+// whileLimitPattern matches the optional "at most N times" iteration limit
+// at the end of a while condition
+var whileLimitPattern = regexp.MustCompile(`^(.*?)\s+at most (\d+) times$`)
+
+// parseWhileStatement parses while loops: while condition [at most N times]: ...
+func (p *Parser) parseWhileStatement() *ast.LoopStatement {
+	stmt := &ast.LoopStatement{
+		Token: p.curToken,
+		Type:  "while",
+	}
+
+	condition := p.parseConditionExpression()
+	if match := whileLimitPattern.FindStringSubmatch(condition); match != nil {
+		limit, err := strconv.Atoi(match[2])
+		if err != nil || limit < 1 {
+			p.addError(fmt.Sprintf("invalid while loop limit: %s", match[2]))
+			return nil
+		}
+		condition = match[1]
+		stmt.MaxIterations = limit
+	}
+	if condition == "" {
+		p.addError("expected a condition after 'while'")
+		return nil
+	}
+	stmt.Condition = condition
+
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+
+	stmt.Body = p.parseControlFlowBody()
+
+	return stmt
+}
+
+// parsePollStatement parses poll blocks, which are followed by the condition
+// that ends them: poll every 10s up to 5m: ... until condition
+func (p *Parser) parsePollStatement() *ast.LoopStatement {
+	stmt := &ast.LoopStatement{
+		Token: p.curToken,
+		Type:  "poll",
+	}
+
+	if p.peekToken.Type != lexer.IDENT || p.peekToken.Literal != "every" {
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected 'every' after 'poll', got %s instead", p.peekToken.Literal),
+			"Use: poll every 10s up to 5m:",
+		)
+		return nil
+	}
+	p.nextToken() // consume "every"
+	stmt.PollInterval = p.parsePollDuration("every")
+	if stmt.PollInterval == "" {
+		return nil
+	}
+
+	if !p.expectPeek(lexer.UP) || !p.expectPeek(lexer.TO) {
+		p.addError("poll blocks need a time limit: poll every 10s up to 5m:")
+		return nil
+	}
+	stmt.PollTimeout = p.parsePollDuration("up to")
+	if stmt.PollTimeout == "" {
+		return nil
+	}
+
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+
+	stmt.Body = p.parseControlFlowBody()
+
+	if p.peekToken.Type != lexer.UNTIL {
+		p.addErrorWithHelpAtPeek("poll block has no until condition", `Follow the block with a line like: until {$code} is "200"`)
+		return nil
+	}
+	p.nextToken() // consume UNTIL
+	stmt.Condition = p.parseUntilCondition()
+	if stmt.Condition == "" {
+		p.addError("expected a condition after 'until'")
+		return nil
+	}
+
+	return stmt
+}
+
+// parsePollDuration parses a duration written as 10s, 2 minutes or "1m30s"
+// and returns it in Go duration syntax
+func (p *Parser) parsePollDuration(after string) string {
+	var text string
+	switch p.peekToken.Type {
+	case lexer.STRING:
+		p.nextToken()
+		text = p.curToken.Literal
+	case lexer.NUMBER:
+		p.nextToken()
+		text = p.curToken.Literal
+		if p.peekToken.Type == lexer.IDENT {
+			p.nextToken()
+			text += pollDurationUnits[p.curToken.Literal]
+			if pollDurationUnits[p.curToken.Literal] == "" {
+				p.addError(fmt.Sprintf("unknown time unit %q after '%s'", p.curToken.Literal, after))
+				return ""
+			}
+		}
+	default:
+		p.addError(fmt.Sprintf("expected a duration after '%s', got %s", after, p.peekToken.Type))
+		return ""
+	}
+
+	if d, err := time.ParseDuration(text); err != nil || d <= 0 {
+		p.addError(fmt.Sprintf("invalid duration %q after '%s'", text, after))
+		return ""
+	}
+	return text
+}
+
+// pollDurationUnits maps the time units accepted after a number to Go's
+var pollDurationUnits = map[string]string{
+	"ms": "ms", "s": "s", "m": "m", "h": "h",
+	"second": "s", "seconds": "s", "minute": "m", "minutes": "m", "hour": "h", "hours": "h",
+}
+
+// parseUntilCondition parses the condition after until; it runs to the end
+// of the line
+func (p *Parser) parseUntilCondition() string {
+	line := p.curToken.Line
+	var builder strings.Builder
+	prevLiteral := ""
+	for p.peekToken.Line == line && p.peekToken.Type != lexer.DEDENT && p.peekToken.Type != lexer.EOF {
+		p.nextToken()
+		currentLiteral := p.curToken.Literal
+		if builder.Len() > 0 && shouldInsertConditionSpace(prevLiteral, currentLiteral) {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(currentLiteral)
+		prevLiteral = currentLiteral
+	}
+	return builder.String()
+}
+
+// parseChunkLoopHeader parses the header of a loop over batches of files;
+// the loop variable defaults to $chunk
+// Syntax: for each chunk [$variable] of <size> files in "<glob>"
+func (p *Parser) parseChunkLoopHeader(stmt *ast.LoopStatement) bool {
+	p.nextToken() // consume "chunk"
+	stmt.Type = "chunk"
+	stmt.Variable = "$chunk"
+	if p.peekToken.Type == lexer.VARIABLE {
+		p.nextToken()
+		stmt.Variable = p.curToken.Literal
+	}
+
+	if !p.expectPeek(lexer.OF) || !p.expectPeek(lexer.NUMBER) {
+		return false
+	}
+	size, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil || size <= 0 {
+		p.addError(fmt.Sprintf("chunk size must be a positive whole number, got %s", p.curToken.Literal))
+		return false
+	}
+	stmt.ChunkSize = size
+
+	if p.peekToken.Type == lexer.FILES {
+		p.nextToken()
+	} else if !p.expectPeekFileKeyword() {
+		return false
+	}
+
+	if !p.expectPeek(lexer.IN) || !p.expectPeek(lexer.STRING) {
+		return false
+	}
+	stmt.Iterable = p.curToken.Literal
+	return true
+}
+
 // parseForStatement parses for loops (each, range, line, match)
 func (p *Parser) parseForStatement() *ast.LoopStatement {
 	stmt := &ast.LoopStatement{
@@ -133,7 +317,8 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 		p.nextToken() // consume LINE
 		stmt.Type = "line"
 
-		if !p.expectPeek(lexer.IDENT) {
+		// The line variable may be written with or without its $ prefix
+		if !p.expectPeekOneOf(lexer.IDENT, lexer.VARIABLE) {
 			return nil
 		}
 		stmt.Variable = p.curToken.Literal
@@ -173,6 +358,15 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 		}
 		stmt.Iterable = p.curToken.Literal
 
+	case lexer.IDENT:
+		if p.peekToken.Literal != "chunk" {
+			p.addError("expected variable (with $ prefix) after 'each'")
+			return nil
+		}
+		if !p.parseChunkLoopHeader(stmt) {
+			return nil
+		}
+
 	default:
 		// Regular "for each $variable in $iterable"
 		if !p.expectPeek(lexer.VARIABLE) {
@@ -185,8 +379,19 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 			return nil
 		}
 
-		// Accept VARIABLE and array literals for iterable
+		// Accept VARIABLE, array literals and paginated HTTP requests for iterable
 		switch p.peekToken.Type {
+		case lexer.GET:
+			p.nextToken() // consume GET
+			stmt.Type = "http"
+			if !p.expectPeek(lexer.STRING) {
+				return nil
+			}
+			stmt.Iterable = p.curToken.Literal
+			stmt.Pagination = p.parsePaginationClause()
+			if stmt.Pagination == nil {
+				return nil
+			}
 		case lexer.VARIABLE:
 			p.nextToken()
 			stmt.Iterable = p.curToken.Literal
@@ -199,6 +404,18 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 			} else {
 				return nil
 			}
+		case lexer.IDENT:
+			// lines of stdin: the content piped into drun
+			if p.peekToken.Literal != "lines" {
+				p.addError(fmt.Sprintf("expected variable (with $ prefix), array literal or 'lines of stdin' for iterable, got %s", p.peekToken.Literal))
+				return nil
+			}
+			p.nextToken() // consume 'lines'
+			if !p.expectPeek(lexer.OF) || !p.expectPeekLiteral("stdin") {
+				return nil
+			}
+			stmt.Type = "stdin"
+			stmt.Iterable = "stdin"
 		default:
 			p.addError(fmt.Sprintf("expected variable (with $ prefix) or array literal for iterable, got %s", p.peekToken.Type))
 			return nil
@@ -207,6 +424,10 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 
 	// Check for filter: "where variable operator value"
 	if p.peekToken.Type == lexer.WHERE {
+		if stmt.Type == "chunk" {
+			p.addError("chunked loops cannot be filtered with 'where': narrow the glob pattern instead")
+			return nil
+		}
 		stmt.Filter = p.parseFilterExpression()
 	}
 
@@ -219,6 +440,11 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 		}
 	}
 
+	if stmt.Type == "http" && stmt.Parallel {
+		p.addError("loops over a paginated API cannot run in parallel: each page is only known after the previous one")
+		return nil
+	}
+
 	if !p.expectPeek(lexer.COLON) {
 		return nil
 	}
@@ -229,6 +455,61 @@ func (p *Parser) parseForEachStatement(stmt *ast.LoopStatement) *ast.LoopStateme
 	return stmt
 }
 
+// parsePaginationClause parses the pagination of an HTTP loop:
+// paginated by header "Link" | cursor "next" [param "cursor"] | query "page",
+// followed by an optional items at "field"
+func (p *Parser) parsePaginationClause() *ast.PaginationClause {
+	for _, word := range []string{"paginated", "by"} {
+		if p.peekToken.Type != lexer.IDENT || p.peekToken.Literal != word {
+			p.addErrorWithHelpAtPeek(
+				fmt.Sprintf("expected '%s' after the URL of a for each loop, got %s instead", word, p.peekToken.Literal),
+				`Use: for each $item in get "https://api.example.com/items" paginated by header "Link":`,
+			)
+			return nil
+		}
+		p.nextToken()
+	}
+
+	clause := &ast.PaginationClause{}
+	switch {
+	case p.peekToken.Type == lexer.HEADER:
+		clause.Strategy = "header"
+	case p.peekToken.Type == lexer.IDENT && (p.peekToken.Literal == "cursor" || p.peekToken.Literal == "query"):
+		clause.Strategy = p.peekToken.Literal
+	default:
+		p.addError(fmt.Sprintf("expected header, cursor or query after 'paginated by', got %s", p.peekToken.Literal))
+		return nil
+	}
+	p.nextToken()
+
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	clause.Name = p.curToken.Literal
+	if clause.Name == "" {
+		p.addError(fmt.Sprintf("'paginated by %s' needs a name", clause.Strategy))
+		return nil
+	}
+
+	if clause.Strategy == "cursor" && p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "param" {
+		p.nextToken() // consume "param"
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		clause.Param = p.curToken.Literal
+	}
+
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "items" {
+		p.nextToken() // consume "items"
+		if !p.expectPeek(lexer.AT) || !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		clause.ItemsPath = p.curToken.Literal
+	}
+
+	return clause
+}
+
 // parseForVariableStatement parses "for $variable in range" or "for $variable in iterable"
 func (p *Parser) parseForVariableStatement(stmt *ast.LoopStatement) *ast.LoopStatement {
 	// Accept variables with or without $ prefix (allow keywords used as identifiers)
@@ -439,11 +720,59 @@ func (p *Parser) parseControlFlowBody() []ast.Statement {
 	for p.peekToken.Type != lexer.DEDENT && p.peekToken.Type != lexer.EOF {
 		p.nextToken()
 
+		bodyLen := len(body)
 		if p.isDetectionToken(p.curToken.Type) && p.isDetectionContext() {
 			detection := p.parseDetectionStatement()
 			if detection != nil {
 				body = append(body, detection)
 			}
+		} else if p.isPipeStatementStart() {
+			pipe := p.parsePipeStatement()
+			if pipe != nil {
+				body = append(body, pipe)
+			}
+		} else if p.isNotifyStatementStart() {
+			notify := p.parseNotifyStatement()
+			if notify != nil {
+				body = append(body, notify)
+			}
+		} else if p.isAssertStatementStart() {
+			assert := p.parseAssertStatement()
+			if assert != nil {
+				body = append(body, assert)
+			}
+		} else if p.isWithinStatementStart() {
+			within := p.parseWithinStatement()
+			if within != nil {
+				body = append(body, within)
+			}
+		} else if p.isLockStatementStart() {
+			lock := p.parseLockStatement()
+			if lock != nil {
+				body = append(body, lock)
+			}
+		} else if p.isPluginStatementStart() {
+			plugin := p.parsePluginStatement()
+			if plugin != nil {
+				body = append(body, plugin)
+			}
+		} else if p.isCompareStatementStart() {
+			compare := p.parseCompareStatement()
+			if compare != nil {
+				body = append(body, compare)
+			}
+		} else if p.isVersionFilesStatementStart() {
+			versionFiles := p.parseVersionFilesStatement()
+			if versionFiles != nil {
+				body = append(body, versionFiles)
+			}
+		} else if p.isConfirmStatementStart() {
+			confirm := p.parseConfirmStatement()
+			if confirm != nil {
+				body = append(body, confirm)
+			}
+		} else if p.isTodoStatementStart() {
+			body = append(body, p.parseTodoStatement())
 		} else if p.curToken.Type == lexer.ORCHESTRATE {
 			orchestrate := p.parseOrchestrationActionStatement()
 			if orchestrate != nil {
@@ -458,7 +787,7 @@ func (p *Parser) parseControlFlowBody() []ast.Statement {
 			// Special handling for RUN token - check context
 			if p.curToken.Type == lexer.RUN {
 				// Look ahead to determine if this is shell or docker command
-				if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.COLON || p.peekToken.Type == lexer.IN {
+				if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.COLON || p.peekToken.Type == lexer.IN || p.peekIsShellModifier() {
 					// This is "run 'command'" or "run:" - shell command
 					shell := p.parseShellStatement()
 					if shell != nil {
@@ -605,6 +934,7 @@ func (p *Parser) parseControlFlowBody() []ast.Statement {
 			p.addError(fmt.Sprintf("unexpected token in control flow body: %s", p.curToken.Type))
 			break
 		}
+		body = p.guardLastStatement(body, bodyLen)
 	}
 
 	// Consume DEDENT
@@ -60,6 +60,22 @@ func (p *Parser) parseDetectionStatement() *ast.DetectionStatement {
 				}
 			}
 
+		} else if p.peekToken.Type == lexer.ENVIRONMENT {
+			// detect environment "nodejs" as $node_version
+			p.nextToken() // consume ENVIRONMENT
+			stmt.Type = "detect_environment"
+			if p.peekToken.Type == lexer.STRING || p.isToolNameToken(p.peekToken.Type) {
+				p.nextToken()
+				stmt.Target = p.curToken.Literal
+			} else {
+				p.errors = append(p.errors, fmt.Sprintf("expected environment entry name after 'detect environment', got %s", p.peekToken.Type))
+				return stmt
+			}
+			if !p.expectPeek(lexer.AS) || !p.expectPeekVariableName() {
+				return stmt
+			}
+			stmt.CaptureVar = p.getVariableName()
+
 		} else if p.peekToken.Type == lexer.PROJECT {
 			p.nextToken() // consume PROJECT
 			stmt.Target = "project"
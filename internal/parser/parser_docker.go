@@ -35,7 +35,7 @@ func (p *Parser) parseDockerStatement() *ast.DockerStatement {
 
 			if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "service" {
 				p.nextToken() // consume IDENT (service)
-				stmt.Options["resource"] = "service"
+				stmt.SetOption("resource", "service")
 
 				if p.peekToken.Type == lexer.STRING {
 					p.nextToken()
@@ -46,7 +46,7 @@ func (p *Parser) parseDockerStatement() *ast.DockerStatement {
 					p.nextToken() // consume TO
 					if p.peekToken.Type == lexer.NUMBER {
 						p.nextToken()
-						stmt.Options["replicas"] = p.curToken.Literal
+						stmt.SetOption("replicas", p.curToken.Literal)
 					}
 				}
 			}
@@ -84,12 +84,12 @@ func (p *Parser) parseDockerStatement() *ast.DockerStatement {
 
 		if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.NUMBER {
 			p.nextToken()
-			stmt.Options[optionKey] = p.curToken.Literal
+			stmt.SetOption(optionKey, p.curToken.Literal)
 		} else if optionKey == "on" && p.peekToken.Type == lexer.PORT {
 			p.nextToken() // consume PORT
 			if p.peekToken.Type == lexer.NUMBER {
 				p.nextToken()
-				stmt.Options["port"] = p.curToken.Literal
+				stmt.SetOption("port", p.curToken.Literal)
 			}
 		}
 	}
@@ -119,11 +119,11 @@ func (p *Parser) parseDockerComposeStatement(stmt *ast.DockerStatement) *ast.Doc
 
 	raw := p.collectInlineCommand()
 	if raw != "" {
-		stmt.Options["args"] = raw
+		stmt.SetOption("args", raw)
 		if _, exists := stmt.Options["command"]; !exists {
 			fields := strings.Fields(raw)
 			if len(fields) > 0 {
-				stmt.Options["command"] = fields[0]
+				stmt.SetOption("command", fields[0])
 			}
 		}
 	}
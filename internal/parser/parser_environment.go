@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// parseEnvironmentStatement parses a project "environment:" block.
+// Examples:
+//
+//	environment:
+//	  image "golang:1.22"
+//	  asdf nodejs "20.11.0"
+//	  nix jq
+func (p *Parser) parseEnvironmentStatement() *ast.EnvironmentStatement {
+	stmt := &ast.EnvironmentStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+	if !p.expectPeekSkipNewlines(lexer.INDENT) {
+		return nil
+	}
+
+	p.nextToken()
+
+	for p.curToken.Type != lexer.DEDENT && p.curToken.Type != lexer.EOF {
+		switch {
+		case p.curToken.Type == lexer.NEWLINE || p.curToken.Type == lexer.COMMENT || p.curToken.Type == lexer.MULTILINE_COMMENT:
+			p.nextToken()
+		case p.curToken.Type == lexer.IMAGE:
+			if !p.expectPeek(lexer.STRING) {
+				p.nextToken()
+				continue
+			}
+			stmt.Entries = append(stmt.Entries, ast.EnvironmentEntry{Kind: "image", Name: p.curToken.Literal})
+			p.nextToken()
+		case p.curToken.Type == lexer.IDENT && (p.curToken.Literal == "asdf" || p.curToken.Literal == "nix"):
+			kind := p.curToken.Literal
+			p.nextToken()
+			name, ok := p.parseEnvironmentToolName()
+			if !ok {
+				p.nextToken()
+				continue
+			}
+			entry := ast.EnvironmentEntry{Kind: kind, Name: name}
+			if kind == "asdf" {
+				if p.curToken.Type != lexer.STRING {
+					p.addError(fmt.Sprintf("expected version string after 'asdf %s', got %s instead", name, p.curToken.Type))
+					continue
+				}
+				entry.Version = p.curToken.Literal
+				p.nextToken()
+			}
+			stmt.Entries = append(stmt.Entries, entry)
+		default:
+			p.addError(fmt.Sprintf("expected 'image', 'asdf' or 'nix' in environment block, got %s instead", p.curToken.Type))
+			p.nextToken()
+		}
+	}
+
+	if len(stmt.Entries) == 0 {
+		p.addError("environment: block must declare at least one image, asdf tool or nix package")
+		return nil
+	}
+
+	return stmt
+}
+
+// parseEnvironmentToolName parses a quoted or bare tool name and advances past it
+func (p *Parser) parseEnvironmentToolName() (string, bool) {
+	if p.curToken.Type == lexer.STRING {
+		name := p.curToken.Literal
+		p.nextToken()
+		return name, true
+	}
+	return p.parseToolName()
+}
@@ -224,11 +224,19 @@ func (p *Parser) parseWriteStatement(stmt *ast.FileStatement) *ast.FileStatement
 
 // parseAppendStatement parses "append" statements
 func (p *Parser) parseAppendStatement(stmt *ast.FileStatement) *ast.FileStatement {
-	// Expect: append "content" to file "path"
-	if !p.expectPeek(lexer.STRING) {
-		return nil
+	// Expect: append "content" to file "path" or append file "source" to file "path"
+	if p.peekToken.Type == lexer.FILE || p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "file" {
+		p.nextToken() // consume FILE
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Source = p.curToken.Literal
+	} else {
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Content = p.curToken.Literal
 	}
-	stmt.Content = p.curToken.Literal
 
 	if !p.expectPeek(lexer.TO) {
 		return nil
@@ -128,7 +128,7 @@ func (p *Parser) parseGitStatement() ast.Statement {
 
 		if p.peekToken.Type == lexer.ALL {
 			p.nextToken() // consume ALL
-			stmt.Options["all"] = "true"
+			stmt.SetOption("all", "true")
 		}
 
 		if p.peekToken.Type == lexer.CHANGES {
@@ -143,7 +143,7 @@ func (p *Parser) parseGitStatement() ast.Statement {
 				p.nextToken() // consume MESSAGE
 				if p.peekToken.Type == lexer.STRING {
 					p.nextToken()
-					stmt.Options["message"] = p.curToken.Literal
+					stmt.SetOption("message", p.curToken.Literal)
 				}
 			}
 		}
@@ -209,7 +209,7 @@ func (p *Parser) parseGitStatement() ast.Statement {
 
 		if p.peekToken.Type == lexer.CURRENT {
 			p.nextToken() // consume CURRENT
-			stmt.Options["current"] = "true"
+			stmt.SetOption("current", "true")
 
 			if p.peekToken.Type == lexer.BRANCH || p.peekToken.Type == lexer.COMMIT {
 				p.nextToken()
@@ -239,26 +239,26 @@ func (p *Parser) parseGitStatement() ast.Statement {
 			switch p.peekToken.Type {
 			case lexer.STRING:
 				p.nextToken()
-				stmt.Options[optionKey] = p.curToken.Literal
+				stmt.SetOption(optionKey, p.curToken.Literal)
 			case lexer.REMOTE, lexer.BRANCH, lexer.MESSAGE:
 				p.nextToken()
 				keywordType := p.curToken.Literal
 				if p.peekToken.Type == lexer.STRING {
 					p.nextToken()
-					stmt.Options[keywordType] = p.curToken.Literal
+					stmt.SetOption(keywordType, p.curToken.Literal)
 				}
 			}
 		case lexer.REMOTE, lexer.BRANCH, lexer.MESSAGE:
 			keywordType := p.curToken.Literal
 			if p.peekToken.Type == lexer.STRING {
 				p.nextToken()
-				stmt.Options[keywordType] = p.curToken.Literal
+				stmt.SetOption(keywordType, p.curToken.Literal)
 			}
 		case lexer.IDENT:
 			optionKey := p.curToken.Literal
 			if p.peekToken.Type == lexer.STRING {
 				p.nextToken()
-				stmt.Options[optionKey] = p.curToken.Literal
+				stmt.SetOption(optionKey, p.curToken.Literal)
 			}
 		}
 	}
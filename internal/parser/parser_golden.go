@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// parseTestStatement parses a top-level test block:
+//
+//	test "deploy to staging" [in dry run]:
+//	  call task "deploy" with environment="staging"
+//	  expect output to match golden "tests/deploy.golden"
+func (p *Parser) parseTestStatement() *ast.TestStatement {
+	stmt := &ast.TestStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.STRING) {
+		p.addError("expected test name string")
+		return nil
+	}
+	stmt.Name = p.curToken.Literal
+
+	// Optional "in dry run"
+	if p.peekToken.Type == lexer.IN {
+		p.nextToken() // consume "in"
+		if !p.expectPeekLiteral("dry") || !p.expectPeek(lexer.RUN) {
+			p.addErrorWithHelp("expected 'in dry run' after the test name", `Example: test "deploy" in dry run:`)
+			return nil
+		}
+		stmt.DryRun = true
+	}
+
+	if !p.expectPeek(lexer.COLON) {
+		p.addError("expected ':' after test declaration")
+		return nil
+	}
+	if !p.expectPeekIndent() {
+		p.addError("expected indent after test declaration")
+		return nil
+	}
+	p.nextToken() // move past the INDENT
+
+	for p.curToken.Type != lexer.DEDENT && p.curToken.Type != lexer.EOF {
+		switch p.curToken.Type {
+		case lexer.NEWLINE, lexer.COMMENT, lexer.MULTILINE_COMMENT:
+			p.nextToken()
+			continue
+		case lexer.CALL:
+			if stmt.Call != nil {
+				p.addErrorWithHelp(fmt.Sprintf("test %q calls more than one task", stmt.Name), "A test runs one task; declare a test for each task")
+				return nil
+			}
+			stmt.Call = p.parseTaskCallStatement()
+			if stmt.Call == nil {
+				return nil
+			}
+		case lexer.EXPECT:
+			if stmt.Golden != "" {
+				p.addError(fmt.Sprintf("test %q expects more than one golden file", stmt.Name))
+				return nil
+			}
+			if !p.expectPeek(lexer.OUTPUT) || !p.expectPeek(lexer.TO) || !p.expectPeek(lexer.MATCH) ||
+				!p.expectPeekLiteral("golden") || !p.expectPeek(lexer.STRING) {
+				p.addErrorWithHelp("expected 'expect output to match golden \"file\"'", `Example: expect output to match golden "tests/deploy.golden"`)
+				return nil
+			}
+			stmt.Golden = p.curToken.Literal
+		default:
+			p.addErrorWithHelp(
+				fmt.Sprintf("unexpected %s in test %q", p.curToken.Type, stmt.Name),
+				"A test body has a 'call task' line and an 'expect output to match golden' line",
+			)
+			return nil
+		}
+		p.nextToken()
+	}
+
+	if p.curToken.Type == lexer.DEDENT {
+		p.nextToken()
+	}
+
+	if stmt.Call == nil {
+		p.addErrorWithHelp(fmt.Sprintf("test %q does not call a task", stmt.Name), `Example: call task "deploy" with environment="staging"`)
+		return nil
+	}
+	if stmt.Golden == "" {
+		p.addErrorWithHelp(fmt.Sprintf("test %q does not expect any output", stmt.Name), `Example: expect output to match golden "tests/deploy.golden"`)
+		return nil
+	}
+	return stmt
+}
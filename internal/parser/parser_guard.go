@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// peekIsStatementGuard reports whether the statement just parsed is followed
+// by 'only when' or 'unless' on the same line
+func (p *Parser) peekIsStatementGuard() bool {
+	if p.curToken.Type == lexer.DEDENT || p.peekToken.Type != lexer.IDENT || p.peekToken.Line != p.curToken.Line {
+		return false
+	}
+	return p.peekToken.Literal == "only" || p.peekToken.Literal == "unless"
+}
+
+// parseStatementGuard wraps the statement just parsed in its trailing
+// condition; the condition runs to the end of the line
+// Syntax: <statement> only when <condition> | <statement> unless <condition>
+func (p *Parser) parseStatementGuard(stmt ast.Statement) ast.Statement {
+	p.nextToken() // consume 'only' or 'unless'
+	guard := &ast.GuardedStatement{
+		Token:     p.curToken,
+		Statement: stmt,
+		Negate:    p.curToken.Literal == "unless",
+	}
+	if !guard.Negate && !p.expectPeek(lexer.WHEN) {
+		return nil
+	}
+
+	guard.Condition = p.collectLineCondition(lexer.EOF)
+	if guard.Condition == "" {
+		p.addError("expected a condition after '" + guard.Token.Literal + "'")
+		return nil
+	}
+	return guard
+}
+
+// guardLastStatement applies a trailing guard to the statement appended to
+// body by the current line. before is the length of body before the line
+// was parsed; lines that did not add exactly one statement are left alone.
+func (p *Parser) guardLastStatement(body []ast.Statement, before int) []ast.Statement {
+	if len(body) != before+1 || !p.peekIsStatementGuard() {
+		return body
+	}
+	if guarded := p.parseStatementGuard(body[before]); guarded != nil {
+		body[before] = guarded
+	}
+	return body
+}
@@ -151,7 +151,7 @@ func (p *Parser) isParameterToken(tokenType lexer.TokenType) bool {
 // isControlFlowToken checks if a token type represents a control flow statement
 func (p *Parser) isControlFlowToken(tokenType lexer.TokenType) bool {
 	switch tokenType {
-	case lexer.WHEN, lexer.IF, lexer.FOR:
+	case lexer.WHEN, lexer.IF, lexer.FOR, lexer.WHILE, lexer.POLL:
 		return true
 	default:
 		return false
@@ -253,7 +253,7 @@ func (p *Parser) isKeywordToken(tokenType lexer.TokenType) bool {
 	case lexer.VERSION, lexer.TASK, lexer.PROJECT, lexer.DRUN,
 		lexer.SETUP, lexer.TEARDOWN, lexer.BEFORE, lexer.AFTER,
 		lexer.IF, lexer.ELSE, lexer.WHEN, lexer.OTHERWISE,
-		lexer.FOR, lexer.IN, lexer.PARALLEL,
+		lexer.FOR, lexer.WHILE, lexer.POLL, lexer.UNTIL, lexer.IN, lexer.PARALLEL,
 		lexer.WITH, lexer.TRY, lexer.CATCH, lexer.FINALLY,
 		lexer.THROW, lexer.IGNORE, lexer.CALL,
 		lexer.COLON, lexer.EQUALS, lexer.COMMA, lexer.LPAREN, lexer.RPAREN,
@@ -2,6 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -76,11 +79,19 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 		p.peekToken.Type == lexer.BODY || p.peekToken.Type == lexer.DATA || p.peekToken.Type == lexer.AUTH ||
 		p.peekToken.Type == lexer.BEARER || p.peekToken.Type == lexer.BASIC || p.peekToken.Type == lexer.TOKEN ||
 		p.peekToken.Type == lexer.TIMEOUT || p.peekToken.Type == lexer.RETRY || p.peekToken.Type == lexer.ACCEPT ||
-		p.peekToken.Type == lexer.CONTENT || p.peekToken.Type == lexer.TYPE {
+		p.peekToken.Type == lexer.CONTENT || p.peekToken.Type == lexer.TYPE || p.peekToken.Type == lexer.CAPTURE ||
+		p.peekToken.Type == lexer.EXPECT ||
+		(p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "using" && p.peekToken.Line == p.curToken.Line) {
 
 		p.nextToken()
 
 		switch p.curToken.Type {
+		case lexer.IDENT: // using client "api"
+			if !p.expectPeekLiteral("client") || !p.expectPeek(lexer.STRING) {
+				return nil
+			}
+			stmt.Client = p.curToken.Literal
+
 		case lexer.WITH:
 			// Parse "with header", "with body", "with auth", etc.
 			switch p.peekToken.Type {
@@ -93,7 +104,7 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 					if colonIdx := strings.Index(headerValue, ":"); colonIdx != -1 {
 						key := strings.TrimSpace(headerValue[:colonIdx])
 						value := strings.TrimSpace(headerValue[colonIdx+1:])
-						stmt.Headers[key] = value
+						stmt.SetHeader(key, value)
 					}
 				}
 			case lexer.BODY, lexer.DATA:
@@ -109,14 +120,14 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 					authType := p.curToken.Literal
 					if p.peekToken.Type == lexer.STRING {
 						p.nextToken()
-						stmt.Auth[authType] = p.curToken.Literal
+						stmt.SetAuth(authType, p.curToken.Literal)
 					}
 				}
 			case lexer.TOKEN:
 				p.nextToken() // consume TOKEN
 				if p.peekToken.Type == lexer.STRING {
 					p.nextToken()
-					stmt.Auth["bearer"] = p.curToken.Literal
+					stmt.SetAuth("bearer", p.curToken.Literal)
 				}
 			}
 
@@ -128,7 +139,7 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 				if colonIdx := strings.Index(headerValue, ":"); colonIdx != -1 {
 					key := strings.TrimSpace(headerValue[:colonIdx])
 					value := strings.TrimSpace(headerValue[colonIdx+1:])
-					stmt.Headers[key] = value
+					stmt.SetHeader(key, value)
 				}
 			}
 
@@ -144,7 +155,7 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 				authType := p.curToken.Literal
 				if p.peekToken.Type == lexer.STRING {
 					p.nextToken()
-					stmt.Auth[authType] = p.curToken.Literal
+					stmt.SetAuth(authType, p.curToken.Literal)
 				}
 			}
 
@@ -152,30 +163,42 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 			authType := p.curToken.Literal
 			if p.peekToken.Type == lexer.STRING {
 				p.nextToken()
-				stmt.Auth[authType] = p.curToken.Literal
+				stmt.SetAuth(authType, p.curToken.Literal)
 			}
 
 		case lexer.TOKEN:
 			if p.peekToken.Type == lexer.STRING {
 				p.nextToken()
-				stmt.Auth["bearer"] = p.curToken.Literal
+				stmt.SetAuth("bearer", p.curToken.Literal)
 			}
 
 		case lexer.TIMEOUT, lexer.RETRY:
 			optionKey := p.curToken.Literal
 			if p.peekToken.Type == lexer.STRING {
 				p.nextToken()
-				stmt.Options[optionKey] = p.curToken.Literal
+				stmt.SetOption(optionKey, p.curToken.Literal)
 			}
 
 		case lexer.ACCEPT:
 			switch p.peekToken.Type {
 			case lexer.JSON, lexer.XML:
 				p.nextToken()
-				stmt.Headers["Accept"] = "application/" + p.curToken.Literal
+				stmt.SetHeader("Accept", "application/"+p.curToken.Literal)
 			case lexer.STRING:
 				p.nextToken()
-				stmt.Headers["Accept"] = p.curToken.Literal
+				stmt.SetHeader("Accept", p.curToken.Literal)
+			}
+
+		case lexer.CAPTURE:
+			capture, ok := p.parseHTTPCapture()
+			if !ok {
+				return nil
+			}
+			stmt.Captures = append(stmt.Captures, capture)
+
+		case lexer.EXPECT:
+			if !p.parseHTTPExpectations(stmt) {
+				return nil
 			}
 
 		case lexer.CONTENT:
@@ -184,10 +207,10 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 				switch p.peekToken.Type {
 				case lexer.JSON, lexer.XML:
 					p.nextToken()
-					stmt.Headers["Content-Type"] = "application/" + p.curToken.Literal
+					stmt.SetHeader("Content-Type", "application/"+p.curToken.Literal)
 				case lexer.STRING:
 					p.nextToken()
-					stmt.Headers["Content-Type"] = p.curToken.Literal
+					stmt.SetHeader("Content-Type", p.curToken.Literal)
 				}
 			}
 		}
@@ -196,6 +219,173 @@ func (p *Parser) parseHTTPStatement() ast.Statement {
 	return stmt
 }
 
+// parseHTTPCapture parses the rest of "capture status|body|header "Name" as $var"
+// after CAPTURE
+func (p *Parser) parseHTTPCapture() (ast.HTTPCapture, bool) {
+	var capture ast.HTTPCapture
+	switch p.peekToken.Type {
+	case lexer.STATUS, lexer.BODY:
+		p.nextToken()
+		capture.Part = strings.ToLower(p.curToken.Literal)
+	case lexer.HEADER:
+		p.nextToken()
+		capture.Part = "header"
+		if !p.expectPeek(lexer.STRING) {
+			return capture, false
+		}
+		capture.Header = p.curToken.Literal
+	default:
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected status, body or header after 'capture', got %s instead", p.peekToken.Literal),
+			`Use: get "https://svc/health" capture status as $code`,
+		)
+		return capture, false
+	}
+
+	if !p.expectPeek(lexer.AS) || !p.expectPeekIdentifierLike() {
+		return capture, false
+	}
+	capture.Variable = p.curToken.Literal
+	if !strings.HasPrefix(capture.Variable, "$") {
+		capture.Variable = "$" + capture.Variable
+	}
+	return capture, true
+}
+
+// httpStatusPattern matches an expected status: a code or a class like "2xx"
+var httpStatusPattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
+// parseHTTPExpectations parses the rest of "expect response status in [200, 201]
+// and header "Content-Type" contains "json" and body matches ".ok == true""
+// after EXPECT
+func (p *Parser) parseHTTPExpectations(stmt *ast.HTTPStatement) bool {
+	if p.peekToken.Type == lexer.RESPONSE {
+		p.nextToken()
+	}
+	for {
+		expectation, ok := p.parseHTTPExpectation()
+		if !ok {
+			return false
+		}
+		stmt.Expectations = append(stmt.Expectations, expectation)
+		if p.peekToken.Type != lexer.AND {
+			return true
+		}
+		p.nextToken() // consume AND
+	}
+}
+
+// parseHTTPExpectation parses one assertion: status is|in, header "Name"
+// is|contains|matches|exists, or body is|contains|matches
+func (p *Parser) parseHTTPExpectation() (ast.HTTPExpectation, bool) {
+	var expectation ast.HTTPExpectation
+	switch p.peekToken.Type {
+	case lexer.STATUS, lexer.BODY:
+		p.nextToken()
+		expectation.Part = strings.ToLower(p.curToken.Literal)
+	case lexer.HEADER:
+		p.nextToken()
+		expectation.Part = "header"
+		if !p.expectPeek(lexer.STRING) {
+			return expectation, false
+		}
+		expectation.Header = p.curToken.Literal
+	default:
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected status, header or body after 'expect response', got %s instead", p.peekToken.Literal),
+			`Use: get "https://svc/health" expect response status in [200, 201] and body matches ".ok == true"`,
+		)
+		return expectation, false
+	}
+
+	allowed := map[string][]lexer.TokenType{
+		"status": {lexer.IS, lexer.IN},
+		"header": {lexer.IS, lexer.CONTAINS, lexer.MATCHES, lexer.EXISTS},
+		"body":   {lexer.IS, lexer.CONTAINS, lexer.MATCHES},
+	}[expectation.Part]
+	var names []string
+	for _, tokenType := range allowed {
+		names = append(names, strings.ToLower(tokenType.String()))
+		if p.peekToken.Type == tokenType {
+			expectation.Operator = strings.ToLower(p.peekToken.Literal)
+		}
+	}
+	if expectation.Operator == "" && expectation.Part == "status" {
+		// "status 200" and "status [200, 201]" read as is and in
+		switch p.peekToken.Type {
+		case lexer.NUMBER, lexer.STRING:
+			status, ok := p.parseExpectedStatus()
+			if !ok {
+				return expectation, false
+			}
+			expectation.Operator, expectation.Values = "is", []string{status}
+			return expectation, true
+		case lexer.LBRACKET:
+			expectation.Operator = "in"
+		}
+	}
+	if expectation.Operator == "" {
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected %s after '%s', got %s instead", strings.Join(names, ", "), expectation.Part, p.peekToken.Literal),
+			`Use: expect response status is 200, header "Content-Type" contains "json" or body matches ".ok == true"`,
+		)
+		return expectation, false
+	}
+	if p.peekToken.Type != lexer.LBRACKET {
+		p.nextToken() // consume the operator, unless in was left out
+	}
+
+	switch {
+	case expectation.Operator == "exists":
+		return expectation, true
+	case expectation.Operator == "in":
+		if !p.expectPeek(lexer.LBRACKET) {
+			return expectation, false
+		}
+		for {
+			status, ok := p.parseExpectedStatus()
+			if !ok {
+				return expectation, false
+			}
+			expectation.Values = append(expectation.Values, status)
+			if p.peekToken.Type != lexer.COMMA {
+				break
+			}
+			p.nextToken() // consume COMMA
+		}
+		if !p.expectPeek(lexer.RBRACKET) {
+			return expectation, false
+		}
+	case expectation.Part == "status":
+		status, ok := p.parseExpectedStatus()
+		if !ok {
+			return expectation, false
+		}
+		expectation.Values = []string{status}
+	default:
+		if !p.expectPeek(lexer.STRING) {
+			return expectation, false
+		}
+		expectation.Values = []string{p.curToken.Literal}
+	}
+	return expectation, true
+}
+
+// parseExpectedStatus parses a status code, or a quoted class like "2xx"
+func (p *Parser) parseExpectedStatus() (string, bool) {
+	if p.peekToken.Type != lexer.NUMBER && p.peekToken.Type != lexer.STRING {
+		p.addError(fmt.Sprintf("expected a status code, got %s instead", p.peekToken.Literal))
+		return "", false
+	}
+	p.nextToken()
+	status := strings.ToLower(p.curToken.Literal)
+	if !httpStatusPattern.MatchString(status) {
+		p.addError(fmt.Sprintf("invalid status %q: expected a code like 200 or a class like \"2xx\"", p.curToken.Literal))
+		return "", false
+	}
+	return status, true
+}
+
 // parseDownloadStatement parses download operations
 // Syntax: download "url" to "path" [allow overwrite] [with header "..."] [timeout "..."]
 func (p *Parser) parseDownloadStatement() *ast.DownloadStatement {
@@ -344,7 +534,7 @@ func (p *Parser) parseDownloadStatement() *ast.DownloadStatement {
 					if colonIdx := strings.Index(headerValue, ":"); colonIdx != -1 {
 						key := strings.TrimSpace(headerValue[:colonIdx])
 						value := strings.TrimSpace(headerValue[colonIdx+1:])
-						stmt.Headers[key] = value
+						stmt.SetHeader(key, value)
 					}
 				}
 
@@ -355,7 +545,7 @@ func (p *Parser) parseDownloadStatement() *ast.DownloadStatement {
 					authType := p.curToken.Literal
 					if p.peekToken.Type == lexer.STRING {
 						p.nextToken()
-						stmt.Auth[authType] = p.curToken.Literal
+						stmt.SetAuth(authType, p.curToken.Literal)
 					}
 				}
 			}
@@ -365,7 +555,7 @@ func (p *Parser) parseDownloadStatement() *ast.DownloadStatement {
 			optionKey := p.curToken.Literal
 			if p.peekToken.Type == lexer.STRING {
 				p.nextToken()
-				stmt.Options[optionKey] = p.curToken.Literal
+				stmt.SetOption(optionKey, p.curToken.Literal)
 			}
 
 		case lexer.REMOVE:
@@ -375,9 +565,72 @@ func (p *Parser) parseDownloadStatement() *ast.DownloadStatement {
 				stmt.RemoveArchive = true
 			}
 
+		case lexer.IDENT:
+			switch p.peekToken.Literal {
+			case "strip":
+				p.nextToken() // consume strip
+				if !p.parseStripComponents(stmt) {
+					return nil
+				}
+			case "only":
+				p.nextToken() // consume only
+				if !p.parseExtractFilters(stmt) {
+					return nil
+				}
+			default:
+				return stmt
+			}
+
 		default:
 			// No more options
 			return stmt
 		}
 	}
 }
+
+// parseStripComponents parses the count of "strip <n> component(s)", which
+// drops the leading directories of every extracted path
+func (p *Parser) parseStripComponents(stmt *ast.DownloadStatement) bool {
+	if stmt.ExtractTo == "" {
+		p.addError("'strip' requires 'extract to'")
+		return false
+	}
+	if !p.expectPeek(lexer.NUMBER) {
+		return false
+	}
+	count, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil || count < 1 {
+		p.addError(fmt.Sprintf("invalid component count %q after 'strip': expected a whole number of at least 1", p.curToken.Literal))
+		return false
+	}
+	if p.peekToken.Type != lexer.IDENT || (p.peekToken.Literal != "component" && p.peekToken.Literal != "components") {
+		p.addError(fmt.Sprintf("expected 'components' after 'strip %d', got %s", count, p.peekToken.Literal))
+		return false
+	}
+	p.nextToken() // consume component(s)
+	stmt.StripComponents = count
+	return true
+}
+
+// parseExtractFilters parses the patterns of "only "bin/*", "LICENSE"",
+// which limit extraction to the matching paths
+func (p *Parser) parseExtractFilters(stmt *ast.DownloadStatement) bool {
+	if stmt.ExtractTo == "" {
+		p.addError("'only' requires 'extract to'")
+		return false
+	}
+	for {
+		if !p.expectPeek(lexer.STRING) {
+			return false
+		}
+		if _, err := path.Match(p.curToken.Literal, ""); err != nil {
+			p.addError(fmt.Sprintf("invalid pattern %q after 'only': %v", p.curToken.Literal, err))
+			return false
+		}
+		stmt.Only = append(stmt.Only, p.curToken.Literal)
+		if p.peekToken.Type != lexer.COMMA {
+			return true
+		}
+		p.nextToken() // consume comma
+	}
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isLockStatementStart reports whether the current token starts a lock block
+func (p *Parser) isLockStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "lock" && p.peekToken.Type == lexer.STRING
+}
+
+// parseLockStatement parses a block that runs while holding a named lock
+// Syntax: lock "name" [using "backend"] [waiting up to <duration>]:
+func (p *Parser) parseLockStatement() *ast.LockStatement {
+	stmt := &ast.LockStatement{Token: p.curToken}
+	p.nextToken() // consume lock
+	stmt.Name = p.curToken.Literal
+	if stmt.Name == "" {
+		p.addError("lock name cannot be empty")
+		return nil
+	}
+
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "using" {
+		p.nextToken() // consume using
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Backend = p.curToken.Literal
+	}
+
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "waiting" {
+		p.nextToken() // consume waiting
+		if !p.expectPeek(lexer.UP) || !p.expectPeek(lexer.TO) {
+			return nil
+		}
+		stmt.Wait = p.parsePollDuration("waiting up to")
+		if stmt.Wait == "" {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+	stmt.Body = p.parseControlFlowBody()
+	if len(stmt.Body) == 0 {
+		p.addError("lock block has no statements")
+		return nil
+	}
+	return stmt
+}
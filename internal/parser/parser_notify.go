@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isNotifyStatementStart reports whether the current token starts
+// "notify me when done"
+func (p *Parser) isNotifyStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "notify" && p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "me"
+}
+
+// parseNotifyStatement parses a request for a completion notification
+// Syntax: notify me when done
+func (p *Parser) parseNotifyStatement() *ast.NotifyStatement {
+	stmt := &ast.NotifyStatement{Token: p.curToken}
+
+	p.nextToken() // move to 'me'
+	if !p.expectPeek(lexer.WHEN) {
+		return nil
+	}
+	if p.peekToken.Literal != "done" {
+		p.addError(fmt.Sprintf("expected 'notify me when done', got 'notify me when %s'", p.peekToken.Literal))
+		return nil
+	}
+	p.nextToken() // move to 'done'
+	return stmt
+}
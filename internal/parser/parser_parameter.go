@@ -33,7 +33,9 @@ func (p *Parser) parseParameterStatement() *ast.ParameterStatement {
 	// Check for type declaration: "as type"
 	if p.peekToken.Type == lexer.AS {
 		p.nextToken() // consume AS
-		if p.isTypeToken(p.peekToken.Type) {
+		if p.peekToken.Type == lexer.FILE {
+			p.parseFilePathType(stmt)
+		} else if p.isTypeToken(p.peekToken.Type) {
 			p.nextToken() // consume type token
 			stmt.DataType = p.curToken.Literal
 
@@ -252,9 +254,51 @@ func (p *Parser) parseParameterStatement() *ast.ParameterStatement {
 		}
 	}
 
+	// A custom validator: requires $namespace validated by "k8s-namespace"
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "validated" {
+		p.nextToken() // consume "validated"
+		if !p.expectPeekLiteral("by") || !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Validator = p.curToken.Literal
+	}
+
+	// Values suggested by shell completion: requires $service completed by "kubectl get svc -o name"
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "completed" {
+		p.nextToken() // consume "completed"
+		if !p.expectPeekLiteral("by") || !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Completion = p.curToken.Literal
+	}
+
 	return stmt
 }
 
+// parseFilePathType parses "file [path] [which must exist]" after 'as'
+func (p *Parser) parseFilePathType(stmt *ast.ParameterStatement) {
+	p.nextToken() // consume FILE
+	stmt.DataType = "file"
+	if p.peekToken.Type == lexer.PATH {
+		p.nextToken() // consume PATH
+	}
+	if p.peekToken.Type != lexer.IDENT || p.peekToken.Literal != "which" {
+		return
+	}
+	p.nextToken() // consume "which"
+	for _, word := range []string{"must", "exist"} {
+		if p.peekToken.Type != lexer.IDENT || p.peekToken.Literal != word {
+			p.addErrorWithHelpAtPeek(
+				fmt.Sprintf("expected '%s' in file parameter constraint, got %s instead", word, p.peekToken.Literal),
+				"Use: requires $config as file path which must exist",
+			)
+			return
+		}
+		p.nextToken()
+	}
+	stmt.MustExist = true
+}
+
 // parseAdvancedConstraints parses advanced parameter constraints
 func (p *Parser) parseAdvancedConstraints(stmt *ast.ParameterStatement) {
 	for {
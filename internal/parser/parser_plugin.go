@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isPluginStatementStart reports whether the current token starts a
+// statement delegated to a drun-x-<tool> plugin; tool names may be keywords
+// such as docker or echo
+func (p *Parser) isPluginStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "x" &&
+		p.peekToken.Line == p.curToken.Line && p.peekToken.Type != lexer.NUMBER && p.isTaskNamePartToken(p.peekToken)
+}
+
+// parsePluginStatement parses a plugin statement; the arguments run to the
+// end of the line. Quoted strings are one argument each, and unquoted tokens
+// written without spaces between them (--force, key=value) are joined into
+// one argument. A bare $variable is passed as its value.
+// Syntax: x <tool> <arguments...>
+func (p *Parser) parsePluginStatement() *ast.PluginStatement {
+	stmt := &ast.PluginStatement{Token: p.curToken}
+	p.nextToken() // consume "x"
+	stmt.Tool = p.curToken.Literal
+
+	line := p.curToken.Line
+	end := -1 // column after the previous unquoted token
+	for p.peekToken.Line == line && p.peekToken.Type != lexer.DEDENT && p.peekToken.Type != lexer.EOF &&
+		p.peekToken.Type != lexer.NEWLINE && p.peekToken.Type != lexer.COMMENT {
+		p.nextToken()
+		tok := p.curToken
+
+		value := tok.Literal
+		if tok.Type == lexer.VARIABLE {
+			value = "{" + tok.Literal + "}"
+		}
+		if tok.Column == end && len(stmt.Args) > 0 {
+			stmt.Args[len(stmt.Args)-1] += value
+		} else {
+			stmt.Args = append(stmt.Args, value)
+		}
+
+		end = -1
+		if tok.Type != lexer.STRING {
+			end = tok.Column + len(tok.Literal)
+		}
+	}
+	return stmt
+}
@@ -2,6 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -68,12 +71,28 @@ func (p *Parser) parseProjectStatement() *ast.ProjectStatement {
 					p.addError("annotation(s) in project body must be followed by a snippet declaration")
 					p.pendingAnnotations = nil
 				}
-				setting := p.parseSetStatement()
-				if setting != nil {
-					stmt.Settings = append(stmt.Settings, setting)
+				if p.peekToken.Type == lexer.PATH {
+					pathStmt := p.parsePathStatement()
+					if pathStmt != nil {
+						stmt.Settings = append(stmt.Settings, pathStmt)
+					} else {
+						p.nextToken()
+					}
+				} else if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "credential" {
+					helper := p.parseCredentialHelperStatement(stmt.Settings)
+					if helper != nil {
+						stmt.Settings = append(stmt.Settings, helper)
+					} else {
+						p.nextToken()
+					}
 				} else {
-					// If parsing failed, advance to avoid infinite loop
-					p.nextToken()
+					setting := p.parseSetStatement()
+					if setting != nil {
+						stmt.Settings = append(stmt.Settings, setting)
+					} else {
+						// If parsing failed, advance to avoid infinite loop
+						p.nextToken()
+					}
 				}
 			case lexer.PARAMETER:
 				if len(p.pendingAnnotations) > 0 {
@@ -132,6 +151,15 @@ func (p *Parser) parseProjectStatement() *ast.ProjectStatement {
 					p.addError("annotation(s) in project body must be followed by a snippet declaration")
 					p.pendingAnnotations = nil
 				}
+				if p.peekToken.Type == lexer.ENVIRONMENT {
+					shellEnv := p.parseShellEnvironmentStatement()
+					if shellEnv != nil {
+						stmt.Settings = append(stmt.Settings, shellEnv)
+					} else {
+						p.nextToken()
+					}
+					break
+				}
 				shellConfig := p.parseShellConfigStatement()
 				if shellConfig != nil {
 					stmt.Settings = append(stmt.Settings, shellConfig)
@@ -188,6 +216,33 @@ func (p *Parser) parseProjectStatement() *ast.ProjectStatement {
 					if p.curToken.Type == lexer.DEDENT {
 						p.nextToken()
 					}
+				case "hosts":
+					if len(p.pendingAnnotations) > 0 {
+						p.addError("annotation(s) in project body must be followed by a snippet declaration")
+						p.pendingAnnotations = nil
+					}
+					group := p.parseHostGroupStatement(stmt.Settings)
+					if group != nil {
+						stmt.Settings = append(stmt.Settings, group)
+					} else {
+						p.nextToken()
+					}
+				case "profile":
+					if len(p.pendingAnnotations) > 0 {
+						p.addError("annotation(s) in project body must be followed by a snippet declaration")
+						p.pendingAnnotations = nil
+					}
+					profile := p.parseProfileStatement()
+					if profile != nil {
+						for _, existing := range stmt.ProfileNames() {
+							if existing == profile.Name {
+								p.addError(fmt.Sprintf("profile %q is defined more than once", profile.Name))
+							}
+						}
+						stmt.Settings = append(stmt.Settings, profile)
+					} else {
+						p.nextToken()
+					}
 				default:
 					if len(p.pendingAnnotations) > 0 {
 						p.addError("annotation(s) in project body must be followed by a snippet declaration")
@@ -196,6 +251,23 @@ func (p *Parser) parseProjectStatement() *ast.ProjectStatement {
 					p.addError(fmt.Sprintf("unexpected token in project body: %s", p.curToken.Type))
 					p.nextToken()
 				}
+			case lexer.ENVIRONMENT:
+				if len(p.pendingAnnotations) > 0 {
+					p.addError("annotation(s) in project body must be followed by a snippet declaration")
+					p.pendingAnnotations = nil
+				}
+				if stmt.Environment() != nil {
+					p.addError("environment: block is declared more than once")
+				}
+				environment := p.parseEnvironmentStatement()
+				if environment != nil {
+					stmt.Settings = append(stmt.Settings, environment)
+				} else {
+					p.nextToken()
+				}
+				if p.curToken.Type == lexer.DEDENT {
+					p.nextToken()
+				}
 			case lexer.GIT:
 				if len(p.pendingAnnotations) > 0 {
 					p.addError("annotation(s) in project body must be followed by a snippet declaration")
@@ -218,6 +290,51 @@ func (p *Parser) parseProjectStatement() *ast.ProjectStatement {
 					p.addError(fmt.Sprintf("unexpected 'git' in project body (did you mean 'git policy:'?), got git %s", p.peekToken.Type))
 					p.nextToken()
 				}
+			case lexer.DEFAULTS:
+				if len(p.pendingAnnotations) > 0 {
+					p.addError("annotation(s) in project body must be followed by a snippet declaration")
+					p.pendingAnnotations = nil
+				}
+				defaults := p.parseNamespaceDefaultsStatement()
+				if defaults != nil {
+					stmt.Settings = append(stmt.Settings, defaults)
+				} else {
+					p.nextToken()
+				}
+			case lexer.DEFAULT_KW:
+				if len(p.pendingAnnotations) > 0 {
+					p.addError("annotation(s) in project body must be followed by a snippet declaration")
+					p.pendingAnnotations = nil
+				}
+				setting := p.parseDefaultTaskStatement()
+				if setting != nil {
+					stmt.Settings = append(stmt.Settings, setting)
+				} else {
+					// If parsing failed, advance to avoid infinite loop
+					p.nextToken()
+				}
+			case lexer.HTTP:
+				if len(p.pendingAnnotations) > 0 {
+					p.addError("annotation(s) in project body must be followed by a snippet declaration")
+					p.pendingAnnotations = nil
+				}
+				client := p.parseHTTPClientStatement(stmt.Settings)
+				if client != nil {
+					stmt.Settings = append(stmt.Settings, client)
+				} else {
+					p.nextToken()
+				}
+			case lexer.CONDITION:
+				if len(p.pendingAnnotations) > 0 {
+					p.addError("annotation(s) in project body must be followed by a snippet declaration")
+					p.pendingAnnotations = nil
+				}
+				condition := p.parseConditionStatement(stmt.Settings)
+				if condition != nil {
+					stmt.Settings = append(stmt.Settings, condition)
+				} else {
+					p.nextToken()
+				}
 			case lexer.COMMENT, lexer.MULTILINE_COMMENT:
 				p.nextToken() // Skip comments
 			case lexer.NEWLINE:
@@ -252,6 +369,173 @@ func (p *Parser) parseProjectStatement() *ast.ProjectStatement {
 	return stmt
 }
 
+// parseHookPriority parses an optional "priority N" clause of a lifecycle
+// hook header; N may be negative to run after hooks without a priority
+func (p *Parser) parseHookPriority(hook *ast.LifecycleHook) bool {
+	if p.peekToken.Type != lexer.IDENT || p.peekToken.Literal != "priority" {
+		return true
+	}
+	p.nextToken() // consume priority
+
+	sign := 1
+	if p.peekToken.Type == lexer.MINUS {
+		p.nextToken()
+		sign = -1
+	}
+	if !p.expectPeek(lexer.NUMBER) {
+		return false
+	}
+	priority, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil {
+		p.addError(fmt.Sprintf("hook priority must be a whole number, got %q", p.curToken.Literal))
+		return false
+	}
+	hook.Priority = sign * priority
+	return true
+}
+
+// parseDefaultTaskStatement parses: default task is "name"
+func (p *Parser) parseDefaultTaskStatement() *ast.DefaultTaskStatement {
+	stmt := &ast.DefaultTaskStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.TASK) || !p.expectPeek(lexer.IS) || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.TaskName = p.curToken.Literal
+	if stmt.TaskName == "" {
+		p.addError("default task name cannot be empty")
+		return nil
+	}
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
+// parseProfileStatement parses a parameter preset, either on one line or as
+// an indented block of set statements:
+//
+//	profile "prod": set env to "prod", set replicas to "5"
+//	profile "staging":
+//	  set env to "staging"
+func (p *Parser) parseProfileStatement() *ast.ProfileStatement {
+	stmt := &ast.ProfileStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Name = p.curToken.Literal
+	if stmt.Name == "" {
+		p.addError("profile name cannot be empty")
+		return nil
+	}
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+
+	values, ok := p.parseSetValues(fmt.Sprintf("profile %q", stmt.Name))
+	if !ok {
+		return nil
+	}
+	stmt.Values = values
+
+	if len(stmt.Values) == 0 {
+		p.addError(fmt.Sprintf("profile %q must set at least one parameter", stmt.Name))
+		return nil
+	}
+	return stmt
+}
+
+// checkPlainSetValue rejects encrypted values outside the project body,
+// where they would be passed on without being decrypted
+func (p *Parser) checkPlainSetValue(value *ast.SetStatement, owner string) bool {
+	if value.Encrypted {
+		p.addError(fmt.Sprintf("encrypted values can only be set in the project body, not in %s", owner))
+		return false
+	}
+	return true
+}
+
+// parseSetValues parses the "set <parameter> to <value>" list that follows
+// the colon of a profile or defaults header, either on the same line
+// (separated by commas) or as an indented block
+func (p *Parser) parseSetValues(owner string) ([]*ast.SetStatement, bool) {
+	var values []*ast.SetStatement
+	if p.peekToken.Type == lexer.SET {
+		for {
+			p.nextToken() // move to SET
+			value := p.parseSetStatement()
+			if value == nil || !p.checkPlainSetValue(value, owner) {
+				return nil, false
+			}
+			values = append(values, value)
+			if p.curToken.Type != lexer.COMMA || p.peekToken.Type != lexer.SET {
+				break
+			}
+		}
+		return values, true
+	}
+
+	if !p.expectPeekSkipNewlines(lexer.INDENT) {
+		return nil, false
+	}
+	p.nextToken()
+
+	for p.curToken.Type != lexer.DEDENT && p.curToken.Type != lexer.EOF {
+		switch p.curToken.Type {
+		case lexer.NEWLINE, lexer.COMMENT, lexer.MULTILINE_COMMENT:
+			p.nextToken()
+		case lexer.SET:
+			value := p.parseSetStatement()
+			if value == nil || !p.checkPlainSetValue(value, owner) {
+				return nil, false
+			}
+			values = append(values, value)
+		default:
+			p.addError(fmt.Sprintf("expected 'set <parameter> to <value>' in %s, got %s instead", owner, p.curToken.Type))
+			p.nextToken()
+		}
+	}
+	if p.curToken.Type == lexer.DEDENT {
+		p.nextToken() // consume the block's DEDENT
+	}
+	return values, true
+}
+
+// parseNamespaceDefaultsStatement parses parameter defaults for included
+// tasks, with the same value syntax as profiles:
+// defaults for docker.*: set registry to "ghcr.io/acme"
+func (p *Parser) parseNamespaceDefaultsStatement() *ast.NamespaceDefaultsStatement {
+	stmt := &ast.NamespaceDefaultsStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.FOR) {
+		return nil
+	}
+	// The pattern is lexed as separate tokens: docker . *
+	for p.peekToken.Type != lexer.COLON && p.peekToken.Line == stmt.Token.Line && p.peekToken.Type != lexer.EOF {
+		p.nextToken()
+		stmt.Pattern += p.curToken.Literal
+	}
+	namespace, rest, found := strings.Cut(stmt.Pattern, ".")
+	if !found || namespace == "" || rest == "" || strings.ContainsAny(namespace, "*") || strings.Contains(strings.TrimSuffix(rest, "*"), "*") {
+		p.addError(fmt.Sprintf("expected 'defaults for <namespace>.*' or 'defaults for <namespace>.<task>', got %q", stmt.Pattern))
+		return nil
+	}
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+
+	values, ok := p.parseSetValues("defaults for " + stmt.Pattern)
+	if !ok {
+		return nil
+	}
+	stmt.Values = values
+	if len(stmt.Values) == 0 {
+		p.addError(fmt.Sprintf("defaults for %s must set at least one parameter", stmt.Pattern))
+		return nil
+	}
+	return stmt
+}
+
 // parseSetStatement parses a set statement with two syntaxes:
 // 1. set key to "value"
 // 2. set key as list to ["value1", "value2", "value3"]
@@ -263,14 +547,52 @@ func (p *Parser) parseSetStatement() *ast.SetStatement {
 	case lexer.IDENT, lexer.MESSAGE, lexer.BRANCH, lexer.REMOTE, lexer.STATUS, lexer.LOG, lexer.COMMIT, lexer.ADD, lexer.PUSH, lexer.PULL,
 		lexer.GET, lexer.POST, lexer.PUT, lexer.DELETE, lexer.PATCH, lexer.HEAD, lexer.OPTIONS, lexer.HTTP, lexer.HTTPS, lexer.URL, lexer.API, lexer.JSON, lexer.XML,
 		lexer.TIMEOUT, lexer.RETRY, lexer.AUTH, lexer.BEARER, lexer.BASIC, lexer.TOKEN, lexer.HEADER, lexer.BODY, lexer.DATA,
-		lexer.SCALE, lexer.PORT, lexer.REGISTRY, lexer.CHECKOUT, lexer.BACKUP, lexer.CHECK, lexer.SIZE, lexer.DIRECTORY, lexer.ENVIRONMENT:
+		lexer.SCALE, lexer.PORT, lexer.REGISTRY, lexer.CHECKOUT, lexer.BACKUP, lexer.CHECK, lexer.SIZE, lexer.DIRECTORY, lexer.ENVIRONMENT, lexer.OUTPUT, lexer.STEP:
 		p.nextToken()
 	default:
-		p.addError(fmt.Sprintf("expected set key, got %s instead", p.peekToken.Type))
-		return nil
+		// Other keywords such as tag or image name parameters in profiles and defaults
+		if !p.isTaskNamePartToken(p.peekToken) || p.peekToken.Type == lexer.NUMBER {
+			p.addError(fmt.Sprintf("expected set key, got %s instead", p.peekToken.Type))
+			return nil
+		}
+		p.nextToken()
 	}
 	stmt.Key = p.curToken.Literal
 
+	// set encrypted db_password to "ENC[...]"
+	if stmt.Key == "encrypted" && p.peekToken.Type != lexer.TO && p.peekToken.Type != lexer.AS {
+		if !p.isTaskNamePartToken(p.peekToken) || p.peekToken.Type == lexer.NUMBER {
+			p.addError(fmt.Sprintf("expected setting name after 'encrypted', got %s instead", p.peekToken.Type))
+			return nil
+		}
+		p.nextToken()
+		stmt.Key = p.curToken.Literal
+		stmt.Encrypted = true
+	}
+
+	// Two-word keys: set output style to "plain", set step style to "banner", set step width to 80,
+	// set shell escaping to "strict", set retry budget to 10, set log sink to "file:./drun.log",
+	// set status symbols to "ok=✔", set status colors to "ok=blue", set lock backend to "redis://...",
+	// set max duration to "30m", set todo behavior to "skip", set network retries to 3
+	if p.curToken.Literal == "network" && p.peekToken.Type == lexer.RETRIES {
+		p.nextToken() // consume retries
+		stmt.Key += "_retries"
+	} else if p.peekToken.Type == lexer.IDENT {
+		second := p.peekToken.Literal
+		if (p.curToken.Type == lexer.OUTPUT && second == "style") ||
+			(p.curToken.Type == lexer.STEP && (second == "style" || second == "width")) ||
+			(p.curToken.Literal == "shell" && second == "escaping") ||
+			(p.curToken.Type == lexer.RETRY && second == "budget") ||
+			(p.curToken.Type == lexer.LOG && second == "sink") ||
+			(p.curToken.Type == lexer.STATUS && (second == "symbols" || second == "colors")) ||
+			(p.curToken.Literal == "lock" && second == "backend") ||
+			(p.curToken.Type == lexer.MAX && second == "duration") ||
+			(p.curToken.Literal == "todo" && second == "behavior") {
+			p.nextToken() // consume style/width
+			stmt.Key += "_" + second
+		}
+	}
+
 	// Check for optional "as list" syntax or direct "to"
 	switch p.peekToken.Type {
 	case lexer.AS:
@@ -291,6 +613,10 @@ func (p *Parser) parseSetStatement() *ast.SetStatement {
 
 	// Parse expression (string literal or array literal)
 	p.nextToken()
+	if stmt.Encrypted && p.curToken.Type != lexer.STRING {
+		p.addError(fmt.Sprintf("expected an ENC[...] string for encrypted setting %s, got %s instead", stmt.Key, p.curToken.Type))
+		return nil
+	}
 	stmt.Value = p.parseExpression()
 	if stmt.Value == nil {
 		return nil
@@ -300,6 +626,264 @@ func (p *Parser) parseSetStatement() *ast.SetStatement {
 	return stmt
 }
 
+// parsePathStatement parses a project PATH extension
+// Syntax: set path to include "dir" [and "dir"]...
+func (p *Parser) parsePathStatement() *ast.PathStatement {
+	stmt := &ast.PathStatement{Token: p.curToken}
+
+	p.nextToken() // move to 'path'
+	if !p.expectPeek(lexer.TO) {
+		return nil
+	}
+	if !p.expectPeek(lexer.INCLUDE) {
+		return nil
+	}
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Entries = append(stmt.Entries, p.curToken.Literal)
+
+	for p.peekToken.Type == lexer.AND || p.peekToken.Type == lexer.COMMA {
+		p.nextToken() // consume separator
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Entries = append(stmt.Entries, p.curToken.Literal)
+	}
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
+// parseShellEnvironmentStatement parses the inherited environment filter
+// Syntax: shell environment allow|deny ["NAME", "PREFIX_*"]
+func (p *Parser) parseShellEnvironmentStatement() *ast.ShellEnvironmentStatement {
+	stmt := &ast.ShellEnvironmentStatement{Token: p.curToken}
+
+	p.nextToken() // move to 'environment'
+	switch {
+	case p.peekToken.Type == lexer.ALLOW:
+		stmt.Mode = "allow"
+	case p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "deny":
+		stmt.Mode = "deny"
+	default:
+		p.addError(fmt.Sprintf("expected 'allow' or 'deny' after 'shell environment', got %s", p.peekToken.Type))
+		return nil
+	}
+	p.nextToken() // move to 'allow'/'deny'
+
+	if !p.expectPeek(lexer.LBRACKET) {
+		return nil
+	}
+	for p.peekToken.Type != lexer.RBRACKET {
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		pattern := strings.TrimSpace(p.curToken.Literal)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			p.addError(fmt.Sprintf("invalid environment variable pattern %q in shell environment %s", p.curToken.Literal, stmt.Mode))
+			return nil
+		}
+		stmt.Patterns = append(stmt.Patterns, pattern)
+		if p.peekToken.Type == lexer.COMMA {
+			p.nextToken()
+		}
+	}
+	p.nextToken() // move to ']'
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
+// parseCredentialHelperStatement parses
+// set credential helper for "host" to "exec:command" (or "env:VARIABLE")
+func (p *Parser) parseCredentialHelperStatement(settings []ast.ProjectSetting) *ast.CredentialHelperStatement {
+	stmt := &ast.CredentialHelperStatement{Token: p.curToken}
+
+	p.nextToken() // move to 'credential'
+	if !p.expectPeekLiteral("helper") || !p.expectPeek(lexer.FOR) || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Host = strings.ToLower(strings.TrimSpace(p.curToken.Literal))
+	if stmt.Host == "" {
+		p.addError("credential helper host must not be empty")
+		return nil
+	}
+	if !p.expectPeek(lexer.TO) || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Helper = p.curToken.Literal
+
+	kind, target, _ := strings.Cut(stmt.Helper, ":")
+	if (kind != "exec" && kind != "env") || strings.TrimSpace(target) == "" {
+		p.addError(fmt.Sprintf("credential helper for %q must be \"exec:<command>\" or \"env:<VARIABLE>\", got %q", stmt.Host, stmt.Helper))
+		return nil
+	}
+	for _, setting := range settings {
+		if existing, ok := setting.(*ast.CredentialHelperStatement); ok && existing.Host == stmt.Host {
+			p.addError(fmt.Sprintf("credential helper for %q is declared more than once", stmt.Host))
+			return nil
+		}
+	}
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
+// parseHostGroupStatement parses hosts group "name" = ["host", ...]
+func (p *Parser) parseHostGroupStatement(settings []ast.ProjectSetting) *ast.HostGroupStatement {
+	stmt := &ast.HostGroupStatement{Token: p.curToken}
+
+	if !p.expectPeekLiteral("group") || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Name = strings.TrimSpace(p.curToken.Literal)
+	if stmt.Name == "" {
+		p.addError("host group name must not be empty")
+		return nil
+	}
+	for _, setting := range settings {
+		if existing, ok := setting.(*ast.HostGroupStatement); ok && existing.Name == stmt.Name {
+			p.addError(fmt.Sprintf("host group %q is declared more than once", stmt.Name))
+			return nil
+		}
+	}
+	if !p.expectPeek(lexer.EQUALS) || !p.expectPeek(lexer.LBRACKET) {
+		return nil
+	}
+	for p.peekToken.Type != lexer.RBRACKET {
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		host := strings.TrimSpace(p.curToken.Literal)
+		if host == "" {
+			p.addError(fmt.Sprintf("host group %q has an empty host name", stmt.Name))
+			return nil
+		}
+		stmt.Hosts = append(stmt.Hosts, host)
+		if p.peekToken.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken() // consume COMMA
+	}
+	if !p.expectPeek(lexer.RBRACKET) {
+		return nil
+	}
+	if len(stmt.Hosts) == 0 {
+		p.addError(fmt.Sprintf("host group %q has no hosts", stmt.Name))
+		return nil
+	}
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
+// parseHTTPClientStatement parses a named HTTP client; its options are
+// separated by commas and run to the end of the line
+// Syntax: http client "name": base url "https://...", header "Key: value", timeout 10s
+func (p *Parser) parseHTTPClientStatement(settings []ast.ProjectSetting) *ast.HTTPClientStatement {
+	stmt := &ast.HTTPClientStatement{Token: p.curToken, Headers: make(map[string]string)}
+
+	if !p.expectPeekLiteral("client") || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Name = strings.TrimSpace(p.curToken.Literal)
+	if stmt.Name == "" {
+		p.addError("HTTP client name must not be empty")
+		return nil
+	}
+	for _, setting := range settings {
+		if existing, ok := setting.(*ast.HTTPClientStatement); ok && existing.Name == stmt.Name {
+			p.addError(fmt.Sprintf("HTTP client %q is declared more than once", stmt.Name))
+			return nil
+		}
+	}
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+
+	line := p.curToken.Line
+	for {
+		if p.peekToken.Line != line {
+			p.addErrorWithHelp(fmt.Sprintf("HTTP client %q has no options", stmt.Name),
+				`Use: http client "api": base url "https://api.example.com", timeout 10s`)
+			return nil
+		}
+		p.nextToken()
+		switch {
+		case p.curToken.Literal == "base":
+			if !p.expectPeek(lexer.URL) || !p.expectPeek(lexer.STRING) {
+				return nil
+			}
+			stmt.BaseURL = p.curToken.Literal
+		case p.curToken.Type == lexer.HEADER:
+			if !p.expectPeek(lexer.STRING) {
+				return nil
+			}
+			key, value, ok := strings.Cut(p.curToken.Literal, ":")
+			if !ok || strings.TrimSpace(key) == "" {
+				p.addError(fmt.Sprintf("expected a header written as \"Name: value\", got %q", p.curToken.Literal))
+				return nil
+			}
+			key = strings.TrimSpace(key)
+			if _, exists := stmt.Headers[key]; !exists {
+				stmt.HeaderOrder = append(stmt.HeaderOrder, key)
+			}
+			stmt.Headers[key] = strings.TrimSpace(value)
+		case p.curToken.Type == lexer.TIMEOUT:
+			stmt.Timeout = p.parsePollDuration("timeout")
+			if stmt.Timeout == "" {
+				return nil
+			}
+		default:
+			p.addErrorWithHelp(fmt.Sprintf("unknown HTTP client option %q", p.curToken.Literal),
+				"HTTP client options are base url, header and timeout")
+			return nil
+		}
+		if p.peekToken.Type != lexer.COMMA || p.peekToken.Line != line {
+			break
+		}
+		p.nextToken() // consume COMMA
+	}
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
+// parseConditionStatement parses a named condition; the expression runs to
+// the end of the line
+// Syntax: condition "name" means <condition>
+func (p *Parser) parseConditionStatement(settings []ast.ProjectSetting) *ast.ConditionStatement {
+	stmt := &ast.ConditionStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Name = strings.Join(strings.Fields(p.curToken.Literal), " ")
+	if stmt.Name == "" {
+		p.addError("condition name must not be empty")
+		return nil
+	}
+	for _, setting := range settings {
+		if existing, ok := setting.(*ast.ConditionStatement); ok && existing.Name == stmt.Name {
+			p.addError(fmt.Sprintf("condition %q is declared more than once", stmt.Name))
+			return nil
+		}
+	}
+	if !p.expectPeek(lexer.MEANS) {
+		return nil
+	}
+	stmt.Expression = p.parseUntilCondition()
+	if stmt.Expression == "" {
+		p.addErrorWithHelp(fmt.Sprintf("condition %q has no expression", stmt.Name),
+			`Use: condition "is prod" means environment is "production"`)
+		return nil
+	}
+
+	p.nextToken() // advance to next token
+	return stmt
+}
+
 // parseIncludeStatement parses an include statement
 func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
 	stmt := &ast.IncludeStatement{Token: p.curToken}
@@ -317,8 +901,29 @@ func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
 			// Expect path (identifier like ops/docker or string)
 			var drunhubPath string
 			switch p.curToken.Type {
-			case lexer.IDENT, lexer.STRING:
+			case lexer.STRING:
+				drunhubPath = p.curToken.Literal
+			case lexer.IDENT:
 				drunhubPath = p.curToken.Literal
+				for p.peekToken.Type == lexer.SLASH {
+					p.nextToken() // move to '/'
+					p.nextToken() // move to the next path segment
+					if !p.isTaskNamePartToken(p.curToken) {
+						p.addError(fmt.Sprintf("expected path segment after '/' in drunhub path, got %s", p.curToken.Type))
+						return nil
+					}
+					drunhubPath += "/" + p.curToken.Literal
+				}
+				// ops/docker@^1.2: the ref is everything written directly after '@'
+				if p.peekToken.Type == lexer.DECORATOR && p.peekToken.Literal == "@" {
+					p.nextToken() // move to '@'
+					ref := p.readAdjacentLiteral()
+					if ref == "" {
+						p.addError("expected version or ref after '@' in drunhub path")
+						return nil
+					}
+					drunhubPath += "@" + ref
+				}
 			default:
 				p.addError(fmt.Sprintf("expected path after drunhub, got %s", p.curToken.Type))
 				return nil
@@ -329,26 +934,36 @@ func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
 
 			p.nextToken()
 
-			// Check for optional "as namespace"
-			if p.curToken.Type == lexer.AS {
-				p.nextToken() // move past 'as'
+			if !p.parseIncludeNamespace(stmt) {
+				return nil
+			}
+
+			p.parseIncludeOverride(stmt)
+			return stmt
+		}
 
-				if p.curToken.Type == lexer.IDENT {
-					stmt.Namespace = p.curToken.Literal
-					p.nextToken()
-				} else {
-					p.addError(fmt.Sprintf("expected namespace identifier after 'as', got %s", p.curToken.Type))
-					return nil
-				}
+		// include from github "org/repo//lib/docker@v1"
+		if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "github" {
+			p.nextToken() // move past 'github'
+			if p.curToken.Type != lexer.STRING {
+				p.addError(fmt.Sprintf("expected quoted repository path after github, got %s", p.curToken.Type))
+				return nil
+			}
+			stmt.Path = "github:" + p.curToken.Literal
+			p.nextToken()
+
+			if !p.parseIncludeNamespace(stmt) {
+				return nil
 			}
 
+			p.parseIncludeOverride(stmt)
 			return stmt
 		}
 
 		// If not drunhub, it must be a regular FROM clause (for backward compatibility)
 		// Back up and let the regular parsing handle it
 		// This shouldn't happen in normal parsing flow, but handle it gracefully
-		p.addError("expected 'drunhub' after 'from' or use 'include snippets/templates/tasks from path'")
+		p.addError("expected 'drunhub' or 'github' after 'from' or use 'include snippets/templates/tasks from path'")
 		return nil
 	}
 
@@ -371,6 +986,13 @@ func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
 
 			p.nextToken()
 
+			// Optional symbol names: include snippets "a" as "b", "c" from "path"
+			if p.curToken.Type == lexer.STRING {
+				if !p.parseIncludeSymbols(stmt) {
+					return nil
+				}
+			}
+
 			// Check for comma (more selectors) or FROM
 			if p.curToken.Type == lexer.COMMA {
 				p.nextToken() // skip comma
@@ -387,6 +1009,12 @@ func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
 		p.nextToken()
 	}
 
+	// include tasks from member "services/api": a package of the workspace
+	if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "member" && p.peekToken.Type == lexer.STRING {
+		stmt.Member = true
+		p.nextToken()
+	}
+
 	// Expect path (string)
 	if p.curToken.Type != lexer.STRING {
 		p.addError(fmt.Sprintf("expected string path, got %s", p.curToken.Type))
@@ -396,20 +1024,89 @@ func (p *Parser) parseIncludeStatement() *ast.IncludeStatement {
 
 	p.nextToken()
 
-	// Check for optional "as namespace"
-	if p.curToken.Type == lexer.AS {
-		p.nextToken() // move past 'as'
+	if !p.parseIncludeNamespace(stmt) {
+		return nil
+	}
 
-		if p.curToken.Type == lexer.IDENT {
-			stmt.Namespace = p.curToken.Literal
+	p.parseIncludeOverride(stmt)
+	return stmt
+}
+
+// parseIncludeNamespace consumes an optional "as <namespace>". Keywords
+// such as docker or test are accepted as namespace names.
+func (p *Parser) parseIncludeNamespace(stmt *ast.IncludeStatement) bool {
+	if p.curToken.Type != lexer.AS {
+		return true
+	}
+	p.nextToken() // move past 'as'
+
+	if p.curToken.Type != lexer.IDENT && !p.isTaskNamePartToken(p.curToken) {
+		p.addError(fmt.Sprintf("expected namespace identifier after 'as', got %s", p.curToken.Type))
+		return false
+	}
+	stmt.Namespace = p.curToken.Literal
+	p.nextToken()
+	return true
+}
+
+// parseIncludeOverride consumes an optional trailing "override" on the
+// include's line, which lets the include shadow already included names
+func (p *Parser) parseIncludeOverride(stmt *ast.IncludeStatement) {
+	if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "override" && p.curToken.Line == stmt.Token.Line {
+		stmt.Override = true
+		p.nextToken()
+	}
+}
+
+// readAdjacentLiteral joins the literals of the tokens written directly
+// after the current one without whitespace, such as ^, 1.2, . and 3 in
+// "@^1.2.3", and leaves the last of them as the current token
+func (p *Parser) readAdjacentLiteral() string {
+	var literal strings.Builder
+	for p.peekToken.Line == p.curToken.Line && p.peekToken.Position == p.curToken.Position+len(p.curToken.Literal) &&
+		p.peekToken.Type != lexer.EOF && p.peekToken.Literal != "" {
+		p.nextToken()
+		literal.WriteString(p.curToken.Literal)
+	}
+	return literal.String()
+}
+
+// parseIncludeSymbols parses the quoted snippet/template names (each with an
+// optional 'as "alias"') that follow the current include selector
+func (p *Parser) parseIncludeSymbols(stmt *ast.IncludeStatement) bool {
+	kind := stmt.Selectors[len(stmt.Selectors)-1]
+	if kind == "tasks" {
+		p.addError("only snippets and templates can be included by name")
+		return false
+	}
+
+	for {
+		symbol := ast.IncludeSymbol{Kind: kind, Name: p.curToken.Literal}
+		p.nextToken()
+
+		if p.curToken.Type == lexer.AS {
+			p.nextToken() // move past 'as'
+			if p.curToken.Type != lexer.STRING {
+				p.addError(fmt.Sprintf("expected quoted name after 'as' in include, got %s", p.curToken.Type))
+				return false
+			}
+			symbol.Alias = p.curToken.Literal
 			p.nextToken()
-		} else {
-			p.addError(fmt.Sprintf("expected namespace identifier after 'as', got %s", p.curToken.Type))
-			return nil
 		}
-	}
 
-	return stmt
+		for _, existing := range stmt.Symbols {
+			if existing.Kind == kind && existing.LocalName() == symbol.LocalName() {
+				p.addError(fmt.Sprintf("include imports two %s named %q", kind, symbol.LocalName()))
+				return false
+			}
+		}
+		stmt.Symbols = append(stmt.Symbols, symbol)
+
+		if p.curToken.Type != lexer.COMMA || p.peekToken.Type != lexer.STRING {
+			return true
+		}
+		p.nextToken() // skip comma
+	}
 }
 
 // parseProjectParameterStatement parses a project-level parameter definition
@@ -788,27 +1485,49 @@ func (p *Parser) parseLifecycleHook() *ast.LifecycleHook {
 		}
 		hook.Type = p.curToken.Literal
 
-		// Expect colon
-		if !p.expectPeek(lexer.COLON) {
+		// Expect optional priority, then colon
+		if !p.parseHookPriority(hook) || !p.expectPeek(lexer.COLON) {
 			return nil
 		}
 	} else {
-		// Old syntax: "before any task:" or "after any task:"
+		// Task hooks: "before any task:", "before task "deploy":" or
+		// "after tasks matching "deploy-*":"
 		hook.Type = p.curToken.Literal // "before" or "after"
 
-		// Expect "any"
-		if !p.expectPeek(lexer.ANY) {
-			return nil
-		}
-		hook.Scope = p.curToken.Literal
+		switch p.peekToken.Type {
+		case lexer.TASK:
+			p.nextToken() // consume task
+			if !p.expectPeek(lexer.STRING) {
+				return nil
+			}
+			hook.Scope = "task"
+			hook.Target = p.curToken.Literal
+		case lexer.TASKS:
+			p.nextToken() // consume tasks
+			if !p.expectPeek(lexer.MATCHING) || !p.expectPeek(lexer.STRING) {
+				return nil
+			}
+			if _, err := path.Match(p.curToken.Literal, ""); err != nil {
+				p.addError(fmt.Sprintf("invalid task pattern %q in %s hook: %v", p.curToken.Literal, hook.Type, err))
+				return nil
+			}
+			hook.Scope = "matching"
+			hook.Target = p.curToken.Literal
+		default:
+			// Expect "any"
+			if !p.expectPeek(lexer.ANY) {
+				return nil
+			}
+			hook.Scope = p.curToken.Literal
 
-		// Expect "task"
-		if !p.expectPeek(lexer.TASK) {
-			return nil
+			// Expect "task"
+			if !p.expectPeek(lexer.TASK) {
+				return nil
+			}
 		}
 
-		// Expect colon
-		if !p.expectPeek(lexer.COLON) {
+		// Expect optional priority, then colon
+		if !p.parseHookPriority(hook) || !p.expectPeek(lexer.COLON) {
 			return nil
 		}
 	}
@@ -853,7 +1572,7 @@ func (p *Parser) parseLifecycleHook() *ast.LifecycleHook {
 				// Special handling for RUN token - check context
 				if p.curToken.Type == lexer.RUN {
 					// Look ahead to determine if this is shell or docker command
-					if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.COLON || p.peekToken.Type == lexer.IN {
+					if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.COLON || p.peekToken.Type == lexer.IN || p.peekIsShellModifier() {
 						// This is "run 'command'" or "run:" - shell command
 						shell := p.parseShellStatement()
 						if shell != nil {
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -34,6 +36,24 @@ func (p *Parser) parseShellStatement() *ast.ShellStatement {
 		stmt.ServiceNameIsLiteral = isLiteral
 	}
 
+	// Optional modifiers for multiline blocks: run quietly logging to "file":
+	if stmt.Action != "capture" && p.peekIsShellModifier() {
+		if !p.parseShellModifiers(stmt) {
+			return nil
+		}
+		if stmt.Attached {
+			p.addError(fmt.Sprintf("%s modifier is only supported for single-line run statements", stmt.AttachedModifier()))
+			return nil
+		}
+		if p.peekToken.Type != lexer.COLON {
+			p.addErrorWithHelpAtPeek(
+				fmt.Sprintf("expected ':' after shell modifiers, got %s instead", p.peekToken.Type),
+				fmt.Sprintf("Single-line commands put modifiers after the command. Example: %s \"make build\" quietly", stmt.Action),
+			)
+			return nil
+		}
+	}
+
 	// Check if this is multiline syntax (action followed by colon or capture with "as")
 	if p.peekToken.Type == lexer.COLON {
 		return p.parseMultilineShellStatement(stmt)
@@ -60,13 +80,27 @@ func (p *Parser) parseShellStatement() *ast.ShellStatement {
 	p.nextToken() // consume STRING
 
 	stmt.Command = p.curToken.Literal
-	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "attached" {
+	for p.peekToken.Type == lexer.PIPE {
 		if stmt.Action != "run" {
-			p.addError("attached modifier is only supported for run statements")
+			p.addError(fmt.Sprintf("only run statements can be piped, got %s", stmt.Action))
 			return nil
 		}
-		p.nextToken() // consume attached
-		stmt.Attached = true
+		p.nextToken() // consume |
+		if !p.expectPeek(lexer.RUN) || !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.PipeInto = append(stmt.PipeInto, p.curToken.Literal)
+	}
+	if stmt.Action == "run" && p.peekToken.Type == lexer.ON {
+		if !p.parseShellHosts(stmt) {
+			return nil
+		}
+	}
+	if !p.parseShellPipelineModifiers(stmt) {
+		return nil
+	}
+	if (stmt.Host != "" || stmt.HostGroup != "") && !p.validateRemoteShell(stmt) {
+		return nil
 	}
 
 	// Set streaming behavior based on action type
@@ -82,6 +116,145 @@ func (p *Parser) parseShellStatement() *ast.ShellStatement {
 	return stmt
 }
 
+// parsePipeStatement parses the long form of a run pipeline
+// Syntax: pipe output of "producer" into "consumer" [into "consumer" ...] [modifiers]
+func (p *Parser) parsePipeStatement() *ast.ShellStatement {
+	stmt := &ast.ShellStatement{Token: p.curToken, Action: "run", StreamOutput: true}
+
+	if !p.expectPeek(lexer.OUTPUT) || !p.expectPeek(lexer.OF) || !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Command = p.curToken.Literal
+
+	if !p.expectPeek(lexer.INTO) {
+		return nil
+	}
+	for p.curToken.Type == lexer.INTO {
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.PipeInto = append(stmt.PipeInto, p.curToken.Literal)
+		if p.peekToken.Type == lexer.INTO {
+			p.nextToken()
+		}
+	}
+
+	if !p.parseShellPipelineModifiers(stmt) {
+		return nil
+	}
+	return stmt
+}
+
+// isPipeStatementStart reports whether the current token begins a
+// "pipe output of" statement
+func (p *Parser) isPipeStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "pipe" && p.peekToken.Type == lexer.OUTPUT
+}
+
+// parseShellPipelineModifiers parses the trailing modifiers of a single-line
+// shell statement, which apply to the whole pipeline when it is piped
+func (p *Parser) parseShellPipelineModifiers(stmt *ast.ShellStatement) bool {
+	if !p.parseShellModifiers(stmt) {
+		return false
+	}
+	if p.peekToken.Type == lexer.PIPE {
+		p.addError("modifiers of a piped run go after its last command")
+		return false
+	}
+	if len(stmt.PipeInto) > 0 && stmt.Attached {
+		p.addError(fmt.Sprintf("%s modifier cannot be used with piped commands", stmt.AttachedModifier()))
+		return false
+	}
+	return true
+}
+
+// parseShellHosts parses where a run statement runs over ssh:
+// on host "web-1", or on group "web" [in parallel [with N workers]]
+func (p *Parser) parseShellHosts(stmt *ast.ShellStatement) bool {
+	p.nextToken() // consume ON
+	switch {
+	case p.peekToken.Type == lexer.HOST:
+		p.nextToken()
+		if !p.expectPeek(lexer.STRING) {
+			return false
+		}
+		stmt.Host = p.curToken.Literal
+	case p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "group":
+		p.nextToken()
+		if !p.expectPeek(lexer.STRING) {
+			return false
+		}
+		stmt.HostGroup = p.curToken.Literal
+	default:
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected 'host' or 'group' after 'on', got %s instead", p.peekToken.Literal),
+			`Use: run "uptime" on group "web" in parallel, with the group declared as hosts group "web" = ["web-1", "web-2"]`,
+		)
+		return false
+	}
+	if strings.TrimSpace(stmt.Host+stmt.HostGroup) == "" {
+		p.addError("the host or host group of a run statement must not be empty")
+		return false
+	}
+
+	if p.peekToken.Type != lexer.IN {
+		return true
+	}
+	p.nextToken() // consume IN
+	if !p.expectPeek(lexer.PARALLEL) {
+		return false
+	}
+	if stmt.Host != "" {
+		p.addError("in parallel needs a host group; a single host runs one command")
+		return false
+	}
+	stmt.HostParallel = true
+	if p.peekToken.Type != lexer.WITH {
+		return true
+	}
+	p.nextToken() // consume WITH
+	if !p.expectPeek(lexer.NUMBER) {
+		return false
+	}
+	workers, err := strconv.Atoi(p.curToken.Literal)
+	if err != nil || workers < 1 {
+		p.addError(fmt.Sprintf("invalid worker count %q: expected a whole number of at least 1", p.curToken.Literal))
+		return false
+	}
+	if p.peekToken.Type != lexer.IDENT || (p.peekToken.Literal != "workers" && p.peekToken.Literal != "worker") {
+		p.addError(fmt.Sprintf("expected 'workers' after 'with %d', got %s instead", workers, p.peekToken.Literal))
+		return false
+	}
+	p.nextToken() // consume workers
+	stmt.HostWorkers = workers
+	return true
+}
+
+// validateRemoteShell rejects what a command run over ssh cannot do
+func (p *Parser) validateRemoteShell(stmt *ast.ShellStatement) bool {
+	var conflict string
+	switch {
+	case stmt.ServiceScoped:
+		conflict = "in service"
+	case len(stmt.PipeInto) > 0:
+		conflict = "piped commands"
+	case stmt.Attached:
+		conflict = stmt.AttachedModifier()
+	case stmt.Log != nil:
+		conflict = "logging"
+	case len(stmt.ExitCodes) > 0:
+		conflict = "mapping exit codes"
+	case stmt.RunAs != "":
+		conflict = "running as another user"
+	case len(stmt.LineFilters) > 0:
+		conflict = "line filters"
+	default:
+		return true
+	}
+	p.addError(fmt.Sprintf("a run statement on remote hosts cannot be combined with %s", conflict))
+	return false
+}
+
 // parseMultilineShellStatement parses multiline shell commands (run:, exec:, shell:, capture as $var:)
 func (p *Parser) parseMultilineShellStatement(stmt *ast.ShellStatement) *ast.ShellStatement {
 	// Handle capture with "as variable" syntax
@@ -146,6 +319,9 @@ func (p *Parser) parseCaptureStatement(stmt *ast.ShellStatement) *ast.ShellState
 	}
 
 	stmt.Command = p.curToken.Literal
+	if !p.parseLineFilters(&stmt.LineFilters) {
+		return nil
+	}
 
 	// Check for capture syntax: capture "command" as variable_name
 	if p.peekToken.Type == lexer.AS {
@@ -154,6 +330,9 @@ func (p *Parser) parseCaptureStatement(stmt *ast.ShellStatement) *ast.ShellState
 			return nil
 		}
 		stmt.CaptureVar = p.getVariableName()
+		if !p.parseLineFilters(&stmt.LineFilters) {
+			return nil
+		}
 	}
 
 	stmt.StreamOutput = false
@@ -216,6 +395,297 @@ func (p *Parser) readCommandTokens() []string {
 	return filteredCommands
 }
 
+// peekIsShellModifier reports whether the next token is a shell statement modifier
+func (p *Parser) peekIsShellModifier() bool {
+	if p.peekToken.Type == lexer.AS {
+		return true // as root, as user "deploy"
+	}
+	if p.peekToken.Type != lexer.IDENT {
+		return false
+	}
+	switch p.peekToken.Literal {
+	case "attached", "interactively", "quietly", "verbosely", "logging", "mapping", "failing",
+		"filtering", "keeping", "dropping":
+		return true
+	}
+	return false
+}
+
+// peekIsLineFilter reports whether the next token begins a line filter
+func (p *Parser) peekIsLineFilter() bool {
+	if p.peekToken.Type != lexer.IDENT {
+		return false
+	}
+	switch p.peekToken.Literal {
+	case "filtering", "keeping", "dropping":
+		return true
+	}
+	return false
+}
+
+// parseLineFilters parses the line filters that follow, in order
+func (p *Parser) parseLineFilters(filters *[]ast.LineFilter) bool {
+	for p.peekIsLineFilter() {
+		p.nextToken() // consume the verb
+		filter, ok := p.parseLineFilter()
+		if !ok {
+			return false
+		}
+		*filters = append(*filters, filter)
+	}
+	return true
+}
+
+// parseLineFilter parses a line filter; the current token is its verb
+// Syntax: filtering | keeping | dropping lines matching "regexp" | containing "text"
+func (p *Parser) parseLineFilter() (ast.LineFilter, bool) {
+	filter := ast.LineFilter{Verb: p.curToken.Literal}
+	if !p.expectPeekLiteral("lines") {
+		return filter, false
+	}
+	switch {
+	case p.peekToken.Type == lexer.MATCHING:
+		filter.Regexp = true
+	case p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "containing":
+	default:
+		p.addErrorWithHelpAtPeek(
+			fmt.Sprintf("expected 'matching' or 'containing' after '%s lines', got %s instead", filter.Verb, p.peekToken.Type),
+			fmt.Sprintf("Example: %s lines containing \"ERROR\", or %s lines matching \"^app:\"", filter.Verb, filter.Verb),
+		)
+		return filter, false
+	}
+	p.nextToken() // consume matching or containing
+	if !p.expectPeek(lexer.STRING) {
+		return filter, false
+	}
+	filter.Pattern = p.curToken.Literal
+	if filter.Pattern == "" {
+		p.addError(fmt.Sprintf("%s lines requires a non-empty pattern", filter.Verb))
+		return filter, false
+	}
+	// Patterns with interpolation are checked once they are interpolated
+	if filter.Regexp && !strings.Contains(filter.Pattern, "{") {
+		if _, err := regexp.Compile(filter.Pattern); err != nil {
+			p.addError(fmt.Sprintf("invalid regular expression in '%s lines matching': %v", filter.Verb, err))
+			return filter, false
+		}
+	}
+	return filter, true
+}
+
+// parseShellModifiers parses trailing shell modifiers in any order:
+// attached | interactively, quietly, verbosely, logging to "file" [appending | keeping N],
+// mapping exit code N to "label" [and exit code N to "label" ...] [as $var], failing with "message",
+// as root | as user "name", filtering | keeping | dropping lines matching "re" | containing "text"
+func (p *Parser) parseShellModifiers(stmt *ast.ShellStatement) bool {
+	for p.peekIsShellModifier() {
+		p.nextToken() // consume modifier
+		switch p.curToken.Literal {
+		case "attached", "interactively":
+			if stmt.Action != "run" {
+				p.addError(fmt.Sprintf("%s modifier is only supported for run statements", p.curToken.Literal))
+				return false
+			}
+			interactive := p.curToken.Literal == "interactively"
+			if stmt.Attached && stmt.Interactive != interactive {
+				p.addError("attached and interactively cannot be combined on the same statement")
+				return false
+			}
+			stmt.Attached = true
+			stmt.Interactive = interactive
+		case "quietly", "verbosely":
+			verbosity := strings.TrimSuffix(p.curToken.Literal, "ly")
+			if stmt.Verbosity != "" && stmt.Verbosity != verbosity {
+				p.addError("quietly and verbosely cannot be combined on the same statement")
+				return false
+			}
+			stmt.Verbosity = verbosity
+		case "logging":
+			stmt.Log = p.parseLogTarget(&stmt.LineFilters)
+			if stmt.Log == nil {
+				return false
+			}
+		case "filtering", "keeping", "dropping":
+			filter, ok := p.parseLineFilter()
+			if !ok {
+				return false
+			}
+			stmt.LineFilters = append(stmt.LineFilters, filter)
+		case "mapping":
+			if !p.parseExitCodeMappings(stmt) {
+				return false
+			}
+		case "failing":
+			if stmt.FailureMessage != "" {
+				p.addError("failing with is declared more than once on the same statement")
+				return false
+			}
+			if !p.expectPeek(lexer.WITH) || !p.expectPeek(lexer.STRING) {
+				return false
+			}
+			if p.curToken.Literal == "" {
+				p.addError("failing with requires a non-empty message")
+				return false
+			}
+			stmt.FailureMessage = p.curToken.Literal
+		case "as":
+			if !p.parseRunAs(stmt) {
+				return false
+			}
+		}
+	}
+
+	if stmt.Attached && stmt.RunAs != "" {
+		p.addError(fmt.Sprintf("%s modifier cannot be combined with running as another user", stmt.AttachedModifier()))
+		return false
+	}
+
+	if stmt.Attached && stmt.Log != nil {
+		p.addError(fmt.Sprintf("logging modifier cannot be combined with %[1]s (%[1]s output goes straight to the terminal)", stmt.AttachedModifier()))
+		return false
+	}
+	if stmt.Attached && len(stmt.LineFilters) > 0 {
+		p.addError(fmt.Sprintf("line filters cannot be combined with %[1]s (%[1]s output goes straight to the terminal)", stmt.AttachedModifier()))
+		return false
+	}
+	if stmt.Attached && stmt.Verbosity == "quiet" {
+		p.addError(fmt.Sprintf("quietly modifier cannot be combined with %[1]s (%[1]s output goes straight to the terminal)", stmt.AttachedModifier()))
+		return false
+	}
+	return true
+}
+
+// parseRunAs parses the user after 'as': root, or user "name"
+func (p *Parser) parseRunAs(stmt *ast.ShellStatement) bool {
+	if stmt.RunAs != "" {
+		p.addError("the user to run as is declared more than once on the same statement")
+		return false
+	}
+	switch {
+	case p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "root":
+		p.nextToken()
+		stmt.RunAs = "root"
+	case p.peekToken.Type == lexer.USER:
+		p.nextToken()
+		if !p.expectPeek(lexer.STRING) {
+			return false
+		}
+		if p.curToken.Literal == "" {
+			p.addError("as user requires a non-empty user name")
+			return false
+		}
+		stmt.RunAs = p.curToken.Literal
+	default:
+		p.addError(fmt.Sprintf("expected 'root' or 'user \"name\"' after 'as', got %s instead", p.peekToken.Type))
+		return false
+	}
+	return true
+}
+
+// parseExitCodeMappings parses the exit codes of a mapping clause
+// Syntax: exit code N to "label" [and exit code N to "label" ...] [as $var]
+func (p *Parser) parseExitCodeMappings(stmt *ast.ShellStatement) bool {
+	for {
+		if !p.expectPeekLiteral("exit") || !p.expectPeekLiteral("code") || !p.expectPeek(lexer.NUMBER) {
+			return false
+		}
+		code, err := strconv.Atoi(p.curToken.Literal)
+		if err != nil || code < 0 || code > 255 {
+			p.addError(fmt.Sprintf("exit code must be a whole number from 0 to 255, got %s", p.curToken.Literal))
+			return false
+		}
+		for _, mapping := range stmt.ExitCodes {
+			if mapping.Code == code {
+				p.addError(fmt.Sprintf("exit code %d is mapped more than once", code))
+				return false
+			}
+		}
+		if !p.expectPeek(lexer.TO) || !p.expectPeek(lexer.STRING) {
+			return false
+		}
+		stmt.ExitCodes = append(stmt.ExitCodes, ast.ExitCodeMapping{Code: code, Label: p.curToken.Literal})
+
+		if p.peekToken.Type != lexer.AND {
+			break
+		}
+		p.nextToken() // consume AND
+	}
+
+	if p.peekToken.Type == lexer.AS {
+		if stmt.ExitStateVar != "" {
+			p.addError("the outcome of mapped exit codes can only be stored in one variable")
+			return false
+		}
+		p.nextToken() // consume AS
+		if p.peekToken.Type == lexer.USER || (p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "root") {
+			return p.parseRunAs(stmt) // mapping exit code 1 to "x" as root
+		}
+		if !p.expectPeekVariableName() {
+			return false
+		}
+		stmt.ExitStateVar = p.getVariableName()
+	}
+	return true
+}
+
+// parseLogTarget parses the destination of a log clause. On a shell
+// statement, a line filter may follow it: filters receives a `keeping lines
+// ...` that is not the number of logs to keep.
+// Syntax: to "path" [appending | keeping N]
+func (p *Parser) parseLogTarget(filters *[]ast.LineFilter) *ast.LogTarget {
+	if !p.expectPeek(lexer.TO) {
+		return nil
+	}
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	target := &ast.LogTarget{Path: p.curToken.Literal}
+
+	if p.peekToken.Type != lexer.IDENT {
+		return target
+	}
+	switch p.peekToken.Literal {
+	case "appending":
+		p.nextToken() // consume appending
+		target.Append = true
+	case "keeping":
+		p.nextToken() // consume keeping
+		if filters != nil && p.peekToken.Literal == "lines" {
+			filter, ok := p.parseLineFilter()
+			if !ok {
+				return nil
+			}
+			*filters = append(*filters, filter)
+			return target
+		}
+		if !p.expectPeek(lexer.NUMBER) {
+			return nil
+		}
+		keep, err := strconv.Atoi(p.curToken.Literal)
+		if err != nil || keep < 1 {
+			p.addError(fmt.Sprintf("keeping expects a positive whole number of rotated logs, got %s", p.curToken.Literal))
+			return nil
+		}
+		target.Keep = keep
+	}
+	return target
+}
+
+// parseLogOutputStatement parses a task-level output log
+// Syntax: log output to "path" [appending | keeping N]
+func (p *Parser) parseLogOutputStatement() *ast.LogOutputStatement {
+	stmt := &ast.LogOutputStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.OUTPUT) {
+		return nil
+	}
+	stmt.Target = p.parseLogTarget(nil)
+	if stmt.Target == nil {
+		return nil
+	}
+	return stmt
+}
+
 // parseServiceReference parses a service name reference, allowing literals or variables
 func (p *Parser) parseServiceReference() (string, bool, bool) {
 	switch p.peekToken.Type {
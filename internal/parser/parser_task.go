@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -9,6 +11,8 @@ import (
 
 func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 	stmt := &ast.TaskStatement{Token: p.curToken}
+	p.taskParameters = map[string]bool{}
+	defer func() { p.taskParameters = nil }()
 
 	// Expect task name as a quoted string
 	if p.peekToken.Type != lexer.STRING {
@@ -32,6 +36,19 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 		stmt.Mode = p.curToken.Literal
 	}
 
+	// Check for optional container clause: task "build" runs in container "golang:1.22":
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "runs" {
+		p.nextToken() // consume runs
+		if !p.expectPeek(lexer.IN) || !p.expectPeek(lexer.CONTAINER) || !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		if p.curToken.Literal == "" {
+			p.addError(fmt.Sprintf("task '%s' has an empty container image", stmt.Name))
+			return nil
+		}
+		stmt.Container = p.curToken.Literal
+	}
+
 	// Check for optional "means" clause
 	if p.peekToken.Type == lexer.MEANS {
 		p.nextToken() // consume lexer.MEANS
@@ -43,6 +60,12 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 		stmt.Description = p.curToken.Literal
 	}
 
+	// Check for optional default marker: task "build" default:
+	if p.peekToken.Type == lexer.DEFAULT_KW {
+		p.nextToken() // consume default
+		stmt.Default = true
+	}
+
 	// Expect colon at end of task declaration
 	if p.peekToken.Type != lexer.COLON {
 		// Special error message pointing to end of current line, not next line
@@ -108,6 +131,7 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 			break
 		}
 
+		bodyLen := len(stmt.Body)
 		if p.isDependencyToken(p.curToken.Type) {
 			dep := p.parseDependencyStatement()
 			if dep != nil {
@@ -124,8 +148,73 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 				param := p.parseParameterStatement()
 				if param != nil {
 					stmt.Parameters = append(stmt.Parameters, *param)
+					p.taskParameters[param.Name] = true
 				}
 			}
+		} else if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "doc" && p.peekToken.Type == lexer.COLON {
+			p.parseDocBlock(stmt)
+		} else if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "sources" && p.peekToken.Type == lexer.STRING {
+			p.parseTaskSources(stmt)
+		} else if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "once" && p.peekToken.Literal == "per" {
+			p.parseOncePerCommit(stmt)
+		} else if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "needs" && p.peekToken.Type == lexer.NUMBER {
+			p.parseResourceNeeds(stmt)
+		} else if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "needs" && p.peekToken.Type == lexer.OUTPUT {
+			p.parseOutputNeed(stmt)
+		} else if p.curToken.Type == lexer.IDENT && p.curToken.Literal == "outputs" && p.peekToken.Type == lexer.STRING {
+			p.parseTaskOutputs(stmt)
+		} else if p.curToken.Type == lexer.LOG && p.peekToken.Type == lexer.OUTPUT {
+			logOutput := p.parseLogOutputStatement()
+			if logOutput != nil {
+				stmt.Body = append(stmt.Body, logOutput)
+			}
+		} else if p.isPipeStatementStart() {
+			pipe := p.parsePipeStatement()
+			if pipe != nil {
+				stmt.Body = append(stmt.Body, pipe)
+			}
+		} else if p.isNotifyStatementStart() {
+			notify := p.parseNotifyStatement()
+			if notify != nil {
+				stmt.Body = append(stmt.Body, notify)
+			}
+		} else if p.isAssertStatementStart() {
+			assert := p.parseAssertStatement()
+			if assert != nil {
+				stmt.Body = append(stmt.Body, assert)
+			}
+		} else if p.isWithinStatementStart() {
+			within := p.parseWithinStatement()
+			if within != nil {
+				stmt.Body = append(stmt.Body, within)
+			}
+		} else if p.isLockStatementStart() {
+			lock := p.parseLockStatement()
+			if lock != nil {
+				stmt.Body = append(stmt.Body, lock)
+			}
+		} else if p.isPluginStatementStart() {
+			plugin := p.parsePluginStatement()
+			if plugin != nil {
+				stmt.Body = append(stmt.Body, plugin)
+			}
+		} else if p.isCompareStatementStart() {
+			compare := p.parseCompareStatement()
+			if compare != nil {
+				stmt.Body = append(stmt.Body, compare)
+			}
+		} else if p.isVersionFilesStatementStart() {
+			versionFiles := p.parseVersionFilesStatement()
+			if versionFiles != nil {
+				stmt.Body = append(stmt.Body, versionFiles)
+			}
+		} else if p.isConfirmStatementStart() {
+			confirm := p.parseConfirmStatement()
+			if confirm != nil {
+				stmt.Body = append(stmt.Body, confirm)
+			}
+		} else if p.isTodoStatementStart() {
+			stmt.Body = append(stmt.Body, p.parseTodoStatement())
 		} else if p.isDetectionToken(p.curToken.Type) && p.isDetectionContext() {
 			detection := p.parseDetectionStatement()
 			if detection != nil {
@@ -150,7 +239,7 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 			// Special handling for RUN token - check context
 			if p.curToken.Type == lexer.RUN {
 				// Look ahead to determine if this is shell or docker command
-				if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.COLON || p.peekToken.Type == lexer.IN {
+				if p.peekToken.Type == lexer.STRING || p.peekToken.Type == lexer.COLON || p.peekToken.Type == lexer.IN || p.peekIsShellModifier() {
 					// This is "run 'command'" or "run:" - shell command
 					shell := p.parseShellStatement()
 					if shell != nil {
@@ -333,6 +422,7 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 			p.addError(fmt.Sprintf("unexpected token in task body: %s (peek: %s) at line %d, column %d", p.curToken.Type, p.peekToken.Type, p.curToken.Line, p.curToken.Column))
 			break // Stop parsing on unexpected token
 		}
+		stmt.Body = p.guardLastStatement(stmt.Body, bodyLen)
 	}
 
 	// Consume lexer.DEDENT
@@ -344,11 +434,196 @@ func (p *Parser) parseTaskStatement() *ast.TaskStatement {
 	return stmt
 }
 
+// parseDocBlock parses a task documentation block; its indented lines are
+// read verbatim as markdown rather than tokenized
+// Syntax: doc:
+//
+//	<markdown>
+func (p *Parser) parseDocBlock(task *ast.TaskStatement) {
+	if task.Doc != "" {
+		p.addError(fmt.Sprintf("task '%s' has more than one doc block", task.Name))
+	}
+
+	// The colon has been read by the lexer as the peek token; the markdown
+	// starts on the next line
+	doc, ok := p.lexer.ReadRawBlock()
+	p.nextToken() // move to COLON, reading the first token after the block
+	if !ok {
+		p.addErrorWithHelp(
+			"unexpected content after 'doc:'",
+			"Write the documentation as indented markdown lines below 'doc:'",
+		)
+		return
+	}
+	if doc == "" {
+		p.addErrorWithHelp("doc block is empty", "Add indented markdown lines below 'doc:'")
+		return
+	}
+	task.Doc = doc
+}
+
+// parseTaskSources parses the globs of the files a task depends on, used to
+// select the tasks affected by a change
+// Syntax: sources "src/**/*.go", "go.mod"
+func (p *Parser) parseTaskSources(task *ast.TaskStatement) {
+	if len(task.Sources) > 0 {
+		p.addError(fmt.Sprintf("task '%s' declares sources more than once", task.Name))
+	}
+
+	var sources []string
+	for p.peekToken.Type == lexer.STRING {
+		p.nextToken()
+		if p.curToken.Literal == "" {
+			p.addError("source glob cannot be empty")
+		}
+		sources = append(sources, p.curToken.Literal)
+
+		if p.peekToken.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken() // consume COMMA
+		if p.peekToken.Type != lexer.STRING {
+			p.addError(fmt.Sprintf("expected source glob after ',', got %s instead", p.peekToken.Type))
+			return
+		}
+	}
+	task.Sources = sources
+}
+
+// parseOncePerCommit parses the declaration that skips a task when it already
+// succeeded for the current git commit and parameters
+// Syntax: once per commit
+func (p *Parser) parseOncePerCommit(task *ast.TaskStatement) {
+	p.nextToken() // move to "per"
+	if !p.expectPeek(lexer.COMMIT) {
+		return
+	}
+	if task.Once {
+		p.addError(fmt.Sprintf("task '%s' declares once per commit more than once", task.Name))
+	}
+	task.Once = true
+}
+
+// memoryUnits are the sizes of the units a task's memory needs are given in
+var memoryUnits = map[string]int64{"B": 1, "KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30, "TB": 1 << 40}
+
+// parseResourceNeeds parses the resources a task needs while it runs, which
+// concurrent targets and parallel loop items are scheduled by
+// Syntax: needs <n> cpu[s] [and <n><unit> memory]
+func (p *Parser) parseResourceNeeds(task *ast.TaskStatement) {
+	if task.CPUs > 0 || task.Memory > 0 {
+		p.addError(fmt.Sprintf("task '%s' declares its resource needs more than once", task.Name))
+	}
+	for {
+		if !p.expectPeek(lexer.NUMBER) {
+			return
+		}
+		number, err := strconv.ParseFloat(p.curToken.Literal, 64)
+		if err != nil || number <= 0 {
+			p.addError(fmt.Sprintf("invalid amount %q in needs: expected a positive number", p.curToken.Literal))
+			return
+		}
+
+		switch {
+		case p.peekToken.Type == lexer.CPU || (p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "cpus"):
+			p.nextToken()
+			task.CPUs = number
+		case p.peekToken.Type == lexer.IDENT && memoryUnits[strings.ToUpper(p.peekToken.Literal)] > 0:
+			p.nextToken()
+			size := memoryUnits[strings.ToUpper(p.curToken.Literal)]
+			if !p.expectPeek(lexer.MEMORY) {
+				return
+			}
+			task.Memory = int64(number * float64(size))
+		default:
+			p.addErrorWithHelpAtPeek(
+				fmt.Sprintf("expected cpus or a memory size after %s in needs, got %s", p.curToken.Literal, p.peekToken.Literal),
+				"Declare resources like: needs 2 cpus and 4GB memory",
+			)
+			return
+		}
+
+		if p.peekToken.Type != lexer.AND {
+			return
+		}
+		p.nextToken() // consume "and"
+	}
+}
+
+// parseTaskOutputs parses the variables a task hands to the tasks that need
+// them
+// Syntax: outputs "image_tag", "digest"
+func (p *Parser) parseTaskOutputs(task *ast.TaskStatement) {
+	if len(task.Outputs) > 0 {
+		p.addError(fmt.Sprintf("task '%s' declares outputs more than once", task.Name))
+	}
+
+	var outputs []string
+	for p.peekToken.Type == lexer.STRING {
+		p.nextToken()
+		output := strings.TrimPrefix(p.curToken.Literal, "$")
+		if output == "" {
+			p.addError("output name cannot be empty")
+		}
+		outputs = append(outputs, output)
+
+		if p.peekToken.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken() // consume COMMA
+		if p.peekToken.Type != lexer.STRING {
+			p.addError(fmt.Sprintf("expected output name after ',', got %s instead", p.peekToken.Type))
+			return
+		}
+	}
+	task.Outputs = outputs
+}
+
+// parseOutputNeed parses a data dependency on an output of another task,
+// which makes that task a dependency
+// Syntax: needs output "image_tag" from task "build" [as $tag]
+func (p *Parser) parseOutputNeed(task *ast.TaskStatement) {
+	const help = `Declare a data dependency like: needs output "image_tag" from task "build"`
+	p.nextToken() // move to "output"
+	if !p.expectPeek(lexer.STRING) {
+		return
+	}
+	need := ast.OutputNeed{Output: strings.TrimPrefix(p.curToken.Literal, "$")}
+	if p.peekToken.Type != lexer.FROM {
+		p.addErrorWithHelpAtPeek(fmt.Sprintf("expected 'from task' after the output name, got %s instead", p.peekToken.Literal), help)
+		return
+	}
+	p.nextToken() // consume FROM
+	if !p.expectPeek(lexer.TASK) || !p.expectPeek(lexer.STRING) {
+		return
+	}
+	need.Task = p.curToken.Literal
+	if p.peekToken.Type == lexer.AS {
+		p.nextToken() // consume AS
+		if !p.expectPeekVariableName() {
+			return
+		}
+		need.As = p.getVariableName()
+	}
+
+	if need.Output == "" || need.Task == "" {
+		p.addErrorWithHelp("needs output requires an output name and a task name", help)
+		return
+	}
+	if need.Task == task.Name {
+		p.addError(fmt.Sprintf("task '%s' cannot need its own output '%s'", task.Name, need.Output))
+		return
+	}
+	task.OutputNeeds = append(task.OutputNeeds, need)
+}
+
 // parseTaskOrTemplateInstance determines if this is a regular task or a task from template
 // parseTaskTemplateStatement parses a template task definition
 // Syntax: template task "name": <parameters and body>
 func (p *Parser) parseTaskTemplateStatement() *ast.TaskTemplateStatement {
 	stmt := &ast.TaskTemplateStatement{Token: p.curToken}
+	p.taskParameters = map[string]bool{}
+	defer func() { p.taskParameters = nil }()
 
 	// Expect "task"
 	if !p.expectPeek(lexer.TASK) {
@@ -398,14 +673,17 @@ func (p *Parser) parseTaskTemplateStatement() *ast.TaskTemplateStatement {
 			param := p.parseParameterStatement()
 			if param != nil {
 				stmt.Parameters = append(stmt.Parameters, *param)
+				p.taskParameters[param.Name] = true
 			}
 		} else {
 			// Parse regular statements (delegate to existing statement parsing)
 			// For now, we'll just collect the body statements
+			bodyLen := len(stmt.Body)
 			bodyStmt := p.parseStatementInTaskBody()
 			if bodyStmt != nil {
 				stmt.Body = append(stmt.Body, bodyStmt)
 			}
+			stmt.Body = p.guardLastStatement(stmt.Body, bodyLen)
 		}
 	}
 
@@ -447,12 +725,87 @@ func (p *Parser) parseStatementInTaskBody() ast.Statement {
 		}
 	}
 
+	if p.curToken.Type == lexer.LOG && p.peekToken.Type == lexer.OUTPUT {
+		if logOutput := p.parseLogOutputStatement(); logOutput != nil {
+			return logOutput
+		}
+		return nil
+	}
+
+	if p.isPipeStatementStart() {
+		if pipe := p.parsePipeStatement(); pipe != nil {
+			return pipe
+		}
+		return nil
+	}
+
+	if p.isNotifyStatementStart() {
+		if notify := p.parseNotifyStatement(); notify != nil {
+			return notify
+		}
+		return nil
+	}
+
+	if p.isAssertStatementStart() {
+		if assert := p.parseAssertStatement(); assert != nil {
+			return assert
+		}
+		return nil
+	}
+
+	if p.isWithinStatementStart() {
+		if within := p.parseWithinStatement(); within != nil {
+			return within
+		}
+		return nil
+	}
+
+	if p.isLockStatementStart() {
+		if lock := p.parseLockStatement(); lock != nil {
+			return lock
+		}
+		return nil
+	}
+
+	if p.isPluginStatementStart() {
+		return p.parsePluginStatement()
+	}
+
+	if p.isCompareStatementStart() {
+		if compare := p.parseCompareStatement(); compare != nil {
+			return compare
+		}
+		return nil
+	}
+
+	if p.isVersionFilesStatementStart() {
+		if versionFiles := p.parseVersionFilesStatement(); versionFiles != nil {
+			return versionFiles
+		}
+		return nil
+	}
+
+	if p.isConfirmStatementStart() {
+		if confirm := p.parseConfirmStatement(); confirm != nil {
+			return confirm
+		}
+		return nil
+	}
+
+	if p.isTodoStatementStart() {
+		return p.parseTodoStatement()
+	}
+
 	// Handle control flow and statement keywords
 	switch p.curToken.Type {
 	case lexer.IF:
 		return p.parseIfStatement()
 	case lexer.FOR:
 		return p.parseForStatement()
+	case lexer.WHILE:
+		return p.parseWhileStatement()
+	case lexer.POLL:
+		return p.parsePollStatement()
 	case lexer.WHEN:
 		return p.parseWhenStatement()
 	case lexer.CALL:
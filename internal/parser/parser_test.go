@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -161,6 +163,30 @@ task "ci" mode "ci" means "Runs buffered CI checks":
 	}
 }
 
+func TestParser_TaskRunsInContainer(t *testing.T) {
+	input := `version: 2.0
+
+task "build" mode "ci" runs in container "golang:1.22" means "Hermetic build":
+  run "go build ./..."`
+
+	lexer := lexer.NewLexer(input)
+	parser := NewParser(lexer)
+	program := parser.ParseProgram()
+
+	checkParserErrors(t, parser)
+
+	task := program.Tasks[0]
+	if task.Container != "golang:1.22" {
+		t.Errorf("task.Container wrong. expected=golang:1.22, got=%s", task.Container)
+	}
+	if task.Mode != "ci" || task.Description != "Hermetic build" {
+		t.Errorf("other header clauses lost: mode=%q description=%q", task.Mode, task.Description)
+	}
+	if !strings.HasPrefix(task.String(), `task "build" mode "ci" runs in container "golang:1.22" means "Hermetic build":`) {
+		t.Errorf("unexpected String(): %q", task.String())
+	}
+}
+
 func TestParser_BasicVersion(t *testing.T) {
 	input := `version: 2.0`
 
@@ -493,3 +519,364 @@ task "test":
 		}
 	}
 }
+
+func TestParser_StepFormatOptions(t *testing.T) {
+	input := `version: 2.0
+
+project "app":
+  set step style to "underline"
+  set step width to 60
+
+task "deploy":
+  step "Deploying" with style "banner" width 80 add line break after
+  step "Checking" with width 40`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	styleSetting := program.Project.Settings[0].(*ast.SetStatement)
+	widthSetting := program.Project.Settings[1].(*ast.SetStatement)
+	if styleSetting.Key != "step_style" || widthSetting.Key != "step_width" {
+		t.Fatalf("unexpected setting keys %q, %q", styleSetting.Key, widthSetting.Key)
+	}
+	if widthSetting.Value.String() != "60" {
+		t.Errorf("step_width value = %q, want 60", widthSetting.Value.String())
+	}
+
+	body := program.Tasks[0].Body
+	banner := body[0].(*ast.ActionStatement)
+	if banner.StepStyle != "banner" || banner.StepWidth != 80 || !banner.LineBreakAfter {
+		t.Errorf("unexpected banner step: %+v", banner)
+	}
+	if got := banner.String(); got != `step "Deploying" with style "banner" width 80 add line break after` {
+		t.Errorf("banner.String() = %q", got)
+	}
+
+	checking := body[1].(*ast.ActionStatement)
+	if checking.StepStyle != "" || checking.StepWidth != 40 {
+		t.Errorf("unexpected width-only step: %+v", checking)
+	}
+}
+
+func TestParser_StepFormatErrors(t *testing.T) {
+	tests := []string{
+		`step "Deploying" with colour "red"`,
+		`step "Deploying" with width 0`,
+		`step "Deploying" with style banner`,
+	}
+	for _, body := range tests {
+		input := "version: 2.0\n\ntask \"deploy\":\n  " + body
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parse error for %q", body)
+		}
+	}
+}
+
+func TestParser_TaskDocBlock(t *testing.T) {
+	input := "version: 2.0\n\n" +
+		"task \"deploy\" means \"Deploy\":\n" +
+		"  doc:\n" +
+		"    Deploys the **api** to `k8s`.\n" +
+		"\n" +
+		"    # Rollback\n" +
+		"    Say \"undo\" {loudly}\n" +
+		"        indented code\n" +
+		"\n" +
+		"  requires $env\n" +
+		"  info \"deploying\"\n" +
+		"\n" +
+		"task \"other\":\n" +
+		"  info \"x\"\n" +
+		"  doc:\r\n" +
+		"    Last\r\n"
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(program.Tasks))
+	}
+
+	deploy := program.Tasks[0]
+	wantDoc := "Deploys the **api** to `k8s`.\n\n# Rollback\nSay \"undo\" {loudly}\n    indented code"
+	if deploy.Doc != wantDoc {
+		t.Errorf("deploy.Doc = %q, want %q", deploy.Doc, wantDoc)
+	}
+	if len(deploy.Parameters) != 1 || len(deploy.Body) != 1 {
+		t.Errorf("expected statements after the doc block to parse, got %d params and %d statements", len(deploy.Parameters), len(deploy.Body))
+	}
+
+	if program.Tasks[1].Doc != "Last" {
+		t.Errorf("other.Doc = %q, want %q", program.Tasks[1].Doc, "Last")
+	}
+}
+
+func TestParser_TaskDocBlockErrors(t *testing.T) {
+	tests := []string{
+		"task \"a\":\n  doc: \"inline\"\n  info \"x\"\n",
+		"task \"a\":\n  doc:\n  info \"x\"\n",
+	}
+	for _, body := range tests {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\n" + body))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parse error for %q", body)
+		}
+	}
+}
+
+func TestParser_TaskSources(t *testing.T) {
+	input := `version: 2.0
+
+task "test":
+  sources "src/**/*.go", "go.mod"
+  depends on lint
+  info "testing"
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	task := program.Tasks[0]
+	if strings.Join(task.Sources, "|") != "src/**/*.go|go.mod" {
+		t.Errorf("task.Sources = %q", task.Sources)
+	}
+	if len(task.Dependencies) != 1 || len(task.Body) != 1 {
+		t.Errorf("expected statements after sources to parse, got %d dependencies and %d statements", len(task.Dependencies), len(task.Body))
+	}
+	if !strings.Contains(task.String(), `sources "src/**/*.go", "go.mod"`) {
+		t.Errorf("task.String() = %q, want sources line", task.String())
+	}
+}
+
+func TestParser_TaskSourcesErrors(t *testing.T) {
+	tests := []string{
+		"task \"a\":\n  sources \"a\",\n  info \"x\"\n",
+		"task \"a\":\n  sources \"\"\n  info \"x\"\n",
+		"task \"a\":\n  sources \"a\"\n  sources \"b\"\n  info \"x\"\n",
+	}
+	for _, body := range tests {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\n" + body))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parse error for %q", body)
+		}
+	}
+}
+
+func TestParser_TaskOncePerCommit(t *testing.T) {
+	p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"verify\":\n  once per commit\n  info \"x\"\n"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	task := program.Tasks[0]
+	if !task.Once || len(task.Body) != 1 {
+		t.Errorf("expected a once per commit task with one statement, got Once=%v and %d statements", task.Once, len(task.Body))
+	}
+	if !strings.Contains(task.String(), "once per commit") {
+		t.Errorf("task.String() = %q, want once per commit", task.String())
+	}
+
+	p = NewParser(lexer.NewLexer("version: 2.0\n\ntask \"verify\":\n  once per run\n  info \"x\"\n"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Error("expected parse error for once per run")
+	}
+}
+
+func TestParser_TaskResourceNeeds(t *testing.T) {
+	tests := []struct {
+		needs  string
+		cpus   float64
+		memory int64
+		text   string
+	}{
+		{"needs 2 cpus and 4GB memory", 2, 4 << 30, "needs 2 cpus and 4GB memory"},
+		{"needs 512 MB memory and 1 cpu", 1, 512 << 20, "needs 1 cpu and 512MB memory"},
+		{"needs 0.5 cpu", 0.5, 0, "needs 0.5 cpus"},
+		{"needs 1.5gb memory", 0, 1536 << 20, "needs 1536MB memory"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"build\":\n  " + tt.needs + "\n  info \"x\"\n"))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		task := program.Tasks[0]
+		if task.CPUs != tt.cpus || task.Memory != tt.memory || len(task.Body) != 1 {
+			t.Errorf("%s: CPUs=%v Memory=%d with %d statements", tt.needs, task.CPUs, task.Memory, len(task.Body))
+		}
+		if !strings.Contains(task.String(), tt.text) {
+			t.Errorf("%s: task.String() = %q, want %q", tt.needs, task.String(), tt.text)
+		}
+	}
+
+	for _, needs := range []string{"needs 2 cores", "needs 4 PB memory", "needs 4GB", "needs 1 cpu\n  needs 2 cpus"} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"build\":\n  " + needs + "\n  info \"x\"\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", needs)
+		}
+	}
+}
+
+func TestParser_TaskOutputsAndOutputNeeds(t *testing.T) {
+	input := `version: 2.0
+
+task "build":
+  outputs "image_tag", "$digest"
+  info "x"
+
+task "deploy":
+  needs output "image_tag" from task "build"
+  needs output "digest" from task "build" as $image_digest
+  info "y"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	build, deploy := program.Tasks[0], program.Tasks[1]
+	if !reflect.DeepEqual(build.Outputs, []string{"image_tag", "digest"}) || len(build.Body) != 1 {
+		t.Fatalf("build.Outputs = %q with %d statements", build.Outputs, len(build.Body))
+	}
+	want := []ast.OutputNeed{
+		{Output: "image_tag", Task: "build"},
+		{Output: "digest", Task: "build", As: "image_digest"},
+	}
+	if !reflect.DeepEqual(deploy.OutputNeeds, want) || len(deploy.Body) != 1 {
+		t.Fatalf("deploy.OutputNeeds = %+v with %d statements", deploy.OutputNeeds, len(deploy.Body))
+	}
+	for _, text := range []string{
+		`outputs "image_tag", "digest"`,
+		`needs output "image_tag" from task "build"`,
+		`needs output "digest" from task "build" as $image_digest`,
+	} {
+		if !strings.Contains(build.String()+deploy.String(), text) {
+			t.Errorf("expected the tasks to print %q", text)
+		}
+	}
+
+	for _, needs := range []string{
+		`needs output "image_tag" task "build"`,
+		`needs output "image_tag" from "build"`,
+		`needs output "image_tag" from task "deploy"`,
+		`needs output "image_tag" from task "build" as tag`,
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"deploy\":\n  " + needs + "\n  info \"x\"\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", needs)
+		}
+	}
+}
+
+func TestParser_ParametersAreReadOnlyUnlessShadowed(t *testing.T) {
+	input := `version: 2.0
+
+task "deploy":
+  given $env defaults to "dev"
+  accepts $region as string
+  let shadow $env = "{$env}-eu"
+  if $region is "us":
+    set shadow $region to "us-east-1"
+  let shadow be "a variable named shadow"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	let, ok := body[0].(*ast.VariableStatement)
+	if !ok || !let.Shadow || !strings.HasPrefix(let.String(), "let shadow $env = ") {
+		t.Fatalf("body[0] = %#v", body[0])
+	}
+	set := body[1].(*ast.ConditionalStatement).Body[0].(*ast.VariableStatement)
+	if !set.Shadow || set.Variable != "$region" {
+		t.Fatalf("set = %#v", set)
+	}
+	if named := body[2].(*ast.VariableStatement); named.Shadow || named.Variable != "shadow" {
+		t.Fatalf("body[2] = %#v", named)
+	}
+
+	tests := map[string]string{
+		`let $env = "prod"`:                                   "parameter $env is read-only and cannot be reassigned with let",
+		`set $env to "prod"`:                                  "parameter $env is read-only and cannot be reassigned with set",
+		`let env be "prod"`:                                   "parameter $env is read-only and cannot be reassigned with let",
+		`let shadow $other = "x"`:                             "nothing to shadow: $other is not a parameter of the task",
+		`set shadow $other to "x"`:                            "nothing to shadow: $other is not a parameter of the task",
+		`for each $x in ["a"]:` + "\n    let $env = \"{$x}\"": "parameter $env is read-only",
+	}
+	for statement, want := range tests {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"deploy\":\n  given $env defaults to \"dev\"\n  " + statement + "\n"))
+		p.ParseProgram()
+		if errs := p.Errors(); len(errs) != 1 || !strings.Contains(errs[0], want) {
+			t.Errorf("parsing %q: errors = %q, want one containing %q", statement, errs, want)
+		}
+	}
+}
+
+func TestParser_LineFilters(t *testing.T) {
+	input := `version: 2.0
+
+task "images":
+  run "docker images" filtering lines matching "^myapp" dropping lines containing "<none>"
+  run "make" logging to "make.log" keeping lines containing "warning"
+  capture from shell "cat app.log" as $log dropping lines containing "DEBUG"
+  capture from shell "cat app.log" keeping lines containing "ERROR" as $errors
+  capture lines of "ls" as $files keeping empty lines dropping lines matching "_test\.go$"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	run := body[0].(*ast.ShellStatement)
+	want := []ast.LineFilter{
+		{Verb: "filtering", Regexp: true, Pattern: "^myapp"},
+		{Verb: "dropping", Pattern: "<none>"},
+	}
+	if !reflect.DeepEqual(run.LineFilters, want) || run.LineFilters[1].Keep() {
+		t.Fatalf("run.LineFilters = %+v", run.LineFilters)
+	}
+	if got := run.String(); !strings.HasSuffix(got, ` filtering lines matching "^myapp" dropping lines containing "<none>"`) {
+		t.Errorf("run.String() = %q", got)
+	}
+	logged := body[1].(*ast.ShellStatement)
+	if logged.Log == nil || logged.Log.Keep != 0 || len(logged.LineFilters) != 1 || logged.LineFilters[0].Pattern != "warning" {
+		t.Errorf("logging with a filter = %+v, %+v", logged.Log, logged.LineFilters)
+	}
+	if log := body[2].(*ast.VariableStatement); log.Variable != "$log" || len(log.LineFilters) != 1 || log.LineFilters[0].Keep() {
+		t.Errorf("capture from shell with a filter after the variable = %+v", log)
+	}
+	errors := body[3].(*ast.VariableStatement)
+	if errors.Operation != "capture_shell" || errors.Variable != "$errors" || len(errors.LineFilters) != 1 || !errors.LineFilters[0].Keep() {
+		t.Errorf("capture from shell = %+v", errors)
+	}
+	files := body[4].(*ast.VariableStatement)
+	if !reflect.DeepEqual(files.Arguments, []string{"keep_empty"}) || len(files.LineFilters) != 1 || !files.LineFilters[0].Regexp {
+		t.Errorf("capture lines = %+v", files)
+	}
+
+	for _, statement := range []string{
+		`run "ls" keeping lines "x"`,
+		`run "ls" keeping lines like "x"`,
+		`run "ls" dropping lines containing ""`,
+		`run "ls" filtering lines matching "("`,
+		`run "vim" interactively keeping lines containing "x"`,
+		`run "ls" on host "web-1" keeping lines containing "x"`,
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"t\":\n  " + statement + "\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", statement)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isTodoStatementStart reports whether the current token starts a todo
+func (p *Parser) isTodoStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "todo" &&
+		p.peekToken.Type == lexer.STRING
+}
+
+// parseTodoStatement parses a placeholder for unwritten work
+// Syntax: todo "message"
+func (p *Parser) parseTodoStatement() *ast.TodoStatement {
+	stmt := &ast.TodoStatement{Token: p.curToken}
+	p.nextToken() // consume "todo"
+	stmt.Message = p.curToken.Literal
+	return stmt
+}
@@ -32,6 +32,16 @@ func (p *Parser) parseVariableStatement() *ast.VariableStatement {
 func (p *Parser) parseLetStatement(stmt *ast.VariableStatement) *ast.VariableStatement {
 	stmt.Operation = "let"
 
+	// "let shadow $variable = value" reassigns a task parameter on purpose
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "shadow" {
+		p.nextToken() // consume shadow
+		if p.peekToken.Type == lexer.BE {
+			// "let shadow be expression" declares a variable named shadow
+			return p.parseLetBe(stmt)
+		}
+		stmt.Shadow = true
+	}
+
 	// Check if next token is $variable (old syntax) or identifier (new syntax)
 	switch p.peekToken.Type {
 	case lexer.VARIABLE:
@@ -40,6 +50,7 @@ func (p *Parser) parseLetStatement(stmt *ast.VariableStatement) *ast.VariableSta
 			return nil
 		}
 		stmt.Variable = p.curToken.Literal
+		p.checkParameterAssignment(stmt)
 
 		// Check for optional "as type" syntax
 		if p.peekToken.Type == lexer.AS {
@@ -84,43 +95,79 @@ func (p *Parser) parseLetStatement(stmt *ast.VariableStatement) *ast.VariableSta
 		if !p.expectPeek(lexer.IDENT) {
 			return nil
 		}
-		stmt.Variable = p.curToken.Literal
+		return p.parseLetBe(stmt)
+	default:
+		p.addError("expected variable name or identifier after 'let'")
+		return nil
+	}
+}
 
-		// Validate that it's not a reserved name (even though IDENT syntax doesn't use $)
-		// This prevents future conflicts if we add more reserved keywords
-		reservedIdents := []string{"globals", "params"}
-		for _, reserved := range reservedIdents {
-			if stmt.Variable == reserved {
-				p.addError(fmt.Sprintf("cannot use reserved variable name '%s' (use a different name)", reserved))
-				return nil
-			}
-		}
+// parseLetBe parses the rest of "let variable be expression", with the
+// current token on the variable name
+func (p *Parser) parseLetBe(stmt *ast.VariableStatement) *ast.VariableStatement {
+	stmt.Variable = p.curToken.Literal
 
-		if !p.expectPeek(lexer.BE) {
+	// Validate that it's not a reserved name (even though IDENT syntax doesn't use $)
+	// This prevents future conflicts if we add more reserved keywords
+	reservedIdents := []string{"globals", "params"}
+	for _, reserved := range reservedIdents {
+		if stmt.Variable == reserved {
+			p.addError(fmt.Sprintf("cannot use reserved variable name '%s' (use a different name)", reserved))
 			return nil
 		}
+	}
+	p.checkParameterAssignment(stmt)
 
-		// Parse the expression after "be"
-		p.nextToken()
-		stmt.Value = p.parseExpression()
-		return stmt
-	default:
-		p.addError("expected variable name or identifier after 'let'")
+	if !p.expectPeek(lexer.BE) {
 		return nil
 	}
+
+	// Parse the expression after "be"
+	p.nextToken()
+	stmt.Value = p.parseExpression()
+	return stmt
+}
+
+// checkParameterAssignment reports a let or set that reassigns a parameter of
+// the task being parsed without shadow, and a shadow of a name that is not a
+// parameter. Parameters are read-only, so a task cannot silently replace a
+// value its caller passed.
+func (p *Parser) checkParameterAssignment(stmt *ast.VariableStatement) {
+	name := strings.TrimPrefix(stmt.Variable, "$")
+	isParameter := p.taskParameters[name]
+	switch {
+	case isParameter && !stmt.Shadow:
+		p.addErrorWithHelp(
+			fmt.Sprintf("parameter $%s is read-only and cannot be reassigned with %s", name, stmt.Operation),
+			fmt.Sprintf("Use another variable name, or write '%s shadow $%s ...' to replace the parameter's value in this task on purpose", stmt.Operation, name),
+		)
+	case stmt.Shadow && !isParameter:
+		p.addErrorWithHelp(
+			fmt.Sprintf("nothing to shadow: $%s is not a parameter of the task", name),
+			fmt.Sprintf("Remove 'shadow', which is only needed to reassign a parameter: %s $%s ...", stmt.Operation, name),
+		)
+	}
 }
 
 // parseSetVariableStatement parses "set variable to value" statements
 // Supports: set $variable to value
 //
 //	set $variable as list to ["value1", "value2"]
+//	set shadow $parameter to value
 func (p *Parser) parseSetVariableStatement(stmt *ast.VariableStatement) *ast.VariableStatement {
 	stmt.Operation = "set"
 
+	// "set shadow $variable to value" reassigns a task parameter on purpose
+	if p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "shadow" {
+		p.nextToken() // consume shadow
+		stmt.Shadow = true
+	}
+
 	if !p.expectPeekVariableName() {
 		return nil
 	}
 	stmt.Variable = p.curToken.Literal
+	p.checkParameterAssignment(stmt)
 
 	// Check for optional "as type" syntax
 	if p.peekToken.Type == lexer.AS {
@@ -179,6 +226,17 @@ func (p *Parser) parseTransformStatement(stmt *ast.VariableStatement) *ast.Varia
 	}
 	stmt.Function = p.curToken.Literal
 
+	// Two-word functions: "byte length", "pad left" and "pad right"
+	switch {
+	case stmt.Function == "byte" && p.peekToken.Type == lexer.LENGTH:
+		p.nextToken()
+		stmt.Function = "byte length"
+	case stmt.Function == "pad" && p.peekToken.Type == lexer.IDENT &&
+		(p.peekToken.Literal == "left" || p.peekToken.Literal == "right"):
+		p.nextToken()
+		stmt.Function = "pad " + p.curToken.Literal
+	}
+
 	// Parse optional arguments
 	for p.peekToken.Type != lexer.NEWLINE && p.peekToken.Type != lexer.DEDENT && p.peekToken.Type != lexer.EOF && p.peekToken.Type != lexer.COMMENT && p.peekToken.Type != lexer.MULTILINE_COMMENT {
 		// Check if the next token looks like an argument
@@ -244,6 +302,9 @@ func (p *Parser) parseCaptureVariableStatement(stmt *ast.VariableStatement) *ast
 				return nil
 			}
 			command := p.curToken.Literal
+			if !p.parseLineFilters(&stmt.LineFilters) {
+				return nil
+			}
 
 			if !p.expectPeek(lexer.AS) {
 				return nil
@@ -253,6 +314,9 @@ func (p *Parser) parseCaptureVariableStatement(stmt *ast.VariableStatement) *ast
 				return nil
 			}
 			stmt.Variable = p.curToken.Literal
+			if !p.parseLineFilters(&stmt.LineFilters) {
+				return nil
+			}
 
 			// Mark this as a shell capture by setting a special operation
 			stmt.Operation = "capture_shell"
@@ -269,6 +333,9 @@ func (p *Parser) parseCaptureVariableStatement(stmt *ast.VariableStatement) *ast
 		if !p.expectPeek(lexer.IDENT) {
 			return nil
 		}
+		if p.curToken.Literal == "lines" && p.peekToken.Type == lexer.OF {
+			return p.parseCaptureLinesStatement(stmt)
+		}
 		stmt.Variable = p.curToken.Literal
 
 		if !p.expectPeek(lexer.FROM) {
@@ -285,6 +352,65 @@ func (p *Parser) parseCaptureVariableStatement(stmt *ast.VariableStatement) *ast
 	}
 }
 
+// parseCaptureLinesStatement parses the capture of a command's output as a
+// list with one item per line. Lines are trimmed and empty lines skipped
+// unless the options say otherwise.
+// Syntax: capture lines of "command" [line filters] as $variable [keeping empty lines] [without trimming] [line filters]
+func (p *Parser) parseCaptureLinesStatement(stmt *ast.VariableStatement) *ast.VariableStatement {
+	stmt.Operation = "capture_lines"
+
+	p.nextToken() // move to 'of'
+	if !p.expectPeek(lexer.STRING) {
+		return nil
+	}
+	stmt.Value = &ast.LiteralExpression{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
+	if !p.parseLineFilters(&stmt.LineFilters) {
+		return nil
+	}
+
+	if !p.expectPeek(lexer.AS) {
+		return nil
+	}
+	if !p.expectPeekVariableName() {
+		return nil
+	}
+	stmt.Variable = p.curToken.Literal
+
+	for {
+		switch {
+		case p.peekToken.Literal == "keeping":
+			p.nextToken() // move to 'keeping'
+			if p.peekToken.Literal == "lines" {
+				filter, ok := p.parseLineFilter()
+				if !ok {
+					return nil
+				}
+				stmt.LineFilters = append(stmt.LineFilters, filter)
+				continue
+			}
+			if !p.expectPeek(lexer.EMPTY) || !p.expectPeekLiteral("lines") {
+				return nil
+			}
+			stmt.Arguments = append(stmt.Arguments, "keep_empty")
+		case p.peekIsLineFilter():
+			if !p.parseLineFilters(&stmt.LineFilters) {
+				return nil
+			}
+		case p.peekToken.Type == lexer.WITHOUT:
+			p.nextToken() // move to 'without'
+			if !p.expectPeekLiteral("trimming") {
+				return nil
+			}
+			stmt.Arguments = append(stmt.Arguments, "no_trim")
+		default:
+			return stmt
+		}
+	}
+}
+
 // parseMultilineShellCapture parses multiline shell capture commands
 func (p *Parser) parseMultilineShellCapture(stmt *ast.VariableStatement) *ast.VariableStatement {
 	// Mark this as a shell capture by setting a special operation
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isVersionFilesStatementStart reports whether the current token starts a
+// read or bump of the version of several manifests
+func (p *Parser) isVersionFilesStatementStart() bool {
+	if p.peekToken.Type != lexer.VERSION {
+		return false
+	}
+	return p.curToken.Type == lexer.GET || (p.curToken.Type == lexer.IDENT && p.curToken.Literal == "bump")
+}
+
+// parseVersionFilesStatement parses a version read or bump across manifests
+// Syntax:
+// get version from files ["a", ...] as $var
+// bump version major|minor|patch in files ["a", ...] [keeping them in sync] [as $var]
+func (p *Parser) parseVersionFilesStatement() *ast.VersionFilesStatement {
+	stmt := &ast.VersionFilesStatement{Token: p.curToken, Operation: p.curToken.Literal}
+	p.nextToken() // consume "get" or "bump"
+
+	if stmt.Operation == "get" {
+		if !p.expectPeek(lexer.FROM) {
+			return nil
+		}
+		stmt.InSync = true
+	} else {
+		p.nextToken()
+		switch p.curToken.Literal {
+		case "major", "minor", "patch":
+			stmt.Level = p.curToken.Literal
+		default:
+			p.addErrorWithHelp("expected major, minor or patch after 'bump version'",
+				`Use: bump version minor in files ["package.json", "VERSION"]`)
+			return nil
+		}
+		if !p.expectPeek(lexer.IN) {
+			return nil
+		}
+	}
+	if !p.expectPeek(lexer.FILES) || !p.expectPeek(lexer.LBRACKET) {
+		return nil
+	}
+	stmt.Files = p.parseStringList()
+	if len(stmt.Files) == 0 {
+		p.addError("expected at least one file in the version file list")
+		return nil
+	}
+
+	if stmt.Operation == "bump" && p.peekToken.Type == lexer.IDENT && p.peekToken.Literal == "keeping" {
+		p.nextToken() // consume "keeping"
+		for _, word := range []string{"them", "in", "sync"} {
+			p.nextToken()
+			if p.curToken.Literal != word {
+				p.addErrorWithHelp("expected 'keeping them in sync'",
+					`Use: bump version minor in files ["package.json", "VERSION"] keeping them in sync`)
+				return nil
+			}
+		}
+		stmt.InSync = true
+	}
+
+	if p.peekToken.Type == lexer.AS {
+		p.nextToken() // consume "as"
+		if !p.expectPeekVariableName() {
+			return nil
+		}
+		stmt.CaptureVar = p.getVariableName()
+	} else if stmt.Operation == "get" {
+		p.addErrorWithHelp("expected 'as $variable' after the version file list",
+			`Use: get version from files ["package.json", "VERSION"] as $version`)
+		return nil
+	}
+	if stmt.CaptureVar != "" && !stmt.InSync && len(stmt.Files) > 1 {
+		p.addErrorWithHelp("capturing the bumped version of several files requires 'keeping them in sync'",
+			`Use: bump version minor in files ["package.json", "VERSION"] keeping them in sync as $version`)
+		return nil
+	}
+	return stmt
+}
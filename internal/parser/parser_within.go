@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+// isWithinStatementStart reports whether the current token starts a
+// time-boxed section
+func (p *Parser) isWithinStatementStart() bool {
+	return p.curToken.Type == lexer.IDENT && p.curToken.Literal == "within" &&
+		(p.peekToken.Type == lexer.NUMBER || p.peekToken.Type == lexer.STRING)
+}
+
+// parseWithinStatement parses a time-boxed section
+// Syntax: within <duration> [as "name"] [or fail]:
+func (p *Parser) parseWithinStatement() *ast.WithinStatement {
+	stmt := &ast.WithinStatement{Token: p.curToken}
+
+	stmt.Budget = p.parsePollDuration("within")
+	if stmt.Budget == "" {
+		return nil
+	}
+
+	if p.peekToken.Type == lexer.AS {
+		p.nextToken() // consume AS
+		if !p.expectPeek(lexer.STRING) {
+			return nil
+		}
+		stmt.Name = p.curToken.Literal
+	}
+
+	if p.peekToken.Type == lexer.OR {
+		p.nextToken() // consume OR
+		if !p.expectPeek(lexer.FAIL) {
+			return nil
+		}
+		stmt.Fail = true
+	}
+
+	if !p.expectPeek(lexer.COLON) {
+		return nil
+	}
+	stmt.Body = p.parseControlFlowBody()
+	if len(stmt.Body) == 0 {
+		p.addError("within block has no statements")
+		return nil
+	}
+	return stmt
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_PluginStatements(t *testing.T) {
+	input := `version: 2.0
+
+task "release":
+  x sentry release new "{version}" --force key="a b" $commit
+  if $ci is "true":
+    x docker-scan image
+  info "done"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements in task body, got %d", len(body))
+	}
+
+	tests := []struct {
+		stmt ast.Statement
+		tool string
+		args []string
+	}{
+		{body[0], "sentry", []string{"release", "new", "{version}", "--force", "key=a b", "{$commit}"}},
+		{body[1].(*ast.ConditionalStatement).Body[0], "docker-scan", []string{"image"}},
+	}
+	for _, tt := range tests {
+		plugin, ok := tt.stmt.(*ast.PluginStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.PluginStatement, got %T", tt.stmt)
+		}
+		if plugin.Tool != tt.tool || !reflect.DeepEqual(plugin.Args, tt.args) {
+			t.Errorf("plugin = (%q, %q), want (%q, %q)", plugin.Tool, plugin.Args, tt.tool, tt.args)
+		}
+	}
+
+	if got := body[0].String(); got != `x sentry release new "{version}" --force "key=a b" "{$commit}"` {
+		t.Errorf("String() = %q", got)
+	}
+}
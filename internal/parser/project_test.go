@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -382,3 +383,852 @@ task "hello":
 		t.Errorf("task.Name not 'hello'. got=%q", program.Tasks[0].Name)
 	}
 }
+
+func TestParser_ProjectPathEntries(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set path to include ".drun/tools" and "node_modules/.bin"
+  set registry to "ghcr.io/company"
+
+task "lint":
+  run "eslint ."`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Project.Settings) != 2 {
+		t.Fatalf("project should have 2 settings. got=%d", len(program.Project.Settings))
+	}
+
+	pathStmt, ok := program.Project.Settings[0].(*ast.PathStatement)
+	if !ok {
+		t.Fatalf("project.Settings[0] is not *ast.PathStatement. got=%T", program.Project.Settings[0])
+	}
+
+	expected := []string{".drun/tools", "node_modules/.bin"}
+	if len(pathStmt.Entries) != len(expected) {
+		t.Fatalf("pathStmt.Entries = %v, want %v", pathStmt.Entries, expected)
+	}
+	for i, entry := range expected {
+		if pathStmt.Entries[i] != entry {
+			t.Errorf("pathStmt.Entries[%d] = %q, want %q", i, pathStmt.Entries[i], entry)
+		}
+	}
+
+	if got := pathStmt.String(); got != `set path to include ".drun/tools" and "node_modules/.bin"` {
+		t.Errorf("pathStmt.String() = %q", got)
+	}
+
+	if _, ok := program.Project.Settings[1].(*ast.SetStatement); !ok {
+		t.Errorf("project.Settings[1] is not *ast.SetStatement. got=%T", program.Project.Settings[1])
+	}
+}
+
+func TestParser_ProjectPathEntriesRequireString(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set path to include
+
+task "lint":
+  run "eslint ."`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatal("expected parse error for 'set path to include' without entries")
+	}
+}
+
+func TestParser_ShellEnvironmentAllowDeny(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  shell environment allow ["PATH", "HOME", "CI_*"]
+  shell environment deny ["*_TOKEN"]
+
+task "lint":
+  run "eslint ."`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Project.Settings) != 2 {
+		t.Fatalf("project should have 2 settings. got=%d", len(program.Project.Settings))
+	}
+
+	allow, ok := program.Project.Settings[0].(*ast.ShellEnvironmentStatement)
+	if !ok {
+		t.Fatalf("project.Settings[0] is not *ast.ShellEnvironmentStatement. got=%T", program.Project.Settings[0])
+	}
+	if got := allow.String(); got != `shell environment allow ["PATH", "HOME", "CI_*"]` {
+		t.Errorf("allow.String() = %q", got)
+	}
+
+	deny, ok := program.Project.Settings[1].(*ast.ShellEnvironmentStatement)
+	if !ok {
+		t.Fatalf("project.Settings[1] is not *ast.ShellEnvironmentStatement. got=%T", program.Project.Settings[1])
+	}
+	if deny.Mode != "deny" || len(deny.Patterns) != 1 || deny.Patterns[0] != "*_TOKEN" {
+		t.Errorf("deny = %s, want shell environment deny [\"*_TOKEN\"]", deny)
+	}
+}
+
+func TestParser_ShellEnvironmentRejectsInvalidInput(t *testing.T) {
+	for _, line := range []string{
+		`shell environment keep ["PATH"]`,
+		`shell environment allow "PATH"`,
+		`shell environment deny ["AWS_[*"]`,
+	} {
+		input := "version: 2.0\n\nproject \"myapp\":\n  " + line + "\n\ntask \"lint\":\n  run \"eslint .\""
+
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parse error for %q", line)
+		}
+	}
+}
+
+func TestParser_ProjectOutputStyle(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set output style to "plain"
+
+task "hello":
+  info "hi"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	setSetting, ok := program.Project.Settings[0].(*ast.SetStatement)
+	if !ok {
+		t.Fatalf("project.Settings[0] is not *ast.SetStatement. got=%T", program.Project.Settings[0])
+	}
+	if setSetting.Key != "output_style" {
+		t.Errorf("setSetting.Key not 'output_style'. got=%q", setSetting.Key)
+	}
+	if setSetting.Value.String() != "plain" {
+		t.Errorf("setSetting.Value not 'plain'. got=%q", setSetting.Value)
+	}
+}
+
+func TestParser_StatusSymbolSettings(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set status symbols to "ok=✔, warn=▲"
+  set status colors to "ok=bold blue"
+
+task "hello":
+  info "hi"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	for i, want := range []string{"status_symbols", "status_colors"} {
+		setSetting, ok := program.Project.Settings[i].(*ast.SetStatement)
+		if !ok || setSetting.Key != want {
+			t.Errorf("project.Settings[%d] = %#v, want key %q", i, program.Project.Settings[i], want)
+		}
+	}
+}
+
+func TestParser_DefaultTask(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  default task is "help-menu"
+  set registry to "ghcr.io/company"
+
+task "help-menu":
+  info "help"
+
+task "build" means "Build the app" default:
+  info "build"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Project.Settings) != 2 {
+		t.Fatalf("project should have 2 settings. got=%d", len(program.Project.Settings))
+	}
+	defaultTask, ok := program.Project.Settings[0].(*ast.DefaultTaskStatement)
+	if !ok {
+		t.Fatalf("project.Settings[0] is not *ast.DefaultTaskStatement. got=%T", program.Project.Settings[0])
+	}
+	if defaultTask.TaskName != "help-menu" {
+		t.Errorf("default task not 'help-menu'. got=%q", defaultTask.TaskName)
+	}
+
+	if program.Tasks[0].Default {
+		t.Errorf("task %q should not be marked default", program.Tasks[0].Name)
+	}
+	if !program.Tasks[1].Default || program.Tasks[1].Description != "Build the app" {
+		t.Errorf("task %q should be marked default with its description. got=%+v", program.Tasks[1].Name, program.Tasks[1])
+	}
+}
+
+func TestParser_DefaultTaskErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "missing task name",
+			input: `version: 2.0
+
+project "myapp":
+  default task is
+
+task "build":
+  info "build"`,
+		},
+		{
+			name: "two default tasks",
+			input: `version: 2.0
+
+task "build" default:
+  info "build"
+
+task "test" default:
+  info "test"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(lexer.NewLexer(tt.input))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatal("expected parser errors")
+			}
+		})
+	}
+}
+
+func TestParser_ScopedLifecycleHooks(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  before task "deploy":
+    info "auth refresh"
+  after tasks matching "deploy-*":
+    info "done"
+
+task "deploy":
+  info "deploying"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Project.Settings) != 2 {
+		t.Fatalf("project should have 2 settings. got=%d", len(program.Project.Settings))
+	}
+
+	tests := []struct {
+		hookType string
+		scope    string
+		target   string
+		text     string
+	}{
+		{"before", "task", "deploy", `before task "deploy":`},
+		{"after", "matching", "deploy-*", `after tasks matching "deploy-*":`},
+	}
+	for i, tt := range tests {
+		hook, ok := program.Project.Settings[i].(*ast.LifecycleHook)
+		if !ok {
+			t.Fatalf("setting %d is not *ast.LifecycleHook. got=%T", i, program.Project.Settings[i])
+		}
+		if hook.Type != tt.hookType || hook.Scope != tt.scope || hook.Target != tt.target {
+			t.Errorf("hook %d = %s/%s/%s, want %s/%s/%s", i, hook.Type, hook.Scope, hook.Target, tt.hookType, tt.scope, tt.target)
+		}
+		if !strings.HasPrefix(hook.String(), tt.text) {
+			t.Errorf("hook %d String() = %q, want prefix %q", i, hook.String(), tt.text)
+		}
+	}
+}
+
+func TestParser_ScopedLifecycleHookInvalidPattern(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  before tasks matching "deploy-[":
+    info "auth refresh"
+
+task "deploy":
+  info "deploying"`
+
+	p := NewParser(lexer.NewLexer(input))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatal("expected an error for an invalid task pattern")
+	}
+}
+
+func TestParser_LifecycleHookPriority(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  before any task priority 10:
+    info "first"
+  after task "deploy" priority -5:
+    info "last"
+  on drun setup priority 3:
+    info "setup"
+
+task "deploy":
+  info "deploying"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	want := []struct {
+		priority int
+		text     string
+	}{
+		{10, "before any task priority 10:"},
+		{-5, `after task "deploy" priority -5:`},
+		{3, "on drun setup priority 3:"},
+	}
+	for i, tt := range want {
+		hook, ok := program.Project.Settings[i].(*ast.LifecycleHook)
+		if !ok {
+			t.Fatalf("setting %d is not *ast.LifecycleHook. got=%T", i, program.Project.Settings[i])
+		}
+		if hook.Priority != tt.priority {
+			t.Errorf("hook %d priority = %d, want %d", i, hook.Priority, tt.priority)
+		}
+		if !strings.HasPrefix(hook.String(), tt.text) {
+			t.Errorf("hook %d String() = %q, want prefix %q", i, hook.String(), tt.text)
+		}
+	}
+}
+
+func TestParser_LifecycleHookPriorityRequiresNumber(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  before any task priority high:
+    info "first"
+
+task "deploy":
+  info "deploying"`
+
+	p := NewParser(lexer.NewLexer(input))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatal("expected an error for a non-numeric hook priority")
+	}
+}
+
+func TestParser_IncludeRenamedSymbols(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  include snippets "docker login" as "registry-login", "cleanup", templates "build" as "docker-build" from "lib.drun" as shared`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	include, ok := program.Project.Settings[0].(*ast.IncludeStatement)
+	if !ok {
+		t.Fatalf("project.Settings[0] is not *ast.IncludeStatement. got=%T", program.Project.Settings[0])
+	}
+	want := []ast.IncludeSymbol{
+		{Kind: "snippets", Name: "docker login", Alias: "registry-login"},
+		{Kind: "snippets", Name: "cleanup"},
+		{Kind: "templates", Name: "build", Alias: "docker-build"},
+	}
+	if len(include.Symbols) != len(want) {
+		t.Fatalf("expected %d symbols. got=%+v", len(want), include.Symbols)
+	}
+	for i, symbol := range want {
+		if include.Symbols[i] != symbol {
+			t.Errorf("symbol %d = %+v, want %+v", i, include.Symbols[i], symbol)
+		}
+	}
+	if include.Path != "lib.drun" || include.Namespace != "shared" {
+		t.Errorf("unexpected path/namespace: %q as %q", include.Path, include.Namespace)
+	}
+	if got := include.String(); got != `include snippets "docker login" as "registry-login", "cleanup", templates "build" as "docker-build" from lib.drun as shared` {
+		t.Errorf("unexpected String(): %s", got)
+	}
+}
+
+func TestParser_IncludeRenamedSymbolErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"tasks by name", `include tasks "build" from "lib.drun"`},
+		{"alias not quoted", `include snippets "login" as login from "lib.drun"`},
+		{"duplicate local name", `include snippets "login", "logout" as "login" from "lib.drun"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "version: 2.0\n\nproject \"myapp\":\n  " + tt.input + "\n"
+			p := NewParser(lexer.NewLexer(input))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatalf("expected a parse error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestParser_IncludeOverride(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  include "lib.drun" as shared override
+  include "other.drun"
+  set registry to "ghcr.io/company"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Project.Settings) != 3 {
+		t.Fatalf("project should have 3 settings. got=%d", len(program.Project.Settings))
+	}
+	first := program.Project.Settings[0].(*ast.IncludeStatement)
+	second := program.Project.Settings[1].(*ast.IncludeStatement)
+	if !first.Override || first.Namespace != "shared" {
+		t.Errorf("first include should override into 'shared'. got=%+v", first)
+	}
+	if second.Override {
+		t.Errorf("second include should not override. got=%+v", second)
+	}
+	if got := first.String(); got != "include lib.drun as shared override" {
+		t.Errorf("unexpected String(): %s", got)
+	}
+}
+
+func TestParser_IncludeFromDrunhubUnquotedPath(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  include from drunhub std/core as lib
+  include from drunhub "ops/docker@v1.0"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	first := program.Project.Settings[0].(*ast.IncludeStatement)
+	second := program.Project.Settings[1].(*ast.IncludeStatement)
+	if first.Path != "drunhub:std/core" || first.Namespace != "lib" {
+		t.Errorf("unexpected first include: %q as %q", first.Path, first.Namespace)
+	}
+	if second.Path != "drunhub:ops/docker@v1.0" {
+		t.Errorf("unexpected second include path: %q", second.Path)
+	}
+}
+
+func TestParser_IncludeFromDrunhubVersionRange(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  include from drunhub ops/docker@^1.2 as docker
+  include from drunhub ops/go@~1.2.3
+  include from drunhub ops/k8s@v1.x as k8s override`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	want := []string{
+		"include drunhub:ops/docker@^1.2 as docker",
+		"include drunhub:ops/go@~1.2.3",
+		"include drunhub:ops/k8s@v1.x as k8s override",
+	}
+	for i, expected := range want {
+		if got := program.Project.Settings[i].(*ast.IncludeStatement).String(); got != expected {
+			t.Errorf("include %d = %q, want %q", i, got, expected)
+		}
+	}
+}
+
+func TestParser_IncludeFromGitHubLibrary(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  include from github "acme/ops//lib/docker@v1" as docker override`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	include := program.Project.Settings[0].(*ast.IncludeStatement)
+	if include.Path != "github:acme/ops//lib/docker@v1" || include.Namespace != "docker" || !include.Override {
+		t.Errorf("unexpected include: %s", include.String())
+	}
+}
+
+func TestParser_IncludeFromWorkspaceMember(t *testing.T) {
+	input := `version: 2.0
+
+project "shop":
+  include tasks from member "services/api"
+  include member "services/web" as frontend`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	want := []string{
+		`include tasks from member "services/api"`,
+		`include member "services/web" as frontend`,
+	}
+	for i, expected := range want {
+		include := program.Project.Settings[i].(*ast.IncludeStatement)
+		if !include.Member {
+			t.Errorf("include %d should be a workspace member", i)
+		}
+		if got := include.String(); got != expected {
+			t.Errorf("include %d = %q, want %q", i, got, expected)
+		}
+	}
+}
+
+func TestParser_EncryptedSetting(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set encrypted db_password to "ENC[YWdl]"
+  set encrypted to "plain"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	first := program.Project.Settings[0].(*ast.SetStatement)
+	if !first.Encrypted || first.Key != "db_password" {
+		t.Errorf("unexpected first setting: %+v", first)
+	}
+	if got := first.String(); got != "set encrypted db_password to ENC[YWdl]" {
+		t.Errorf("unexpected String(): %s", got)
+	}
+	second := program.Project.Settings[1].(*ast.SetStatement)
+	if second.Encrypted || second.Key != "encrypted" {
+		t.Errorf("a setting named encrypted should stay plain: %+v", second)
+	}
+}
+
+func TestParser_EncryptedSettingOnlyInProjectBody(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  profile "prod":
+    set encrypted token to "ENC[YWdl]"`
+
+	p := NewParser(lexer.NewLexer(input))
+	p.ParseProgram()
+
+	errors := strings.Join(p.Errors(), "\n")
+	if !strings.Contains(errors, `encrypted values can only be set in the project body, not in profile "prod"`) {
+		t.Fatalf("expected an error for an encrypted profile value, got:\n%s", errors)
+	}
+}
+
+func TestParser_CredentialHelpers(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  set credential helper for "GHCR.io" to "exec:gh auth token"
+  set credential helper for "api.example.com" to "env:API_TOKEN"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	first, ok := program.Project.Settings[0].(*ast.CredentialHelperStatement)
+	if !ok {
+		t.Fatalf("project.Settings[0] is not *ast.CredentialHelperStatement. got=%T", program.Project.Settings[0])
+	}
+	if first.Host != "ghcr.io" || first.Helper != "exec:gh auth token" {
+		t.Errorf("unexpected helper: %+v", first)
+	}
+	if got := first.String(); got != `set credential helper for "ghcr.io" to "exec:gh auth token"` {
+		t.Errorf("unexpected String(): %s", got)
+	}
+	if second := program.Project.Settings[1].(*ast.CredentialHelperStatement); second.Helper != "env:API_TOKEN" {
+		t.Errorf("unexpected second helper: %+v", second)
+	}
+}
+
+func TestParser_CredentialHelperErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown kind", `set credential helper for "ghcr.io" to "file:/tmp/token"`},
+		{"empty command", `set credential helper for "ghcr.io" to "exec:"`},
+		{"empty host", `set credential helper for "" to "exec:gh auth token"`},
+		{"duplicate host", "set credential helper for \"ghcr.io\" to \"env:A\"\n  set credential helper for \"ghcr.io\" to \"env:B\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "version: 2.0\n\nproject \"myapp\":\n  " + tt.input + "\n"
+			p := NewParser(lexer.NewLexer(input))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatalf("expected a parse error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestParser_ProjectProfiles(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  profile "prod": set env to "prod", set replicas to "5"
+  profile "staging":
+    set env to "staging"
+    set replicas to 2
+  set registry to "ghcr.io/company"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Project.Settings) != 3 {
+		t.Fatalf("project should have 3 settings. got=%d", len(program.Project.Settings))
+	}
+	if names := program.Project.ProfileNames(); len(names) != 2 || names[0] != "prod" || names[1] != "staging" {
+		t.Fatalf("unexpected profile names: %v", names)
+	}
+
+	prod := program.Project.Profile("prod").Params()
+	if prod["env"] != "prod" || prod["replicas"] != "5" {
+		t.Errorf("unexpected prod profile: %v", prod)
+	}
+	staging := program.Project.Profile("staging").Params()
+	if staging["env"] != "staging" || staging["replicas"] != "2" {
+		t.Errorf("unexpected staging profile: %v", staging)
+	}
+	if program.Project.Profile("missing") != nil {
+		t.Error("expected no profile named 'missing'")
+	}
+}
+
+func TestParser_ProjectProfileErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty block": "project \"myapp\":\n  profile \"prod\":\n    # nothing\n  set registry to \"x\"\n",
+		"duplicate":   "project \"myapp\":\n  profile \"prod\": set env to \"prod\"\n  profile \"prod\": set env to \"live\"\n",
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewParser(lexer.NewLexer("version: 2.0\n\n" + body))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatal("expected a parser error")
+			}
+		})
+	}
+}
+
+func TestParser_NamespaceDefaults(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  include "lib/docker.drun" as docker
+  defaults for docker.*: set registry to "ghcr.io/acme"
+  defaults for docker.build:
+    set tag to "edge"
+    set image to "api"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	all, ok := program.Project.Settings[1].(*ast.NamespaceDefaultsStatement)
+	if !ok {
+		t.Fatalf("project.Settings[1] is not *ast.NamespaceDefaultsStatement. got=%T", program.Project.Settings[1])
+	}
+	if all.Pattern != "docker.*" || all.Params()["registry"] != "ghcr.io/acme" {
+		t.Errorf("unexpected defaults: %s", all.String())
+	}
+	if !all.Matches("docker", "push") || all.Matches("k8s", "push") || all.Matches("", "docker") {
+		t.Errorf("docker.* should match exactly the tasks of the docker namespace")
+	}
+
+	build := program.Project.Settings[2].(*ast.NamespaceDefaultsStatement)
+	if params := build.Params(); params["tag"] != "edge" || params["image"] != "api" {
+		t.Errorf("unexpected docker.build defaults: %v", params)
+	}
+	if !build.Matches("docker", "build") || build.Matches("docker", "push") {
+		t.Errorf("docker.build should match only that task")
+	}
+
+	for _, pattern := range []string{"docker", "*.build", ".*"} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\nproject \"myapp\":\n  defaults for " + pattern + ": set tag to \"x\"\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parser error for pattern %q", pattern)
+		}
+	}
+}
+
+func TestParser_ProjectEnvironment(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  environment:
+    image "golang:1.22"
+    asdf nodejs "20.11.0"
+    nix jq
+
+task "show":
+  detect environment "nodejs" as $node`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	env := program.Project.Environment()
+	if env == nil || len(env.Entries) != 3 {
+		t.Fatalf("expected an environment block with 3 entries. got=%+v", env)
+	}
+	want := []ast.EnvironmentEntry{
+		{Kind: "image", Name: "golang:1.22"},
+		{Kind: "asdf", Name: "nodejs", Version: "20.11.0"},
+		{Kind: "nix", Name: "jq"},
+	}
+	for i, entry := range env.Entries {
+		if entry != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+	if key, value := env.Entries[0].Key(), env.Entries[0].Value(); key != "golang" || value != "golang:1.22" {
+		t.Errorf("image entry key/value = %q/%q", key, value)
+	}
+
+	detect, ok := program.Tasks[0].Body[0].(*ast.DetectionStatement)
+	if !ok {
+		t.Fatalf("expected DetectionStatement. got=%T", program.Tasks[0].Body[0])
+	}
+	if detect.Type != "detect_environment" || detect.Target != "nodejs" || detect.CaptureVar != "node" {
+		t.Errorf("unexpected detection: %+v", detect)
+	}
+	if got := detect.String(); got != `detect environment "nodejs" as $node` {
+		t.Errorf("unexpected String(): %s", got)
+	}
+}
+
+func TestParser_ProjectEnvironmentErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty block":    "project \"myapp\":\n  environment:\n    # nothing\n  set registry to \"x\"\n",
+		"duplicate":      "project \"myapp\":\n  environment:\n    nix jq\n  environment:\n    nix yq\n",
+		"unknown kind":   "project \"myapp\":\n  environment:\n    brew jq\n",
+		"asdf version":   "project \"myapp\":\n  environment:\n    asdf nodejs\n",
+		"detect no name": "task \"t\":\n  detect environment as $x\n",
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewParser(lexer.NewLexer("version: 2.0\n\n" + body))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatal("expected a parser error")
+			}
+		})
+	}
+}
+
+func TestParser_ProjectConditions(t *testing.T) {
+	input := `version: 2.0
+
+project "myapp":
+  condition "is prod" means environment is "production" and {ci.provider} is not empty
+  condition "is  release" means is prod and $tag is not empty
+  set registry to "ghcr.io/acme"
+
+task "deploy":
+  when is prod:
+    info "prod"`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	want := []string{
+		`condition "is prod" means environment is production and {ci.provider} is not empty`,
+		`condition "is release" means is prod and $tag is not empty`,
+	}
+	for i, str := range want {
+		condition, ok := program.Project.Settings[i].(*ast.ConditionStatement)
+		if !ok {
+			t.Fatalf("setting %d: expected a ConditionStatement, got %T", i, program.Project.Settings[i])
+		}
+		if got := condition.String(); got != str {
+			t.Errorf("setting %d String() = %q, want %q", i, got, str)
+		}
+	}
+	if _, ok := program.Project.Settings[2].(*ast.SetStatement); !ok {
+		t.Errorf("expected the set statement after the conditions, got %T", program.Project.Settings[2])
+	}
+	when, ok := program.Tasks[0].Body[0].(*ast.ConditionalStatement)
+	if !ok || when.Condition != "is prod" {
+		t.Fatalf("expected when is prod, got %#v", program.Tasks[0].Body[0])
+	}
+}
+
+func TestParser_ProjectConditionErrors(t *testing.T) {
+	tests := map[string]string{
+		"duplicate":     "project \"myapp\":\n  condition \"is prod\" means $env is \"prod\"\n  condition \"is prod\" means $env is \"live\"\n",
+		"no expression": "project \"myapp\":\n  condition \"is prod\" means\n",
+		"no means":      "project \"myapp\":\n  condition \"is prod\" $env is \"prod\"\n",
+		"empty name":    "project \"myapp\":\n  condition \"\" means $env is \"prod\"\n",
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewParser(lexer.NewLexer("version: 2.0\n\n" + body))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatal("expected a parser error")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/lexer"
@@ -176,3 +177,72 @@ task "build":
 		t.Errorf("expected no constraints, got %v", param.Constraints)
 	}
 }
+
+func TestParameterCompletionCommand(t *testing.T) {
+	input := `
+version: 2.0
+
+task "logs":
+	requires $service completed by "kubectl get svc -o name"
+	given $pod defaults to "api-0" completed by "kubectl get pods -o name"
+	accepts $namespace from ["default", "prod"] completed by "kubectl get ns -o name"
+	info "Logs of {$service}"
+`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %v", p.Errors())
+	}
+
+	want := map[string]string{
+		"service":   "kubectl get svc -o name",
+		"pod":       "kubectl get pods -o name",
+		"namespace": "kubectl get ns -o name",
+	}
+	for _, param := range program.Tasks[0].Parameters {
+		if param.Completion != want[param.Name] {
+			t.Errorf("parameter %s: completion = %q, want %q", param.Name, param.Completion, want[param.Name])
+		}
+	}
+
+	p = NewParser(lexer.NewLexer("version: 2.0\n\ntask \"logs\":\n  requires $service completed with \"kubectl\"\n"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Error("expected an error for 'completed' without 'by'")
+	}
+}
+
+func TestParameterCustomValidator(t *testing.T) {
+	input := `
+version: 2.0
+
+task "deploy":
+	requires $namespace validated by "k8s-namespace" completed by "kubectl get ns -o name"
+	given $replicas as number between 1 and 5 defaults to "2" validated by "quota"
+	info "Deploying to {$namespace}"
+`
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %v", p.Errors())
+	}
+
+	params := program.Tasks[0].Parameters
+	if params[0].Validator != "k8s-namespace" || params[0].Completion != "kubectl get ns -o name" {
+		t.Errorf("namespace: validator = %q, completion = %q", params[0].Validator, params[0].Completion)
+	}
+	if params[1].Validator != "quota" || params[1].MinValue == nil {
+		t.Errorf("replicas: validator = %q, min = %v", params[1].Validator, params[1].MinValue)
+	}
+	if got := params[0].String(); !strings.Contains(got, `validated by "k8s-namespace"`) {
+		t.Errorf("String() = %q", got)
+	}
+
+	p = NewParser(lexer.NewLexer("version: 2.0\n\ntask \"deploy\":\n  requires $namespace validated with \"k8s\"\n"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Error("expected an error for 'validated' without 'by'")
+	}
+}
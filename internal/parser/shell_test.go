@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phillarmonic/drun/v2/internal/ast"
@@ -299,3 +300,457 @@ task "svc":
 		t.Fatalf("expected parser error for exec attached")
 	}
 }
+
+func TestParser_RunInteractively(t *testing.T) {
+	input := `version: 2.0
+
+task "login":
+  run "npm login" interactively
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	shellStmt, ok := program.Tasks[0].Body[0].(*ast.ShellStatement)
+	if !ok {
+		t.Fatalf("expected ShellStatement, got %T", program.Tasks[0].Body[0])
+	}
+	if !shellStmt.Attached || !shellStmt.Interactive {
+		t.Fatalf("expected an attached, interactive run statement, got %+v", shellStmt)
+	}
+	if got := shellStmt.String(); got != `run "npm login" interactively` {
+		t.Errorf("unexpected String(): %q", got)
+	}
+}
+
+func TestParser_InteractivelyErrors(t *testing.T) {
+	tests := map[string]string{
+		"exec":     `exec "npm login" interactively`,
+		"combined": `run "npm login" attached interactively`,
+		"logging":  `run "npm login" interactively logging to "login.log"`,
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := "version: 2.0\n\ntask \"login\":\n  " + line + "\n"
+			p := NewParser(lexer.NewLexer(input))
+			_ = p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatalf("expected parser error for %q", line)
+			}
+		})
+	}
+}
+
+func TestParser_RunPipelines(t *testing.T) {
+	input := `version: 2.0
+
+task "apply":
+  run "generate-config" | run "kubectl apply -f -" quietly
+  pipe output of "cat users.csv" into "sort" into "uniq -c"
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(body))
+	}
+
+	tests := []struct {
+		command  string
+		pipeInto []string
+		str      string
+	}{
+		{"generate-config", []string{"kubectl apply -f -"}, `run "generate-config" | run "kubectl apply -f -" quietly`},
+		{"cat users.csv", []string{"sort", "uniq -c"}, `run "cat users.csv" | run "sort" | run "uniq -c"`},
+	}
+	for i, test := range tests {
+		shellStmt, ok := body[i].(*ast.ShellStatement)
+		if !ok {
+			t.Fatalf("statement %d: expected ShellStatement, got %T", i, body[i])
+		}
+		if shellStmt.Action != "run" || shellStmt.Command != test.command {
+			t.Errorf("statement %d: unexpected action/command %q %q", i, shellStmt.Action, shellStmt.Command)
+		}
+		if strings.Join(shellStmt.PipeInto, ",") != strings.Join(test.pipeInto, ",") {
+			t.Errorf("statement %d: expected pipe into %v, got %v", i, test.pipeInto, shellStmt.PipeInto)
+		}
+		if got := shellStmt.String(); got != test.str {
+			t.Errorf("statement %d: unexpected String(): %q", i, got)
+		}
+	}
+}
+
+func TestParser_RunPipelineErrors(t *testing.T) {
+	tests := map[string]string{
+		"exec":              `exec "a" | run "b"`,
+		"modifier mid-pipe": `run "a" quietly | run "b"`,
+		"attached":          `run "a" | run "b" attached`,
+		"missing into":      `pipe output of "a"`,
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := "version: 2.0\n\ntask \"t\":\n  " + line + "\n"
+			p := NewParser(lexer.NewLexer(input))
+			_ = p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatalf("expected parser error for %q", line)
+			}
+		})
+	}
+}
+
+func TestParser_ShellLoggingModifiers(t *testing.T) {
+	input := `version: 2.0
+
+task "build":
+  log output to "logs/{task}.log" keeping 3
+  run "make build" logging to "logs/build.log"
+  run "make test" logging to "logs/test.log" appending
+  run logging to "logs/release.log":
+    echo one
+    echo two`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 4 {
+		t.Fatalf("Expected 4 statements in task body, got %d", len(body))
+	}
+
+	logOutput, ok := body[0].(*ast.LogOutputStatement)
+	if !ok {
+		t.Fatalf("Expected *ast.LogOutputStatement, got %T", body[0])
+	}
+	if logOutput.Target.Path != "logs/{task}.log" || logOutput.Target.Keep != 3 {
+		t.Errorf("Unexpected log output target: %+v", logOutput.Target)
+	}
+
+	tests := []struct {
+		index  int
+		path   string
+		append bool
+		str    string
+	}{
+		{1, "logs/build.log", false, `run "make build" logging to "logs/build.log"`},
+		{2, "logs/test.log", true, `run "make test" logging to "logs/test.log" appending`},
+	}
+	for _, tt := range tests {
+		shellStmt, ok := body[tt.index].(*ast.ShellStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.ShellStatement at %d, got %T", tt.index, body[tt.index])
+		}
+		if shellStmt.Log == nil || shellStmt.Log.Path != tt.path || shellStmt.Log.Append != tt.append {
+			t.Errorf("statement %d: unexpected log target %+v", tt.index, shellStmt.Log)
+		}
+		if got := shellStmt.String(); got != tt.str {
+			t.Errorf("statement %d: String() = %q, want %q", tt.index, got, tt.str)
+		}
+	}
+
+	multiline, ok := body[3].(*ast.ShellStatement)
+	if !ok {
+		t.Fatalf("Expected *ast.ShellStatement, got %T", body[3])
+	}
+	if !multiline.IsMultiline || multiline.Log == nil || multiline.Log.Path != "logs/release.log" {
+		t.Errorf("Unexpected multiline logging statement: %+v", multiline)
+	}
+	if len(multiline.Commands) != 2 {
+		t.Errorf("Expected 2 multiline commands, got %v", multiline.Commands)
+	}
+}
+
+func TestParser_ShellLoggingRejectsAttached(t *testing.T) {
+	input := `version: 2.0
+
+task "dev":
+  run "npm run dev" attached logging to "logs/dev.log"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatal("Expected an error for attached combined with logging")
+	}
+}
+
+func TestParser_ShellVerbosityModifiers(t *testing.T) {
+	input := `version: 2.0
+
+task "install":
+  run "npm ci" quietly
+  run "ls" verbosely logging to "logs/ls.log"
+  run quietly:
+    make deps`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements in task body, got %d", len(body))
+	}
+
+	expected := []struct {
+		verbosity string
+		str       string
+	}{
+		{"quiet", `run "npm ci" quietly`},
+		{"verbose", `run "ls" verbosely logging to "logs/ls.log"`},
+		{"quiet", "run quietly:\n  make deps"},
+	}
+	for i, want := range expected {
+		shellStmt, ok := body[i].(*ast.ShellStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.ShellStatement at %d, got %T", i, body[i])
+		}
+		if shellStmt.Verbosity != want.verbosity {
+			t.Errorf("statement %d: Verbosity = %q, want %q", i, shellStmt.Verbosity, want.verbosity)
+		}
+		if got := shellStmt.String(); got != want.str {
+			t.Errorf("statement %d: String() = %q, want %q", i, got, want.str)
+		}
+	}
+}
+
+func TestParser_ShellVerbosityConflicts(t *testing.T) {
+	inputs := []string{
+		`run "npm ci" quietly verbosely`,
+		`run "npm run dev" attached quietly`,
+	}
+	for _, stmt := range inputs {
+		l := lexer.NewLexer("version: 2.0\n\ntask \"t\":\n  " + stmt)
+		p := NewParser(l)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("Expected a parse error for %q", stmt)
+		}
+	}
+}
+
+func TestParser_ShellExitCodeMappings(t *testing.T) {
+	input := `version: 2.0
+
+task "plan":
+  run "terraform plan -detailed-exitcode" mapping exit code 0 to "no changes" and exit code 2 to "changes pending" as $plan failing with "run terraform init first"
+  run quietly mapping exit code 1 to "nothing to commit":
+    git diff --quiet
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	plan, ok := program.Tasks[0].Body[0].(*ast.ShellStatement)
+	if !ok {
+		t.Fatalf("expected ShellStatement, got %T", program.Tasks[0].Body[0])
+	}
+	want := []ast.ExitCodeMapping{{Code: 0, Label: "no changes"}, {Code: 2, Label: "changes pending"}}
+	if len(plan.ExitCodes) != len(want) || plan.ExitCodes[0] != want[0] || plan.ExitCodes[1] != want[1] {
+		t.Errorf("unexpected exit code mappings: %+v", plan.ExitCodes)
+	}
+	if plan.ExitStateVar != "plan" || plan.FailureMessage != "run terraform init first" {
+		t.Errorf("unexpected state var %q or failure message %q", plan.ExitStateVar, plan.FailureMessage)
+	}
+	wantString := `run "terraform plan -detailed-exitcode" mapping exit code 0 to "no changes" and exit code 2 to "changes pending" as $plan failing with "run terraform init first"`
+	if got := plan.String(); got != wantString {
+		t.Errorf("unexpected String(): %s", got)
+	}
+
+	diff, ok := program.Tasks[0].Body[1].(*ast.ShellStatement)
+	if !ok || !diff.IsMultiline || diff.Verbosity != "quiet" || len(diff.ExitCodes) != 1 || diff.ExitCodes[0].Code != 1 {
+		t.Errorf("unexpected multiline statement: %+v", program.Tasks[0].Body[1])
+	}
+}
+
+func TestParser_ShellExitCodeMappingErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing code":  `run "a" mapping exit code to "x"`,
+		"out of range":  `run "a" mapping exit code 300 to "x"`,
+		"duplicate":     `run "a" mapping exit code 2 to "x" and exit code 2 to "y"`,
+		"missing label": `run "a" mapping exit code 2`,
+		"empty message": `run "a" failing with ""`,
+		"two messages":  `run "a" failing with "x" failing with "y"`,
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := "version: 2.0\n\ntask \"t\":\n  " + line + "\n"
+			p := NewParser(lexer.NewLexer(input))
+			_ = p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				t.Fatalf("expected parser error for %q", line)
+			}
+		})
+	}
+}
+
+func TestParser_ShellRunAs(t *testing.T) {
+	input := `version: 2.0
+
+task "restart":
+  run "systemctl restart nginx" as root
+  run "whoami" quietly as user "deploy" failing with "deploy user missing"
+  run "systemctl is-active nginx" mapping exit code 3 to "inactive" as root
+  run as root:
+    apt-get update
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	want := []string{"root", "deploy", "root", "root"}
+	for i, runAs := range want {
+		stmt, ok := program.Tasks[0].Body[i].(*ast.ShellStatement)
+		if !ok {
+			t.Fatalf("statement %d: expected ShellStatement, got %T", i, program.Tasks[0].Body[i])
+		}
+		if stmt.RunAs != runAs {
+			t.Errorf("statement %d: RunAs = %q, want %q", i, stmt.RunAs, runAs)
+		}
+	}
+	deploy := program.Tasks[0].Body[1].(*ast.ShellStatement)
+	if deploy.Verbosity != "quiet" || deploy.FailureMessage != "deploy user missing" {
+		t.Errorf("expected the other modifiers to be kept, got %+v", deploy)
+	}
+	if got := program.Tasks[0].Body[0].String(); !strings.Contains(got, "as root") {
+		t.Errorf("String() = %q, want it to mention as root", got)
+	}
+
+	for _, stmt := range []string{
+		`run "id" as admin`,
+		`run "id" as user ""`,
+		`run "id" as root as root`,
+		`run "vim" attached as root`,
+	} {
+		l := lexer.NewLexer("version: 2.0\n\ntask \"t\":\n  " + stmt + "\n")
+		p := NewParser(l)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("Expected a parse error for %q", stmt)
+		}
+	}
+}
+
+func TestParser_CaptureLines(t *testing.T) {
+	input := `version: 2.0
+
+task "pods":
+  capture lines of "kubectl get pods -o name" as $pods
+  capture lines of "cat notes.txt" as $notes keeping empty lines without trimming
+  capture lines from "other"
+`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	tests := []struct {
+		command   string
+		variable  string
+		arguments []string
+		str       string
+	}{
+		{"kubectl get pods -o name", "$pods", nil, `capture lines of "kubectl get pods -o name" as $pods`},
+		{"cat notes.txt", "$notes", []string{"keep_empty", "no_trim"}, `capture lines of "cat notes.txt" as $notes keeping empty lines without trimming`},
+	}
+	for i, tt := range tests {
+		stmt, ok := program.Tasks[0].Body[i].(*ast.VariableStatement)
+		if !ok || stmt.Operation != "capture_lines" {
+			t.Fatalf("statement %d: expected a capture_lines VariableStatement, got %#v", i, program.Tasks[0].Body[i])
+		}
+		if stmt.Value.String() != tt.command || stmt.Variable != tt.variable || strings.Join(stmt.Arguments, ",") != strings.Join(tt.arguments, ",") {
+			t.Errorf("statement %d = (%q, %q, %v)", i, stmt.Value.String(), stmt.Variable, stmt.Arguments)
+		}
+		if got := stmt.String(); got != tt.str {
+			t.Errorf("statement %d String() = %q, want %q", i, got, tt.str)
+		}
+	}
+
+	// "capture lines from" still captures an expression into a variable named lines
+	if stmt, ok := program.Tasks[0].Body[2].(*ast.VariableStatement); !ok || stmt.Operation != "capture" || stmt.Variable != "lines" {
+		t.Errorf("expected an expression capture into 'lines', got %#v", program.Tasks[0].Body[2])
+	}
+}
+
+func TestParser_RunOnHosts(t *testing.T) {
+	input := `version: 2.0
+
+project "fleet":
+  hosts group "web" = ["web-1", "web-2", "web-3"]
+
+task "uptime":
+  run "uptime" on group "web" in parallel with 2 workers
+  run "df -h" on group "web" quietly
+  run "reboot" on host "web-1" failing with "reboot failed"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	group, ok := program.Project.Settings[0].(*ast.HostGroupStatement)
+	if !ok || group.Name != "web" || strings.Join(group.Hosts, ",") != "web-1,web-2,web-3" {
+		t.Fatalf("expected the web host group, got %#v", program.Project.Settings[0])
+	}
+
+	want := []string{
+		`run "uptime" on group "web" in parallel with 2 workers`,
+		`run "df -h" on group "web" quietly`,
+		`run "reboot" on host "web-1" failing with "reboot failed"`,
+	}
+	for i, str := range want {
+		stmt, ok := program.Tasks[0].Body[i].(*ast.ShellStatement)
+		if !ok {
+			t.Fatalf("statement %d: expected a ShellStatement, got %T", i, program.Tasks[0].Body[i])
+		}
+		if got := stmt.String(); got != str {
+			t.Errorf("statement %d String() = %q, want %q", i, got, str)
+		}
+	}
+}
+
+func TestParser_RunOnHostsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"unknown target", `  run "uptime" on rack "a"`, "expected 'host' or 'group' after 'on'"},
+		{"single host in parallel", `  run "uptime" on host "web-1" in parallel`, "in parallel needs a host group"},
+		{"zero workers", `  run "uptime" on group "web" in parallel with 0 workers`, "invalid worker count"},
+		{"piped", `  run "uptime" | run "grep load" on group "web"`, "cannot be combined with piped commands"},
+		{"attached", `  run "top" on group "web" attached`, "cannot be combined with attached"},
+		{"empty group", "project \"fleet\":\n  hosts group \"web\" = []\n\ntask \"t\":\n  info \"x\"", `host group "web" has no hosts`},
+		{"duplicate group", "project \"fleet\":\n  hosts group \"web\" = [\"a\"]\n  hosts group \"web\" = [\"b\"]\n\ntask \"t\":\n  info \"x\"", "declared more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "version: 2.0\n\n" + tt.input + "\n"
+			if !strings.HasPrefix(tt.input, "project") {
+				input = "version: 2.0\n\ntask \"t\":\n" + tt.input + "\n"
+			}
+			p := NewParser(lexer.NewLexer(input))
+			p.ParseProgram()
+			if !strings.Contains(strings.Join(p.Errors(), "\n"), tt.err) {
+				t.Errorf("expected an error containing %q, got %v", tt.err, p.Errors())
+			}
+		})
+	}
+}
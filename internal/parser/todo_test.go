@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_TodoStatement(t *testing.T) {
+	input := `version: 2.0
+
+project "demo":
+  set todo behavior to "skip"
+
+task "deploy":
+  todo "implement rollback"
+  if true:
+    todo "handle {$region}"
+  info "done"
+`
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	setting, ok := program.Project.Settings[0].(*ast.SetStatement)
+	if !ok || setting.Key != "todo_behavior" || setting.Value.String() != "skip" {
+		t.Errorf("expected the todo_behavior setting, got %+v", program.Project.Settings[0])
+	}
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(body))
+	}
+	todo, ok := body[0].(*ast.TodoStatement)
+	if !ok || todo.Message != "implement rollback" {
+		t.Fatalf("expected TodoStatement, got %T %+v", body[0], body[0])
+	}
+	if todo.String() != `todo "implement rollback"` {
+		t.Errorf("String() = %q", todo.String())
+	}
+	if todo.Token.Line != 7 {
+		t.Errorf("expected the todo on line 7, got %d", todo.Token.Line)
+	}
+	ifStmt, ok := body[1].(*ast.ConditionalStatement)
+	if !ok || len(ifStmt.Body) != 1 {
+		t.Fatalf("expected the todo inside the if block, got %T", body[1])
+	}
+	if nested, ok := ifStmt.Body[0].(*ast.TodoStatement); !ok || nested.Message != "handle {$region}" {
+		t.Errorf("expected TodoStatement in the if block, got %T", ifStmt.Body[0])
+	}
+}
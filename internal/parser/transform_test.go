@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_TransformFunctions(t *testing.T) {
+	tests := []struct {
+		input     string
+		function  string
+		arguments []string
+	}{
+		{`transform $name with length`, "length", nil},
+		{`transform $name with byte length`, "byte length", nil},
+		{`transform $name with slice 0 4`, "slice", []string{"0", "4"}},
+		{`transform $name with pad left 10`, "pad left", []string{"10"}},
+		{`transform $name with pad right 10 "."`, "pad right", []string{"10", "."}},
+		{`transform $name with pad right width`, "pad right", []string{"{width}"}},
+	}
+
+	for _, tt := range tests {
+		input := "version: 2.0\n\ntask \"text\":\n  " + tt.input + "\n"
+		p := NewParser(lexer.NewLexer(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Tasks[0].Body[0].(*ast.VariableStatement)
+		if !ok {
+			t.Fatalf("%s: expected VariableStatement, got %T", tt.input, program.Tasks[0].Body[0])
+		}
+		if stmt.Operation != "transform" || stmt.Variable != "$name" || stmt.Function != tt.function {
+			t.Errorf("%s: got %s %s with %q", tt.input, stmt.Operation, stmt.Variable, stmt.Function)
+		}
+		if !reflect.DeepEqual(stmt.Arguments, tt.arguments) {
+			t.Errorf("%s: arguments = %q, want %q", tt.input, stmt.Arguments, tt.arguments)
+		}
+	}
+}
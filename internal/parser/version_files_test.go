@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_VersionFilesStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ast.VersionFilesStatement
+	}{
+		{`get version from files ["package.json", "VERSION"] as $version`, ast.VersionFilesStatement{Operation: "get", Files: []string{"package.json", "VERSION"}, InSync: true, CaptureVar: "version"}},
+		{`bump version minor in files ["package.json", "Chart.yaml", "VERSION"] keeping them in sync`, ast.VersionFilesStatement{Operation: "bump", Level: "minor", Files: []string{"package.json", "Chart.yaml", "VERSION"}, InSync: true}},
+		{`bump version patch in files ["a.json", "b.json"]`, ast.VersionFilesStatement{Operation: "bump", Level: "patch", Files: []string{"a.json", "b.json"}}},
+		{`bump version major in files ["VERSION"] as $next`, ast.VersionFilesStatement{Operation: "bump", Level: "major", Files: []string{"VERSION"}, CaptureVar: "next"}},
+	}
+
+	for _, tt := range tests {
+		input := "version: 2.0\n\ntask \"release\":\n  " + tt.input + "\n  if true:\n    " + tt.input + "\n"
+		p := NewParser(lexer.NewLexer(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Tasks[0].Body[0].(*ast.VersionFilesStatement)
+		if !ok {
+			t.Fatalf("%s: expected VersionFilesStatement, got %T", tt.input, program.Tasks[0].Body[0])
+		}
+		stmt.Token = lexer.Token{}
+		if !reflect.DeepEqual(*stmt, tt.expected) {
+			t.Errorf("%s: got %+v", tt.input, stmt)
+		}
+		if stmt.String() != tt.input {
+			t.Errorf("String() = %q, want %q", stmt.String(), tt.input)
+		}
+		ifStmt, ok := program.Tasks[0].Body[1].(*ast.ConditionalStatement)
+		if !ok || len(ifStmt.Body) != 1 {
+			t.Fatalf("%s: expected the statement inside the if block, got %T", tt.input, program.Tasks[0].Body[1])
+		}
+		if _, ok := ifStmt.Body[0].(*ast.VersionFilesStatement); !ok {
+			t.Errorf("%s: expected VersionFilesStatement in the if block, got %T", tt.input, ifStmt.Body[0])
+		}
+	}
+}
+
+func TestParser_VersionFilesStatementErrors(t *testing.T) {
+	for _, input := range []string{
+		`bump version minorish in files ["VERSION"]`,
+		`bump version minor in files []`,
+		`bump version minor in files ["a.json"] keeping them aligned`,
+		`bump version minor in files ["a.json", "b.json"] as $next`,
+		`get version from files ["a.json"]`,
+	} {
+		p := NewParser(lexer.NewLexer("version: 2.0\n\ntask \"release\":\n  " + input + "\n"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %s", input)
+		}
+	}
+}
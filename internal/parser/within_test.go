@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/ast"
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+)
+
+func TestParser_WithinSections(t *testing.T) {
+	input := `version: 2.0
+
+task "ci":
+  within 10 minutes as "integration tests":
+    run "make integration"
+  if $ci is "true":
+    within "1m30s" or fail:
+      run "make lint"
+  info "done"`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	body := program.Tasks[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements in task body, got %d", len(body))
+	}
+
+	tests := []struct {
+		stmt   ast.Statement
+		budget string
+		name   string
+		fail   bool
+	}{
+		{body[0], "10m", "integration tests", false},
+		{body[1].(*ast.ConditionalStatement).Body[0], "1m30s", "", true},
+	}
+	for _, tt := range tests {
+		within, ok := tt.stmt.(*ast.WithinStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.WithinStatement, got %T", tt.stmt)
+		}
+		if within.Budget != tt.budget || within.Name != tt.name || within.Fail != tt.fail {
+			t.Errorf("within = (%q, %q, %t), want (%q, %q, %t)", within.Budget, within.Name, within.Fail, tt.budget, tt.name, tt.fail)
+		}
+		if len(within.Body) != 1 {
+			t.Errorf("Expected 1 statement in the section, got %d", len(within.Body))
+		}
+	}
+
+	if got := body[0].String(); got != "within 10m as \"integration tests\":\n  run \"make integration\"\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParser_WithinRejectsInvalidBudgets(t *testing.T) {
+	for _, line := range []string{
+		`within 10 parsecs:`,
+		`within "soon":`,
+		`within 5 minutes or warn:`,
+	} {
+		input := "version: 2.0\n\ntask \"ci\":\n  " + line + "\n    info \"work\""
+
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected a parse error for %q", line)
+		}
+	}
+}
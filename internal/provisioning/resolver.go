@@ -395,7 +395,7 @@ func (r *Resolver) fetchManifestContent(ctx context.Context, sourceRef string) (
 		return os.ReadFile(sourceRef)
 	}
 
-	cacheKey := cache.GenerateKey(sourceRef, "")
+	cacheKey := cache.GenerateCategoryKey(cache.CategoryCatalogs, sourceRef, "")
 	if r.cacheManager != nil {
 		if content, hit, err := r.cacheManager.Get(cacheKey); err == nil && hit {
 			return content, nil
@@ -10,6 +10,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/phillarmonic/drun/v2/internal/netretry"
 )
 
 // Fetcher defines the interface for remote content fetchers
@@ -82,9 +84,11 @@ func IsRemoteURL(url string) bool {
 type GitHubFetcher struct {
 	token         string
 	client        *http.Client
-	branchCache   map[string]string // Cache for default branches
+	branchCache   map[string]string   // Cache for default branches
+	tagCache      map[string][]string // Cache for published tags
 	cacheExpiry   map[string]time.Time
 	cacheDuration time.Duration
+	retry         netretry.Policy
 }
 
 // NewGitHubFetcher creates a new GitHub fetcher
@@ -93,11 +97,18 @@ func NewGitHubFetcher() *GitHubFetcher {
 		token:         os.Getenv("GITHUB_TOKEN"),
 		client:        &http.Client{Timeout: 30 * time.Second},
 		branchCache:   make(map[string]string),
+		tagCache:      make(map[string][]string),
 		cacheExpiry:   make(map[string]time.Time),
 		cacheDuration: 1 * time.Hour, // Cache default branches for 1 hour
+		retry:         netretry.DefaultPolicy(),
 	}
 }
 
+// SetRetryPolicy sets how requests that fail transiently are retried
+func (g *GitHubFetcher) SetRetryPolicy(policy netretry.Policy) {
+	g.retry = policy
+}
+
 // Protocol returns the protocol identifier
 func (g *GitHubFetcher) Protocol() string {
 	return "github"
@@ -139,7 +150,7 @@ func (g *GitHubFetcher) Fetch(ctx context.Context, path, ref string) ([]byte, er
 	}
 	req.Header.Set("User-Agent", "drun-remote-includes")
 
-	resp, err := g.client.Do(req)
+	resp, err := g.retry.Do(g.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch from GitHub: %w", err)
 	}
@@ -188,7 +199,7 @@ func (g *GitHubFetcher) getDefaultBranch(ctx context.Context, owner, repo, fileP
 	}
 	req.Header.Set("User-Agent", "drun-remote-includes")
 
-	resp, err := g.client.Do(req)
+	resp, err := g.retry.Do(g.client, req)
 	if err != nil {
 		// Fallback strategy: try main, then master
 		return g.tryDefaultBranchFallback(ctx, owner, repo, filePath)
@@ -213,6 +224,57 @@ func (g *GitHubFetcher) getDefaultBranch(ctx context.Context, owner, repo, fileP
 	return repoInfo.DefaultBranch, nil
 }
 
+// ListTags returns the names of the repository's tags
+func (g *GitHubFetcher) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	cacheKey := fmt.Sprintf("tags:%s/%s", owner, repo)
+	if tags, ok := g.tagCache[cacheKey]; ok {
+		if expiry, exists := g.cacheExpiry[cacheKey]; exists && time.Now().Before(expiry) {
+			return tags, nil
+		}
+	}
+
+	var tags []string
+	for page := 1; ; page++ {
+		apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/tags?per_page=100&page=%d", owner, repo, page)
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if g.token != "" {
+			req.Header.Set("Authorization", "Bearer "+g.token)
+		}
+		req.Header.Set("User-Agent", "drun-remote-includes")
+
+		resp, err := g.retry.Do(g.client, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s/%s: %w", owner, repo, err)
+		}
+		var batch []struct {
+			Name string `json:"name"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("failed to list tags of %s/%s: HTTP %d", owner, repo, resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&batch)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s/%s: %w", owner, repo, err)
+		}
+
+		for _, tag := range batch {
+			tags = append(tags, tag.Name)
+		}
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	g.tagCache[cacheKey] = tags
+	g.cacheExpiry[cacheKey] = time.Now().Add(g.cacheDuration)
+	return tags, nil
+}
+
 // tryDefaultBranchFallback tries common default branches
 func (g *GitHubFetcher) tryDefaultBranchFallback(ctx context.Context, owner, repo, filePath string) (string, error) {
 	// Try main first (modern default)
@@ -245,7 +307,7 @@ func (g *GitHubFetcher) fileExists(ctx context.Context, owner, repo, ref, filePa
 	}
 	req.Header.Set("User-Agent", "drun-remote-includes")
 
-	resp, err := g.client.Do(req)
+	resp, err := g.retry.Do(g.client, req)
 	if err != nil {
 		return false
 	}
@@ -257,15 +319,22 @@ func (g *GitHubFetcher) fileExists(ctx context.Context, owner, repo, ref, filePa
 // HTTPSFetcher fetches content from HTTPS URLs
 type HTTPSFetcher struct {
 	client *http.Client
+	retry  netretry.Policy
 }
 
 // NewHTTPSFetcher creates a new HTTPS fetcher
 func NewHTTPSFetcher() *HTTPSFetcher {
 	return &HTTPSFetcher{
 		client: &http.Client{Timeout: 30 * time.Second},
+		retry:  netretry.DefaultPolicy(),
 	}
 }
 
+// SetRetryPolicy sets how requests that fail transiently are retried
+func (h *HTTPSFetcher) SetRetryPolicy(policy netretry.Policy) {
+	h.retry = policy
+}
+
 // Protocol returns the protocol identifier
 func (h *HTTPSFetcher) Protocol() string {
 	return "https"
@@ -280,7 +349,7 @@ func (h *HTTPSFetcher) Fetch(ctx context.Context, url, _ string) ([]byte, error)
 
 	req.Header.Set("User-Agent", "drun-remote-includes")
 
-	resp, err := h.client.Do(req)
+	resp, err := h.retry.Do(h.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch from HTTPS: %w", err)
 	}
@@ -354,9 +423,33 @@ func (d *DrunhubFetcher) Fetch(ctx context.Context, path, ref string) ([]byte, e
 		path = path + ".drun"
 	}
 
+	// Resolve a version range such as ^1.2 to a published tag
+	ref, err := d.ResolveRef(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert to GitHub path: phillarmonic/drun-hub/{path}
 	githubPath := fmt.Sprintf("phillarmonic/drun-hub/%s", path)
 
 	// Use the GitHub fetcher to retrieve the content
 	return d.githubFetcher.Fetch(ctx, githubPath, ref)
 }
+
+// ListTags returns the tags published in the drunhub repository
+func (d *DrunhubFetcher) ListTags(ctx context.Context) ([]string, error) {
+	return d.githubFetcher.ListTags(ctx, "phillarmonic", "drun-hub")
+}
+
+// ResolveRef returns the highest published tag matching a version range
+// such as ^1.2; any other ref is returned unchanged
+func (d *DrunhubFetcher) ResolveRef(ctx context.Context, ref string) (string, error) {
+	if !IsVersionRange(ref) {
+		return ref, nil
+	}
+	tags, err := d.ListTags(ctx)
+	if err != nil {
+		return "", err
+	}
+	return ResolveVersionRange(ref, tags)
+}
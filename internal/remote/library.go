@@ -0,0 +1,107 @@
+package remote
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LibraryIndexFile is loaded when a library include names a directory
+const LibraryIndexFile = "index.drun"
+
+// Library locates a file of a multi-file remote library. The "//" in
+// github:org/repo//lib/docker@v1 separates the repository from the library
+// directory; relative includes inside the library resolve against Dir.
+type Library struct {
+	Protocol string
+	Repo     string // org/repo for github, the base URL for https
+	Dir      string // library directory within Repo
+	File     string // file to load, relative to Repo
+	Ref      string
+}
+
+// IsLibraryURL reports whether url names a file or directory below a "//"
+func IsLibraryURL(url string) bool {
+	_, ok, _ := ParseLibraryURL(url)
+	return ok
+}
+
+// ParseLibraryURL parses a library include URL. A path ending in .drun
+// names one file of the library, whose directory becomes the library
+// directory; any other path names the directory and loads its index.drun.
+// ok is false for URLs without a "//" subpath.
+func ParseLibraryURL(url string) (lib Library, ok bool, err error) {
+	protocol, remotePath, ref, err := ParseRemoteURL(url)
+	if err != nil {
+		return Library{}, false, err
+	}
+
+	search := remotePath
+	offset := 0
+	if protocol == "https" {
+		offset = len("https://")
+		search = remotePath[offset:]
+	}
+	idx := strings.Index(search, "//")
+	if idx < 0 {
+		return Library{}, false, nil
+	}
+	if protocol == "drunhub" {
+		return Library{}, false, fmt.Errorf("drunhub includes cannot name a library subpath: %s", url)
+	}
+
+	repo := remotePath[:offset+idx]
+	sub := strings.Trim(search[idx+2:], "/")
+	if sub == "" || !isLocalPath(sub) {
+		return Library{}, false, fmt.Errorf("invalid library path %q in %s", sub, url)
+	}
+
+	lib = Library{Protocol: protocol, Repo: repo, Ref: ref}
+	if strings.HasSuffix(sub, ".drun") {
+		lib.File = sub
+		lib.Dir = path.Dir(sub)
+	} else {
+		lib.File = path.Join(sub, LibraryIndexFile)
+		lib.Dir = sub
+	}
+	return lib, true, nil
+}
+
+// FetchPath is the path passed to the protocol's fetcher for lib.File
+func (l Library) FetchPath() string {
+	return l.Repo + "/" + l.File
+}
+
+// Contains reports whether other is a file of the same library
+func (l Library) Contains(other Library) bool {
+	return l.Protocol == other.Protocol && l.Repo == other.Repo && l.Ref == other.Ref && isLocalPath(other.File) &&
+		(l.Dir == "." || other.File == l.Dir || strings.HasPrefix(other.File, l.Dir+"/"))
+}
+
+// Resolve returns the URL of a file included by a library file with the
+// relative path name; it may not leave the library directory
+func (l Library) Resolve(name string) (string, error) {
+	file := Library{Protocol: l.Protocol, Repo: l.Repo, Dir: l.Dir, File: path.Join(l.Dir, name), Ref: l.Ref}
+	if !l.Contains(file) {
+		return "", fmt.Errorf("include %q leaves the library directory %s", name, l.Dir)
+	}
+	return file.URL(), nil
+}
+
+// URL returns the include URL of lib.File
+func (l Library) URL() string {
+	url := l.Protocol + ":" + l.Repo + "//" + l.File
+	if l.Protocol == "https" {
+		url = l.Repo + "//" + l.File
+	}
+	if l.Ref != "" {
+		url += "@" + l.Ref
+	}
+	return url
+}
+
+// isLocalPath reports whether p stays below the directory it is joined to
+func isLocalPath(p string) bool {
+	clean := path.Clean(p)
+	return clean != ".." && !strings.HasPrefix(clean, "../") && !path.IsAbs(clean)
+}
@@ -0,0 +1,56 @@
+package remote
+
+import "testing"
+
+func TestParseLibraryURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		fetch string
+		dir   string
+	}{
+		{"github:acme/ops//lib/docker@v1", "acme/ops/lib/docker/index.drun", "lib/docker"},
+		{"github:acme/ops//lib/docker/build.drun@v1", "acme/ops/lib/docker/build.drun", "lib/docker"},
+		{"github:acme/ops//main.drun", "acme/ops/main.drun", "."},
+		{"https://example.com/libs//docker", "https://example.com/libs/docker/index.drun", "docker"},
+	}
+	for _, tt := range tests {
+		lib, ok, err := ParseLibraryURL(tt.url)
+		if err != nil || !ok {
+			t.Fatalf("ParseLibraryURL(%q) = %v, %v", tt.url, ok, err)
+		}
+		if lib.FetchPath() != tt.fetch || lib.Dir != tt.dir {
+			t.Errorf("ParseLibraryURL(%q) fetches %q in %q, want %q in %q", tt.url, lib.FetchPath(), lib.Dir, tt.fetch, tt.dir)
+		}
+	}
+
+	for _, url := range []string{"github:acme/ops/docker.drun@v1", "https://example.com/docker.drun"} {
+		if _, ok, err := ParseLibraryURL(url); ok || err != nil {
+			t.Errorf("ParseLibraryURL(%q) = %v, %v; want a single-file include", url, ok, err)
+		}
+	}
+	for _, url := range []string{"github:acme/ops//../secrets", "drunhub:ops//docker"} {
+		if _, _, err := ParseLibraryURL(url); err == nil {
+			t.Errorf("ParseLibraryURL(%q) should fail", url)
+		}
+	}
+}
+
+func TestLibraryResolve(t *testing.T) {
+	lib, _, err := ParseLibraryURL("github:acme/ops//lib/docker@v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := lib.Resolve("internal/build.drun")
+	if err != nil || got != "github:acme/ops//lib/docker/internal/build.drun@v1" {
+		t.Fatalf("Resolve() = %q, %v", got, err)
+	}
+	if _, err := lib.Resolve("../shared.drun"); err == nil {
+		t.Fatal("expected an include outside the library directory to fail")
+	}
+
+	https, _, _ := ParseLibraryURL("https://example.com/libs//docker")
+	if got, _ := https.Resolve("push.drun"); got != "https://example.com/libs//docker/push.drun" {
+		t.Fatalf("Resolve() = %q", got)
+	}
+}
@@ -0,0 +1,160 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VendorDir is the directory, relative to the project root, that holds
+// vendored copies of remote includes.
+const VendorDir = "vendor/drun"
+
+// VendorLockFile is the name of the lockfile inside VendorDir.
+const VendorLockFile = "drun.lock"
+
+// vendorLockVersion is the current lockfile format version.
+const vendorLockVersion = 1
+
+// VendorLock records which remote includes are vendored and their checksums.
+type VendorLock struct {
+	Version  int           `json:"version"`
+	Includes []VendorEntry `json:"includes"`
+}
+
+// VendorEntry records one vendored remote include.
+type VendorEntry struct {
+	URL     string `json:"url"`               // include URL as written in the drun file
+	File    string `json:"file"`              // slash-separated path relative to VendorDir
+	SHA256  string `json:"sha256"`            // hex-encoded SHA-256 of the vendored content
+	Version string `json:"version,omitempty"` // tag a version range such as ^1.2 resolved to
+}
+
+// VendorRoot returns the project root a drun file vendors into: the file's
+// directory, or its parent when the file lives in .drun/.
+func VendorRoot(specFile string) string {
+	dir := filepath.Dir(specFile)
+	if filepath.Base(dir) == ".drun" {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// VendorLockPath returns the lockfile path for a project root.
+func VendorLockPath(root string) string {
+	return filepath.Join(root, filepath.FromSlash(VendorDir), VendorLockFile)
+}
+
+// LoadVendorLock reads the lockfile under root. The returned error wraps
+// os.ErrNotExist when the project has nothing vendored.
+func LoadVendorLock(root string) (*VendorLock, error) {
+	// #nosec G304 -- the lockfile lives at a fixed location under the project root.
+	data, err := os.ReadFile(VendorLockPath(root))
+	if err != nil {
+		return nil, err
+	}
+
+	var lock VendorLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", VendorLockFile, err)
+	}
+	if lock.Version != vendorLockVersion {
+		return nil, fmt.Errorf("unsupported %s version %d", VendorLockFile, lock.Version)
+	}
+	return &lock, nil
+}
+
+// Save writes the lockfile under root with entries sorted by URL.
+func (l *VendorLock) Save(root string) error {
+	l.Version = vendorLockVersion
+	sort.Slice(l.Includes, func(i, j int) bool { return l.Includes[i].URL < l.Includes[j].URL })
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	lockPath := VendorLockPath(root)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(lockPath, append(data, '\n'), 0600)
+}
+
+// Lookup returns the entry recorded for url.
+func (l *VendorLock) Lookup(url string) (VendorEntry, bool) {
+	if l == nil {
+		return VendorEntry{}, false
+	}
+	for _, entry := range l.Includes {
+		if entry.URL == url {
+			return entry, true
+		}
+	}
+	return VendorEntry{}, false
+}
+
+// Path returns the absolute location of the vendored file under root.
+func (e VendorEntry) Path(root string) string {
+	return filepath.Join(root, filepath.FromSlash(VendorDir), filepath.FromSlash(e.File))
+}
+
+// Verify checks the vendored file under root against the recorded checksum.
+func (e VendorEntry) Verify(root string) error {
+	// #nosec G304 -- vendored files live under the project vendor directory.
+	content, err := os.ReadFile(e.Path(root))
+	if err != nil {
+		return fmt.Errorf("%s: vendored copy missing: %w", e.URL, err)
+	}
+	if sum := Checksum(content); sum != e.SHA256 {
+		return fmt.Errorf("%s: checksum mismatch (lock %s, file %s)", e.URL, e.SHA256, sum)
+	}
+	return nil
+}
+
+// Checksum returns the hex-encoded SHA-256 of content.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+var unsafeVendorChars = regexp.MustCompile(`[^A-Za-z0-9._@-]`)
+
+// VendorFileName maps a remote include URL to a stable slash-separated path
+// inside VendorDir, e.g. github:acme/ops/docker.drun@v1 -> github/acme/ops/docker@v1.drun.
+func VendorFileName(url string) (string, error) {
+	protocol, remotePath, ref, err := ParseRemoteURL(url)
+	if err != nil {
+		return "", err
+	}
+
+	if protocol == "https" {
+		remotePath = strings.TrimPrefix(remotePath, "https://")
+		if idx := strings.IndexAny(remotePath, "?#"); idx != -1 {
+			remotePath = remotePath[:idx]
+		}
+	}
+
+	segments := []string{protocol}
+	for _, segment := range strings.Split(remotePath, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, unsafeVendorChars.ReplaceAllString(segment, "_"))
+	}
+	if len(segments) == 1 {
+		return "", fmt.Errorf("cannot derive a vendor path from %s", url)
+	}
+
+	name := strings.TrimSuffix(path.Join(segments...), ".drun")
+	if ref != "" {
+		name += "@" + unsafeVendorChars.ReplaceAllString(ref, "_")
+	}
+	return name + ".drun", nil
+}
@@ -0,0 +1,73 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVendorFileName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"github:acme/ops/docker.drun@v1.2.0", "github/acme/ops/docker@v1.2.0.drun"},
+		{"github:acme/ops/docker.drun", "github/acme/ops/docker.drun"},
+		{"drunhub:ops/docker", "drunhub/ops/docker.drun"},
+		{"drunhub:ops/docker@feature/x", "drunhub/ops/docker@feature_x.drun"},
+		{"https://example.com/a/../b.drun?token=1", "https/example.com/a/b.drun"},
+	}
+	for _, tt := range tests {
+		got, err := VendorFileName(tt.url)
+		if err != nil {
+			t.Fatalf("VendorFileName(%q) error = %v", tt.url, err)
+		}
+		if got != tt.want {
+			t.Errorf("VendorFileName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestVendorLockRoundTripAndVerify(t *testing.T) {
+	root := t.TempDir()
+	content := []byte("version: 2.0\n")
+	entry := VendorEntry{URL: "drunhub:ops/docker", File: "drunhub/ops/docker.drun", SHA256: Checksum(content)}
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path(root)), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry.Path(root), content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&VendorLock{Includes: []VendorEntry{entry}}).Save(root); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	lock, err := LoadVendorLock(root)
+	if err != nil {
+		t.Fatalf("LoadVendorLock() error = %v", err)
+	}
+	got, ok := lock.Lookup("drunhub:ops/docker")
+	if !ok || got != entry {
+		t.Fatalf("Lookup() = %+v, %v", got, ok)
+	}
+	if err := got.Verify(root); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if err := os.WriteFile(entry.Path(root), []byte("changed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := got.Verify(root); err == nil {
+		t.Fatal("Verify() expected checksum mismatch")
+	}
+}
+
+func TestVendorRoot(t *testing.T) {
+	if got := VendorRoot(filepath.Join("proj", ".drun", "spec.drun")); got != "proj" {
+		t.Errorf("VendorRoot(.drun/spec.drun) = %q, want proj", got)
+	}
+	if got := VendorRoot(filepath.Join("proj", "tasks.drun")); got != "proj" {
+		t.Errorf("VendorRoot(tasks.drun) = %q, want proj", got)
+	}
+}
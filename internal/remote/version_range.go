@@ -0,0 +1,167 @@
+package remote
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/phillarmonic/drun/v2/internal/scm"
+)
+
+var (
+	partialVersionPattern = regexp.MustCompile(`^v?([0-9]+)(?:\.([0-9]+))?(?:\.([0-9]+))?$`)
+	rangeClausePattern    = regexp.MustCompile(`^(>=|<=|>|<|=)\s*(v?[0-9]+(?:\.[0-9]+){0,2})$`)
+)
+
+// IsVersionRange reports whether ref is a semver range such as ^1.2, ~1.2.3,
+// 1.x or ">=1.2 <2" rather than a branch, tag or commit
+func IsVersionRange(ref string) bool {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return false
+	}
+	if strings.ContainsAny(ref[:1], "^~<>=") {
+		return true
+	}
+	prefix, ok := wildcardPrefix(ref)
+	return ok && partialVersionPattern.MatchString(prefix)
+}
+
+// ParseVersionRange parses a semver range into a version constraint.
+// ^1.2 allows 1.2.0 up to 2.0.0 (0.x ranges stay on the minor), ~1.2 allows
+// 1.2.x, 1.x and 1.2.x allow the series and operator clauses such as
+// ">=1.2 <2" are combined. Missing minor and patch numbers are zero.
+func ParseVersionRange(ref string) (scm.GitVersionConstraint, error) {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case strings.HasPrefix(ref, "^"):
+		lower, parts, err := parsePartialVersion(ref[1:])
+		if err != nil {
+			return scm.GitVersionConstraint{}, err
+		}
+		upper := newVersion(lower.Major+1, 0, 0)
+		if lower.Major == 0 && (lower.Minor > 0 || parts == 2) {
+			upper = newVersion(0, lower.Minor+1, 0)
+		} else if lower.Major == 0 && parts == 3 {
+			upper = newVersion(0, 0, lower.Patch+1)
+		}
+		return between(lower, upper), nil
+
+	case strings.HasPrefix(ref, "~"):
+		lower, parts, err := parsePartialVersion(ref[1:])
+		if err != nil {
+			return scm.GitVersionConstraint{}, err
+		}
+		upper := newVersion(lower.Major, lower.Minor+1, 0)
+		if parts == 1 {
+			upper = newVersion(lower.Major+1, 0, 0)
+		}
+		return between(lower, upper), nil
+	}
+
+	if prefix, ok := wildcardPrefix(ref); ok {
+		return scm.SeriesConstraint(strings.TrimPrefix(prefix, "v"))
+	}
+
+	var constraint scm.GitVersionConstraint
+	for _, field := range strings.FieldsFunc(ref, func(r rune) bool { return r == ' ' || r == ',' }) {
+		match := rangeClausePattern.FindStringSubmatch(field)
+		if match == nil {
+			return scm.GitVersionConstraint{}, fmt.Errorf("invalid version range %q", ref)
+		}
+		version, _, err := parsePartialVersion(match[2])
+		if err != nil {
+			return scm.GitVersionConstraint{}, err
+		}
+		constraint.Clauses = append(constraint.Clauses, scm.VersionConstraintClause{Operator: match[1], Version: version})
+	}
+	if len(constraint.Clauses) == 0 {
+		return scm.GitVersionConstraint{}, fmt.Errorf("invalid version range %q", ref)
+	}
+	return constraint, nil
+}
+
+// ResolveVersionRange returns the highest stable tag that satisfies ref.
+// Tags may carry a "v" prefix; other tags are ignored.
+func ResolveVersionRange(ref string, tags []string) (string, error) {
+	constraint, err := ParseVersionRange(ref)
+	if err != nil {
+		return "", err
+	}
+	tag, ok := highestTag(tags, constraint.Matches)
+	if !ok {
+		return "", fmt.Errorf("no published version matches %s", ref)
+	}
+	return tag, nil
+}
+
+// LatestTag returns the highest stable version tag of tags
+func LatestTag(tags []string) (string, bool) {
+	return highestTag(tags, func(scm.Version) bool { return true })
+}
+
+// TagVersion parses a version tag such as v1.2.3 or 1.2.3
+func TagVersion(tag string) (scm.Version, bool) {
+	version, err := scm.ParseVersion(strings.TrimPrefix(tag, "v"))
+	return version, err == nil
+}
+
+// highestTag returns the highest version tag accepted by match
+func highestTag(tags []string, match func(scm.Version) bool) (string, bool) {
+	var best string
+	var bestVersion scm.Version
+	for _, tag := range tags {
+		version, ok := TagVersion(tag)
+		if !ok || !match(version) {
+			continue
+		}
+		if best == "" || version.Compare(bestVersion) > 0 {
+			best, bestVersion = tag, version
+		}
+	}
+	return best, best != ""
+}
+
+// wildcardPrefix returns "1.2" for the wildcard ranges 1.2.x and 1.2.*
+func wildcardPrefix(ref string) (string, bool) {
+	for _, suffix := range []string{".x", ".X", ".*"} {
+		if prefix, ok := strings.CutSuffix(ref, suffix); ok {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// parsePartialVersion parses 1, 1.2 or 1.2.3 (optionally v-prefixed) and
+// returns how many components were given
+func parsePartialVersion(value string) (scm.Version, int, error) {
+	match := partialVersionPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return scm.Version{}, 0, fmt.Errorf("invalid version %q in range", value)
+	}
+	var numbers [3]uint64
+	parts := 0
+	for i := range numbers {
+		if match[i+1] == "" {
+			break
+		}
+		n, err := strconv.ParseUint(match[i+1], 10, 64)
+		if err != nil {
+			return scm.Version{}, 0, fmt.Errorf("invalid version %q in range", value)
+		}
+		numbers[i] = n
+		parts++
+	}
+	return newVersion(numbers[0], numbers[1], numbers[2]), parts, nil
+}
+
+func newVersion(major, minor, patch uint64) scm.Version {
+	return scm.Version{Major: major, Minor: minor, Patch: patch, Raw: fmt.Sprintf("%d.%d.%d", major, minor, patch)}
+}
+
+func between(lower, upper scm.Version) scm.GitVersionConstraint {
+	return scm.GitVersionConstraint{Clauses: []scm.VersionConstraintClause{
+		{Operator: ">=", Version: lower}, {Operator: "<", Version: upper},
+	}}
+}
@@ -0,0 +1,79 @@
+package remote
+
+import "testing"
+
+var publishedTags = []string{"v0.9.0", "v1.0.0", "v1.2.0", "v1.2.4", "v1.3.1", "v1.4.0-rc.1", "v2.0.0", "main", "nightly"}
+
+func TestIsVersionRange(t *testing.T) {
+	for _, ref := range []string{"^1.2", "~1.2.3", "1.x", "v1.2.x", "1.*", ">=1.2 <2", "=1.2.0"} {
+		if !IsVersionRange(ref) {
+			t.Errorf("IsVersionRange(%q) = false", ref)
+		}
+	}
+	for _, ref := range []string{"", "main", "v1", "v1.2.0", "abc123", "release.x"} {
+		if IsVersionRange(ref) {
+			t.Errorf("IsVersionRange(%q) = true", ref)
+		}
+	}
+}
+
+func TestResolveVersionRange(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"^1.2", "v1.3.1"},
+		{"^1", "v1.3.1"},
+		{"~1.2", "v1.2.4"},
+		{"~1.2.1", "v1.2.4"},
+		{"1.2.x", "v1.2.4"},
+		{"v1.x", "v1.3.1"},
+		{">=1.0 <1.3", "v1.2.4"},
+		{">=1.2, <=2", "v2.0.0"},
+		{"^0.9", "v0.9.0"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveVersionRange(tt.ref, publishedTags)
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveVersionRange(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+	}
+
+	if _, err := ResolveVersionRange("^3", publishedTags); err == nil {
+		t.Error("expected an error when no version matches")
+	}
+	if _, err := ResolveVersionRange("^one", publishedTags); err == nil {
+		t.Error("expected an error for an invalid range")
+	}
+}
+
+func TestParseVersionRangeZeroMajor(t *testing.T) {
+	tests := []struct {
+		ref            string
+		inside, beyond string
+	}{
+		{"^0.2.3", "0.2.9", "0.3.0"},
+		{"^0.0.3", "0.0.3", "0.0.4"},
+		{"^0", "0.9.9", "1.0.0"},
+	}
+	for _, tt := range tests {
+		constraint, err := ParseVersionRange(tt.ref)
+		if err != nil {
+			t.Fatalf("ParseVersionRange(%q) error = %v", tt.ref, err)
+		}
+		inside, _ := TagVersion(tt.inside)
+		beyond, _ := TagVersion(tt.beyond)
+		if !constraint.Matches(inside) || constraint.Matches(beyond) {
+			t.Errorf("%s should allow %s and not %s", tt.ref, tt.inside, tt.beyond)
+		}
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	if got, ok := LatestTag(publishedTags); !ok || got != "v2.0.0" {
+		t.Fatalf("LatestTag() = %q, %v", got, ok)
+	}
+	if _, ok := LatestTag([]string{"main"}); ok {
+		t.Fatal("expected no version tag")
+	}
+}
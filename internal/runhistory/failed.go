@@ -0,0 +1,111 @@
+package runhistory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// failedRunsFile is kept next to the run history and holds the latest run
+// with failed loop items of every project
+const failedRunsFile = "last-failed.json"
+
+// Target is a task a run was asked for, with the parameters it was given
+type Target struct {
+	Task   string            `json:"task"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// FailedRun records the items of parallel loops that failed in a run, so
+// `xdrun cmd:rerun --last-failed` can replay only those items with the same
+// tasks and parameters
+type FailedRun struct {
+	Project  string              `json:"project"`
+	Run      string              `json:"run"`
+	Targets  []Target            `json:"targets"`
+	Loops    map[string][]string `json:"loops"` // loop key (task/variable) -> failed items
+	FailedAt time.Time           `json:"failed_at"`
+}
+
+// Items returns how many loop items failed
+func (r FailedRun) Items() int {
+	count := 0
+	for _, items := range r.Loops {
+		count += len(items)
+	}
+	return count
+}
+
+// RecordFailed keeps run as the latest failed run of its project, replacing
+// the one recorded before
+func (s *Store) RecordFailed(run FailedRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.loadFailed()
+	if err != nil {
+		return err
+	}
+	runs[run.Project] = run
+	return s.saveFailed(runs)
+}
+
+// LastFailed returns the latest run of a project with failed loop items
+func (s *Store) LastFailed(project string) (FailedRun, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.loadFailed()
+	if err != nil {
+		return FailedRun{}, false, err
+	}
+	run, found := runs[project]
+	return run, found, nil
+}
+
+// ClearFailed forgets the failed run of a project, once its items passed
+func (s *Store) ClearFailed(project string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.loadFailed()
+	if err != nil {
+		return err
+	}
+	if _, found := runs[project]; !found {
+		return nil
+	}
+	delete(runs, project)
+	return s.saveFailed(runs)
+}
+
+func (s *Store) failedPath() string {
+	return filepath.Join(filepath.Dir(s.path), failedRunsFile)
+}
+
+func (s *Store) loadFailed() (map[string]FailedRun, error) {
+	runs := make(map[string]FailedRun)
+
+	data, err := os.ReadFile(s.failedPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return runs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read failed runs: %w", err)
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse failed runs %s: %w", s.failedPath(), err)
+	}
+	return runs, nil
+}
+
+func (s *Store) saveFailed(runs map[string]FailedRun) error {
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode failed runs: %w", err)
+	}
+	return writeFileAtomic(s.failedPath(), data)
+}
@@ -0,0 +1,210 @@
+// Package runhistory records the tasks that succeeded for a git commit, so
+// tasks declared `once per commit` are not run again for the same commit and
+// parameters.
+package runhistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxRecords bounds the history file; the oldest records are dropped first
+const maxRecords = 1000
+
+// Record describes a successful task run
+type Record struct {
+	Project    string            `json:"project"`
+	Task       string            `json:"task"`
+	Commit     string            `json:"commit"`
+	Params     map[string]string `json:"params,omitempty"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
+
+// Key identifies the run by project, task, commit and parameter values; the
+// finish time is not part of it
+func (r Record) Key() string {
+	names := make([]string, 0, len(r.Params))
+	for name := range r.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%s", r.Project, r.Task, r.Commit)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "\x00%s=%s", name, r.Params[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Input is one of the values a run's key is computed from, with a short
+// hash of its value
+type Input struct {
+	Name  string
+	Value string
+	Hash  string
+}
+
+// Inputs returns the values the key is computed from: the project, the task,
+// the commit and every parameter, by name
+func (r Record) Inputs() []Input {
+	inputs := []Input{
+		{Name: "project", Value: r.Project},
+		{Name: "task", Value: r.Task},
+		{Name: "commit", Value: r.Commit},
+	}
+	names := make([]string, 0, len(r.Params))
+	for name := range r.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		inputs = append(inputs, Input{Name: "param " + name, Value: r.Params[name]})
+	}
+	for i := range inputs {
+		sum := sha256.Sum256([]byte(inputs[i].Value))
+		inputs[i].Hash = hex.EncodeToString(sum[:6])
+	}
+	return inputs
+}
+
+// Store is a run history kept in a JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store backed by the file at path, which is created on
+// the first recorded run
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the location of the user's run history,
+// ~/.drun/run-history.json
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".drun", "run-history.json"), nil
+}
+
+// Lookup returns the recorded run with the key of run, if any
+func (s *Store) Lookup(run Record) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return Record{}, false, err
+	}
+	record, found := records[run.Key()]
+	return record, found, nil
+}
+
+// Latest returns the most recently finished run of a task in a project, for
+// any commit and parameters
+func (s *Store) Latest(project, task string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return Record{}, false, err
+	}
+	var latest Record
+	found := false
+	for _, record := range records {
+		if record.Project == project && record.Task == task && (!found || record.FinishedAt.After(latest.FinishedAt)) {
+			latest, found = record, true
+		}
+	}
+	return latest, found, nil
+}
+
+// Add records a successful run, replacing an earlier record with the same key
+func (s *Store) Add(run Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	records[run.Key()] = run
+
+	if len(records) > maxRecords {
+		keys := make([]string, 0, len(records))
+		for key := range records {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return records[keys[i]].FinishedAt.Before(records[keys[j]].FinishedAt)
+		})
+		for _, key := range keys[:len(records)-maxRecords] {
+			delete(records, key)
+		}
+	}
+
+	return s.save(records)
+}
+
+func (s *Store) load() (map[string]Record, error) {
+	records := make(map[string]Record)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run history: %w", err)
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse run history %s: %w", s.path, err)
+	}
+	return records, nil
+}
+
+// save writes records through a temporary file so a concurrent reader never
+// sees a partial history
+func (s *Store) save(records map[string]Record) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run history: %w", err)
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic replaces the file at path through a temporary file in the
+// same directory
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create run history directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".run-history-*")
+	if err != nil {
+		return fmt.Errorf("failed to write run history: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write run history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write run history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write run history: %w", err)
+	}
+	return nil
+}
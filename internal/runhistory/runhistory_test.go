@@ -0,0 +1,119 @@
+package runhistory
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreRecordsRunsByCommitAndParameters(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history", "run-history.json"))
+	run := Record{
+		Project: "/work/app",
+		Task:    "verify",
+		Commit:  "abc123",
+		Params:  map[string]string{"env": "prod", "region": "eu"},
+	}
+
+	if _, found, err := store.Lookup(run); err != nil || found {
+		t.Fatalf("Lookup() on an empty store = %v, %v; want not found", found, err)
+	}
+
+	run.FinishedAt = time.Now()
+	if err := store.Add(run); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// Lookups ignore the finish time and parameter order
+	same := Record{Project: "/work/app", Task: "verify", Commit: "abc123", Params: map[string]string{"region": "eu", "env": "prod"}}
+	if record, found, err := NewStore(store.path).Lookup(same); err != nil || !found || record.Task != "verify" {
+		t.Errorf("Lookup() = %+v, %v, %v; want the recorded run", record, found, err)
+	}
+
+	for _, other := range []Record{
+		{Project: "/work/app", Task: "verify", Commit: "def456", Params: run.Params},
+		{Project: "/work/app", Task: "verify", Commit: "abc123", Params: map[string]string{"env": "dev", "region": "eu"}},
+		{Project: "/work/other", Task: "verify", Commit: "abc123", Params: run.Params},
+	} {
+		if _, found, _ := store.Lookup(other); found {
+			t.Errorf("Lookup(%+v) found a run recorded for different inputs", other)
+		}
+	}
+}
+
+func TestStoreLatestAndInputs(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "run-history.json"))
+	if _, found, err := store.Latest("/work/app", "verify"); err != nil || found {
+		t.Fatalf("Latest() on an empty store = %v, %v; want not found", found, err)
+	}
+
+	now := time.Now()
+	for _, run := range []Record{
+		{Project: "/work/app", Task: "verify", Commit: "abc123", FinishedAt: now.Add(-time.Hour)},
+		{Project: "/work/app", Task: "verify", Commit: "def456", Params: map[string]string{"env": "prod"}, FinishedAt: now},
+		{Project: "/work/app", Task: "build", Commit: "fed987", FinishedAt: now.Add(time.Hour)},
+	} {
+		if err := store.Add(run); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	latest, found, err := store.Latest("/work/app", "verify")
+	if err != nil || !found || latest.Commit != "def456" {
+		t.Fatalf("Latest() = %+v, %v, %v; want the run of def456", latest, found, err)
+	}
+
+	inputs := latest.Inputs()
+	var names []string
+	for _, input := range inputs {
+		names = append(names, input.Name)
+		if len(input.Hash) != 12 {
+			t.Errorf("input %s has hash %q, want 12 hex digits", input.Name, input.Hash)
+		}
+	}
+	if got := strings.Join(names, ","); got != "project,task,commit,param env" {
+		t.Errorf("Inputs() names = %s", got)
+	}
+	if other := (Record{Commit: "abc123"}).Inputs(); other[2].Hash == inputs[2].Hash {
+		t.Error("different commits have the same input hash")
+	}
+}
+
+func TestStoreKeepsTheLastFailedRunPerProject(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "run-history.json"))
+	if _, found, err := store.LastFailed("/work/app"); err != nil || found {
+		t.Fatalf("LastFailed() on an empty store = %v, %v; want not found", found, err)
+	}
+
+	first := FailedRun{
+		Project: "/work/app",
+		Run:     "run-1",
+		Targets: []Target{{Task: "deploy", Params: map[string]string{"env": "prod"}}},
+		Loops:   map[string][]string{"deploy/region": {"eu", "us"}},
+	}
+	second := first
+	second.Run = "run-2"
+	second.Loops = map[string][]string{"deploy/region": {"us"}}
+	other := FailedRun{Project: "/work/other", Run: "run-3", Loops: map[string][]string{"build/pkg": {"a"}}}
+	for _, run := range []FailedRun{first, other, second} {
+		if err := store.RecordFailed(run); err != nil {
+			t.Fatalf("RecordFailed() error = %v", err)
+		}
+	}
+
+	run, found, err := NewStore(store.path).LastFailed("/work/app")
+	if err != nil || !found || run.Run != "run-2" || run.Items() != 1 || run.Targets[0].Params["env"] != "prod" {
+		t.Errorf("LastFailed() = %+v, %v, %v; want the second run", run, found, err)
+	}
+
+	if err := store.ClearFailed("/work/app"); err != nil {
+		t.Fatalf("ClearFailed() error = %v", err)
+	}
+	if _, found, _ := store.LastFailed("/work/app"); found {
+		t.Error("LastFailed() found a cleared run")
+	}
+	if _, found, _ := store.LastFailed("/work/other"); !found {
+		t.Error("ClearFailed() removed the run of another project")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package runtemp
+
+import "golang.org/x/sys/windows"
+
+// stillActive is the exit code Windows reports for a running process
+const stillActive = 259
+
+// processAlive reports whether a process with pid is running
+func processAlive(pid int) bool {
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid)) // #nosec G115 -- pids fit in uint32 on Windows
+	if err != nil {
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer func() { _ = windows.CloseHandle(process) }()
+	var code uint32
+	if err := windows.GetExitCodeProcess(process, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
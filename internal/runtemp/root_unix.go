@@ -0,0 +1,34 @@
+//go:build !windows
+
+package runtemp
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// rootName names the directory of the current user's run directories; the
+// system temporary directory is shared between users
+func rootName() string {
+	return "drun-" + strconv.Itoa(os.Getuid())
+}
+
+// ownedByCurrentUser reports whether info describes a file of the current user
+func ownedByCurrentUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
+
+// checkRootOwner rejects a root directory of another user, and one other
+// users can write to
+func checkRootOwner(path string, info os.FileInfo) error {
+	if !ownedByCurrentUser(info) {
+		return fmt.Errorf("%s is not owned by the current user", path)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("%s is accessible by other users (mode %#o)", path, info.Mode().Perm())
+	}
+	return nil
+}
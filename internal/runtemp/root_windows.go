@@ -0,0 +1,23 @@
+//go:build windows
+
+package runtemp
+
+import "os"
+
+// rootName names the directory of the run directories; the temporary
+// directory is already per user on Windows
+func rootName() string {
+	return "drun"
+}
+
+// ownedByCurrentUser reports whether info describes a file of the current
+// user; the temporary directory on Windows only holds the user's files
+func ownedByCurrentUser(os.FileInfo) bool {
+	return true
+}
+
+// checkRootOwner accepts the root directory; it is in the per-user temporary
+// directory and inherits its access control
+func checkRootOwner(string, os.FileInfo) error {
+	return nil
+}
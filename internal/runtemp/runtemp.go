@@ -0,0 +1,223 @@
+// Package runtemp keeps the temporary files of a drun run in a directory of
+// their own, so files left behind by a run that crashed can be found and
+// removed later. Each run directory records the PID and host of its run;
+// Sweep removes the directories whose run is no longer alive.
+package runtemp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ownerFile is the metadata file of a run directory
+const ownerFile = "owner.json"
+
+// runDirPrefix starts the name of every run directory
+const runDirPrefix = "run-"
+
+// legacyPattern matches the remote include files of drun versions that wrote
+// them straight into the system temporary directory
+const legacyPattern = "drun-remote-*.drun"
+
+// DefaultGrace is how old a run directory without readable metadata, or a
+// legacy temporary file, must be before Sweep removes it; it covers a run
+// that is still writing its metadata
+const DefaultGrace = time.Hour
+
+// Owner describes the run a directory belongs to
+type Owner struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Dir is the temporary directory of one run
+type Dir struct {
+	path string
+}
+
+// Root returns the directory the current user's run directories are created
+// in, drun-<uid> under the system temporary directory
+func Root() string {
+	return filepath.Join(os.TempDir(), rootName())
+}
+
+// checkRoot makes sure root is a directory of the current user that other
+// users cannot write to, so nothing another user planted there is removed
+// or written through
+func checkRoot(root string) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+	return checkRootOwner(root, info)
+}
+
+// sweepOnce sweeps stale run directories the first time a run directory is created
+var sweepOnce sync.Once
+
+// New creates the temporary directory of the current run. The first call of
+// a process also removes the directories of runs that are no longer alive.
+func New() (*Dir, error) {
+	root := Root()
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	if err := checkRoot(root); err != nil {
+		return nil, fmt.Errorf("refusing to use temporary directory: %w", err)
+	}
+	sweepOnce.Do(func() {
+		_, _ = Sweep(root, SweepOptions{Grace: DefaultGrace})
+	})
+
+	path, err := os.MkdirTemp(root, fmt.Sprintf("%s%d-*", runDirPrefix, os.Getpid()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	data, err := json.Marshal(Owner{PID: os.Getpid(), Host: host, StartedAt: time.Now()})
+	if err == nil {
+		err = os.WriteFile(filepath.Join(path, ownerFile), data, 0600)
+	}
+	if err != nil {
+		_ = os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to record the owner of %s: %w", path, err)
+	}
+	return &Dir{path: path}, nil
+}
+
+// Path returns the directory
+func (d *Dir) Path() string {
+	return d.path
+}
+
+// CreateFile creates a new file in the directory, named like os.CreateTemp names files
+func (d *Dir) CreateFile(pattern string) (*os.File, error) {
+	return os.CreateTemp(d.path, pattern)
+}
+
+// Remove deletes the directory and everything in it
+func (d *Dir) Remove() error {
+	return os.RemoveAll(d.path)
+}
+
+// SweepOptions controls which temporary files Sweep removes
+type SweepOptions struct {
+	Grace  time.Duration // minimum age of directories without metadata and of legacy files
+	DryRun bool          // report what would be removed without removing it
+}
+
+// Stale is a temporary directory or file left behind by a run
+type Stale struct {
+	Path   string
+	Owner  *Owner // nil for legacy files and directories without metadata
+	Size   int64
+	Reason string
+}
+
+// Sweep removes the run directories in root whose run is no longer alive,
+// and the current user's legacy temporary include files in the system
+// temporary directory. It returns what it removed, or would remove with
+// DryRun. A root that belongs to another user or that other users can write
+// to is an error.
+func Sweep(root string, opts SweepOptions) ([]Stale, error) {
+	if err := checkRoot(root); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("refusing to sweep: %w", err)
+	}
+	stale, err := findStale(root, opts.Grace, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return stale, nil
+	}
+
+	var errs []error
+	removed := stale[:0]
+	for _, entry := range stale {
+		if err := os.RemoveAll(entry.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, entry)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// findStale lists the stale run directories in root and the stale legacy files
+func findStale(root string, grace time.Duration, now time.Time) ([]Stale, error) {
+	var stale []Stale
+	host, _ := os.Hostname()
+
+	entries, err := os.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), runDirPrefix) {
+			continue
+		}
+		path := filepath.Join(root, entry.Name())
+		owner, err := readOwner(path)
+		switch {
+		case err != nil:
+			if info, statErr := entry.Info(); statErr == nil && now.Sub(info.ModTime()) >= grace {
+				stale = append(stale, Stale{Path: path, Size: dirSize(path), Reason: "no owner metadata"})
+			}
+		case owner.Host != host:
+			// The run belongs to another machine sharing this directory; its
+			// process cannot be checked from here
+		case owner.PID == os.Getpid():
+		case !processAlive(owner.PID):
+			stale = append(stale, Stale{Path: path, Owner: owner, Size: dirSize(path), Reason: fmt.Sprintf("process %d has exited", owner.PID)})
+		}
+	}
+
+	legacy, _ := filepath.Glob(filepath.Join(os.TempDir(), legacyPattern))
+	for _, path := range legacy {
+		if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() && ownedByCurrentUser(info) && now.Sub(info.ModTime()) >= grace {
+			stale = append(stale, Stale{Path: path, Size: info.Size(), Reason: "left by an older drun version"})
+		}
+	}
+	return stale, nil
+}
+
+// readOwner reads the metadata of a run directory
+func readOwner(dir string) (*Owner, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ownerFile)) // #nosec G304 -- dir is a drun run directory
+	if err != nil {
+		return nil, err
+	}
+	var owner Owner
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return nil, err
+	}
+	if owner.PID <= 0 {
+		return nil, fmt.Errorf("invalid pid %d", owner.PID)
+	}
+	return &owner, nil
+}
+
+// dirSize returns the total size of the files in dir
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
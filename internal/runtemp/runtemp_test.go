@@ -0,0 +1,176 @@
+package runtemp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exitedPID returns the pid of a process that has already exited
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	name, args := "true", []string{}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/c", "exit"}
+	}
+	cmd := exec.Command(name, args...)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("running %s: %v", name, err)
+	}
+	return cmd.Process.Pid
+}
+
+// writeRunDir creates a run directory in root owned by owner, or without
+// metadata when owner is nil
+func writeRunDir(t *testing.T, root, name string, owner *Owner) string {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "remote-1.drun"), []byte("task \"x\":\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if owner != nil {
+		data, _ := json.Marshal(owner)
+		if err := os.WriteFile(filepath.Join(dir, ownerFile), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNewRecordsTheOwnerAndRemoveDeletesTheDirectory(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	dir, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if filepath.Dir(dir.Path()) != Root() {
+		t.Fatalf("run directory %s is not under %s", dir.Path(), Root())
+	}
+	owner, err := readOwner(dir.Path())
+	if err != nil || owner.PID != os.Getpid() {
+		t.Fatalf("readOwner() = %+v, %v", owner, err)
+	}
+	file, err := dir.CreateFile("remote-*.drun")
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	_ = file.Close()
+
+	if stale, err := Sweep(Root(), SweepOptions{}); err != nil || len(stale) != 0 {
+		t.Fatalf("the directory of the running process must not be swept, got %+v, %v", stale, err)
+	}
+	if err := dir.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected the run directory to be removed, got %v", err)
+	}
+}
+
+func TestSweepRemovesDirectoriesOfExitedRuns(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	root := Root()
+	host, _ := os.Hostname()
+	old := time.Now().Add(-2 * time.Hour)
+
+	exited := writeRunDir(t, root, "run-1-a", &Owner{PID: exitedPID(t), Host: host})
+	running := writeRunDir(t, root, "run-2-b", &Owner{PID: os.Getpid(), Host: host})
+	remote := writeRunDir(t, root, "run-3-c", &Owner{PID: exitedPID(t), Host: host + ".elsewhere"})
+	orphan := writeRunDir(t, root, "run-4-d", nil)
+	fresh := writeRunDir(t, root, "run-5-e", nil)
+	legacy := filepath.Join(tmp, "drun-remote-123.drun")
+	if err := os.WriteFile(legacy, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{orphan, legacy} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stale, err := Sweep(root, SweepOptions{Grace: time.Hour, DryRun: true})
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	var paths []string
+	for _, entry := range stale {
+		paths = append(paths, entry.Path)
+	}
+	sort.Strings(paths)
+	want := []string{legacy, exited, orphan}
+	sort.Strings(want)
+	if len(paths) != len(want) {
+		t.Fatalf("Sweep() = %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("Sweep() = %v, want %v", paths, want)
+		}
+	}
+	if _, err := os.Stat(exited); err != nil {
+		t.Fatalf("a dry run must not remove anything: %v", err)
+	}
+
+	if _, err := Sweep(root, SweepOptions{Grace: time.Hour}); err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", path, err)
+		}
+	}
+	for _, path := range []string{running, remote, fresh} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept: %v", path, err)
+		}
+	}
+}
+
+func TestRootMustBelongToTheUserAndBePrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the temporary directory is per user on Windows")
+	}
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	if filepath.Base(Root()) != fmt.Sprintf("drun-%d", os.Getuid()) {
+		t.Fatalf("Root() = %s, want a per-user directory", Root())
+	}
+
+	if err := os.Mkdir(Root(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	// #nosec G302 -- the test needs a directory other users can write to
+	if err := os.Chmod(Root(), 0777); err != nil {
+		t.Fatal(err)
+	}
+	writeRunDir(t, Root(), "run-1-a", &Owner{PID: exitedPID(t)})
+
+	if _, err := New(); err == nil || !strings.Contains(err.Error(), "accessible by other users") {
+		t.Fatalf("New() with a shared root error = %v", err)
+	}
+	if _, err := Sweep(Root(), SweepOptions{}); err == nil || !strings.Contains(err.Error(), "refusing to sweep") {
+		t.Fatalf("Sweep() with a shared root error = %v", err)
+	}
+
+	if err := os.RemoveAll(Root()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(tmp, Root()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Fatalf("New() with a symlinked root error = %v", err)
+	}
+}
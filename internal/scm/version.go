@@ -46,6 +46,23 @@ func (v Version) Compare(other Version) int {
 	return 0
 }
 
+// Bump returns the next version at level "major", "minor" or "patch",
+// resetting the lower components
+func (v Version) Bump(level string) (Version, error) {
+	switch level {
+	case "major":
+		v = Version{Major: v.Major + 1}
+	case "minor":
+		v = Version{Major: v.Major, Minor: v.Minor + 1}
+	case "patch":
+		v = Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	default:
+		return Version{}, fmt.Errorf("unknown version level %q; expected major, minor or patch", level)
+	}
+	v.Raw = fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	return v, nil
+}
+
 type GitVersionConstraint struct {
 	Clauses []VersionConstraintClause
 }
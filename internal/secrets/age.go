@@ -0,0 +1,195 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Encrypted values in drun files are age files (https://age-encryption.org/v1)
+// encrypted to X25519 recipients, base64-encoded and wrapped in ENC[...]:
+//
+//	printf %s "$secret" | age -r age1... | base64 | tr -d '\n'
+//
+// They are decrypted with the same identities SOPS uses, so a user who can
+// edit SOPS files can run drun files with encrypted values.
+
+const (
+	encryptedPrefix = "ENC["
+	encryptedSuffix = "]"
+)
+
+// ErrNoAgeIdentity is returned when no age identity is configured
+var ErrNoAgeIdentity = errors.New("no age identity found")
+
+// AgeIdentity is an age X25519 private key (AGE-SECRET-KEY-1...)
+type AgeIdentity struct {
+	key *age.X25519Identity
+}
+
+// Recipient returns the public key values are encrypted to for identity
+func (i *AgeIdentity) Recipient() *AgeRecipient {
+	return &AgeRecipient{key: i.key.Recipient()}
+}
+
+// AgeRecipient is an age X25519 public key (age1...)
+type AgeRecipient struct {
+	key *age.X25519Recipient
+}
+
+// ParseAgeRecipient parses one age1... public key
+func ParseAgeRecipient(text string) (*AgeRecipient, error) {
+	key, err := age.ParseX25519Recipient(text)
+	if err != nil {
+		return nil, fmt.Errorf("malformed age recipient: %w", err)
+	}
+	return &AgeRecipient{key: key}, nil
+}
+
+func (r *AgeRecipient) String() string {
+	return r.key.String()
+}
+
+// IsEncryptedValue reports whether value is an ENC[...] encrypted value
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) && strings.HasSuffix(value, encryptedSuffix)
+}
+
+// DecryptValue decrypts an ENC[...] value with the first identity that is a
+// recipient of it
+func DecryptValue(value string, identities []*AgeIdentity) (string, error) {
+	if !IsEncryptedValue(value) {
+		return "", fmt.Errorf("value is not of the form ENC[...]")
+	}
+	encoded := strings.TrimSuffix(strings.TrimPrefix(value, encryptedPrefix), encryptedSuffix)
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return "", fmt.Errorf("value is not base64: %w", err)
+	}
+
+	keys := make([]age.Identity, 0, len(identities))
+	for _, identity := range identities {
+		keys = append(keys, identity.key)
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), keys...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return "", fmt.Errorf("none of the configured age identities can decrypt it")
+	}
+	if err != nil {
+		return "", fmt.Errorf("malformed age value: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("age payload does not decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// EncryptValue encrypts plaintext to recipients and returns it as an
+// ENC[...] value, the same as encrypting it with the age tool would
+func EncryptValue(plaintext string, recipients []*AgeRecipient) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("no age recipient to encrypt to")
+	}
+	keys := make([]age.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		keys = append(keys, recipient.key)
+	}
+
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, keys...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, plaintext); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(out.Bytes()) + encryptedSuffix, nil
+}
+
+// LoadAgeIdentities returns the age identities of the current user, read the
+// way SOPS reads them: from $SOPS_AGE_KEY, the file named by
+// $SOPS_AGE_KEY_FILE, and sops/age/keys.txt in the user configuration
+// directory. It returns ErrNoAgeIdentity when none of them has a key.
+func LoadAgeIdentities() ([]*AgeIdentity, error) {
+	var identities []*AgeIdentity
+	var looked []string
+
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		parsed, err := ParseAgeIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("SOPS_AGE_KEY: %w", err)
+		}
+		identities = append(identities, parsed...)
+	}
+	looked = append(looked, "$SOPS_AGE_KEY")
+
+	var files []string
+	if file := os.Getenv("SOPS_AGE_KEY_FILE"); file != "" {
+		files = append(files, file)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(dir, "sops", "age", "keys.txt"))
+	}
+	for _, file := range files {
+		looked = append(looked, file)
+		// #nosec G304 -- the key file is chosen by the user running drun.
+		content, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseAgeIdentities(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		identities = append(identities, parsed...)
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%w (looked in %s)", ErrNoAgeIdentity, strings.Join(looked, ", "))
+	}
+	return identities, nil
+}
+
+// ParseAgeIdentities reads AGE-SECRET-KEY-1... lines; blank lines and
+// # comments are skipped
+func ParseAgeIdentities(r io.Reader) ([]*AgeIdentity, error) {
+	var identities []*AgeIdentity
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		identity, err := ParseAgeIdentity(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		identities = append(identities, identity)
+	}
+	return identities, scanner.Err()
+}
+
+// ParseAgeIdentity parses one AGE-SECRET-KEY-1... key
+func ParseAgeIdentity(text string) (*AgeIdentity, error) {
+	key, err := age.ParseX25519Identity(text)
+	if err != nil {
+		return nil, fmt.Errorf("malformed age identity: %w", err)
+	}
+	return &AgeIdentity{key: key}, nil
+}
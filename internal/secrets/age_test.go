@@ -0,0 +1,201 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+// Fixed vectors made with the age and age-keygen commands of
+// filippo.io/age v1.2.1:
+//
+//	age-keygen -o keys1.txt; age-keygen -o keys2.txt
+//	printf %s hunter2 | age -r <recipient 1> -r <recipient 2> | base64
+//	printf %s s3cret | age -r <recipient 1> | base64
+//
+// ageTamperedVector is ageMultiVector with the first character of its header
+// MAC changed, which "age -d" rejects with "bad header MAC".
+const (
+	ageKeysFile1 = `# created: 2026-10-16T15:41:42Z
+# public key: age1s4r8vrsxkxjqcsrvqlzaunwzkqm8wj7sfyugsfdt9r98f5x69awq452gex
+AGE-SECRET-KEY-1U7KEXEGXGZETM59S6PJ9LTHM240XR9GKQMSGEY365GCGSQ4QTWQSHUG0V4
+`
+	ageKeysFile2 = `# created: 2026-10-16T15:41:42Z
+# public key: age14sv6k2ugds70nu36gtvme6wecn0p7p05l0nzs9g8fvq7du9g4dlquaxwzz
+AGE-SECRET-KEY-10S22XGEP5KKPWP3FM5QMSNH4U597TEA6WDN9X8V39WYQ9QGT3NPQHEJ6VH
+`
+
+	ageRecipient1 = "age1s4r8vrsxkxjqcsrvqlzaunwzkqm8wj7sfyugsfdt9r98f5x69awq452gex"
+	ageRecipient2 = "age14sv6k2ugds70nu36gtvme6wecn0p7p05l0nzs9g8fvq7du9g4dlquaxwzz"
+
+	ageMultiVector = "ENC[" +
+		"YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZc3luWXJHRmxXT1BpZklhUmpOSHBI" +
+		"ZEVvYmpJeU1HYTNyVytQOCsrZFI0Ckl6YVY2V3hNUnJ2MksvaS9QOS9mQXhnYjNaWCtKcDhP" +
+		"SFFOdWFHU25aSDgKLT4gWDI1NTE5IEVzeEQ2dEVPUjVSUThpU0VZLzRqN0xRMXB0ejBJYUdh" +
+		"cFZEdXBSSEUvaWcKWUxCS1JsVXoya2hnQjFOY0c0eDFUN2pnYnZhZjZFTWRBbWRtbUh4RjFs" +
+		"QQotLS0gcjlRVDNSY2RvU1JIYzlMMDl5OFdPaWFzYmhVSFlJK2NsaVRzcTlVamExRQp9GR+L" +
+		"NvwAa8w4bC2uJfAK7xmnK38OweoARBqydlj/vxasJ/1vpug=" +
+		"]"
+	ageSingleVector = "ENC[" +
+		"YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBTZExSZHMxNDdldThPMWRWcFR6YU1S" +
+		"Y1NIdUlNNWliVUYyU0FNdUFaU0ZFClpTeGRnb2hVLzhZbkN5MDM2NURyTDVDSVVjTUlhSXY2" +
+		"Mk9Sd2VYZUp4M0kKLS0tIHFlbnMxb1ExMWJqWDhRekRpVlRDbGxrdkJBTDI1L3daSnZ1SHY0" +
+		"VTZiZ0UK+Ck2OuP+LxdqCQsKA5mEJ2xvoAAx27IdERqwqv8nO/kzc5+vf90=" +
+		"]"
+	ageTamperedVector = "ENC[" +
+		"YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZc3luWXJHRmxXT1BpZklhUmpOSHBI" +
+		"ZEVvYmpJeU1HYTNyVytQOCsrZFI0Ckl6YVY2V3hNUnJ2MksvaS9QOS9mQXhnYjNaWCtKcDhP" +
+		"SFFOdWFHU25aSDgKLT4gWDI1NTE5IEVzeEQ2dEVPUjVSUThpU0VZLzRqN0xRMXB0ejBJYUdh" +
+		"cFZEdXBSSEUvaWcKWUxCS1JsVXoya2hnQjFOY0c0eDFUN2pnYnZhZjZFTWRBbWRtbUh4RjFs" +
+		"QQotLS0gQTlRVDNSY2RvU1JIYzlMMDl5OFdPaWFzYmhVSFlJK2NsaVRzcTlVamExRQp9GR+L" +
+		"NvwAa8w4bC2uJfAK7xmnK38OweoARBqydlj/vxasJ/1vpug=" +
+		"]"
+)
+
+// newTestIdentity returns a random identity and its AGE-SECRET-KEY-1... text
+func newTestIdentity(t *testing.T) (*AgeIdentity, string) {
+	t.Helper()
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity, err := ParseAgeIdentity(key.String())
+	if err != nil {
+		t.Fatalf("ParseAgeIdentity(%s) error = %v", key, err)
+	}
+	return identity, key.String()
+}
+
+// parseTestKeys parses a key file written by age-keygen
+func parseTestKeys(t *testing.T, content string) []*AgeIdentity {
+	t.Helper()
+	identities, err := ParseAgeIdentities(strings.NewReader(content))
+	if err != nil || len(identities) != 1 {
+		t.Fatalf("ParseAgeIdentities() = %d identities, %v", len(identities), err)
+	}
+	return identities
+}
+
+// encryptTestValue encrypts plaintext to identity
+func encryptTestValue(t *testing.T, identity *AgeIdentity, plaintext []byte) string {
+	t.Helper()
+	recipient, err := ParseAgeRecipient(identity.Recipient().String())
+	if err != nil {
+		t.Fatalf("ParseAgeRecipient() error = %v", err)
+	}
+	value, err := EncryptValue(string(plaintext), []*AgeRecipient{recipient})
+	if err != nil {
+		t.Fatalf("EncryptValue() error = %v", err)
+	}
+	return value
+}
+
+func TestDecryptValue(t *testing.T) {
+	identity, _ := newTestIdentity(t)
+	other, _ := newTestIdentity(t)
+
+	for _, plaintext := range []string{"", "hunter2", strings.Repeat("x", 64*1024), strings.Repeat("y", 64*1024*2+10)} {
+		value := encryptTestValue(t, identity, []byte(plaintext))
+		if !IsEncryptedValue(value) {
+			t.Fatalf("IsEncryptedValue(%q) = false", value)
+		}
+		got, err := DecryptValue(value, []*AgeIdentity{other, identity})
+		if err != nil {
+			t.Fatalf("DecryptValue() error = %v", err)
+		}
+		if got != plaintext {
+			t.Fatalf("DecryptValue() = %d bytes, want %d", len(got), len(plaintext))
+		}
+	}
+
+	value, err := EncryptValue("hunter2", []*AgeRecipient{other.Recipient(), identity.Recipient()})
+	if err != nil {
+		t.Fatalf("EncryptValue() error = %v", err)
+	}
+	if got, err := DecryptValue(value, []*AgeIdentity{identity}); err != nil || got != "hunter2" {
+		t.Fatalf("DecryptValue() with the second recipient = %q, %v", got, err)
+	}
+
+	value = encryptTestValue(t, identity, []byte("hunter2"))
+	if _, err := DecryptValue(value, []*AgeIdentity{other}); err == nil || !strings.Contains(err.Error(), "none of the configured age identities") {
+		t.Errorf("expected an error for a foreign identity, got %v", err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, "ENC["), "]"))
+	data[len(data)-1] ^= 1
+	tampered := "ENC[" + base64.StdEncoding.EncodeToString(data) + "]"
+	if _, err := DecryptValue(tampered, []*AgeIdentity{identity}); err == nil {
+		t.Error("expected an error for a tampered payload")
+	}
+
+	if _, err := DecryptValue("ENC[bm90IGFnZQ]", []*AgeIdentity{identity}); err == nil || !strings.Contains(err.Error(), "malformed age value") {
+		t.Errorf("expected an error for a value that is not an age file, got %v", err)
+	}
+}
+
+func TestDecryptAgeToolVectors(t *testing.T) {
+	keys1 := parseTestKeys(t, ageKeysFile1)
+	keys2 := parseTestKeys(t, ageKeysFile2)
+	if got := keys1[0].Recipient().String(); got != ageRecipient1 {
+		t.Fatalf("Recipient() = %s, want %s", got, ageRecipient1)
+	}
+	if got := keys2[0].Recipient().String(); got != ageRecipient2 {
+		t.Fatalf("Recipient() = %s, want %s", got, ageRecipient2)
+	}
+
+	for _, identities := range [][]*AgeIdentity{keys1, keys2, append(keys2, keys1...)} {
+		if got, err := DecryptValue(ageMultiVector, identities); err != nil || got != "hunter2" {
+			t.Errorf("DecryptValue(multi-recipient vector) = %q, %v", got, err)
+		}
+	}
+	if got, err := DecryptValue(ageSingleVector, keys1); err != nil || got != "s3cret" {
+		t.Errorf("DecryptValue(single-recipient vector) = %q, %v", got, err)
+	}
+	if _, err := DecryptValue(ageSingleVector, keys2); err == nil || !strings.Contains(err.Error(), "none of the configured age identities") {
+		t.Errorf("expected an error for an identity that is not a recipient, got %v", err)
+	}
+	if _, err := DecryptValue(ageTamperedVector, keys1); err == nil || !strings.Contains(err.Error(), "MAC") {
+		t.Errorf("expected a header MAC error for a tampered vector, got %v", err)
+	}
+}
+
+func TestLoadAgeIdentities(t *testing.T) {
+	_, text := newTestIdentity(t)
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	if _, err := LoadAgeIdentities(); !errors.Is(err, ErrNoAgeIdentity) {
+		t.Fatalf("expected ErrNoAgeIdentity, got %v", err)
+	}
+
+	keyFile := filepath.Join(dir, "keys.txt")
+	if err := os.WriteFile(keyFile, []byte(ageKeysFile1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyFile)
+	identities, err := LoadAgeIdentities()
+	if err != nil || len(identities) != 1 {
+		t.Fatalf("LoadAgeIdentities() = %d identities, %v", len(identities), err)
+	}
+	if got, err := DecryptValue(ageSingleVector, identities); err != nil || got != "s3cret" {
+		t.Fatalf("DecryptValue() with the SOPS key file = %q, %v", got, err)
+	}
+
+	t.Setenv("SOPS_AGE_KEY", text)
+	if identities, err := LoadAgeIdentities(); err != nil || len(identities) != 2 {
+		t.Fatalf("LoadAgeIdentities() with SOPS_AGE_KEY = %d identities, %v", len(identities), err)
+	}
+
+	t.Setenv("SOPS_AGE_KEY", "AGE-SECRET-KEY-1NOTAKEY")
+	if _, err := LoadAgeIdentities(); err == nil || !strings.Contains(err.Error(), "SOPS_AGE_KEY") {
+		t.Fatalf("expected an error naming SOPS_AGE_KEY, got %v", err)
+	}
+}
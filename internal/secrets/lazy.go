@@ -0,0 +1,73 @@
+package secrets
+
+import "sync"
+
+// lazyManager defers choosing and probing a backend until a secret is first
+// used, so commands that never touch secrets skip the keychain entirely
+type lazyManager struct {
+	opts    []ManagerOption
+	once    sync.Once
+	manager Manager
+	err     error
+}
+
+// NewLazyManager returns a Manager that creates its backend on first use.
+// Backend errors are returned by the first call and every call after it.
+func NewLazyManager(opts ...ManagerOption) Manager {
+	return &lazyManager{opts: opts}
+}
+
+func (l *lazyManager) get() (Manager, error) {
+	l.once.Do(func() {
+		l.manager, l.err = NewManager(l.opts...)
+	})
+	return l.manager, l.err
+}
+
+func (l *lazyManager) Set(namespace, key, value string) error {
+	manager, err := l.get()
+	if err != nil {
+		return err
+	}
+	return manager.Set(namespace, key, value)
+}
+
+func (l *lazyManager) Get(namespace, key string) (string, error) {
+	manager, err := l.get()
+	if err != nil {
+		return "", err
+	}
+	return manager.Get(namespace, key)
+}
+
+func (l *lazyManager) Delete(namespace, key string) error {
+	manager, err := l.get()
+	if err != nil {
+		return err
+	}
+	return manager.Delete(namespace, key)
+}
+
+func (l *lazyManager) Exists(namespace, key string) (bool, error) {
+	manager, err := l.get()
+	if err != nil {
+		return false, err
+	}
+	return manager.Exists(namespace, key)
+}
+
+func (l *lazyManager) List(namespace string) ([]string, error) {
+	manager, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return manager.List(namespace)
+}
+
+func (l *lazyManager) ListNamespaces() ([]string, error) {
+	manager, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return manager.ListNamespaces()
+}
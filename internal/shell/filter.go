@@ -0,0 +1,84 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// streamOutputs returns the writers stdout and stderr of the command are
+// shown on: Output, or with a LineFilter, writers that pass on only the lines
+// the filter keeps. Each stream gets its own filter, so a line of stdout is
+// never cut by one of stderr, and the two share a lock around Output, which
+// both stream goroutines write to. flush shows a last line that has no
+// newline; call it once both streams are read.
+func streamOutputs(opts *Options) (stdout, stderr io.Writer, flush func()) {
+	if opts.LineFilter == nil {
+		return opts.Output, opts.Output, func() {}
+	}
+	var mu sync.Mutex
+	stdoutFilter := &lineFilterWriter{w: opts.Output, mu: &mu, keep: opts.LineFilter}
+	stderrFilter := &lineFilterWriter{w: opts.Output, mu: &mu, keep: opts.LineFilter}
+	return stdoutFilter, stderrFilter, func() {
+		stdoutFilter.flush()
+		stderrFilter.flush()
+	}
+}
+
+// lineFilterWriter writes the lines keep accepts to w, holding mu while it
+// does. A line is held until its newline arrives, so keep always sees whole
+// lines (without the line ending).
+type lineFilterWriter struct {
+	w       io.Writer
+	mu      *sync.Mutex
+	keep    func(line string) bool
+	partial []byte
+}
+
+func (f *lineFilterWriter) Write(p []byte) (int, error) {
+	f.partial = append(f.partial, p...)
+	for {
+		end := bytes.IndexByte(f.partial, '\n')
+		if end < 0 {
+			break
+		}
+		line := f.partial[:end+1]
+		if f.keep(strings.TrimRight(string(line), "\r\n")) {
+			if _, err := f.write(line); err != nil {
+				return 0, err
+			}
+		}
+		f.partial = f.partial[end+1:]
+	}
+	return len(p), nil
+}
+
+func (f *lineFilterWriter) write(line []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Write(line)
+}
+
+// flush writes the last line when it has no newline and is kept
+func (f *lineFilterWriter) flush() {
+	if len(f.partial) > 0 && f.keep(strings.TrimRight(string(f.partial), "\r")) {
+		_, _ = f.write(f.partial)
+	}
+	f.partial = nil
+}
+
+// FilterLines returns the lines of text that keep accepts, joined by
+// newlines; it is how captured output is filtered
+func FilterLines(text string, keep func(line string) bool) string {
+	if keep == nil || text == "" {
+		return text
+	}
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		if keep(strings.TrimRight(line, "\r")) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,52 @@
+package shell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLineFilterShowsOnlyKeptLines(t *testing.T) {
+	if usesPowerShell(DefaultOptions().Shell) {
+		t.Skip("filter test uses POSIX utilities")
+	}
+
+	keep := func(line string) bool { return strings.Contains(line, "app") }
+	for name, run := range map[string]func(*Options) (*Result, error){
+		"command": func(opts *Options) (*Result, error) {
+			return Execute("printf 'app 1\\nother\\napp 2'; echo 'app err' >&2", opts)
+		},
+		"pipeline": func(opts *Options) (*Result, error) {
+			return ExecutePipeline([]string{"printf 'app 1\\nother\\napp 2'", "cat"}, opts)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := DefaultOptions()
+			opts.StreamOutput = true
+			opts.Output = &out
+			opts.LineFilter = keep
+
+			result, err := run(opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(out.String(), "other") || !strings.Contains(out.String(), "app 1\n") || !strings.Contains(out.String(), "app 2") {
+				t.Errorf("streamed output = %q, want only the app lines", out.String())
+			}
+			if !strings.Contains(result.Stdout, "other") {
+				t.Errorf("expected the result to keep the whole output, got %q", result.Stdout)
+			}
+		})
+	}
+}
+
+func TestFilterLines(t *testing.T) {
+	keep := func(line string) bool { return !strings.HasPrefix(line, "#") }
+	if got := FilterLines("a\r\n# b\nc", keep); got != "a\r\nc" {
+		t.Errorf("FilterLines() = %q", got)
+	}
+	if got := FilterLines("# only comments", keep); got != "" {
+		t.Errorf("FilterLines() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,64 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PrependPath returns a PATH value with entries placed ahead of base.
+// Empty entries are skipped and base is kept verbatim after them.
+func PrependPath(entries []string, base string) string {
+	parts := make([]string, 0, len(entries)+1)
+	for _, entry := range entries {
+		if entry != "" {
+			parts = append(parts, entry)
+		}
+	}
+	if base != "" {
+		parts = append(parts, base)
+	}
+	return strings.Join(parts, string(os.PathListSeparator))
+}
+
+// LookPathIn searches dirs in order for an executable named name.
+// On Windows the extensions listed in PATHEXT are tried as well.
+func LookPathIn(name string, dirs []string) (string, bool) {
+	candidates := []string{name}
+	if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+		pathExt := os.Getenv("PATHEXT")
+		if pathExt == "" {
+			pathExt = ".COM;.EXE;.BAT;.CMD"
+		}
+		for _, ext := range strings.Split(pathExt, ";") {
+			if ext != "" {
+				candidates = append(candidates, name+strings.ToLower(ext))
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, candidate := range candidates {
+			path := filepath.Join(dir, candidate)
+			if isExecutable(path) {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,180 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExecutePipeline runs commands with the stdout of each one streamed into the
+// stdin of the next, without temporary files. Output options apply to the
+// stdout of the last command and to the stderr of every command. Like a shell
+// pipeline with pipefail, it fails when any command fails; the result reports
+// the exit code of the last command that failed.
+func ExecutePipeline(commands []string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if len(commands) == 0 {
+		return nil, errors.New("pipeline has no commands")
+	}
+	if len(commands) == 1 {
+		return Execute(commands[0], opts)
+	}
+	if opts.Attached {
+		return nil, errors.New("pipelines cannot be attached to the terminal")
+	}
+
+	start := time.Now()
+
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	stdout, stderr, flush := pipelineWriters(opts, &stdoutBuf, &stderrBuf)
+
+	cmds := make([]*exec.Cmd, len(commands))
+	trees := make([]*processTree, len(commands))
+	for i, command := range commands {
+		cmds[i] = buildCommand(ctx, command, opts)
+		trees[i] = newProcessTree(cmds[i], false)
+		applyCommandOptions(cmds[i], opts)
+		cmds[i].Stderr = stderr
+	}
+	cmds[len(cmds)-1].Stdout = stdout
+
+	// Connect each command to the next; the parent closes its copies of the
+	// pipe ends once both sides have started so EOF propagates
+	var parentEnds []*os.File
+	closeParentEnds := func() {
+		for _, f := range parentEnds {
+			_ = f.Close()
+		}
+		parentEnds = nil
+	}
+	for i := 0; i < len(cmds)-1; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeParentEnds()
+			return nil, fmt.Errorf("failed to create pipe: %w", err)
+		}
+		cmds[i].Stdout = w
+		cmds[i+1].Stdin = r
+		parentEnds = append(parentEnds, r, w)
+	}
+
+	for i, cmd := range cmds {
+		err := cmd.Start()
+		started := i
+		if err == nil {
+			started = i + 1
+			err = trees[i].started()
+		}
+		if err != nil {
+			closeParentEnds()
+			for j := 0; j < started; j++ {
+				_ = trees[j].kill()
+				_ = cmds[j].Wait()
+				trees[j].release()
+			}
+			return nil, fmt.Errorf("failed to start command %q: %w", commands[i], err)
+		}
+		defer trees[i].release()
+	}
+	closeParentEnds()
+
+	stopForward, interrupted := forwardSignals(trees...)
+	defer stopForward()
+
+	result := &Result{Command: strings.Join(commands, " | ")}
+	failedStage := -1
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			var exitError *exec.ExitError
+			if !errors.As(err, &exitError) {
+				return nil, fmt.Errorf("command execution failed: %w", err)
+			}
+			result.ExitCode = exitError.ExitCode()
+			failedStage = i
+		}
+	}
+
+	flush()
+	result.Duration = time.Since(start)
+	result.Stdout = strings.TrimRight(stdoutBuf.String(), "\r\n")
+	result.Stderr = strings.TrimRight(stderrBuf.String(), "\r\n")
+	result.Success = failedStage < 0
+
+	if !result.Success || ctx.Err() != nil {
+		for _, tree := range trees {
+			_ = tree.kill()
+		}
+	}
+
+	if !result.Success && interrupted.Load() {
+		return result, fmt.Errorf("command %w (exit code %d in pipeline stage %d)", ErrInterrupted, result.ExitCode, failedStage+1)
+	}
+
+	if !result.Success && !opts.IgnoreErrors {
+		return result, fmt.Errorf("command failed with exit code %d in pipeline stage %d (%s)%s",
+			result.ExitCode, failedStage+1, commands[failedStage], formatFailureOutput(result))
+	}
+
+	return result, nil
+}
+
+// pipelineWriters returns the stdout and stderr destinations for a pipeline
+// according to the capture, stream and log options. The stderr writer is
+// shared by every command and therefore serialized. flush shows the last
+// streamed lines held by a line filter once every command has finished.
+func pipelineWriters(opts *Options, stdoutBuf, stderrBuf *bytes.Buffer) (stdoutWriter, stderrWriter io.Writer, flush func()) {
+	var stdout, stderr []io.Writer
+	if opts.CaptureOutput {
+		stdout = append(stdout, stdoutBuf)
+		stderr = append(stderr, stderrBuf)
+	}
+	flushStreams := func() {}
+	if opts.StreamOutput && opts.Output != nil {
+		var stdoutOutput, stderrOutput io.Writer
+		stdoutOutput, stderrOutput, flushStreams = streamOutputs(opts)
+		stdout = append(stdout, stdoutOutput)
+		stderr = append(stderr, stderrOutput)
+	}
+	if opts.LogWriter != nil {
+		stdout = append(stdout, opts.LogWriter)
+		stderr = append(stderr, opts.LogWriter)
+	}
+
+	var mu sync.Mutex
+	combine := func(writers []io.Writer) io.Writer {
+		if len(writers) == 0 {
+			return nil
+		}
+		return &lockedWriter{mu: &mu, w: io.MultiWriter(writers...)}
+	}
+	return combine(stdout), combine(stderr), flushStreams
+}
+
+// lockedWriter serializes writes from the output-copying goroutines of
+// several commands
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
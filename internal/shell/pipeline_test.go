@@ -0,0 +1,51 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecutePipeline_StreamsBetweenCommands(t *testing.T) {
+	if usesPowerShell(DefaultOptions().Shell) {
+		t.Skip("pipeline test uses POSIX utilities")
+	}
+
+	result, err := ExecutePipeline([]string{"printf 'b\\na\\nc\\n'", "sort", "tr a-z A-Z"}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Stdout != "A\nB\nC" {
+		t.Errorf("expected sorted upper-case output, got %q", result.Stdout)
+	}
+	if result.Command != "printf 'b\\na\\nc\\n' | sort | tr a-z A-Z" {
+		t.Errorf("unexpected command %q", result.Command)
+	}
+}
+
+func TestExecutePipeline_FailsWhenAnyStageFails(t *testing.T) {
+	if usesPowerShell(DefaultOptions().Shell) {
+		t.Skip("pipeline test uses POSIX utilities")
+	}
+
+	result, err := ExecutePipeline([]string{"echo data; exit 3", "cat"}, DefaultOptions())
+	if err == nil {
+		t.Fatal("expected the pipeline to fail")
+	}
+	if result == nil || result.ExitCode != 3 || result.Success {
+		t.Fatalf("expected exit code 3 from the first stage, got %+v", result)
+	}
+	if !strings.Contains(err.Error(), "pipeline stage 1") {
+		t.Errorf("expected the failing stage in the error, got %v", err)
+	}
+	if result.Stdout != "data" {
+		t.Errorf("expected downstream output to be kept, got %q", result.Stdout)
+	}
+}
+
+func TestExecutePipeline_RejectsAttached(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Attached = true
+	if _, err := ExecutePipeline([]string{"echo a", "cat"}, opts); err == nil {
+		t.Fatal("expected attached pipelines to be rejected")
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package shell
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// processTree groups a shell command with the processes it spawns so
+// cancellation reaches all of them. On Unix the command leads its own process
+// group; attached commands stay in the terminal's foreground group instead.
+type processTree struct {
+	cmd     *exec.Cmd
+	grouped bool
+}
+
+// newProcessTree prepares cmd, before it is started, to run in its own process
+// group and makes context cancellation kill the whole group
+func newProcessTree(cmd *exec.Cmd, attached bool) *processTree {
+	tree := &processTree{cmd: cmd, grouped: !attached}
+	if tree.grouped {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	cmd.Cancel = tree.kill
+	return tree
+}
+
+// started is called once the process is running; process groups need no
+// extra setup, so it never fails
+func (t *processTree) started() error {
+	return nil
+}
+
+// signal delivers sig to every process in the tree
+func (t *processTree) signal(sig os.Signal) error {
+	if t.cmd.Process == nil {
+		return nil
+	}
+	if s, ok := sig.(syscall.Signal); ok && t.grouped {
+		return ignoreFinished(syscall.Kill(-t.cmd.Process.Pid, s))
+	}
+	return t.cmd.Process.Signal(sig)
+}
+
+// kill forcibly stops every process in the tree, including descendants left
+// behind after the shell itself exited
+func (t *processTree) kill() error {
+	return t.signal(syscall.SIGKILL)
+}
+
+// release frees resources held for the tree
+func (t *processTree) release() {}
+
+func ignoreFinished(err error) error {
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
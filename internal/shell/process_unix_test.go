@@ -0,0 +1,125 @@
+//go:build !windows
+
+package shell
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// processGone reports whether pid has exited. Orphans may linger as zombies
+// when the container's init does not reap them, which counts as gone.
+func processGone(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return true
+	}
+	// #nosec G304 -- reading procfs for a test-spawned pid
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+func waitForProcessGone(t *testing.T, pid int) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if processGone(pid) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	_ = syscall.Kill(pid, syscall.SIGKILL)
+	t.Fatalf("background process %d survived its shell command", pid)
+}
+
+func readPID(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path) // #nosec G304 -- test temp file
+	if err != nil {
+		t.Fatalf("failed to read pid file: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("invalid pid %q: %v", data, err)
+	}
+	return pid
+}
+
+func TestExecute_FailureKillsBackgroundDescendants(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	opts := DefaultOptions()
+	opts.Shell = "/bin/sh"
+	_, err := Execute("sleep 30 >/dev/null 2>&1 & echo $! > "+pidFile+"; exit 3", opts)
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+
+	waitForProcessGone(t, readPID(t, pidFile))
+}
+
+func TestExecute_TimeoutKillsDescendants(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	opts := DefaultOptions()
+	opts.Shell = "/bin/sh"
+	opts.Timeout = 300 * time.Millisecond
+	start := time.Now()
+	_, err := Execute("sleep 30 & echo $! > "+pidFile+"; wait", opts)
+	if err == nil {
+		t.Fatal("expected the command to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("timeout did not stop the command tree, took %v", elapsed)
+	}
+
+	waitForProcessGone(t, readPID(t, pidFile))
+}
+
+func TestExecute_SuccessKeepsBackgroundProcesses(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	opts := DefaultOptions()
+	opts.Shell = "/bin/sh"
+	if _, err := Execute("sleep 30 >/dev/null 2>&1 & echo $! > "+pidFile, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pid := readPID(t, pidFile)
+	defer func() { _ = syscall.Kill(pid, syscall.SIGKILL) }()
+	time.Sleep(100 * time.Millisecond)
+	if processGone(pid) {
+		t.Fatal("a successful command's background process should keep running")
+	}
+}
+
+func TestExecute_SignalReportsInterrupted(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	opts := DefaultOptions()
+	opts.Shell = "/bin/sh"
+	opts.IgnoreErrors = true
+	go func() {
+		for i := 0; i < 100; i++ {
+			if _, err := os.Stat(pidFile); err == nil {
+				_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	// The pid file appears once the signals are forwarded
+	_, err := Execute("sleep 0.2; echo $$ > "+pidFile+"; sleep 30", opts)
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted even when errors are ignored, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+//go:build windows
+
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processTree groups a shell command with the processes it spawns so
+// cancellation reaches all of them. On Windows the command starts suspended
+// and is assigned to a Job Object before it runs, so every process it spawns
+// joins the job.
+type processTree struct {
+	cmd *exec.Cmd
+
+	mu  sync.Mutex // guards job, which kill reads from the cmd.Cancel goroutine
+	job windows.Handle
+}
+
+// newProcessTree prepares cmd, before it is started, to start suspended and
+// makes context cancellation terminate the whole job rather than only the shell
+func newProcessTree(cmd *exec.Cmd, attached bool) *processTree {
+	tree := &processTree{cmd: cmd}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+	cmd.Cancel = tree.kill
+	return tree
+}
+
+// started assigns the suspended process to a new Job Object and then resumes
+// it. Without a job the tree falls back to the shell process alone; an error
+// means the process could not be resumed and never ran.
+func (t *processTree) started() error {
+	if job, err := assignJob(t.cmd.Process.Pid); err == nil {
+		t.mu.Lock()
+		t.job = job
+		t.mu.Unlock()
+	}
+	return resumeProcess(t.cmd.Process.Pid)
+}
+
+// assignJob creates a Job Object and assigns the process with pid to it
+func assignJob(pid int) (windows.Handle, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid)) // #nosec G115 -- pids fit in uint32 on Windows
+	if err != nil {
+		_ = windows.CloseHandle(job)
+		return 0, err
+	}
+	defer func() { _ = windows.CloseHandle(process) }()
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		_ = windows.CloseHandle(job)
+		return 0, err
+	}
+	return job, nil
+}
+
+// resumeProcess resumes the threads of a process started with
+// CREATE_SUSPENDED. os/exec does not keep the main thread handle, so the
+// threads are found with a Toolhelp snapshot.
+func resumeProcess(pid int) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = windows.CloseHandle(snapshot) }()
+
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	resumed := false
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != uint32(pid) { // #nosec G115 -- pids fit in uint32 on Windows
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return err
+		}
+		_, err = windows.ResumeThread(thread)
+		_ = windows.CloseHandle(thread)
+		if err != nil {
+			return err
+		}
+		resumed = true
+	}
+	if !resumed {
+		return fmt.Errorf("no thread of process %d to resume", pid)
+	}
+	return nil
+}
+
+// signal delivers sig to the tree. Windows cannot send interrupts to another
+// process, so only os.Kill reaches descendants; console interrupts are already
+// delivered to every process attached to the console.
+func (t *processTree) signal(sig os.Signal) error {
+	if sig == os.Kill {
+		return t.kill()
+	}
+	if t.cmd.Process == nil {
+		return nil
+	}
+	return t.cmd.Process.Signal(sig)
+}
+
+// kill terminates every process in the job, falling back to the shell process
+// when it could not be assigned to one
+func (t *processTree) kill() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.job != 0 {
+		return windows.TerminateJobObject(t.job, 1)
+	}
+	if t.cmd.Process == nil {
+		return nil
+	}
+	return t.cmd.Process.Kill()
+}
+
+// release closes the job handle. The job is not created with
+// KILL_ON_JOB_CLOSE, so processes a successful command left running survive.
+func (t *processTree) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.job != 0 {
+		_ = windows.CloseHandle(t.job)
+		t.job = 0
+	}
+}
@@ -3,6 +3,7 @@ package shell
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,9 +11,13 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"golang.org/x/term"
 )
 
 // Result represents the result of a shell command execution
@@ -27,15 +32,42 @@ type Result struct {
 
 // Options configures shell command execution
 type Options struct {
-	WorkingDir    string            // Working directory for the command
-	Environment   map[string]string // Additional environment variables
-	Timeout       time.Duration     // Command timeout (0 = no timeout)
-	CaptureOutput bool              // Whether to capture stdout/stderr
-	StreamOutput  bool              // Whether to stream output in real-time
-	Output        io.Writer         // Where to stream output (if StreamOutput is true)
-	Shell         string            // Shell to use (default: /bin/sh)
-	IgnoreErrors  bool              // Whether to ignore non-zero exit codes
-	Attached      bool              // Whether to keep stdin attached and allocate a TTY when possible
+	WorkingDir    string                 // Working directory for the command
+	Environment   map[string]string      // Additional environment variables
+	Timeout       time.Duration          // Command timeout (0 = no timeout)
+	CaptureOutput bool                   // Whether to capture stdout/stderr
+	StreamOutput  bool                   // Whether to stream output in real-time
+	Output        io.Writer              // Where to stream output (if StreamOutput is true)
+	Shell         string                 // Shell to use (default: /bin/sh)
+	IgnoreErrors  bool                   // Whether to ignore non-zero exit codes
+	Attached      bool                   // Whether to keep stdin attached and allocate a TTY when possible
+	Interactive   bool                   // With Attached, fail instead of running without a terminal
+	LogWriter     io.Writer              // Optional writer receiving a copy of stdout/stderr (ignored when Attached)
+	Container     *Container             // Run the command inside a Docker container instead of on the host
+	InheritEnv    func(name string) bool // Optional filter for inherited variables; Environment is always passed
+	RunAs         string                 // Run the command as this user through sudo, such as "root"
+	LineFilter    func(line string) bool // Optional filter of streamed output: only the lines it keeps are shown (ignored when Attached)
+}
+
+// Container describes the Docker container a command runs in. The workspace
+// is mounted at the same path inside the container so host paths keep working.
+type Container struct {
+	Image     string // image to run, such as "golang:1.22"
+	Workspace string // host directory mounted into the container
+}
+
+// ErrNoTerminal is returned for interactive commands when drun is not
+// connected to a terminal, for example in CI or when input is piped
+var ErrNoTerminal = errors.New("interactive command needs a terminal, but stdin or stdout is not a TTY")
+
+// ErrInterrupted is returned when drun receives an interrupt or termination
+// signal while a command runs and the command, which receives the signal too,
+// fails. It is returned even when errors are ignored, so the run stops.
+var ErrInterrupted = errors.New("interrupted")
+
+// isTerminal reports whether f is connected to a terminal; tests replace it
+var isTerminal = func(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd())) // #nosec G115 -- file descriptors fit in int
 }
 
 // DefaultOptions returns sensible default options
@@ -99,6 +131,12 @@ func Execute(command string, opts *Options) (*Result, error) {
 		opts = DefaultOptions()
 	}
 
+	if opts.Interactive {
+		if err := checkInteractive(); err != nil {
+			return nil, err
+		}
+	}
+
 	start := time.Now()
 
 	// Create context with timeout if specified
@@ -114,6 +152,7 @@ func Execute(command string, opts *Options) (*Result, error) {
 
 	// Create the command
 	cmd := buildCommand(ctx, command, opts)
+	tree := newProcessTree(cmd, opts.Attached)
 
 	// Explicitly set stdin to nil to prevent commands from hanging waiting for input
 	// This is important for non-interactive command execution.
@@ -123,19 +162,7 @@ func Execute(command string, opts *Options) (*Result, error) {
 		cmd.Stdin = nil
 	}
 
-	// Set working directory
-	if opts.WorkingDir != "" {
-		cmd.Dir = opts.WorkingDir
-	}
-
-	// Set environment variables
-	if len(opts.Environment) > 0 {
-		env := os.Environ()
-		for key, value := range opts.Environment {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
-		}
-		cmd.Env = env
-	}
+	applyCommandOptions(cmd, opts)
 
 	result := &Result{
 		Command: command,
@@ -153,16 +180,35 @@ func Execute(command string, opts *Options) (*Result, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 		}
-	} else if opts.StreamOutput && opts.Output != nil {
+	} else if opts.StreamOutput && opts.Output != nil && opts.Attached {
 		cmd.Stdout = opts.Output
 		cmd.Stderr = opts.Output
+	} else if opts.StreamOutput && opts.Output != nil {
+		stdout, stderr, flushStreams := streamOutputs(opts)
+		defer flushStreams()
+		if opts.LogWriter != nil {
+			stdout = io.MultiWriter(stdout, opts.LogWriter)
+			stderr = io.MultiWriter(stderr, opts.LogWriter)
+		}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	} else if opts.LogWriter != nil && !opts.Attached {
+		cmd.Stdout = opts.LogWriter
+		cmd.Stderr = opts.LogWriter
 	}
 
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start command: %w", err)
 	}
+	if err := tree.started(); err != nil {
+		_ = tree.kill()
+		_ = cmd.Wait()
+		tree.release()
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	defer tree.release()
 
-	stopForward := forwardSignals(cmd)
+	stopForward, interrupted := forwardSignals(tree)
 	defer stopForward()
 
 	if opts.CaptureOutput {
@@ -174,9 +220,16 @@ func Execute(command string, opts *Options) (*Result, error) {
 		var stdoutWriter io.Writer = &stdoutBuf
 		var stderrWriter io.Writer = &stderrBuf
 
+		flushStreams := func() {}
 		if opts.StreamOutput && opts.Output != nil {
-			stdoutWriter = io.MultiWriter(opts.Output, &stdoutBuf)
-			stderrWriter = io.MultiWriter(opts.Output, &stderrBuf)
+			var stdoutOutput, stderrOutput io.Writer
+			stdoutOutput, stderrOutput, flushStreams = streamOutputs(opts)
+			stdoutWriter = io.MultiWriter(stdoutOutput, &stdoutBuf)
+			stderrWriter = io.MultiWriter(stderrOutput, &stderrBuf)
+		}
+		if opts.LogWriter != nil {
+			stdoutWriter = io.MultiWriter(stdoutWriter, opts.LogWriter)
+			stderrWriter = io.MultiWriter(stderrWriter, opts.LogWriter)
 		}
 
 		go func() {
@@ -194,6 +247,7 @@ func Execute(command string, opts *Options) (*Result, error) {
 		// completes races and can yield "read |0: file already closed".
 		stdoutErr := <-stdoutDone
 		stderrErr := <-stderrDone
+		flushStreams()
 		err := cmd.Wait()
 
 		if stdoutErr != nil && stdoutErr != io.EOF {
@@ -226,6 +280,16 @@ func Execute(command string, opts *Options) (*Result, error) {
 	result.Duration = time.Since(start)
 	result.Success = result.ExitCode == 0
 
+	// A failed or timed-out command must not leave descendants running, such
+	// as the dev server started by an npm script
+	if !result.Success || ctx.Err() != nil {
+		_ = tree.kill()
+	}
+
+	if !result.Success && interrupted.Load() {
+		return result, fmt.Errorf("command %w (exit code %d)", ErrInterrupted, result.ExitCode)
+	}
+
 	// Check if we should treat this as an error
 	if !result.Success && !opts.IgnoreErrors {
 		return result, fmt.Errorf("command failed with exit code %d%s", result.ExitCode, formatFailureOutput(result))
@@ -234,6 +298,40 @@ func Execute(command string, opts *Options) (*Result, error) {
 	return result, nil
 }
 
+// applyCommandOptions sets the working directory and environment of cmd
+func applyCommandOptions(cmd *exec.Cmd, opts *Options) {
+	// Set working directory
+	if opts.WorkingDir != "" {
+		cmd.Dir = opts.WorkingDir
+	}
+
+	// Set environment variables
+	if len(opts.Environment) > 0 || opts.InheritEnv != nil {
+		env := inheritedEnvironment(opts.InheritEnv)
+		for key, value := range opts.Environment {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+		cmd.Env = env
+	}
+}
+
+// inheritedEnvironment returns drun's environment, keeping only the variables
+// accept allows when it is set
+func inheritedEnvironment(accept func(name string) bool) []string {
+	env := os.Environ()
+	if accept == nil {
+		return env
+	}
+	kept := make([]string, 0, len(env))
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if accept(name) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
 func formatFailureOutput(result *Result) string {
 	stdout := strings.TrimSpace(result.Stdout)
 	stderr := strings.TrimSpace(result.Stderr)
@@ -251,6 +349,14 @@ func formatFailureOutput(result *Result) string {
 }
 
 func buildCommand(ctx context.Context, command string, opts *Options) *exec.Cmd {
+	if opts.Container != nil {
+		return createContainerCommand(ctx, command, opts)
+	}
+	if opts.RunAs != "" {
+		args := SudoArgs(opts.RunAs, opts.Shell, command)
+		// #nosec G204 -- escalated statements intentionally run the user-authored command through sudo.
+		return exec.CommandContext(ctx, args[0], args[1:]...)
+	}
 	if opts.Attached {
 		return createTTYCommand(ctx, command, opts.Shell)
 	}
@@ -259,6 +365,65 @@ func buildCommand(ctx context.Context, command string, opts *Options) *exec.Cmd
 	return exec.CommandContext(ctx, opts.Shell, "-c", command)
 }
 
+// SudoArgs returns the command line running command with shellPath as user.
+// sudo is never allowed to prompt (-n): the password is asked for once, up
+// front, and later commands reuse sudo's cached authentication.
+func SudoArgs(user, shellPath, command string) []string {
+	args := []string{"sudo", "-n"}
+	if user != "root" {
+		args = append(args, "-u", user)
+	}
+	return append(args, "--", shellPath, "-c", command)
+}
+
+// createContainerCommand runs command with sh in a throwaway container. The
+// additional environment is passed by name, so docker reads the values from
+// its own environment; PATH stays the image's own.
+func createContainerCommand(ctx context.Context, command string, opts *Options) *exec.Cmd {
+	workdir := opts.WorkingDir
+	if workdir == "" {
+		workdir = opts.Container.Workspace
+	}
+	mount := filepath.ToSlash(opts.Container.Workspace)
+
+	args := []string{"run", "--rm", "-i"}
+	if opts.Attached && isTerminal(os.Stdin) {
+		args = append(args, "-t")
+	}
+	args = append(args, "-v", mount+":"+mount, "-w", filepath.ToSlash(workdir))
+
+	keys := make([]string, 0, len(opts.Environment))
+	for key := range opts.Environment {
+		if key != "PATH" && key != "DRUN_SHELL_ARGS" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key)
+	}
+	args = append(args, opts.Container.Image, "sh", "-c", command)
+
+	// #nosec G204 -- container tasks intentionally run the user-authored command in the configured image.
+	return exec.CommandContext(ctx, "docker", args...)
+}
+
+// checkInteractive verifies that an interactive command can get a
+// pseudo-terminal: drun must run in one, and on Linux and macOS the script
+// utility that allocates it must be installed
+func checkInteractive() error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return ErrNoTerminal
+	}
+	switch runtime.GOOS {
+	case "darwin", "linux":
+		if _, err := exec.LookPath("script"); err != nil {
+			return fmt.Errorf("interactive command needs the 'script' utility to allocate a pseudo-terminal: %w", err)
+		}
+	}
+	return nil
+}
+
 func createTTYCommand(ctx context.Context, command, shellPath string) *exec.Cmd {
 	switch runtime.GOOS {
 	case "darwin":
@@ -290,10 +455,14 @@ func ExecuteWithOutput(command string, output io.Writer) (*Result, error) {
 	return Execute(command, opts)
 }
 
-func forwardSignals(cmd *exec.Cmd) func() {
+// forwardSignals relays interrupt and termination signals to the process
+// trees until the returned stop function is called. The flag records whether
+// any signal arrived.
+func forwardSignals(trees ...*processTree) (func(), *atomic.Bool) {
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 	done := make(chan struct{})
+	interrupted := &atomic.Bool{}
 
 	go func() {
 		for {
@@ -302,8 +471,9 @@ func forwardSignals(cmd *exec.Cmd) func() {
 				if !ok {
 					return
 				}
-				if cmd.Process != nil {
-					_ = cmd.Process.Signal(sig)
+				interrupted.Store(true)
+				for _, tree := range trees {
+					_ = tree.signal(sig)
 				}
 			case <-done:
 				return
@@ -315,5 +485,5 @@ func forwardSignals(cmd *exec.Cmd) func() {
 		close(done)
 		signal.Stop(signalCh)
 		close(signalCh)
-	}
+	}, interrupted
 }
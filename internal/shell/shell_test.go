@@ -3,6 +3,7 @@ package shell
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -353,6 +354,56 @@ func TestBuildCommand_AttachedUsesTTYOnUnix(t *testing.T) {
 	}
 }
 
+func TestExecute_InteractiveRequiresTerminal(t *testing.T) {
+	original := isTerminal
+	isTerminal = func(*os.File) bool { return false }
+	defer func() { isTerminal = original }()
+
+	opts := DefaultOptions()
+	opts.Attached = true
+	opts.Interactive = true
+
+	_, err := Execute("echo test", opts)
+	if !errors.Is(err, ErrNoTerminal) {
+		t.Fatalf("expected ErrNoTerminal, got %v", err)
+	}
+}
+
+func TestBuildCommand_ContainerRunsDocker(t *testing.T) {
+	opts := DefaultOptions()
+	opts.WorkingDir = "/work/app/sub"
+	opts.Environment["version"] = "v1"
+	opts.Environment["PATH"] = "/host/bin"
+	opts.Container = &Container{Image: "golang:1.22", Workspace: "/work/app"}
+
+	cmd := buildCommand(context.Background(), "go build ./...", opts)
+
+	if filepath.Base(cmd.Path) != "docker" && cmd.Path != "docker" {
+		t.Fatalf("expected docker, got %q", cmd.Path)
+	}
+	expected := "docker run --rm -i -v /work/app:/work/app -w /work/app/sub -e version golang:1.22 sh -c go build ./..."
+	if got := strings.Join(cmd.Args, " "); got != expected {
+		t.Fatalf("unexpected docker invocation:\n got: %s\nwant: %s", got, expected)
+	}
+}
+
+func TestBuildCommand_RunAsUsesSudo(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Shell = "/bin/sh"
+
+	opts.RunAs = "root"
+	cmd := buildCommand(context.Background(), "systemctl restart nginx", opts)
+	if got, want := strings.Join(cmd.Args, " "), "sudo -n -- /bin/sh -c systemctl restart nginx"; got != want {
+		t.Fatalf("unexpected sudo invocation:\n got: %s\nwant: %s", got, want)
+	}
+
+	opts.RunAs = "deploy"
+	cmd = buildCommand(context.Background(), "whoami", opts)
+	if got, want := strings.Join(cmd.Args, " "), "sudo -n -u deploy -- /bin/sh -c whoami"; got != want {
+		t.Fatalf("unexpected sudo invocation:\n got: %s\nwant: %s", got, want)
+	}
+}
+
 func TestBuildCommand_DefaultUsesShell(t *testing.T) {
 	opts := DefaultOptions()
 
@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// syntaxCheckTimeout bounds how long a shell may take to parse a command
+const syntaxCheckTimeout = 10 * time.Second
+
+// powerShellSyntaxCheck parses the script in $env:DRUN_SYNTAX_CHECK with the
+// PowerShell parser and prints its errors, without running the script
+const powerShellSyntaxCheck = `$errors = $null
+[void][System.Management.Automation.Language.Parser]::ParseInput($env:DRUN_SYNTAX_CHECK, [ref]$null, [ref]$errors)
+foreach ($e in $errors) { "line $($e.Extent.StartLineNumber): $($e.Message)" }
+if ($errors.Count -gt 0) { exit 1 }`
+
+// ErrSyntaxCheckUnsupported is returned for shells that cannot parse a
+// command without running it, such as cmd.exe
+var ErrSyntaxCheckUnsupported = errors.New("the shell has no syntax check")
+
+// SyntaxError is a command the shell could not parse
+type SyntaxError struct {
+	Shell   string
+	Message string // what the shell reported, e.g. "bash: line 1: syntax error near unexpected token `)'"
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+// CheckSyntax parses command with the shell at shellPath without running it:
+// POSIX shells read it with -n, PowerShell with its language parser. It
+// returns a *SyntaxError when the shell rejects the command, and another error
+// when the check could not be made.
+func CheckSyntax(command, shellPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), syntaxCheckTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch strings.TrimSuffix(strings.ToLower(filepath.Base(shellPath)), ".exe") {
+	case "cmd":
+		return ErrSyntaxCheckUnsupported
+	case "powershell", "pwsh":
+		// #nosec G204 -- the syntax check intentionally invokes the configured shell.
+		cmd = exec.CommandContext(ctx, shellPath, "-NoProfile", "-NonInteractive", "-Command", powerShellSyntaxCheck)
+		cmd.Env = append(os.Environ(), "DRUN_SYNTAX_CHECK="+command)
+	default:
+		// #nosec G204 -- the syntax check intentionally invokes the configured shell.
+		cmd = exec.CommandContext(ctx, shellPath, "-n")
+		cmd.Stdin = strings.NewReader(command + "\n")
+	}
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || ctx.Err() != nil {
+		return fmt.Errorf("cannot check syntax with %s: %w", shellPath, err)
+	}
+	message := strings.TrimSpace(output.String())
+	if message == "" {
+		message = fmt.Sprintf("%s exited with code %d", filepath.Base(shellPath), exitErr.ExitCode())
+	}
+	return &SyntaxError{Shell: shellPath, Message: message}
+}
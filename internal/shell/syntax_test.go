@@ -0,0 +1,47 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	shellPath := DefaultOptions().Shell
+	if usesPowerShell(shellPath) {
+		t.Skip("syntax test uses POSIX shell syntax")
+	}
+
+	for _, command := range []string{
+		"echo 'hello world' | tr a-z A-Z",
+		"if [ -f go.mod ]; then\n  go build ./...\nfi",
+		"rm -rf /definitely/not/run",
+	} {
+		if err := CheckSyntax(command, shellPath); err != nil {
+			t.Errorf("CheckSyntax(%q) error = %v", command, err)
+		}
+	}
+
+	for _, command := range []string{
+		"echo $(date",
+		"echo 'unterminated",
+		"if true; then\n  echo yes",
+	} {
+		err := CheckSyntax(command, shellPath)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Message == "" {
+			t.Errorf("CheckSyntax(%q) error = %v, want a syntax error", command, err)
+		}
+	}
+}
+
+func TestCheckSyntaxUnsupportedShells(t *testing.T) {
+	if err := CheckSyntax("dir", "cmd.exe"); !errors.Is(err, ErrSyntaxCheckUnsupported) {
+		t.Errorf("CheckSyntax(cmd.exe) error = %v, want ErrSyntaxCheckUnsupported", err)
+	}
+	err := CheckSyntax("echo hi", "/definitely/missing/shell")
+	var syntaxErr *SyntaxError
+	if err == nil || errors.As(err, &syntaxErr) || !strings.Contains(err.Error(), "cannot check syntax") {
+		t.Errorf("CheckSyntax(missing shell) error = %v, want a failed check", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Package stdlib holds the drun standard library. It is compiled into the
+// binary, so `include from drunhub std/core` works offline and always matches
+// the running version of drun.
+package stdlib
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed std/*.drun
+var files embed.FS
+
+// Lookup returns the content of the built-in library at a drunhub path such
+// as "std/core" (the .drun extension is optional)
+func Lookup(name string) ([]byte, bool) {
+	if !strings.HasPrefix(name, "std/") {
+		return nil, false
+	}
+	if !strings.HasSuffix(name, ".drun") {
+		name += ".drun"
+	}
+	content, err := files.ReadFile(path.Clean(name))
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// Names returns the drunhub paths of the built-in libraries, sorted
+func Names() []string {
+	entries, _ := fs.ReadDir(files, "std")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, "std/"+strings.TrimSuffix(entry.Name(), ".drun"))
+	}
+	sort.Strings(names)
+	return names
+}
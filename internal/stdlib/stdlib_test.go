@@ -0,0 +1,42 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/phillarmonic/drun/v2/internal/lexer"
+	"github.com/phillarmonic/drun/v2/internal/parser"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"std/core", "std/core.drun"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Lookup(%q) found nothing", name)
+		}
+	}
+	for _, name := range []string{"ops/docker", "std/missing", "core"} {
+		if _, ok := Lookup(name); ok {
+			t.Errorf("Lookup(%q) should find nothing", name)
+		}
+	}
+}
+
+func TestLibrariesParse(t *testing.T) {
+	names := Names()
+	if len(names) == 0 {
+		t.Fatal("expected at least one built-in library")
+	}
+	for _, name := range names {
+		content, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("Names() listed %q but Lookup found nothing", name)
+		}
+		p := parser.NewParser(lexer.NewLexer(string(content)))
+		program := p.ParseProgram()
+		if errs := p.Errors(); len(errs) > 0 {
+			t.Fatalf("%s does not parse: %v", name, errs)
+		}
+		if program.Project == nil || program.Project.Name != "std" {
+			t.Errorf("%s should declare project \"std\"", name)
+		}
+	}
+}
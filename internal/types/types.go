@@ -38,7 +38,7 @@ func (pt ParameterType) String() string {
 // ParseParameterType parses a string into a ParameterType
 func ParseParameterType(s string) (ParameterType, error) {
 	switch strings.ToLower(s) {
-	case "string":
+	case "string", "file":
 		return StringType, nil
 	case "number":
 		return NumberType, nil
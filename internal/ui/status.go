@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Status categories the icons of status messages fall into. Symbols and
+// colors are configured per category.
+const (
+	StatusInfo  = "info"
+	StatusOK    = "ok"
+	StatusWarn  = "warn"
+	StatusError = "error"
+	StatusFail  = "fail"
+)
+
+// statusCategories lists the categories in the order they are documented
+var statusCategories = []string{StatusInfo, StatusOK, StatusWarn, StatusError, StatusFail}
+
+// statusOf returns the category of an emoji symbol; symbols with no
+// category of their own, such as 🐳, are informational
+func statusOf(symbol string) string {
+	switch symbol {
+	case "⚠", "🚨":
+		return StatusWarn
+	case "❌", "🚫", "🔴":
+		return StatusError
+	case "💥":
+		return StatusFail
+	case "✅", "✓", "✔", "🎉":
+		return StatusOK
+	default:
+		return StatusInfo
+	}
+}
+
+// Symbols of the presets. The plain tags are machine-stable; the ASCII
+// symbols avoid anything outside 7-bit ASCII; the high-contrast symbols
+// differ in shape, so no category is told apart by color alone.
+var (
+	plainSymbols = map[string]string{
+		StatusInfo: "[INFO]", StatusOK: "[OK]", StatusWarn: "[WARN]", StatusError: "[ERROR]", StatusFail: "[FAIL]",
+	}
+	asciiSymbols = map[string]string{
+		StatusInfo: "[i]", StatusOK: "[+]", StatusWarn: "[!]", StatusError: "[x]", StatusFail: "[X]",
+	}
+	highContrastSymbols = map[string]string{
+		StatusInfo: "ℹ", StatusOK: "✔", StatusWarn: "▲", StatusError: "✖", StatusFail: "‼",
+	}
+	// highContrastColors pairs blue with yellow and magenta, which stay
+	// distinct for the common forms of color blindness
+	highContrastColors = map[string]string{
+		StatusOK: "1;34", StatusWarn: "1;33", StatusError: "1;35", StatusFail: "1;35",
+	}
+)
+
+// colorCodes are the SGR codes of the color names accepted in status colors
+var colorCodes = map[string]string{
+	"bold": "1", "dim": "2", "underline": "4",
+	"black": "30", "red": "31", "green": "32", "yellow": "33", "blue": "34", "magenta": "35", "cyan": "36", "white": "37",
+	"gray": "90", "bright-red": "91", "bright-green": "92", "bright-yellow": "93", "bright-blue": "94",
+	"bright-magenta": "95", "bright-cyan": "96", "bright-white": "97",
+}
+
+// SetStatusSymbols overrides the symbols of some status categories, given as
+// a comma-separated list such as "ok=✔, warn=▲". Categories left out keep the
+// symbol of the output style.
+func (t *Theme) SetStatusSymbols(spec string) error {
+	overrides, err := parseStatusList(spec)
+	if err != nil {
+		return err
+	}
+	for status, symbol := range overrides {
+		if t.symbols == nil {
+			t.symbols = make(map[string]string)
+		}
+		t.symbols[status] = symbol
+	}
+	return nil
+}
+
+// SetStatusColors overrides the colors of some status categories, given as
+// a comma-separated list such as "ok=bold blue, error=magenta". A color is
+// one or more names such as bold, red or bright-cyan; none removes the color.
+func (t *Theme) SetStatusColors(spec string) error {
+	overrides, err := parseStatusList(spec)
+	if err != nil {
+		return err
+	}
+	for status, names := range overrides {
+		var codes []string
+		for _, name := range strings.Fields(strings.ToLower(names)) {
+			if name == "none" {
+				continue
+			}
+			code, ok := colorCodes[name]
+			if !ok {
+				return fmt.Errorf("unknown color %q for %s", name, status)
+			}
+			codes = append(codes, code)
+		}
+		if t.colors == nil {
+			t.colors = make(map[string]string)
+		}
+		t.colors[status] = strings.Join(codes, ";")
+	}
+	return nil
+}
+
+// parseStatusList parses "category=value, ..." into a map
+func parseStatusList(spec string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		status, value, ok := strings.Cut(entry, "=")
+		status, value = strings.ToLower(strings.TrimSpace(status)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("expected category=value, got %q", entry)
+		}
+		if !isStatusCategory(status) {
+			return nil, fmt.Errorf("unknown status category %q (supported: %s)", status, strings.Join(statusCategories, ", "))
+		}
+		values[status] = value
+	}
+	return values, nil
+}
+
+// isStatusCategory reports whether name is a status category
+func isStatusCategory(name string) bool {
+	for _, status := range statusCategories {
+		if name == status {
+			return true
+		}
+	}
+	return false
+}
+
+// colorize wraps text in the color of a status category; NO_COLOR turns
+// colors off
+func (t *Theme) colorize(status, text string) string {
+	code := t.colors[status]
+	if code == "" || os.Getenv("NO_COLOR") != "" {
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
+
+// copyStatusMap returns a copy of a preset so overrides never change it
+func copyStatusMap(preset map[string]string) map[string]string {
+	copied := make(map[string]string, len(preset))
+	for status, value := range preset {
+		copied[status] = value
+	}
+	return copied
+}
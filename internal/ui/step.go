@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Step styles accepted by `step "..." with style "..."` and
+// `set step style to "..."`.
+const (
+	StepStyleBox       = "box"
+	StepStyleBanner    = "banner"
+	StepStyleUnderline = "underline"
+	StepStyleMinimal   = "minimal"
+)
+
+// StepRenderer draws the header printed by `step` actions.
+type StepRenderer interface {
+	RenderStep(w io.Writer, lines []string)
+}
+
+// NewStepRenderer returns the renderer for the named step style. A positive
+// width sets the minimum number of columns the header spans.
+func NewStepRenderer(style string, width int) (StepRenderer, error) {
+	if width < 0 {
+		return nil, fmt.Errorf("step width must not be negative, got %d", width)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(style)) {
+	case "", StepStyleBox:
+		return BoxStep{Width: width}, nil
+	case StepStyleBanner:
+		return BannerStep{Width: width}, nil
+	case StepStyleUnderline:
+		return UnderlineStep{Width: width}, nil
+	case StepStyleMinimal:
+		return MinimalStep{}, nil
+	default:
+		return nil, fmt.Errorf("unknown step style %q (supported: %s, %s, %s, %s)",
+			style, StepStyleBox, StepStyleBanner, StepStyleUnderline, StepStyleMinimal)
+	}
+}
+
+// maxDisplayWidth returns the display width of the widest line.
+func maxDisplayWidth(lines []string) int {
+	maxWidth := 0
+	for _, line := range lines {
+		if width := DisplayWidth(line); width > maxWidth {
+			maxWidth = width
+		}
+	}
+	return maxWidth
+}
+
+// BoxStep draws the lines inside a Unicode box sized to the longest line,
+// or to Width columns when that is wider.
+type BoxStep struct {
+	Width int
+}
+
+// RenderStep implements StepRenderer.
+func (s BoxStep) RenderStep(w io.Writer, lines []string) {
+	inner := max(maxDisplayWidth(lines), s.Width-4)
+
+	horizontal := strings.Repeat("─", inner+2)
+	_, _ = fmt.Fprintf(w, "┌%s┐\n", horizontal)
+	for _, line := range lines {
+		_, _ = fmt.Fprintf(w, "│ %s │\n", PadRight(line, inner))
+	}
+	_, _ = fmt.Fprintf(w, "└%s┘\n", horizontal)
+}
+
+// BannerStep centers the lines between two heavy rules.
+type BannerStep struct {
+	Width int
+}
+
+// RenderStep implements StepRenderer.
+func (s BannerStep) RenderStep(w io.Writer, lines []string) {
+	total := max(maxDisplayWidth(lines)+6, s.Width)
+
+	rule := strings.Repeat("═", total)
+	_, _ = fmt.Fprintln(w, rule)
+	for _, line := range lines {
+		left := (total - DisplayWidth(line)) / 2
+		_, _ = fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", left), line)
+	}
+	_, _ = fmt.Fprintln(w, rule)
+}
+
+// UnderlineStep prints the lines followed by a rule as wide as the longest
+// line, or Width columns when that is wider.
+type UnderlineStep struct {
+	Width int
+}
+
+// RenderStep implements StepRenderer.
+func (s UnderlineStep) RenderStep(w io.Writer, lines []string) {
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(w, line)
+	}
+	_, _ = fmt.Fprintln(w, strings.Repeat("─", max(maxDisplayWidth(lines), s.Width)))
+}
+
+// MinimalStep prints each line behind a single marker with no rules.
+type MinimalStep struct{}
+
+// RenderStep implements StepRenderer.
+func (MinimalStep) RenderStep(w io.Writer, lines []string) {
+	for _, line := range lines {
+		_, _ = fmt.Fprintf(w, "▸ %s\n", line)
+	}
+}
+
+// PlainStep prints each line with a [STEP] prefix and no box drawing.
+type PlainStep struct{}
+
+// RenderStep implements StepRenderer.
+func (PlainStep) RenderStep(w io.Writer, lines []string) {
+	for _, line := range lines {
+		_, _ = fmt.Fprintf(w, "[STEP] %s\n", line)
+	}
+}
+
+// ASCIIStep draws the lines inside a box of 7-bit ASCII characters.
+type ASCIIStep struct{}
+
+// RenderStep implements StepRenderer.
+func (ASCIIStep) RenderStep(w io.Writer, lines []string) {
+	inner := maxDisplayWidth(lines)
+
+	horizontal := strings.Repeat("-", inner+2)
+	_, _ = fmt.Fprintf(w, "+%s+\n", horizontal)
+	for _, line := range lines {
+		_, _ = fmt.Fprintf(w, "| %s |\n", PadRight(line, inner))
+	}
+	_, _ = fmt.Fprintf(w, "+%s+\n", horizontal)
+}
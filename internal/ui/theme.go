@@ -0,0 +1,120 @@
+// Package ui renders drun's user-facing status output.
+//
+// The engine writes messages as an icon prefix plus text; a Theme decides how
+// that prefix looks (emoji by default, bracketed tags for the plain style) and
+// how step headers are drawn. Symbols and colors can be set per status
+// category (info, ok, warn, error, fail).
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output styles accepted by `set output style to "..."`.
+const (
+	StyleEmoji        = "emoji"
+	StylePlain        = "plain"
+	StyleASCII        = "ascii"
+	StyleHighContrast = "high-contrast"
+)
+
+// Theme renders status prefixes and step headers for one output style.
+type Theme struct {
+	style     string
+	stepStyle string
+	stepWidth int
+	symbols   map[string]string // status category -> symbol; emoji are kept for categories without one
+	colors    map[string]string // status category -> SGR code
+	Step      StepRenderer
+}
+
+// DefaultTheme returns the emoji theme drun uses unless configured otherwise.
+func DefaultTheme() *Theme {
+	return &Theme{style: StyleEmoji, Step: BoxStep{}}
+}
+
+// NewTheme returns the theme for the named output style.
+func NewTheme(style string) (*Theme, error) {
+	switch strings.ToLower(strings.TrimSpace(style)) {
+	case "", StyleEmoji, "default":
+		return DefaultTheme(), nil
+	case StylePlain:
+		return &Theme{style: StylePlain, symbols: copyStatusMap(plainSymbols), Step: PlainStep{}}, nil
+	case StyleASCII:
+		return &Theme{style: StyleASCII, symbols: copyStatusMap(asciiSymbols), Step: ASCIIStep{}}, nil
+	case StyleHighContrast:
+		return &Theme{style: StyleHighContrast, symbols: copyStatusMap(highContrastSymbols),
+			colors: copyStatusMap(highContrastColors), Step: BoxStep{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown output style %q (supported: %s, %s, %s, %s)",
+			style, StyleEmoji, StylePlain, StyleASCII, StyleHighContrast)
+	}
+}
+
+// fixedStep reports whether the style keeps its own step headers: the plain
+// [STEP] prefix and the ASCII box ignore step styles
+func (t *Theme) fixedStep() bool {
+	return t.style == StylePlain || t.style == StyleASCII
+}
+
+// SetStepStyle changes the default step header style and width.
+// The plain and ASCII output styles always keep their own step headers.
+func (t *Theme) SetStepStyle(style string, width int) error {
+	renderer, err := NewStepRenderer(style, width)
+	if err != nil {
+		return err
+	}
+	t.stepStyle, t.stepWidth = style, width
+	if !t.fixedStep() {
+		t.Step = renderer
+	}
+	return nil
+}
+
+// StepFor returns the renderer for a single step, letting a per-step style
+// or width override the theme defaults. Empty values keep the defaults.
+func (t *Theme) StepFor(style string, width int) (StepRenderer, error) {
+	if style == "" && width == 0 {
+		return t.Step, nil
+	}
+	if style == "" {
+		style = t.stepStyle
+	}
+	if width == 0 {
+		width = t.stepWidth
+	}
+	renderer, err := NewStepRenderer(style, width)
+	if err != nil || t.fixedStep() {
+		return t.Step, err
+	}
+	return renderer, nil
+}
+
+// Style returns the theme's output style name.
+func (t *Theme) Style() string {
+	if t == nil {
+		return StyleEmoji
+	}
+	return t.style
+}
+
+// Icon returns the prefix to print for an emoji icon, including its trailing
+// spacing (for example "⚠️  "). The symbol of the icon's status category
+// replaces the emoji when the theme has one, followed by a single space.
+// Leading newlines are preserved.
+func (t *Theme) Icon(icon string) string {
+	if t == nil || (len(t.symbols) == 0 && len(t.colors) == 0) {
+		return icon
+	}
+
+	trimmed := strings.TrimLeft(icon, "\n")
+	lead := icon[:len(icon)-len(trimmed)]
+	text := strings.TrimRight(trimmed, " ")
+	spacing := trimmed[len(text):]
+	status := statusOf(strings.TrimSpace(strings.ReplaceAll(text, "\ufe0f", "")))
+	if symbol, ok := t.symbols[status]; ok {
+		text, spacing = symbol, " "
+	}
+	return lead + t.colorize(status, text) + spacing
+}
@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestThemeIcon(t *testing.T) {
+	plain, err := NewTheme("plain")
+	if err != nil {
+		t.Fatalf("NewTheme(plain) error = %v", err)
+	}
+
+	tests := []struct {
+		icon  string
+		emoji string
+		plain string
+	}{
+		{"ℹ️  ", "ℹ️  ", "[INFO] "},
+		{"⚠️  ", "⚠️  ", "[WARN] "},
+		{"❌  ", "❌  ", "[ERROR] "},
+		{"✅ ", "✅ ", "[OK] "},
+		{"💥  ", "💥  ", "[FAIL] "},
+		{"🐳 ", "🐳 ", "[INFO] "},
+		{"\n📊 ", "\n📊 ", "\n[INFO] "},
+	}
+	for _, tt := range tests {
+		if got := DefaultTheme().Icon(tt.icon); got != tt.emoji {
+			t.Errorf("DefaultTheme().Icon(%q) = %q, want %q", tt.icon, got, tt.emoji)
+		}
+		if got := plain.Icon(tt.icon); got != tt.plain {
+			t.Errorf("plain.Icon(%q) = %q, want %q", tt.icon, got, tt.plain)
+		}
+	}
+}
+
+func TestThemeStatusPresets(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	ascii, _ := NewTheme("ascii")
+	contrast, _ := NewTheme("high-contrast")
+
+	tests := []struct {
+		icon     string
+		ascii    string
+		contrast string
+	}{
+		{"ℹ️  ", "[i] ", "ℹ "},
+		{"✅ ", "[+] ", "\033[1;34m✔\033[0m "},
+		{"⚠️  ", "[!] ", "\033[1;33m▲\033[0m "},
+		{"❌  ", "[x] ", "\033[1;35m✖\033[0m "},
+		{"💥  ", "[X] ", "\033[1;35m‼\033[0m "},
+		{"\n🐳 ", "\n[i] ", "\nℹ "},
+	}
+	for _, tt := range tests {
+		if got := ascii.Icon(tt.icon); got != tt.ascii {
+			t.Errorf("ascii.Icon(%q) = %q, want %q", tt.icon, got, tt.ascii)
+		}
+		if got := contrast.Icon(tt.icon); got != tt.contrast {
+			t.Errorf("high-contrast.Icon(%q) = %q, want %q", tt.icon, got, tt.contrast)
+		}
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := contrast.Icon("✅ "); got != "✔ " {
+		t.Errorf("high-contrast.Icon() with NO_COLOR = %q, want no color", got)
+	}
+}
+
+func TestThemeStatusOverrides(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	theme := DefaultTheme()
+	if err := theme.SetStatusSymbols("ok=PASS, warn = (!)"); err != nil {
+		t.Fatalf("SetStatusSymbols() error = %v", err)
+	}
+	if err := theme.SetStatusColors("warn=bold bright-cyan, ok=none"); err != nil {
+		t.Fatalf("SetStatusColors() error = %v", err)
+	}
+
+	for icon, want := range map[string]string{
+		"✅ ":   "PASS ",
+		"🎉 ":   "PASS ",
+		"⚠️  ": "\033[1;96m(!)\033[0m ",
+		"❌  ":  "❌  ",
+	} {
+		if got := theme.Icon(icon); got != want {
+			t.Errorf("Icon(%q) = %q, want %q", icon, got, want)
+		}
+	}
+
+	plain, _ := NewTheme("plain")
+	if err := plain.SetStatusSymbols("fail=[BOOM]"); err != nil || plain.Icon("💥  ") != "[BOOM] " || plain.Icon("ℹ️  ") != "[INFO] " {
+		t.Errorf("plain overrides: Icon(💥) = %q, Icon(ℹ️) = %q, err = %v", plain.Icon("💥  "), plain.Icon("ℹ️  "), err)
+	}
+	if other, _ := NewTheme("plain"); other.Icon("💥  ") != "[FAIL] " {
+		t.Errorf("overrides changed the plain preset: %q", other.Icon("💥  "))
+	}
+
+	for _, spec := range []string{"okay=+", "ok", "ok="} {
+		if err := DefaultTheme().SetStatusSymbols(spec); err == nil {
+			t.Errorf("SetStatusSymbols(%q) expected error", spec)
+		}
+	}
+	if err := DefaultTheme().SetStatusColors("ok=orange"); err == nil {
+		t.Error("SetStatusColors(ok=orange) expected error")
+	}
+}
+
+func TestNewThemeRejectsUnknownStyle(t *testing.T) {
+	if _, err := NewTheme("fancy"); err == nil {
+		t.Fatal("NewTheme(fancy) expected error")
+	}
+}
+
+func TestStepRenderers(t *testing.T) {
+	var box bytes.Buffer
+	BoxStep{}.RenderStep(&box, []string{"Build", "Deploy now"})
+	want := "┌────────────┐\n│ Build      │\n│ Deploy now │\n└────────────┘\n"
+	if box.String() != want {
+		t.Errorf("BoxStep output = %q, want %q", box.String(), want)
+	}
+
+	var plain bytes.Buffer
+	PlainStep{}.RenderStep(&plain, []string{"Build"})
+	if plain.String() != "[STEP] Build\n" {
+		t.Errorf("PlainStep output = %q", plain.String())
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"Deploy", 6},
+		{"デプロイ", 8},
+		{"部署 v2", 7},
+		{"🚀 Ship", 7},
+		{"café", 4},
+		{"é", 1},
+		{"ℹ️", 1},
+	}
+	for _, tt := range tests {
+		if got := DisplayWidth(tt.in); got != tt.want {
+			t.Errorf("DisplayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"abc", []string{"a", "b", "c"}},
+		{"cafe\u0301!", []string{"c", "a", "f", "e\u0301", "!"}},
+		{"👍🏽ok", []string{"👍🏽", "o", "k"}},
+		{"👩\u200d💻 dev", []string{"👩\u200d💻", " ", "d", "e", "v"}},
+		{"🇧🇷🇵🇹🇺", []string{"🇧🇷", "🇵🇹", "🇺"}},
+		{"a\r\nb", []string{"a", "\r\n", "b"}},
+		{"ℹ️", []string{"ℹ️"}},
+	}
+	for _, tt := range tests {
+		got := Graphemes(tt.in)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Graphemes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := PadLeft("部署", 6); got != "  部署" {
+		t.Errorf("PadLeft() = %q", got)
+	}
+}
+
+func TestStepStyles(t *testing.T) {
+	tests := []struct {
+		style string
+		width int
+		lines []string
+		want  string
+	}{
+		{"box", 0, []string{"デプロイ", "Go"}, "┌──────────┐\n│ デプロイ │\n│ Go       │\n└──────────┘\n"},
+		{"box", 10, []string{"Go"}, "┌────────┐\n│ Go     │\n└────────┘\n"},
+		{"banner", 0, []string{"Go"}, "════════\n   Go\n════════\n"},
+		{"banner", 12, []string{"部署"}, "════════════\n    部署\n════════════\n"},
+		{"underline", 0, []string{"デプロイ"}, "デプロイ\n────────\n"},
+		{"underline", 5, []string{"Go"}, "Go\n─────\n"},
+		{"minimal", 40, []string{"Go"}, "▸ Go\n"},
+	}
+	for _, tt := range tests {
+		renderer, err := NewStepRenderer(tt.style, tt.width)
+		if err != nil {
+			t.Fatalf("NewStepRenderer(%q, %d) error = %v", tt.style, tt.width, err)
+		}
+		var buf bytes.Buffer
+		renderer.RenderStep(&buf, tt.lines)
+		if buf.String() != tt.want {
+			t.Errorf("%s/%d output = %q, want %q", tt.style, tt.width, buf.String(), tt.want)
+		}
+	}
+
+	if _, err := NewStepRenderer("fancy", 0); err == nil {
+		t.Error("NewStepRenderer(fancy) expected error")
+	}
+}
+
+func TestThemeStepFor(t *testing.T) {
+	theme := DefaultTheme()
+	if err := theme.SetStepStyle("underline", 0); err != nil {
+		t.Fatalf("SetStepStyle() error = %v", err)
+	}
+	if _, ok := theme.Step.(UnderlineStep); !ok {
+		t.Errorf("theme.Step = %T, want UnderlineStep", theme.Step)
+	}
+	if r, _ := theme.StepFor("", 30); r != (UnderlineStep{Width: 30}) {
+		t.Errorf("StepFor(\"\", 30) = %#v, want underline with width 30", r)
+	}
+	if r, _ := theme.StepFor("banner", 0); r != (BannerStep{}) {
+		t.Errorf("StepFor(banner, 0) = %#v, want BannerStep", r)
+	}
+
+	plain, _ := NewTheme("plain")
+	if r, err := plain.StepFor("banner", 80); err != nil || r != (PlainStep{}) {
+		t.Errorf("plain StepFor(banner, 80) = %#v, %v; want PlainStep", r, err)
+	}
+
+	ascii, _ := NewTheme("ascii")
+	if err := ascii.SetStepStyle("banner", 0); err != nil || ascii.Step != (ASCIIStep{}) {
+		t.Errorf("ascii step after SetStepStyle(banner) = %#v, %v; want ASCIIStep", ascii.Step, err)
+	}
+}